@@ -27,6 +27,9 @@ func main() {
 		fmt.Println("parsing env variables was not successful: ", err)
 	}
 	config.Settings.Sanitize()
+	if err = config.Settings.Validate(); err != nil {
+		log.Fatalf("Server refused to start: %v", err)
+	}
 	if err = server.Run(config.Settings.Address); err != nil {
 		log.Fatalf("Server failed to start: %v", err)
 	}
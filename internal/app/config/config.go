@@ -16,7 +16,10 @@ import (
 	"io/fs"
 	"math/big"
 	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -24,31 +27,146 @@ import (
 
 // Config is a structure that contains all the configurations for the application.
 type Config struct {
-	Address                            string `env:"SERVER_ADDRESS" json:"server_address"`
-	HostedOn                           string `env:"BASE_URL" json:"base_url"`
-	LogLevel                           string `env:"LOG_LEVEL" envDefault:"INFO"`
-	FileStoragePath                    string `env:"FILE_STORAGE_PATH" json:"file_storage_path"`
-	DatabaseDSN                        string `env:"DATABASE_DSN" json:"database_dsn"`
-	SecretKey                          string `env:"SECRET_KEY" envDefault:"DontUseThatInProduction"`
-	KeyPath                            string `env:"KEY_PATH" envDefault:"./cert.pem"`
-	CertPath                           string `env:"CERT_PATH" envDefault:"./key.pem"`
-	ConfigFile                         string `env:"CONFIG"`
-	TrustedSubnet                      string `env:"TRUSTED_SUBNET" json:"trusted_subnet"`
-	GRPCPort                           string `env:"GRPC_PORT" envDefault:"3200" json:"grpc_port"`
-	GRPCToken                          string `env:"GRPC_TOKEN" json:"grpc_token"`
-	DatabaseMaxConnections             int    `env:"DATABASE_MAX_CONNECTIONS"  envDefault:"99"`
-	JWTExpireHours                     int64  `env:"JWT_EXPIRE_HOURS" envDefault:"96"`
-	DefaultChannelsBufferSize          int64  `env:"DEFAULT_CHANNELS_BUFFER_SIZE" envDefault:"1024"`
-	DeletionBufferFlushIntervalSeconds int64  `env:"DELETION_BUFFER_FLUSH_INTERVAL_SECONDS" envDefault:"10"`
-	TLSEnabled                         bool   `env:"ENABLE_HTTPS" envDefault:"false" json:"enable_https"`
-	UseHeaderForSourceAddress          bool   `env:"USE_HEADER_FOR_SOURCE_ADDRESS" envDefault:"true" json:"use_header_for_source_address"`
-}
-
-// Sanitize fixes HostedOn variable with trailing slash.
+	Address                            string   `env:"SERVER_ADDRESS" json:"server_address"`
+	HostedOn                           string   `env:"BASE_URL" json:"base_url"`
+	LogLevel                           string   `env:"LOG_LEVEL" envDefault:"INFO"`
+	FileStoragePath                    string   `env:"FILE_STORAGE_PATH" json:"file_storage_path"`
+	DatabaseDSN                        string   `env:"DATABASE_DSN" json:"database_dsn"`
+	SecretKey                          string   `env:"SECRET_KEY" envDefault:"DontUseThatInProduction"`
+	KeyPath                            string   `env:"KEY_PATH" envDefault:"./cert.pem"`
+	CertPath                           string   `env:"CERT_PATH" envDefault:"./key.pem"`
+	ConfigFile                         string   `env:"CONFIG"`
+	TrustedSubnet                      string   `env:"TRUSTED_SUBNET" json:"trusted_subnet"`
+	GRPCPort                           string   `env:"GRPC_PORT" envDefault:"3200" json:"grpc_port"`
+	GRPCToken                          string   `env:"GRPC_TOKEN" json:"grpc_token"`
+	DefaultReferrerPolicy              string   `env:"DEFAULT_REFERRER_POLICY"`
+	DefaultRobotsTag                   string   `env:"DEFAULT_ROBOTS_TAG"`
+	GeoIPDatabasePath                  string   `env:"GEOIP_DATABASE_PATH" json:"geoip_database_path"`
+	AuthCookieDomain                   string   `env:"AUTH_COOKIE_DOMAIN"`
+	AuthCookieSameSite                 string   `env:"AUTH_COOKIE_SAME_SITE" envDefault:"Lax"`
+	OIDCIssuer                         string   `env:"OIDC_ISSUER" json:"oidc_issuer"`
+	OIDCClientID                       string   `env:"OIDC_CLIENT_ID" json:"oidc_client_id"`
+	OIDCClientSecret                   string   `env:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL                    string   `env:"OIDC_REDIRECT_URL" json:"oidc_redirect_url"`
+	Domains                            []Domain `env:"SHORT_DOMAINS" json:"domains"`
+	JWTKeys                            []JWTKey `env:"JWT_KEYS" json:"jwt_keys"`
+	TrustedProxies                     []string `env:"TRUSTED_PROXIES" envSeparator:"," json:"trusted_proxies"`
+	OIDCScopes                         []string `env:"OIDC_SCOPES" envDefault:"openid,email,profile" envSeparator:","`
+	DatabaseMaxConnections             int      `env:"DATABASE_MAX_CONNECTIONS"  envDefault:"99"`
+	JWTExpireHours                     int64    `env:"JWT_EXPIRE_HOURS" envDefault:"96"`
+	JWTReissueGraceHours               int64    `env:"JWT_REISSUE_GRACE_HOURS" envDefault:"24"`
+	DefaultChannelsBufferSize          int64    `env:"DEFAULT_CHANNELS_BUFFER_SIZE" envDefault:"1024"`
+	DeletionBufferFlushIntervalSeconds int64    `env:"DELETION_BUFFER_FLUSH_INTERVAL_SECONDS" envDefault:"10"`
+	MetadataFetchTimeoutSeconds        int64    `env:"METADATA_FETCH_TIMEOUT_SECONDS" envDefault:"5"`
+	PasswordAttemptsWindowSeconds      int64    `env:"PASSWORD_ATTEMPTS_WINDOW_SECONDS" envDefault:"900"`
+	PermanentRedirectMaxAgeSeconds     int64    `env:"PERMANENT_REDIRECT_MAX_AGE_SECONDS" envDefault:"86400"`
+	ClicksFlushIntervalSeconds         int64    `env:"CLICKS_FLUSH_INTERVAL_SECONDS" envDefault:"10"`
+	SplitCookieMaxAgeSeconds           int      `env:"SPLIT_COOKIE_MAX_AGE_SECONDS" envDefault:"2592000"`
+	AuthCookieMaxAgeSeconds            int      `env:"AUTH_COOKIE_MAX_AGE_SECONDS" envDefault:"2592000"`
+	MetadataWorkers                    int      `env:"METADATA_WORKERS" envDefault:"4"`
+	PasswordMaxAttempts                int      `env:"PASSWORD_MAX_ATTEMPTS" envDefault:"5"`
+	PasswordLinkMaxAttempts            int      `env:"PASSWORD_LINK_MAX_ATTEMPTS" envDefault:"50"`
+	LoginAccountMaxAttempts            int      `env:"LOGIN_ACCOUNT_MAX_ATTEMPTS" envDefault:"20"`
+	RegisterMaxAttempts                int      `env:"REGISTER_MAX_ATTEMPTS" envDefault:"10"`
+	DefaultRedirectStatus              int      `env:"DEFAULT_REDIRECT_STATUS" envDefault:"307"`
+	TLSEnabled                         bool     `env:"ENABLE_HTTPS" envDefault:"false" json:"enable_https"`
+	UseHeaderForSourceAddress          bool     `env:"USE_HEADER_FOR_SOURCE_ADDRESS" envDefault:"true" json:"use_header_for_source_address"`
+	AllowPrivateNetworks               bool     `env:"ALLOW_PRIVATE_NETWORKS" envDefault:"false"`
+	ScheduledPlaceholder               bool     `env:"SCHEDULED_PLACEHOLDER" envDefault:"false" json:"scheduled_placeholder"`
+	DevMode                            bool     `env:"DEV_MODE" json:"dev_mode"`
+	AuthCookieSecure                   bool     `env:"AUTH_COOKIE_SECURE"`
+}
+
+// DefaultSecretKey is the well-known SecretKey used if none is passed, acceptable in the dev mode only.
+const DefaultSecretKey = "DontUseThatInProduction"
+
+// ErrInsecureDefaults is returned by Validate if the server is about to start with the well-known secrets.
+var ErrInsecureDefaults = errors.New("the default secret key is used outside of the dev mode")
+
+// JWTKey is the key the JWTs are signed or verified with, see middlewares.Keyring.
+// Implements the TextUnmarshaler interface to be listed in the environment variable separated by commas,
+// the ID of the key is followed by the algorithm and the source after the equal sign: "2026-10=RS256:/keys/jwt.pem".
+// The source is the secret itself for HS256 and the path of the PEM file for RS256 and EdDSA.
+type JWTKey struct {
+	ID        string `json:"id"`               // the kid header of the tokens signed with the key
+	Algorithm string `json:"algorithm"`        // HS256, RS256 or EdDSA
+	Secret    string `json:"secret,omitempty"` // the secret of the HS256 key
+	Path      string `json:"path,omitempty"`   // the PEM file with the private key, or the public one to verify only
+}
+
+// UnmarshalText sets the key from its representation in the environment variable.
+func (k *JWTKey) UnmarshalText(text []byte) error {
+	id, rest, found := strings.Cut(string(text), "=")
+	if !found {
+		return fmt.Errorf("wrong JWT key format %q (must be id=algorithm:source)", text)
+	}
+	algorithm, source, found := strings.Cut(rest, ":")
+	if !found {
+		return fmt.Errorf("wrong JWT key format %q (must be id=algorithm:source)", text)
+	}
+	*k = JWTKey{ID: id, Algorithm: algorithm}
+	if strings.HasPrefix(algorithm, "HS") {
+		k.Secret = source
+	} else {
+		k.Path = source
+	}
+	return nil
+}
+
+// UnmarshalJSON sets the key from the JSON object of the config file, bypassing UnmarshalText.
+func (k *JWTKey) UnmarshalJSON(data []byte) error {
+	type plainJWTKey JWTKey
+	return json.Unmarshal(data, (*plainJWTKey)(k))
+}
+
+// Domain is the branded short domain along with the users allowed to create the short URLs on it.
+// Implements the TextUnmarshaler interface to be listed in the environment variable separated by commas,
+// the host is followed by the users separated by | after the equal sign: "go.example.com,brand.example.com=id1|id2".
+type Domain struct {
+	Host  string   `json:"host"`            // host the visitors follow the short URLs on, with the port if it isn't default
+	Users []string `json:"users,omitempty"` // IDs of the users allowed to use the domain, anyone if empty
+}
+
+// UnmarshalText sets the domain from its representation in the environment variable.
+func (d *Domain) UnmarshalText(text []byte) error {
+	host, users, found := strings.Cut(string(text), "=")
+	d.Host = host
+	d.Users = nil
+	if found && users != "" {
+		d.Users = strings.Split(users, "|")
+	}
+	return nil
+}
+
+// UnmarshalJSON sets the domain from the JSON object of the config file, bypassing UnmarshalText.
+func (d *Domain) UnmarshalJSON(data []byte) error {
+	type plainDomain Domain
+	return json.Unmarshal(data, (*plainDomain)(d))
+}
+
+// Allows reports whether the user may create the short URLs on the domain.
+func (d Domain) Allows(userID string) bool {
+	return len(d.Users) == 0 || slices.Contains(d.Users, userID)
+}
+
+// Sanitize fixes HostedOn variable with trailing slash and falls back to the temporary redirect
+// if the default redirect status is not supported. Lowercases the hosts of the branded domains,
+// dropping the invalid ones and the one of HostedOn, which is the default domain anyway.
+// The identity provider redirects back to the callback of HostedOn unless the redirect URL is set.
 func (cfg *Config) Sanitize() {
 	if !strings.HasSuffix(cfg.HostedOn, "/") {
 		cfg.HostedOn = cfg.HostedOn + "/"
 	}
+	cfg.Domains = sanitizeDomains(cfg.Domains, cfg.HostedOn)
+	switch cfg.DefaultRedirectStatus {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		fmt.Printf("unsupported default redirect status %d, using %d\n", cfg.DefaultRedirectStatus, http.StatusTemporaryRedirect)
+		cfg.DefaultRedirectStatus = http.StatusTemporaryRedirect
+	}
+	if cfg.OIDCIssuer != "" && cfg.OIDCRedirectURL == "" {
+		cfg.OIDCRedirectURL = cfg.HostedOn + OIDCCallbackPath
+	}
 
 	if Settings.TLSEnabled {
 		_, _, err := GetOrCreateCertAndKey()
@@ -58,6 +176,60 @@ func (cfg *Config) Sanitize() {
 	}
 }
 
+// Validate refuses the settings the server must not start with: the JWTs signed with DefaultSecretKey can be
+// forged by anyone, so either the JWT keys or the own SecretKey are required unless DevMode is set.
+// The SameSite attribute of the auth cookie must be one of Lax, Strict or None, the single sign-on
+// needs the client ID of the identity provider, and the trusted proxies must be the valid CIDRs. Without
+// the trusted proxies the source address is taken from the headers of any client if UseHeaderForSourceAddress is set.
+func (cfg *Config) Validate() error {
+	for _, proxy := range cfg.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			return fmt.Errorf("invalid TRUSTED_PROXIES %q: %w", proxy, err)
+		}
+	}
+	switch strings.ToLower(cfg.AuthCookieSameSite) {
+	case "", "lax", "strict", "none":
+	default:
+		return fmt.Errorf("invalid AUTH_COOKIE_SAME_SITE %q, use Lax, Strict or None", cfg.AuthCookieSameSite)
+	}
+	if cfg.OIDCIssuer != "" && cfg.OIDCClientID == "" {
+		return errors.New("OIDC_CLIENT_ID is required along with OIDC_ISSUER")
+	}
+	if cfg.DevMode {
+		return nil
+	}
+	if len(cfg.JWTKeys) == 0 && cfg.SecretKey == DefaultSecretKey {
+		return fmt.Errorf("%w: set SECRET_KEY or JWT_KEYS, or DEV_MODE for the local development", ErrInsecureDefaults)
+	}
+	return nil
+}
+
+// sanitizeDomains returns the valid branded domains with the lowercased hosts, the first one wins for the repeated host.
+func sanitizeDomains(domains []Domain, hostedOn string) []Domain {
+	var defaultHost string
+	if parsed, err := url.Parse(hostedOn); err == nil {
+		defaultHost = strings.ToLower(parsed.Host)
+	}
+	result := make([]Domain, 0, len(domains))
+	seen := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		domain.Host = strings.ToLower(strings.TrimSpace(domain.Host))
+		if domain.Host == defaultHost || seen[domain.Host] {
+			continue
+		}
+		if parsed, err := url.Parse("//" + domain.Host); err != nil || domain.Host == "" || parsed.Host != domain.Host {
+			fmt.Printf("invalid short domain %q, ignoring it\n", domain.Host)
+			continue
+		}
+		seen[domain.Host] = true
+		result = append(result, domain)
+	}
+	return result
+}
+
+// OIDCCallbackPath is the path of the callback the identity provider redirects back to, relative to the base URL.
+const OIDCCallbackPath = "api/user/oidc/callback"
+
 // Settings is the global instance of Config type with all initialized settings.
 var Settings Config
 
@@ -71,6 +243,7 @@ func NewConfigFromArgs(argsConfig ArgsConfig) Config {
 		TLSEnabled:      argsConfig.TLSEnabled.TLSEnabled,
 		ConfigFile:      argsConfig.ConfigFile.String(),
 		TrustedSubnet:   argsConfig.TrustedSubnet.String(),
+		DevMode:         argsConfig.DevMode.DevMode,
 	}
 }
 
@@ -83,6 +256,7 @@ type ArgsConfig struct {
 	HostedOn        HTTPAddress
 	Address         NetAddress
 	TLSEnabled      TLSEnabled
+	DevMode         DevMode
 }
 
 var argsConfig ArgsConfig
@@ -211,6 +385,28 @@ func (e *TLSEnabled) Set(_ string) error {
 	return nil
 }
 
+// DevMode is a structure that represents the flag of the local development mode, allowing the insecure defaults.
+// Implements the Value interface.
+type DevMode struct {
+	DevMode bool
+}
+
+// String returns the string representation of the flag.
+func (d *DevMode) String() string {
+	return strconv.FormatBool(d.DevMode)
+}
+
+// Set sets the flag from its string representation.
+func (d *DevMode) Set(_ string) error {
+	d.DevMode = true
+	return nil
+}
+
+// IsBoolFlag allows passing the flag without the value.
+func (d *DevMode) IsBoolFlag() bool {
+	return true
+}
+
 // FileConfig is a structure that represents the path of config file for the project.
 // Implements the Value interface.
 type FileConfig struct {
@@ -260,6 +456,7 @@ func ParseFlags() {
 	isTLSEnabled := new(TLSEnabled)
 	fileConfig := new(FileConfig)
 	trustedSubnet := new(TrustedSubnet)
+	devMode := new(DevMode)
 
 	flag.Var(hostAddr, "a", "Address to host on host:port")
 	flag.Var(baseAddr, "b", "base URL for resulting short URL (scheme://host:port)")
@@ -268,6 +465,7 @@ func ParseFlags() {
 	flag.Var(isTLSEnabled, "s", "TLS is enabled (default: false)")
 	flag.Var(fileConfig, "c", "path to config file")
 	flag.Var(trustedSubnet, "t", "trusted subnet to use for access check in internal routers")
+	flag.Var(devMode, "dev", "local development mode, allows the default secret key (default: false)")
 	flag.Parse()
 	jsonConfig := &Config{}
 	var filePath string
@@ -313,6 +511,12 @@ func ParseFlags() {
 			os.Exit(1)
 		}
 	}
+	if jsonConfig.DevMode {
+		setErr := devMode.Set("true")
+		if setErr != nil {
+			os.Exit(1)
+		}
+	}
 	if jsonConfig.TLSEnabled {
 		setErr := argsConfig.TLSEnabled.Set("true")
 		if setErr != nil {
@@ -326,7 +530,14 @@ func ParseFlags() {
 	argsConfig.TLSEnabled = *isTLSEnabled
 	argsConfig.ConfigFile = *fileConfig
 	argsConfig.TrustedSubnet = *trustedSubnet
+	argsConfig.DevMode = *devMode
 	Settings = NewConfigFromArgs(argsConfig)
+	Settings.Domains = jsonConfig.Domains
+	Settings.JWTKeys = jsonConfig.JWTKeys
+	Settings.TrustedProxies = jsonConfig.TrustedProxies
+	Settings.OIDCIssuer = jsonConfig.OIDCIssuer
+	Settings.OIDCClientID = jsonConfig.OIDCClientID
+	Settings.OIDCRedirectURL = jsonConfig.OIDCRedirectURL
 }
 
 func closeWrapper(file *os.File) {
@@ -454,9 +665,12 @@ func init() {
 	Settings.LogLevel = "INFO"
 	Settings.FileStoragePath = "./storage.json"
 	Settings.DatabaseDSN = ""
-	Settings.SecretKey = "DontUseThatInProduction" // Ожидается, что настоящий ключ будет передан через env
+	Settings.SecretKey = DefaultSecretKey          // Ожидается, что настоящий ключ будет передан через env
 	Settings.GRPCToken = "DontUseThatInProduction" // Ожидается, что настоящий ключ будет передан через env
 	Settings.DeletionBufferFlushIntervalSeconds = 1
+	Settings.ClicksFlushIntervalSeconds = 1
+	Settings.JWTReissueGraceHours = 24
+	Settings.AuthCookieMaxAgeSeconds = 2592000
 	Settings.KeyPath = "./key.pem"
 	Settings.CertPath = "./cert.pem"
 }
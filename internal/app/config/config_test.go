@@ -1,11 +1,13 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 	"testing"
 
+	"github.com/caarlos0/env/v6"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -527,3 +529,108 @@ func TestTrustedSubnet_String(t1 *testing.T) {
 		})
 	}
 }
+
+func TestDomain_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Domain
+	}{
+		{
+			name:  "domain open to everyone",
+			input: "go.example.com",
+			want:  Domain{Host: "go.example.com"},
+		},
+		{
+			name:  "domain with users",
+			input: "brand.example.com=alice|bob",
+			want:  Domain{Host: "brand.example.com", Users: []string{"alice", "bob"}},
+		},
+		{
+			name:  "domain with empty users",
+			input: "brand.example.com=",
+			want:  Domain{Host: "brand.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var domain Domain
+			require.NoError(t, domain.UnmarshalText([]byte(tt.input)))
+			assert.Equal(t, tt.want, domain)
+		})
+	}
+}
+
+func TestDomains_FromJSON(t *testing.T) {
+	var config Config
+	err := json.Unmarshal(
+		[]byte(`{"domains": [{"host": "go.example.com"}, {"host": "brand.example.com", "users": ["alice"]}]}`), &config)
+	require.NoError(t, err)
+	assert.Equal(t, []Domain{{Host: "go.example.com"}, {Host: "brand.example.com", Users: []string{"alice"}}}, config.Domains)
+}
+
+func TestDomains_FromEnv(t *testing.T) {
+	t.Setenv("SHORT_DOMAINS", "go.example.com,brand.example.com=alice|bob")
+	var config Config
+	require.NoError(t, env.Parse(&config))
+	assert.Equal(t, []Domain{
+		{Host: "go.example.com"},
+		{Host: "brand.example.com", Users: []string{"alice", "bob"}},
+	}, config.Domains)
+}
+
+func TestDomain_Allows(t *testing.T) {
+	assert.True(t, Domain{Host: "go.example.com"}.Allows("alice"))
+	assert.True(t, Domain{Host: "brand.example.com", Users: []string{"alice"}}.Allows("alice"))
+	assert.False(t, Domain{Host: "brand.example.com", Users: []string{"alice"}}.Allows("bob"))
+}
+
+func Test_sanitizeDomains(t *testing.T) {
+	domains := []Domain{
+		{Host: " Go.Example.com "},
+		{Host: "localhost:8080"},
+		{Host: "go.example.com", Users: []string{"alice"}},
+		{Host: "bad/host"},
+		{Host: ""},
+		{Host: "brand.example.com:8443", Users: []string{"bob"}},
+	}
+	assert.Equal(t, []Domain{
+		{Host: "go.example.com"},
+		{Host: "brand.example.com:8443", Users: []string{"bob"}},
+	}, sanitizeDomains(domains, "http://localhost:8080/"))
+}
+
+func TestJWTKey_UnmarshalText(t *testing.T) {
+	var key JWTKey
+	require.NoError(t, key.UnmarshalText([]byte("2026-10=HS256:se:cret")))
+	assert.Equal(t, JWTKey{ID: "2026-10", Algorithm: "HS256", Secret: "se:cret"}, key)
+	require.NoError(t, key.UnmarshalText([]byte("2026-11=RS256:/keys/jwt.pem")))
+	assert.Equal(t, JWTKey{ID: "2026-11", Algorithm: "RS256", Path: "/keys/jwt.pem"}, key)
+	assert.Error(t, key.UnmarshalText([]byte("2026-12")))
+	assert.Error(t, key.UnmarshalText([]byte("2026-12=EdDSA")))
+}
+
+func TestJWTKeys_FromEnv(t *testing.T) {
+	t.Setenv("JWT_KEYS", "new=EdDSA:/keys/ed.pem,old=HS256:secret")
+	var config Config
+	require.NoError(t, env.Parse(&config))
+	assert.Equal(t, []JWTKey{
+		{ID: "new", Algorithm: "EdDSA", Path: "/keys/ed.pem"},
+		{ID: "old", Algorithm: "HS256", Secret: "secret"},
+	}, config.JWTKeys)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.ErrorIs(t, (&Config{SecretKey: DefaultSecretKey}).Validate(), ErrInsecureDefaults)
+	assert.NoError(t, (&Config{SecretKey: DefaultSecretKey, DevMode: true}).Validate())
+	assert.NoError(t, (&Config{SecretKey: "own-secret"}).Validate())
+	assert.NoError(t, (&Config{SecretKey: DefaultSecretKey,
+		JWTKeys: []JWTKey{{ID: "new", Algorithm: "HS256", Secret: "secret"}}}).Validate())
+	assert.NoError(t, (&Config{SecretKey: "own-secret", AuthCookieSameSite: "strict"}).Validate())
+	assert.Error(t, (&Config{SecretKey: "own-secret", AuthCookieSameSite: "Sometimes"}).Validate())
+	assert.Error(t, (&Config{SecretKey: "own-secret", OIDCIssuer: "https://idp.example"}).Validate())
+	assert.NoError(t, (&Config{SecretKey: "own-secret", OIDCIssuer: "https://idp.example",
+		OIDCClientID: "shortener"}).Validate())
+	assert.NoError(t, (&Config{SecretKey: "own-secret", TrustedProxies: []string{"10.0.0.0/8", "::1/128"}}).Validate())
+	assert.Error(t, (&Config{SecretKey: "own-secret", TrustedProxies: []string{"10.0.0.1"}}).Validate())
+}
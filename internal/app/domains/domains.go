@@ -0,0 +1,85 @@
+// Package domains maps the short URLs to the short domains they are followed on.
+//
+// Besides the default domain of the base URL, the short URLs can be created on the branded domains listed
+// in the settings. Every domain has its own namespace of IDs: the short URL of the default domain is stored
+// by its ID, the one of the branded domain is stored by the key made of the host and the ID separated by the slash,
+// which never occurs in the generated IDs.
+package domains
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/clearthree/url-shortener/internal/app/config"
+)
+
+const keySeparator = "/"
+
+// Lookup returns the branded domain configured for the host, the host is compared case-insensitively.
+func Lookup(host string) (config.Domain, bool) {
+	host = strings.ToLower(host)
+	for _, domain := range config.Settings.Domains {
+		if domain.Host == host {
+			return domain, true
+		}
+	}
+	return config.Domain{}, false
+}
+
+// FromHost returns the domain the request sent to the host is served on: the branded domain if the host
+// is configured, the default one (the empty string) otherwise.
+func FromHost(host string) string {
+	if domain, ok := Lookup(host); ok {
+		return domain.Host
+	}
+	return ""
+}
+
+// IsDefault reports whether the host is the one of the default domain or is empty.
+func IsDefault(host string) bool {
+	return host == "" || strings.EqualFold(host, defaultHost())
+}
+
+// Key returns the key the short URL with the ID is stored by on the domain.
+func Key(domain string, id string) string {
+	if domain == "" {
+		return id
+	}
+	return domain + keySeparator + id
+}
+
+// Split splits the key of the short URL into the domain and the ID, the domain is empty for the default one.
+func Split(key string) (string, string) {
+	domain, id, found := strings.Cut(key, keySeparator)
+	if !found {
+		return "", key
+	}
+	return domain, id
+}
+
+// BaseURL returns the URL the IDs of the short URLs on the domain are appended to, with the trailing slash.
+// The branded domains share the scheme of the default one.
+func BaseURL(domain string) string {
+	if domain == "" {
+		return config.Settings.HostedOn
+	}
+	scheme := "http"
+	if parsed, err := url.Parse(config.Settings.HostedOn); err == nil && parsed.Scheme != "" {
+		scheme = parsed.Scheme
+	}
+	return scheme + "://" + domain + "/"
+}
+
+// ShortURL returns the full short URL the visitors follow by the key it is stored by.
+func ShortURL(key string) string {
+	domain, id := Split(key)
+	return BaseURL(domain) + id
+}
+
+func defaultHost() string {
+	parsed, err := url.Parse(config.Settings.HostedOn)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
@@ -0,0 +1,72 @@
+package domains
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/clearthree/url-shortener/internal/app/config"
+)
+
+func withDomains(t *testing.T, domains ...config.Domain) {
+	previous := config.Settings.Domains
+	config.Settings.Domains = domains
+	t.Cleanup(func() { config.Settings.Domains = previous })
+}
+
+func TestKeyAndSplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain string
+		id     string
+		key    string
+	}{
+		{name: "default domain", domain: "", id: "lelelele", key: "lelelele"},
+		{name: "branded domain", domain: "go.example.com", id: "lelelele", key: "go.example.com/lelelele"},
+		{name: "branded domain with port", domain: "go.example.com:8443", id: "lelelele", key: "go.example.com:8443/lelelele"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.key, Key(tt.domain, tt.id))
+			domain, id := Split(tt.key)
+			assert.Equal(t, tt.domain, domain)
+			assert.Equal(t, tt.id, id)
+		})
+	}
+}
+
+func TestShortURL(t *testing.T) {
+	assert.Equal(t, "http://localhost:8080/lelelele", ShortURL("lelelele"))
+	assert.Equal(t, "http://go.example.com/lelelele", ShortURL("go.example.com/lelelele"))
+
+	previous := config.Settings.HostedOn
+	config.Settings.HostedOn = "https://short.example.com/"
+	defer func() { config.Settings.HostedOn = previous }()
+	assert.Equal(t, "https://go.example.com/lelelele", ShortURL("go.example.com/lelelele"))
+}
+
+func TestFromHost(t *testing.T) {
+	withDomains(t, config.Domain{Host: "go.example.com"}, config.Domain{Host: "brand.example.com", Users: []string{"alice"}})
+	assert.Equal(t, "go.example.com", FromHost("go.example.com"))
+	assert.Equal(t, "brand.example.com", FromHost("Brand.Example.com"))
+	assert.Equal(t, "", FromHost("localhost:8080"))
+	assert.Equal(t, "", FromHost("unknown.example.com"))
+	assert.Equal(t, "", FromHost(""))
+}
+
+func TestLookup(t *testing.T) {
+	withDomains(t, config.Domain{Host: "brand.example.com", Users: []string{"alice"}})
+	domain, ok := Lookup("BRAND.example.com")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"alice"}, domain.Users)
+	_, ok = Lookup("go.example.com")
+	assert.False(t, ok)
+}
+
+func TestIsDefault(t *testing.T) {
+	assert.True(t, IsDefault(""))
+	assert.True(t, IsDefault("localhost:8080"))
+	assert.True(t, IsDefault("LOCALHOST:8080"))
+	assert.False(t, IsDefault("localhost"))
+	assert.False(t, IsDefault("go.example.com"))
+}
@@ -0,0 +1,56 @@
+// Package geoip resolves the country of the visitor from the local database in the MaxMind format.
+package geoip
+
+import (
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// DB is the global database used to resolve the countries, nothing is resolved if it isn't opened.
+var DB *Reader
+
+// Reader is a wrapper over the MaxMind database reader that looks up only the country of the address.
+type Reader struct {
+	reader *maxminddb.Reader
+}
+
+// countryRecord is the part of the GeoIP2/GeoLite2 country and city records the reader needs.
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// Open opens the database file by the path.
+func Open(path string) (*Reader, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{reader: reader}, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country of the address, the registered country is used
+// if the actual one is unknown. The empty string is returned if the database has no record for the address.
+func (r *Reader) Country(ip net.IP) string {
+	if r == nil || ip == nil {
+		return ""
+	}
+	var record countryRecord
+	if err := r.reader.Lookup(ip, &record); err != nil {
+		return ""
+	}
+	if record.Country.ISOCode != "" {
+		return record.Country.ISOCode
+	}
+	return record.RegisteredCountry.ISOCode
+}
+
+// Close closes the database file.
+func (r *Reader) Close() error {
+	return r.reader.Close()
+}
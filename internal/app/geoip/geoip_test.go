@@ -0,0 +1,40 @@
+package geoip
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader_Country(t *testing.T) {
+	reader, err := Open("testdata/GeoIP2-Country-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+	tests := []struct {
+		name string
+		ip   string
+		want string
+	}{
+		{name: "IPv4 address", ip: "81.2.69.142", want: "GB"},
+		{name: "IPv6 address", ip: "2a02:6b8::2:242", want: "RU"},
+		{name: "Registered country only", ip: "216.160.83.56", want: "US"},
+		{name: "Unknown address", ip: "127.0.0.1", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, reader.Country(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestReader_CountryWithoutDatabase(t *testing.T) {
+	var reader *Reader
+	assert.Equal(t, "", reader.Country(net.ParseIP("81.2.69.142")))
+}
+
+func TestOpen_Missing(t *testing.T) {
+	_, err := Open("testdata/missing.mmdb")
+	assert.Error(t, err)
+}
@@ -6,18 +6,29 @@
 package handlers
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"io"
+	"math"
+	"math/rand"
+	"net"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/clearthree/url-shortener/internal/app/utils"
 
+	"github.com/clearthree/url-shortener/internal/app/config"
+	"github.com/clearthree/url-shortener/internal/app/domains"
+	"github.com/clearthree/url-shortener/internal/app/geoip"
 	"github.com/clearthree/url-shortener/internal/app/logger"
 	"github.com/clearthree/url-shortener/internal/app/middlewares"
 	"github.com/clearthree/url-shortener/internal/app/models"
+	"github.com/clearthree/url-shortener/internal/app/oidc"
+	"github.com/clearthree/url-shortener/internal/app/pages"
 	"github.com/clearthree/url-shortener/internal/app/storage"
 
 	"github.com/clearthree/url-shortener/internal/app/service"
@@ -26,6 +37,29 @@ import (
 // maxPayloadSize - is the maximum size of payload that the server can process in the request.
 const maxPayloadSize = 1024 * 1024
 
+// splitCookiePrefix is the prefix of the cookie name the visitor keeps the split variant of the short URL in.
+const splitCookiePrefix = "split_"
+
+// qrCacheControl is the Cache-Control header of the QR code image. The image of the short URL never changes,
+// so it is cached for a day and revalidated by the ETag afterward.
+const qrCacheControl = "public, max-age=86400"
+
+// oidcLoginCookieName is the name of the cookie keeping the state of the single sign-on between the redirect
+// to the identity provider and the callback.
+const oidcLoginCookieName = "oidc_login"
+
+// oidcLoginCookiePath limits the cookie with the state of the single sign-on to its endpoints.
+const oidcLoginCookiePath = "/api/user/oidc"
+
+// oidcLoginMaxAgeSeconds is the time the user has to sign in with the identity provider.
+const oidcLoginMaxAgeSeconds = 600
+
+// PasswordHeader is the header the API clients pass the password of the protected short URL in.
+const PasswordHeader = "X-Link-Password"
+
+// errSuffixNotAllowed is an error that is returned when the path suffix is passed to the short URL not allowing it.
+var errSuffixNotAllowed = errors.New("path suffix is not allowed for the short url")
+
 // IHandler is the interface for all handler-structures
 type IHandler interface {
 	ServeHTTP(http.ResponseWriter, *http.Request)
@@ -47,6 +81,7 @@ func NewCreateShortURLHandler(service service.ShortURLServiceInterface) *CreateS
 // Accepts text/plain request body that contains a valid URL.
 // Maximal body size is defined with maxPayloadSize constant.
 // Responds with text/plain body that contains a valid short URL.
+// The short URL is created on the branded domain if the request is sent to its host.
 func (create CreateShortURLHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	if contentType := request.Header.Get("Content-Type"); !(strings.Contains(contentType, "text/plain") ||
 		strings.Contains(contentType, "application/x-gzip")) {
@@ -90,13 +125,22 @@ func (create CreateShortURLHandler) ServeHTTP(writer http.ResponseWriter, reques
 		http.Error(writer, "The provided payload is not a valid URL", http.StatusBadRequest)
 		return
 	}
-	userID := request.Header.Get(middlewares.UserIDHeaderName)
-	id, err := create.service.Create(request.Context(), payloadString, userID)
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	options := models.ShortURLOptions{Domain: domains.FromHost(request.Host)}
+	id, err := create.service.Create(request.Context(), payloadString, userID, options)
 	if err != nil {
 		if errors.Is(err, storage.ErrAlreadyExists) {
 			create.writeResponse(writer, http.StatusConflict, id)
 			return
 		}
+		if errors.Is(err, service.ErrDomainNotAllowed) {
+			http.Error(writer, "Short domain is not allowed", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, service.ErrUserBanned) {
+			http.Error(writer, err.Error(), http.StatusForbidden)
+			return
+		}
 		logger.Log.Warnf("Failed to create short URL %v", err)
 		http.Error(writer, "Couldn't create short url", http.StatusBadRequest)
 		return
@@ -126,28 +170,559 @@ func NewRedirectToOriginalURLHandler(service service.ShortURLServiceInterface) *
 }
 
 // ServeHTTP Serves as handler function. Extracts the original URL from the storage using passed short URL,
-// then responds with temporary redirection to the extracted URL.
+// then responds with redirection to the extracted URL, or with the warning page if the short URL requires it.
+// The status of the redirect and the caching headers are chosen per short URL, the server defaults are used otherwise.
+// The protected short URL is followed only if the password is passed in the header, the password prompt is shown otherwise.
+// The query and the path suffix of the request are forwarded to the destination if the short URL allows it.
+// The destination is chosen by the country of the visitor resolved from the address by the GeoIP database,
+// then by the platform detected from the User-Agent header if the short URL has such rules, the original URL is the fallback.
+// The traffic of the short URL with the split variants is split by their weights instead of the original URL,
+// the visitor keeps the assigned variant in the cookie and the click on it is counted.
+// Every redirect by the click-limited short URL takes one of its clicks, it is gone once there are none left.
 func (redirect RedirectToOriginalURLHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	shortURL, ok := resolveShortURL(redirect.service, writer, request)
+	if !ok {
+		return
+	}
+	destination, variant, ok := resolveDestination(writer, request, shortURL)
+	if !ok {
+		return
+	}
+	if shortURL.Protected() {
+		password := request.Header.Get(PasswordHeader)
+		if password == "" {
+			writePasswordPrompt(writer, http.StatusOK, shortURL, submitURL(request), "")
+			return
+		}
+		if !checkPassword(redirect.service, writer, request, shortURL, password, false) {
+			return
+		}
+	}
+	if !useClick(redirect.service, writer, request, shortURL) {
+		return
+	}
+	assignVariant(redirect.service, writer, shortURL, variant)
+	followShortURL(writer, request, shortURL, destination, redirectStatus(shortURL))
+}
+
+// UnlockShortURLHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to follow the protected short URL with the password submitted from the prompt page.
+type UnlockShortURLHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewUnlockShortURLHandler is a constructor function that returns a pointer
+// to the freshly created UnlockShortURLHandler structure.
+func NewUnlockShortURLHandler(service service.ShortURLServiceInterface) *UnlockShortURLHandler {
+	return &UnlockShortURLHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function. Checks the password submitted as the form value,
+// then redirects the visitor to the original URL, or shows the prompt page again with the error.
+func (unlock UnlockShortURLHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	shortURL, ok := resolveShortURL(unlock.service, writer, request)
+	if !ok {
+		return
+	}
+	destination, variant, ok := resolveDestination(writer, request, shortURL)
+	if !ok {
+		return
+	}
+	request.Body = http.MaxBytesReader(writer, request.Body, maxPayloadSize)
+	password := request.PostFormValue("password")
+	if !checkPassword(unlock.service, writer, request, shortURL, password, true) {
+		return
+	}
+	if !useClick(unlock.service, writer, request, shortURL) {
+		return
+	}
+	assignVariant(unlock.service, writer, shortURL, variant)
+	followShortURL(writer, request, shortURL, destination, http.StatusSeeOther)
+}
+
+// PreviewShortURLHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to show where the short URL leads to without redirecting.
+type PreviewShortURLHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewPreviewShortURLHandler is a constructor function that returns a pointer
+// to the freshly created PreviewShortURLHandler structure.
+func NewPreviewShortURLHandler(service service.ShortURLServiceInterface) *PreviewShortURLHandler {
+	return &PreviewShortURLHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function. Responds with the HTML page showing the destination and the title
+// of the short URL along with the button to continue. The destination of the click-limited short URL is not shown:
+// the preview takes no click, so it would let the destination be read any number of times.
+func (preview PreviewShortURLHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	shortURL, ok := resolveShortURL(preview.service, writer, request)
+	if !ok {
+		return
+	}
+	if shortURL.Protected() {
+		writePasswordPrompt(writer, http.StatusOK, shortURL, domains.ShortURL(shortURL.ShortURL), "")
+		return
+	}
+	link := newPageLink(shortURL)
+	if shortURL.ClickLimited() {
+		link.Destination = ""
+	}
+	writePage(writer, pages.WritePreview, link)
+}
+
+// QRCodeHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to render the QR code image of the short URL.
+type QRCodeHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewQRCodeHandler is a constructor function that returns a pointer
+// to the freshly created QRCodeHandler structure.
+func NewQRCodeHandler(service service.ShortURLServiceInterface) *QRCodeHandler {
+	return &QRCodeHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function. Responds with the QR code image of the full short URL.
+// The format (png or svg), the size in pixels and the error correction level (L, M, Q or H) are passed in the query,
+// the omitted ones get the default values. Responds with 304 if the image matches the ETag the client already has.
+// The short URL is looked up on the domain the request is sent to.
+func (qrCode QRCodeHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	id := request.PathValue("id")
 	if id == "" {
 		http.Error(writer, "Please provide the short url ID", http.StatusBadRequest)
 		return
 	}
-	originalURL, deleted, err := redirect.service.Read(request.Context(), id)
+	id = hostKey(request, id)
+	query := request.URL.Query()
+	options := models.QROptions{Format: query.Get("format"), Level: query.Get("level")}
+	if size := query.Get("size"); size != "" {
+		var err error
+		if options.Size, err = strconv.Atoi(size); err != nil {
+			http.Error(writer, "Invalid size", http.StatusBadRequest)
+			return
+		}
+	}
+	result, err := qrCode.service.GetQRCode(request.Context(), id, options)
 	if err != nil {
-		if errors.Is(err, service.ErrShortURLNotFound) {
+		switch {
+		case errors.Is(err, service.ErrShortURLNotFound):
 			http.Error(writer, "Short url not found", http.StatusNotFound)
-			return
+		case errors.Is(err, service.ErrInvalidQROptions):
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrShortURLDisabled):
+			http.Error(writer, "Short url is disabled", http.StatusGone)
+		default:
+			logger.Log.Errorf("Error rendering QR code of %s: %s", id, err)
+			http.Error(writer, "Something went wrong", http.StatusInternalServerError)
 		}
-		http.Error(writer, "Something went wrong", http.StatusBadRequest)
 		return
 	}
-	if deleted {
+	writer.Header().Set("ETag", result.ETag)
+	writer.Header().Set("Cache-Control", qrCacheControl)
+	if etagMatches(request.Header.Get("If-None-Match"), result.ETag) {
+		writer.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writer.Header().Set("Content-Type", result.ContentType)
+	writer.Header().Set("Content-Length", strconv.Itoa(len(result.Image)))
+	writer.WriteHeader(http.StatusOK)
+	_, err = writer.Write(result.Image)
+	if err != nil {
+		logger.Log.Errorf("Error writing response: %s", err)
+	}
+}
+
+// etagMatches reports whether the If-None-Match header lists the ETag, compared weakly as the standard requires.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveShortURL reads the short URL passed in the path on the domain the request is sent to,
+// responding with the error if it can't be followed. The short URL is not found before its activation window,
+// or the placeholder page is shown if the server is configured so, and it is gone after the window
+// or once the click-limited short URL has no clicks left.
+func resolveShortURL(
+	shortURLService service.ShortURLServiceInterface, writer http.ResponseWriter, request *http.Request) (*models.ShortURL, bool) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the short url ID", http.StatusBadRequest)
+		return nil, false
+	}
+	id = hostKey(request, id)
+	shortURL, err := shortURLService.Resolve(request.Context(), id)
+	if err != nil {
+		var notActiveYetErr *service.ErrNotActiveYetExtended
+		switch {
+		case errors.Is(err, service.ErrShortURLNotFound):
+			http.Error(writer, "Short url not found", http.StatusNotFound)
+		case errors.As(err, &notActiveYetErr) && config.Settings.ScheduledPlaceholder:
+			scheduled := pages.Scheduled{ShortURL: domains.ShortURL(id), ActiveFrom: notActiveYetErr.ActiveFrom}
+			if pageErr := pages.WriteScheduled(writer, scheduled); pageErr != nil {
+				logger.Log.Errorf("Error rendering page: %s", pageErr)
+				http.Error(writer, "Something went wrong", http.StatusInternalServerError)
+			}
+		case errors.Is(err, service.ErrNotActiveYet):
+			http.Error(writer, "Short url not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrNoLongerActive), errors.Is(err, service.ErrClicksExhausted):
+			writer.WriteHeader(http.StatusGone)
+		case errors.Is(err, service.ErrShortURLDisabled):
+			http.Error(writer, "Short url is disabled", http.StatusGone)
+		default:
+			http.Error(writer, "Something went wrong", http.StatusBadRequest)
+		}
+		return nil, false
+	}
+	if shortURL.Deleted {
 		writer.WriteHeader(http.StatusGone)
+		return nil, false
+	}
+	return shortURL, true
+}
+
+// hostKey returns the key of the short URL with the ID on the domain the request is sent to,
+// the requests sent to the hosts other than the branded ones are served on the default domain.
+func hostKey(request *http.Request, id string) string {
+	return domains.Key(domains.FromHost(request.Host), id)
+}
+
+// queryKey returns the key of the short URL with the ID on the domain passed in the query,
+// the short URL is on the default domain if the domain is omitted.
+func queryKey(request *http.Request, id string) string {
+	domain := request.URL.Query().Get("domain")
+	if domains.IsDefault(domain) {
+		return id
+	}
+	return domains.Key(strings.ToLower(domain), id)
+}
+
+// useClick takes one of the clicks left for the click-limited short URL, responding with the error if there are none.
+func useClick(
+	shortURLService service.ShortURLServiceInterface, writer http.ResponseWriter, request *http.Request,
+	shortURL *models.ShortURL) bool {
+	if !shortURL.ClickLimited() {
+		return true
+	}
+	err := shortURLService.UseClick(request.Context(), shortURL)
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, service.ErrClicksExhausted) {
+		writer.WriteHeader(http.StatusGone)
+		return false
+	}
+	logger.Log.Errorf("Error using the click on %s: %s", shortURL.ShortURL, err)
+	http.Error(writer, "Something went wrong", http.StatusInternalServerError)
+	return false
+}
+
+// resolveDestination returns the URL the visitor of the short URL is redirected to along with the split variant
+// it comes from, responding with the error if the request can't be forwarded.
+func resolveDestination(
+	writer http.ResponseWriter, request *http.Request, shortURL *models.ShortURL) (string, *models.SplitVariant, bool) {
+	target, variant := targetURL(request, shortURL)
+	destination, err := destinationURL(request, shortURL, target)
+	if err != nil {
+		if errors.Is(err, errSuffixNotAllowed) {
+			http.Error(writer, "Short url not found", http.StatusNotFound)
+			return "", nil, false
+		}
+		http.Error(writer, "Invalid path", http.StatusBadRequest)
+		return "", nil, false
+	}
+	return destination, variant, true
+}
+
+// destinationURL adds the parameters of the UTM template to the target chosen for the visitor,
+// then applies the passthrough mode of the short URL to the request. The query of the request is merged into the destination query, the parameters
+// of the destination win. The path suffix is appended to the destination path, the query of the request is ignored then.
+// The path suffix is not found unless the mode allows it.
+func destinationURL(request *http.Request, shortURL *models.ShortURL, target string) (string, error) {
+	destination, err := utmDestination(target, shortURL.UTM)
+	if err != nil {
+		return "", err
+	}
+	suffix := pathSuffix(request)
+	switch shortURL.Passthrough {
+	case models.PassthroughPath:
+		return utils.AppendPath(destination, suffix)
+	case models.PassthroughQuery:
+		if suffix == "" {
+			return utils.MergeQuery(destination, request.URL.RawQuery)
+		}
+	default:
+		if suffix == "" {
+			return destination, nil
+		}
+	}
+	return "", errSuffixNotAllowed
+}
+
+// targetURL returns the URL of the geo rule of the short URL for the country of the visitor, then the URL
+// of the first targeting rule matching the platforms of the visitor, then the URL of the split variant
+// the visitor is assigned to, or the original URL if there is none. The variant is returned only if its URL is chosen.
+func targetURL(request *http.Request, shortURL *models.ShortURL) (string, *models.SplitVariant) {
+	if len(shortURL.GeoRules) > 0 {
+		if country := visitorCountry(request); country != "" {
+			for _, rule := range shortURL.GeoRules {
+				if rule.Country == country {
+					return rule.URL, nil
+				}
+			}
+		}
+	}
+	if len(shortURL.Targets) > 0 {
+		platforms := utils.DetectPlatforms(request.UserAgent())
+		for _, rule := range shortURL.Targets {
+			if slices.Contains(platforms, rule.Platform) {
+				return rule.URL, nil
+			}
+		}
+	}
+	if variant := splitVariant(request, shortURL); variant != nil {
+		return variant.URL, variant
+	}
+	return shortURL.OriginalURL, nil
+}
+
+// splitVariant returns the split variant of the short URL the visitor is assigned to by the cookie,
+// or picks the new one at random by the weights. Returns nil if the traffic of the short URL isn't split.
+func splitVariant(request *http.Request, shortURL *models.ShortURL) *models.SplitVariant {
+	if len(shortURL.Variants) == 0 {
+		return nil
+	}
+	if cookie, err := request.Cookie(splitCookieName(shortURL)); err == nil {
+		if variant := shortURL.Variant(cookie.Value); variant != nil {
+			return variant
+		}
+	}
+	total := 0
+	for _, variant := range shortURL.Variants {
+		total += variant.Weight
+	}
+	return pickVariant(shortURL.Variants, rand.Intn(total))
+}
+
+// pickVariant returns the variant the roll from the range [0, sum of the weights) falls on.
+func pickVariant(variants []models.SplitVariant, roll int) *models.SplitVariant {
+	for i := range variants {
+		if roll < variants[i].Weight {
+			return &variants[i]
+		}
+		roll -= variants[i].Weight
+	}
+	return &variants[len(variants)-1]
+}
+
+// assignVariant keeps the split variant chosen for the visitor in the cookie scoped to the short URL
+// and counts the click on it. Nothing happens if the variant isn't chosen.
+func assignVariant(
+	shortURLService service.ShortURLServiceInterface, writer http.ResponseWriter, shortURL *models.ShortURL,
+	variant *models.SplitVariant) {
+	if variant == nil {
+		return
+	}
+	http.SetCookie(writer, &http.Cookie{
+		Name:     splitCookieName(shortURL),
+		Value:    variant.Name,
+		Path:     "/" + shortURLID(shortURL),
+		MaxAge:   config.Settings.SplitCookieMaxAgeSeconds,
+		Secure:   config.Settings.TLSEnabled,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	shortURLService.RecordClick(shortURL.ShortURL, variant.Name)
+}
+
+// splitCookieName returns the name of the cookie the split variant of the short URL is kept in.
+func splitCookieName(shortURL *models.ShortURL) string {
+	return splitCookiePrefix + shortURLID(shortURL)
+}
+
+// shortURLID returns the ID of the short URL without its domain, which is how it appears in the path.
+func shortURLID(shortURL *models.ShortURL) string {
+	_, id := domains.Split(shortURL.ShortURL)
+	return id
+}
+
+// visitorCountry returns the country of the visitor resolved from the address by the GeoIP database,
+// the empty string if the database isn't configured or has no record for it.
+func visitorCountry(request *http.Request) string {
+	ip, err := middlewares.ResolveIP(request)
+	if err != nil {
+		return ""
+	}
+	return geoip.DB.Country(ip)
+}
+
+// utmDestination returns the destination with the parameters of the UTM template of the short URL.
+// The parameters already present in the destination win, so the stored URL is never changed.
+func utmDestination(destination string, utm *models.UTMParameters) (string, error) {
+	if utm == nil {
+		return destination, nil
+	}
+	return utils.MergeQuery(destination, utm.RawQuery())
+}
+
+// pathSuffix returns the escaped part of the request path after the short URL ID.
+func pathSuffix(request *http.Request) string {
+	_, suffix, _ := strings.Cut(strings.TrimPrefix(request.URL.EscapedPath(), "/"), "/")
+	return suffix
+}
+
+// submitURL returns the URL the password prompt is submitted to, so the passthrough is kept after the unlock.
+func submitURL(request *http.Request) string {
+	return domains.BaseURL(domains.FromHost(request.Host)) + strings.TrimPrefix(request.URL.RequestURI(), "/")
+}
+
+// followShortURL redirects the visitor to the destination with the status, or shows the warning page
+// if the short URL requires it.
+func followShortURL(
+	writer http.ResponseWriter, request *http.Request, shortURL *models.ShortURL, destination string, status int) {
+	writeRedirectHeaders(writer, shortURL, status)
+	if shortURL.Interstitial {
+		link := newPageLink(shortURL)
+		link.Destination = destination
+		writePage(writer, pages.WriteInterstitial, link)
 		return
 	}
+	http.Redirect(writer, request, destination, status)
+}
+
+// redirectStatus returns the status of the redirect chosen for the short URL or the server default.
+func redirectStatus(shortURL *models.ShortURL) int {
+	if shortURL.RedirectStatus != 0 {
+		return shortURL.RedirectStatus
+	}
+	if config.Settings.DefaultRedirectStatus != 0 {
+		return config.Settings.DefaultRedirectStatus
+	}
+	return http.StatusTemporaryRedirect
+}
+
+// writeRedirectHeaders sets the caching, referrer and indexing headers chosen for the short URL or the server defaults.
+// The permanent redirects are cached, the temporary ones are not, so every click reaches the server.
+// The redirect of the protected short URL is never cached regardless of the settings, since the cache would skip the password.
+// Neither is the one of the short URL with the split variants, since the cache would skip the assignment and the counting,
+// nor the one of the click-limited short URL, since the cache would skip the limit.
+// The permanent redirect isn't cached past the end of the activation window of the short URL.
+// The redirect of the targeted short URL varies by the User-Agent header, so the caches keep one per platform.
+// The permanent redirect of the short URL with geo rules is cached by the browser only, since the shared caches
+// can't tell the countries apart.
+func writeRedirectHeaders(writer http.ResponseWriter, shortURL *models.ShortURL, status int) {
+	cacheControl := shortURL.CacheControl
+	switch {
+	case shortURL.Protected() || len(shortURL.Variants) > 0 || shortURL.ClickLimited():
+		cacheControl = "no-store"
+	case cacheControl != "":
+	case status == http.StatusMovedPermanently || status == http.StatusPermanentRedirect:
+		visibility := "public"
+		if len(shortURL.GeoRules) > 0 {
+			visibility = "private"
+		}
+		cacheControl = visibility + ", max-age=" + strconv.FormatInt(permanentMaxAge(shortURL, time.Now()), 10)
+	default:
+		cacheControl = "no-store"
+	}
+	writer.Header().Set("Cache-Control", cacheControl)
+	if len(shortURL.Targets) > 0 {
+		writer.Header().Add("Vary", "User-Agent")
+	}
+	setHeader(writer, "Referrer-Policy", shortURL.ReferrerPolicy, config.Settings.DefaultReferrerPolicy)
+	setHeader(writer, "X-Robots-Tag", shortURL.RobotsTag, config.Settings.DefaultRobotsTag)
+}
+
+// permanentMaxAge returns the time the permanent redirect is cached for in seconds. The server default is cut
+// to the end of the activation window of the short URL, so the cache never outlives it.
+func permanentMaxAge(shortURL *models.ShortURL, now time.Time) int64 {
+	maxAge := config.Settings.PermanentRedirectMaxAgeSeconds
+	activeUntil, err := time.Parse(time.RFC3339, shortURL.ActiveUntil)
+	if err != nil {
+		return maxAge
+	}
+	return max(0, min(maxAge, int64(activeUntil.Sub(now)/time.Second)))
+}
+
+// setHeader sets the header to the value or to the fallback one if the value is empty. Nothing is set if both are empty.
+func setHeader(writer http.ResponseWriter, key string, value string, fallback string) {
+	if value == "" {
+		value = fallback
+	}
+	if value != "" {
+		writer.Header().Set(key, value)
+	}
+}
+
+// checkPassword checks the password of the protected short URL, responding with the error if it can't be followed.
+// The visitors submitting the form get the prompt page with the error, the API clients get the plain text one.
+func checkPassword(
+	shortURLService service.ShortURLServiceInterface, writer http.ResponseWriter, request *http.Request,
+	shortURL *models.ShortURL, password string, prompt bool) bool {
+	err := shortURLService.CheckPassword(shortURL, password, clientIP(request))
+	if err == nil {
+		return true
+	}
+	var tooManyAttemptsErr *service.ErrTooManyAttemptsExtended
+	var status int
+	var message string
+	switch {
+	case errors.Is(err, service.ErrWrongPassword):
+		status, message = http.StatusForbidden, "Wrong password"
+	case errors.As(err, &tooManyAttemptsErr):
+		writer.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(tooManyAttemptsErr.RetryAfter.Seconds()))))
+		status, message = http.StatusTooManyRequests, "Too many password attempts, try again later"
+	default:
+		logger.Log.Errorf("Error checking password: %s", err)
+		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
+		return false
+	}
+	if prompt {
+		writePasswordPrompt(writer, status, shortURL, submitURL(request), message)
+	} else {
+		http.Error(writer, message, status)
+	}
+	return false
+}
+
+// clientIP returns the address of the visitor used to limit the password attempts.
+func clientIP(request *http.Request) string {
+	if ip, err := middlewares.ResolveIP(request); err == nil {
+		return ip.String()
+	}
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return host
+}
+
+func writePasswordPrompt(writer http.ResponseWriter, status int, shortURL *models.ShortURL, action string, message string) {
+	prompt := pages.PasswordPrompt{ShortURL: domains.ShortURL(shortURL.ShortURL), Action: action, Error: message}
+	if err := pages.WritePasswordPrompt(writer, status, prompt); err != nil {
+		logger.Log.Errorf("Error rendering page: %s", err)
+		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
+	}
+}
+
+func newPageLink(shortURL *models.ShortURL) pages.Link {
+	return pages.Link{
+		ShortURL:    domains.ShortURL(shortURL.ShortURL),
+		Destination: shortURL.OriginalURL,
+		Title:       shortURL.DisplayTitle(),
+	}
+}
 
-	http.Redirect(writer, request, originalURL, http.StatusTemporaryRedirect)
+func writePage(writer http.ResponseWriter, write func(http.ResponseWriter, pages.Link) error, link pages.Link) {
+	if err := write(writer, link); err != nil {
+		logger.Log.Errorf("Error rendering page: %s", err)
+		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
+	}
 }
 
 // CreateJSONShortURLHandler is a structure to store dependencies and
@@ -193,13 +768,25 @@ func (create CreateJSONShortURLHandler) ServeHTTP(writer http.ResponseWriter, re
 		http.Error(writer, "The provided payload is not a valid URL", http.StatusBadRequest)
 		return
 	}
-	userID := request.Header.Get(middlewares.UserIDHeaderName)
-	id, err := create.service.Create(request.Context(), requestData.URL, userID)
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	id, err := create.service.Create(request.Context(), requestData.URL, userID, requestData.ShortURLOptions)
 	if err != nil {
 		if errors.Is(err, storage.ErrAlreadyExists) {
 			create.writeResponse(writer, http.StatusConflict, id)
 			return
 		}
+		if errors.Is(err, service.ErrInvalidOptions) {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, service.ErrDomainNotAllowed) {
+			http.Error(writer, "Short domain is not allowed", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, service.ErrOrgForbidden) || errors.Is(err, service.ErrUserBanned) {
+			http.Error(writer, err.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(writer, "Couldn't create short url", http.StatusBadRequest)
 		return
 	}
@@ -285,9 +872,21 @@ func (create BatchCreateShortURLHandler) ServeHTTP(writer http.ResponseWriter, r
 			return
 		}
 	}
-	userID := request.Header.Get(middlewares.UserIDHeaderName)
+	userID, _ := middlewares.UserIDFromContext(request.Context())
 	results, err := create.service.BatchCreate(request.Context(), requestData, userID)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidOptions) {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, service.ErrDomainNotAllowed) {
+			http.Error(writer, "Short domain is not allowed", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, service.ErrOrgForbidden) || errors.Is(err, service.ErrUserBanned) {
+			http.Error(writer, err.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(writer, "Couldn't create short url", http.StatusBadRequest)
 		return
 	}
@@ -314,6 +913,8 @@ func NewGetAllURLsForUserHandler(service service.ShortURLServiceInterface) *GetA
 
 // ServeHTTP Serves as handler function.
 // Responds with a JSON which is a list of models.ShortURLsByUserResponse objects.
+// The list might be filtered by the tag passed as the "tag" query parameter and by the ID of the campaign
+// passed as the "campaign" query parameter.
 func (getHandler GetAllURLsForUserHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -322,8 +923,13 @@ func (getHandler GetAllURLsForUserHandler) ServeHTTP(writer http.ResponseWriter,
 			http.Error(writer, "Error closing body", http.StatusInternalServerError)
 		}
 	}(request.Body)
-	userID := request.Header.Get(middlewares.UserIDHeaderName)
-	results, err := getHandler.service.ReadByUserID(request.Context(), userID)
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	filter := models.ShortURLFilter{Tag: request.URL.Query().Get("tag"), CampaignID: request.URL.Query().Get("campaign")}
+	results, err := getHandler.service.ReadByUserID(request.Context(), userID, filter)
+	if errors.Is(err, service.ErrCampaignNotFound) {
+		http.Error(writer, "Campaign not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(writer, "Couldn't read all the urls for user", http.StatusBadRequest)
 		return
@@ -341,23 +947,24 @@ func (getHandler GetAllURLsForUserHandler) ServeHTTP(writer http.ResponseWriter,
 	}
 }
 
-// DeleteBatchOfURLsHandler is a structure to store dependencies and
-// implement ServeHTTP Handler function to delete a batch of URLs created by authorized user.
-type DeleteBatchOfURLsHandler struct {
+// UpdateShortURLHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to change the title, notes, tags and redirect attributes of the URL
+// created by authorized user.
+type UpdateShortURLHandler struct {
 	service service.ShortURLServiceInterface
 }
 
-// NewDeleteBatchOfURLsHandler is a constructor function that returns a pointer
-// to the freshly created DeleteBatchOfURLsHandler structure.
-func NewDeleteBatchOfURLsHandler(service service.ShortURLServiceInterface) *DeleteBatchOfURLsHandler {
-	return &DeleteBatchOfURLsHandler{service: service}
+// NewUpdateShortURLHandler is a constructor function that returns a pointer
+// to the freshly created UpdateShortURLHandler structure.
+func NewUpdateShortURLHandler(service service.ShortURLServiceInterface) *UpdateShortURLHandler {
+	return &UpdateShortURLHandler{service: service}
 }
 
 // ServeHTTP Serves as handler function.
-// Accepts the JSON-formatted list of short URL IDs, that should be deleted.
-// Schedules the deletion of passed URLs. The URL will be deleted in some time after the response (not instantly).
-// The URL will be deleted if it was created by the same user that tries to delete it.
-func (delete DeleteBatchOfURLsHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+// Accepts the JSON specified in models.UpdateShortURLRequest, fields omitted in JSON are left unchanged.
+// Responds with a JSON document, specified in models.ShortURLsByUserResponse, which is the updated short URL.
+// The short URL of the branded domain is updated if the domain is passed in the query.
+func (update UpdateShortURLHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	if contentType := request.Header.Get("Content-Type"); !strings.Contains(contentType, "application/json") {
 		http.Error(writer, "Only application/json content type is allowed", http.StatusBadRequest)
 		return
@@ -365,63 +972,1518 @@ func (delete DeleteBatchOfURLsHandler) ServeHTTP(writer http.ResponseWriter, req
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
 		if err != nil {
-			logger.Log.Errorf("Error encoding response: %s", err)
-			http.Error(writer, "Error encoding response body", http.StatusInternalServerError)
+			logger.Log.Debugf("Error closing body: %s", err)
+			http.Error(writer, "Error closing body", http.StatusInternalServerError)
 		}
 	}(request.Body)
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the short url ID", http.StatusBadRequest)
+		return
+	}
+	id = queryKey(request, id)
 
-	var requestData []string
+	var requestData models.UpdateShortURLRequest
 	dec := json.NewDecoder(request.Body)
 	if err := dec.Decode(&requestData); err != nil {
 		logger.Log.Debugf("Couldn't decode the request body: %s", err)
 		writer.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	if len(requestData) == 0 {
-		http.Error(writer, "Please provide a batch of shortURLs", http.StatusBadRequest)
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	result, err := update.service.Update(request.Context(), id, userID, requestData)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrShortURLNotFound):
+			http.Error(writer, "Short url not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrForbidden):
+			http.Error(writer, "Short url belongs to another user", http.StatusForbidden)
+		case errors.Is(err, service.ErrUserBanned):
+			http.Error(writer, err.Error(), http.StatusForbidden)
+		case errors.Is(err, service.ErrInvalidOptions):
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+		default:
+			logger.Log.Warnf("Failed to update short URL %v", err)
+			http.Error(writer, "Couldn't update short url", http.StatusBadRequest)
+		}
 		return
 	}
-
-	userID := request.Header.Get(middlewares.UserIDHeaderName)
-	requestPrepared := make([]models.ShortURLChannelMessage, len(requestData))
-	for i, requestItem := range requestData {
-		requestPrepared[i] = models.ShortURLChannelMessage{
-			Ctx:      request.Context(),
-			ShortURL: requestItem,
-			UserID:   userID,
-		}
+	writer.Header().Add("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(writer)
+	if err = enc.Encode(result); err != nil {
+		logger.Log.Debugf("Error encoding response: %s", err)
+		return
 	}
-	go delete.service.ScheduleDeletionOfBatch(requestPrepared)
-	writer.WriteHeader(http.StatusAccepted)
 }
 
-// GetStatsHandler is a structure to store dependencies and
-// implement ServeHTTP Handler function to return statistics of created users and short URLs in the service.
-type GetStatsHandler struct {
+// GetShortURLStatsHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to return the clicks on the split variants of the URL created by authorized user.
+type GetShortURLStatsHandler struct {
 	service service.ShortURLServiceInterface
 }
 
-// NewGetStatsHandler is a constructor function that returns a pointer
-// to the freshly created GetStatsHandler structure.
-func NewGetStatsHandler(service service.ShortURLServiceInterface) *GetStatsHandler {
-	return &GetStatsHandler{service: service}
+// NewGetShortURLStatsHandler is a constructor function that returns a pointer
+// to the freshly created GetShortURLStatsHandler structure.
+func NewGetShortURLStatsHandler(service service.ShortURLServiceInterface) *GetShortURLStatsHandler {
+	return &GetShortURLStatsHandler{service: service}
 }
 
 // ServeHTTP Serves as handler function.
-// Returns the JSON, representing the total number of users and short URLs created in the service.
-func (stats GetStatsHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	statistic, err := stats.service.GetStats(request.Context())
+// Responds with a JSON document, specified in models.ShortURLStats.
+// The short URL of the branded domain is read if the domain is passed in the query.
+func (stats GetShortURLStatsHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the short url ID", http.StatusBadRequest)
+		return
+	}
+	id = queryKey(request, id)
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	result, err := stats.service.GetShortURLStats(request.Context(), id, userID)
 	if err != nil {
-		logger.Log.Debugf("Error getting statistics: %s", err)
-		http.Error(writer, "Couldn't get service statistics", http.StatusBadRequest)
+		switch {
+		case errors.Is(err, service.ErrShortURLNotFound):
+			http.Error(writer, "Short url not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrForbidden):
+			http.Error(writer, "Short url belongs to another user", http.StatusForbidden)
+		default:
+			logger.Log.Warnf("Failed to read short URL stats %v", err)
+			http.Error(writer, "Couldn't read short url stats", http.StatusBadRequest)
+		}
 		return
 	}
-	enc := json.NewEncoder(writer)
-	writer.Header().Add("Content-Type", "application/json")
-	writer.WriteHeader(http.StatusOK)
-	if encErr := enc.Encode(statistic); encErr != nil {
-		logger.Log.Debugf("Error encoding response: %s", encErr)
-		http.Error(writer, "Couldn't get service statistics", http.StatusBadRequest)
+	writeJSON(writer, http.StatusOK, result)
+}
+
+// CreateUTMTemplateHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to create the UTM template owned by authorized user.
+type CreateUTMTemplateHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewCreateUTMTemplateHandler is a constructor function that returns a pointer
+// to the freshly created CreateUTMTemplateHandler structure.
+func NewCreateUTMTemplateHandler(service service.ShortURLServiceInterface) *CreateUTMTemplateHandler {
+	return &CreateUTMTemplateHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON specified in models.UTMTemplate without the ID.
+// Responds with a JSON document, specified in models.UTMTemplate, which is the created template.
+// The ID of the template is passed as utm_template_id when the short URL is created or updated.
+func (create CreateUTMTemplateHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	requestData, ok := decodeUTMTemplate(writer, request)
+	if !ok {
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	result, err := create.service.CreateUTMTemplate(request.Context(), userID, requestData)
+	if err != nil {
+		writeUTMTemplateError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusCreated, result)
+}
+
+// GetUTMTemplatesHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to return all the UTM templates created by authorized user.
+type GetUTMTemplatesHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewGetUTMTemplatesHandler is a constructor function that returns a pointer
+// to the freshly created GetUTMTemplatesHandler structure.
+func NewGetUTMTemplatesHandler(service service.ShortURLServiceInterface) *GetUTMTemplatesHandler {
+	return &GetUTMTemplatesHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Responds with a JSON which is a list of models.UTMTemplate objects.
+func (getHandler GetUTMTemplatesHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	results, err := getHandler.service.ReadUTMTemplatesByUserID(request.Context(), userID)
+	if err != nil {
+		http.Error(writer, "Couldn't read all the utm templates for user", http.StatusBadRequest)
+		return
+	}
+	if len(results) == 0 {
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(writer, http.StatusOK, results)
+}
+
+// UpdateUTMTemplateHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to change the UTM template created by authorized user.
+type UpdateUTMTemplateHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewUpdateUTMTemplateHandler is a constructor function that returns a pointer
+// to the freshly created UpdateUTMTemplateHandler structure.
+func NewUpdateUTMTemplateHandler(service service.ShortURLServiceInterface) *UpdateUTMTemplateHandler {
+	return &UpdateUTMTemplateHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON specified in models.UTMTemplate, which replaces the name and all the parameters of the template.
+// Responds with a JSON document, specified in models.UTMTemplate, which is the updated template.
+// The short URLs using the template get the new parameters on the next redirect.
+func (update UpdateUTMTemplateHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the utm template ID", http.StatusBadRequest)
+		return
+	}
+	requestData, ok := decodeUTMTemplate(writer, request)
+	if !ok {
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	result, err := update.service.UpdateUTMTemplate(request.Context(), id, userID, requestData)
+	if err != nil {
+		writeUTMTemplateError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusOK, result)
+}
+
+// DeleteUTMTemplateHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to delete the UTM template created by authorized user.
+type DeleteUTMTemplateHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewDeleteUTMTemplateHandler is a constructor function that returns a pointer
+// to the freshly created DeleteUTMTemplateHandler structure.
+func NewDeleteUTMTemplateHandler(service service.ShortURLServiceInterface) *DeleteUTMTemplateHandler {
+	return &DeleteUTMTemplateHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Deletes the template, the short URLs using it are redirected without the UTM parameters afterward.
+// Responds with no content.
+func (delete DeleteUTMTemplateHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the utm template ID", http.StatusBadRequest)
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	if err := delete.service.DeleteUTMTemplate(request.Context(), id, userID); err != nil {
+		writeUTMTemplateError(writer, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// decodeUTMTemplate decodes the UTM template passed as JSON, responding with the error if it can't be decoded.
+func decodeUTMTemplate(writer http.ResponseWriter, request *http.Request) (models.UTMTemplate, bool) {
+	var requestData models.UTMTemplate
+	if contentType := request.Header.Get("Content-Type"); !strings.Contains(contentType, "application/json") {
+		http.Error(writer, "Only application/json content type is allowed", http.StatusBadRequest)
+		return requestData, false
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			logger.Log.Debugf("Error closing body: %s", err)
+		}
+	}(request.Body)
+	dec := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxPayloadSize))
+	if err := dec.Decode(&requestData); err != nil {
+		logger.Log.Debugf("Couldn't decode the request body: %s", err)
+		writer.WriteHeader(http.StatusBadRequest)
+		return requestData, false
+	}
+	return requestData, true
+}
+
+func writeUTMTemplateError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrUTMTemplateNotFound):
+		http.Error(writer, "Utm template not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidUTMTemplate):
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Log.Warnf("Failed to change utm template %v", err)
+		http.Error(writer, "Couldn't change utm template", http.StatusBadRequest)
+	}
+}
+
+// CreateCampaignHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to create the campaign owned by authorized user.
+type CreateCampaignHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewCreateCampaignHandler is a constructor function that returns a pointer
+// to the freshly created CreateCampaignHandler structure.
+func NewCreateCampaignHandler(service service.ShortURLServiceInterface) *CreateCampaignHandler {
+	return &CreateCampaignHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON specified in models.Campaign without the ID.
+// Responds with a JSON document, specified in models.Campaign, which is the created campaign.
+// The ID of the campaign is passed as campaign_id when the short URL is created or updated.
+func (create CreateCampaignHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	requestData, ok := decodeCampaign(writer, request)
+	if !ok {
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	result, err := create.service.CreateCampaign(request.Context(), userID, requestData)
+	if err != nil {
+		writeCampaignError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusCreated, result)
+}
+
+// GetCampaignsHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to return all the campaigns created by authorized user.
+type GetCampaignsHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewGetCampaignsHandler is a constructor function that returns a pointer
+// to the freshly created GetCampaignsHandler structure.
+func NewGetCampaignsHandler(service service.ShortURLServiceInterface) *GetCampaignsHandler {
+	return &GetCampaignsHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Responds with a JSON which is a list of models.Campaign objects.
+// The short URLs of the campaign are listed by /api/user/urls with the "campaign" query parameter.
+func (getHandler GetCampaignsHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	results, err := getHandler.service.ReadCampaignsByUserID(request.Context(), userID)
+	if err != nil {
+		http.Error(writer, "Couldn't read all the campaigns for user", http.StatusBadRequest)
+		return
+	}
+	if len(results) == 0 {
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(writer, http.StatusOK, results)
+}
+
+// UpdateCampaignHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to rename the campaign created by authorized user.
+type UpdateCampaignHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewUpdateCampaignHandler is a constructor function that returns a pointer
+// to the freshly created UpdateCampaignHandler structure.
+func NewUpdateCampaignHandler(service service.ShortURLServiceInterface) *UpdateCampaignHandler {
+	return &UpdateCampaignHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON specified in models.Campaign, which replaces the name of the campaign.
+// Responds with a JSON document, specified in models.Campaign, which is the updated campaign.
+func (update UpdateCampaignHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the campaign ID", http.StatusBadRequest)
+		return
+	}
+	requestData, ok := decodeCampaign(writer, request)
+	if !ok {
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	result, err := update.service.UpdateCampaign(request.Context(), id, userID, requestData)
+	if err != nil {
+		writeCampaignError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusOK, result)
+}
+
+// DeleteCampaignHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to delete the campaign created by authorized user together with its URLs.
+type DeleteCampaignHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewDeleteCampaignHandler is a constructor function that returns a pointer
+// to the freshly created DeleteCampaignHandler structure.
+func NewDeleteCampaignHandler(service service.ShortURLServiceInterface) *DeleteCampaignHandler {
+	return &DeleteCampaignHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Deletes the campaign and schedules the deletion of its short URLs, which will be deleted in some time
+// after the response (not instantly). Responds with the 202 status code.
+func (delete DeleteCampaignHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the campaign ID", http.StatusBadRequest)
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	if err := delete.service.DeleteCampaign(request.Context(), id, userID); err != nil {
+		writeCampaignError(writer, err)
+		return
+	}
+	writer.WriteHeader(http.StatusAccepted)
+}
+
+// GetCampaignStatsHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to return the totals of the campaign created by authorized user.
+type GetCampaignStatsHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewGetCampaignStatsHandler is a constructor function that returns a pointer
+// to the freshly created GetCampaignStatsHandler structure.
+func NewGetCampaignStatsHandler(service service.ShortURLServiceInterface) *GetCampaignStatsHandler {
+	return &GetCampaignStatsHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Responds with a JSON document, specified in models.CampaignStats, which is the amount of the active short URLs
+// in the campaign and the clicks counted on their split variants.
+func (getHandler GetCampaignStatsHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the campaign ID", http.StatusBadRequest)
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	result, err := getHandler.service.GetCampaignStats(request.Context(), id, userID)
+	if err != nil {
+		writeCampaignError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusOK, result)
+}
+
+// decodeCampaign decodes the campaign passed as JSON, responding with the error if it can't be decoded.
+func decodeCampaign(writer http.ResponseWriter, request *http.Request) (models.Campaign, bool) {
+	var requestData models.Campaign
+	if contentType := request.Header.Get("Content-Type"); !strings.Contains(contentType, "application/json") {
+		http.Error(writer, "Only application/json content type is allowed", http.StatusBadRequest)
+		return requestData, false
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			logger.Log.Debugf("Error closing body: %s", err)
+		}
+	}(request.Body)
+	dec := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxPayloadSize))
+	if err := dec.Decode(&requestData); err != nil {
+		logger.Log.Debugf("Couldn't decode the request body: %s", err)
+		writer.WriteHeader(http.StatusBadRequest)
+		return requestData, false
+	}
+	return requestData, true
+}
+
+func writeCampaignError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrCampaignNotFound):
+		http.Error(writer, "Campaign not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidCampaign):
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Log.Warnf("Failed to change campaign %v", err)
+		http.Error(writer, "Couldn't change campaign", http.StatusBadRequest)
+	}
+}
+
+// CreateOrganizationHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to create the organization owned by authorized user.
+type CreateOrganizationHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewCreateOrganizationHandler is a constructor function that returns a pointer
+// to the freshly created CreateOrganizationHandler structure.
+func NewCreateOrganizationHandler(service service.ShortURLServiceInterface) *CreateOrganizationHandler {
+	return &CreateOrganizationHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON specified in models.Organization with the name only.
+// Responds with a JSON document, specified in models.Organization, which is the created organization
+// with the authorized user as its owner. The ID of the organization is passed as org_id when the short URL is created.
+func (create CreateOrganizationHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	var requestData models.Organization
+	if !decodeOrgRequest(writer, request, &requestData) {
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	result, err := create.service.CreateOrganization(request.Context(), userID, requestData)
+	if err != nil {
+		writeOrganizationError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusCreated, result)
+}
+
+// GetOrganizationsHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to return all the organizations authorized user is a member of.
+type GetOrganizationsHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewGetOrganizationsHandler is a constructor function that returns a pointer
+// to the freshly created GetOrganizationsHandler structure.
+func NewGetOrganizationsHandler(service service.ShortURLServiceInterface) *GetOrganizationsHandler {
+	return &GetOrganizationsHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Responds with a JSON which is a list of models.Organization objects with the role of authorized user in each.
+func (getHandler GetOrganizationsHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	results, err := getHandler.service.ReadOrganizationsByUserID(request.Context(), userID)
+	if err != nil {
+		http.Error(writer, "Couldn't read all the organizations for user", http.StatusBadRequest)
+		return
+	}
+	if len(results) == 0 {
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(writer, http.StatusOK, results)
+}
+
+// GetOrgMembersHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to return the members of the organization authorized user is a member of.
+type GetOrgMembersHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewGetOrgMembersHandler is a constructor function that returns a pointer
+// to the freshly created GetOrgMembersHandler structure.
+func NewGetOrgMembersHandler(service service.ShortURLServiceInterface) *GetOrgMembersHandler {
+	return &GetOrgMembersHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Responds with a JSON which is a list of models.OrgMember objects in the order they joined.
+func (getHandler GetOrgMembersHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the organization ID", http.StatusBadRequest)
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	results, err := getHandler.service.ReadOrgMembers(request.Context(), id, userID)
+	if err != nil {
+		writeOrganizationError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusOK, results)
+}
+
+// AddOrgMemberHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to add the member to the organization managed by authorized user.
+type AddOrgMemberHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewAddOrgMemberHandler is a constructor function that returns a pointer
+// to the freshly created AddOrgMemberHandler structure.
+func NewAddOrgMemberHandler(service service.ShortURLServiceInterface) *AddOrgMemberHandler {
+	return &AddOrgMemberHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON specified in models.AddOrgMemberRequest, the member is the registered account with the email.
+// Responds with a JSON document, specified in models.OrgMember, which is the added member.
+func (add AddOrgMemberHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the organization ID", http.StatusBadRequest)
+		return
+	}
+	var requestData models.AddOrgMemberRequest
+	if !decodeOrgRequest(writer, request, &requestData) {
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	result, err := add.service.AddOrgMember(request.Context(), id, userID, requestData)
+	if err != nil {
+		writeOrganizationError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusCreated, result)
+}
+
+// UpdateOrgMemberHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to change the role of the member of the organization managed by authorized user.
+type UpdateOrgMemberHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewUpdateOrgMemberHandler is a constructor function that returns a pointer
+// to the freshly created UpdateOrgMemberHandler structure.
+func NewUpdateOrgMemberHandler(service service.ShortURLServiceInterface) *UpdateOrgMemberHandler {
+	return &UpdateOrgMemberHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON specified in models.OrgMember with the role only.
+// Responds with a JSON document, specified in models.OrgMember, which is the updated member.
+func (update UpdateOrgMemberHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id, memberID := request.PathValue("id"), request.PathValue("userID")
+	if id == "" || memberID == "" {
+		http.Error(writer, "Please provide the organization ID and the user ID", http.StatusBadRequest)
+		return
+	}
+	var requestData models.OrgMember
+	if !decodeOrgRequest(writer, request, &requestData) {
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	result, err := update.service.UpdateOrgMember(request.Context(), id, memberID, userID, requestData.Role)
+	if err != nil {
+		writeOrganizationError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusOK, result)
+}
+
+// RemoveOrgMemberHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to remove the member from the organization managed by authorized user
+// or to leave the organization.
+type RemoveOrgMemberHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewRemoveOrgMemberHandler is a constructor function that returns a pointer
+// to the freshly created RemoveOrgMemberHandler structure.
+func NewRemoveOrgMemberHandler(service service.ShortURLServiceInterface) *RemoveOrgMemberHandler {
+	return &RemoveOrgMemberHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Removes the member, the short URLs of the organization the member has created stay with the organization.
+// Responds with no content.
+func (remove RemoveOrgMemberHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id, memberID := request.PathValue("id"), request.PathValue("userID")
+	if id == "" || memberID == "" {
+		http.Error(writer, "Please provide the organization ID and the user ID", http.StatusBadRequest)
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	if err := remove.service.RemoveOrgMember(request.Context(), id, memberID, userID); err != nil {
+		writeOrganizationError(writer, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// GetOrgURLsHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to return all the URLs of the organization authorized user is a member of.
+type GetOrgURLsHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewGetOrgURLsHandler is a constructor function that returns a pointer
+// to the freshly created GetOrgURLsHandler structure.
+func NewGetOrgURLsHandler(service service.ShortURLServiceInterface) *GetOrgURLsHandler {
+	return &GetOrgURLsHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Responds with a JSON which is a list of models.ShortURLsByUserResponse objects.
+// The list might be filtered by the tag passed as the "tag" query parameter.
+func (getHandler GetOrgURLsHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the organization ID", http.StatusBadRequest)
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	filter := models.ShortURLFilter{Tag: request.URL.Query().Get("tag")}
+	results, err := getHandler.service.ReadByOrgID(request.Context(), id, userID, filter)
+	if err != nil {
+		writeOrganizationError(writer, err)
+		return
+	}
+	if len(results) == 0 {
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(writer, http.StatusOK, results)
+}
+
+// decodeOrgRequest decodes the organization request passed as JSON into the target, responding with the error
+// if it can't be decoded.
+func decodeOrgRequest(writer http.ResponseWriter, request *http.Request, target any) bool {
+	if contentType := request.Header.Get("Content-Type"); !strings.Contains(contentType, "application/json") {
+		http.Error(writer, "Only application/json content type is allowed", http.StatusBadRequest)
+		return false
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			logger.Log.Debugf("Error closing body: %s", err)
+		}
+	}(request.Body)
+	dec := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxPayloadSize))
+	if err := dec.Decode(target); err != nil {
+		logger.Log.Debugf("Couldn't decode the request body: %s", err)
+		writer.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeOrganizationError responds with the status matching the error of the organization operation.
+func writeOrganizationError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrOrganizationNotFound):
+		http.Error(writer, "Organization not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrOrgMemberNotFound):
+		http.Error(writer, "Organization member not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrOrgForbidden):
+		http.Error(writer, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidOrganization):
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Log.Errorf("Error processing organization: %s", err)
+		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
+	}
+}
+
+// RegisterHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to register the account with the email and the password.
+type RegisterHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewRegisterHandler is a constructor function that returns a pointer
+// to the freshly created RegisterHandler structure.
+func NewRegisterHandler(service service.ShortURLServiceInterface) *RegisterHandler {
+	return &RegisterHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON specified in models.Credentials. With claim_links the account takes over the short URLs,
+// the UTM templates and the campaigns of the current anonymous user.
+// Responds with a JSON document, specified in models.Account, which is the registered account,
+// the user is logged in to it by the auth cookie. The registrations are limited, the client gets the 429 status
+// code with Retry-After header once the limit is reached.
+func (register RegisterHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	credentials, ok := decodeCredentials(writer, request)
+	if !ok {
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	account, err := register.service.Register(request.Context(), credentials, userID, clientIP(request))
+	if err != nil {
+		writeAccountError(writer, err)
+		return
+	}
+	if _, err = middlewares.IssueAuthCookie(writer, account.ID); err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(writer, http.StatusCreated, account)
+}
+
+// LoginHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to log in to the account by the email and the password.
+type LoginHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewLoginHandler is a constructor function that returns a pointer
+// to the freshly created LoginHandler structure.
+func NewLoginHandler(service service.ShortURLServiceInterface) *LoginHandler {
+	return &LoginHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON specified in models.Credentials.
+// Responds with a JSON document, specified in models.Account, and replaces the auth cookie with the one
+// of the account. The wrong attempts are limited, the client gets the 429 status code with Retry-After header
+// once the limit is reached.
+func (login LoginHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	credentials, ok := decodeCredentials(writer, request)
+	if !ok {
+		return
+	}
+	account, err := login.service.Login(request.Context(), credentials, clientIP(request))
+	if err != nil {
+		writeAccountError(writer, err)
+		return
+	}
+	if _, err = middlewares.IssueAuthCookie(writer, account.ID); err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(writer, http.StatusOK, account)
+}
+
+// LogoutHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to log out of the account.
+type LogoutHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewLogoutHandler is a constructor function that returns a pointer
+// to the freshly created LogoutHandler structure.
+func NewLogoutHandler(service service.ShortURLServiceInterface) *LogoutHandler {
+	return &LogoutHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Revokes the token of the auth cookie, so its copies are rejected as well, and expires the cookie,
+// the next request gets the new anonymous user. Responds with no content.
+func (logout LogoutHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if tokenID, ok := middlewares.TokenIDFromContext(request.Context()); ok && tokenID != "" {
+		userID, _ := middlewares.UserIDFromContext(request.Context())
+		if err := logout.service.RevokeToken(request.Context(), tokenID, userID); err != nil {
+			logger.Log.Errorf("Failed to revoke the token: %v", err)
+			http.Error(writer, "Couldn't log out", http.StatusInternalServerError)
+			return
+		}
+	}
+	middlewares.ClearAuthCookie(writer)
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutEverywhereHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to log the user out on all the devices.
+type LogoutEverywhereHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewLogoutEverywhereHandler is a constructor function that returns a pointer
+// to the freshly created LogoutEverywhereHandler structure.
+func NewLogoutEverywhereHandler(service service.ShortURLServiceInterface) *LogoutEverywhereHandler {
+	return &LogoutEverywhereHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Revokes all the tokens of the current user issued so far and expires the auth cookie.
+// The API keys aren't affected. Responds with no content.
+func (logout LogoutEverywhereHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	if err := logout.service.RevokeAllTokens(request.Context(), userID); err != nil {
+		logger.Log.Errorf("Failed to revoke the tokens: %v", err)
+		http.Error(writer, "Couldn't log out", http.StatusInternalServerError)
+		return
+	}
+	middlewares.ClearAuthCookie(writer)
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// OIDCLoginHandler is a structure to implement ServeHTTP Handler function to start the single sign-on.
+type OIDCLoginHandler struct{}
+
+// NewOIDCLoginHandler is a constructor function that returns a pointer
+// to the freshly created OIDCLoginHandler structure.
+func NewOIDCLoginHandler() *OIDCLoginHandler {
+	return &OIDCLoginHandler{}
+}
+
+// ServeHTTP Serves as handler function.
+// Redirects the user to the identity provider with the new state, nonce and PKCE challenge, the state,
+// the nonce and the code verifier are kept in the short-lived cookie until the callback.
+// With claim_links=true in the query the account created on the first login takes over the short URLs
+// of the current anonymous user, like the registration does. Responds with 404 status code if the single sign-on
+// isn't configured.
+func (login OIDCLoginHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if oidc.SSO == nil {
+		http.Error(writer, "Single sign-on is not configured", http.StatusNotFound)
+		return
+	}
+	claimLinks := "0"
+	if value := request.URL.Query().Get("claim_links"); value != "" {
+		claim, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(writer, "Invalid claim_links", http.StatusBadRequest)
+			return
+		}
+		if claim {
+			claimLinks = "1"
+		}
+	}
+	authRequest, err := oidc.NewAuthRequest()
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(writer, newOIDCLoginCookie(
+		strings.Join([]string{authRequest.State, authRequest.Nonce, authRequest.CodeVerifier, claimLinks}, "."),
+		oidcLoginMaxAgeSeconds))
+	http.Redirect(writer, request, oidc.SSO.AuthCodeURL(authRequest), http.StatusFound)
+}
+
+// OIDCCallbackHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to finish the single sign-on.
+type OIDCCallbackHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewOIDCCallbackHandler is a constructor function that returns a pointer
+// to the freshly created OIDCCallbackHandler structure.
+func NewOIDCCallbackHandler(service service.ShortURLServiceInterface) *OIDCCallbackHandler {
+	return &OIDCCallbackHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the code and the state the identity provider redirects back with. The state must match the one
+// of the login cookie, then the code is exchanged for the ID token, whose subject is mapped to the local account.
+// Responds with a JSON document, specified in models.Account, and replaces the auth cookie with the one
+// of the account, like LoginHandler does.
+func (callback OIDCCallbackHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if oidc.SSO == nil {
+		http.Error(writer, "Single sign-on is not configured", http.StatusNotFound)
+		return
+	}
+	loginCookie, err := request.Cookie(oidcLoginCookieName)
+	if err != nil {
+		http.Error(writer, "The sign-in has expired, start it again", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(writer, newOIDCLoginCookie("", -1))
+	parts := strings.Split(loginCookie.Value, ".")
+	query := request.URL.Query()
+	if len(parts) != 4 || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(query.Get("state"))) != 1 {
+		http.Error(writer, "The sign-in state doesn't match", http.StatusBadRequest)
+		return
+	}
+	if idpError := query.Get("error"); idpError != "" {
+		logger.Log.Infof("Sign-in refused by the identity provider: %s %s", idpError, query.Get("error_description"))
+		http.Error(writer, "The sign-in is refused by the identity provider", http.StatusUnauthorized)
+		return
+	}
+	claims, err := oidc.SSO.Login(request.Context(), query.Get("code"),
+		oidc.AuthRequest{State: parts[0], Nonce: parts[1], CodeVerifier: parts[2]})
+	if err != nil {
+		logger.Log.Warnf("Failed to sign in with the identity provider: %v", err)
+		http.Error(writer, "Couldn't sign in with the identity provider", http.StatusUnauthorized)
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	account, err := callback.service.LoginOIDC(request.Context(), models.OIDCLogin{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		ClaimLinks:    parts[3] == "1",
+	}, userID)
+	if err != nil {
+		writeAccountError(writer, err)
+		return
+	}
+	if _, err = middlewares.IssueAuthCookie(writer, account.ID); err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(writer, http.StatusOK, account)
+}
+
+// newOIDCLoginCookie returns the cookie with the state of the single sign-on. It's sent on the top-level
+// redirect back from the identity provider, so SameSite is always Lax.
+func newOIDCLoginCookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     oidcLoginCookieName,
+		Value:    value,
+		Path:     oidcLoginCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   config.Settings.AuthCookieSecure || config.Settings.TLSEnabled,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// JWKSHandler is a structure to implement ServeHTTP Handler function to publish the keys the JWTs are verified with.
+type JWKSHandler struct{}
+
+// NewJWKSHandler is a constructor function that returns a pointer
+// to the freshly created JWKSHandler structure.
+func NewJWKSHandler() *JWKSHandler {
+	return &JWKSHandler{}
+}
+
+// ServeHTTP Serves as handler function.
+// Responds with a JSON document, specified in middlewares.JWKSet, which lists the public RS256 and EdDSA keys
+// of the keyring, so the other services can verify the tokens. The HS256 secrets are never published.
+func (jwks JWKSHandler) ServeHTTP(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(writer, http.StatusOK, middlewares.CurrentKeyring().JWKS())
+}
+
+// decodeCredentials decodes the credentials passed as JSON, responding with the error if they can't be decoded.
+func decodeCredentials(writer http.ResponseWriter, request *http.Request) (models.Credentials, bool) {
+	var credentials models.Credentials
+	if contentType := request.Header.Get("Content-Type"); !strings.Contains(contentType, "application/json") {
+		http.Error(writer, "Only application/json content type is allowed", http.StatusBadRequest)
+		return credentials, false
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			logger.Log.Debugf("Error closing body: %s", err)
+		}
+	}(request.Body)
+	dec := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxPayloadSize))
+	if err := dec.Decode(&credentials); err != nil {
+		logger.Log.Debugf("Couldn't decode the request body: %s", err)
+		writer.WriteHeader(http.StatusBadRequest)
+		return credentials, false
+	}
+	return credentials, true
+}
+
+func writeAccountError(writer http.ResponseWriter, err error) {
+	var tooManyAttemptsErr *service.ErrTooManyAttemptsExtended
+	switch {
+	case errors.Is(err, service.ErrInvalidAccount):
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrAccountExists):
+		http.Error(writer, "Account already exists", http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidCredentials):
+		http.Error(writer, "Wrong email or password", http.StatusUnauthorized)
+	case errors.As(err, &tooManyAttemptsErr):
+		writer.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(tooManyAttemptsErr.RetryAfter.Seconds()))))
+		http.Error(writer, "Too many attempts, try again later", http.StatusTooManyRequests)
+	default:
+		logger.Log.Errorf("Error processing account: %s", err)
+		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
+	}
+}
+
+// CreateAPIKeyHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to create the personal API key of authorized user.
+type CreateAPIKeyHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewCreateAPIKeyHandler is a constructor function that returns a pointer
+// to the freshly created CreateAPIKeyHandler structure.
+func NewCreateAPIKeyHandler(service service.ShortURLServiceInterface) *CreateAPIKeyHandler {
+	return &CreateAPIKeyHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON specified in models.CreateAPIKeyRequest.
+// Responds with a JSON document, specified in models.CreatedAPIKey, which contains the plain key.
+// The key is shown only once and is passed afterward in the "Authorization: Bearer" header.
+func (create CreateAPIKeyHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	var requestData models.CreateAPIKeyRequest
+	if contentType := request.Header.Get("Content-Type"); !strings.Contains(contentType, "application/json") {
+		http.Error(writer, "Only application/json content type is allowed", http.StatusBadRequest)
+		return
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			logger.Log.Debugf("Error closing body: %s", err)
+		}
+	}(request.Body)
+	dec := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxPayloadSize))
+	if err := dec.Decode(&requestData); err != nil {
+		logger.Log.Debugf("Couldn't decode the request body: %s", err)
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	result, err := create.service.CreateAPIKey(request.Context(), userID, requestData)
+	if err != nil {
+		writeAPIKeyError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusCreated, result)
+}
+
+// GetAPIKeysHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to return all the API keys of authorized user.
+type GetAPIKeysHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewGetAPIKeysHandler is a constructor function that returns a pointer
+// to the freshly created GetAPIKeysHandler structure.
+func NewGetAPIKeysHandler(service service.ShortURLServiceInterface) *GetAPIKeysHandler {
+	return &GetAPIKeysHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Responds with a JSON which is a list of models.APIKey objects, newest first. The keys themselves are
+// never returned, only their prefixes.
+func (getHandler GetAPIKeysHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	results, err := getHandler.service.ReadAPIKeysByUserID(request.Context(), userID)
+	if err != nil {
+		http.Error(writer, "Couldn't read all the api keys for user", http.StatusBadRequest)
+		return
+	}
+	if len(results) == 0 {
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(writer, http.StatusOK, results)
+}
+
+// DeleteAPIKeyHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to revoke the API key of authorized user.
+type DeleteAPIKeyHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewDeleteAPIKeyHandler is a constructor function that returns a pointer
+// to the freshly created DeleteAPIKeyHandler structure.
+func NewDeleteAPIKeyHandler(service service.ShortURLServiceInterface) *DeleteAPIKeyHandler {
+	return &DeleteAPIKeyHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Revokes the API key, the key is rejected starting from the next request. Responds with no content.
+func (delete DeleteAPIKeyHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the api key ID", http.StatusBadRequest)
+		return
+	}
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	if err := delete.service.DeleteAPIKey(request.Context(), id, userID); err != nil {
+		writeAPIKeyError(writer, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func writeAPIKeyError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		http.Error(writer, "API key not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidAPIKey):
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Log.Errorf("Error processing api key: %s", err)
+		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(writer http.ResponseWriter, statusCode int, body any) {
+	writer.Header().Add("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	enc := json.NewEncoder(writer)
+	if err := enc.Encode(body); err != nil {
+		logger.Log.Debugf("Error encoding response: %s", err)
+	}
+}
+
+// DeleteBatchOfURLsHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to delete a batch of URLs created by authorized user.
+type DeleteBatchOfURLsHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewDeleteBatchOfURLsHandler is a constructor function that returns a pointer
+// to the freshly created DeleteBatchOfURLsHandler structure.
+func NewDeleteBatchOfURLsHandler(service service.ShortURLServiceInterface) *DeleteBatchOfURLsHandler {
+	return &DeleteBatchOfURLsHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON-formatted list of short URL IDs, that should be deleted. The short URLs of the branded domains
+// are passed as the host and the ID separated by the slash.
+// Schedules the deletion of passed URLs. The URL will be deleted in some time after the response (not instantly).
+// The URL will be deleted if it was created by the same user that tries to delete it.
+func (delete DeleteBatchOfURLsHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if contentType := request.Header.Get("Content-Type"); !strings.Contains(contentType, "application/json") {
+		http.Error(writer, "Only application/json content type is allowed", http.StatusBadRequest)
+		return
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			logger.Log.Errorf("Error encoding response: %s", err)
+			http.Error(writer, "Error encoding response body", http.StatusInternalServerError)
+		}
+	}(request.Body)
+
+	var requestData []string
+	dec := json.NewDecoder(request.Body)
+	if err := dec.Decode(&requestData); err != nil {
+		logger.Log.Debugf("Couldn't decode the request body: %s", err)
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if len(requestData) == 0 {
+		http.Error(writer, "Please provide a batch of shortURLs", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := middlewares.UserIDFromContext(request.Context())
+	requestPrepared := make([]models.ShortURLChannelMessage, len(requestData))
+	for i, requestItem := range requestData {
+		requestPrepared[i] = models.ShortURLChannelMessage{
+			Actor:    service.ActorFromContext(request.Context()),
+			ShortURL: requestItem,
+			UserID:   userID,
+		}
+	}
+	go delete.service.ScheduleDeletionOfBatch(requestPrepared)
+	writer.WriteHeader(http.StatusAccepted)
+}
+
+// GetStatsHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to return statistics of created users and short URLs in the service.
+type GetStatsHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewGetStatsHandler is a constructor function that returns a pointer
+// to the freshly created GetStatsHandler structure.
+func NewGetStatsHandler(service service.ShortURLServiceInterface) *GetStatsHandler {
+	return &GetStatsHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Returns the JSON, representing the total number of users and short URLs created in the service.
+func (stats GetStatsHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	statistic, err := stats.service.GetStats(request.Context())
+	if err != nil {
+		logger.Log.Debugf("Error getting statistics: %s", err)
+		http.Error(writer, "Couldn't get service statistics", http.StatusBadRequest)
+		return
+	}
+	enc := json.NewEncoder(writer)
+	writer.Header().Add("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	if encErr := enc.Encode(statistic); encErr != nil {
+		logger.Log.Debugf("Error encoding response: %s", encErr)
+		http.Error(writer, "Couldn't get service statistics", http.StatusBadRequest)
+		return
+	}
+}
+
+// AdminGetShortURLHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to return the short URL of any user along with its moderation state.
+type AdminGetShortURLHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewAdminGetShortURLHandler is a constructor function that returns a pointer
+// to the freshly created AdminGetShortURLHandler structure.
+func NewAdminGetShortURLHandler(service service.ShortURLServiceInterface) *AdminGetShortURLHandler {
+	return &AdminGetShortURLHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// The short URL is looked up on the domain passed as the "domain" query parameter or on the default one.
+// Responds with a JSON document, specified in models.AdminShortURL.
+func (getHandler AdminGetShortURLHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the short url ID", http.StatusBadRequest)
+		return
+	}
+	result, err := getHandler.service.AdminReadShortURL(request.Context(), queryKey(request, id))
+	if err != nil {
+		writeModerationError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusOK, result)
+}
+
+// SearchShortURLsHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to search the short URLs of all the users.
+type SearchShortURLsHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewSearchShortURLsHandler is a constructor function that returns a pointer
+// to the freshly created SearchShortURLsHandler structure.
+func NewSearchShortURLsHandler(service service.ShortURLServiceInterface) *SearchShortURLsHandler {
+	return &SearchShortURLsHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the "original_url", "domain" and "user_id" query parameters, at least one of them is required,
+// and the optional "limit" one. The domain matches the host of the destination along with its subdomains.
+// Responds with a JSON which is a list of models.AdminShortURL objects.
+func (search SearchShortURLsHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	filter := models.ShortURLSearch{
+		OriginalURL: query.Get("original_url"),
+		Domain:      query.Get("domain"),
+		UserID:      query.Get("user_id"),
+	}
+	writeSearchResults(writer, request, search.service, filter)
+}
+
+// AdminGetUserURLsHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to return the short URLs created by any user.
+type AdminGetUserURLsHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewAdminGetUserURLsHandler is a constructor function that returns a pointer
+// to the freshly created AdminGetUserURLsHandler structure.
+func NewAdminGetUserURLsHandler(service service.ShortURLServiceInterface) *AdminGetUserURLsHandler {
+	return &AdminGetUserURLsHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// The amount of the short URLs might be limited by the "limit" query parameter.
+// Responds with a JSON which is a list of models.AdminShortURL objects, the deleted short URLs included.
+func (getHandler AdminGetUserURLsHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	userID := request.PathValue("id")
+	if userID == "" {
+		http.Error(writer, "Please provide the user ID", http.StatusBadRequest)
+		return
+	}
+	writeSearchResults(writer, request, getHandler.service, models.ShortURLSearch{UserID: userID})
+}
+
+// DisableShortURLHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to block the redirects of the short URL of any user.
+type DisableShortURLHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewDisableShortURLHandler is a constructor function that returns a pointer
+// to the freshly created DisableShortURLHandler structure.
+func NewDisableShortURLHandler(service service.ShortURLServiceInterface) *DisableShortURLHandler {
+	return &DisableShortURLHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON specified in models.ModerationRequest, the reason is required.
+// The short URL is looked up on the domain passed as the "domain" query parameter or on the default one.
+// Responds with a JSON document, specified in models.AdminShortURL, which is the disabled short URL.
+func (disable DisableShortURLHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the short url ID", http.StatusBadRequest)
+		return
+	}
+	var requestData models.ModerationRequest
+	if !decodeOrgRequest(writer, request, &requestData) {
+		return
+	}
+	result, err := disable.service.DisableShortURL(request.Context(), queryKey(request, id), requestData.Reason)
+	if err != nil {
+		writeModerationError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusOK, result)
+}
+
+// EnableShortURLHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to let the disabled short URL redirect again.
+type EnableShortURLHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewEnableShortURLHandler is a constructor function that returns a pointer
+// to the freshly created EnableShortURLHandler structure.
+func NewEnableShortURLHandler(service service.ShortURLServiceInterface) *EnableShortURLHandler {
+	return &EnableShortURLHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// The short URL is looked up on the domain passed as the "domain" query parameter or on the default one.
+// Responds with a JSON document, specified in models.AdminShortURL, which is the enabled short URL.
+func (enable EnableShortURLHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	id := request.PathValue("id")
+	if id == "" {
+		http.Error(writer, "Please provide the short url ID", http.StatusBadRequest)
+		return
+	}
+	result, err := enable.service.EnableShortURL(request.Context(), queryKey(request, id))
+	if err != nil {
+		writeModerationError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusOK, result)
+}
+
+// BanUserHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to ban the user.
+type BanUserHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewBanUserHandler is a constructor function that returns a pointer
+// to the freshly created BanUserHandler structure.
+func NewBanUserHandler(service service.ShortURLServiceInterface) *BanUserHandler {
+	return &BanUserHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the JSON specified in models.ModerationRequest, the reason is required. The banned user can't create
+// the short URLs and the redirects of the user's short URLs are blocked.
+// Responds with a JSON document, specified in models.UserBan.
+func (ban BanUserHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	userID := request.PathValue("id")
+	if userID == "" {
+		http.Error(writer, "Please provide the user ID", http.StatusBadRequest)
+		return
+	}
+	var requestData models.ModerationRequest
+	if !decodeOrgRequest(writer, request, &requestData) {
+		return
+	}
+	result, err := ban.service.BanUser(request.Context(), userID, requestData.Reason)
+	if err != nil {
+		writeModerationError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusOK, result)
+}
+
+// UnbanUserHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to lift the ban of the user.
+type UnbanUserHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewUnbanUserHandler is a constructor function that returns a pointer
+// to the freshly created UnbanUserHandler structure.
+func NewUnbanUserHandler(service service.ShortURLServiceInterface) *UnbanUserHandler {
+	return &UnbanUserHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Responds with no content.
+func (unban UnbanUserHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	userID := request.PathValue("id")
+	if userID == "" {
+		http.Error(writer, "Please provide the user ID", http.StatusBadRequest)
+		return
+	}
+	if err := unban.service.UnbanUser(request.Context(), userID); err != nil {
+		writeModerationError(writer, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// writeSearchResults searches the short URLs of all the users and responds with the results found.
+// The amount of the results might be limited by the "limit" query parameter.
+func writeSearchResults(
+	writer http.ResponseWriter, request *http.Request, shortURLService service.ShortURLServiceInterface,
+	filter models.ShortURLSearch) {
+	if limit := request.URL.Query().Get("limit"); limit != "" {
+		var err error
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			http.Error(writer, "Limit should be integer", http.StatusBadRequest)
+			return
+		}
+	}
+	results, err := shortURLService.SearchShortURLs(request.Context(), filter)
+	if err != nil {
+		writeModerationError(writer, err)
+		return
+	}
+	writeJSON(writer, http.StatusOK, results)
+}
+
+// writeModerationError responds with the status matching the error of the moderation operation.
+func writeModerationError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrShortURLNotFound):
+		http.Error(writer, "Short url not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrUserNotBanned):
+		http.Error(writer, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidModeration):
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+	default:
+		logger.Log.Errorf("Error processing moderation: %s", err)
+		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
+	}
+}
+
+// GetAuditLogHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to return the events of the audit log.
+type GetAuditLogHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewGetAuditLogHandler is a constructor function that returns a pointer
+// to the freshly created GetAuditLogHandler structure.
+func NewGetAuditLogHandler(service service.ShortURLServiceInterface) *GetAuditLogHandler {
+	return &GetAuditLogHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Accepts the optional "action", "target", "user_id" and "transport" query parameters along with the period
+// of the events as the "from" and "to" ones in RFC 3339 format. The events are paged with the "after" query parameter,
+// which is the position of the last event already read, and the "limit" one.
+// Responds with a JSON which is a list of models.AuditEvent objects ordered by their position.
+func (getHandler GetAuditLogHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	filter := models.AuditFilter{
+		Action:    query.Get("action"),
+		Target:    query.Get("target"),
+		UserID:    query.Get("user_id"),
+		Transport: query.Get("transport"),
+	}
+	var err error
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(name); value != "" {
+			if *target, err = time.Parse(time.RFC3339, value); err != nil {
+				http.Error(writer, "The period should be in RFC 3339 format", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if after := query.Get("after"); after != "" {
+		if filter.AfterSeq, err = strconv.ParseInt(after, 10, 64); err != nil {
+			http.Error(writer, "After should be integer", http.StatusBadRequest)
+			return
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			http.Error(writer, "Limit should be integer", http.StatusBadRequest)
+			return
+		}
+	}
+	results, err := getHandler.service.ReadAuditEvents(request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAuditFilter) {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.Log.Errorf("Error reading audit log: %s", err)
+		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(writer, http.StatusOK, results)
+}
+
+// VerifyAuditLogHandler is a structure to store dependencies and
+// implement ServeHTTP Handler function to check the hash chain of the audit log.
+type VerifyAuditLogHandler struct {
+	service service.ShortURLServiceInterface
+}
+
+// NewVerifyAuditLogHandler is a constructor function that returns a pointer
+// to the freshly created VerifyAuditLogHandler structure.
+func NewVerifyAuditLogHandler(service service.ShortURLServiceInterface) *VerifyAuditLogHandler {
+	return &VerifyAuditLogHandler{service: service}
+}
+
+// ServeHTTP Serves as handler function.
+// Responds with a JSON document, specified in models.AuditVerification, the broken chain is reported
+// with the position of the first event breaking it.
+func (verify VerifyAuditLogHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	result, err := verify.service.VerifyAuditLog(request.Context())
+	if err != nil {
+		logger.Log.Errorf("Error verifying audit log: %s", err)
+		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
 		return
 	}
+	writeJSON(writer, http.StatusOK, result)
 }
@@ -10,16 +10,22 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/clearthree/url-shortener/internal/app/config"
+	"github.com/clearthree/url-shortener/internal/app/geoip"
+	"github.com/clearthree/url-shortener/internal/app/middlewares"
 	"github.com/clearthree/url-shortener/internal/app/mocks"
 	"github.com/clearthree/url-shortener/internal/app/models"
+	"github.com/clearthree/url-shortener/internal/app/oidc"
+	"github.com/clearthree/url-shortener/internal/app/oidc/oidctest"
 	"github.com/clearthree/url-shortener/internal/app/service"
 	"github.com/clearthree/url-shortener/internal/app/storage"
+	"github.com/clearthree/url-shortener/internal/app/utils"
 )
 
 var ServiceForTest = service.NewService(storage.MemoryRepo{}, make(chan struct{}))
@@ -207,7 +213,7 @@ func TestCreateShortURLHandler(t *testing.T) {
 			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
 			if test.mockExpect {
 				shortURLServiceMock.EXPECT().
-					Create(context.Background(), gomock.Any(), gomock.Any()).
+					Create(context.Background(), gomock.Any(), gomock.Any(), models.ShortURLOptions{}).
 					Return(test.mockReturns, test.mockReturnsError)
 			}
 
@@ -259,34 +265,92 @@ func TestNewRedirectToOriginalURLHandler(t *testing.T) {
 
 func TestRedirectToOriginalURLHandler(t *testing.T) {
 	type want struct {
+		headers  map[string]string
 		response string
 		header   string
 		code     int
 	}
 	tests := []struct {
-		name          string
-		requestMethod string
-		mockValue     string
-		want          want
+		mockValue *models.ShortURL
+		mockError error
+		name      string
+		want      want
 	}{
 		{
-			name:          "Successful redirection test",
-			requestMethod: http.MethodGet,
-			mockValue:     "lelelele",
+			name:      "Successful redirection test",
+			mockValue: &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru"},
 			want: want{
 				code:     http.StatusTemporaryRedirect,
 				response: "https://ya.ru",
 				header:   "Location",
+				headers:  map[string]string{"Cache-Control": "no-store", "Referrer-Policy": "", "X-Robots-Tag": ""},
+			},
+		},
+		{
+			name: "Successful permanent redirection test",
+			mockValue: &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru",
+				ShortURLOptions: models.ShortURLOptions{
+					RedirectOptions: models.RedirectOptions{RedirectStatus: http.StatusPermanentRedirect}}},
+			want: want{
+				code:     http.StatusPermanentRedirect,
+				response: "https://ya.ru",
+				header:   "Location",
+				headers: map[string]string{
+					"Cache-Control": "public, max-age=" + strconv.FormatInt(config.Settings.PermanentRedirectMaxAgeSeconds, 10)},
+			},
+		},
+		{
+			name: "Successful redirection with the headers chosen for the link test",
+			mockValue: &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru",
+				ShortURLOptions: models.ShortURLOptions{RedirectOptions: models.RedirectOptions{
+					RedirectStatus: http.StatusFound,
+					CacheControl:   "private, max-age=60",
+					ReferrerPolicy: "no-referrer",
+					RobotsTag:      "noindex, nofollow",
+				}}},
+			want: want{
+				code:     http.StatusFound,
+				response: "https://ya.ru",
+				header:   "Location",
+				headers: map[string]string{
+					"Cache-Control":   "private, max-age=60",
+					"Referrer-Policy": "no-referrer",
+					"X-Robots-Tag":    "noindex, nofollow",
+				},
+			},
+		},
+		{
+			name: "Successful redirection with the UTM template test",
+			mockValue: &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru/?utm_source=site&q=1",
+				UTM: &models.UTMParameters{Source: "newsletter", Medium: "email", Campaign: "autumn sale"}},
+			want: want{
+				code:     http.StatusTemporaryRedirect,
+				response: "https://ya.ru/?utm_source=site&q=1&utm_medium=email&utm_campaign=autumn+sale",
+				header:   "Location",
+			},
+		},
+		{
+			name: "Successful interstitial page test",
+			mockValue: &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru",
+				ShortURLOptions: models.ShortURLOptions{RedirectOptions: models.RedirectOptions{Interstitial: true}}},
+			want: want{
+				code:     http.StatusOK,
+				response: "https://ya.ru",
+			},
+		},
+		{
+			name:      "Unsuccessful redirection due to deleted shortURL test",
+			mockValue: &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru", Deleted: true},
+			want: want{
+				code: http.StatusGone,
 			},
 		},
 		{
-			name:          "Unsuccessful redirection due to non-existing shortURL test",
-			requestMethod: http.MethodGet,
-			mockValue:     "",
+			name:      "Unsuccessful redirection due to non-existing shortURL test",
+			mockError: service.ErrShortURLNotFound,
 			want: want{
 				code:     http.StatusNotFound,
 				response: "Short url not found",
-				header:   "",
 			},
 		},
 	}
@@ -296,435 +360,707 @@ func TestRedirectToOriginalURLHandler(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
-			if test.mockValue != "" {
-				shortURLServiceMock.EXPECT().Read(context.Background(), test.mockValue).Return(test.want.response, false, nil)
-			} else {
-				shortURLServiceMock.EXPECT().Read(context.Background(), gomock.Any()).Return("", false, service.ErrShortURLNotFound)
-			}
+			shortURLServiceMock.EXPECT().Resolve(context.Background(), shortURL).Return(test.mockValue, test.mockError)
 			recorder := httptest.NewRecorder()
-			request := httptest.NewRequest(test.requestMethod, "/"+shortURL, nil)
+			request := httptest.NewRequest(http.MethodGet, "/"+shortURL, nil)
 			request.SetPathValue("id", shortURL)
 			handler := NewRedirectToOriginalURLHandler(shortURLServiceMock)
 			handler.ServeHTTP(recorder, request)
 			res := recorder.Result()
+			defer res.Body.Close()
 			assert.Equal(t, test.want.code, res.StatusCode)
-			if res.StatusCode >= http.StatusBadRequest {
-				defer res.Body.Close()
-				resBody, err := io.ReadAll(res.Body)
-				require.NoError(t, err)
-				assert.Contains(t, string(resBody), test.want.response)
-			} else {
+			for key, value := range test.want.headers {
+				assert.Equal(t, value, res.Header.Get(key), key)
+			}
+			if test.want.header != "" {
 				header := res.Header.Get(test.want.header)
 				assert.NotEmpty(t, header)
 				assert.Equal(t, test.want.response, header)
+				return
+			}
+			resBody, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(resBody), test.want.response)
+			if test.want.code == http.StatusOK {
+				assert.Equal(t, "text/html; charset=utf-8", res.Header.Get("Content-Type"))
 			}
 		})
 	}
 }
 
-func TestNewCreateJSONShortURLHandler(t *testing.T) {
-	type args struct {
-		service service.ShortURLServiceInterface
-	}
+func TestRedirectToOriginalURLHandler_Protected(t *testing.T) {
+	protected := &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru",
+		ShortURLOptions: models.ShortURLOptions{PasswordHash: "hash", RedirectOptions: models.RedirectOptions{
+			RedirectStatus: http.StatusMovedPermanently, CacheControl: "public, max-age=3600"}}}
 	tests := []struct {
-		args args
-		want *CreateJSONShortURLHandler
-		name string
+		checkErr     error
+		name         string
+		password     string
+		wantLocation string
+		wantBody     string
+		wantCode     int
 	}{
 		{
-			name: "success",
-			args: args{
-				service: &ServiceForTest,
-			},
-			want: &CreateJSONShortURLHandler{service: &ServiceForTest},
+			name:     "Password prompt without the header",
+			wantCode: http.StatusOK,
+			wantBody: "Password required",
+		},
+		{
+			name:         "Successful redirection with the right password",
+			password:     "secret",
+			wantCode:     http.StatusMovedPermanently,
+			wantLocation: "https://ya.ru",
+		},
+		{
+			name:     "Unsuccessful redirection with the wrong password",
+			password: "wrong",
+			checkErr: service.ErrWrongPassword,
+			wantCode: http.StatusForbidden,
+			wantBody: "Wrong password",
+		},
+		{
+			name:     "Unsuccessful redirection after too many attempts",
+			password: "secret",
+			checkErr: service.NewErrTooManyAttempts(service.ErrTooManyAttempts, 90*time.Second),
+			wantCode: http.StatusTooManyRequests,
+			wantBody: "Too many password attempts",
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, NewCreateJSONShortURLHandler(tt.args.service), "NewCreateJSONShortURLHandler(%v)", tt.args.service)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			shortURLServiceMock.EXPECT().Resolve(context.Background(), "lelelele").Return(protected, nil)
+			if test.password != "" {
+				shortURLServiceMock.EXPECT().CheckPassword(protected, test.password, gomock.Any()).Return(test.checkErr)
+			}
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/lelelele", nil)
+			request.SetPathValue("id", "lelelele")
+			if test.password != "" {
+				request.Header.Set(PasswordHeader, test.password)
+			}
+			handler := NewRedirectToOriginalURLHandler(shortURLServiceMock)
+			handler.ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.wantCode, res.StatusCode)
+			assert.Equal(t, test.wantLocation, res.Header.Get("Location"))
+			resBody, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(resBody), test.wantBody)
+			if test.wantLocation == "" {
+				assert.NotContains(t, string(resBody), "https://ya.ru", "the destination must not be revealed")
+			} else {
+				assert.Equal(t, "no-store", res.Header.Get("Cache-Control"), "the protected redirect must not be cached")
+			}
+			if test.wantCode == http.StatusTooManyRequests {
+				assert.Equal(t, "90", res.Header.Get("Retry-After"))
+			}
 		})
 	}
 }
 
-func TestCreateJSONShortURLHandler_ServeHTTP(t *testing.T) {
-	type want struct {
-		contentType string
-		errMessage  string
-		code        int
-	}
+func TestRedirectToOriginalURLHandler_Targeted(t *testing.T) {
+	targeted := &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru",
+		UTM: &models.UTMParameters{Source: "qr"},
+		ShortURLOptions: models.ShortURLOptions{RedirectOptions: models.RedirectOptions{
+			Targets: []models.TargetingRule{
+				{Platform: utils.PlatformIOS, URL: "https://apps.apple.com/app/id1"},
+				{Platform: utils.PlatformAndroid, URL: "market://details?id=com.example"},
+				{Platform: utils.PlatformMobile, URL: "https://m.ya.ru"},
+			}}}}
 	tests := []struct {
-		name               string
-		requestPayload     string
-		requestContentType string
-		want               want
-		mockExpect         bool
+		name         string
+		userAgent    string
+		wantLocation string
 	}{
 		{
-			name:               "Successful creation of the short URL",
-			requestPayload:     `{"url": "https://ya.ru"}`,
-			requestContentType: "application/json",
-			mockExpect:         true,
-			want: want{
-				code:        http.StatusCreated,
-				contentType: "application/json",
-				errMessage:  "",
-			},
+			name:         "iOS visitor",
+			userAgent:    "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) Mobile/15E148 Safari/604.1",
+			wantLocation: "https://apps.apple.com/app/id1?utm_source=qr",
 		},
 		{
-			name:               "Empty URL passed",
-			requestPayload:     `{"url": ""}`,
-			requestContentType: "application/json",
-			mockExpect:         false,
-			want: want{
-				code:        http.StatusBadRequest,
-				contentType: "application/json",
-				errMessage:  "Please provide an url\n",
-			},
+			name:         "Android visitor",
+			userAgent:    "Mozilla/5.0 (Linux; Android 14; Pixel 8) Mobile Safari/537.36",
+			wantLocation: "market://details?id=com.example&utm_source=qr",
 		},
 		{
-			name:               "Invalid URL passed",
-			requestPayload:     `{"url": "asdasdsa"}`,
-			requestContentType: "application/json",
-			mockExpect:         false,
-			want: want{
-				code:        http.StatusBadRequest,
-				contentType: "application/json",
-				errMessage:  "The provided payload is not a valid URL\n",
-			},
+			name:         "Other mobile visitor",
+			userAgent:    "Opera/9.80 (J2ME/MIDP; Opera Mini/9.80) Mobile Presto/2.12",
+			wantLocation: "https://m.ya.ru?utm_source=qr",
+		},
+		{
+			name:         "Fallback for desktop visitors",
+			userAgent:    "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/126.0.0.0 Safari/537.36",
+			wantLocation: "https://ya.ru?utm_source=qr",
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-
 			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
-			if test.mockExpect {
-				shortURLServiceMock.EXPECT().Create(context.Background(), gomock.Any(), gomock.Any()).Return("http://localhost:8080/lelelele", nil)
-			}
-			body := strings.NewReader(test.requestPayload)
-			request := httptest.NewRequest(http.MethodPost, "/", body)
-			request.Header.Add("Content-Type", test.requestContentType)
+			shortURLServiceMock.EXPECT().Resolve(context.Background(), "lelelele").Return(targeted, nil)
 			recorder := httptest.NewRecorder()
-			handler := NewCreateJSONShortURLHandler(shortURLServiceMock)
-			handler.ServeHTTP(recorder, request)
+			request := httptest.NewRequest(http.MethodGet, "/lelelele", nil)
+			request.SetPathValue("id", "lelelele")
+			request.Header.Set("User-Agent", test.userAgent)
+			NewRedirectToOriginalURLHandler(shortURLServiceMock).ServeHTTP(recorder, request)
 			res := recorder.Result()
-			assert.Equal(t, test.want.code, res.StatusCode)
 			defer res.Body.Close()
-			if test.want.errMessage != "" {
-				resBody, err := io.ReadAll(res.Body)
-				require.NoError(t, err)
-				assert.Equal(t, test.want.errMessage, string(resBody))
-				return
-			}
-			var responseData models.ShortenResponse
-			dec := json.NewDecoder(res.Body)
-			err := dec.Decode(&responseData)
-			require.NoError(t, err)
-			assert.Contains(t, responseData.Result, config.Settings.HostedOn)
-			assert.Equal(t, test.want.contentType, res.Header.Get("Content-Type"))
+			assert.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+			assert.Equal(t, test.wantLocation, res.Header.Get("Location"))
+			assert.Equal(t, "User-Agent", res.Header.Get("Vary"))
 		})
 	}
 }
 
-func TestNewPingHandler(t *testing.T) {
-	type args struct {
-		service service.ShortURLServiceInterface
-	}
+func TestRedirectToOriginalURLHandler_Geo(t *testing.T) {
+	reader, err := geoip.Open("../geoip/testdata/GeoIP2-Country-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+	geoip.DB = reader
+	defer func() { geoip.DB = nil }()
+	useHeader := config.Settings.UseHeaderForSourceAddress
+	config.Settings.UseHeaderForSourceAddress = true
+	defer func() { config.Settings.UseHeaderForSourceAddress = useHeader }()
+	config.Settings.TrustedProxies = []string{"192.0.2.0/24"} // the address of the httptest requests
+	defer func() { config.Settings.TrustedProxies = nil }()
+	geoTargeted := &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru",
+		ShortURLOptions: models.ShortURLOptions{RedirectOptions: models.RedirectOptions{
+			RedirectStatus: http.StatusPermanentRedirect,
+			GeoRules: []models.GeoRule{
+				{Country: "GB", URL: "https://ya.ru/uk"},
+				{Country: "SE", URL: "https://ya.ru/se"},
+			},
+			Targets: []models.TargetingRule{{Platform: utils.PlatformIOS, URL: "https://apps.apple.com/app/id1"}},
+		}}}
 	tests := []struct {
-		args args
-		want *PingHandler
-		name string
+		name         string
+		realIP       string
+		forwardedFor string
+		userAgent    string
+		wantLocation string
 	}{
 		{
-			name: "success",
-			args: args{
-				service: &ServiceForTest,
-			},
-			want: &PingHandler{service: &ServiceForTest},
+			name:         "Visitor from the country with the rule",
+			realIP:       "81.2.69.142",
+			wantLocation: "https://ya.ru/uk",
+		},
+		{
+			name:         "Visitor behind the proxies",
+			forwardedFor: "89.160.20.112, 10.0.0.1",
+			wantLocation: "https://ya.ru/se",
+		},
+		{
+			name:         "Geo rule wins over the platform",
+			realIP:       "81.2.69.142",
+			userAgent:    "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) Mobile/15E148",
+			wantLocation: "https://ya.ru/uk",
+		},
+		{
+			name:         "Platform is checked for another country",
+			realIP:       "5.255.255.77",
+			userAgent:    "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) Mobile/15E148",
+			wantLocation: "https://apps.apple.com/app/id1",
+		},
+		{
+			name:         "Fallback for the unknown address",
+			realIP:       "127.0.0.1",
+			wantLocation: "https://ya.ru",
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, NewPingHandler(tt.args.service), "NewPingHandler(%v)", tt.args.service)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			shortURLServiceMock.EXPECT().Resolve(context.Background(), "lelelele").Return(geoTargeted, nil)
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/lelelele", nil)
+			request.SetPathValue("id", "lelelele")
+			request.Header.Set("X-Real-IP", test.realIP)
+			request.Header.Set("X-Forwarded-For", test.forwardedFor)
+			request.Header.Set("User-Agent", test.userAgent)
+			NewRedirectToOriginalURLHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, http.StatusPermanentRedirect, res.StatusCode)
+			assert.Equal(t, test.wantLocation, res.Header.Get("Location"))
+			assert.True(t, strings.HasPrefix(res.Header.Get("Cache-Control"), "private"),
+				"the shared caches must not keep the redirect of one country")
 		})
 	}
 }
 
-func TestPingHandler_ServeHTTP(t *testing.T) {
-	type want struct {
-		errMessage string
-		code       int
-	}
+func TestRedirectToOriginalURLHandler_Scheduled(t *testing.T) {
+	activeFrom := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
 	tests := []struct {
-		name        string
-		want        want
-		wantSuccess bool
+		resolveErr      error
+		name            string
+		wantContentType string
+		wantBody        string
+		wantStatus      int
+		placeholder     bool
 	}{
 		{
-			name:        "Successful ping",
-			wantSuccess: true,
-			want: want{
-				code:       200,
-				errMessage: "",
-			},
+			name:            "Not active yet",
+			resolveErr:      service.NewErrNotActiveYet(service.ErrNotActiveYet, activeFrom),
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Short url not found",
 		},
 		{
-			name:        "Unsuccessful ping",
-			wantSuccess: false,
-			want: want{
-				code:       500,
-				errMessage: "Database is not available\n",
-			},
+			name:            "Not active yet with the placeholder",
+			resolveErr:      service.NewErrNotActiveYet(service.ErrNotActiveYet, activeFrom),
+			placeholder:     true,
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "text/html; charset=utf-8",
+			wantBody:        "2026-11-01T09:00:00Z",
+		},
+		{
+			name:        "No longer active",
+			resolveErr:  service.ErrNoLongerActive,
+			placeholder: true,
+			wantStatus:  http.StatusGone,
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
+			placeholder := config.Settings.ScheduledPlaceholder
+			config.Settings.ScheduledPlaceholder = test.placeholder
+			defer func() { config.Settings.ScheduledPlaceholder = placeholder }()
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-
 			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
-			if test.wantSuccess {
-				shortURLServiceMock.EXPECT().Ping(context.Background()).Return(nil)
-			} else {
-				shortURLServiceMock.EXPECT().Ping(context.Background()).Return(errors.New("database is not available"))
-			}
-			request := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			shortURLServiceMock.EXPECT().Resolve(context.Background(), "lelelele").Return(nil, test.resolveErr)
 			recorder := httptest.NewRecorder()
-			pingHandler := NewPingHandler(shortURLServiceMock)
-			pingHandler.ServeHTTP(recorder, request)
+			request := httptest.NewRequest(http.MethodGet, "/lelelele", nil)
+			request.SetPathValue("id", "lelelele")
+			NewRedirectToOriginalURLHandler(shortURLServiceMock).ServeHTTP(recorder, request)
 			res := recorder.Result()
 			defer res.Body.Close()
-			assert.Equal(t, test.want.code, res.StatusCode)
-			if res.StatusCode > http.StatusOK {
-				resBody, err := io.ReadAll(res.Body)
-				require.NoError(t, err)
-				assert.Equal(t, test.want.errMessage, string(resBody))
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+			assert.Empty(t, res.Header.Get("Location"))
+			if test.wantContentType != "" {
+				assert.Equal(t, test.wantContentType, res.Header.Get("Content-Type"))
 			}
+			assert.Contains(t, string(body), test.wantBody)
 		})
 	}
 }
 
-func TestNewBatchCreateShortURLHandler(t *testing.T) {
-	type args struct {
-		service service.ShortURLServiceInterface
+func TestRedirectToOriginalURLHandler_ClickLimited(t *testing.T) {
+	limited := &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru/reset",
+		ShortURLOptions: models.ShortURLOptions{
+			RedirectOptions: models.RedirectOptions{RedirectStatus: http.StatusPermanentRedirect},
+			MaxClicks:       1,
+		},
+		ClicksLeft: 1,
 	}
 	tests := []struct {
-		args args
-		want *BatchCreateShortURLHandler
-		name string
+		resolveErr   error
+		useErr       error
+		name         string
+		wantLocation string
+		wantStatus   int
+		wantUse      bool
 	}{
 		{
-			name: "success",
-			args: args{
-				service: &ServiceForTest,
-			},
-			want: &BatchCreateShortURLHandler{
-				service: &ServiceForTest,
-			},
+			name:         "Click is taken",
+			wantUse:      true,
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "https://ya.ru/reset",
+		},
+		{
+			name:       "Last click is taken by another visitor",
+			wantUse:    true,
+			useErr:     service.ErrClicksExhausted,
+			wantStatus: http.StatusGone,
+		},
+		{
+			name:       "No clicks left",
+			resolveErr: service.ErrClicksExhausted,
+			wantStatus: http.StatusGone,
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, NewBatchCreateShortURLHandler(tt.args.service), "NewBatchCreateShortURLHandler(%v)", tt.args.service)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			if test.resolveErr != nil {
+				shortURLServiceMock.EXPECT().Resolve(context.Background(), "lelelele").Return(nil, test.resolveErr)
+			} else {
+				shortURLServiceMock.EXPECT().Resolve(context.Background(), "lelelele").Return(limited, nil)
+			}
+			if test.wantUse {
+				shortURLServiceMock.EXPECT().UseClick(context.Background(), limited).Return(test.useErr)
+			}
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/lelelele", nil)
+			request.SetPathValue("id", "lelelele")
+			NewRedirectToOriginalURLHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+			assert.Equal(t, test.wantLocation, res.Header.Get("Location"))
+			if test.wantLocation != "" {
+				assert.Equal(t, "no-store", res.Header.Get("Cache-Control"),
+					"the cached redirect would skip the limit")
+			}
 		})
 	}
 }
 
-func TestBatchCreateShortURLHandler_ServeHTTP(t *testing.T) {
-	type want struct {
-		contentType string
-		errMessage  string
-		payload     []models.ShortenBatchItemResponse
-		code        int
-	}
+func TestRedirectToOriginalURLHandler_Domains(t *testing.T) {
+	domains := config.Settings.Domains
+	config.Settings.Domains = []config.Domain{{Host: "go.example.com"}}
+	defer func() { config.Settings.Domains = domains }()
 	tests := []struct {
-		name               string
-		requestPayload     string
-		requestContentType string
-		want               want
-		mockExpect         bool
+		name    string
+		host    string
+		wantKey string
+	}{
+		{name: "Default domain", host: "localhost:8080", wantKey: "lelelele"},
+		{name: "Branded domain", host: "GO.example.com", wantKey: "go.example.com/lelelele"},
+		{name: "Unknown host falls back to default domain", host: "127.0.0.1:8080", wantKey: "lelelele"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			shortURLServiceMock.EXPECT().Resolve(context.Background(), test.wantKey).Return(
+				&models.ShortURL{ShortURL: test.wantKey, OriginalURL: "https://ya.ru"}, nil)
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/lelelele", nil)
+			request.Host = test.host
+			request.SetPathValue("id", "lelelele")
+			NewRedirectToOriginalURLHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+			assert.Equal(t, "https://ya.ru", res.Header.Get("Location"))
+		})
+	}
+}
+
+func Test_splitCookieOnDomain(t *testing.T) {
+	shortURL := &models.ShortURL{ShortURL: "go.example.com/lelelele"}
+	assert.Equal(t, "split_lelelele", splitCookieName(shortURL), "the cookie is scoped to the host already")
+	assert.Equal(t, "lelelele", shortURLID(shortURL))
+}
+
+func Test_queryKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "No domain", query: "", want: "lelelele"},
+		{name: "Default domain", query: "?domain=localhost:8080", want: "lelelele"},
+		{name: "Branded domain", query: "?domain=Go.Example.com", want: "go.example.com/lelelele"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/user/urls/lelelele/stats"+test.query, nil)
+			assert.Equal(t, test.want, queryKey(request, "lelelele"))
+		})
+	}
+}
+
+func TestCreateShortURLHandler_DomainNotAllowed(t *testing.T) {
+	domains := config.Settings.Domains
+	config.Settings.Domains = []config.Domain{{Host: "brand.example.com", Users: []string{"alice"}}}
+	defer func() { config.Settings.Domains = domains }()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	ctx := middlewares.WithUserID(context.Background(), "bob")
+	shortURLServiceMock.EXPECT().Create(ctx, "https://ya.ru", "bob",
+		models.ShortURLOptions{Domain: "brand.example.com"}).Return("", service.ErrDomainNotAllowed)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://ya.ru")).WithContext(ctx)
+	request.Host = "brand.example.com"
+	request.Header.Set("Content-Type", "text/plain")
+	request.Header.Set("Content-Length", "13")
+	NewCreateShortURLHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func Test_permanentMaxAge(t *testing.T) {
+	maxAge := config.Settings.PermanentRedirectMaxAgeSeconds
+	config.Settings.PermanentRedirectMaxAgeSeconds = 86400
+	defer func() { config.Settings.PermanentRedirectMaxAgeSeconds = maxAge }()
+	now := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		activeUntil string
+		want        int64
+	}{
+		{name: "No end of the window", want: 86400},
+		{name: "Window ends later than the cache", activeUntil: "2027-11-01T09:00:00Z", want: 86400},
+		{name: "Window ends in an hour", activeUntil: "2026-11-01T10:00:00Z", want: 3600},
+		{name: "Window has ended", activeUntil: "2026-11-01T08:00:00Z", want: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			shortURL := &models.ShortURL{ShortURLOptions: models.ShortURLOptions{
+				RedirectOptions: models.RedirectOptions{ActiveUntil: test.activeUntil}}}
+			assert.Equal(t, test.want, permanentMaxAge(shortURL, now))
+		})
+	}
+}
+
+func TestRedirectToOriginalURLHandler_Split(t *testing.T) {
+	split := &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru",
+		ShortURLOptions: models.ShortURLOptions{RedirectOptions: models.RedirectOptions{
+			RedirectStatus: http.StatusPermanentRedirect,
+			Variants: []models.SplitVariant{
+				{Name: "a", URL: "https://ya.ru/a", Weight: 1},
+				{Name: "b", URL: "https://ya.ru/b", Weight: 1},
+			}}}}
+	tests := []struct {
+		name         string
+		cookie       string
+		wantLocation string
 	}{
 		{
-			name: "Successful creation of batch for short url",
-			requestPayload: `[
-				{"original_url": "https://ya.ru", "correlation_id": "lelele"},
-				{"original_url": "https://yandex.ru", "correlation_id": "lololo"}
-			]`,
-			requestContentType: "application/json",
-			mockExpect:         true,
-			want: want{
-				code:        http.StatusCreated,
-				contentType: "application/json",
-				payload: []models.ShortenBatchItemResponse{
-					{CorrelationID: "lelele", ShortURL: "http://localhost:8080/LELELELE"},
-					{CorrelationID: "lololo", ShortURL: "http://localhost:8080/LELELELE"},
-				},
-				errMessage: "",
-			},
-		},
-		{
-			name:               "Successful creation of batch for short url with single url",
-			requestPayload:     `[{"original_url": "https://ya.ru", "correlation_id": "lelele"}]`,
-			requestContentType: "application/json",
-			mockExpect:         true,
-			want: want{
-				code:        http.StatusCreated,
-				contentType: "application/json",
-				payload: []models.ShortenBatchItemResponse{
-					{CorrelationID: "lelele", ShortURL: "http://localhost:8080/LELELELE"},
-				},
-				errMessage: "",
-			},
+			name:         "Visitor keeps the assigned variant",
+			cookie:       "b",
+			wantLocation: "https://ya.ru/b",
 		},
 		{
-			name:               "Unsuccessful creation of batch for short url with empty batch",
-			requestPayload:     `[]`,
-			requestContentType: "application/json",
-			mockExpect:         false,
-			want: want{
-				code:        http.StatusBadRequest,
-				contentType: "application/json",
-				payload:     nil,
-				errMessage:  "Please provide a batch of URLs\n",
-			},
+			name:   "Visitor of the removed variant is assigned again",
+			cookie: "c",
 		},
 		{
-			name: "Successful creation of batch for short url",
-			requestPayload: `[
-				{"original_url": "lele://ya.ru", "correlation_id": "lelele"},
-				{"original_url": "https://yandex.ru", "correlation_id": "lololo"}
-			]`,
-			requestContentType: "application/json",
-			mockExpect:         false,
-			want: want{
-				code:        http.StatusBadRequest,
-				contentType: "application/json",
-				payload:     nil,
-				errMessage:  "One of the provided items is not a valid URL\n",
-			},
+			name: "New visitor is assigned",
 		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			shortURLServiceMock.EXPECT().Resolve(context.Background(), "lelelele").Return(split, nil)
+			var clicked string
+			shortURLServiceMock.EXPECT().RecordClick("lelelele", gomock.Any()).Do(func(_ string, variant string) {
+				clicked = variant
+			})
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/lelelele", nil)
+			request.SetPathValue("id", "lelelele")
+			if test.cookie != "" {
+				request.AddCookie(&http.Cookie{Name: "split_lelelele", Value: test.cookie})
+			}
+			NewRedirectToOriginalURLHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, http.StatusPermanentRedirect, res.StatusCode)
+			assert.Equal(t, "no-store", res.Header.Get("Cache-Control"), "the split redirect must not be cached")
+			location := res.Header.Get("Location")
+			if test.wantLocation != "" {
+				assert.Equal(t, test.wantLocation, location)
+			}
+			variant := split.Variant(clicked)
+			require.NotNil(t, variant)
+			assert.Equal(t, variant.URL, location, "the click is counted on the chosen variant")
+			cookies := res.Cookies()
+			require.Len(t, cookies, 1)
+			assert.Equal(t, "split_lelelele", cookies[0].Name)
+			assert.Equal(t, clicked, cookies[0].Value)
+			assert.Equal(t, "/lelelele", cookies[0].Path)
+			assert.True(t, cookies[0].HttpOnly)
+		})
+	}
+}
+
+func Test_pickVariant(t *testing.T) {
+	variants := []models.SplitVariant{{Name: "a", Weight: 1}, {Name: "b", Weight: 3}, {Name: "c", Weight: 2}}
+	tests := []struct {
+		want string
+		roll int
+	}{
+		{roll: 0, want: "a"},
+		{roll: 1, want: "b"},
+		{roll: 3, want: "b"},
+		{roll: 4, want: "c"},
+		{roll: 5, want: "c"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, pickVariant(variants, tt.roll).Name, "roll %d", tt.roll)
+	}
+}
 
+func TestQRCodeHandler_ServeHTTP(t *testing.T) {
+	code := &models.QRCode{ContentType: "image/svg+xml", ETag: `"0123456789abcdef"`, Image: []byte("<svg></svg>")}
+	tests := []struct {
+		serviceErr      error
+		serviceResult   *models.QRCode
+		wantOptions     *models.QROptions
+		name            string
+		query           string
+		ifNoneMatch     string
+		wantBody        string
+		wantContentType string
+		wantStatus      int
+	}{
+		{
+			name:            "Rendered",
+			query:           "?format=svg&size=512&level=H",
+			wantOptions:     &models.QROptions{Format: "svg", Level: "H", Size: 512},
+			serviceResult:   code,
+			wantStatus:      http.StatusOK,
+			wantContentType: "image/svg+xml",
+			wantBody:        "<svg></svg>",
+		},
+		{
+			name:          "Not modified",
+			wantOptions:   &models.QROptions{},
+			ifNoneMatch:   `"fedcba9876543210", W/"0123456789abcdef"`,
+			serviceResult: code,
+			wantStatus:    http.StatusNotModified,
+		},
+		{
+			name:       "Invalid size",
+			query:      "?size=large",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Invalid options",
+			query:       "?format=gif",
+			wantOptions: &models.QROptions{Format: "gif"},
+			serviceErr:  service.ErrInvalidQROptions,
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "Not found",
+			wantOptions: &models.QROptions{},
+			serviceErr:  service.ErrShortURLNotFound,
+			wantStatus:  http.StatusNotFound,
+		},
+		{
+			name:        "Disabled",
+			wantOptions: &models.QROptions{},
+			serviceErr:  service.ErrShortURLDisabled,
+			wantStatus:  http.StatusGone,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
 			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
-			if test.mockExpect {
-				var requestData []models.ShortenBatchItemRequest
-				dec := json.NewDecoder(strings.NewReader(test.requestPayload))
-				if err := dec.Decode(&requestData); err != nil {
-					require.NoError(t, err, "failed to decode test.requestPayload")
-				}
-				var returnStruct []models.ShortenBatchItemResponse
-				for _, requestItem := range requestData {
-					returnStruct = append(returnStruct, models.ShortenBatchItemResponse{
-						CorrelationID: requestItem.CorrelationID,
-						ShortURL:      "http://localhost:8080/LELELELE",
-					})
-				}
-				shortURLServiceMock.EXPECT().
-					BatchCreate(context.Background(), requestData, gomock.Any()).
-					Return(returnStruct, nil)
+			if test.wantOptions != nil {
+				shortURLServiceMock.EXPECT().GetQRCode(context.Background(), "lelelele", *test.wantOptions).
+					Return(test.serviceResult, test.serviceErr)
 			}
-			body := strings.NewReader(test.requestPayload)
-			request := httptest.NewRequest(http.MethodPost, "/", body)
-			request.Header.Add("Content-Type", test.requestContentType)
 			recorder := httptest.NewRecorder()
-			handler := NewBatchCreateShortURLHandler(shortURLServiceMock)
-			handler.ServeHTTP(recorder, request)
+			request := httptest.NewRequest(http.MethodGet, "/lelelele/qr"+test.query, nil)
+			request.SetPathValue("id", "lelelele")
+			request.Header.Set("If-None-Match", test.ifNoneMatch)
+			NewQRCodeHandler(shortURLServiceMock).ServeHTTP(recorder, request)
 			res := recorder.Result()
-			assert.Equal(t, test.want.code, res.StatusCode)
 			defer res.Body.Close()
-			if test.want.errMessage != "" {
-				resBody, err := io.ReadAll(res.Body)
-				require.NoError(t, err)
-				assert.Equal(t, test.want.errMessage, string(resBody))
-				return
-			}
-			var responseData []models.ShortenBatchItemResponse
-			dec := json.NewDecoder(res.Body)
-			err := dec.Decode(&responseData)
+			body, err := io.ReadAll(res.Body)
 			require.NoError(t, err)
-			assert.Equal(t, test.want.payload, responseData)
-			assert.Equal(t, test.want.contentType, res.Header.Get("Content-Type"))
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+			if test.serviceResult != nil {
+				assert.Equal(t, test.serviceResult.ETag, res.Header.Get("ETag"))
+				assert.Equal(t, qrCacheControl, res.Header.Get("Cache-Control"))
+			}
+			if test.wantBody != "" {
+				assert.Equal(t, test.wantContentType, res.Header.Get("Content-Type"))
+				assert.Equal(t, test.wantBody, string(body))
+			}
 		})
 	}
 }
 
-func TestNewGetAllURLsForUserHandler(t *testing.T) {
+func Test_etagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`"abc"`, `"abc"`))
+	assert.True(t, etagMatches(`"xyz", W/"abc"`, `"abc"`))
+	assert.True(t, etagMatches(`*`, `"abc"`))
+	assert.False(t, etagMatches(``, `"abc"`))
+	assert.False(t, etagMatches(`"abcd"`, `"abc"`))
+}
+
+func TestNewUnlockShortURLHandler(t *testing.T) {
 	type args struct {
 		service service.ShortURLServiceInterface
 	}
 	tests := []struct {
 		args args
-		want *GetAllURLsForUserHandler
+		want *UnlockShortURLHandler
 		name string
 	}{
 		{
-			name: "Successful creation of all URLs for user handler",
-			args: args{
-				service: &ServiceForTest,
-			},
-			want: &GetAllURLsForUserHandler{
-				service: &ServiceForTest,
-			},
+			name: "Successful handler creation",
+			args: args{service: &ServiceForTest},
+			want: &UnlockShortURLHandler{service: &ServiceForTest},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, NewGetAllURLsForUserHandler(tt.args.service), "NewGetAllURLsForUserHandler(%v)", tt.args.service)
+			assert.Equalf(t, tt.want, NewUnlockShortURLHandler(tt.args.service), "NewUnlockShortURLHandler(%v)", tt.args.service)
 		})
 	}
 }
 
-func TestGetAllURLsForUserHandler_ServeHTTP(t *testing.T) {
-	type want struct {
-		contentType string
-		payload     []models.ShortURLsByUserResponse
-		code        int
-	}
+func TestUnlockShortURLHandler_ServeHTTP(t *testing.T) {
+	protected := &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru",
+		ShortURLOptions: models.ShortURLOptions{PasswordHash: "hash"}}
+	protectedInterstitial := &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru",
+		ShortURLOptions: models.ShortURLOptions{PasswordHash: "hash",
+			RedirectOptions: models.RedirectOptions{Interstitial: true}}}
 	tests := []struct {
-		name      string
-		mockValue []models.ShortURLsByUserResponse
-		want      want
+		shortURL     *models.ShortURL
+		checkErr     error
+		name         string
+		wantLocation string
+		wantBody     string
+		wantCode     int
 	}{
 		{
-			name: "Successful return of all URLs for the user",
-			want: want{
-				code:        http.StatusOK,
-				contentType: "application/json",
-				payload: []models.ShortURLsByUserResponse{
-					{
-						ShortURL:    "http://localhost:8080/lelele",
-						OriginalURL: "http://ya.ru",
-					},
-					{
-						ShortURL:    "http://localhost:8080/lololo",
-						OriginalURL: "http://yandex.ru",
-					},
-				},
-			},
-			mockValue: []models.ShortURLsByUserResponse{
-				{
-					ShortURL:    "http://localhost:8080/lelele",
-					OriginalURL: "http://ya.ru",
-				},
-				{
-					ShortURL:    "http://localhost:8080/lololo",
-					OriginalURL: "http://yandex.ru",
-				},
-			},
+			name:         "Successful unlock",
+			shortURL:     protected,
+			wantCode:     http.StatusSeeOther,
+			wantLocation: "https://ya.ru",
 		},
 		{
-			name: "Successful return of empty URLs list",
-			want: want{
-				code:        http.StatusNoContent,
-				contentType: "application/json",
-				payload:     nil,
-			},
-			mockValue: []models.ShortURLsByUserResponse{},
+			name:     "Successful unlock of the link with the interstitial page",
+			shortURL: protectedInterstitial,
+			wantCode: http.StatusOK,
+			wantBody: "You are about to leave",
+		},
+		{
+			name:     "Wrong password shows the prompt again",
+			shortURL: protected,
+			checkErr: service.ErrWrongPassword,
+			wantCode: http.StatusForbidden,
+			wantBody: "Wrong password",
+		},
+		{
+			name:     "Too many attempts",
+			shortURL: protected,
+			checkErr: service.NewErrTooManyAttempts(service.ErrTooManyAttempts, time.Minute),
+			wantCode: http.StatusTooManyRequests,
+			wantBody: "Too many password attempts",
+		},
+		{
+			name:     "Unexpected error",
+			shortURL: protected,
+			checkErr: errors.New("crypto/bcrypt: hashedSecret too short"),
+			wantCode: http.StatusInternalServerError,
+			wantBody: "Something went wrong",
 		},
 	}
 	for _, test := range tests {
@@ -732,97 +1068,1666 @@ func TestGetAllURLsForUserHandler_ServeHTTP(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
-			shortURLServiceMock.EXPECT().
-				ReadByUserID(context.Background(), gomock.Any()).
-				Return(test.mockValue, nil)
-			request := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+			shortURLServiceMock.EXPECT().Resolve(gomock.Any(), "lelelele").Return(test.shortURL, nil)
+			shortURLServiceMock.EXPECT().CheckPassword(test.shortURL, "secret", "192.0.2.1").Return(test.checkErr)
 			recorder := httptest.NewRecorder()
-			handler := NewGetAllURLsForUserHandler(shortURLServiceMock)
+			request := httptest.NewRequest(http.MethodPost, "/lelelele", strings.NewReader("password=secret"))
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			request.SetPathValue("id", "lelelele")
+			handler := NewUnlockShortURLHandler(shortURLServiceMock)
 			handler.ServeHTTP(recorder, request)
 			res := recorder.Result()
-			assert.Equal(t, test.want.code, res.StatusCode)
 			defer res.Body.Close()
-
-			if test.want.payload != nil {
-				var responseData []models.ShortURLsByUserResponse
-				dec := json.NewDecoder(res.Body)
-				err := dec.Decode(&responseData)
-				require.NoError(t, err)
-				assert.Equal(t, test.want.payload, responseData)
-				assert.Equal(t, test.want.contentType, res.Header.Get("Content-Type"))
-			}
+			assert.Equal(t, test.wantCode, res.StatusCode)
+			assert.Equal(t, test.wantLocation, res.Header.Get("Location"))
+			resBody, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(resBody), test.wantBody)
 		})
 	}
 }
 
-func TestNewGetStatsHandler(t *testing.T) {
+func TestNewPreviewShortURLHandler(t *testing.T) {
 	type args struct {
 		service service.ShortURLServiceInterface
 	}
 	tests := []struct {
 		args args
-		want *GetStatsHandler
+		want *PreviewShortURLHandler
 		name string
 	}{
 		{
-			name: "Successful creation of stats handler",
-			args: args{
-				service: &ServiceForTest,
-			},
-			want: &GetStatsHandler{
-				service: &ServiceForTest,
-			},
+			name: "Successful handler creation",
+			args: args{service: &ServiceForTest},
+			want: &PreviewShortURLHandler{service: &ServiceForTest},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, NewGetStatsHandler(tt.args.service), "NewGetStatsHandler(%v)", tt.args.service)
+			assert.Equalf(t, tt.want, NewPreviewShortURLHandler(tt.args.service), "NewPreviewShortURLHandler(%v)", tt.args.service)
 		})
 	}
 }
 
-func TestGetStatsHandler_ServeHTTP(t *testing.T) {
-	type want struct {
-		payload     *models.ServiceStats
-		contentType string
-		code        int
-	}
+func TestPreviewShortURLHandler_ServeHTTP(t *testing.T) {
 	tests := []struct {
-		mockValue *models.ServiceStats
-		name      string
-		want      want
+		mockValue   *models.ShortURL
+		mockError   error
+		name        string
+		contains    []string
+		notContains []string
+		wantCode    int
 	}{
 		{
-			name: "Successful get stats",
-			mockValue: &models.ServiceStats{
-				Users: 1337,
-				URLs:  1338,
+			name: "Successful preview with the user-defined title",
+			mockValue: &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru",
+				ShortURLOptions: models.ShortURLOptions{Title: "Yandex"}},
+			wantCode: http.StatusOK,
+			contains: []string{"https://ya.ru", "Yandex"},
+		},
+		{
+			name: "Successful preview with the title of the destination page",
+			mockValue: &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru",
+				Metadata: &models.PageMetadata{Title: "Search engine"}},
+			wantCode: http.StatusOK,
+			contains: []string{"https://ya.ru", "Search engine"},
+		},
+		{
+			name:      "Preview escapes the destination",
+			mockValue: &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru/?q=<script>"},
+			wantCode:  http.StatusOK,
+			contains:  []string{"&lt;script&gt;"},
+		},
+		{
+			name: "Preview of the protected URL asks for the password",
+			mockValue: &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru",
+				ShortURLOptions: models.ShortURLOptions{PasswordHash: "hash"}},
+			wantCode: http.StatusOK,
+			contains: []string{"Password required"},
+		},
+		{
+			name: "Preview of the click-limited URL hides the destination",
+			mockValue: &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru/one-time",
+				ShortURLOptions: models.ShortURLOptions{MaxClicks: 1}, ClicksLeft: 1},
+			wantCode:    http.StatusOK,
+			contains:    []string{"limited number of times", "/lelelele"},
+			notContains: []string{"https://ya.ru/one-time"},
+		},
+		{
+			name:      "Unsuccessful preview of the deleted URL",
+			mockValue: &models.ShortURL{ShortURL: "lelelele", OriginalURL: "https://ya.ru", Deleted: true},
+			wantCode:  http.StatusGone,
+		},
+		{
+			name:      "Unsuccessful preview of the non-existing URL",
+			mockError: service.ErrShortURLNotFound,
+			wantCode:  http.StatusNotFound,
+			contains:  []string{"Short url not found"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			shortURLServiceMock.EXPECT().Resolve(context.Background(), "lelelele").Return(test.mockValue, test.mockError)
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/lelelele+", nil)
+			request.SetPathValue("id", "lelelele")
+			handler := NewPreviewShortURLHandler(shortURLServiceMock)
+			handler.ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.wantCode, res.StatusCode)
+			resBody, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			for _, value := range test.contains {
+				assert.Contains(t, string(resBody), value)
+			}
+			for _, value := range test.notContains {
+				assert.NotContains(t, string(resBody), value)
+			}
+			if test.wantCode == http.StatusOK {
+				assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+				assert.Empty(t, res.Header.Get("Location"))
+			}
+		})
+	}
+}
+
+func TestNewCreateJSONShortURLHandler(t *testing.T) {
+	type args struct {
+		service service.ShortURLServiceInterface
+	}
+	tests := []struct {
+		args args
+		want *CreateJSONShortURLHandler
+		name string
+	}{
+		{
+			name: "success",
+			args: args{
+				service: &ServiceForTest,
+			},
+			want: &CreateJSONShortURLHandler{service: &ServiceForTest},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, NewCreateJSONShortURLHandler(tt.args.service), "NewCreateJSONShortURLHandler(%v)", tt.args.service)
+		})
+	}
+}
+
+func TestCreateJSONShortURLHandler_ServeHTTP(t *testing.T) {
+	type want struct {
+		contentType string
+		errMessage  string
+		code        int
+	}
+	tests := []struct {
+		name               string
+		requestPayload     string
+		requestContentType string
+		want               want
+		mockExpect         bool
+	}{
+		{
+			name:               "Successful creation of the short URL",
+			requestPayload:     `{"url": "https://ya.ru"}`,
+			requestContentType: "application/json",
+			mockExpect:         true,
+			want: want{
+				code:        http.StatusCreated,
+				contentType: "application/json",
+				errMessage:  "",
 			},
+		},
+		{
+			name:               "Empty URL passed",
+			requestPayload:     `{"url": ""}`,
+			requestContentType: "application/json",
+			mockExpect:         false,
 			want: want{
-				code:        http.StatusOK,
+				code:        http.StatusBadRequest,
 				contentType: "application/json",
-				payload: &models.ServiceStats{
-					Users: 1337,
-					URLs:  1338,
-				},
+				errMessage:  "Please provide an url\n",
+			},
+		},
+		{
+			name:               "Invalid URL passed",
+			requestPayload:     `{"url": "asdasdsa"}`,
+			requestContentType: "application/json",
+			mockExpect:         false,
+			want: want{
+				code:        http.StatusBadRequest,
+				contentType: "application/json",
+				errMessage:  "The provided payload is not a valid URL\n",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			if test.mockExpect {
+				shortURLServiceMock.EXPECT().Create(context.Background(), gomock.Any(), gomock.Any(), gomock.Any()).Return("http://localhost:8080/lelelele", nil)
+			}
+			body := strings.NewReader(test.requestPayload)
+			request := httptest.NewRequest(http.MethodPost, "/", body)
+			request.Header.Add("Content-Type", test.requestContentType)
+			recorder := httptest.NewRecorder()
+			handler := NewCreateJSONShortURLHandler(shortURLServiceMock)
+			handler.ServeHTTP(recorder, request)
+			res := recorder.Result()
+			assert.Equal(t, test.want.code, res.StatusCode)
+			defer res.Body.Close()
+			if test.want.errMessage != "" {
+				resBody, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, test.want.errMessage, string(resBody))
+				return
+			}
+			var responseData models.ShortenResponse
+			dec := json.NewDecoder(res.Body)
+			err := dec.Decode(&responseData)
+			require.NoError(t, err)
+			assert.Contains(t, responseData.Result, config.Settings.HostedOn)
+			assert.Equal(t, test.want.contentType, res.Header.Get("Content-Type"))
+		})
+	}
+}
+
+func TestNewPingHandler(t *testing.T) {
+	type args struct {
+		service service.ShortURLServiceInterface
+	}
+	tests := []struct {
+		args args
+		want *PingHandler
+		name string
+	}{
+		{
+			name: "success",
+			args: args{
+				service: &ServiceForTest,
 			},
+			want: &PingHandler{service: &ServiceForTest},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, NewPingHandler(tt.args.service), "NewPingHandler(%v)", tt.args.service)
+		})
+	}
+}
+
+func TestPingHandler_ServeHTTP(t *testing.T) {
+	type want struct {
+		errMessage string
+		code       int
+	}
+	tests := []struct {
+		name        string
+		want        want
+		wantSuccess bool
+	}{
+		{
+			name:        "Successful ping",
+			wantSuccess: true,
+			want: want{
+				code:       200,
+				errMessage: "",
+			},
+		},
+		{
+			name:        "Unsuccessful ping",
+			wantSuccess: false,
+			want: want{
+				code:       500,
+				errMessage: "Database is not available\n",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
+
 			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
-			shortURLServiceMock.EXPECT().
-				GetStats(context.Background()).
-				Return(tt.mockValue, nil)
-			request := httptest.NewRequest(http.MethodGet, "/api/internal/stats", nil)
+			if test.wantSuccess {
+				shortURLServiceMock.EXPECT().Ping(context.Background()).Return(nil)
+			} else {
+				shortURLServiceMock.EXPECT().Ping(context.Background()).Return(errors.New("database is not available"))
+			}
+			request := httptest.NewRequest(http.MethodGet, "/ping", nil)
 			recorder := httptest.NewRecorder()
-			handler := NewGetStatsHandler(shortURLServiceMock)
-			handler.ServeHTTP(recorder, request)
+			pingHandler := NewPingHandler(shortURLServiceMock)
+			pingHandler.ServeHTTP(recorder, request)
 			res := recorder.Result()
-			assert.Equal(t, tt.want.code, res.StatusCode)
 			defer res.Body.Close()
+			assert.Equal(t, test.want.code, res.StatusCode)
+			if res.StatusCode > http.StatusOK {
+				resBody, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, test.want.errMessage, string(resBody))
+			}
+		})
+	}
+}
+
+func TestNewBatchCreateShortURLHandler(t *testing.T) {
+	type args struct {
+		service service.ShortURLServiceInterface
+	}
+	tests := []struct {
+		args args
+		want *BatchCreateShortURLHandler
+		name string
+	}{
+		{
+			name: "success",
+			args: args{
+				service: &ServiceForTest,
+			},
+			want: &BatchCreateShortURLHandler{
+				service: &ServiceForTest,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, NewBatchCreateShortURLHandler(tt.args.service), "NewBatchCreateShortURLHandler(%v)", tt.args.service)
 		})
 	}
 }
+
+func TestBatchCreateShortURLHandler_ServeHTTP(t *testing.T) {
+	type want struct {
+		contentType string
+		errMessage  string
+		payload     []models.ShortenBatchItemResponse
+		code        int
+	}
+	tests := []struct {
+		name               string
+		requestPayload     string
+		requestContentType string
+		want               want
+		mockExpect         bool
+	}{
+		{
+			name: "Successful creation of batch for short url",
+			requestPayload: `[
+				{"original_url": "https://ya.ru", "correlation_id": "lelele"},
+				{"original_url": "https://yandex.ru", "correlation_id": "lololo"}
+			]`,
+			requestContentType: "application/json",
+			mockExpect:         true,
+			want: want{
+				code:        http.StatusCreated,
+				contentType: "application/json",
+				payload: []models.ShortenBatchItemResponse{
+					{CorrelationID: "lelele", ShortURL: "http://localhost:8080/LELELELE"},
+					{CorrelationID: "lololo", ShortURL: "http://localhost:8080/LELELELE"},
+				},
+				errMessage: "",
+			},
+		},
+		{
+			name:               "Successful creation of batch for short url with single url",
+			requestPayload:     `[{"original_url": "https://ya.ru", "correlation_id": "lelele"}]`,
+			requestContentType: "application/json",
+			mockExpect:         true,
+			want: want{
+				code:        http.StatusCreated,
+				contentType: "application/json",
+				payload: []models.ShortenBatchItemResponse{
+					{CorrelationID: "lelele", ShortURL: "http://localhost:8080/LELELELE"},
+				},
+				errMessage: "",
+			},
+		},
+		{
+			name:               "Unsuccessful creation of batch for short url with empty batch",
+			requestPayload:     `[]`,
+			requestContentType: "application/json",
+			mockExpect:         false,
+			want: want{
+				code:        http.StatusBadRequest,
+				contentType: "application/json",
+				payload:     nil,
+				errMessage:  "Please provide a batch of URLs\n",
+			},
+		},
+		{
+			name: "Successful creation of batch for short url",
+			requestPayload: `[
+				{"original_url": "lele://ya.ru", "correlation_id": "lelele"},
+				{"original_url": "https://yandex.ru", "correlation_id": "lololo"}
+			]`,
+			requestContentType: "application/json",
+			mockExpect:         false,
+			want: want{
+				code:        http.StatusBadRequest,
+				contentType: "application/json",
+				payload:     nil,
+				errMessage:  "One of the provided items is not a valid URL\n",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			if test.mockExpect {
+				var requestData []models.ShortenBatchItemRequest
+				dec := json.NewDecoder(strings.NewReader(test.requestPayload))
+				if err := dec.Decode(&requestData); err != nil {
+					require.NoError(t, err, "failed to decode test.requestPayload")
+				}
+				var returnStruct []models.ShortenBatchItemResponse
+				for _, requestItem := range requestData {
+					returnStruct = append(returnStruct, models.ShortenBatchItemResponse{
+						CorrelationID: requestItem.CorrelationID,
+						ShortURL:      "http://localhost:8080/LELELELE",
+					})
+				}
+				shortURLServiceMock.EXPECT().
+					BatchCreate(context.Background(), requestData, gomock.Any()).
+					Return(returnStruct, nil)
+			}
+			body := strings.NewReader(test.requestPayload)
+			request := httptest.NewRequest(http.MethodPost, "/", body)
+			request.Header.Add("Content-Type", test.requestContentType)
+			recorder := httptest.NewRecorder()
+			handler := NewBatchCreateShortURLHandler(shortURLServiceMock)
+			handler.ServeHTTP(recorder, request)
+			res := recorder.Result()
+			assert.Equal(t, test.want.code, res.StatusCode)
+			defer res.Body.Close()
+			if test.want.errMessage != "" {
+				resBody, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, test.want.errMessage, string(resBody))
+				return
+			}
+			var responseData []models.ShortenBatchItemResponse
+			dec := json.NewDecoder(res.Body)
+			err := dec.Decode(&responseData)
+			require.NoError(t, err)
+			assert.Equal(t, test.want.payload, responseData)
+			assert.Equal(t, test.want.contentType, res.Header.Get("Content-Type"))
+		})
+	}
+}
+
+func TestNewGetAllURLsForUserHandler(t *testing.T) {
+	type args struct {
+		service service.ShortURLServiceInterface
+	}
+	tests := []struct {
+		args args
+		want *GetAllURLsForUserHandler
+		name string
+	}{
+		{
+			name: "Successful creation of all URLs for user handler",
+			args: args{
+				service: &ServiceForTest,
+			},
+			want: &GetAllURLsForUserHandler{
+				service: &ServiceForTest,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, NewGetAllURLsForUserHandler(tt.args.service), "NewGetAllURLsForUserHandler(%v)", tt.args.service)
+		})
+	}
+}
+
+func TestGetAllURLsForUserHandler_ServeHTTP(t *testing.T) {
+	type want struct {
+		contentType string
+		payload     []models.ShortURLsByUserResponse
+		code        int
+	}
+	tests := []struct {
+		name      string
+		mockValue []models.ShortURLsByUserResponse
+		want      want
+	}{
+		{
+			name: "Successful return of all URLs for the user",
+			want: want{
+				code:        http.StatusOK,
+				contentType: "application/json",
+				payload: []models.ShortURLsByUserResponse{
+					{
+						ShortURL:    "http://localhost:8080/lelele",
+						OriginalURL: "http://ya.ru",
+					},
+					{
+						ShortURL:    "http://localhost:8080/lololo",
+						OriginalURL: "http://yandex.ru",
+					},
+				},
+			},
+			mockValue: []models.ShortURLsByUserResponse{
+				{
+					ShortURL:    "http://localhost:8080/lelele",
+					OriginalURL: "http://ya.ru",
+				},
+				{
+					ShortURL:    "http://localhost:8080/lololo",
+					OriginalURL: "http://yandex.ru",
+				},
+			},
+		},
+		{
+			name: "Successful return of empty URLs list",
+			want: want{
+				code:        http.StatusNoContent,
+				contentType: "application/json",
+				payload:     nil,
+			},
+			mockValue: []models.ShortURLsByUserResponse{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			shortURLServiceMock.EXPECT().
+				ReadByUserID(context.Background(), gomock.Any(), models.ShortURLFilter{}).
+				Return(test.mockValue, nil)
+			request := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+			recorder := httptest.NewRecorder()
+			handler := NewGetAllURLsForUserHandler(shortURLServiceMock)
+			handler.ServeHTTP(recorder, request)
+			res := recorder.Result()
+			assert.Equal(t, test.want.code, res.StatusCode)
+			defer res.Body.Close()
+
+			if test.want.payload != nil {
+				var responseData []models.ShortURLsByUserResponse
+				dec := json.NewDecoder(res.Body)
+				err := dec.Decode(&responseData)
+				require.NoError(t, err)
+				assert.Equal(t, test.want.payload, responseData)
+				assert.Equal(t, test.want.contentType, res.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestNewGetStatsHandler(t *testing.T) {
+	type args struct {
+		service service.ShortURLServiceInterface
+	}
+	tests := []struct {
+		args args
+		want *GetStatsHandler
+		name string
+	}{
+		{
+			name: "Successful creation of stats handler",
+			args: args{
+				service: &ServiceForTest,
+			},
+			want: &GetStatsHandler{
+				service: &ServiceForTest,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, NewGetStatsHandler(tt.args.service), "NewGetStatsHandler(%v)", tt.args.service)
+		})
+	}
+}
+
+func TestGetStatsHandler_ServeHTTP(t *testing.T) {
+	type want struct {
+		payload     *models.ServiceStats
+		contentType string
+		code        int
+	}
+	tests := []struct {
+		mockValue *models.ServiceStats
+		name      string
+		want      want
+	}{
+		{
+			name: "Successful get stats",
+			mockValue: &models.ServiceStats{
+				Users: 1337,
+				URLs:  1338,
+			},
+			want: want{
+				code:        http.StatusOK,
+				contentType: "application/json",
+				payload: &models.ServiceStats{
+					Users: 1337,
+					URLs:  1338,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			shortURLServiceMock.EXPECT().
+				GetStats(context.Background()).
+				Return(tt.mockValue, nil)
+			request := httptest.NewRequest(http.MethodGet, "/api/internal/stats", nil)
+			recorder := httptest.NewRecorder()
+			handler := NewGetStatsHandler(shortURLServiceMock)
+			handler.ServeHTTP(recorder, request)
+			res := recorder.Result()
+			assert.Equal(t, tt.want.code, res.StatusCode)
+			defer res.Body.Close()
+		})
+	}
+}
+
+func TestNewUpdateShortURLHandler(t *testing.T) {
+	type args struct {
+		service service.ShortURLServiceInterface
+	}
+	tests := []struct {
+		args args
+		want *UpdateShortURLHandler
+		name string
+	}{
+		{
+			name: "Successful creation of update handler",
+			args: args{
+				service: &ServiceForTest,
+			},
+			want: &UpdateShortURLHandler{
+				service: &ServiceForTest,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, NewUpdateShortURLHandler(tt.args.service), "NewUpdateShortURLHandler(%v)", tt.args.service)
+		})
+	}
+}
+
+func TestUpdateShortURLHandler_ServeHTTP(t *testing.T) {
+	type want struct {
+		payload     *models.ShortURLsByUserResponse
+		contentType string
+		code        int
+	}
+	tests := []struct {
+		mockError   error
+		mockValue   *models.ShortURLsByUserResponse
+		name        string
+		contentType string
+		body        string
+		want        want
+		callService bool
+	}{
+		{
+			name:        "Successful update",
+			contentType: "application/json",
+			body:        `{"title": "Yandex", "tags": ["search"]}`,
+			callService: true,
+			mockValue: &models.ShortURLsByUserResponse{
+				ShortURL:        "http://localhost:8080/lelele",
+				OriginalURL:     "http://ya.ru",
+				ShortURLOptions: models.ShortURLOptions{Title: "Yandex", Tags: []string{"search"}},
+			},
+			want: want{
+				code:        http.StatusOK,
+				contentType: "application/json",
+				payload: &models.ShortURLsByUserResponse{
+					ShortURL:        "http://localhost:8080/lelele",
+					OriginalURL:     "http://ya.ru",
+					ShortURLOptions: models.ShortURLOptions{Title: "Yandex", Tags: []string{"search"}},
+				},
+			},
+		},
+		{
+			name:        "Wrong content type",
+			contentType: "text/plain",
+			body:        `{"title": "Yandex"}`,
+			want:        want{code: http.StatusBadRequest},
+		},
+		{
+			name:        "Broken JSON",
+			contentType: "application/json",
+			body:        `{"title": `,
+			want:        want{code: http.StatusBadRequest},
+		},
+		{
+			name:        "Not found",
+			contentType: "application/json",
+			body:        `{"title": "Yandex"}`,
+			callService: true,
+			mockError:   service.ErrShortURLNotFound,
+			want:        want{code: http.StatusNotFound},
+		},
+		{
+			name:        "Belongs to another user",
+			contentType: "application/json",
+			body:        `{"title": "Yandex"}`,
+			callService: true,
+			mockError:   service.ErrForbidden,
+			want:        want{code: http.StatusForbidden},
+		},
+		{
+			name:        "Banned user",
+			contentType: "application/json",
+			body:        `{"title": "Yandex"}`,
+			callService: true,
+			mockError:   service.ErrUserBanned,
+			want:        want{code: http.StatusForbidden},
+		},
+		{
+			name:        "Invalid attributes",
+			contentType: "application/json",
+			body:        `{"tags": ["news,search"]}`,
+			callService: true,
+			mockError:   service.ErrInvalidOptions,
+			want:        want{code: http.StatusBadRequest},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			if test.callService {
+				shortURLServiceMock.EXPECT().
+					Update(gomock.Any(), "lelele", gomock.Any(), gomock.Any()).
+					Return(test.mockValue, test.mockError)
+			}
+			request := httptest.NewRequest(http.MethodPatch, "/api/user/urls/lelele", strings.NewReader(test.body))
+			request.Header.Set("Content-Type", test.contentType)
+			request.SetPathValue("id", "lelele")
+			recorder := httptest.NewRecorder()
+			handler := NewUpdateShortURLHandler(shortURLServiceMock)
+			handler.ServeHTTP(recorder, request)
+			res := recorder.Result()
+			assert.Equal(t, test.want.code, res.StatusCode)
+			defer res.Body.Close()
+
+			if test.want.payload != nil {
+				var responseData models.ShortURLsByUserResponse
+				dec := json.NewDecoder(res.Body)
+				err := dec.Decode(&responseData)
+				require.NoError(t, err)
+				assert.Equal(t, *test.want.payload, responseData)
+				assert.Equal(t, test.want.contentType, res.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestNewCreateUTMTemplateHandler(t *testing.T) {
+	assert.Equal(t, &CreateUTMTemplateHandler{service: &ServiceForTest}, NewCreateUTMTemplateHandler(&ServiceForTest))
+}
+
+func TestCreateUTMTemplateHandler_ServeHTTP(t *testing.T) {
+	created := &models.UTMTemplate{ID: "template", Name: "Newsletter",
+		UTMParameters: models.UTMParameters{Source: "newsletter"}}
+	tests := []struct {
+		mockError   error
+		mockValue   *models.UTMTemplate
+		name        string
+		contentType string
+		body        string
+		wantCode    int
+		callService bool
+	}{
+		{
+			name:        "Successful creation",
+			contentType: "application/json",
+			body:        `{"name": "Newsletter", "utm_source": "newsletter"}`,
+			callService: true,
+			mockValue:   created,
+			wantCode:    http.StatusCreated,
+		},
+		{
+			name:        "Wrong content type",
+			contentType: "text/plain",
+			body:        `{"name": "Newsletter"}`,
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name:        "Invalid template",
+			contentType: "application/json",
+			body:        `{"name": "Newsletter"}`,
+			callService: true,
+			mockError:   service.ErrInvalidUTMTemplate,
+			wantCode:    http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			if test.callService {
+				shortURLServiceMock.EXPECT().
+					CreateUTMTemplate(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(test.mockValue, test.mockError)
+			}
+			request := httptest.NewRequest(http.MethodPost, "/api/user/utm-templates", strings.NewReader(test.body))
+			request.Header.Set("Content-Type", test.contentType)
+			recorder := httptest.NewRecorder()
+			NewCreateUTMTemplateHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.wantCode, res.StatusCode)
+			if test.mockValue != nil {
+				var responseData models.UTMTemplate
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&responseData))
+				assert.Equal(t, *test.mockValue, responseData)
+			}
+		})
+	}
+}
+
+func TestUpdateUTMTemplateHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	shortURLServiceMock.EXPECT().
+		UpdateUTMTemplate(gomock.Any(), "template", gomock.Any(),
+			models.UTMTemplate{Name: "Ads", UTMParameters: models.UTMParameters{Source: "google"}}).
+		Return(nil, service.ErrUTMTemplateNotFound)
+	request := httptest.NewRequest(http.MethodPut, "/api/user/utm-templates/template",
+		strings.NewReader(`{"name": "Ads", "utm_source": "google"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.SetPathValue("id", "template")
+	recorder := httptest.NewRecorder()
+	NewUpdateUTMTemplateHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestDeleteUTMTemplateHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	shortURLServiceMock.EXPECT().DeleteUTMTemplate(gomock.Any(), "template", gomock.Any()).Return(nil)
+	request := httptest.NewRequest(http.MethodDelete, "/api/user/utm-templates/template", nil)
+	request.SetPathValue("id", "template")
+	recorder := httptest.NewRecorder()
+	NewDeleteUTMTemplateHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+}
+
+func TestCreateCampaignHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	created := &models.Campaign{ID: "campaign", Name: "Black Friday"}
+	shortURLServiceMock.EXPECT().
+		CreateCampaign(gomock.Any(), gomock.Any(), models.Campaign{Name: "Black Friday"}).
+		Return(created, nil)
+	request := httptest.NewRequest(http.MethodPost, "/api/user/campaigns", strings.NewReader(`{"name": "Black Friday"}`))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	NewCreateCampaignHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	var responseData models.Campaign
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&responseData))
+	assert.Equal(t, *created, responseData)
+}
+
+func TestDeleteCampaignHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		mockError error
+		name      string
+		wantCode  int
+	}{
+		{
+			name:     "Successful deletion",
+			wantCode: http.StatusAccepted,
+		},
+		{
+			name:      "Campaign of another user",
+			mockError: service.ErrCampaignNotFound,
+			wantCode:  http.StatusNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			shortURLServiceMock.EXPECT().DeleteCampaign(gomock.Any(), "campaign", gomock.Any()).Return(test.mockError)
+			request := httptest.NewRequest(http.MethodDelete, "/api/user/campaigns/campaign", nil)
+			request.SetPathValue("id", "campaign")
+			recorder := httptest.NewRecorder()
+			NewDeleteCampaignHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.wantCode, res.StatusCode)
+		})
+	}
+}
+
+func TestGetCampaignStatsHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	stats := &models.CampaignStats{Campaign: models.Campaign{ID: "campaign", Name: "Sale"}, URLs: 3, Clicks: 42}
+	shortURLServiceMock.EXPECT().GetCampaignStats(gomock.Any(), "campaign", gomock.Any()).Return(stats, nil)
+	request := httptest.NewRequest(http.MethodGet, "/api/user/campaigns/campaign/stats", nil)
+	request.SetPathValue("id", "campaign")
+	recorder := httptest.NewRecorder()
+	NewGetCampaignStatsHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": "campaign", "name": "Sale", "urls": 3, "clicks": 42}`, string(body))
+}
+
+func TestGetAllURLsForUserHandler_CampaignFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	shortURLServiceMock.EXPECT().
+		ReadByUserID(gomock.Any(), gomock.Any(), models.ShortURLFilter{CampaignID: "campaign"}).
+		Return(nil, service.ErrCampaignNotFound)
+	request := httptest.NewRequest(http.MethodGet, "/api/user/urls?campaign=campaign", nil)
+	recorder := httptest.NewRecorder()
+	NewGetAllURLsForUserHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestCreateOrganizationHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	created := &models.Organization{ID: "org", Name: "Acme", Role: models.RoleOwner}
+	shortURLServiceMock.EXPECT().
+		CreateOrganization(gomock.Any(), gomock.Any(), models.Organization{Name: "Acme"}).
+		Return(created, nil)
+	request := httptest.NewRequest(http.MethodPost, "/api/orgs", strings.NewReader(`{"name": "Acme"}`))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	NewCreateOrganizationHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	var responseData models.Organization
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&responseData))
+	assert.Equal(t, *created, responseData)
+}
+
+func TestAddOrgMemberHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		mockError error
+		name      string
+		wantCode  int
+	}{
+		{
+			name:     "Successful addition",
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:      "Organization of other users",
+			mockError: service.ErrOrganizationNotFound,
+			wantCode:  http.StatusNotFound,
+		},
+		{
+			name:      "Role is too low",
+			mockError: service.ErrOrgForbidden,
+			wantCode:  http.StatusForbidden,
+		},
+		{
+			name:      "Unknown role",
+			mockError: service.ErrInvalidOrganization,
+			wantCode:  http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			var member *models.OrgMember
+			if test.mockError == nil {
+				member = &models.OrgMember{UserID: "MemberID", Email: "jane@example.com", Role: models.RoleEditor}
+			}
+			shortURLServiceMock.EXPECT().
+				AddOrgMember(gomock.Any(), "org", gomock.Any(),
+					models.AddOrgMemberRequest{Email: "jane@example.com", Role: models.RoleEditor}).
+				Return(member, test.mockError)
+			request := httptest.NewRequest(http.MethodPost, "/api/orgs/org/members",
+				strings.NewReader(`{"email": "jane@example.com", "role": "editor"}`))
+			request.Header.Set("Content-Type", "application/json")
+			request.SetPathValue("id", "org")
+			recorder := httptest.NewRecorder()
+			NewAddOrgMemberHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.wantCode, res.StatusCode)
+		})
+	}
+}
+
+func TestUpdateOrgMemberHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	shortURLServiceMock.EXPECT().UpdateOrgMember(gomock.Any(), "org", "MemberID", gomock.Any(), models.RoleAdmin).
+		Return(&models.OrgMember{UserID: "MemberID", Role: models.RoleAdmin}, nil)
+	request := httptest.NewRequest(http.MethodPut, "/api/orgs/org/members/MemberID", strings.NewReader(`{"role": "admin"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.SetPathValue("id", "org")
+	request.SetPathValue("userID", "MemberID")
+	recorder := httptest.NewRecorder()
+	NewUpdateOrgMemberHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user_id": "MemberID", "role": "admin", "created_at": "0001-01-01T00:00:00Z"}`, string(body))
+}
+
+func TestRemoveOrgMemberHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	shortURLServiceMock.EXPECT().RemoveOrgMember(gomock.Any(), "org", "MemberID", gomock.Any()).
+		Return(service.ErrOrgMemberNotFound)
+	request := httptest.NewRequest(http.MethodDelete, "/api/orgs/org/members/MemberID", nil)
+	request.SetPathValue("id", "org")
+	request.SetPathValue("userID", "MemberID")
+	recorder := httptest.NewRecorder()
+	NewRemoveOrgMemberHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestGetOrgURLsHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	shortURLServiceMock.EXPECT().ReadByOrgID(gomock.Any(), "org", gomock.Any(), models.ShortURLFilter{Tag: "search"}).
+		Return([]models.ShortURLsByUserResponse{{ShortURL: "http://localhost:8080/lelelele",
+			OriginalURL: "https://ya.ru", ShortURLOptions: models.ShortURLOptions{OrgID: "org"}}}, nil)
+	request := httptest.NewRequest(http.MethodGet, "/api/orgs/org/urls?tag=search", nil)
+	request.SetPathValue("id", "org")
+	recorder := httptest.NewRecorder()
+	NewGetOrgURLsHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var responseData []models.ShortURLsByUserResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&responseData))
+	require.Len(t, responseData, 1)
+	assert.Equal(t, "org", responseData[0].OrgID)
+}
+
+func TestCreateJSONShortURLHandler_OrgForbidden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	shortURLServiceMock.EXPECT().
+		Create(gomock.Any(), "https://ya.ru", gomock.Any(), models.ShortURLOptions{OrgID: "org"}).
+		Return("", service.ErrOrgForbidden)
+	request := httptest.NewRequest(http.MethodPost, "/api/shorten",
+		strings.NewReader(`{"url": "https://ya.ru", "org_id": "org"}`))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	NewCreateJSONShortURLHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func TestRegisterHandler_ServeHTTP(t *testing.T) {
+	expireHours := config.Settings.JWTExpireHours
+	config.Settings.JWTExpireHours = 1
+	defer func() { config.Settings.JWTExpireHours = expireHours }()
+	tests := []struct {
+		mockError error
+		name      string
+		wantCode  int
+	}{
+		{
+			name:     "Successful registration",
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:      "Email taken",
+			mockError: service.ErrAccountExists,
+			wantCode:  http.StatusConflict,
+		},
+		{
+			name:      "Invalid email",
+			mockError: service.ErrInvalidAccount,
+			wantCode:  http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			account := &models.Account{ID: "AnonymousUserID", Email: "jane@example.com"}
+			if test.mockError != nil {
+				account = nil
+			}
+			shortURLServiceMock.EXPECT().
+				Register(gomock.Any(), models.Credentials{Email: "jane@example.com", Password: "correct horse",
+					ClaimLinks: true}, "AnonymousUserID", "192.0.2.1").
+				Return(account, test.mockError)
+			request := httptest.NewRequest(http.MethodPost, "/api/user/register",
+				strings.NewReader(`{"email": "jane@example.com", "password": "correct horse", "claim_links": true}`))
+			request.Header.Set("Content-Type", "application/json")
+			request = request.WithContext(middlewares.WithUserID(request.Context(), "AnonymousUserID"))
+			recorder := httptest.NewRecorder()
+			NewRegisterHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.wantCode, res.StatusCode)
+			if account == nil {
+				assert.Empty(t, res.Cookies())
+				return
+			}
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.NotContains(t, string(body), "password", "the password hash is never returned")
+			require.Len(t, res.Cookies(), 1)
+			userID, err := middlewares.GetUserID(context.Background(), res.Cookies()[0].Value)
+			require.NoError(t, err)
+			assert.Equal(t, "AnonymousUserID", userID)
+		})
+	}
+}
+
+func TestLoginHandler_ServeHTTP(t *testing.T) {
+	expireHours := config.Settings.JWTExpireHours
+	config.Settings.JWTExpireHours = 1
+	defer func() { config.Settings.JWTExpireHours = expireHours }()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	shortURLServiceMock.EXPECT().Login(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&models.Account{ID: "AccountID", Email: "jane@example.com"}, nil)
+	request := httptest.NewRequest(http.MethodPost, "/api/user/login",
+		strings.NewReader(`{"email": "jane@example.com", "password": "correct horse"}`))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	NewLoginHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	require.Len(t, res.Cookies(), 1)
+	userID, err := middlewares.GetUserID(context.Background(), res.Cookies()[0].Value)
+	require.NoError(t, err)
+	assert.Equal(t, "AccountID", userID)
+
+	shortURLServiceMock.EXPECT().Login(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, service.NewErrTooManyAttempts(service.ErrTooManyAttempts, 30*time.Second))
+	request = httptest.NewRequest(http.MethodPost, "/api/user/login",
+		strings.NewReader(`{"email": "jane@example.com", "password": "wrong"}`))
+	request.Header.Set("Content-Type", "application/json")
+	recorder = httptest.NewRecorder()
+	NewLoginHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res = recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, "30", res.Header.Get("Retry-After"))
+}
+
+func TestLogoutHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/user/logout", nil)
+	recorder := httptest.NewRecorder()
+	NewLogoutHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode, "nothing to revoke without the auth cookie")
+	require.Len(t, res.Cookies(), 1)
+	assert.Equal(t, middlewares.AuthCookieName, res.Cookies()[0].Name)
+	assert.Negative(t, res.Cookies()[0].MaxAge)
+	assert.True(t, res.Cookies()[0].HttpOnly)
+
+	ctx := middlewares.WithTokenID(middlewares.WithUserID(context.Background(), "Jane"), "token-id")
+	shortURLServiceMock.EXPECT().RevokeToken(ctx, "token-id", "Jane").Return(nil)
+	request = httptest.NewRequest(http.MethodPost, "/api/user/logout", nil).WithContext(ctx)
+	recorder = httptest.NewRecorder()
+	NewLogoutHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res = recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+	shortURLServiceMock.EXPECT().RevokeToken(ctx, "token-id", "Jane").Return(errors.New("db is down"))
+	recorder = httptest.NewRecorder()
+	NewLogoutHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res = recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	assert.Empty(t, res.Cookies(), "the cookie is kept if the token isn't revoked")
+}
+
+func TestLogoutEverywhereHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	ctx := middlewares.WithUserID(context.Background(), "Jane")
+	shortURLServiceMock.EXPECT().RevokeAllTokens(ctx, "Jane").Return(nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/user/logout-all", nil).WithContext(ctx)
+	recorder := httptest.NewRecorder()
+	NewLogoutEverywhereHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.Len(t, res.Cookies(), 1)
+	assert.Negative(t, res.Cookies()[0].MaxAge)
+}
+
+func TestOIDCHandlers_NotConfigured(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewOIDCLoginHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/user/oidc/login", nil))
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	recorder = httptest.NewRecorder()
+	NewOIDCCallbackHandler(nil).ServeHTTP(recorder,
+		httptest.NewRequest(http.MethodGet, "/api/user/oidc/callback?code=c&state=s", nil))
+	res = recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestOIDCCallbackHandler_State(t *testing.T) {
+	idp := oidctest.NewServer("shortener", "secret")
+	defer idp.Close()
+	provider, err := oidc.NewProvider(context.Background(), idp.Config("http://localhost/callback"), idp.Client())
+	require.NoError(t, err)
+	oidc.SSO = provider
+	defer func() { oidc.SSO = nil }()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+
+	recorder := httptest.NewRecorder()
+	NewOIDCLoginHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/user/oidc/login", nil))
+	res := recorder.Result()
+	defer res.Body.Close()
+	require.Equal(t, http.StatusFound, res.StatusCode)
+	assert.True(t, strings.HasPrefix(res.Header.Get("Location"), idp.Issuer()+"/authorize?"))
+	require.Len(t, res.Cookies(), 1)
+	loginCookie := res.Cookies()[0]
+	assert.True(t, loginCookie.HttpOnly)
+
+	recorder = httptest.NewRecorder()
+	NewOIDCLoginHandler().ServeHTTP(recorder,
+		httptest.NewRequest(http.MethodGet, "/api/user/oidc/login?claim_links=maybe", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	tests := []struct {
+		cookie *http.Cookie
+		name   string
+		query  string
+	}{
+		{name: "no login cookie", query: "?code=c&state=s"},
+		{name: "forged state", cookie: loginCookie, query: "?code=c&state=forged"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/user/oidc/callback"+tt.query, nil)
+			if tt.cookie != nil {
+				request.AddCookie(tt.cookie)
+			}
+			recorder := httptest.NewRecorder()
+			NewOIDCCallbackHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		})
+	}
+
+	state := strings.Split(loginCookie.Value, ".")[0]
+	request := httptest.NewRequest(http.MethodGet, "/api/user/oidc/callback?error=access_denied&state="+state, nil)
+	request.AddCookie(loginCookie)
+	recorder = httptest.NewRecorder()
+	NewOIDCCallbackHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res = recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode, "the user refused to sign in")
+}
+
+func TestCreateAPIKeyHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		mockError error
+		name      string
+		wantCode  int
+	}{
+		{
+			name:     "Successful creation",
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:      "Too many keys",
+			mockError: service.ErrInvalidAPIKey,
+			wantCode:  http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			created := &models.CreatedAPIKey{Key: "usk_secret", APIKey: models.APIKey{ID: "key", Name: "CI",
+				Prefix: "usk_secr", KeyHash: "hash"}}
+			if test.mockError != nil {
+				created = nil
+			}
+			shortURLServiceMock.EXPECT().
+				CreateAPIKey(gomock.Any(), "APIKeysOwner", models.CreateAPIKeyRequest{Name: "CI"}).
+				Return(created, test.mockError)
+			request := httptest.NewRequest(http.MethodPost, "/api/user/api-keys", strings.NewReader(`{"name": "CI"}`))
+			request.Header.Set("Content-Type", "application/json")
+			request = request.WithContext(middlewares.WithUserID(request.Context(), "APIKeysOwner"))
+			recorder := httptest.NewRecorder()
+			NewCreateAPIKeyHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.wantCode, res.StatusCode)
+			if created == nil {
+				return
+			}
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), `"key":"usk_secret"`)
+			assert.NotContains(t, string(body), "hash", "the hash of the key is never returned")
+		})
+	}
+}
+
+func TestGetAPIKeysHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	shortURLServiceMock.EXPECT().ReadAPIKeysByUserID(gomock.Any(), gomock.Any()).Return(nil, nil)
+	request := httptest.NewRequest(http.MethodGet, "/api/user/api-keys", nil)
+	recorder := httptest.NewRecorder()
+	NewGetAPIKeysHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+}
+
+func TestDeleteAPIKeyHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		mockError error
+		name      string
+		wantCode  int
+	}{
+		{
+			name:     "Successful revocation",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:      "Key of another user",
+			mockError: service.ErrAPIKeyNotFound,
+			wantCode:  http.StatusNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			shortURLServiceMock.EXPECT().DeleteAPIKey(gomock.Any(), "key", gomock.Any()).Return(test.mockError)
+			request := httptest.NewRequest(http.MethodDelete, "/api/user/api-keys/key", nil)
+			request.SetPathValue("id", "key")
+			recorder := httptest.NewRecorder()
+			NewDeleteAPIKeyHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.wantCode, res.StatusCode)
+		})
+	}
+}
+
+func TestJWKSHandler_ServeHTTP(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	recorder := httptest.NewRecorder()
+	NewJWKSHandler().ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	var responseData middlewares.JWKSet
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&responseData))
+	assert.Empty(t, responseData.Keys, "the HS256 secret is never published")
+}
+
+func TestGetShortURLStatsHandler_ServeHTTP(t *testing.T) {
+	stats := &models.ShortURLStats{ShortURL: "http://localhost:8080/lelelele", Variants: []models.VariantStats{
+		{SplitVariant: models.SplitVariant{Name: "a", URL: "https://ya.ru/a", Weight: 1}, Clicks: 10},
+	}}
+	tests := []struct {
+		mockValue *models.ShortURLStats
+		mockError error
+		name      string
+		wantCode  int
+	}{
+		{
+			name:      "Successful stats test",
+			mockValue: stats,
+			wantCode:  http.StatusOK,
+		},
+		{
+			name:      "Stats of another user test",
+			mockError: service.ErrForbidden,
+			wantCode:  http.StatusForbidden,
+		},
+		{
+			name:      "Stats of non-existing short URL test",
+			mockError: service.ErrShortURLNotFound,
+			wantCode:  http.StatusNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			shortURLServiceMock.EXPECT().GetShortURLStats(gomock.Any(), "lelelele", "SomeUserID").
+				Return(test.mockValue, test.mockError)
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/api/user/urls/lelelele/stats", nil)
+			request.SetPathValue("id", "lelelele")
+			request = request.WithContext(middlewares.WithUserID(request.Context(), "SomeUserID"))
+			NewGetShortURLStatsHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.wantCode, res.StatusCode)
+			if test.wantCode == http.StatusOK {
+				var responseData models.ShortURLStats
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&responseData))
+				assert.Equal(t, *stats, responseData)
+			}
+		})
+	}
+}
+
+func TestDisableShortURLHandler_ServeHTTP(t *testing.T) {
+	disabled := &models.AdminShortURL{
+		Moderation:  &models.Moderation{DisabledAt: time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC), Reason: "Phishing"},
+		ShortURL:    "http://localhost:8080/lelelele",
+		OriginalURL: "https://login.example.com",
+		UserID:      "SomeUserID",
+	}
+	tests := []struct {
+		mockValue *models.AdminShortURL
+		mockError error
+		name      string
+		body      string
+		wantCode  int
+		mocked    bool
+	}{
+		{
+			name:      "Successful disable test",
+			body:      `{"reason": "Phishing"}`,
+			mockValue: disabled,
+			wantCode:  http.StatusOK,
+			mocked:    true,
+		},
+		{
+			name:      "Disable without reason test",
+			body:      `{}`,
+			mockError: service.ErrInvalidModeration,
+			wantCode:  http.StatusBadRequest,
+			mocked:    true,
+		},
+		{
+			name:      "Disable non-existing short URL test",
+			body:      `{"reason": "Phishing"}`,
+			mockError: service.ErrShortURLNotFound,
+			wantCode:  http.StatusNotFound,
+			mocked:    true,
+		},
+		{
+			name:     "Disable with invalid body test",
+			body:     `reason`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			if test.mocked {
+				shortURLServiceMock.EXPECT().DisableShortURL(gomock.Any(), "lelelele", gomock.Any()).
+					Return(test.mockValue, test.mockError)
+			}
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/api/internal/urls/lelelele/disable", strings.NewReader(test.body))
+			request.Header.Set("Content-Type", "application/json")
+			request.SetPathValue("id", "lelelele")
+			NewDisableShortURLHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+			res := recorder.Result()
+			defer res.Body.Close()
+			assert.Equal(t, test.wantCode, res.StatusCode)
+			if test.wantCode == http.StatusOK {
+				var responseData models.AdminShortURL
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&responseData))
+				assert.Equal(t, *disabled, responseData)
+			}
+		})
+	}
+}
+
+func TestSearchShortURLsHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	found := []models.AdminShortURL{{ShortURL: "http://localhost:8080/lelelele", OriginalURL: "https://login.example.com"}}
+	shortURLServiceMock.EXPECT().
+		SearchShortURLs(gomock.Any(), models.ShortURLSearch{Domain: "example.com", Limit: 10}).
+		Return(found, nil)
+	shortURLServiceMock.EXPECT().
+		SearchShortURLs(gomock.Any(), models.ShortURLSearch{}).
+		Return(nil, service.ErrInvalidModeration)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/api/internal/urls?domain=example.com&limit=10", nil)
+	NewSearchShortURLsHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	res := recorder.Result()
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var responseData []models.AdminShortURL
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&responseData))
+	assert.Equal(t, found, responseData)
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/api/internal/urls", nil)
+	NewSearchShortURLsHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, "at least one filter is required")
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/api/internal/urls?domain=example.com&limit=ten", nil)
+	NewSearchShortURLsHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestBanUserHandlers_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	ban := &models.UserBan{BannedAt: time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC), UserID: "SomeUserID", Reason: "Spam"}
+	shortURLServiceMock.EXPECT().BanUser(gomock.Any(), "SomeUserID", "Spam").Return(ban, nil)
+	shortURLServiceMock.EXPECT().UnbanUser(gomock.Any(), "SomeUserID").Return(nil)
+	shortURLServiceMock.EXPECT().UnbanUser(gomock.Any(), "SomeUserID").Return(service.ErrUserNotBanned)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/api/internal/users/SomeUserID/ban", strings.NewReader(`{"reason": "Spam"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.SetPathValue("id", "SomeUserID")
+	NewBanUserHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var responseData models.UserBan
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&responseData))
+	assert.Equal(t, *ban, responseData)
+
+	for _, wantCode := range []int{http.StatusNoContent, http.StatusNotFound} {
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest(http.MethodPost, "/api/internal/users/SomeUserID/unban", nil)
+		request.SetPathValue("id", "SomeUserID")
+		NewUnbanUserHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+		assert.Equal(t, wantCode, recorder.Code)
+	}
+}
+
+func TestRedirectToOriginalURLHandler_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	shortURLServiceMock.EXPECT().Resolve(gomock.Any(), "lelelele").Return(nil, service.ErrShortURLDisabled)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/lelelele", nil)
+	request.SetPathValue("id", "lelelele")
+	NewRedirectToOriginalURLHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusGone, recorder.Code)
+}
+
+func TestGetAuditLogHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	from := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	events := []models.AuditEvent{{Seq: 6, CreatedAt: from, Action: "url.create", Target: "lelelele",
+		UserID: "SomeUserID", IP: "192.0.2.10", Transport: models.TransportHTTP, PrevHash: "fifth", Hash: "sixth"}}
+	shortURLServiceMock.EXPECT().
+		ReadAuditEvents(gomock.Any(), models.AuditFilter{From: from, Target: "lelelele", AfterSeq: 5, Limit: 10}).
+		Return(events, nil)
+	shortURLServiceMock.EXPECT().ReadAuditEvents(gomock.Any(), models.AuditFilter{Limit: -1}).
+		Return(nil, service.ErrInvalidAuditFilter)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet,
+		"/api/internal/audit?target=lelelele&from=2026-10-19T00:00:00Z&after=5&limit=10", nil)
+	NewGetAuditLogHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var responseData []models.AuditEvent
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&responseData))
+	assert.Equal(t, events, responseData)
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/api/internal/audit?limit=-1", nil)
+	NewGetAuditLogHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/api/internal/audit?from=yesterday", nil)
+	NewGetAuditLogHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestVerifyAuditLogHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	verification := &models.AuditVerification{Events: 2, BrokenAt: 2}
+	shortURLServiceMock.EXPECT().VerifyAuditLog(gomock.Any()).Return(verification, nil)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/api/internal/audit/verify", nil)
+	NewVerifyAuditLogHandler(shortURLServiceMock).ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var responseData models.AuditVerification
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&responseData))
+	assert.Equal(t, *verification, responseData)
+}
@@ -0,0 +1,201 @@
+// Package metadata fetches the title, Open Graph tags and favicon URL of the destination pages.
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/clearthree/url-shortener/internal/app/models"
+	"github.com/clearthree/url-shortener/internal/app/utils"
+)
+
+// Limits protecting the service from huge or malicious pages.
+const (
+	maxBodySize       = 1 << 20
+	maxRedirects      = 5
+	maxValueLength    = 1024
+	maxOpenGraphCount = 32
+)
+
+// ErrNotHTML is an error that is returned when the destination page is not an HTML document.
+var ErrNotHTML = errors.New("destination is not an html page")
+
+// ErrUnexpectedStatus is an error that is returned when the destination responds with non-2xx status code.
+var ErrUnexpectedStatus = errors.New("unexpected response status")
+
+// Fetcher is the interface for fetching the metadata of the destination page.
+type Fetcher interface {
+
+	// Fetch requests the page and extracts its metadata.
+	Fetch(ctx context.Context, pageURL string) (*models.PageMetadata, error)
+}
+
+// HTTPFetcher implements the Fetcher interface by requesting the page over HTTP, respecting the URL safety policy.
+type HTTPFetcher struct {
+	client *http.Client
+	policy utils.URLPolicy
+}
+
+// NewHTTPFetcher is the constructor that returns the new HTTPFetcher. Each request, including redirects,
+// must be completed within the timeout.
+func NewHTTPFetcher(policy utils.URLPolicy, timeout time.Duration) *HTTPFetcher {
+	dialer := &net.Dialer{Timeout: timeout, Control: policy.Control}
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       90 * time.Second,
+	}
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return policy.CheckURL(request.URL.String())
+		},
+	}
+	return &HTTPFetcher{client: client, policy: policy}
+}
+
+// Fetch requests the page and extracts its title, Open Graph properties and favicon URL.
+// Only the head of the page within the first megabyte is parsed.
+func (f *HTTPFetcher) Fetch(ctx context.Context, pageURL string) (*models.PageMetadata, error) {
+	if err := f.policy.CheckURL(pageURL); err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "text/html,application/xhtml+xml")
+	request.Header.Set("User-Agent", "url-shortener-metadata-fetcher")
+	response, err := f.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("%w: %d", ErrUnexpectedStatus, response.StatusCode)
+	}
+	if contentType := response.Header.Get("Content-Type"); !strings.Contains(contentType, "html") {
+		return nil, fmt.Errorf("%w: %s", ErrNotHTML, contentType)
+	}
+	return parse(io.LimitReader(response.Body, maxBodySize), response.Request.URL)
+}
+
+// parse extracts the metadata from the head of the HTML document, the relative URLs are resolved against base.
+func parse(body io.Reader, base *url.URL) (*models.PageMetadata, error) {
+	result := &models.PageMetadata{}
+	tokenizer := html.NewTokenizer(body)
+	inTitle := false
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if !errors.Is(tokenizer.Err(), io.EOF) {
+				return nil, tokenizer.Err()
+			}
+			return finish(result, base), nil
+		case html.TextToken:
+			if inTitle && result.Title == "" {
+				result.Title = truncate(strings.TrimSpace(string(tokenizer.Text())))
+			}
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				return finish(result, base), nil
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.Title:
+				inTitle = tokenType == html.StartTagToken
+			case atom.Meta:
+				addOpenGraph(result, token)
+			case atom.Link:
+				addFavicon(result, token, base)
+			case atom.Body:
+				return finish(result, base), nil
+			}
+		}
+	}
+}
+
+// addOpenGraph stores the og:* property of the meta tag, the first occurrence of the property wins.
+func addOpenGraph(result *models.PageMetadata, token html.Token) {
+	property, content := attribute(token, "property"), attribute(token, "content")
+	name, found := strings.CutPrefix(strings.ToLower(property), "og:")
+	if !found || name == "" || content == "" {
+		return
+	}
+	if result.OpenGraph == nil {
+		result.OpenGraph = make(map[string]string)
+	}
+	if _, exists := result.OpenGraph[name]; exists || len(result.OpenGraph) >= maxOpenGraphCount {
+		return
+	}
+	result.OpenGraph[name] = truncate(strings.TrimSpace(content))
+}
+
+// addFavicon stores the absolute URL of the first icon link.
+func addFavicon(result *models.PageMetadata, token html.Token, base *url.URL) {
+	if result.FaviconURL != "" {
+		return
+	}
+	isIcon := false
+	for _, rel := range strings.Fields(strings.ToLower(attribute(token, "rel"))) {
+		if rel == "icon" {
+			isIcon = true
+		}
+	}
+	href := strings.TrimSpace(attribute(token, "href"))
+	if !isIcon || href == "" {
+		return
+	}
+	reference, err := url.Parse(href)
+	if err != nil {
+		return
+	}
+	result.FaviconURL = truncate(base.ResolveReference(reference).String())
+}
+
+// finish fills the defaults for the metadata that were not found in the document.
+func finish(result *models.PageMetadata, base *url.URL) *models.PageMetadata {
+	if result.FaviconURL == "" {
+		result.FaviconURL = base.ResolveReference(&url.URL{Path: "/favicon.ico"}).String()
+	}
+	return result
+}
+
+func attribute(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func truncate(value string) string {
+	if utf8.RuneCountInString(value) <= maxValueLength {
+		return value
+	}
+	return string([]rune(value)[:maxValueLength])
+}
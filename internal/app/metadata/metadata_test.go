@@ -0,0 +1,125 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearthree/url-shortener/internal/app/models"
+	"github.com/clearthree/url-shortener/internal/app/utils"
+)
+
+const testPage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>  Yandex  </title>
+	<meta property="og:title" content="Yandex search">
+	<meta property="og:image" content="https://ya.ru/logo.png">
+	<meta property="og:title" content="Ignored duplicate">
+	<meta name="description" content="Not an Open Graph tag">
+	<link rel="shortcut icon" href="/static/favicon.png">
+</head>
+<body><title>Not a page title</title></body>
+</html>`
+
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = writer.Write([]byte(testPage))
+	})
+	mux.HandleFunc("/bare", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+		_, _ = writer.Write([]byte(`<html><head><title>Bare</title></head></html>`))
+	})
+	mux.HandleFunc("/json", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/slow", func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-request.Context().Done():
+		}
+	})
+	mux.HandleFunc("/redirect", func(writer http.ResponseWriter, request *http.Request) {
+		http.Redirect(writer, request, "/page", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPFetcher_Fetch(t *testing.T) {
+	server := newTestServer(t)
+	fetcher := NewHTTPFetcher(utils.URLPolicy{AllowPrivateNetworks: true}, 100*time.Millisecond)
+	tests := []struct {
+		wantErr error
+		want    *models.PageMetadata
+		name    string
+		path    string
+	}{
+		{
+			name: "Title, Open Graph and favicon",
+			path: "/page",
+			want: &models.PageMetadata{
+				Title:      "Yandex",
+				FaviconURL: server.URL + "/static/favicon.png",
+				OpenGraph:  map[string]string{"title": "Yandex search", "image": "https://ya.ru/logo.png"},
+			},
+		},
+		{
+			name: "Default favicon",
+			path: "/bare",
+			want: &models.PageMetadata{Title: "Bare", FaviconURL: server.URL + "/favicon.ico"},
+		},
+		{
+			name: "Relative URLs are resolved after redirects",
+			path: "/redirect",
+			want: &models.PageMetadata{
+				Title:      "Yandex",
+				FaviconURL: server.URL + "/static/favicon.png",
+				OpenGraph:  map[string]string{"title": "Yandex search", "image": "https://ya.ru/logo.png"},
+			},
+		},
+		{
+			name:    "Not HTML",
+			path:    "/json",
+			wantErr: ErrNotHTML,
+		},
+		{
+			name:    "Not found",
+			path:    "/missing",
+			wantErr: ErrUnexpectedStatus,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fetcher.Fetch(context.Background(), server.URL+tt.path)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHTTPFetcher_FetchTimeout(t *testing.T) {
+	server := newTestServer(t)
+	fetcher := NewHTTPFetcher(utils.URLPolicy{AllowPrivateNetworks: true}, 50*time.Millisecond)
+	_, err := fetcher.Fetch(context.Background(), server.URL+"/slow")
+	require.Error(t, err)
+}
+
+func TestHTTPFetcher_FetchRespectsPolicy(t *testing.T) {
+	server := newTestServer(t)
+	fetcher := NewHTTPFetcher(utils.URLPolicy{}, 100*time.Millisecond)
+	_, err := fetcher.Fetch(context.Background(), server.URL+"/page")
+	assert.ErrorIs(t, err, utils.ErrURLNotAllowed)
+
+	_, err = fetcher.Fetch(context.Background(), "ftp://ya.ru/page")
+	assert.ErrorIs(t, err, utils.ErrURLNotAllowed)
+}
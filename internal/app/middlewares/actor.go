@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/clearthree/url-shortener/internal/app/models"
+	"github.com/clearthree/url-shortener/internal/app/service"
+)
+
+// maxForwardedForLength limits the forwarded addresses recorded in the audit log, since the caller may send any.
+const maxForwardedForLength = 256
+
+// ActorMiddleware is the middleware function to save the caller to the request context as the actor of the mutations
+// recorded in the audit log, see service.WithActor. Must follow AuthMiddleware to know the user.
+// The actor is recorded with the source address of the request resolved by ResolveIP, so behind the proxy it's
+// the address of the client rather than the proxy one. The forwarded addresses are recorded apart as claimed.
+func ActorMiddleware(next http.Handler) http.Handler {
+	fn := func(writer http.ResponseWriter, request *http.Request) {
+		actor := models.Actor{Transport: models.TransportHTTP, ForwardedFor: forwardedFor(request)}
+		actor.UserID, _ = UserIDFromContext(request.Context())
+		ip, err := ResolveIP(request)
+		if err != nil {
+			ip, err = RemoteIP(request)
+		}
+		if err == nil {
+			actor.IP = ip.String()
+		}
+		next.ServeHTTP(writer, request.WithContext(service.WithActor(request.Context(), actor)))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// forwardedFor returns the addresses the request is forwarded for according to the X-Forwarded-For header
+// or the X-Real-IP one, cut to maxForwardedForLength.
+func forwardedFor(r *http.Request) string {
+	forwarded := r.Header.Get("X-Forwarded-For")
+	if forwarded == "" {
+		forwarded = r.Header.Get("X-Real-IP")
+	}
+	if len(forwarded) > maxForwardedForLength {
+		forwarded = forwarded[:maxForwardedForLength]
+	}
+	return forwarded
+}
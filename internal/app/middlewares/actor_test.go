@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/clearthree/url-shortener/internal/app/config"
+	"github.com/clearthree/url-shortener/internal/app/models"
+	"github.com/clearthree/url-shortener/internal/app/service"
+)
+
+func TestActorMiddleware(t *testing.T) {
+	oldSettings := config.Settings
+	defer func() { config.Settings = oldSettings }()
+	config.Settings.UseHeaderForSourceAddress = false
+	var actor models.Actor
+	handler := ActorMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+		actor = service.ActorFromContext(request.Context())
+	}))
+	request := httptest.NewRequest(http.MethodPost, "/api/shorten", nil)
+	request.RemoteAddr = "192.0.2.10:54321"
+	request = request.WithContext(WithUserID(request.Context(), "SomeUserID"))
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Equal(t, models.Actor{UserID: "SomeUserID", IP: "192.0.2.10", Transport: models.TransportHTTP}, actor)
+
+	request = httptest.NewRequest(http.MethodPost, "/api/shorten", nil)
+	request.RemoteAddr = "192.0.2.10:54321"
+	request.Header.Set("X-Forwarded-For", "203.0.113.7, 198.51.100.1")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Equal(t, models.Actor{
+		IP: "192.0.2.10", ForwardedFor: "203.0.113.7, 198.51.100.1", Transport: models.TransportHTTP}, actor)
+
+	request = httptest.NewRequest(http.MethodPost, "/api/shorten", nil)
+	request.RemoteAddr = "192.0.2.10:54321"
+	request.Header.Set("X-Real-IP", strings.Repeat("1", maxForwardedForLength+1))
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Equal(t, "192.0.2.10", actor.IP)
+	assert.Len(t, actor.ForwardedFor, maxForwardedForLength)
+
+	config.Settings.UseHeaderForSourceAddress = true
+	config.Settings.TrustedProxies = []string{"192.0.2.0/24"}
+	request = httptest.NewRequest(http.MethodPost, "/api/shorten", nil)
+	request.RemoteAddr = "192.0.2.10:54321"
+	request.Header.Set("X-Forwarded-For", "203.0.113.7, 198.51.100.1")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Equal(t, models.Actor{
+		IP: "203.0.113.7", ForwardedFor: "203.0.113.7, 198.51.100.1", Transport: models.TransportHTTP}, actor,
+		"behind the proxy the actor is the client")
+
+	request = httptest.NewRequest(http.MethodPost, "/api/shorten", nil)
+	request.RemoteAddr = "198.51.100.1:54321"
+	request.Header.Set("X-Real-IP", "203.0.113.7")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Equal(t, "198.51.100.1", actor.IP, "the headers of the untrusted peer are ignored")
+	assert.Equal(t, "203.0.113.7", actor.ForwardedFor)
+
+	request = httptest.NewRequest(http.MethodPost, "/api/shorten", nil)
+	request.RemoteAddr = "192.0.2.10:54321"
+	request.Header.Set("X-Real-IP", "garbage")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Equal(t, "192.0.2.10", actor.IP, "the peer is recorded if the headers can't be parsed")
+}
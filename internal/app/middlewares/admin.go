@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/clearthree/url-shortener/internal/app/config"
+)
+
+// AdminTokenHeaderName is the header of the admin token, the same one the admin passes to the gRPC server.
+const AdminTokenHeaderName = "x-admin-token"
+
+// RequireAdminToken is a middleware that lets through only the requests with the admin token, so the trusted subnet
+// alone isn't enough to moderate the links and ban the users. Responds with 403 if no admin token is configured.
+func RequireAdminToken(next http.Handler) http.Handler {
+	fn := func(writer http.ResponseWriter, request *http.Request) {
+		if config.Settings.GRPCToken == "" {
+			http.Error(writer, "no admin token specified", http.StatusForbidden)
+			return
+		}
+		token := request.Header.Get(AdminTokenHeaderName)
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(config.Settings.GRPCToken)) != 1 {
+			http.Error(writer, "Admin token is required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(writer, request)
+	}
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/clearthree/url-shortener/internal/app/config"
+)
+
+func TestRequireAdminToken(t *testing.T) {
+	oldSettings := config.Settings
+	defer func() { config.Settings = oldSettings }()
+	handler := RequireAdminToken(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	tests := []struct {
+		name       string
+		adminToken string
+		token      string
+		wantCode   int
+	}{
+		{name: "Admin token", adminToken: "admin-secret", token: "admin-secret", wantCode: http.StatusNoContent},
+		{name: "Wrong token", adminToken: "admin-secret", token: "guess", wantCode: http.StatusUnauthorized},
+		{name: "No token", adminToken: "admin-secret", wantCode: http.StatusUnauthorized},
+		{name: "No admin token configured", wantCode: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config.Settings.GRPCToken = test.adminToken
+			request := httptest.NewRequest(http.MethodPost, "/api/internal/users/SomeUserID/ban", nil)
+			request.Header.Set("X-Real-IP", "10.0.0.1")
+			if test.token != "" {
+				request.Header.Set(AdminTokenHeaderName, test.token)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			assert.Equal(t, test.wantCode, recorder.Code)
+		})
+	}
+}
@@ -2,8 +2,11 @@
 package middlewares
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -11,43 +14,103 @@ import (
 
 	"github.com/clearthree/url-shortener/internal/app/config"
 	"github.com/clearthree/url-shortener/internal/app/logger"
+	"github.com/clearthree/url-shortener/internal/app/service"
 )
 
 // Constants used for the authorization purposes.
 const (
 	AuthCookieName   = "auth"      // The name of the cookie to store an auth-token.
-	UserIDHeaderName = "x-user-id" // The name of the header to store the decoded userID from the token.
+	UserIDHeaderName = "x-user-id" // The legacy header of the userID, stripped from the incoming requests.
 )
 
+type userIDKey struct{}
+
+type tokenIDKey struct{}
+
+// WithUserID returns the copy of the context carrying the ID of the authenticated user.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the ID of the user authenticated by AuthMiddleware.
+// Returns false if the request hasn't passed through the middleware.
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(string)
+	return userID, ok
+}
+
+// WithTokenID returns the copy of the context carrying the ID of the token the user is authenticated with.
+func WithTokenID(ctx context.Context, tokenID string) context.Context {
+	return context.WithValue(ctx, tokenIDKey{}, tokenID)
+}
+
+// TokenIDFromContext returns the ID (the jti claim) of the auth cookie token the request is authenticated with,
+// the expired token being reissued included. Returns false if the request is authenticated otherwise,
+// e.g. with the API key or with the cookie of the new anonymous user.
+func TokenIDFromContext(ctx context.Context) (string, bool) {
+	tokenID, ok := ctx.Value(tokenIDKey{}).(string)
+	return tokenID, ok
+}
+
+// RevocationChecker reports whether the token is logged out before it expires.
+type RevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, tokenID string, userID string, issuedAt time.Time) (bool, error)
+}
+
+var (
+	revocationChecker RevocationChecker
+	revocationLock    sync.RWMutex
+)
+
+// SetRevocationChecker sets the list of the revoked tokens GetUserID checks the tokens against.
+func SetRevocationChecker(checker RevocationChecker) {
+	revocationLock.Lock()
+	defer revocationLock.Unlock()
+	revocationChecker = checker
+}
+
+// APIKeyResolver resolves the personal API key passed as a bearer token to the ID of its owner.
+type APIKeyResolver interface {
+	ResolveAPIKey(ctx context.Context, key string) (string, error)
+}
+
 // Errors that might occur in the Auth middleware.
 var (
 	ErrWrongAlgorithm  = errors.New("unexpected signing method")
 	ErrTokenIsNotValid = errors.New("invalid token passed")
+	ErrTokenRevoked    = errors.New("token is revoked")
+	ErrTokenTooOld     = errors.New("token is expired too long ago to be reissued")
 )
 
 type claims struct {
 	jwt.RegisteredClaims
-	UserID string `json:"user_id"`
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"` // the first token of the session, kept by the reissued ones
+	UserID   string           `json:"user_id"`
 }
 
-// GenerateJWTString generates the JWT token for the given userID.
+// GenerateJWTString generates the JWT token for the given userID, signed with the first key of the keyring.
 // Might generate the userID itself, if not passed from above.
 func GenerateJWTString(userID string) (string, string, error) {
+	return generateJWTString(userID, time.Now())
+}
+
+// generateJWTString generates the JWT token of the session started at authTime.
+func generateJWTString(userID string, authTime time.Time) (string, string, error) {
 	if userID == "" {
 		userID = uuid.New().String()
 	}
 	issueTime := time.Now()
 	expireTime := issueTime.Add(time.Hour * time.Duration(config.Settings.JWTExpireHours))
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+	tokenString, err := CurrentKeyring().Sign(claims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			Issuer:    "clearthree",
 			IssuedAt:  jwt.NewNumericDate(issueTime),
 			ExpiresAt: jwt.NewNumericDate(expireTime),
 		},
-		UserID: userID,
+		AuthTime: jwt.NewNumericDate(authTime),
+		UserID:   userID,
 	})
-
-	tokenString, err := token.SignedString([]byte(config.Settings.SecretKey))
 	if err != nil {
 		return "", "", err
 	}
@@ -55,36 +118,180 @@ func GenerateJWTString(userID string) (string, string, error) {
 }
 
 // GetUserID returns the userID, extracted from the token passed as an input.
-// If not valid, returns the corresponding error.
-func GetUserID(tokenString string) (string, error) {
+// The token is verified with the key of the keyring found by the kid header and is checked against
+// the revoked ones. If not valid, returns the corresponding error.
+func GetUserID(ctx context.Context, tokenString string) (string, error) {
+	claimsObj, err := parseToken(ctx, tokenString)
+	if claimsObj == nil {
+		return "", err
+	}
+	return claimsObj.UserID, err
+}
+
+// parseToken returns the claims of the valid token. The claims of the expired token are returned along with
+// jwt.ErrTokenExpired, so the token is reissued for the same user unless it's revoked, but only within the grace
+// period after the expiration and while the session is younger than the auth cookie max age: otherwise,
+// the stolen cookie would never expire. The older tokens are rejected with ErrTokenTooOld.
+func parseToken(ctx context.Context, tokenString string) (*claims, error) {
 	claimsObj := &claims{}
+	keyring := CurrentKeyring()
 	token, err := jwt.ParseWithClaims(tokenString, claimsObj,
 		func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				logger.Log.Warnf("unexpected signing method: %v", t.Header["alg"])
-				return nil, ErrWrongAlgorithm
+			key, keyErr := keyring.KeyFunc(t)
+			if keyErr != nil {
+				logger.Log.Warnf("unexpected signing key %v or method %v", t.Header["kid"], t.Header["alg"])
 			}
-			return []byte(config.Settings.SecretKey), nil
+			return key, keyErr
 		})
-	if err != nil {
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			return claimsObj.UserID, err
+	if err != nil && !errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, err
+	}
+	if err == nil && !token.Valid {
+		logger.Log.Info("Token is not valid")
+		return nil, ErrTokenIsNotValid
+	}
+	if err != nil && !reissuable(claimsObj, time.Now()) {
+		return nil, ErrTokenTooOld
+	}
+
+	revocationLock.RLock()
+	checker := revocationChecker
+	revocationLock.RUnlock()
+	if checker != nil {
+		var issuedAt time.Time
+		if claimsObj.IssuedAt != nil {
+			issuedAt = claimsObj.IssuedAt.Time
+		}
+		revoked, checkErr := checker.IsTokenRevoked(ctx, claimsObj.ID, claimsObj.UserID, issuedAt)
+		if checkErr != nil {
+			return nil, checkErr
 		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+	return claimsObj, err
+}
+
+// reissuable reports whether the expired token can be reissued for the same user.
+func reissuable(tokenClaims *claims, now time.Time) bool {
+	if tokenClaims.ExpiresAt == nil {
+		return false
+	}
+	grace := time.Hour * time.Duration(config.Settings.JWTReissueGraceHours)
+	if now.Sub(tokenClaims.ExpiresAt.Time) > grace {
+		return false
+	}
+	authTime := tokenClaims.AuthTime
+	if authTime == nil {
+		authTime = tokenClaims.IssuedAt
+	}
+	maxAge := time.Second * time.Duration(config.Settings.AuthCookieMaxAgeSeconds)
+	return authTime != nil && now.Sub(authTime.Time) <= maxAge
+}
+
+// IssueAuthCookie sets the cookie with the new token of the user, generating the userID if not passed.
+// Returns the userID the token is issued for.
+func IssueAuthCookie(writer http.ResponseWriter, userID string) (string, error) {
+	JWTString, userID, err := GenerateJWTString(userID)
+	if err != nil {
 		return "", err
 	}
+	setAuthCookie(writer, JWTString)
+	return userID, nil
+}
 
-	if !token.Valid {
-		logger.Log.Info("Token is not valid")
-		return "", ErrTokenIsNotValid
+// reissueAuthCookie sets the cookie with the new token of the expired token session.
+func reissueAuthCookie(writer http.ResponseWriter, tokenClaims *claims) error {
+	authTime := tokenClaims.AuthTime
+	if authTime == nil {
+		authTime = tokenClaims.IssuedAt
+	}
+	JWTString, _, err := generateJWTString(tokenClaims.UserID, authTime.Time)
+	if err != nil {
+		return err
+	}
+	setAuthCookie(writer, JWTString)
+	return nil
+}
+
+func setAuthCookie(writer http.ResponseWriter, JWTString string) {
+	cookie := newAuthCookie(JWTString)
+	cookie.MaxAge = config.Settings.AuthCookieMaxAgeSeconds
+	http.SetCookie(writer, cookie)
+}
+
+// ClearAuthCookie expires the cookie with the token, so the next request gets the new anonymous user.
+func ClearAuthCookie(writer http.ResponseWriter) {
+	cookie := newAuthCookie("")
+	cookie.MaxAge = -1
+	http.SetCookie(writer, cookie)
+}
+
+// newAuthCookie returns the auth cookie with the configured attributes. The cookie is never readable by the scripts,
+// and is sent over HTTPS only if the server is run with TLS, the secure cookies are enabled or SameSite is None.
+// The cookie may outlive the token: the expired token is reissued for the same user within the grace period.
+func newAuthCookie(value string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     AuthCookieName,
+		Value:    value,
+		Path:     "/",
+		Domain:   config.Settings.AuthCookieDomain,
+		HttpOnly: true,
+		Secure:   config.Settings.AuthCookieSecure || config.Settings.TLSEnabled,
+		SameSite: http.SameSiteLaxMode,
+	}
+	switch strings.ToLower(config.Settings.AuthCookieSameSite) {
+	case "strict":
+		cookie.SameSite = http.SameSiteStrictMode
+	case "none":
+		cookie.SameSite = http.SameSiteNoneMode
+		cookie.Secure = true
 	}
+	return cookie
+}
 
-	return claimsObj.UserID, nil
+// BearerToken returns the token passed in the Authorization header with the Bearer scheme, if any.
+func BearerToken(request *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(request.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// AuthMiddleware returns the middleware function itself, that authorizes the request by the API key passed
+// as a bearer token or by the token from the request cookies and saves the userID to the request context,
+// see UserIDFromContext. If neither is passed, generates the anonymous user in advance.
+// The userID header passed by the client is dropped, so it can't be mistaken for the authenticated one.
+func AuthMiddleware(keys APIKeyResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return authMiddleware(keys, next)
+	}
 }
 
-// AuthMiddleware is the middleware function itself, that tries to extract the token from the request cookies,
-// authorizes it and saves the userID to request headers. If not passed, generates one in advance.
-func AuthMiddleware(next http.Handler) http.Handler {
+func authMiddleware(keys APIKeyResolver, next http.Handler) http.Handler {
 	fn := func(writer http.ResponseWriter, request *http.Request) {
+		request.Header.Del(UserIDHeaderName)
+		if key, ok := BearerToken(request); ok {
+			userID, err := keys.ResolveAPIKey(request.Context(), key)
+			if err != nil {
+				if errors.Is(err, service.ErrAPIKeyRejected) {
+					http.Error(writer, err.Error(), http.StatusUnauthorized)
+					return
+				}
+				logger.Log.Error(err)
+				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			next.ServeHTTP(writer, request.WithContext(WithUserID(request.Context(), userID)))
+			return
+		}
+
+		var userID string
+		ctx := request.Context()
+
 		token, err := request.Cookie(AuthCookieName)
 		if err != nil {
 			if !errors.Is(err, http.ErrNoCookie) {
@@ -93,36 +300,33 @@ func AuthMiddleware(next http.Handler) http.Handler {
 				return
 			}
 
-			JWTString, userID, genErr := GenerateJWTString("")
+			newUserID, genErr := IssueAuthCookie(writer, "")
 			if genErr != nil {
 				http.Error(writer, genErr.Error(), http.StatusInternalServerError)
 				return
 			}
-			request.Header.Set("x-user-id", userID)
-			http.SetCookie(writer, &http.Cookie{
-				Name:  AuthCookieName,
-				Value: JWTString,
-				Path:  "/",
-			})
+			userID = newUserID
 		} else {
-			userID, tokenErr := GetUserID(token.Value)
+			tokenClaims, tokenErr := parseToken(ctx, token.Value)
+			if tokenClaims != nil {
+				userID = tokenClaims.UserID
+				ctx = WithTokenID(ctx, tokenClaims.ID)
+			}
 			switch {
-			case errors.Is(tokenErr, ErrTokenIsNotValid), errors.Is(tokenErr, ErrWrongAlgorithm):
+			case errors.Is(tokenErr, ErrTokenIsNotValid), errors.Is(tokenErr, ErrWrongAlgorithm),
+				errors.Is(tokenErr, ErrUnknownKeyID), errors.Is(tokenErr, ErrTokenRevoked),
+				errors.Is(tokenErr, ErrTokenTooOld):
 				userID = ""
 				logger.Log.Warnf("Token is invalid: %v", tokenErr)
-				fallthrough
+				if _, genErr := IssueAuthCookie(writer, ""); genErr != nil {
+					http.Error(writer, genErr.Error(), http.StatusInternalServerError)
+					return
+				}
 			case errors.Is(tokenErr, jwt.ErrTokenExpired):
-				JWTString, newUserID, genErr := GenerateJWTString(userID)
-				if genErr != nil {
+				if genErr := reissueAuthCookie(writer, tokenClaims); genErr != nil {
 					http.Error(writer, genErr.Error(), http.StatusInternalServerError)
 					return
 				}
-				request.Header.Set("x-user-id", newUserID)
-				http.SetCookie(writer, &http.Cookie{
-					Name:  AuthCookieName,
-					Value: JWTString,
-					Path:  "/",
-				})
 			case tokenErr != nil:
 				logger.Log.Error(tokenErr)
 				http.Error(writer, tokenErr.Error(), http.StatusInternalServerError)
@@ -132,10 +336,9 @@ func AuthMiddleware(next http.Handler) http.Handler {
 				http.Error(writer, "Unauthorized", http.StatusUnauthorized)
 				return
 			}
-			request.Header.Set("x-user-id", userID)
 		}
 
-		next.ServeHTTP(writer, request)
+		next.ServeHTTP(writer, request.WithContext(WithUserID(ctx, userID)))
 	}
 	return http.HandlerFunc(fn)
 }
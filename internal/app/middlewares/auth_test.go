@@ -0,0 +1,135 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearthree/url-shortener/internal/app/config"
+)
+
+type revokedTokens map[string]bool
+
+func (r revokedTokens) IsTokenRevoked(_ context.Context, tokenID string, _ string, _ time.Time) (bool, error) {
+	return r[tokenID], nil
+}
+
+func expiredToken(t *testing.T, userID string) (string, string) {
+	tokenClaims := claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "expired-token",
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-2 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+		},
+		UserID: userID,
+	}
+	tokenString, err := CurrentKeyring().Sign(tokenClaims)
+	require.NoError(t, err)
+	return tokenString, tokenClaims.ID
+}
+
+func TestAuthMiddleware_RevokedToken(t *testing.T) {
+	defer SetRevocationChecker(nil)
+	tokenString, id := expiredToken(t, "Alice")
+	var gotTokenID string
+	handler := AuthMiddleware(nil)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		gotTokenID, _ = TokenIDFromContext(request.Context())
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+	request.AddCookie(&http.Cookie{Name: AuthCookieName, Value: tokenString})
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code, "the expired token is reissued")
+	assert.Equal(t, id, gotTokenID, "the reissued token can be logged out")
+
+	SetRevocationChecker(revokedTokens{id: true})
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, "the revoked token isn't reissued for the same user")
+	_, err := GetUserID(context.Background(), tokenString)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestIssueAuthCookie_Attributes(t *testing.T) {
+	oldSettings := config.Settings
+	defer func() { config.Settings = oldSettings }()
+	config.Settings.AuthCookieSameSite = "None"
+	config.Settings.AuthCookieDomain = "short.example"
+	config.Settings.AuthCookieMaxAgeSeconds = 3600
+
+	recorder := httptest.NewRecorder()
+	_, err := IssueAuthCookie(recorder, "Alice")
+	require.NoError(t, err)
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, cookies[0].Secure, "SameSite=None requires the secure cookie")
+	assert.Equal(t, http.SameSiteNoneMode, cookies[0].SameSite)
+	assert.Equal(t, "short.example", cookies[0].Domain)
+	assert.Equal(t, 3600, cookies[0].MaxAge)
+}
+
+func TestAuthMiddleware_ExpiredTokenReissue(t *testing.T) {
+	oldSettings := config.Settings
+	defer func() { config.Settings = oldSettings }()
+	config.Settings.JWTReissueGraceHours = 24
+	config.Settings.AuthCookieMaxAgeSeconds = 7 * 24 * 3600
+	sign := func(authTime time.Time, expiresAt time.Time) string {
+		tokenString, err := CurrentKeyring().Sign(claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        "expired-token",
+				IssuedAt:  jwt.NewNumericDate(expiresAt.Add(-time.Hour)),
+				ExpiresAt: jwt.NewNumericDate(expiresAt),
+			},
+			AuthTime: jwt.NewNumericDate(authTime),
+			UserID:   "Alice",
+		})
+		require.NoError(t, err)
+		return tokenString
+	}
+	now := time.Now()
+	tests := []struct {
+		name     string
+		token    string
+		wantCode int
+		wantUser string
+	}{
+		{name: "Within the grace period", token: sign(now.Add(-2*time.Hour), now.Add(-time.Hour)),
+			wantCode: http.StatusOK, wantUser: "Alice"},
+		{name: "Beyond the grace period", token: sign(now.Add(-49*time.Hour), now.Add(-48*time.Hour)),
+			wantCode: http.StatusUnauthorized},
+		{name: "Session older than the cookie", token: sign(now.Add(-8*24*time.Hour), now.Add(-time.Hour)),
+			wantCode: http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var gotUser string
+			handler := AuthMiddleware(nil)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				gotUser, _ = UserIDFromContext(request.Context())
+			}))
+			request := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+			request.AddCookie(&http.Cookie{Name: AuthCookieName, Value: test.token})
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			assert.Equal(t, test.wantCode, recorder.Code)
+			assert.Equal(t, test.wantUser, gotUser)
+			cookies := recorder.Result().Cookies()
+			require.Len(t, cookies, 1, "the new cookie is issued either way")
+			if test.wantUser == "" {
+				return
+			}
+			reissued := &claims{}
+			_, _, err := jwt.NewParser().ParseUnverified(cookies[0].Value, reissued)
+			require.NoError(t, err)
+			assert.Equal(t, "Alice", reissued.UserID)
+			assert.Equal(t, now.Add(-2*time.Hour).Unix(), reissued.AuthTime.Unix(), "the session start is kept")
+		})
+	}
+}
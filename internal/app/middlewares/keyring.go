@@ -0,0 +1,217 @@
+package middlewares
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/clearthree/url-shortener/internal/app/config"
+)
+
+// LegacyKeyID is the ID of the key made of config.Settings.SecretKey. The tokens issued before the keyring
+// have no kid header and are verified with this key.
+const LegacyKeyID = "default"
+
+// ErrUnknownKeyID is returned if the token is signed with the key missing from the keyring.
+var ErrUnknownKeyID = errors.New("unknown key id")
+
+// Keyring holds the keys the JWTs are signed and verified with. The first key signs the new tokens,
+// the others verify the tokens carrying their ID in the kid header. The signing key is rotated by putting
+// the new key first and keeping the old one until its tokens expire, so the users keep their identity.
+type Keyring struct {
+	keys []keyringKey
+}
+
+type keyringKey struct {
+	signKey   any
+	verifyKey any
+	method    jwt.SigningMethod
+	id        string
+}
+
+// JWK is the public key in the JSON Web Key format.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	ID        string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKSet is the JSON Web Key Set of the public keys the tokens are verified with.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	loadedKeyring *Keyring
+	keyringLock   sync.RWMutex
+)
+
+// NewKeyring loads the configured keys, the asymmetric ones are read from the PEM files. The key made of
+// the secretKey is added last to verify the tokens without the kid header, and signs the tokens if no keys
+// are configured. DefaultSecretKey is never trusted along with the configured keys, as anyone knows it.
+func NewKeyring(keys []config.JWTKey, secretKey string) (*Keyring, error) {
+	keyring := &Keyring{}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.ID == "" || key.ID == LegacyKeyID || seen[key.ID] {
+			return nil, fmt.Errorf("JWT key id %q is empty, reserved or repeated", key.ID)
+		}
+		seen[key.ID] = true
+		loaded, err := loadKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", key.ID, err)
+		}
+		keyring.keys = append(keyring.keys, loaded)
+	}
+	if secretKey != "" && (len(keys) == 0 || secretKey != config.DefaultSecretKey) {
+		keyring.keys = append(keyring.keys, keyringKey{
+			id:        LegacyKeyID,
+			method:    jwt.SigningMethodHS256,
+			signKey:   []byte(secretKey),
+			verifyKey: []byte(secretKey),
+		})
+	}
+	if len(keyring.keys) == 0 {
+		return nil, errors.New("no JWT keys configured")
+	}
+	if keyring.keys[0].signKey == nil {
+		return nil, fmt.Errorf("JWT key %q can't sign the tokens, the private key is required", keyring.keys[0].id)
+	}
+	return keyring, nil
+}
+
+func loadKey(key config.JWTKey) (keyringKey, error) {
+	loaded := keyringKey{id: key.ID}
+	switch key.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if key.Secret == "" {
+			return loaded, errors.New("the secret is required")
+		}
+		loaded.method = jwt.SigningMethodHS256
+		loaded.signKey = []byte(key.Secret)
+		loaded.verifyKey = []byte(key.Secret)
+		return loaded, nil
+	case jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg():
+	default:
+		return loaded, fmt.Errorf("unsupported algorithm %q", key.Algorithm)
+	}
+	data, err := os.ReadFile(key.Path)
+	if err != nil {
+		return loaded, err
+	}
+	if key.Algorithm == jwt.SigningMethodRS256.Alg() {
+		loaded.method = jwt.SigningMethodRS256
+		if private, parseErr := jwt.ParseRSAPrivateKeyFromPEM(data); parseErr == nil {
+			loaded.signKey, loaded.verifyKey = private, &private.PublicKey
+			return loaded, nil
+		}
+		public, parseErr := jwt.ParseRSAPublicKeyFromPEM(data)
+		if parseErr != nil {
+			return loaded, parseErr
+		}
+		loaded.verifyKey = public
+		return loaded, nil
+	}
+	loaded.method = jwt.SigningMethodEdDSA
+	if private, parseErr := jwt.ParseEdPrivateKeyFromPEM(data); parseErr == nil {
+		edPrivate := private.(ed25519.PrivateKey)
+		loaded.signKey, loaded.verifyKey = edPrivate, edPrivate.Public()
+		return loaded, nil
+	}
+	public, parseErr := jwt.ParseEdPublicKeyFromPEM(data)
+	if parseErr != nil {
+		return loaded, parseErr
+	}
+	loaded.verifyKey = public
+	return loaded, nil
+}
+
+// LoadKeyring replaces the keyring the tokens are issued and checked with by the one of the current settings.
+func LoadKeyring() error {
+	keyring, err := NewKeyring(config.Settings.JWTKeys, config.Settings.SecretKey)
+	if err != nil {
+		return err
+	}
+	keyringLock.Lock()
+	defer keyringLock.Unlock()
+	loadedKeyring = keyring
+	return nil
+}
+
+// CurrentKeyring returns the keyring loaded by LoadKeyring, or the one made of config.Settings.SecretKey
+// if nothing is loaded yet.
+func CurrentKeyring() *Keyring {
+	keyringLock.RLock()
+	keyring := loadedKeyring
+	keyringLock.RUnlock()
+	if keyring != nil {
+		return keyring
+	}
+	return &Keyring{keys: []keyringKey{{
+		id:        LegacyKeyID,
+		method:    jwt.SigningMethodHS256,
+		signKey:   []byte(config.Settings.SecretKey),
+		verifyKey: []byte(config.Settings.SecretKey),
+	}}}
+}
+
+// Sign returns the token with the given claims signed with the first key of the keyring.
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	key := k.keys[0]
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.signKey)
+}
+
+// KeyFunc returns the key the token is verified with, found by the kid header.
+// The algorithm of the token must match the one of the key.
+func (k *Keyring) KeyFunc(token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+	if keyID == "" {
+		keyID = LegacyKeyID
+	}
+	for _, key := range k.keys {
+		if key.id != keyID {
+			continue
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, ErrWrongAlgorithm
+		}
+		return key.verifyKey, nil
+	}
+	return nil, ErrUnknownKeyID
+}
+
+// JWKS returns the public keys of the keyring, the HS256 secrets are never published.
+func (k *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range k.keys {
+		jwk := JWK{ID: key.id, Algorithm: key.method.Alg(), Use: "sig"}
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,123 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearthree/url-shortener/internal/app/config"
+)
+
+func writePEM(t *testing.T, blockType string, data []byte) string {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0600))
+	return path
+}
+
+func testClaims(userID string) claims {
+	return claims{UserID: userID}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPath := writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	edPath := writePEM(t, "PRIVATE KEY", edDER)
+
+	oldKeyring, err := NewKeyring(nil, "old-secret")
+	require.NoError(t, err)
+	legacyToken, err := oldKeyring.Sign(testClaims("Alice"))
+	require.NoError(t, err)
+
+	rotated, err := NewKeyring([]config.JWTKey{
+		{ID: "ed", Algorithm: "EdDSA", Path: edPath},
+		{ID: "rsa", Algorithm: "RS256", Path: rsaPath},
+	}, "old-secret")
+	require.NoError(t, err)
+	newToken, err := rotated.Sign(testClaims("Alice"))
+	require.NoError(t, err)
+
+	for _, tokenString := range []string{legacyToken, newToken} {
+		parsed := &claims{}
+		_, err = jwt.ParseWithClaims(tokenString, parsed, rotated.KeyFunc)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", parsed.UserID, "the user keeps the identity after the rotation")
+	}
+	token, _, err := new(jwt.Parser).ParseUnverified(newToken, &claims{})
+	require.NoError(t, err)
+	assert.Equal(t, "ed", token.Header["kid"], "the first key signs the new tokens")
+
+	_, err = jwt.ParseWithClaims(newToken, &claims{}, oldKeyring.KeyFunc)
+	assert.ErrorIs(t, err, ErrUnknownKeyID)
+
+	jwks := rotated.JWKS()
+	require.Len(t, jwks.Keys, 2, "the HS256 secret is never published")
+	assert.Equal(t, JWK{KeyType: "OKP", ID: "ed", Algorithm: "EdDSA", Use: "sig", Curve: "Ed25519",
+		X: jwks.Keys[0].X}, jwks.Keys[0])
+	assert.Equal(t, "RSA", jwks.Keys[1].KeyType)
+	assert.Equal(t, "AQAB", jwks.Keys[1].E)
+}
+
+func TestKeyring_WrongAlgorithm(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	publicPath := writePEM(t, "PUBLIC KEY", publicDER)
+
+	keyring, err := NewKeyring([]config.JWTKey{{ID: "hs", Algorithm: "HS256", Secret: "secret"},
+		{ID: "rsa", Algorithm: "RS256", Path: publicPath}}, config.DefaultSecretKey)
+	require.NoError(t, err)
+	assert.Len(t, keyring.keys, 2, "the default secret isn't trusted along with the configured keys")
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims("Mallory"))
+	forged.Header["kid"] = "rsa"
+	forgedString, err := forged.SignedString(publicDER)
+	require.NoError(t, err)
+	_, err = jwt.ParseWithClaims(forgedString, &claims{}, keyring.KeyFunc)
+	assert.ErrorIs(t, err, ErrWrongAlgorithm, "the public key can't be used as the HMAC secret")
+
+	_, err = NewKeyring([]config.JWTKey{{ID: "rsa", Algorithm: "RS256", Path: publicPath}}, "")
+	assert.Error(t, err, "the public key can't sign the tokens")
+	_, err = NewKeyring([]config.JWTKey{{ID: LegacyKeyID, Algorithm: "HS256", Secret: "secret"}}, "")
+	assert.Error(t, err, "the id of the legacy key is reserved")
+	_, err = NewKeyring([]config.JWTKey{{ID: "es", Algorithm: "ES256", Path: publicPath}}, "")
+	assert.Error(t, err)
+}
+
+func TestGenerateJWTString_LoadedKeyring(t *testing.T) {
+	oldKeys, oldSecret, oldExpire := config.Settings.JWTKeys, config.Settings.SecretKey, config.Settings.JWTExpireHours
+	defer func() {
+		config.Settings.JWTKeys, config.Settings.SecretKey, config.Settings.JWTExpireHours = oldKeys, oldSecret, oldExpire
+		loadedKeyring = nil
+	}()
+	config.Settings.JWTExpireHours = 1
+	config.Settings.SecretKey = "old-secret"
+	legacyToken, _, err := GenerateJWTString("Alice")
+	require.NoError(t, err)
+
+	config.Settings.JWTKeys = []config.JWTKey{{ID: "2026-10", Algorithm: "HS256", Secret: "new-secret"}}
+	require.NoError(t, LoadKeyring())
+	userID, err := GetUserID(context.Background(), legacyToken)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", userID)
+	newToken, _, err := GenerateJWTString("Alice")
+	require.NoError(t, err)
+	userID, err = GetUserID(context.Background(), newToken)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", userID)
+}
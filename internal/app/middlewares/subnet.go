@@ -12,32 +12,50 @@ import (
 // IPNet is the storage for CIDR specified in config
 var IPNet *net.IPNet
 
-func resolveIP(r *http.Request) (net.IP, error) {
-	if !config.Settings.UseHeaderForSourceAddress {
-		addr := r.RemoteAddr
-		ipStr, _, err := net.SplitHostPort(addr)
-		if err != nil {
-			return nil, err
-		}
-		ip := net.ParseIP(ipStr)
-		if ip == nil {
-			return nil, fmt.Errorf("invalid IP address: %s", ip)
-		}
-		return ip, nil
-	} else {
-		ipStr := r.Header.Get("X-Real-IP")
-		ip := net.ParseIP(ipStr)
-		if ip == nil {
-			ips := r.Header.Get("X-Forwarded-For")
-			ipStrs := strings.Split(ips, ",")
-			ipStr = ipStrs[0]
-			ip = net.ParseIP(ipStr)
-		}
-		if ip == nil {
-			return nil, fmt.Errorf("failed parse ip from http header")
+// ResolveIP returns the source address of the request. The address is taken from the headers set by the proxy
+// if configured. Once the trusted proxies are listed, the headers are taken only from the requests coming from them:
+// anyone else can forge the headers.
+func ResolveIP(r *http.Request) (net.IP, error) {
+	ip, err := RemoteIP(r)
+	if err != nil || !config.Settings.UseHeaderForSourceAddress ||
+		(len(config.Settings.TrustedProxies) > 0 && !trustedProxy(ip)) {
+		return ip, err
+	}
+	ipStr := r.Header.Get("X-Real-IP")
+	headerIP := net.ParseIP(ipStr)
+	if headerIP == nil {
+		ips := r.Header.Get("X-Forwarded-For")
+		ipStrs := strings.Split(ips, ",")
+		ipStr = strings.TrimSpace(ipStrs[0])
+		headerIP = net.ParseIP(ipStr)
+	}
+	if headerIP == nil {
+		return nil, fmt.Errorf("failed parse ip from http header")
+	}
+	return headerIP, nil
+}
+
+// RemoteIP returns the address of the peer the request comes from, which can't be forged by the headers.
+func RemoteIP(r *http.Request) (net.IP, error) {
+	ipStr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address: %s", ipStr)
+	}
+	return ip, nil
+}
+
+// trustedProxy checks if the address belongs to one of the trusted proxies.
+func trustedProxy(ip net.IP) bool {
+	for _, proxy := range config.Settings.TrustedProxies {
+		if _, subnet, err := net.ParseCIDR(proxy); err == nil && subnet.Contains(ip) {
+			return true
 		}
-		return ip, nil
 	}
+	return false
 }
 
 // CheckSubnet is a middleware that checks if the request's source addr matches the trusted subnet.
@@ -50,7 +68,7 @@ func CheckSubnet(next http.Handler) http.Handler {
 			_, IPNet, _ = net.ParseCIDR(config.Settings.TrustedSubnet)
 		}
 
-		address, err := resolveIP(request)
+		address, err := ResolveIP(request)
 		if err != nil || address == nil {
 			http.Error(writer, "Unexpected error during IP parsing", http.StatusForbidden)
 			return
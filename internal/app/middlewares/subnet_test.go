@@ -0,0 +1,98 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearthree/url-shortener/internal/app/config"
+)
+
+func TestResolveIP(t *testing.T) {
+	oldSettings := config.Settings
+	defer func() { config.Settings = oldSettings }()
+	config.Settings.UseHeaderForSourceAddress = true
+	config.Settings.TrustedProxies = []string{"10.0.0.0/8"}
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		realIP       string
+		forwardedFor string
+		want         string
+	}{
+		{name: "Trusted proxy", remoteAddr: "10.0.0.1:1234", realIP: "203.0.113.7", want: "203.0.113.7"},
+		{name: "Trusted proxy chain", remoteAddr: "10.0.0.1:1234", forwardedFor: "203.0.113.8, 10.0.0.2",
+			want: "203.0.113.8"},
+		{name: "Forged by the client", remoteAddr: "198.51.100.1:1234", realIP: "10.0.0.5", want: "198.51.100.1"},
+		{name: "No headers", remoteAddr: "198.51.100.1:1234", want: "198.51.100.1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.RemoteAddr = test.remoteAddr
+			if test.realIP != "" {
+				request.Header.Set("X-Real-IP", test.realIP)
+			}
+			if test.forwardedFor != "" {
+				request.Header.Set("X-Forwarded-For", test.forwardedFor)
+			}
+			ip, err := ResolveIP(request)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, ip.String())
+		})
+	}
+
+	config.Settings.TrustedProxies = nil
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.RemoteAddr = "198.51.100.1:1234"
+	request.Header.Set("X-Real-IP", "203.0.113.7")
+	ip, err := ResolveIP(request)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", ip.String(), "the headers are taken from anyone unless the proxies are listed")
+
+	config.Settings.UseHeaderForSourceAddress = false
+	request = httptest.NewRequest(http.MethodGet, "/", nil)
+	request.RemoteAddr = "10.0.0.1:1234"
+	request.Header.Set("X-Real-IP", "203.0.113.7")
+	ip, err = ResolveIP(request)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", ip.String(), "the headers are ignored unless configured")
+}
+
+func TestCheckSubnet(t *testing.T) {
+	oldSettings, oldIPNet := config.Settings, IPNet
+	defer func() { config.Settings, IPNet = oldSettings, oldIPNet }()
+	config.Settings.UseHeaderForSourceAddress = true
+	config.Settings.TrustedProxies = nil
+	config.Settings.TrustedSubnet = "192.0.2.0/24"
+	IPNet = nil
+	handler := CheckSubnet(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	tests := []struct {
+		name       string
+		remoteAddr string
+		realIP     string
+		want       int
+	}{
+		{name: "No header set by the proxy", remoteAddr: "192.0.2.10:1234", want: http.StatusForbidden},
+		{name: "Proxied from the subnet", remoteAddr: "10.0.0.1:1234", realIP: "192.0.2.10", want: http.StatusOK},
+		{name: "Proxied from out of the subnet", remoteAddr: "10.0.0.1:1234", realIP: "198.51.100.1",
+			want: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/internal/stats", nil)
+			request.RemoteAddr = test.remoteAddr
+			if test.realIP != "" {
+				request.Header.Set("X-Real-IP", test.realIP)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			assert.Equal(t, test.want, recorder.Code)
+		})
+	}
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 
@@ -36,6 +37,49 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddVariantClicks mocks base method.
+func (m *MockRepository) AddVariantClicks(arg0 context.Context, arg1, arg2 string, arg3 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddVariantClicks", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddVariantClicks indicates an expected call of AddVariantClicks.
+func (mr *MockRepositoryMockRecorder) AddVariantClicks(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddVariantClicks", reflect.TypeOf((*MockRepository)(nil).AddVariantClicks), arg0, arg1, arg2, arg3)
+}
+
+// AppendAuditEvents mocks base method.
+func (m *MockRepository) AppendAuditEvents(arg0 context.Context, arg1 []models.AuditEvent, arg2 func(models.AuditEvent) string) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendAuditEvents", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AppendAuditEvents indicates an expected call of AppendAuditEvents.
+func (mr *MockRepositoryMockRecorder) AppendAuditEvents(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendAuditEvents", reflect.TypeOf((*MockRepository)(nil).AppendAuditEvents), arg0, arg1, arg2)
+}
+
+// BanUser mocks base method.
+func (m *MockRepository) BanUser(arg0 context.Context, arg1 models.UserBan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BanUser indicates an expected call of BanUser.
+func (mr *MockRepositoryMockRecorder) BanUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanUser", reflect.TypeOf((*MockRepository)(nil).BanUser), arg0, arg1)
+}
+
 // BatchCreate mocks base method.
 func (m *MockRepository) BatchCreate(arg0 context.Context, arg1 map[string]models.ShortenBatchItemRequest, arg2 string) ([]models.ShortenBatchItemResponse, error) {
 	m.ctrl.T.Helper()
@@ -52,18 +96,201 @@ func (mr *MockRepositoryMockRecorder) BatchCreate(arg0, arg1, arg2 interface{})
 }
 
 // Create mocks base method.
-func (m *MockRepository) Create(arg0 context.Context, arg1, arg2, arg3 string) (string, error) {
+func (m *MockRepository) Create(arg0 context.Context, arg1, arg2, arg3 string, arg4 models.ShortURLOptions) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockRepositoryMockRecorder) Create(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Create(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), arg0, arg1, arg2, arg3, arg4)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockRepository) CreateAPIKey(arg0 context.Context, arg1 models.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockRepositoryMockRecorder) CreateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockRepository)(nil).CreateAPIKey), arg0, arg1)
+}
+
+// CreateAccount mocks base method.
+func (m *MockRepository) CreateAccount(arg0 context.Context, arg1 models.Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockRepositoryMockRecorder) CreateAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockRepository)(nil).CreateAccount), arg0, arg1)
+}
+
+// CreateAuditEvent mocks base method.
+func (m *MockRepository) CreateAuditEvent(arg0 context.Context, arg1 models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockRepositoryMockRecorder) CreateAuditEvent(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockRepository)(nil).CreateAuditEvent), arg0, arg1)
+}
+
+// CreateCampaign mocks base method.
+func (m *MockRepository) CreateCampaign(arg0 context.Context, arg1 models.Campaign) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCampaign indicates an expected call of CreateCampaign.
+func (mr *MockRepositoryMockRecorder) CreateCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCampaign", reflect.TypeOf((*MockRepository)(nil).CreateCampaign), arg0, arg1)
+}
+
+// CreateOIDCIdentity mocks base method.
+func (m *MockRepository) CreateOIDCIdentity(arg0 context.Context, arg1 models.OIDCIdentity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOIDCIdentity", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOIDCIdentity indicates an expected call of CreateOIDCIdentity.
+func (mr *MockRepositoryMockRecorder) CreateOIDCIdentity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOIDCIdentity", reflect.TypeOf((*MockRepository)(nil).CreateOIDCIdentity), arg0, arg1)
+}
+
+// CreateOrganization mocks base method.
+func (m *MockRepository) CreateOrganization(arg0 context.Context, arg1 models.Organization, arg2 models.OrgMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockRepositoryMockRecorder) CreateOrganization(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockRepository)(nil).CreateOrganization), arg0, arg1, arg2)
+}
+
+// CreateUTMTemplate mocks base method.
+func (m *MockRepository) CreateUTMTemplate(arg0 context.Context, arg1 models.UTMTemplate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUTMTemplate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUTMTemplate indicates an expected call of CreateUTMTemplate.
+func (mr *MockRepositoryMockRecorder) CreateUTMTemplate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUTMTemplate", reflect.TypeOf((*MockRepository)(nil).CreateUTMTemplate), arg0, arg1)
+}
+
+// DeleteAPIKey mocks base method.
+func (m *MockRepository) DeleteAPIKey(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MockRepositoryMockRecorder) DeleteAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockRepository)(nil).DeleteAPIKey), arg0, arg1)
+}
+
+// DeleteCampaign mocks base method.
+func (m *MockRepository) DeleteCampaign(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCampaign indicates an expected call of DeleteCampaign.
+func (mr *MockRepositoryMockRecorder) DeleteCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCampaign", reflect.TypeOf((*MockRepository)(nil).DeleteCampaign), arg0, arg1)
+}
+
+// DeleteOrgMember mocks base method.
+func (m *MockRepository) DeleteOrgMember(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrgMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrgMember indicates an expected call of DeleteOrgMember.
+func (mr *MockRepositoryMockRecorder) DeleteOrgMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrgMember", reflect.TypeOf((*MockRepository)(nil).DeleteOrgMember), arg0, arg1, arg2)
+}
+
+// DeleteUTMTemplate mocks base method.
+func (m *MockRepository) DeleteUTMTemplate(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUTMTemplate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUTMTemplate indicates an expected call of DeleteUTMTemplate.
+func (mr *MockRepositoryMockRecorder) DeleteUTMTemplate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUTMTemplate", reflect.TypeOf((*MockRepository)(nil).DeleteUTMTemplate), arg0, arg1)
+}
+
+// DeleteUnverifiedAccount mocks base method.
+func (m *MockRepository) DeleteUnverifiedAccount(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUnverifiedAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUnverifiedAccount indicates an expected call of DeleteUnverifiedAccount.
+func (mr *MockRepositoryMockRecorder) DeleteUnverifiedAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUnverifiedAccount", reflect.TypeOf((*MockRepository)(nil).DeleteUnverifiedAccount), arg0, arg1)
+}
+
+// GetCampaignStats mocks base method.
+func (m *MockRepository) GetCampaignStats(arg0 context.Context, arg1 string) (*models.CampaignStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaignStats", arg0, arg1)
+	ret0, _ := ret[0].(*models.CampaignStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaignStats indicates an expected call of GetCampaignStats.
+func (mr *MockRepositoryMockRecorder) GetCampaignStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaignStats", reflect.TypeOf((*MockRepository)(nil).GetCampaignStats), arg0, arg1)
 }
 
 // GetStats mocks base method.
@@ -81,19 +308,19 @@ func (mr *MockRepositoryMockRecorder) GetStats(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockRepository)(nil).GetStats), arg0)
 }
 
-// GetUserIDByShortURL mocks base method.
-func (m *MockRepository) GetUserIDByShortURL(arg0 context.Context, arg1 string) (string, error) {
+// IsTokenRevoked mocks base method.
+func (m *MockRepository) IsTokenRevoked(arg0 context.Context, arg1, arg2 string, arg3 time.Time) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserIDByShortURL", arg0, arg1)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "IsTokenRevoked", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserIDByShortURL indicates an expected call of GetUserIDByShortURL.
-func (mr *MockRepositoryMockRecorder) GetUserIDByShortURL(arg0, arg1 interface{}) *gomock.Call {
+// IsTokenRevoked indicates an expected call of IsTokenRevoked.
+func (mr *MockRepositoryMockRecorder) IsTokenRevoked(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDByShortURL", reflect.TypeOf((*MockRepository)(nil).GetUserIDByShortURL), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenRevoked", reflect.TypeOf((*MockRepository)(nil).IsTokenRevoked), arg0, arg1, arg2, arg3)
 }
 
 // Ping mocks base method.
@@ -125,19 +352,389 @@ func (mr *MockRepositoryMockRecorder) Read(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockRepository)(nil).Read), arg0, arg1)
 }
 
+// ReadAPIKey mocks base method.
+func (m *MockRepository) ReadAPIKey(arg0 context.Context, arg1 string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAPIKey indicates an expected call of ReadAPIKey.
+func (mr *MockRepositoryMockRecorder) ReadAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAPIKey", reflect.TypeOf((*MockRepository)(nil).ReadAPIKey), arg0, arg1)
+}
+
+// ReadAPIKeyByHash mocks base method.
+func (m *MockRepository) ReadAPIKeyByHash(arg0 context.Context, arg1 string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAPIKeyByHash", arg0, arg1)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAPIKeyByHash indicates an expected call of ReadAPIKeyByHash.
+func (mr *MockRepositoryMockRecorder) ReadAPIKeyByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAPIKeyByHash", reflect.TypeOf((*MockRepository)(nil).ReadAPIKeyByHash), arg0, arg1)
+}
+
+// ReadAPIKeysByUserID mocks base method.
+func (m *MockRepository) ReadAPIKeysByUserID(arg0 context.Context, arg1 string) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAPIKeysByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAPIKeysByUserID indicates an expected call of ReadAPIKeysByUserID.
+func (mr *MockRepositoryMockRecorder) ReadAPIKeysByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAPIKeysByUserID", reflect.TypeOf((*MockRepository)(nil).ReadAPIKeysByUserID), arg0, arg1)
+}
+
+// ReadAccount mocks base method.
+func (m *MockRepository) ReadAccount(arg0 context.Context, arg1 string) (*models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAccount", arg0, arg1)
+	ret0, _ := ret[0].(*models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAccount indicates an expected call of ReadAccount.
+func (mr *MockRepositoryMockRecorder) ReadAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAccount", reflect.TypeOf((*MockRepository)(nil).ReadAccount), arg0, arg1)
+}
+
+// ReadAccountByEmail mocks base method.
+func (m *MockRepository) ReadAccountByEmail(arg0 context.Context, arg1 string) (*models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAccountByEmail", arg0, arg1)
+	ret0, _ := ret[0].(*models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAccountByEmail indicates an expected call of ReadAccountByEmail.
+func (mr *MockRepositoryMockRecorder) ReadAccountByEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAccountByEmail", reflect.TypeOf((*MockRepository)(nil).ReadAccountByEmail), arg0, arg1)
+}
+
+// ReadAuditEvents mocks base method.
+func (m *MockRepository) ReadAuditEvents(arg0 context.Context, arg1 models.AuditFilter) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAuditEvents indicates an expected call of ReadAuditEvents.
+func (mr *MockRepositoryMockRecorder) ReadAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuditEvents", reflect.TypeOf((*MockRepository)(nil).ReadAuditEvents), arg0, arg1)
+}
+
+// ReadByOrgID mocks base method.
+func (m *MockRepository) ReadByOrgID(arg0 context.Context, arg1 string, arg2 models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadByOrgID", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.ShortURLsByUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadByOrgID indicates an expected call of ReadByOrgID.
+func (mr *MockRepositoryMockRecorder) ReadByOrgID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByOrgID", reflect.TypeOf((*MockRepository)(nil).ReadByOrgID), arg0, arg1, arg2)
+}
+
 // ReadByUserID mocks base method.
-func (m *MockRepository) ReadByUserID(arg0 context.Context, arg1 string) ([]models.ShortURLsByUserResponse, error) {
+func (m *MockRepository) ReadByUserID(arg0 context.Context, arg1 string, arg2 models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadByUserID", arg0, arg1)
+	ret := m.ctrl.Call(m, "ReadByUserID", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]models.ShortURLsByUserResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ReadByUserID indicates an expected call of ReadByUserID.
-func (mr *MockRepositoryMockRecorder) ReadByUserID(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) ReadByUserID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByUserID", reflect.TypeOf((*MockRepository)(nil).ReadByUserID), arg0, arg1, arg2)
+}
+
+// ReadCampaign mocks base method.
+func (m *MockRepository) ReadCampaign(arg0 context.Context, arg1 string) (*models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadCampaign", arg0, arg1)
+	ret0, _ := ret[0].(*models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadCampaign indicates an expected call of ReadCampaign.
+func (mr *MockRepositoryMockRecorder) ReadCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadCampaign", reflect.TypeOf((*MockRepository)(nil).ReadCampaign), arg0, arg1)
+}
+
+// ReadCampaignsByUserID mocks base method.
+func (m *MockRepository) ReadCampaignsByUserID(arg0 context.Context, arg1 string) ([]models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadCampaignsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadCampaignsByUserID indicates an expected call of ReadCampaignsByUserID.
+func (mr *MockRepositoryMockRecorder) ReadCampaignsByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadCampaignsByUserID", reflect.TypeOf((*MockRepository)(nil).ReadCampaignsByUserID), arg0, arg1)
+}
+
+// ReadOIDCIdentity mocks base method.
+func (m *MockRepository) ReadOIDCIdentity(arg0 context.Context, arg1, arg2 string) (*models.OIDCIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOIDCIdentity", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.OIDCIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadOIDCIdentity indicates an expected call of ReadOIDCIdentity.
+func (mr *MockRepositoryMockRecorder) ReadOIDCIdentity(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOIDCIdentity", reflect.TypeOf((*MockRepository)(nil).ReadOIDCIdentity), arg0, arg1, arg2)
+}
+
+// ReadOrgMember mocks base method.
+func (m *MockRepository) ReadOrgMember(arg0 context.Context, arg1, arg2 string) (*models.OrgMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOrgMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.OrgMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadOrgMember indicates an expected call of ReadOrgMember.
+func (mr *MockRepositoryMockRecorder) ReadOrgMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOrgMember", reflect.TypeOf((*MockRepository)(nil).ReadOrgMember), arg0, arg1, arg2)
+}
+
+// ReadOrgMembers mocks base method.
+func (m *MockRepository) ReadOrgMembers(arg0 context.Context, arg1 string) ([]models.OrgMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOrgMembers", arg0, arg1)
+	ret0, _ := ret[0].([]models.OrgMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadOrgMembers indicates an expected call of ReadOrgMembers.
+func (mr *MockRepositoryMockRecorder) ReadOrgMembers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOrgMembers", reflect.TypeOf((*MockRepository)(nil).ReadOrgMembers), arg0, arg1)
+}
+
+// ReadOrganization mocks base method.
+func (m *MockRepository) ReadOrganization(arg0 context.Context, arg1 string) (*models.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOrganization", arg0, arg1)
+	ret0, _ := ret[0].(*models.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadOrganization indicates an expected call of ReadOrganization.
+func (mr *MockRepositoryMockRecorder) ReadOrganization(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByUserID", reflect.TypeOf((*MockRepository)(nil).ReadByUserID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOrganization", reflect.TypeOf((*MockRepository)(nil).ReadOrganization), arg0, arg1)
+}
+
+// ReadOrganizationsByUserID mocks base method.
+func (m *MockRepository) ReadOrganizationsByUserID(arg0 context.Context, arg1 string) ([]models.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOrganizationsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]models.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadOrganizationsByUserID indicates an expected call of ReadOrganizationsByUserID.
+func (mr *MockRepositoryMockRecorder) ReadOrganizationsByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOrganizationsByUserID", reflect.TypeOf((*MockRepository)(nil).ReadOrganizationsByUserID), arg0, arg1)
+}
+
+// ReadShortURL mocks base method.
+func (m *MockRepository) ReadShortURL(arg0 context.Context, arg1 string) (*models.ShortURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadShortURL", arg0, arg1)
+	ret0, _ := ret[0].(*models.ShortURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadShortURL indicates an expected call of ReadShortURL.
+func (mr *MockRepositoryMockRecorder) ReadShortURL(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadShortURL", reflect.TypeOf((*MockRepository)(nil).ReadShortURL), arg0, arg1)
+}
+
+// ReadUTMTemplate mocks base method.
+func (m *MockRepository) ReadUTMTemplate(arg0 context.Context, arg1 string) (*models.UTMTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadUTMTemplate", arg0, arg1)
+	ret0, _ := ret[0].(*models.UTMTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadUTMTemplate indicates an expected call of ReadUTMTemplate.
+func (mr *MockRepositoryMockRecorder) ReadUTMTemplate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadUTMTemplate", reflect.TypeOf((*MockRepository)(nil).ReadUTMTemplate), arg0, arg1)
+}
+
+// ReadUTMTemplatesByUserID mocks base method.
+func (m *MockRepository) ReadUTMTemplatesByUserID(arg0 context.Context, arg1 string) ([]models.UTMTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadUTMTemplatesByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]models.UTMTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadUTMTemplatesByUserID indicates an expected call of ReadUTMTemplatesByUserID.
+func (mr *MockRepositoryMockRecorder) ReadUTMTemplatesByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadUTMTemplatesByUserID", reflect.TypeOf((*MockRepository)(nil).ReadUTMTemplatesByUserID), arg0, arg1)
+}
+
+// ReadUserBan mocks base method.
+func (m *MockRepository) ReadUserBan(arg0 context.Context, arg1 string) (*models.UserBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadUserBan", arg0, arg1)
+	ret0, _ := ret[0].(*models.UserBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadUserBan indicates an expected call of ReadUserBan.
+func (mr *MockRepositoryMockRecorder) ReadUserBan(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadUserBan", reflect.TypeOf((*MockRepository)(nil).ReadUserBan), arg0, arg1)
+}
+
+// ReadVariantClicks mocks base method.
+func (m *MockRepository) ReadVariantClicks(arg0 context.Context, arg1 string) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadVariantClicks", arg0, arg1)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadVariantClicks indicates an expected call of ReadVariantClicks.
+func (mr *MockRepositoryMockRecorder) ReadVariantClicks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadVariantClicks", reflect.TypeOf((*MockRepository)(nil).ReadVariantClicks), arg0, arg1)
+}
+
+// RevokeToken mocks base method.
+func (m *MockRepository) RevokeToken(arg0 context.Context, arg1, arg2 string, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockRepositoryMockRecorder) RevokeToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockRepository)(nil).RevokeToken), arg0, arg1, arg2, arg3)
+}
+
+// RevokeUserTokens mocks base method.
+func (m *MockRepository) RevokeUserTokens(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserTokens", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserTokens indicates an expected call of RevokeUserTokens.
+func (mr *MockRepositoryMockRecorder) RevokeUserTokens(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserTokens", reflect.TypeOf((*MockRepository)(nil).RevokeUserTokens), arg0, arg1, arg2)
+}
+
+// SaveOrgMember mocks base method.
+func (m *MockRepository) SaveOrgMember(arg0 context.Context, arg1 models.OrgMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrgMember", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOrgMember indicates an expected call of SaveOrgMember.
+func (mr *MockRepositoryMockRecorder) SaveOrgMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrgMember", reflect.TypeOf((*MockRepository)(nil).SaveOrgMember), arg0, arg1)
+}
+
+// SearchShortURLs mocks base method.
+func (m *MockRepository) SearchShortURLs(arg0 context.Context, arg1 models.ShortURLSearch) ([]models.AdminShortURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchShortURLs", arg0, arg1)
+	ret0, _ := ret[0].([]models.AdminShortURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchShortURLs indicates an expected call of SearchShortURLs.
+func (mr *MockRepositoryMockRecorder) SearchShortURLs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchShortURLs", reflect.TypeOf((*MockRepository)(nil).SearchShortURLs), arg0, arg1)
+}
+
+// SetMetadata mocks base method.
+func (m *MockRepository) SetMetadata(arg0 context.Context, arg1 string, arg2 models.PageMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMetadata", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMetadata indicates an expected call of SetMetadata.
+func (mr *MockRepositoryMockRecorder) SetMetadata(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMetadata", reflect.TypeOf((*MockRepository)(nil).SetMetadata), arg0, arg1, arg2)
+}
+
+// SetModeration mocks base method.
+func (m *MockRepository) SetModeration(arg0 context.Context, arg1 string, arg2 *models.Moderation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetModeration", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetModeration indicates an expected call of SetModeration.
+func (mr *MockRepositoryMockRecorder) SetModeration(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetModeration", reflect.TypeOf((*MockRepository)(nil).SetModeration), arg0, arg1, arg2)
 }
 
 // SetURLsInactive mocks base method.
@@ -153,3 +750,88 @@ func (mr *MockRepositoryMockRecorder) SetURLsInactive(arg0, arg1 interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetURLsInactive", reflect.TypeOf((*MockRepository)(nil).SetURLsInactive), arg0, arg1)
 }
+
+// TouchAPIKey mocks base method.
+func (m *MockRepository) TouchAPIKey(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockRepositoryMockRecorder) TouchAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockRepository)(nil).TouchAPIKey), arg0, arg1, arg2)
+}
+
+// UnbanUser mocks base method.
+func (m *MockRepository) UnbanUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanUser indicates an expected call of UnbanUser.
+func (mr *MockRepositoryMockRecorder) UnbanUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanUser", reflect.TypeOf((*MockRepository)(nil).UnbanUser), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockRepository) Update(arg0 context.Context, arg1 string, arg2 models.UpdateShortURLRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRepositoryMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), arg0, arg1, arg2)
+}
+
+// UpdateCampaign mocks base method.
+func (m *MockRepository) UpdateCampaign(arg0 context.Context, arg1 models.Campaign) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCampaign", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCampaign indicates an expected call of UpdateCampaign.
+func (mr *MockRepositoryMockRecorder) UpdateCampaign(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCampaign", reflect.TypeOf((*MockRepository)(nil).UpdateCampaign), arg0, arg1)
+}
+
+// UpdateUTMTemplate mocks base method.
+func (m *MockRepository) UpdateUTMTemplate(arg0 context.Context, arg1 models.UTMTemplate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUTMTemplate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUTMTemplate indicates an expected call of UpdateUTMTemplate.
+func (mr *MockRepositoryMockRecorder) UpdateUTMTemplate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUTMTemplate", reflect.TypeOf((*MockRepository)(nil).UpdateUTMTemplate), arg0, arg1)
+}
+
+// UseClick mocks base method.
+func (m *MockRepository) UseClick(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseClick", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseClick indicates an expected call of UseClick.
+func (mr *MockRepositoryMockRecorder) UseClick(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseClick", reflect.TypeOf((*MockRepository)(nil).UseClick), arg0, arg1)
+}
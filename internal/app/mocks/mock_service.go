@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 
@@ -36,6 +37,51 @@ func (m *MockShortURLServiceInterface) EXPECT() *MockShortURLServiceInterfaceMoc
 	return m.recorder
 }
 
+// AddOrgMember mocks base method.
+func (m *MockShortURLServiceInterface) AddOrgMember(arg0 context.Context, arg1, arg2 string, arg3 models.AddOrgMemberRequest) (*models.OrgMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOrgMember", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.OrgMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddOrgMember indicates an expected call of AddOrgMember.
+func (mr *MockShortURLServiceInterfaceMockRecorder) AddOrgMember(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrgMember", reflect.TypeOf((*MockShortURLServiceInterface)(nil).AddOrgMember), arg0, arg1, arg2, arg3)
+}
+
+// AdminReadShortURL mocks base method.
+func (m *MockShortURLServiceInterface) AdminReadShortURL(arg0 context.Context, arg1 string) (*models.AdminShortURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminReadShortURL", arg0, arg1)
+	ret0, _ := ret[0].(*models.AdminShortURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminReadShortURL indicates an expected call of AdminReadShortURL.
+func (mr *MockShortURLServiceInterfaceMockRecorder) AdminReadShortURL(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminReadShortURL", reflect.TypeOf((*MockShortURLServiceInterface)(nil).AdminReadShortURL), arg0, arg1)
+}
+
+// BanUser mocks base method.
+func (m *MockShortURLServiceInterface) BanUser(arg0 context.Context, arg1, arg2 string) (*models.UserBan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.UserBan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BanUser indicates an expected call of BanUser.
+func (mr *MockShortURLServiceInterfaceMockRecorder) BanUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanUser", reflect.TypeOf((*MockShortURLServiceInterface)(nil).BanUser), arg0, arg1, arg2)
+}
+
 // BatchCreate mocks base method.
 func (m *MockShortURLServiceInterface) BatchCreate(arg0 context.Context, arg1 []models.ShortenBatchItemRequest, arg2 string) ([]models.ShortenBatchItemResponse, error) {
 	m.ctrl.T.Helper()
@@ -51,19 +97,189 @@ func (mr *MockShortURLServiceInterfaceMockRecorder) BatchCreate(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreate", reflect.TypeOf((*MockShortURLServiceInterface)(nil).BatchCreate), arg0, arg1, arg2)
 }
 
+// CheckPassword mocks base method.
+func (m *MockShortURLServiceInterface) CheckPassword(arg0 *models.ShortURL, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPassword", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckPassword indicates an expected call of CheckPassword.
+func (mr *MockShortURLServiceInterfaceMockRecorder) CheckPassword(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPassword", reflect.TypeOf((*MockShortURLServiceInterface)(nil).CheckPassword), arg0, arg1, arg2)
+}
+
 // Create mocks base method.
-func (m *MockShortURLServiceInterface) Create(arg0 context.Context, arg1, arg2 string) (string, error) {
+func (m *MockShortURLServiceInterface) Create(arg0 context.Context, arg1, arg2 string, arg3 models.ShortURLOptions) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockShortURLServiceInterfaceMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockShortURLServiceInterfaceMockRecorder) Create(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockShortURLServiceInterface)(nil).Create), arg0, arg1, arg2, arg3)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockShortURLServiceInterface) CreateAPIKey(arg0 context.Context, arg1 string, arg2 models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.CreatedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockShortURLServiceInterfaceMockRecorder) CreateAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockShortURLServiceInterface)(nil).CreateAPIKey), arg0, arg1, arg2)
+}
+
+// CreateCampaign mocks base method.
+func (m *MockShortURLServiceInterface) CreateCampaign(arg0 context.Context, arg1 string, arg2 models.Campaign) (*models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCampaign", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCampaign indicates an expected call of CreateCampaign.
+func (mr *MockShortURLServiceInterfaceMockRecorder) CreateCampaign(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCampaign", reflect.TypeOf((*MockShortURLServiceInterface)(nil).CreateCampaign), arg0, arg1, arg2)
+}
+
+// CreateOrganization mocks base method.
+func (m *MockShortURLServiceInterface) CreateOrganization(arg0 context.Context, arg1 string, arg2 models.Organization) (*models.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockShortURLServiceInterfaceMockRecorder) CreateOrganization(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockShortURLServiceInterface)(nil).CreateOrganization), arg0, arg1, arg2)
+}
+
+// CreateUTMTemplate mocks base method.
+func (m *MockShortURLServiceInterface) CreateUTMTemplate(arg0 context.Context, arg1 string, arg2 models.UTMTemplate) (*models.UTMTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUTMTemplate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.UTMTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUTMTemplate indicates an expected call of CreateUTMTemplate.
+func (mr *MockShortURLServiceInterfaceMockRecorder) CreateUTMTemplate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUTMTemplate", reflect.TypeOf((*MockShortURLServiceInterface)(nil).CreateUTMTemplate), arg0, arg1, arg2)
+}
+
+// DeleteAPIKey mocks base method.
+func (m *MockShortURLServiceInterface) DeleteAPIKey(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MockShortURLServiceInterfaceMockRecorder) DeleteAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockShortURLServiceInterface)(nil).DeleteAPIKey), arg0, arg1, arg2)
+}
+
+// DeleteCampaign mocks base method.
+func (m *MockShortURLServiceInterface) DeleteCampaign(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCampaign", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCampaign indicates an expected call of DeleteCampaign.
+func (mr *MockShortURLServiceInterfaceMockRecorder) DeleteCampaign(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCampaign", reflect.TypeOf((*MockShortURLServiceInterface)(nil).DeleteCampaign), arg0, arg1, arg2)
+}
+
+// DeleteUTMTemplate mocks base method.
+func (m *MockShortURLServiceInterface) DeleteUTMTemplate(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUTMTemplate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUTMTemplate indicates an expected call of DeleteUTMTemplate.
+func (mr *MockShortURLServiceInterfaceMockRecorder) DeleteUTMTemplate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUTMTemplate", reflect.TypeOf((*MockShortURLServiceInterface)(nil).DeleteUTMTemplate), arg0, arg1, arg2)
+}
+
+// DisableShortURL mocks base method.
+func (m *MockShortURLServiceInterface) DisableShortURL(arg0 context.Context, arg1, arg2 string) (*models.AdminShortURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableShortURL", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.AdminShortURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableShortURL indicates an expected call of DisableShortURL.
+func (mr *MockShortURLServiceInterfaceMockRecorder) DisableShortURL(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockShortURLServiceInterface)(nil).Create), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableShortURL", reflect.TypeOf((*MockShortURLServiceInterface)(nil).DisableShortURL), arg0, arg1, arg2)
+}
+
+// EnableShortURL mocks base method.
+func (m *MockShortURLServiceInterface) EnableShortURL(arg0 context.Context, arg1 string) (*models.AdminShortURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableShortURL", arg0, arg1)
+	ret0, _ := ret[0].(*models.AdminShortURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableShortURL indicates an expected call of EnableShortURL.
+func (mr *MockShortURLServiceInterfaceMockRecorder) EnableShortURL(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableShortURL", reflect.TypeOf((*MockShortURLServiceInterface)(nil).EnableShortURL), arg0, arg1)
+}
+
+// FetchMetadata mocks base method.
+func (m *MockShortURLServiceInterface) FetchMetadata() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "FetchMetadata")
+}
+
+// FetchMetadata indicates an expected call of FetchMetadata.
+func (mr *MockShortURLServiceInterfaceMockRecorder) FetchMetadata() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchMetadata", reflect.TypeOf((*MockShortURLServiceInterface)(nil).FetchMetadata))
+}
+
+// FlushClicks mocks base method.
+func (m *MockShortURLServiceInterface) FlushClicks() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "FlushClicks")
+}
+
+// FlushClicks indicates an expected call of FlushClicks.
+func (mr *MockShortURLServiceInterfaceMockRecorder) FlushClicks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlushClicks", reflect.TypeOf((*MockShortURLServiceInterface)(nil).FlushClicks))
 }
 
 // FlushDeletions mocks base method.
@@ -78,6 +294,51 @@ func (mr *MockShortURLServiceInterfaceMockRecorder) FlushDeletions() *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlushDeletions", reflect.TypeOf((*MockShortURLServiceInterface)(nil).FlushDeletions))
 }
 
+// GetCampaignStats mocks base method.
+func (m *MockShortURLServiceInterface) GetCampaignStats(arg0 context.Context, arg1, arg2 string) (*models.CampaignStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaignStats", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.CampaignStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaignStats indicates an expected call of GetCampaignStats.
+func (mr *MockShortURLServiceInterfaceMockRecorder) GetCampaignStats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaignStats", reflect.TypeOf((*MockShortURLServiceInterface)(nil).GetCampaignStats), arg0, arg1, arg2)
+}
+
+// GetQRCode mocks base method.
+func (m *MockShortURLServiceInterface) GetQRCode(arg0 context.Context, arg1 string, arg2 models.QROptions) (*models.QRCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQRCode", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.QRCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQRCode indicates an expected call of GetQRCode.
+func (mr *MockShortURLServiceInterfaceMockRecorder) GetQRCode(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQRCode", reflect.TypeOf((*MockShortURLServiceInterface)(nil).GetQRCode), arg0, arg1, arg2)
+}
+
+// GetShortURLStats mocks base method.
+func (m *MockShortURLServiceInterface) GetShortURLStats(arg0 context.Context, arg1, arg2 string) (*models.ShortURLStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShortURLStats", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ShortURLStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShortURLStats indicates an expected call of GetShortURLStats.
+func (mr *MockShortURLServiceInterfaceMockRecorder) GetShortURLStats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShortURLStats", reflect.TypeOf((*MockShortURLServiceInterface)(nil).GetShortURLStats), arg0, arg1, arg2)
+}
+
 // GetStats mocks base method.
 func (m *MockShortURLServiceInterface) GetStats(arg0 context.Context) (*models.ServiceStats, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +354,51 @@ func (mr *MockShortURLServiceInterfaceMockRecorder) GetStats(arg0 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockShortURLServiceInterface)(nil).GetStats), arg0)
 }
 
+// IsTokenRevoked mocks base method.
+func (m *MockShortURLServiceInterface) IsTokenRevoked(arg0 context.Context, arg1, arg2 string, arg3 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTokenRevoked", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTokenRevoked indicates an expected call of IsTokenRevoked.
+func (mr *MockShortURLServiceInterfaceMockRecorder) IsTokenRevoked(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenRevoked", reflect.TypeOf((*MockShortURLServiceInterface)(nil).IsTokenRevoked), arg0, arg1, arg2, arg3)
+}
+
+// Login mocks base method.
+func (m *MockShortURLServiceInterface) Login(arg0 context.Context, arg1 models.Credentials, arg2 string) (*models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockShortURLServiceInterfaceMockRecorder) Login(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockShortURLServiceInterface)(nil).Login), arg0, arg1, arg2)
+}
+
+// LoginOIDC mocks base method.
+func (m *MockShortURLServiceInterface) LoginOIDC(arg0 context.Context, arg1 models.OIDCLogin, arg2 string) (*models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginOIDC", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginOIDC indicates an expected call of LoginOIDC.
+func (mr *MockShortURLServiceInterfaceMockRecorder) LoginOIDC(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginOIDC", reflect.TypeOf((*MockShortURLServiceInterface)(nil).LoginOIDC), arg0, arg1, arg2)
+}
+
 // Ping mocks base method.
 func (m *MockShortURLServiceInterface) Ping(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -123,19 +429,223 @@ func (mr *MockShortURLServiceInterfaceMockRecorder) Read(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockShortURLServiceInterface)(nil).Read), arg0, arg1)
 }
 
+// ReadAPIKeysByUserID mocks base method.
+func (m *MockShortURLServiceInterface) ReadAPIKeysByUserID(arg0 context.Context, arg1 string) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAPIKeysByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAPIKeysByUserID indicates an expected call of ReadAPIKeysByUserID.
+func (mr *MockShortURLServiceInterfaceMockRecorder) ReadAPIKeysByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAPIKeysByUserID", reflect.TypeOf((*MockShortURLServiceInterface)(nil).ReadAPIKeysByUserID), arg0, arg1)
+}
+
+// ReadAuditEvents mocks base method.
+func (m *MockShortURLServiceInterface) ReadAuditEvents(arg0 context.Context, arg1 models.AuditFilter) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAuditEvents indicates an expected call of ReadAuditEvents.
+func (mr *MockShortURLServiceInterfaceMockRecorder) ReadAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuditEvents", reflect.TypeOf((*MockShortURLServiceInterface)(nil).ReadAuditEvents), arg0, arg1)
+}
+
+// ReadByOrgID mocks base method.
+func (m *MockShortURLServiceInterface) ReadByOrgID(arg0 context.Context, arg1, arg2 string, arg3 models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadByOrgID", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.ShortURLsByUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadByOrgID indicates an expected call of ReadByOrgID.
+func (mr *MockShortURLServiceInterfaceMockRecorder) ReadByOrgID(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByOrgID", reflect.TypeOf((*MockShortURLServiceInterface)(nil).ReadByOrgID), arg0, arg1, arg2, arg3)
+}
+
 // ReadByUserID mocks base method.
-func (m *MockShortURLServiceInterface) ReadByUserID(arg0 context.Context, arg1 string) ([]models.ShortURLsByUserResponse, error) {
+func (m *MockShortURLServiceInterface) ReadByUserID(arg0 context.Context, arg1 string, arg2 models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ReadByUserID", arg0, arg1)
+	ret := m.ctrl.Call(m, "ReadByUserID", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]models.ShortURLsByUserResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ReadByUserID indicates an expected call of ReadByUserID.
-func (mr *MockShortURLServiceInterfaceMockRecorder) ReadByUserID(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockShortURLServiceInterfaceMockRecorder) ReadByUserID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByUserID", reflect.TypeOf((*MockShortURLServiceInterface)(nil).ReadByUserID), arg0, arg1, arg2)
+}
+
+// ReadCampaignsByUserID mocks base method.
+func (m *MockShortURLServiceInterface) ReadCampaignsByUserID(arg0 context.Context, arg1 string) ([]models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadCampaignsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadCampaignsByUserID indicates an expected call of ReadCampaignsByUserID.
+func (mr *MockShortURLServiceInterfaceMockRecorder) ReadCampaignsByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadCampaignsByUserID", reflect.TypeOf((*MockShortURLServiceInterface)(nil).ReadCampaignsByUserID), arg0, arg1)
+}
+
+// ReadOrgMembers mocks base method.
+func (m *MockShortURLServiceInterface) ReadOrgMembers(arg0 context.Context, arg1, arg2 string) ([]models.OrgMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOrgMembers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.OrgMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadOrgMembers indicates an expected call of ReadOrgMembers.
+func (mr *MockShortURLServiceInterfaceMockRecorder) ReadOrgMembers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOrgMembers", reflect.TypeOf((*MockShortURLServiceInterface)(nil).ReadOrgMembers), arg0, arg1, arg2)
+}
+
+// ReadOrganizationsByUserID mocks base method.
+func (m *MockShortURLServiceInterface) ReadOrganizationsByUserID(arg0 context.Context, arg1 string) ([]models.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOrganizationsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]models.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadOrganizationsByUserID indicates an expected call of ReadOrganizationsByUserID.
+func (mr *MockShortURLServiceInterfaceMockRecorder) ReadOrganizationsByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOrganizationsByUserID", reflect.TypeOf((*MockShortURLServiceInterface)(nil).ReadOrganizationsByUserID), arg0, arg1)
+}
+
+// ReadUTMTemplatesByUserID mocks base method.
+func (m *MockShortURLServiceInterface) ReadUTMTemplatesByUserID(arg0 context.Context, arg1 string) ([]models.UTMTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadUTMTemplatesByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]models.UTMTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadUTMTemplatesByUserID indicates an expected call of ReadUTMTemplatesByUserID.
+func (mr *MockShortURLServiceInterfaceMockRecorder) ReadUTMTemplatesByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadUTMTemplatesByUserID", reflect.TypeOf((*MockShortURLServiceInterface)(nil).ReadUTMTemplatesByUserID), arg0, arg1)
+}
+
+// RecordClick mocks base method.
+func (m *MockShortURLServiceInterface) RecordClick(arg0, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordClick", arg0, arg1)
+}
+
+// RecordClick indicates an expected call of RecordClick.
+func (mr *MockShortURLServiceInterfaceMockRecorder) RecordClick(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordClick", reflect.TypeOf((*MockShortURLServiceInterface)(nil).RecordClick), arg0, arg1)
+}
+
+// Register mocks base method.
+func (m *MockShortURLServiceInterface) Register(arg0 context.Context, arg1 models.Credentials, arg2, arg3 string) (*models.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockShortURLServiceInterfaceMockRecorder) Register(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockShortURLServiceInterface)(nil).Register), arg0, arg1, arg2, arg3)
+}
+
+// RemoveOrgMember mocks base method.
+func (m *MockShortURLServiceInterface) RemoveOrgMember(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveOrgMember", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveOrgMember indicates an expected call of RemoveOrgMember.
+func (mr *MockShortURLServiceInterfaceMockRecorder) RemoveOrgMember(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOrgMember", reflect.TypeOf((*MockShortURLServiceInterface)(nil).RemoveOrgMember), arg0, arg1, arg2, arg3)
+}
+
+// Resolve mocks base method.
+func (m *MockShortURLServiceInterface) Resolve(arg0 context.Context, arg1 string) (*models.ShortURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", arg0, arg1)
+	ret0, _ := ret[0].(*models.ShortURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockShortURLServiceInterfaceMockRecorder) Resolve(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockShortURLServiceInterface)(nil).Resolve), arg0, arg1)
+}
+
+// ResolveAPIKey mocks base method.
+func (m *MockShortURLServiceInterface) ResolveAPIKey(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveAPIKey indicates an expected call of ResolveAPIKey.
+func (mr *MockShortURLServiceInterfaceMockRecorder) ResolveAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveAPIKey", reflect.TypeOf((*MockShortURLServiceInterface)(nil).ResolveAPIKey), arg0, arg1)
+}
+
+// RevokeAllTokens mocks base method.
+func (m *MockShortURLServiceInterface) RevokeAllTokens(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllTokens indicates an expected call of RevokeAllTokens.
+func (mr *MockShortURLServiceInterfaceMockRecorder) RevokeAllTokens(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadByUserID", reflect.TypeOf((*MockShortURLServiceInterface)(nil).ReadByUserID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllTokens", reflect.TypeOf((*MockShortURLServiceInterface)(nil).RevokeAllTokens), arg0, arg1)
+}
+
+// RevokeToken mocks base method.
+func (m *MockShortURLServiceInterface) RevokeToken(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockShortURLServiceInterfaceMockRecorder) RevokeToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockShortURLServiceInterface)(nil).RevokeToken), arg0, arg1, arg2)
 }
 
 // ScheduleDeletionOfBatch mocks base method.
@@ -149,3 +659,121 @@ func (mr *MockShortURLServiceInterfaceMockRecorder) ScheduleDeletionOfBatch(arg0
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleDeletionOfBatch", reflect.TypeOf((*MockShortURLServiceInterface)(nil).ScheduleDeletionOfBatch), arg0)
 }
+
+// SearchShortURLs mocks base method.
+func (m *MockShortURLServiceInterface) SearchShortURLs(arg0 context.Context, arg1 models.ShortURLSearch) ([]models.AdminShortURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchShortURLs", arg0, arg1)
+	ret0, _ := ret[0].([]models.AdminShortURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchShortURLs indicates an expected call of SearchShortURLs.
+func (mr *MockShortURLServiceInterfaceMockRecorder) SearchShortURLs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchShortURLs", reflect.TypeOf((*MockShortURLServiceInterface)(nil).SearchShortURLs), arg0, arg1)
+}
+
+// UnbanUser mocks base method.
+func (m *MockShortURLServiceInterface) UnbanUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanUser indicates an expected call of UnbanUser.
+func (mr *MockShortURLServiceInterfaceMockRecorder) UnbanUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanUser", reflect.TypeOf((*MockShortURLServiceInterface)(nil).UnbanUser), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockShortURLServiceInterface) Update(arg0 context.Context, arg1, arg2 string, arg3 models.UpdateShortURLRequest) (*models.ShortURLsByUserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ShortURLsByUserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockShortURLServiceInterfaceMockRecorder) Update(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockShortURLServiceInterface)(nil).Update), arg0, arg1, arg2, arg3)
+}
+
+// UpdateCampaign mocks base method.
+func (m *MockShortURLServiceInterface) UpdateCampaign(arg0 context.Context, arg1, arg2 string, arg3 models.Campaign) (*models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCampaign", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCampaign indicates an expected call of UpdateCampaign.
+func (mr *MockShortURLServiceInterfaceMockRecorder) UpdateCampaign(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCampaign", reflect.TypeOf((*MockShortURLServiceInterface)(nil).UpdateCampaign), arg0, arg1, arg2, arg3)
+}
+
+// UpdateOrgMember mocks base method.
+func (m *MockShortURLServiceInterface) UpdateOrgMember(arg0 context.Context, arg1, arg2, arg3, arg4 string) (*models.OrgMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrgMember", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.OrgMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOrgMember indicates an expected call of UpdateOrgMember.
+func (mr *MockShortURLServiceInterfaceMockRecorder) UpdateOrgMember(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrgMember", reflect.TypeOf((*MockShortURLServiceInterface)(nil).UpdateOrgMember), arg0, arg1, arg2, arg3, arg4)
+}
+
+// UpdateUTMTemplate mocks base method.
+func (m *MockShortURLServiceInterface) UpdateUTMTemplate(arg0 context.Context, arg1, arg2 string, arg3 models.UTMTemplate) (*models.UTMTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUTMTemplate", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.UTMTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUTMTemplate indicates an expected call of UpdateUTMTemplate.
+func (mr *MockShortURLServiceInterfaceMockRecorder) UpdateUTMTemplate(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUTMTemplate", reflect.TypeOf((*MockShortURLServiceInterface)(nil).UpdateUTMTemplate), arg0, arg1, arg2, arg3)
+}
+
+// UseClick mocks base method.
+func (m *MockShortURLServiceInterface) UseClick(arg0 context.Context, arg1 *models.ShortURL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseClick", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseClick indicates an expected call of UseClick.
+func (mr *MockShortURLServiceInterfaceMockRecorder) UseClick(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseClick", reflect.TypeOf((*MockShortURLServiceInterface)(nil).UseClick), arg0, arg1)
+}
+
+// VerifyAuditLog mocks base method.
+func (m *MockShortURLServiceInterface) VerifyAuditLog(arg0 context.Context) (*models.AuditVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyAuditLog", arg0)
+	ret0, _ := ret[0].(*models.AuditVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyAuditLog indicates an expected call of VerifyAuditLog.
+func (mr *MockShortURLServiceInterfaceMockRecorder) VerifyAuditLog(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAuditLog", reflect.TypeOf((*MockShortURLServiceInterface)(nil).VerifyAuditLog), arg0)
+}
@@ -1,11 +1,332 @@
 // Package models contains all the models used for json (de)serialization in handlers.
 package models
 
-import "context"
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Passthrough modes define what part of the request to the short URL is forwarded to the destination.
+const (
+	PassthroughNone  = ""      // the query and the path suffix are ignored, the suffix is not found
+	PassthroughQuery = "query" // the query is merged into the destination query
+	PassthroughPath  = "path"  // the path suffix is appended to the destination path, except the reserved suffix qr
+)
+
+// TargetingRule is the model of the destination chosen for the visitors of the platform
+// detected from the User-Agent header, e.g. the App Store for iOS.
+type TargetingRule struct {
+	Platform string `json:"platform"` // one of utils.TargetingPlatforms
+	URL      string `json:"url"`      // the web page or the deep link of the app
+}
+
+// GeoRule is the model of the destination chosen for the visitors from the country resolved from their address,
+// e.g. the regional site.
+type GeoRule struct {
+	Country string `json:"country"` // ISO 3166-1 alpha-2 code
+	URL     string `json:"url"`
+}
+
+// SplitVariant is the model of one of the destinations the traffic of the short URL is split across by weight.
+type SplitVariant struct {
+	Name   string `json:"name"` // unique within the short URL, the visitor keeps it in the cookie
+	URL    string `json:"url"`
+	Weight int    `json:"weight"` // the share of the traffic relative to the weights of the other variants
+}
+
+// RedirectOptions is the model of optional per-link attributes that control what happens
+// when the short URL is followed.
+type RedirectOptions struct {
+	CacheControl   string          `json:"cache_control,omitempty"`   // overrides the Cache-Control header of the redirect
+	ReferrerPolicy string          `json:"referrer_policy,omitempty"` // overrides the Referrer-Policy header
+	RobotsTag      string          `json:"robots_tag,omitempty"`      // overrides the X-Robots-Tag header
+	Passthrough    string          `json:"passthrough,omitempty"`     // one of the Passthrough modes
+	ActiveFrom     string          `json:"active_from,omitempty"`     // RFC 3339 time the short URL isn't found before
+	ActiveUntil    string          `json:"active_until,omitempty"`    // RFC 3339 time the short URL is gone after
+	Targets        []TargetingRule `json:"targets,omitempty"`         // the first one matching the visitor wins, the original URL otherwise
+	GeoRules       []GeoRule       `json:"geo_rules,omitempty"`       // checked before the targets, the original URL is the fallback
+	Variants       []SplitVariant  `json:"variants,omitempty"`        // replace the original URL unless a rule above matches
+	RedirectStatus int             `json:"redirect_status,omitempty"` // one of 301, 302, 307, 308; the server default if zero
+	Interstitial   bool            `json:"interstitial,omitempty"`    // show the warning page instead of the immediate redirect
+}
+
+// ShortURLOptions is the model of optional user-defined attributes that can be attached to the short URL
+// at creation time and changed later with an update.
+type ShortURLOptions struct {
+	Title        string   `json:"title,omitempty"`
+	Notes        string   `json:"notes,omitempty"`
+	Password     string   `json:"password,omitempty"` // plain password accepted on creation, never stored nor returned
+	PasswordHash string   `json:"-"`                  // salted hash of the password that protects the redirect
+	Tags         []string `json:"tags,omitempty"`
+	// UTMTemplateID is the ID of the user-owned UTM template merged into the destination at redirect time.
+	UTMTemplateID string `json:"utm_template_id,omitempty"`
+	// CampaignID is the ID of the user-owned campaign the short URL is grouped into.
+	CampaignID string `json:"campaign_id,omitempty"`
+	// Domain is the host of the branded domain the short URL is followed on, the default domain if empty. Set on creation only.
+	Domain string `json:"domain,omitempty"`
+	// OrgID is the ID of the organization the short URL is owned by, so its members manage it according to their roles.
+	// The short URL is personal if empty. Set on creation only.
+	OrgID string `json:"org_id,omitempty"`
+	RedirectOptions
+	// MaxClicks is the number of redirects after which the short URL is gone, unlimited if zero. Set on creation only.
+	MaxClicks int64 `json:"max_clicks,omitempty"`
+}
+
+// Protected reports whether the visitors must enter the password to follow the short URL.
+func (o ShortURLOptions) Protected() bool {
+	return o.PasswordHash != ""
+}
+
+// ClickLimited reports whether the short URL is gone after the limited number of redirects.
+func (o ShortURLOptions) ClickLimited() bool {
+	return o.MaxClicks > 0
+}
+
+// UTMParameters is the model of the UTM parameters added to the destination of the short URL.
+type UTMParameters struct {
+	Source   string `json:"utm_source,omitempty"`
+	Medium   string `json:"utm_medium,omitempty"`
+	Campaign string `json:"utm_campaign,omitempty"`
+	Term     string `json:"utm_term,omitempty"`
+	Content  string `json:"utm_content,omitempty"`
+}
+
+// RawQuery returns the non-empty parameters encoded as the query in the fixed order.
+func (p UTMParameters) RawQuery() string {
+	pairs := []struct{ key, value string }{
+		{"utm_source", p.Source},
+		{"utm_medium", p.Medium},
+		{"utm_campaign", p.Campaign},
+		{"utm_term", p.Term},
+		{"utm_content", p.Content},
+	}
+	var query []string
+	for _, pair := range pairs {
+		if pair.value != "" {
+			query = append(query, pair.key+"="+url.QueryEscape(pair.value))
+		}
+	}
+	return strings.Join(query, "&")
+}
+
+// UTMTemplate is the model of the reusable set of UTM parameters owned by the user, used in UTM templates handlers.
+type UTMTemplate struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	UserID string `json:"-"`
+	UTMParameters
+}
+
+// Campaign is the model of the user-owned group of the short URLs, used in campaigns handlers.
+type Campaign struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	UserID string `json:"-"`
+}
+
+// Account is the model of the registered user, used in account handlers. The ID is the user ID the short URLs
+// of the account are owned by, so the links of the anonymous user are kept when the account takes over its ID.
+type Account struct {
+	RegisteredAt time.Time  `json:"registered_at"`
+	VerifiedAt   *time.Time `json:"verified_at,omitempty"` // set once the email is confirmed
+	ID           string     `json:"id"`
+	Email        string     `json:"email"`
+	PasswordHash string     `json:"-"`
+}
+
+// Credentials is the model of input JSON used in RegisterHandler and LoginHandler.
+type Credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	// ClaimLinks makes the new account take over the short URLs of the current anonymous user, registration only.
+	ClaimLinks bool `json:"claim_links"`
+}
+
+// APIKey is the model of the personal API key used by the scripts instead of the auth cookie,
+// used in API keys handlers. Only the hash of the key is stored, the prefix is kept to tell the keys apart.
+type APIKey struct {
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // the key never expires if not set
+	ID         string     `json:"id"`
+	UserID     string     `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+}
+
+// CreateAPIKeyRequest is the model of input JSON used in CreateAPIKeyHandler.
+type CreateAPIKeyRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+	Name      string     `json:"name"`
+}
+
+// CreatedAPIKey is the model of output JSON used in CreateAPIKeyHandler, the only response with the plain key.
+type CreatedAPIKey struct {
+	Key string `json:"key"`
+	APIKey
+}
+
+// TokenRevocation is the model of the logout kept server-side: either the single token found by its ID
+// is revoked until ExpiresAt, or all the tokens of the user issued before RevokedBefore.
+type TokenRevocation struct {
+	RevokedBefore time.Time `json:"revoked_before,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at,omitempty"` // the token can't be used nor reissued afterward anyway
+	TokenID       string    `json:"token_id,omitempty"`
+	UserID        string    `json:"user_id"`
+}
+
+// OIDCIdentity is the model of the user of the identity provider linked to the local user.
+type OIDCIdentity struct {
+	CreatedAt time.Time `json:"created_at"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	UserID    string    `json:"user_id"`
+}
+
+// OIDCLogin is the model of the verified ID token the user signs in with.
+type OIDCLogin struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	// ClaimLinks makes the account created on the first login take over the short URLs of the current anonymous user.
+	ClaimLinks bool
+}
+
+// Roles of the organization members, each one allows everything the previous one does.
+const (
+	RoleViewer = "viewer" // reads the short URLs of the organization and their statistics
+	RoleEditor = "editor" // creates, changes and deletes the short URLs of the organization
+	RoleAdmin  = "admin"  // manages the members, except for the owners
+	RoleOwner  = "owner"  // manages the owners as well
+)
+
+// Organization is the model of the team that owns the short URLs together, used in organizations handlers.
+type Organization struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role,omitempty"` // the role of the current user in the organization, when listed
+}
+
+// OrgMember is the model of the user's membership in the organization, used in organization members handlers.
+type OrgMember struct {
+	CreatedAt time.Time `json:"created_at"`
+	OrgID     string    `json:"-"`
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email,omitempty"` // the email of the member's account, when listed
+	Role      string    `json:"role"`
+}
+
+// AddOrgMemberRequest is the model of input JSON used in AddOrgMemberHandler. The member is found by the email
+// of the account.
+type AddOrgMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// Moderation is the model of the short URL disabled by the admin, its redirects are blocked until it is enabled.
+type Moderation struct {
+	DisabledAt time.Time `json:"disabled_at"`
+	Reason     string    `json:"reason"`
+}
+
+// UserBan is the model of the user banned by the admin: the user can't create the short URLs
+// and the redirects of the user's short URLs are blocked.
+type UserBan struct {
+	BannedAt time.Time `json:"banned_at"`
+	UserID   string    `json:"user_id"`
+	Reason   string    `json:"reason"`
+}
+
+// ModerationRequest is the model of input JSON used in the admin handlers that disable the short URL or ban the user.
+type ModerationRequest struct {
+	Reason string `json:"reason"`
+}
+
+// AdminShortURL is the model of the short URL of any user shown to the admin along with its moderation state.
+type AdminShortURL struct {
+	Moderation  *Moderation `json:"moderation,omitempty"` // nil unless the short URL is disabled
+	ShortURL    string      `json:"short_url"`
+	OriginalURL string      `json:"original_url"`
+	UserID      string      `json:"user_id"`
+	OrgID       string      `json:"org_id,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Deleted     bool        `json:"deleted"`
+	OwnerBanned bool        `json:"owner_banned"`
+}
+
+// ShortURLSearch is the model of filters the admin looks the short URLs of all the users up with.
+// The non-empty filters are combined, at least one of them is required.
+type ShortURLSearch struct {
+	OriginalURL string // only the short URLs redirecting to this destination are returned if not empty
+	Domain      string // only the short URLs redirecting to this host or its subdomains are returned if not empty
+	UserID      string // only the short URLs created by this user are returned if not empty
+	Limit       int    // the maximum amount of the short URLs returned
+}
+
+// Transports the mutation is requested with, recorded in the audit log.
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// Actor is the model of the caller who requests the mutation, recorded in the audit log.
+type Actor struct {
+	UserID       string // the authenticated user, empty for the admin calls with the shared token
+	IP           string // the source address of the request, the client one behind the trusted proxy
+	ForwardedFor string // the addresses the request is forwarded for as claimed by the headers, not trusted
+	Transport    string // one of TransportHTTP and TransportGRPC, empty for the mutations made by the service itself
+}
+
+// AuditEvent is the model of the record of the append-only audit log. Each event is chained to the previous one:
+// its hash covers its own attributes along with the hash of the previous event, so the changed or removed events
+// break the chain.
+type AuditEvent struct {
+	CreatedAt    time.Time `json:"created_at"`
+	Action       string    `json:"action"`                  // e.g. url.create, see the service for the full list
+	Target       string    `json:"target"`                  // the ID of the short URL, the template, the user etc.
+	UserID       string    `json:"user_id,omitempty"`       // the user the mutation is made by or on behalf of
+	IP           string    `json:"ip,omitempty"`            // the source address the mutation is requested from
+	ForwardedFor string    `json:"forwarded_for,omitempty"` // the forwarded addresses claimed by the caller, not trusted
+	Transport    string    `json:"transport,omitempty"`     // one of TransportHTTP and TransportGRPC
+	PrevHash     string    `json:"prev_hash"`               // empty for the first event
+	Hash         string    `json:"hash"`
+	Seq          int64     `json:"seq"` // the position of the event in the log, starting from 1
+}
+
+// AuditFilter is the model of filters the admin reads the audit log with. The non-empty filters are combined.
+type AuditFilter struct {
+	From      time.Time // only the events created at this time or later are returned if not zero
+	To        time.Time // only the events created before this time are returned if not zero
+	Action    string
+	Target    string
+	UserID    string
+	Transport string
+	AfterSeq  int64 // only the events following this position are returned, used for paging
+	Limit     int   // the maximum amount of the events returned
+}
+
+// AuditVerification is the model of the message that the audit log verification handler responds with.
+type AuditVerification struct {
+	Events   int64 `json:"events"`              // the amount of the events checked
+	BrokenAt int64 `json:"broken_at,omitempty"` // the position of the first event that breaks the chain
+	Valid    bool  `json:"valid"`
+}
+
+// CampaignStats is the model of the message that the campaign statistics handler responds with.
+type CampaignStats struct {
+	Campaign
+	URLs   int   `json:"urls"`   // the amount of the active short URLs in the campaign
+	Clicks int64 `json:"clicks"` // the clicks counted on the split variants of the active short URLs in the campaign
+}
 
 // ShortenRequest model is the model of input JSON used in CreateJSONShortURLHandler
 type ShortenRequest struct {
 	URL string `json:"url"`
+	ShortURLOptions
 }
 
 // ShortenResponse model is the model of output JSON used in CreateJSONShortURLHandler
@@ -17,6 +338,7 @@ type ShortenResponse struct {
 type ShortenBatchItemRequest struct {
 	CorrelationID string `json:"correlation_id"`
 	OriginalURL   string `json:"original_url"`
+	ShortURLOptions
 }
 
 // ShortenBatchItemResponse is the model of output JSON used in BatchCreateShortURLHandler and ShortURLService
@@ -25,21 +347,174 @@ type ShortenBatchItemResponse struct {
 	ShortURL      string `json:"short_url"`
 }
 
+// PageMetadata is the model of the destination page metadata fetched in the background after the short URL is created.
+type PageMetadata struct {
+	OpenGraph  map[string]string `json:"open_graph,omitempty"` // og:* properties without the "og:" prefix
+	Title      string            `json:"title,omitempty"`
+	FaviconURL string            `json:"favicon_url,omitempty"`
+}
+
 // ShortURLsByUserResponse is the model of output JSON used in GetAllURLsForUserHandler.
 type ShortURLsByUserResponse struct {
-	ShortURL    string `json:"short_url"`
-	OriginalURL string `json:"original_url"`
+	Metadata    *PageMetadata `json:"metadata,omitempty"`
+	ShortURL    string        `json:"short_url"`
+	OriginalURL string        `json:"original_url"`
+	ClicksLeft  *int64        `json:"clicks_left,omitempty"` // the redirects left for the click-limited short URL only
+	ShortURLOptions
+	PasswordProtected bool `json:"password_protected,omitempty"`
+}
+
+// ShortURLFilter is the model of filters that can be applied to the list of the user-owned URLs.
+type ShortURLFilter struct {
+	Tag        string // only the URLs marked with this tag are returned if not empty
+	CampaignID string // only the URLs of this campaign are returned if not empty
+}
+
+// UpdateShortURLRequest is the model of input JSON used in UpdateShortURLHandler.
+// Omitted (nil) fields are left unchanged, tags are replaced as a whole set.
+type UpdateShortURLRequest struct {
+	Title          *string          `json:"title"`
+	Notes          *string          `json:"notes"`
+	Tags           *[]string        `json:"tags"`
+	Interstitial   *bool            `json:"interstitial"`
+	RedirectStatus *int             `json:"redirect_status"`
+	CacheControl   *string          `json:"cache_control"`
+	ReferrerPolicy *string          `json:"referrer_policy"`
+	RobotsTag      *string          `json:"robots_tag"`
+	Passthrough    *string          `json:"passthrough"`
+	ActiveFrom     *string          `json:"active_from"`     // the empty one removes the limit
+	ActiveUntil    *string          `json:"active_until"`    // the empty one removes the limit
+	UTMTemplateID  *string          `json:"utm_template_id"` // the empty one detaches the template
+	CampaignID     *string          `json:"campaign_id"`     // the empty one removes the short URL from the campaign
+	Targets        *[]TargetingRule `json:"targets"`         // replaced as a whole list, the empty one removes the targeting
+	GeoRules       *[]GeoRule       `json:"geo_rules"`       // replaced as a whole list as well
+	Variants       *[]SplitVariant  `json:"variants"`        // replaced as a whole list, the clicks of the kept names are kept
+}
+
+// ChangesRedirect reports whether the update changes any of the redirect attributes.
+func (u UpdateShortURLRequest) ChangesRedirect() bool {
+	return u.Interstitial != nil || u.RedirectStatus != nil || u.CacheControl != nil || u.ReferrerPolicy != nil ||
+		u.RobotsTag != nil || u.Passthrough != nil || u.Targets != nil || u.GeoRules != nil ||
+		u.Variants != nil || u.ActiveFrom != nil || u.ActiveUntil != nil
+}
+
+// ApplyRedirect changes the redirect attributes according to the update.
+func (u UpdateShortURLRequest) ApplyRedirect(options *RedirectOptions) {
+	if u.Interstitial != nil {
+		options.Interstitial = *u.Interstitial
+	}
+	if u.RedirectStatus != nil {
+		options.RedirectStatus = *u.RedirectStatus
+	}
+	if u.CacheControl != nil {
+		options.CacheControl = *u.CacheControl
+	}
+	if u.ReferrerPolicy != nil {
+		options.ReferrerPolicy = *u.ReferrerPolicy
+	}
+	if u.RobotsTag != nil {
+		options.RobotsTag = *u.RobotsTag
+	}
+	if u.Passthrough != nil {
+		options.Passthrough = *u.Passthrough
+	}
+	if u.Targets != nil {
+		options.Targets = *u.Targets
+	}
+	if u.GeoRules != nil {
+		options.GeoRules = *u.GeoRules
+	}
+	if u.Variants != nil {
+		options.Variants = *u.Variants
+	}
+	if u.ActiveFrom != nil {
+		options.ActiveFrom = *u.ActiveFrom
+	}
+	if u.ActiveUntil != nil {
+		options.ActiveUntil = *u.ActiveUntil
+	}
+}
+
+// ShortURL is the model of the single short URL record with all its attributes, as it is kept in the storage.
+type ShortURL struct {
+	Metadata    *PageMetadata
+	UTM         *UTMParameters // the parameters of the attached UTM template, nil if there is none
+	Moderation  *Moderation    // the state of the short URL disabled by the admin, nil if it isn't disabled
+	ShortURL    string
+	OriginalURL string
+	UserID      string
+	ShortURLOptions
+	ClicksLeft int64 // the redirects left for the click-limited short URL
+	Deleted    bool
+}
+
+// DisplayTitle returns the title to show to the visitors: the user-defined one or the title of the destination page.
+func (s ShortURL) DisplayTitle() string {
+	if s.Title == "" && s.Metadata != nil {
+		return s.Metadata.Title
+	}
+	return s.Title
+}
+
+// MetadataJob is the model of the message that the service sends to the metadata fetching workers.
+type MetadataJob struct {
+	ShortURL    string
+	OriginalURL string
 }
 
 // ShortURLChannelMessage is the model of the message that the deletion handler sends to the channel.
 type ShortURLChannelMessage struct {
-	Ctx      context.Context
+	Actor    Actor // the caller the deletion is recorded in the audit log as made by
 	ShortURL string
 	UserID   string
 }
 
+// Variant returns the split variant of the short URL by its name or nil if there is no such variant.
+func (o RedirectOptions) Variant(name string) *SplitVariant {
+	for i := range o.Variants {
+		if o.Variants[i].Name == name {
+			return &o.Variants[i]
+		}
+	}
+	return nil
+}
+
+// VariantStats is the model of the clicks on the single split variant of the short URL.
+// The variants removed from the short URL are listed with the clicks they got, but without the URL and the weight.
+type VariantStats struct {
+	SplitVariant
+	Clicks int64 `json:"clicks"`
+}
+
+// ShortURLStats is the model of the message that the short URL statistics handler responds with.
+type ShortURLStats struct {
+	ShortURL string         `json:"short_url"`
+	Variants []VariantStats `json:"variants"`
+}
+
+// VariantClick is the model of the click on the split variant of the short URL, passed to the click counting worker.
+type VariantClick struct {
+	ShortURL string
+	Variant  string
+}
+
+// QROptions is the model of the QR code image requested for the short URL.
+type QROptions struct {
+	Format string // one of the qr formats, PNG if empty
+	Level  string // one of the qr error correction levels, medium if empty
+	Size   int    // the width and the height of the image in pixels, the default if zero
+}
+
+// QRCode is the model of the QR code image rendered for the short URL.
+type QRCode struct {
+	ContentType string
+	ETag        string // the quoted hash of the image
+	Image       []byte
+}
+
 // ServiceStats is the model of the message that the statistics handler responds with.
 type ServiceStats struct {
-	Users int `json:"users"` // the amount of users in the service
-	URLs  int `json:"urls"`  // the amount of shortened URLs
+	Users     int `json:"users"`     // the amount of users in the service
+	URLs      int `json:"urls"`      // the amount of shortened URLs
+	Campaigns int `json:"campaigns"` // the amount of campaigns
 }
@@ -0,0 +1,384 @@
+// Package oidc implements the OpenID Connect single sign-on with the authorization code flow and PKCE.
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Limits protecting the service from the misbehaving identity provider.
+const (
+	maxResponseSize    = 1 << 20
+	keysRefetchPeriod  = time.Minute
+	randomValueBytes   = 32
+	discoveryPath      = "/.well-known/openid-configuration"
+	codeChallengeS256  = "S256"
+	defaultHTTPTimeout = 10 * time.Second
+)
+
+// SSO is the global identity provider the users sign in with, the single sign-on is disabled if it isn't set.
+var SSO *Provider
+
+// ErrDiscovery is returned if the discovery document of the identity provider is missing or invalid.
+var ErrDiscovery = errors.New("invalid discovery document")
+
+// ErrTokenExchange is returned if the identity provider refuses to exchange the authorization code.
+var ErrTokenExchange = errors.New("authorization code exchange failed")
+
+// ErrInvalidIDToken is returned if the ID token isn't issued by the provider for this client and login.
+var ErrInvalidIDToken = errors.New("invalid id token")
+
+// Config is the registration of the service as the client of the identity provider.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Discovery is the part of the OpenID Provider metadata the login flow needs.
+type Discovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	CodeChallengeMethods  []string `json:"code_challenge_methods_supported"`
+}
+
+// AuthRequest is the state of the single login kept by the client between the redirect to the provider
+// and the callback.
+type AuthRequest struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+// Claims is the ID token issued by the provider.
+type Claims struct {
+	jwt.RegisteredClaims
+	Nonce           string `json:"nonce"`
+	AuthorizedParty string `json:"azp,omitempty"`
+	Email           string `json:"email,omitempty"`
+	Name            string `json:"name,omitempty"`
+	EmailVerified   bool   `json:"email_verified,omitempty"`
+}
+
+// Provider is the identity provider discovered by its issuer URL.
+type Provider struct {
+	keysFetchedAt time.Time
+	client        *http.Client
+	keys          map[string]any
+	discovery     Discovery
+	config        Config
+	keysLock      sync.Mutex
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	ID      string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// Discover fetches and checks the discovery document of the issuer. The issuer of the document must match
+// the requested one exactly, and the provider must support PKCE with S256 if it lists the methods.
+func Discover(ctx context.Context, client *http.Client, issuer string) (*Discovery, error) {
+	discovery := &Discovery{}
+	if err := getJSON(ctx, client, strings.TrimSuffix(issuer, "/")+discoveryPath, discovery); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDiscovery, err)
+	}
+	switch {
+	case discovery.Issuer != issuer:
+		return nil, fmt.Errorf("%w: issuer %q doesn't match %q", ErrDiscovery, discovery.Issuer, issuer)
+	case discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "":
+		return nil, fmt.Errorf("%w: the endpoints are missing", ErrDiscovery)
+	case len(discovery.CodeChallengeMethods) > 0 && !slices.Contains(discovery.CodeChallengeMethods, codeChallengeS256):
+		return nil, fmt.Errorf("%w: PKCE with S256 isn't supported", ErrDiscovery)
+	}
+	return discovery, nil
+}
+
+// NewProvider discovers the identity provider of the config. The default HTTP client with the timeout is used
+// if the client isn't passed.
+func NewProvider(ctx context.Context, config Config, client *http.Client) (*Provider, error) {
+	if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, errors.New("the issuer, the client ID and the redirect URL are required")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	discovery, err := Discover(ctx, client, config.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(config.Scopes, "openid") {
+		config.Scopes = append([]string{"openid"}, config.Scopes...)
+	}
+	return &Provider{client: client, discovery: *discovery, config: config}, nil
+}
+
+// NewAuthRequest returns the new login with the random state, nonce and PKCE code verifier.
+func NewAuthRequest() (AuthRequest, error) {
+	var request AuthRequest
+	for _, value := range []*string{&request.State, &request.Nonce, &request.CodeVerifier} {
+		buffer := make([]byte, randomValueBytes)
+		if _, err := rand.Read(buffer); err != nil {
+			return AuthRequest{}, err
+		}
+		*value = base64.RawURLEncoding.EncodeToString(buffer)
+	}
+	return request, nil
+}
+
+// CodeChallenge returns the S256 PKCE challenge of the code verifier.
+func CodeChallenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// Issuer returns the issuer URL of the provider.
+func (p *Provider) Issuer() string {
+	return p.discovery.Issuer
+}
+
+// AuthCodeURL returns the URL of the provider the user is redirected to for the login.
+func (p *Provider) AuthCodeURL(request AuthRequest) string {
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {request.State},
+		"nonce":                 {request.Nonce},
+		"code_challenge":        {CodeChallenge(request.CodeVerifier)},
+		"code_challenge_method": {codeChallengeS256},
+	}
+	separator := "?"
+	if strings.Contains(p.discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.discovery.AuthorizationEndpoint + separator + query.Encode()
+}
+
+// Login exchanges the authorization code of the callback for the ID token and returns its verified claims.
+func (p *Provider) Login(ctx context.Context, code string, request AuthRequest) (*Claims, error) {
+	rawIDToken, err := p.Exchange(ctx, code, request.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	return p.VerifyIDToken(ctx, rawIDToken, request.Nonce)
+}
+
+// Exchange redeems the authorization code at the token endpoint and returns the raw ID token.
+// The client authenticates with HTTP Basic if it has the secret, the public client sends the client ID only.
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if p.config.ClientSecret == "" {
+		form.Set("client_id", p.config.ClientID)
+	}
+	request, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, p.discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+	response, err := p.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	var token tokenResponse
+	if err = json.NewDecoder(io.LimitReader(response.Body, maxResponseSize)).Decode(&token); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTokenExchange, err)
+	}
+	if response.StatusCode != http.StatusOK || token.Error != "" {
+		return "", fmt.Errorf("%w: status %d %s %s", ErrTokenExchange, response.StatusCode,
+			token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return "", fmt.Errorf("%w: no id token in the response", ErrTokenExchange)
+	}
+	return token.IDToken, nil
+}
+
+// VerifyIDToken checks the signature of the ID token with the published keys of the provider, its issuer,
+// audience, expiration and the nonce of the login.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (*Claims, error) {
+	claims := &Claims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{
+		jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg(), jwt.SigningMethodEdDSA.Alg()}))
+	_, err := parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		keyID, _ := token.Header["kid"].(string)
+		return p.key(ctx, keyID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+	switch {
+	case claims.Issuer != p.discovery.Issuer:
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+	case !slices.Contains(claims.Audience, p.config.ClientID):
+		return nil, fmt.Errorf("%w: issued for another client", ErrInvalidIDToken)
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID:
+		return nil, fmt.Errorf("%w: issued for another authorized party", ErrInvalidIDToken)
+	case !claims.VerifyExpiresAt(time.Now(), true):
+		return nil, fmt.Errorf("%w: no expiration", ErrInvalidIDToken)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	case subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1:
+		return nil, fmt.Errorf("%w: nonce doesn't match the login", ErrInvalidIDToken)
+	}
+	return claims, nil
+}
+
+// key returns the published key of the provider by its ID. The keys are fetched again for the unknown ID,
+// as the provider might rotate them, but not more often than keysRefetchPeriod.
+func (p *Provider) key(ctx context.Context, keyID string) (any, error) {
+	p.keysLock.Lock()
+	defer p.keysLock.Unlock()
+	if key, ok := p.lookupKey(keyID); ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < keysRefetchPeriod {
+		return nil, fmt.Errorf("unknown signing key %q", keyID)
+	}
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.keys, p.keysFetchedAt = keys, time.Now()
+	if key, ok := p.lookupKey(keyID); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", keyID)
+}
+
+// lookupKey finds the key by its ID, the only key is used for the token without the kid header.
+func (p *Provider) lookupKey(keyID string) (any, bool) {
+	if keyID == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[keyID]
+	return key, ok
+}
+
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]any, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, p.client, p.discovery.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, webKey := range set.Keys {
+		if webKey.Use != "" && webKey.Use != "sig" {
+			continue
+		}
+		key, err := webKey.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[webKey.ID] = key
+	}
+	return keys, nil
+}
+
+// publicKey decodes the RSA, P-256 or Ed25519 public key.
+func (k jsonWebKey) publicKey() (any, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch {
+	case k.KeyType == "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case k.KeyType == "EC" && k.Curve == "P-256":
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("the point isn't on the curve")
+		}
+		return key, nil
+	case k.KeyType == "OKP" && k.Curve == "Ed25519":
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+}
+
+func getJSON(ctx context.Context, client *http.Client, endpoint string, target any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d of %s", response.StatusCode, endpoint)
+	}
+	return json.NewDecoder(io.LimitReader(response.Body, maxResponseSize)).Decode(target)
+}
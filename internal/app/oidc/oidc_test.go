@@ -0,0 +1,141 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/clearthree/url-shortener/internal/app/oidc"
+	"github.com/clearthree/url-shortener/internal/app/oidc/oidctest"
+)
+
+const redirectURL = "http://localhost:8080/api/user/oidc/callback"
+
+// authorize follows the redirect to the provider and returns the code it redirects back with.
+func authorize(t *testing.T, idp *oidctest.Server, provider *oidc.Provider, request oidc.AuthRequest) string {
+	client := idp.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Get(provider.AuthCodeURL(request))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, request.State, location.Query().Get("state"))
+	return location.Query().Get("code")
+}
+
+func TestDiscover(t *testing.T) {
+	idp := oidctest.NewServer("shortener", "secret")
+	defer idp.Close()
+	discovery, err := oidc.Discover(context.Background(), idp.Client(), idp.Issuer())
+	require.NoError(t, err)
+	assert.Equal(t, idp.Issuer()+"/token", discovery.TokenEndpoint)
+
+	_, err = oidc.Discover(context.Background(), idp.Client(), idp.Issuer()+"/tenant")
+	assert.ErrorIs(t, err, oidc.ErrDiscovery)
+
+	impostor := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"issuer": "https://idp.example", "authorization_endpoint": "a",
+			"token_endpoint": "t", "jwks_uri": "k"}`))
+	}))
+	defer impostor.Close()
+	_, err = oidc.Discover(context.Background(), impostor.Client(), impostor.URL)
+	assert.ErrorIs(t, err, oidc.ErrDiscovery, "the issuer must match the requested one")
+}
+
+func TestProvider_Login(t *testing.T) {
+	idp := oidctest.NewServer("shortener", "secret")
+	defer idp.Close()
+	provider, err := oidc.NewProvider(context.Background(), idp.Config(redirectURL), idp.Client())
+	require.NoError(t, err)
+	request, err := oidc.NewAuthRequest()
+	require.NoError(t, err)
+
+	authURL, err := url.Parse(provider.AuthCodeURL(request))
+	require.NoError(t, err)
+	assert.Equal(t, oidc.CodeChallenge(request.CodeVerifier), authURL.Query().Get("code_challenge"))
+	assert.NotContains(t, authURL.RawQuery, request.CodeVerifier, "the verifier never leaves the client")
+
+	code := authorize(t, idp, provider, request)
+	claims, err := provider.Login(context.Background(), code, request)
+	require.NoError(t, err)
+	assert.Equal(t, "oidctest-subject", claims.Subject)
+	assert.Equal(t, "jane@corp.example", claims.Email)
+	assert.True(t, claims.EmailVerified)
+
+	_, err = provider.Login(context.Background(), code, request)
+	assert.ErrorIs(t, err, oidc.ErrTokenExchange, "the code is used once")
+
+	code = authorize(t, idp, provider, request)
+	stolen := request
+	stolen.CodeVerifier = "intercepted-code-without-verifier"
+	_, err = provider.Login(context.Background(), code, stolen)
+	assert.ErrorIs(t, err, oidc.ErrTokenExchange, "PKCE rejects the code without the verifier")
+
+	code = authorize(t, idp, provider, request)
+	replayed := request
+	replayed.Nonce = "other-login"
+	_, err = provider.Login(context.Background(), code, replayed)
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken, "the token of the other login is rejected")
+}
+
+func TestProvider_VerifyIDToken(t *testing.T) {
+	idp := oidctest.NewServer("shortener", "secret")
+	defer idp.Close()
+	provider, err := oidc.NewProvider(context.Background(), idp.Config(redirectURL), idp.Client())
+	require.NoError(t, err)
+	valid := func() oidc.Claims {
+		return oidc.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    idp.Issuer(),
+				Subject:   "subject",
+				Audience:  jwt.ClaimStrings{"shortener"},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+			Nonce: "nonce",
+		}
+	}
+	_, err = provider.VerifyIDToken(context.Background(), idp.SignIDToken(valid()), "nonce")
+	require.NoError(t, err)
+
+	tests := []struct {
+		modify func(claims *oidc.Claims)
+		name   string
+	}{
+		{name: "other issuer", modify: func(claims *oidc.Claims) { claims.Issuer = "https://evil.example" }},
+		{name: "other client", modify: func(claims *oidc.Claims) { claims.Audience = jwt.ClaimStrings{"other"} }},
+		{name: "other authorized party", modify: func(claims *oidc.Claims) {
+			claims.Audience = jwt.ClaimStrings{"shortener", "other"}
+			claims.AuthorizedParty = "other"
+		}},
+		{name: "expired", modify: func(claims *oidc.Claims) {
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		}},
+		{name: "no expiration", modify: func(claims *oidc.Claims) { claims.ExpiresAt = nil }},
+		{name: "no subject", modify: func(claims *oidc.Claims) { claims.Subject = "" }},
+		{name: "other nonce", modify: func(claims *oidc.Claims) { claims.Nonce = "other" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.modify(&claims)
+			_, err := provider.VerifyIDToken(context.Background(), idp.SignIDToken(claims), "nonce")
+			assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+		})
+	}
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, valid())
+	forged.Header["kid"] = oidctest.KeyID
+	forgedString, err := forged.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = provider.VerifyIDToken(context.Background(), forgedString, "nonce")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken, "the symmetric algorithms aren't accepted")
+}
@@ -0,0 +1,193 @@
+// Package oidctest provides the in-process OpenID Connect identity provider for the end-to-end tests
+// of the single sign-on.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/clearthree/url-shortener/internal/app/oidc"
+)
+
+// KeyID is the kid header of the ID tokens signed by the provider.
+const KeyID = "oidctest"
+
+// User is the identity the provider signs in without asking.
+type User struct {
+	Subject       string
+	Email         string
+	Name          string
+	EmailVerified bool
+}
+
+// Server is the identity provider serving the discovery document, the authorization, token and keys endpoints.
+// The authorization endpoint approves the login of the current user at once and redirects back with the code.
+type Server struct {
+	key          *rsa.PrivateKey
+	server       *httptest.Server
+	codes        map[string]authorization
+	ClientID     string
+	ClientSecret string
+	User         User
+	lock         sync.Mutex
+}
+
+type authorization struct {
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	user          User
+}
+
+// NewServer starts the provider registering the client with the ID and secret. Close it when finished.
+func NewServer(clientID string, clientSecret string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	s := &Server{
+		key:          key,
+		codes:        make(map[string]authorization),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		User:         User{Subject: "oidctest-subject", Email: "jane@corp.example", Name: "Jane", EmailVerified: true},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/keys", s.keys)
+	s.server = httptest.NewServer(mux)
+	return s
+}
+
+// Issuer returns the issuer URL of the provider.
+func (s *Server) Issuer() string {
+	return s.server.URL
+}
+
+// Config returns the client config of the provider with the redirect URL.
+func (s *Server) Config(redirectURL string) oidc.Config {
+	return oidc.Config{Issuer: s.Issuer(), ClientID: s.ClientID, ClientSecret: s.ClientSecret,
+		RedirectURL: redirectURL, Scopes: []string{"openid", "email"}}
+}
+
+// Client returns the HTTP client the provider is reachable with.
+func (s *Server) Client() *http.Client {
+	return s.server.Client()
+}
+
+// Close shuts the provider down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SignIDToken signs the claims as the provider does, to forge the broken tokens in the tests.
+func (s *Server) SignIDToken(claims jwt.Claims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = KeyID
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+func (s *Server) discovery(writer http.ResponseWriter, _ *http.Request) {
+	writeJSON(writer, http.StatusOK, oidc.Discovery{
+		Issuer:                s.Issuer(),
+		AuthorizationEndpoint: s.Issuer() + "/authorize",
+		TokenEndpoint:         s.Issuer() + "/token",
+		JWKSURI:               s.Issuer() + "/keys",
+		CodeChallengeMethods:  []string{"S256"},
+	})
+}
+
+func (s *Server) authorize(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || query.Get("client_id") != s.ClientID || query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(writer, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	buffer := make([]byte, 16)
+	if _, err = rand.Read(buffer); err != nil {
+		http.Error(writer, "server_error", http.StatusInternalServerError)
+		return
+	}
+	code := base64.RawURLEncoding.EncodeToString(buffer)
+	s.lock.Lock()
+	s.codes[code] = authorization{user: s.User, redirectURI: redirectURI.String(), nonce: query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge")}
+	s.lock.Unlock()
+	callback := redirectURI.Query()
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callback.Encode()
+	http.Redirect(writer, request, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Server) token(writer http.ResponseWriter, request *http.Request) {
+	clientID, clientSecret, _ := request.BasicAuth()
+	if subtle.ConstantTimeCompare([]byte(clientID), []byte(url.QueryEscape(s.ClientID))) != 1 ||
+		subtle.ConstantTimeCompare([]byte(clientSecret), []byte(url.QueryEscape(s.ClientSecret))) != 1 {
+		writeJSON(writer, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	s.lock.Lock()
+	code := request.PostFormValue("code")
+	auth, ok := s.codes[code]
+	delete(s.codes, code)
+	s.lock.Unlock()
+	if !ok || request.PostFormValue("grant_type") != "authorization_code" ||
+		request.PostFormValue("redirect_uri") != auth.redirectURI ||
+		oidc.CodeChallenge(request.PostFormValue("code_verifier")) != auth.codeChallenge {
+		writeJSON(writer, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	now := time.Now()
+	idToken := s.SignIDToken(oidc.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.Issuer(),
+			Subject:   auth.user.Subject,
+			Audience:  jwt.ClaimStrings{s.ClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Nonce:         auth.nonce,
+		Email:         auth.user.Email,
+		Name:          auth.user.Name,
+		EmailVerified: auth.user.EmailVerified,
+	})
+	writeJSON(writer, http.StatusOK, map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+func (s *Server) keys(writer http.ResponseWriter, _ *http.Request) {
+	public := s.key.PublicKey
+	writeJSON(writer, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": KeyID,
+		"use": "sig",
+		"alg": jwt.SigningMethodRS256.Alg(),
+		"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+	}}})
+}
+
+func writeJSON(writer http.ResponseWriter, status int, body any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(body)
+}
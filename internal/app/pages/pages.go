@@ -0,0 +1,72 @@
+// Package pages renders the HTML pages shown to the visitors of the short URLs instead of the immediate redirect.
+package pages
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+var templates = template.Must(template.ParseFS(templatesFS, "templates/*.html"))
+
+// Link is the model of the short URL data shown on the pages.
+type Link struct {
+	ShortURL    string
+	Destination string
+	Title       string
+}
+
+// PasswordPrompt is the model of the page asking for the password of the protected short URL.
+// The destination is never shown on this page.
+type PasswordPrompt struct {
+	ShortURL string
+	Action   string // the URL the password is submitted to
+	Error    string
+}
+
+// Scheduled is the model of the page shown instead of the short URL that is not active yet.
+// The destination is never shown on this page.
+type Scheduled struct {
+	ActiveFrom time.Time
+	ShortURL   string
+}
+
+// WritePreview responds with the page showing where the short URL leads to, along with the continue button.
+// The continue button leads to the short URL itself if the destination is empty.
+func WritePreview(writer http.ResponseWriter, link Link) error {
+	return write(writer, "preview.html", http.StatusOK, link)
+}
+
+// WriteInterstitial responds with the page warning the visitor that they are leaving to another site.
+func WriteInterstitial(writer http.ResponseWriter, link Link) error {
+	return write(writer, "interstitial.html", http.StatusOK, link)
+}
+
+// WritePasswordPrompt responds with the page asking the visitor for the password of the short URL.
+func WritePasswordPrompt(writer http.ResponseWriter, status int, prompt PasswordPrompt) error {
+	return write(writer, "password.html", status, prompt)
+}
+
+// WriteScheduled responds with the page telling the visitor when the short URL becomes active.
+// The status is 404, since the short URL doesn't resolve yet.
+func WriteScheduled(writer http.ResponseWriter, scheduled Scheduled) error {
+	return write(writer, "scheduled.html", http.StatusNotFound, scheduled)
+}
+
+// write renders the page to the buffer first, so the template error never produces a half-written response.
+func write(writer http.ResponseWriter, name string, status int, data any) error {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, name, data); err != nil {
+		return err
+	}
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.WriteHeader(status)
+	_, err := writer.Write(buffer.Bytes())
+	return err
+}
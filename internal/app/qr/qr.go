@@ -0,0 +1,100 @@
+// Package qr renders the QR codes of the short URLs as PNG or SVG images.
+package qr
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Formats of the rendered QR code image.
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Error correction levels of the QR code, the higher one survives more damage of the printed code,
+// but needs more modules for the same content.
+const (
+	LevelLow      = "L" // 7% of the code can be restored
+	LevelMedium   = "M" // 15% of the code can be restored
+	LevelQuartile = "Q" // 25% of the code can be restored
+	LevelHigh     = "H" // 30% of the code can be restored
+)
+
+// levels maps the error correction levels to the ones of the encoder.
+var levels = map[string]qrcode.RecoveryLevel{
+	LevelLow:      qrcode.Low,
+	LevelMedium:   qrcode.Medium,
+	LevelQuartile: qrcode.High,
+	LevelHigh:     qrcode.Highest,
+}
+
+// contentTypes maps the formats to the Content-Type of the image.
+var contentTypes = map[string]string{
+	FormatPNG: "image/png",
+	FormatSVG: "image/svg+xml",
+}
+
+// IsFormat reports whether the QR code can be rendered in the format.
+func IsFormat(format string) bool {
+	_, ok := contentTypes[format]
+	return ok
+}
+
+// IsLevel reports whether the error correction level is supported.
+func IsLevel(level string) bool {
+	_, ok := levels[level]
+	return ok
+}
+
+// ContentType returns the Content-Type of the image in the format.
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// Render renders the content as the QR code image of the format with the error correction level.
+// The size is the width and the height of the image in pixels, the PNG image is silently enlarged
+// if the size is too small to draw every module in a pixel at least.
+func Render(content string, format string, level string, size int) ([]byte, error) {
+	recoveryLevel, ok := levels[level]
+	if !ok {
+		return nil, fmt.Errorf("unsupported error correction level %q", level)
+	}
+	code, err := qrcode.New(content, recoveryLevel)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatPNG:
+		return code.PNG(size)
+	case FormatSVG:
+		return renderSVG(code.Bitmap(), size), nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// renderSVG draws the bitmap of the QR code as the SVG image scaled to the size. Every module is a unit square
+// of the view box, the adjacent dark modules of the row are drawn as the single rectangle to keep the image small.
+func renderSVG(bitmap [][]bool, size int) []byte {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" `+
+		`shape-rendering="crispEdges">`, size, size, len(bitmap), len(bitmap))
+	buffer.WriteString(`<rect width="100%" height="100%" fill="#fff"/><path fill="#000" d="`)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&buffer, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+	buffer.WriteString(`"/></svg>`)
+	return buffer.Bytes()
+}
@@ -0,0 +1,56 @@
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender_PNG(t *testing.T) {
+	image, err := Render("http://localhost:8080/lelelele", FormatPNG, LevelMedium, 256)
+	require.NoError(t, err)
+	decoded, err := png.Decode(bytes.NewReader(image))
+	require.NoError(t, err)
+	assert.Equal(t, 256, decoded.Bounds().Dx())
+	assert.Equal(t, 256, decoded.Bounds().Dy())
+}
+
+func TestRender_SVG(t *testing.T) {
+	image, err := Render("http://localhost:8080/lelelele", FormatSVG, LevelHigh, 512)
+	require.NoError(t, err)
+	svg := string(image)
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="512" height="512"`))
+	assert.True(t, strings.HasSuffix(svg, "</svg>"))
+	assert.Contains(t, svg, "M")
+}
+
+func TestRender_LevelChangesCode(t *testing.T) {
+	low, err := Render("http://localhost:8080/lelelele", FormatSVG, LevelLow, 256)
+	require.NoError(t, err)
+	high, err := Render("http://localhost:8080/lelelele", FormatSVG, LevelHigh, 256)
+	require.NoError(t, err)
+	assert.NotEqual(t, low, high)
+}
+
+func TestRender_Unsupported(t *testing.T) {
+	_, err := Render("http://localhost:8080/lelelele", "gif", LevelMedium, 256)
+	assert.Error(t, err)
+	_, err = Render("http://localhost:8080/lelelele", FormatPNG, "X", 256)
+	assert.Error(t, err)
+}
+
+func Test_renderSVG(t *testing.T) {
+	bitmap := [][]bool{
+		{true, true, false},
+		{false, true, true},
+		{true, false, true},
+	}
+	want := `<svg xmlns="http://www.w3.org/2000/svg" width="30" height="30" viewBox="0 0 3 3" shape-rendering="crispEdges">` +
+		`<rect width="100%" height="100%" fill="#fff"/><path fill="#000" d="` +
+		`M0 0h2v1h-2zM1 1h2v1h-2zM0 2h1v1h-1zM2 2h1v1h-1z"/></svg>`
+	assert.Equal(t, want, string(renderSVG(bitmap, 30)))
+}
@@ -0,0 +1,153 @@
+package proto
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/clearthree/url-shortener/internal/app/models"
+	"github.com/clearthree/url-shortener/internal/app/service"
+)
+
+// ShortenerAdminGRPCServer Supports the moderation methods of the service, all of them require the admin token.
+type ShortenerAdminGRPCServer struct {
+	UnimplementedURLShortenerAdminServiceServer
+
+	service service.ShortURLServiceInterface
+}
+
+// NewShortenerAdminGRPCServer creates the ShortenerAdminGRPCServer structure and returns a pointer
+// to freshly created struct.
+func NewShortenerAdminGRPCServer(service service.ShortURLServiceInterface) *ShortenerAdminGRPCServer {
+	return &ShortenerAdminGRPCServer{
+		service: service,
+	}
+}
+
+// GetShortURL - RPC handler that returns the short URL of any user along with its moderation state.
+func (s ShortenerAdminGRPCServer) GetShortURL(ctx context.Context, request *AdminGetShortURLRequest) (*AdminShortURL, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if request.ShortUrl == "" {
+		return nil, status.Error(codes.InvalidArgument, "ShortUrl is required")
+	}
+	result, err := s.service.AdminReadShortURL(ctx, request.ShortUrl)
+	if err != nil {
+		return nil, moderationError(err)
+	}
+	return newAdminShortURLResponse(*result), nil
+}
+
+// SearchShortURLs - RPC handler that returns the short URLs of all the users matching the search.
+func (s ShortenerAdminGRPCServer) SearchShortURLs(
+	ctx context.Context, request *SearchShortURLsRequest) (*SearchShortURLsResponse, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	search := models.ShortURLSearch{
+		OriginalURL: request.OriginalUrl,
+		Domain:      request.Domain,
+		UserID:      request.UserId,
+		Limit:       int(request.Limit),
+	}
+	result, err := s.service.SearchShortURLs(ctx, search)
+	if err != nil {
+		return nil, moderationError(err)
+	}
+	response := &SearchShortURLsResponse{}
+	for _, item := range result {
+		response.Urls = append(response.Urls, newAdminShortURLResponse(item))
+	}
+	return response, nil
+}
+
+// DisableShortURL - RPC handler to block the redirects of the short URL of any user.
+func (s ShortenerAdminGRPCServer) DisableShortURL(ctx context.Context, request *DisableShortURLRequest) (*AdminShortURL, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if request.ShortUrl == "" {
+		return nil, status.Error(codes.InvalidArgument, "ShortUrl is required")
+	}
+	result, err := s.service.DisableShortURL(ctx, request.ShortUrl, request.Reason)
+	if err != nil {
+		return nil, moderationError(err)
+	}
+	return newAdminShortURLResponse(*result), nil
+}
+
+// EnableShortURL - RPC handler to let the short URL disabled by the admin redirect again.
+func (s ShortenerAdminGRPCServer) EnableShortURL(ctx context.Context, request *EnableShortURLRequest) (*AdminShortURL, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if request.ShortUrl == "" {
+		return nil, status.Error(codes.InvalidArgument, "ShortUrl is required")
+	}
+	result, err := s.service.EnableShortURL(ctx, request.ShortUrl)
+	if err != nil {
+		return nil, moderationError(err)
+	}
+	return newAdminShortURLResponse(*result), nil
+}
+
+// BanUser - RPC handler to ban the user.
+func (s ShortenerAdminGRPCServer) BanUser(ctx context.Context, request *BanUserRequest) (*UserBan, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	result, err := s.service.BanUser(ctx, request.UserId, request.Reason)
+	if err != nil {
+		return nil, moderationError(err)
+	}
+	return &UserBan{UserId: result.UserID, BannedAt: timestamppb.New(result.BannedAt), Reason: result.Reason}, nil
+}
+
+// UnbanUser - RPC handler to lift the ban of the user.
+func (s ShortenerAdminGRPCServer) UnbanUser(ctx context.Context, request *UnbanUserRequest) (*emptypb.Empty, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if request.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "UserId is required")
+	}
+	if err := s.service.UnbanUser(ctx, request.UserId); err != nil {
+		return nil, moderationError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// moderationError converts the error of the moderation operation to the status.
+func moderationError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrShortURLNotFound), errors.Is(err, service.ErrUserNotBanned):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidModeration):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func newAdminShortURLResponse(shortURL models.AdminShortURL) *AdminShortURL {
+	response := &AdminShortURL{
+		ShortUrl:    shortURL.ShortURL,
+		OriginalUrl: shortURL.OriginalURL,
+		UserId:      shortURL.UserID,
+		OrgId:       shortURL.OrgID,
+		Title:       shortURL.Title,
+		Deleted:     shortURL.Deleted,
+		OwnerBanned: shortURL.OwnerBanned,
+	}
+	if shortURL.Moderation != nil {
+		response.Moderation = &Moderation{
+			DisabledAt: timestamppb.New(shortURL.Moderation.DisabledAt),
+			Reason:     shortURL.Moderation.Reason,
+		}
+	}
+	return response
+}
@@ -0,0 +1,85 @@
+package proto
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/clearthree/url-shortener/internal/app/mocks"
+	"github.com/clearthree/url-shortener/internal/app/models"
+	"github.com/clearthree/url-shortener/internal/app/service"
+)
+
+func TestShortenerAdminGRPCServer_RequireAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s := NewShortenerAdminGRPCServer(mocks.NewMockShortURLServiceInterface(ctrl))
+	userCtx := withPrincipal(context.Background(), principal{userID: "lele"})
+
+	_, err := s.GetShortURL(userCtx, &AdminGetShortURLRequest{ShortUrl: "lelelele"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = s.BanUser(userCtx, &BanUserRequest{UserId: "lele", Reason: "Spam"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = s.SearchShortURLs(context.Background(), &SearchShortURLsRequest{UserId: "lele"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestShortenerAdminGRPCServer_Moderation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	s := NewShortenerAdminGRPCServer(shortURLServiceMock)
+	ctx := adminCtx
+	disabledAt := time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC)
+	disabled := &models.AdminShortURL{
+		Moderation:  &models.Moderation{DisabledAt: disabledAt, Reason: "Phishing"},
+		ShortURL:    "http://localhost:8080/lelelele",
+		OriginalURL: "https://login.example.com",
+		UserID:      "lele",
+		OwnerBanned: true,
+	}
+
+	shortURLServiceMock.EXPECT().DisableShortURL(ctx, "lelelele", "Phishing").Return(disabled, nil)
+	got, err := s.DisableShortURL(ctx, &DisableShortURLRequest{ShortUrl: "lelelele", Reason: "Phishing"})
+	require.NoError(t, err)
+	assert.Equal(t, "Phishing", got.Moderation.Reason)
+	assert.True(t, disabledAt.Equal(got.Moderation.DisabledAt.AsTime()))
+	assert.True(t, got.OwnerBanned)
+
+	shortURLServiceMock.EXPECT().EnableShortURL(ctx, "missing").Return(nil, service.ErrShortURLNotFound)
+	_, err = s.EnableShortURL(ctx, &EnableShortURLRequest{ShortUrl: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = s.GetShortURL(ctx, &AdminGetShortURLRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	shortURLServiceMock.EXPECT().
+		SearchShortURLs(ctx, models.ShortURLSearch{Domain: "example.com", Limit: 5}).
+		Return([]models.AdminShortURL{*disabled}, nil)
+	found, err := s.SearchShortURLs(ctx, &SearchShortURLsRequest{Domain: "example.com", Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, found.Urls, 1)
+	assert.Equal(t, "https://login.example.com", found.Urls[0].OriginalUrl)
+
+	shortURLServiceMock.EXPECT().BanUser(ctx, "lele", "").Return(nil, service.ErrInvalidModeration)
+	_, err = s.BanUser(ctx, &BanUserRequest{UserId: "lele"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	shortURLServiceMock.EXPECT().UnbanUser(ctx, "lele").Return(service.ErrUserNotBanned)
+	_, err = s.UnbanUser(ctx, &UnbanUserRequest{UserId: "lele"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestShortenerGRPCServer_CreateShortURLBanned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	shortURLServiceMock.EXPECT().Create(adminCtx, "https://ya.ru", "lele", gomock.Any()).Return("", service.ErrUserBanned)
+	_, err := NewShortenerGRPCServer(shortURLServiceMock).CreateShortURL(adminCtx,
+		&ShortenRequest{Url: "https://ya.ru", UserId: "lele"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
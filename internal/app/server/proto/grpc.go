@@ -4,15 +4,23 @@ package proto
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"strings"
 
 	"github.com/clearthree/url-shortener/internal/app/utils"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/clearthree/url-shortener/internal/app/config"
+	"github.com/clearthree/url-shortener/internal/app/middlewares"
 	"github.com/clearthree/url-shortener/internal/app/models"
 	"github.com/clearthree/url-shortener/internal/app/service"
 )
@@ -36,15 +44,35 @@ func (s ShortenerGRPCServer) CreateShortURL(ctx context.Context, request *Shorte
 	if request.Url == "" {
 		return nil, status.Error(codes.InvalidArgument, "URL is required")
 	}
-	if request.UserId == "" {
-		return nil, status.Error(codes.InvalidArgument, "UserId is required")
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
 	}
 	if !utils.IsURL(request.Url) {
 		return nil, status.Error(codes.InvalidArgument, "URL is invalid")
 	}
 	var response ShortenResponse
-	result, err := s.service.Create(ctx, request.Url, request.UserId)
+	options := models.ShortURLOptions{
+		Title:           request.Title,
+		Notes:           request.Notes,
+		Tags:            request.Tags,
+		Password:        request.Password,
+		UTMTemplateID:   request.UtmTemplateId,
+		RedirectOptions: newRedirectOptions(request.Redirect),
+		MaxClicks:       int64(request.MaxClicks),
+		Domain:          request.Domain,
+		CampaignID:      request.CampaignId,
+		OrgID:           request.OrgId,
+	}
+	result, err := s.service.Create(ctx, request.Url, userID, options)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidOptions) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, service.ErrDomainNotAllowed) || errors.Is(err, service.ErrOrgForbidden) ||
+			errors.Is(err, service.ErrUserBanned) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	response.Result = result
@@ -56,18 +84,38 @@ func (s ShortenerGRPCServer) BatchCreateShortURL(ctx context.Context, request *B
 	if len(request.Items) == 0 {
 		return nil, status.Error(codes.InvalidArgument, "Items required")
 	}
-	if request.UserId == "" {
-		return nil, status.Error(codes.InvalidArgument, "UserId required")
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
 	}
 	requestData := make([]models.ShortenBatchItemRequest, len(request.Items))
 	for i, item := range request.Items {
 		requestData[i] = models.ShortenBatchItemRequest{
 			CorrelationID: item.CorrelationId,
 			OriginalURL:   item.OriginalUrl,
+			ShortURLOptions: models.ShortURLOptions{
+				Title:           item.Title,
+				Notes:           item.Notes,
+				Tags:            item.Tags,
+				Password:        item.Password,
+				UTMTemplateID:   item.UtmTemplateId,
+				RedirectOptions: newRedirectOptions(item.Redirect),
+				MaxClicks:       int64(item.MaxClicks),
+				Domain:          item.Domain,
+				CampaignID:      item.CampaignId,
+				OrgID:           item.OrgId,
+			},
 		}
 	}
-	result, err := s.service.BatchCreate(ctx, requestData, request.UserId)
+	result, err := s.service.BatchCreate(ctx, requestData, userID)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidOptions) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, service.ErrDomainNotAllowed) || errors.Is(err, service.ErrOrgForbidden) ||
+			errors.Is(err, service.ErrUserBanned) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	var response BatchShortenResponse
@@ -80,11 +128,16 @@ func (s ShortenerGRPCServer) BatchCreateShortURL(ctx context.Context, request *B
 
 // GetUserURLs - RPC handler that returns all the URLs created by user.
 func (s ShortenerGRPCServer) GetUserURLs(ctx context.Context, request *GetUserURLsRequest) (*GetUserURLsResponse, error) {
-	if request.UserId == "" {
-		return nil, status.Error(codes.InvalidArgument, "UserID is required")
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
 	}
-	result, err := s.service.ReadByUserID(ctx, request.UserId)
+	result, err := s.service.ReadByUserID(
+		ctx, userID, models.ShortURLFilter{Tag: request.Tag, CampaignID: request.CampaignId})
 	if err != nil {
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	if len(result) == 0 {
@@ -92,16 +145,538 @@ func (s ShortenerGRPCServer) GetUserURLs(ctx context.Context, request *GetUserUR
 	}
 	var response GetUserURLsResponse
 	for _, item := range result {
-		response.Urls = append(response.Urls, &GetUserURLsResponse_URL{
-			ShortUrl: item.ShortURL, OriginalUrl: item.OriginalURL})
+		response.Urls = append(response.Urls, newURLResponse(item))
+	}
+	return &response, nil
+}
+
+// GetOrgURLs - RPC handler that returns all the URLs owned by the organization the user is a member of.
+func (s ShortenerGRPCServer) GetOrgURLs(ctx context.Context, request *GetOrgURLsRequest) (*GetUserURLsResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if request.OrgId == "" {
+		return nil, status.Error(codes.InvalidArgument, "OrgId is required")
+	}
+	result, err := s.service.ReadByOrgID(ctx, request.OrgId, userID, models.ShortURLFilter{Tag: request.Tag})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOrganizationNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, service.ErrOrgForbidden):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	var response GetUserURLsResponse
+	for _, item := range result {
+		response.Urls = append(response.Urls, newURLResponse(item))
+	}
+	return &response, nil
+}
+
+// UpdateShortURL - RPC handler that changes the title, notes, tags and redirect attributes of the URL (if it belongs to the current user).
+func (s ShortenerGRPCServer) UpdateShortURL(ctx context.Context, request *UpdateShortURLRequest) (*UpdateShortURLResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if request.ShortUrl == "" {
+		return nil, status.Error(codes.InvalidArgument, "ShortUrl is required")
+	}
+	update := models.UpdateShortURLRequest{Title: request.Title, Notes: request.Notes, UTMTemplateID: request.UtmTemplateId,
+		CampaignID: request.CampaignId}
+	if request.Tags != nil {
+		tags := request.Tags.Values
+		update.Tags = &tags
+	}
+	if request.Redirect != nil {
+		update.Interstitial = request.Redirect.Interstitial
+		update.CacheControl = request.Redirect.CacheControl
+		update.ReferrerPolicy = request.Redirect.ReferrerPolicy
+		update.RobotsTag = request.Redirect.RobotsTag
+		update.Passthrough = request.Redirect.Passthrough
+		update.ActiveFrom = request.Redirect.ActiveFrom
+		update.ActiveUntil = request.Redirect.ActiveUntil
+		if request.Redirect.Targets != nil {
+			targets := newTargetingRules(request.Redirect.Targets.Rules)
+			update.Targets = &targets
+		}
+		if request.Redirect.GeoRules != nil {
+			geoRules := newGeoRules(request.Redirect.GeoRules.Rules)
+			update.GeoRules = &geoRules
+		}
+		if request.Redirect.Variants != nil {
+			variants := newSplitVariants(request.Redirect.Variants.Variants)
+			update.Variants = &variants
+		}
+		if request.Redirect.RedirectStatus != nil {
+			redirectStatus := int(*request.Redirect.RedirectStatus)
+			update.RedirectStatus = &redirectStatus
+		}
+	}
+	result, err := s.service.Update(ctx, request.ShortUrl, userID, update)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrShortURLNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrUserBanned):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		case errors.Is(err, service.ErrInvalidOptions):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &UpdateShortURLResponse{Url: newURLResponse(*result)}, nil
+}
+
+func newURLResponse(item models.ShortURLsByUserResponse) *GetUserURLsResponse_URL {
+	response := &GetUserURLsResponse_URL{
+		ShortUrl:          item.ShortURL,
+		OriginalUrl:       item.OriginalURL,
+		Title:             item.Title,
+		Notes:             item.Notes,
+		Tags:              item.Tags,
+		Redirect:          newRedirectOptionsResponse(item.RedirectOptions),
+		PasswordProtected: item.PasswordProtected,
+		UtmTemplateId:     item.UTMTemplateID,
+		MaxClicks:         uint64(item.MaxClicks),
+		Domain:            item.Domain,
+		CampaignId:        item.CampaignID,
+		OrgId:             item.OrgID,
+	}
+	if item.ClicksLeft != nil {
+		clicksLeft := uint64(*item.ClicksLeft)
+		response.ClicksLeft = &clicksLeft
+	}
+	if item.Metadata != nil {
+		response.Metadata = &PageMetadata{
+			Title:      item.Metadata.Title,
+			FaviconUrl: item.Metadata.FaviconURL,
+			OpenGraph:  item.Metadata.OpenGraph,
+		}
+	}
+	return response
+}
+
+// newRedirectOptions converts the redirect attributes of the request, the omitted ones get the default values.
+func newRedirectOptions(request *RedirectOptions) models.RedirectOptions {
+	return models.RedirectOptions{
+		Interstitial:   request.GetInterstitial(),
+		RedirectStatus: int(request.GetRedirectStatus()),
+		CacheControl:   request.GetCacheControl(),
+		ReferrerPolicy: request.GetReferrerPolicy(),
+		RobotsTag:      request.GetRobotsTag(),
+		Passthrough:    request.GetPassthrough(),
+		ActiveFrom:     request.GetActiveFrom(),
+		ActiveUntil:    request.GetActiveUntil(),
+		Targets:        newTargetingRules(request.GetTargets().GetRules()),
+		GeoRules:       newGeoRules(request.GetGeoRules().GetRules()),
+		Variants:       newSplitVariants(request.GetVariants().GetVariants()),
+	}
+}
+
+func newTargetingRules(request []*TargetingRule) []models.TargetingRule {
+	var rules []models.TargetingRule
+	for _, rule := range request {
+		rules = append(rules, models.TargetingRule{Platform: rule.Platform, URL: rule.Url})
+	}
+	return rules
+}
+
+func newGeoRules(request []*GeoRule) []models.GeoRule {
+	var rules []models.GeoRule
+	for _, rule := range request {
+		rules = append(rules, models.GeoRule{Country: rule.Country, URL: rule.Url})
+	}
+	return rules
+}
+
+func newSplitVariants(request []*SplitVariant) []models.SplitVariant {
+	var variants []models.SplitVariant
+	for _, variant := range request {
+		variants = append(variants, models.SplitVariant{Name: variant.Name, URL: variant.Url, Weight: int(variant.Weight)})
+	}
+	return variants
+}
+
+func newRedirectOptionsResponse(options models.RedirectOptions) *RedirectOptions {
+	redirectStatus := uint32(options.RedirectStatus)
+	return &RedirectOptions{
+		Interstitial:   &options.Interstitial,
+		RedirectStatus: &redirectStatus,
+		CacheControl:   &options.CacheControl,
+		ReferrerPolicy: &options.ReferrerPolicy,
+		RobotsTag:      &options.RobotsTag,
+		Passthrough:    &options.Passthrough,
+		ActiveFrom:     &options.ActiveFrom,
+		ActiveUntil:    &options.ActiveUntil,
+		Targets:        newTargetsResponse(options.Targets),
+		GeoRules:       newGeoRulesResponse(options.GeoRules),
+		Variants:       newVariantsResponse(options.Variants),
+	}
+}
+
+func newTargetsResponse(rules []models.TargetingRule) *RedirectOptions_Targets {
+	response := &RedirectOptions_Targets{}
+	for _, rule := range rules {
+		response.Rules = append(response.Rules, &TargetingRule{Platform: rule.Platform, Url: rule.URL})
+	}
+	return response
+}
+
+func newGeoRulesResponse(rules []models.GeoRule) *RedirectOptions_GeoRules {
+	response := &RedirectOptions_GeoRules{}
+	for _, rule := range rules {
+		response.Rules = append(response.Rules, &GeoRule{Country: rule.Country, Url: rule.URL})
+	}
+	return response
+}
+
+func newVariantsResponse(variants []models.SplitVariant) *RedirectOptions_Variants {
+	response := &RedirectOptions_Variants{}
+	for _, variant := range variants {
+		response.Variants = append(response.Variants, newSplitVariantResponse(variant))
+	}
+	return response
+}
+
+func newSplitVariantResponse(variant models.SplitVariant) *SplitVariant {
+	return &SplitVariant{Name: variant.Name, Url: variant.URL, Weight: uint32(variant.Weight)}
+}
+
+// GetShortURLStats - RPC handler that returns the clicks on the split variants of the URL (if it belongs to the current user).
+func (s ShortenerGRPCServer) GetShortURLStats(ctx context.Context, request *GetShortURLStatsRequest) (*GetShortURLStatsResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if request.ShortUrl == "" {
+		return nil, status.Error(codes.InvalidArgument, "ShortUrl is required")
+	}
+	stats, err := s.service.GetShortURLStats(ctx, request.ShortUrl, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrShortURLNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	response := &GetShortURLStatsResponse{ShortUrl: stats.ShortURL}
+	for _, variant := range stats.Variants {
+		response.Variants = append(response.Variants, &GetShortURLStatsResponse_Variant{
+			Variant: newSplitVariantResponse(variant.SplitVariant),
+			Clicks:  uint64(variant.Clicks),
+		})
+	}
+	return response, nil
+}
+
+// GetQRCode - RPC handler to render the QR code image of the short URL.
+func (s ShortenerGRPCServer) GetQRCode(ctx context.Context, request *GetQRCodeRequest) (*GetQRCodeResponse, error) {
+	if request.ShortUrl == "" {
+		return nil, status.Error(codes.InvalidArgument, "ShortUrl is required")
+	}
+	options := models.QROptions{Format: request.Format, Level: request.Level, Size: int(request.Size)}
+	result, err := s.service.GetQRCode(ctx, request.ShortUrl, options)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrShortURLNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, service.ErrInvalidQROptions):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrShortURLDisabled):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &GetQRCodeResponse{Image: result.Image, ContentType: result.ContentType, Etag: result.ETag}, nil
+}
+
+// CreateUTMTemplate - RPC handler to create the UTM template owned by the user.
+func (s ShortenerGRPCServer) CreateUTMTemplate(ctx context.Context, request *CreateUTMTemplateRequest) (*UTMTemplate, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if request.Template == nil {
+		return nil, status.Error(codes.InvalidArgument, "Template is required")
+	}
+	result, err := s.service.CreateUTMTemplate(ctx, userID, newUTMTemplate(request.Template))
+	if err != nil {
+		return nil, utmTemplateError(err)
+	}
+	return newUTMTemplateResponse(*result), nil
+}
+
+// GetUTMTemplates - RPC handler that returns all the UTM templates created by user.
+func (s ShortenerGRPCServer) GetUTMTemplates(ctx context.Context, request *GetUTMTemplatesRequest) (*GetUTMTemplatesResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.service.ReadUTMTemplatesByUserID(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	var response GetUTMTemplatesResponse
+	for _, item := range result {
+		response.Templates = append(response.Templates, newUTMTemplateResponse(item))
+	}
+	return &response, nil
+}
+
+// UpdateUTMTemplate - RPC handler that replaces the name and the parameters of the UTM template (if it belongs to the current user).
+func (s ShortenerGRPCServer) UpdateUTMTemplate(ctx context.Context, request *UpdateUTMTemplateRequest) (*UTMTemplate, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if request.Template.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Template ID is required")
+	}
+	result, err := s.service.UpdateUTMTemplate(ctx, request.Template.Id, userID, newUTMTemplate(request.Template))
+	if err != nil {
+		return nil, utmTemplateError(err)
+	}
+	return newUTMTemplateResponse(*result), nil
+}
+
+// DeleteUTMTemplate - RPC handler that deletes the UTM template (if it belongs to the current user).
+func (s ShortenerGRPCServer) DeleteUTMTemplate(ctx context.Context, request *DeleteUTMTemplateRequest) (*emptypb.Empty, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if request.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "ID is required")
+	}
+	if err := s.service.DeleteUTMTemplate(ctx, request.Id, userID); err != nil {
+		return nil, utmTemplateError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func utmTemplateError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrUTMTemplateNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidUTMTemplate):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func newUTMTemplate(request *UTMTemplate) models.UTMTemplate {
+	return models.UTMTemplate{
+		Name: request.Name,
+		UTMParameters: models.UTMParameters{
+			Source:   request.UtmSource,
+			Medium:   request.UtmMedium,
+			Campaign: request.UtmCampaign,
+			Term:     request.UtmTerm,
+			Content:  request.UtmContent,
+		},
+	}
+}
+
+func newUTMTemplateResponse(template models.UTMTemplate) *UTMTemplate {
+	return &UTMTemplate{
+		Id:          template.ID,
+		Name:        template.Name,
+		UtmSource:   template.Source,
+		UtmMedium:   template.Medium,
+		UtmCampaign: template.Campaign,
+		UtmTerm:     template.Term,
+		UtmContent:  template.Content,
+	}
+}
+
+// CreateCampaign - RPC handler to create the campaign owned by the user.
+func (s ShortenerGRPCServer) CreateCampaign(ctx context.Context, request *CreateCampaignRequest) (*Campaign, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if request.Campaign == nil {
+		return nil, status.Error(codes.InvalidArgument, "Campaign is required")
+	}
+	result, err := s.service.CreateCampaign(ctx, userID, models.Campaign{Name: request.Campaign.Name})
+	if err != nil {
+		return nil, campaignError(err)
+	}
+	return newCampaignResponse(*result), nil
+}
+
+// GetCampaigns - RPC handler that returns all the campaigns created by user.
+func (s ShortenerGRPCServer) GetCampaigns(ctx context.Context, request *GetCampaignsRequest) (*GetCampaignsResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.service.ReadCampaignsByUserID(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	var response GetCampaignsResponse
+	for _, item := range result {
+		response.Campaigns = append(response.Campaigns, newCampaignResponse(item))
 	}
 	return &response, nil
 }
 
+// UpdateCampaign - RPC handler that renames the campaign (if it belongs to the current user).
+func (s ShortenerGRPCServer) UpdateCampaign(ctx context.Context, request *UpdateCampaignRequest) (*Campaign, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if request.Campaign.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Campaign ID is required")
+	}
+	result, err := s.service.UpdateCampaign(
+		ctx, request.Campaign.Id, userID, models.Campaign{Name: request.Campaign.Name})
+	if err != nil {
+		return nil, campaignError(err)
+	}
+	return newCampaignResponse(*result), nil
+}
+
+// DeleteCampaign - RPC handler that deletes the campaign (if it belongs to the current user)
+// and schedules the deletion of its URLs.
+func (s ShortenerGRPCServer) DeleteCampaign(ctx context.Context, request *DeleteCampaignRequest) (*emptypb.Empty, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if request.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "ID is required")
+	}
+	if err := s.service.DeleteCampaign(ctx, request.Id, userID); err != nil {
+		return nil, campaignError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// GetCampaignStats - RPC handler that returns the totals of the campaign (if it belongs to the current user).
+func (s ShortenerGRPCServer) GetCampaignStats(
+	ctx context.Context, request *GetCampaignStatsRequest) (*GetCampaignStatsResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if request.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "ID is required")
+	}
+	result, err := s.service.GetCampaignStats(ctx, request.Id, userID)
+	if err != nil {
+		return nil, campaignError(err)
+	}
+	return &GetCampaignStatsResponse{
+		Campaign: newCampaignResponse(result.Campaign),
+		Urls:     uint32(result.URLs),
+		Clicks:   uint64(result.Clicks),
+	}, nil
+}
+
+func campaignError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrCampaignNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidCampaign):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func newCampaignResponse(campaign models.Campaign) *Campaign {
+	return &Campaign{Id: campaign.ID, Name: campaign.Name}
+}
+
+// CreateAPIKey - RPC handler to create the personal API key of the user, the key is returned only once.
+func (s ShortenerGRPCServer) CreateAPIKey(ctx context.Context, request *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	keyRequest := models.CreateAPIKeyRequest{Name: request.Name}
+	if request.ExpiresAt != nil {
+		expiresAt := request.ExpiresAt.AsTime()
+		keyRequest.ExpiresAt = &expiresAt
+	}
+	result, err := s.service.CreateAPIKey(ctx, userID, keyRequest)
+	if err != nil {
+		return nil, apiKeyError(err)
+	}
+	return &CreateAPIKeyResponse{Key: result.Key, ApiKey: newAPIKeyResponse(result.APIKey)}, nil
+}
+
+// GetAPIKeys - RPC handler that returns all the API keys of the user, newest first.
+func (s ShortenerGRPCServer) GetAPIKeys(ctx context.Context, request *GetAPIKeysRequest) (*GetAPIKeysResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.service.ReadAPIKeysByUserID(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	var response GetAPIKeysResponse
+	for _, item := range result {
+		response.ApiKeys = append(response.ApiKeys, newAPIKeyResponse(item))
+	}
+	return &response, nil
+}
+
+// DeleteAPIKey - RPC handler that revokes the API key (if it belongs to the current user).
+func (s ShortenerGRPCServer) DeleteAPIKey(ctx context.Context, request *DeleteAPIKeyRequest) (*emptypb.Empty, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if request.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "ID is required")
+	}
+	if err = s.service.DeleteAPIKey(ctx, request.Id, userID); err != nil {
+		return nil, apiKeyError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func apiKeyError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidAPIKey):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func newAPIKeyResponse(key models.APIKey) *APIKey {
+	response := &APIKey{
+		Id:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		CreatedAt: timestamppb.New(key.CreatedAt),
+	}
+	if key.LastUsedAt != nil {
+		response.LastUsedAt = timestamppb.New(*key.LastUsedAt)
+	}
+	if key.ExpiresAt != nil {
+		response.ExpiresAt = timestamppb.New(*key.ExpiresAt)
+	}
+	return response
+}
+
 // DeleteBatchURLs - RPC handler that schedules the deletion of the URL batch (if they belong to the current user).
 func (s ShortenerGRPCServer) DeleteBatchURLs(ctx context.Context, request *DeleteBatchRequest) (*emptypb.Empty, error) {
-	if request.UserId == "" {
-		return nil, status.Error(codes.InvalidArgument, "UserID is required")
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, err
 	}
 	if len(request.ShortUrls) == 0 {
 		return nil, status.Error(codes.InvalidArgument, "ShortUrls required")
@@ -109,24 +684,28 @@ func (s ShortenerGRPCServer) DeleteBatchURLs(ctx context.Context, request *Delet
 	requestPrepared := make([]models.ShortURLChannelMessage, len(request.ShortUrls))
 	for i, requestItem := range request.ShortUrls {
 		requestPrepared[i] = models.ShortURLChannelMessage{
-			Ctx:      ctx,
+			Actor:    service.ActorFromContext(ctx),
 			ShortURL: requestItem,
-			UserID:   request.UserId,
+			UserID:   userID,
 		}
 	}
 	s.service.ScheduleDeletionOfBatch(requestPrepared)
 	return &emptypb.Empty{}, nil
 }
 
-// GetServiceStats - RPC handler that returns the statistics of the service.
+// GetServiceStats - RPC handler that returns the statistics of the service, available to the admin only.
 func (s ShortenerGRPCServer) GetServiceStats(ctx context.Context, _ *ServiceStatsRequest) (*ServiceStatsResponse, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
 	result, err := s.service.GetStats(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	response := &ServiceStatsResponse{
-		Users: uint32(result.Users),
-		Urls:  uint32(result.URLs),
+		Users:     uint32(result.Users),
+		Urls:      uint32(result.URLs),
+		Campaigns: uint32(result.Campaigns),
 	}
 	return response, nil
 }
@@ -140,14 +719,101 @@ func (s ShortenerGRPCServer) Ping(ctx context.Context, _ *emptypb.Empty) (*empty
 	return &emptypb.Empty{}, nil
 }
 
-// AuthFn is a custom auth-function that checks the header presence.
-func AuthFn(ctx context.Context) (context.Context, error) {
-	token, err := auth.AuthFromMD(ctx, "bearer")
-	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, err.Error())
+// principal is the caller authenticated by the auth-function: either the admin holding the shared token
+// or the user identified by the JWT or the personal API key.
+type principal struct {
+	userID string
+	admin  bool
+}
+
+type principalKey struct{}
+
+// withPrincipal returns the copy of the context carrying the authenticated caller.
+func withPrincipal(ctx context.Context, caller principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, caller)
+}
+
+// principalFromContext returns the caller saved to the context by the auth-function.
+func principalFromContext(ctx context.Context) (principal, bool) {
+	caller, ok := ctx.Value(principalKey{}).(principal)
+	return caller, ok
+}
+
+// NewAuthFn returns a custom auth-function that derives the caller from the bearer token: the shared GRPCToken
+// authenticates the admin, the JWT issued by middlewares.GenerateJWTString or the personal API key authenticate
+// the user they belong to. The caller is saved to the context.
+func NewAuthFn(keys service.ShortURLServiceInterface) auth.AuthFunc {
+	return func(ctx context.Context) (context.Context, error) {
+		token, err := auth.AuthFromMD(ctx, "bearer")
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if config.Settings.GRPCToken != "" &&
+			subtle.ConstantTimeCompare([]byte(token), []byte(config.Settings.GRPCToken)) == 1 {
+			return withPrincipal(ctx, principal{admin: true}), nil
+		}
+		if strings.HasPrefix(token, service.APIKeyPrefix) {
+			userID, resolveErr := keys.ResolveAPIKey(ctx, token)
+			if resolveErr != nil {
+				if errors.Is(resolveErr, service.ErrAPIKeyRejected) {
+					return nil, status.Error(codes.Unauthenticated, "invalid auth token")
+				}
+				return nil, status.Error(codes.Internal, resolveErr.Error())
+			}
+			return withPrincipal(ctx, principal{userID: userID}), nil
+		}
+		userID, err := middlewares.GetUserID(ctx, token)
+		if err != nil || userID == "" {
+			return nil, status.Error(codes.Unauthenticated, "invalid auth token")
+		}
+		return withPrincipal(ctx, principal{userID: userID}), nil
+	}
+}
+
+// ActorInterceptor saves the caller to the context as the actor of the mutations recorded in the audit log,
+// see service.WithActor. Must follow the auth interceptor to know the caller, the admin is recorded without the user.
+func ActorInterceptor(
+	ctx context.Context, request any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	actor := models.Actor{Transport: models.TransportGRPC}
+	if caller, ok := principalFromContext(ctx); ok {
+		actor.UserID = caller.userID
+	}
+	if remote, ok := peer.FromContext(ctx); ok && remote.Addr != nil {
+		actor.IP = remote.Addr.String()
+		if host, _, err := net.SplitHostPort(actor.IP); err == nil {
+			actor.IP = host
+		}
+	}
+	return handler(service.WithActor(ctx, actor), request)
+}
+
+// requestUserID returns the user the RPC is made for. The user_id passed in the request is honoured only
+// for the admin, the other callers act on their own behalf and may pass either nothing or their own ID.
+func requestUserID(ctx context.Context, userID string) (string, error) {
+	caller, ok := principalFromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "the caller is not authenticated")
+	}
+	if !caller.admin {
+		if userID != "" && userID != caller.userID {
+			return "", status.Error(codes.PermissionDenied, "user_id doesn't match the authenticated user")
+		}
+		return caller.userID, nil
+	}
+	if userID == "" {
+		return "", status.Error(codes.InvalidArgument, "UserID is required")
+	}
+	return userID, nil
+}
+
+// requireAdmin allows the RPC only to the admin holding the shared token.
+func requireAdmin(ctx context.Context) error {
+	caller, ok := principalFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "the caller is not authenticated")
 	}
-	if token != config.Settings.GRPCToken {
-		return nil, status.Error(codes.Unauthenticated, "invalid auth token")
+	if !caller.admin {
+		return status.Error(codes.PermissionDenied, "the admin token is required")
 	}
-	return ctx, nil
+	return nil
 }
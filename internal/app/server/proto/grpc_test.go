@@ -3,13 +3,24 @@ package proto
 import (
 	"context"
 	"errors"
+	"net"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/clearthree/url-shortener/internal/app/config"
+	"github.com/clearthree/url-shortener/internal/app/middlewares"
 	"github.com/clearthree/url-shortener/internal/app/mocks"
 	"github.com/clearthree/url-shortener/internal/app/models"
 	"github.com/clearthree/url-shortener/internal/app/service"
@@ -18,6 +29,9 @@ import (
 
 var ServiceForTest = service.NewService(storage.MemoryRepo{}, make(chan struct{}))
 
+// adminCtx is the context of the call authorized with the shared token, so user_id is honoured.
+var adminCtx = withPrincipal(context.Background(), principal{admin: true})
+
 func TestNewShortenerGRPCServer(t *testing.T) {
 	type args struct {
 		service service.ShortURLServiceInterface
@@ -60,7 +74,7 @@ func TestShortenerGRPCServer_BatchCreateShortURL(t *testing.T) {
 		{
 			name: "BatchCreateShortURL empty URLs",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 				request: &BatchShortenRequest{
 					Items: make([]*BatchShortenRequest_Item, 0),
 				},
@@ -70,7 +84,7 @@ func TestShortenerGRPCServer_BatchCreateShortURL(t *testing.T) {
 		{
 			name: "BatchCreateShortURL empty UserID",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 				request: &BatchShortenRequest{
 					Items: []*BatchShortenRequest_Item{
 						{
@@ -89,7 +103,7 @@ func TestShortenerGRPCServer_BatchCreateShortURL(t *testing.T) {
 		{
 			name: "BatchCreateShortURL success",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 				request: &BatchShortenRequest{
 					Items: []*BatchShortenRequest_Item{
 						{
@@ -123,7 +137,7 @@ func TestShortenerGRPCServer_BatchCreateShortURL(t *testing.T) {
 			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
 			if !tt.wantErr {
 				shortURLServiceMock.EXPECT().
-					BatchCreate(context.Background(), requestData, tt.args.request.UserId).
+					BatchCreate(adminCtx, requestData, tt.args.request.UserId).
 					Return(tt.mockValue, nil)
 			}
 			s := NewShortenerGRPCServer(shortURLServiceMock)
@@ -153,7 +167,7 @@ func TestShortenerGRPCServer_CreateShortURL(t *testing.T) {
 		{
 			name: "CreateShortURL empty URL",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 				request: &ShortenRequest{
 					UserId: "lele",
 				},
@@ -163,7 +177,7 @@ func TestShortenerGRPCServer_CreateShortURL(t *testing.T) {
 		{
 			name: "CreateShortURL empty UserID",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 				request: &ShortenRequest{
 					Url: "http://ya.ru",
 				},
@@ -173,7 +187,7 @@ func TestShortenerGRPCServer_CreateShortURL(t *testing.T) {
 		{
 			name: "CreateShortURL success",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 				request: &ShortenRequest{
 					Url:    "http://ya.ru",
 					UserId: "lele",
@@ -191,7 +205,7 @@ func TestShortenerGRPCServer_CreateShortURL(t *testing.T) {
 			s := NewShortenerGRPCServer(shortURLServiceMock)
 			if !tt.wantErr {
 				shortURLServiceMock.EXPECT().
-					Create(context.Background(), tt.args.request.Url, tt.args.request.UserId).
+					Create(adminCtx, tt.args.request.Url, tt.args.request.UserId, gomock.Any()).
 					Return(tt.mockValue, nil)
 			}
 			got, err := s.CreateShortURL(tt.args.ctx, tt.args.request)
@@ -219,7 +233,7 @@ func TestShortenerGRPCServer_DeleteBatchURLs(t *testing.T) {
 		{
 			name: "DeleteBatchURLs empty URLs",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 				request: &DeleteBatchRequest{
 					UserId: "lele",
 				},
@@ -229,7 +243,7 @@ func TestShortenerGRPCServer_DeleteBatchURLs(t *testing.T) {
 		{
 			name: "DeleteBatchURLs empty UserId",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 				request: &DeleteBatchRequest{
 					ShortUrls: []string{"http://ya.ru", "http://ya2.ru"},
 				},
@@ -239,7 +253,7 @@ func TestShortenerGRPCServer_DeleteBatchURLs(t *testing.T) {
 		{
 			name: "DeleteBatchURLs success",
 			args: args{
-				ctx: context.Background(),
+				ctx: service.WithActor(adminCtx, models.Actor{IP: "192.0.2.10", Transport: models.TransportGRPC}),
 				request: &DeleteBatchRequest{
 					ShortUrls: []string{"http://ya.ru", "http://ya2.ru"},
 					UserId:    "lele",
@@ -258,7 +272,7 @@ func TestShortenerGRPCServer_DeleteBatchURLs(t *testing.T) {
 				requestPrepared := make([]models.ShortURLChannelMessage, len(tt.args.request.ShortUrls))
 				for i, requestItem := range tt.args.request.ShortUrls {
 					requestPrepared[i] = models.ShortURLChannelMessage{
-						Ctx:      context.Background(),
+						Actor:    service.ActorFromContext(tt.args.ctx),
 						ShortURL: requestItem,
 						UserID:   tt.args.request.UserId,
 					}
@@ -289,18 +303,19 @@ func TestShortenerGRPCServer_GetServiceStats(t *testing.T) {
 		{
 			name: "GetServiceStats success",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 			},
 			want: &models.ServiceStats{
-				Users: 13,
-				URLs:  37,
+				Users:     13,
+				URLs:      37,
+				Campaigns: 4,
 			},
 			wantErr: false,
 		},
 		{
 			name: "GetServiceStats error",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 			},
 			want:    &models.ServiceStats{},
 			wantErr: true,
@@ -314,10 +329,10 @@ func TestShortenerGRPCServer_GetServiceStats(t *testing.T) {
 			s := NewShortenerGRPCServer(shortURLServiceMock)
 			if !tt.wantErr {
 				shortURLServiceMock.EXPECT().
-					GetStats(context.Background()).Return(tt.want, nil)
+					GetStats(adminCtx).Return(tt.want, nil)
 			} else {
 				shortURLServiceMock.EXPECT().
-					GetStats(context.Background()).Return(&models.ServiceStats{}, errors.New("service error"))
+					GetStats(adminCtx).Return(&models.ServiceStats{}, errors.New("service error"))
 			}
 			got, err := s.GetServiceStats(tt.args.ctx, tt.args.in1)
 			if (err != nil) != tt.wantErr {
@@ -327,6 +342,7 @@ func TestShortenerGRPCServer_GetServiceStats(t *testing.T) {
 			if !tt.wantErr {
 				assert.Equal(t, got.Users, uint32(tt.want.Users), "GetServiceStats() Users got = %v, want %v", got, tt.want)
 				assert.Equal(t, got.Urls, uint32(tt.want.URLs), "GetServiceStats() URLs got = %v, want %v", got, tt.want)
+				assert.Equal(t, got.Campaigns, uint32(tt.want.Campaigns), "GetServiceStats() Campaigns got = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -346,7 +362,7 @@ func TestShortenerGRPCServer_GetUserURLs(t *testing.T) {
 		{
 			name: "GetUserURLs success",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 				request: &GetUserURLsRequest{
 					UserId: "lele",
 				},
@@ -366,7 +382,7 @@ func TestShortenerGRPCServer_GetUserURLs(t *testing.T) {
 		{
 			name: "GetUserURLs error",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 				request: &GetUserURLsRequest{
 					UserId: "lele",
 				},
@@ -383,10 +399,10 @@ func TestShortenerGRPCServer_GetUserURLs(t *testing.T) {
 			s := NewShortenerGRPCServer(shortURLServiceMock)
 			if !tt.wantErr {
 				shortURLServiceMock.EXPECT().
-					ReadByUserID(context.Background(), tt.args.request.UserId).Return(tt.want, nil)
+					ReadByUserID(adminCtx, tt.args.request.UserId, models.ShortURLFilter{}).Return(tt.want, nil)
 			} else {
 				shortURLServiceMock.EXPECT().
-					ReadByUserID(context.Background(), tt.args.request.UserId).Return(nil, errors.New("service error"))
+					ReadByUserID(adminCtx, tt.args.request.UserId, models.ShortURLFilter{}).Return(nil, errors.New("service error"))
 			}
 			got, err := s.GetUserURLs(tt.args.ctx, tt.args.request)
 			if (err != nil) != tt.wantErr {
@@ -414,7 +430,7 @@ func TestShortenerGRPCServer_Ping(t *testing.T) {
 		{
 			name: "Ping success",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 				in1: &emptypb.Empty{},
 			},
 			want:    &emptypb.Empty{},
@@ -423,7 +439,7 @@ func TestShortenerGRPCServer_Ping(t *testing.T) {
 		{
 			name: "Ping failure",
 			args: args{
-				ctx: context.Background(),
+				ctx: adminCtx,
 				in1: &emptypb.Empty{},
 			},
 			want:    &emptypb.Empty{},
@@ -438,10 +454,10 @@ func TestShortenerGRPCServer_Ping(t *testing.T) {
 			s := NewShortenerGRPCServer(shortURLServiceMock)
 			if !tt.wantErr {
 				shortURLServiceMock.EXPECT().
-					Ping(context.Background()).Return(nil)
+					Ping(adminCtx).Return(nil)
 			} else {
 				shortURLServiceMock.EXPECT().
-					Ping(context.Background()).Return(errors.New("service error"))
+					Ping(adminCtx).Return(errors.New("service error"))
 			}
 			got, err := s.Ping(tt.args.ctx, tt.args.in1)
 			if (err != nil) != tt.wantErr {
@@ -454,3 +470,484 @@ func TestShortenerGRPCServer_Ping(t *testing.T) {
 		})
 	}
 }
+
+func TestShortenerGRPCServer_UpdateShortURL(t *testing.T) {
+	title := "Yandex"
+	tests := []struct {
+		mockError   error
+		request     *UpdateShortURLRequest
+		name        string
+		wantCode    codes.Code
+		callService bool
+	}{
+		{
+			name: "UpdateShortURL success",
+			request: &UpdateShortURLRequest{
+				ShortUrl: "lele",
+				UserId:   "lele",
+				Title:    &title,
+				Tags:     &UpdateShortURLRequest_Tags{Values: []string{"search"}},
+			},
+			callService: true,
+			wantCode:    codes.OK,
+		},
+		{
+			name:     "UpdateShortURL without user",
+			request:  &UpdateShortURLRequest{ShortUrl: "lele", Title: &title},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "UpdateShortURL without short url",
+			request:  &UpdateShortURLRequest{UserId: "lele", Title: &title},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:        "UpdateShortURL not found",
+			request:     &UpdateShortURLRequest{ShortUrl: "lele", UserId: "lele", Title: &title},
+			callService: true,
+			mockError:   service.ErrShortURLNotFound,
+			wantCode:    codes.NotFound,
+		},
+		{
+			name:        "UpdateShortURL of another user",
+			request:     &UpdateShortURLRequest{ShortUrl: "lele", UserId: "lele", Title: &title},
+			callService: true,
+			mockError:   service.ErrForbidden,
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:        "UpdateShortURL by banned user",
+			request:     &UpdateShortURLRequest{ShortUrl: "lele", UserId: "lele", Title: &title},
+			callService: true,
+			mockError:   service.ErrUserBanned,
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:        "UpdateShortURL with invalid attributes",
+			request:     &UpdateShortURLRequest{ShortUrl: "lele", UserId: "lele", Title: &title},
+			callService: true,
+			mockError:   service.ErrInvalidOptions,
+			wantCode:    codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			s := NewShortenerGRPCServer(shortURLServiceMock)
+			if tt.callService {
+				var result *models.ShortURLsByUserResponse
+				if tt.mockError == nil {
+					result = &models.ShortURLsByUserResponse{
+						ShortURL:        "http://localhost:8080/lele",
+						OriginalURL:     "http://ya.ru",
+						ShortURLOptions: models.ShortURLOptions{Title: title, Tags: []string{"search"}},
+					}
+				}
+				shortURLServiceMock.EXPECT().
+					Update(adminCtx, tt.request.ShortUrl, tt.request.UserId, gomock.Any()).
+					Return(result, tt.mockError)
+			}
+			got, err := s.UpdateShortURL(adminCtx, tt.request)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, "http://localhost:8080/lele", got.Url.ShortUrl)
+				assert.Equal(t, title, got.Url.Title)
+				assert.Equal(t, []string{"search"}, got.Url.Tags)
+			}
+		})
+	}
+}
+
+func TestShortenerGRPCServer_UpdateShortURLRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	s := NewShortenerGRPCServer(shortURLServiceMock)
+	targets := []models.TargetingRule{{Platform: "ios", URL: "https://apps.apple.com/app/id1"}}
+	geoRules := []models.GeoRule{{Country: "GB", URL: "https://ya.ru/uk"}}
+	shortURLServiceMock.EXPECT().
+		Update(adminCtx, "lele", "lele", models.UpdateShortURLRequest{Targets: &targets, GeoRules: &geoRules}).
+		Return(&models.ShortURLsByUserResponse{ShortURL: "http://localhost:8080/lele", OriginalURL: "http://ya.ru",
+			ShortURLOptions: models.ShortURLOptions{RedirectOptions: models.RedirectOptions{
+				Targets: targets, GeoRules: geoRules}}}, nil)
+	got, err := s.UpdateShortURL(adminCtx, &UpdateShortURLRequest{ShortUrl: "lele", UserId: "lele",
+		Redirect: &RedirectOptions{
+			Targets: &RedirectOptions_Targets{
+				Rules: []*TargetingRule{{Platform: "ios", Url: "https://apps.apple.com/app/id1"}}},
+			GeoRules: &RedirectOptions_GeoRules{
+				Rules: []*GeoRule{{Country: "GB", Url: "https://ya.ru/uk"}}},
+		}})
+	require.NoError(t, err)
+	require.Len(t, got.Url.Redirect.Targets.Rules, 1)
+	assert.Equal(t, "https://apps.apple.com/app/id1", got.Url.Redirect.Targets.Rules[0].Url)
+	require.Len(t, got.Url.Redirect.GeoRules.Rules, 1)
+	assert.Equal(t, "GB", got.Url.Redirect.GeoRules.Rules[0].Country)
+}
+
+func TestShortenerGRPCServer_UTMTemplates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	s := NewShortenerGRPCServer(shortURLServiceMock)
+	ctx := adminCtx
+
+	template := models.UTMTemplate{Name: "Newsletter", UTMParameters: models.UTMParameters{Source: "newsletter"}}
+	created := template
+	created.ID = "template"
+	shortURLServiceMock.EXPECT().CreateUTMTemplate(ctx, "lele", template).Return(&created, nil)
+	got, err := s.CreateUTMTemplate(ctx, &CreateUTMTemplateRequest{
+		UserId: "lele", Template: &UTMTemplate{Name: "Newsletter", UtmSource: "newsletter"}})
+	require.NoError(t, err)
+	assert.Equal(t, "template", got.Id)
+	assert.Equal(t, "newsletter", got.UtmSource)
+
+	_, err = s.CreateUTMTemplate(ctx, &CreateUTMTemplateRequest{UserId: "lele"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = s.UpdateUTMTemplate(ctx, &UpdateUTMTemplateRequest{UserId: "lele", Template: &UTMTemplate{Name: "Ads"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "the ID of the template is required")
+
+	shortURLServiceMock.EXPECT().DeleteUTMTemplate(ctx, "template", "lele").Return(service.ErrUTMTemplateNotFound)
+	_, err = s.DeleteUTMTemplate(ctx, &DeleteUTMTemplateRequest{UserId: "lele", Id: "template"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestShortenerGRPCServer_Campaigns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	s := NewShortenerGRPCServer(shortURLServiceMock)
+	ctx := adminCtx
+
+	created := models.Campaign{ID: "campaign", Name: "Black Friday"}
+	shortURLServiceMock.EXPECT().CreateCampaign(ctx, "lele", models.Campaign{Name: "Black Friday"}).Return(&created, nil)
+	got, err := s.CreateCampaign(ctx, &CreateCampaignRequest{UserId: "lele", Campaign: &Campaign{Name: "Black Friday"}})
+	require.NoError(t, err)
+	assert.Equal(t, "campaign", got.Id)
+
+	_, err = s.UpdateCampaign(ctx, &UpdateCampaignRequest{UserId: "lele", Campaign: &Campaign{Name: "Sale"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "the ID of the campaign is required")
+
+	shortURLServiceMock.EXPECT().GetCampaignStats(ctx, "campaign", "lele").
+		Return(&models.CampaignStats{Campaign: created, URLs: 3, Clicks: 42}, nil)
+	stats, err := s.GetCampaignStats(ctx, &GetCampaignStatsRequest{UserId: "lele", Id: "campaign"})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), stats.Urls)
+	assert.Equal(t, uint64(42), stats.Clicks)
+
+	shortURLServiceMock.EXPECT().DeleteCampaign(ctx, "campaign", "lele").Return(service.ErrCampaignNotFound)
+	_, err = s.DeleteCampaign(ctx, &DeleteCampaignRequest{UserId: "lele", Id: "campaign"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	shortURLServiceMock.EXPECT().ReadByUserID(ctx, "lele", models.ShortURLFilter{CampaignID: "campaign"}).
+		Return([]models.ShortURLsByUserResponse{{ShortURL: "http://localhost:8080/lelelele",
+			ShortURLOptions: models.ShortURLOptions{CampaignID: "campaign"}}}, nil)
+	urls, err := s.GetUserURLs(ctx, &GetUserURLsRequest{UserId: "lele", CampaignId: "campaign"})
+	require.NoError(t, err)
+	require.Len(t, urls.Urls, 1)
+	assert.Equal(t, "campaign", urls.Urls[0].CampaignId)
+}
+
+func TestShortenerGRPCServer_Organizations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	s := NewShortenerGRPCServer(shortURLServiceMock)
+	ctx := adminCtx
+
+	shortURLServiceMock.EXPECT().Create(ctx, "http://ya.ru", "lele", models.ShortURLOptions{OrgID: "org"}).
+		Return("", service.ErrOrgForbidden)
+	_, err := s.CreateShortURL(ctx, &ShortenRequest{Url: "http://ya.ru", UserId: "lele", OrgId: "org"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "the viewers don't create the links")
+
+	_, err = s.GetOrgURLs(ctx, &GetOrgURLsRequest{UserId: "lele"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "the ID of the organization is required")
+
+	shortURLServiceMock.EXPECT().ReadByOrgID(ctx, "missing", "lele", models.ShortURLFilter{}).
+		Return(nil, service.ErrOrganizationNotFound)
+	_, err = s.GetOrgURLs(ctx, &GetOrgURLsRequest{UserId: "lele", OrgId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	shortURLServiceMock.EXPECT().ReadByOrgID(ctx, "org", "lele", models.ShortURLFilter{Tag: "search"}).
+		Return([]models.ShortURLsByUserResponse{{ShortURL: "http://localhost:8080/lelelele",
+			ShortURLOptions: models.ShortURLOptions{OrgID: "org"}}}, nil)
+	urls, err := s.GetOrgURLs(ctx, &GetOrgURLsRequest{UserId: "lele", OrgId: "org", Tag: "search"})
+	require.NoError(t, err)
+	require.Len(t, urls.Urls, 1)
+	assert.Equal(t, "org", urls.Urls[0].OrgId)
+}
+
+func TestShortenerGRPCServer_GetShortURLStats(t *testing.T) {
+	tests := []struct {
+		mockError   error
+		request     *GetShortURLStatsRequest
+		name        string
+		wantCode    codes.Code
+		callService bool
+	}{
+		{
+			name:        "GetShortURLStats success",
+			request:     &GetShortURLStatsRequest{ShortUrl: "lele", UserId: "lele"},
+			callService: true,
+			wantCode:    codes.OK,
+		},
+		{
+			name:     "GetShortURLStats without user",
+			request:  &GetShortURLStatsRequest{ShortUrl: "lele"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:        "GetShortURLStats of another user",
+			request:     &GetShortURLStatsRequest{ShortUrl: "lele", UserId: "lele"},
+			callService: true,
+			mockError:   service.ErrForbidden,
+			wantCode:    codes.PermissionDenied,
+		},
+		{
+			name:        "GetShortURLStats not found",
+			request:     &GetShortURLStatsRequest{ShortUrl: "lele", UserId: "lele"},
+			callService: true,
+			mockError:   service.ErrShortURLNotFound,
+			wantCode:    codes.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			s := NewShortenerGRPCServer(shortURLServiceMock)
+			if tt.callService {
+				var result *models.ShortURLStats
+				if tt.mockError == nil {
+					result = &models.ShortURLStats{ShortURL: "http://localhost:8080/lele", Variants: []models.VariantStats{
+						{SplitVariant: models.SplitVariant{Name: "a", URL: "https://ya.ru/a", Weight: 2}, Clicks: 7},
+					}}
+				}
+				shortURLServiceMock.EXPECT().GetShortURLStats(adminCtx, "lele", "lele").Return(result, tt.mockError)
+			}
+			got, err := s.GetShortURLStats(adminCtx, tt.request)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				require.Len(t, got.Variants, 1)
+				assert.Equal(t, "a", got.Variants[0].Variant.Name)
+				assert.Equal(t, uint32(2), got.Variants[0].Variant.Weight)
+				assert.Equal(t, uint64(7), got.Variants[0].Clicks)
+			}
+		})
+	}
+}
+
+func Test_newURLResponseClickLimited(t *testing.T) {
+	clicksLeft := int64(2)
+	response := newURLResponse(models.ShortURLsByUserResponse{
+		ShortURL:        "http://localhost:8080/lele",
+		ShortURLOptions: models.ShortURLOptions{MaxClicks: 5},
+		ClicksLeft:      &clicksLeft,
+	})
+	assert.Equal(t, uint64(5), response.GetMaxClicks())
+	require.NotNil(t, response.ClicksLeft)
+	assert.Equal(t, uint64(2), response.GetClicksLeft())
+
+	response = newURLResponse(models.ShortURLsByUserResponse{ShortURL: "http://localhost:8080/lelele"})
+	assert.Nil(t, response.ClicksLeft)
+}
+
+func TestShortenerGRPCServer_GetQRCode(t *testing.T) {
+	tests := []struct {
+		mockError   error
+		request     *GetQRCodeRequest
+		name        string
+		wantCode    codes.Code
+		callService bool
+	}{
+		{
+			name:        "GetQRCode success",
+			request:     &GetQRCodeRequest{ShortUrl: "lele", Format: "svg", Size: 512, Level: "Q"},
+			callService: true,
+			wantCode:    codes.OK,
+		},
+		{
+			name:     "GetQRCode without short URL",
+			request:  &GetQRCodeRequest{},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:        "GetQRCode with invalid options",
+			request:     &GetQRCodeRequest{ShortUrl: "lele", Format: "svg", Size: 512, Level: "Q"},
+			callService: true,
+			mockError:   service.ErrInvalidQROptions,
+			wantCode:    codes.InvalidArgument,
+		},
+		{
+			name:        "GetQRCode not found",
+			request:     &GetQRCodeRequest{ShortUrl: "lele", Format: "svg", Size: 512, Level: "Q"},
+			callService: true,
+			mockError:   service.ErrShortURLNotFound,
+			wantCode:    codes.NotFound,
+		},
+		{
+			name:        "GetQRCode of disabled short URL",
+			request:     &GetQRCodeRequest{ShortUrl: "lele", Format: "svg", Size: 512, Level: "Q"},
+			callService: true,
+			mockError:   service.ErrShortURLDisabled,
+			wantCode:    codes.FailedPrecondition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+			s := NewShortenerGRPCServer(shortURLServiceMock)
+			code := &models.QRCode{ContentType: "image/svg+xml", ETag: `"0123456789abcdef"`, Image: []byte("<svg></svg>")}
+			if tt.callService {
+				var result *models.QRCode
+				if tt.mockError == nil {
+					result = code
+				}
+				shortURLServiceMock.EXPECT().
+					GetQRCode(adminCtx, "lele", models.QROptions{Format: "svg", Level: "Q", Size: 512}).
+					Return(result, tt.mockError)
+			}
+			got, err := s.GetQRCode(adminCtx, tt.request)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, code.Image, got.Image)
+				assert.Equal(t, code.ContentType, got.ContentType)
+				assert.Equal(t, code.ETag, got.Etag)
+			}
+		})
+	}
+}
+
+func TestShortenerGRPCServer_CreateShortURLOnDomain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	s := NewShortenerGRPCServer(shortURLServiceMock)
+	request := &ShortenRequest{Url: "http://ya.ru", UserId: "lele", Domain: "go.example.com"}
+	shortURLServiceMock.EXPECT().
+		Create(adminCtx, "http://ya.ru", "lele", models.ShortURLOptions{Domain: "go.example.com"}).
+		Return("http://go.example.com/LELELELE", nil)
+	got, err := s.CreateShortURL(adminCtx, request)
+	require.NoError(t, err)
+	assert.Equal(t, "http://go.example.com/LELELELE", got.Result)
+
+	shortURLServiceMock.EXPECT().
+		Create(adminCtx, "http://ya.ru", "lele", models.ShortURLOptions{Domain: "go.example.com"}).
+		Return("", service.ErrDomainNotAllowed)
+	_, err = s.CreateShortURL(adminCtx, request)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	response := newURLResponse(models.ShortURLsByUserResponse{
+		ShortURL:        "http://go.example.com/LELELELE",
+		ShortURLOptions: models.ShortURLOptions{Domain: "go.example.com"},
+	})
+	assert.Equal(t, "go.example.com", response.Domain)
+}
+
+func TestNewAuthFn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	authFn := NewAuthFn(shortURLServiceMock)
+	oldToken := config.Settings.GRPCToken
+	config.Settings.GRPCToken = "shared"
+	defer func() { config.Settings.GRPCToken = oldToken }()
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	}
+
+	_, err := authFn(context.Background())
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "the token is required")
+
+	ctx, err := authFn(withToken("shared"))
+	require.NoError(t, err)
+	userID, err := requestUserID(ctx, "")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "the holder of the shared token passes user_id")
+	userID, err = requestUserID(ctx, "AnyUserID")
+	require.NoError(t, err)
+	assert.Equal(t, "AnyUserID", userID)
+
+	shortURLServiceMock.EXPECT().ResolveAPIKey(gomock.Any(), "usk_revoked").Return("", service.ErrAPIKeyRejected)
+	_, err = authFn(withToken("usk_revoked"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	shortURLServiceMock.EXPECT().ResolveAPIKey(gomock.Any(), "usk_valid").Return("APIKeyOwner", nil)
+	ctx, err = authFn(withToken("usk_valid"))
+	require.NoError(t, err)
+	userID, err = requestUserID(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "APIKeyOwner", userID, "the owner of the key is used")
+	_, err = requestUserID(ctx, "AnotherUserID")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "the key can't act for another user")
+
+	oldSecret, oldExpire := config.Settings.SecretKey, config.Settings.JWTExpireHours
+	config.Settings.SecretKey, config.Settings.JWTExpireHours = "secret", 1
+	defer func() { config.Settings.SecretKey, config.Settings.JWTExpireHours = oldSecret, oldExpire }()
+	jwtString, _, err := middlewares.GenerateJWTString("JWTOwner")
+	require.NoError(t, err)
+	ctx, err = authFn(withToken(jwtString))
+	require.NoError(t, err)
+	userID, err = requestUserID(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "JWTOwner", userID, "the owner of the JWT is used")
+	_, err = NewShortenerGRPCServer(shortURLServiceMock).GetServiceStats(ctx, &ServiceStatsRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "the stats are available to the admin only")
+
+	_, err = authFn(withToken(jwtString + "x"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "the JWT with the wrong signature is rejected")
+
+	config.Settings.GRPCToken = ""
+	_, err = authFn(withToken("shared"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "the empty shared token authenticates no one")
+
+	_, err = requestUserID(context.Background(), "AnyUserID")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "the unauthenticated call can't pass user_id")
+}
+
+func TestShortenerGRPCServer_APIKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	shortURLServiceMock := mocks.NewMockShortURLServiceInterface(ctrl)
+	s := NewShortenerGRPCServer(shortURLServiceMock)
+	ctx := adminCtx
+	createdAt := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(24 * time.Hour)
+	key := models.APIKey{ID: "key", Name: "CI", Prefix: "usk_secr", CreatedAt: createdAt, ExpiresAt: &expiresAt}
+
+	shortURLServiceMock.EXPECT().
+		CreateAPIKey(ctx, "lele", models.CreateAPIKeyRequest{Name: "CI", ExpiresAt: &expiresAt}).
+		Return(&models.CreatedAPIKey{Key: "usk_secret", APIKey: key}, nil)
+	created, err := s.CreateAPIKey(ctx, &CreateAPIKeyRequest{UserId: "lele", Name: "CI",
+		ExpiresAt: timestamppb.New(expiresAt)})
+	require.NoError(t, err)
+	assert.Equal(t, "usk_secret", created.Key)
+	assert.Equal(t, "usk_secr", created.ApiKey.Prefix)
+	assert.Nil(t, created.ApiKey.LastUsedAt)
+	assert.True(t, expiresAt.Equal(created.ApiKey.ExpiresAt.AsTime()))
+
+	shortURLServiceMock.EXPECT().ReadAPIKeysByUserID(ctx, "lele").Return([]models.APIKey{key}, nil)
+	listed, err := s.GetAPIKeys(ctx, &GetAPIKeysRequest{UserId: "lele"})
+	require.NoError(t, err)
+	assert.Len(t, listed.ApiKeys, 1)
+
+	shortURLServiceMock.EXPECT().DeleteAPIKey(ctx, "key", "lele").Return(service.ErrAPIKeyNotFound)
+	_, err = s.DeleteAPIKey(ctx, &DeleteAPIKeyRequest{UserId: "lele", Id: "key"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestActorInterceptor(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{userID: "lele"})
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.10"), Port: 54321}})
+	var actor models.Actor
+	_, err := ActorInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+		actor = service.ActorFromContext(ctx)
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.Actor{UserID: "lele", IP: "192.0.2.10", Transport: models.TransportGRPC}, actor)
+}
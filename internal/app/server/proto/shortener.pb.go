@@ -14,6 +14,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
@@ -23,29 +24,47 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-// Message for creating a short URL
-type ShortenRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
-	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+// Per-link attributes controlling what happens when the short URL is followed.
+// Omitted fields get the default values on creation and are left unchanged on update
+type RedirectOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Show the warning page instead of the immediate redirect
+	Interstitial *bool `protobuf:"varint,1,opt,name=interstitial,proto3,oneof" json:"interstitial,omitempty"`
+	// HTTP status of the redirect: 301, 302, 307 or 308, the server default if zero
+	RedirectStatus *uint32 `protobuf:"varint,2,opt,name=redirect_status,json=redirectStatus,proto3,oneof" json:"redirect_status,omitempty"`
+	// Values of Cache-Control, Referrer-Policy and X-Robots-Tag headers, the server defaults if empty
+	CacheControl   *string `protobuf:"bytes,3,opt,name=cache_control,json=cacheControl,proto3,oneof" json:"cache_control,omitempty"`
+	ReferrerPolicy *string `protobuf:"bytes,4,opt,name=referrer_policy,json=referrerPolicy,proto3,oneof" json:"referrer_policy,omitempty"`
+	RobotsTag      *string `protobuf:"bytes,5,opt,name=robots_tag,json=robotsTag,proto3,oneof" json:"robots_tag,omitempty"`
+	// What part of the request is forwarded to the destination: "none", "query" or "path"
+	Passthrough *string `protobuf:"bytes,6,opt,name=passthrough,proto3,oneof" json:"passthrough,omitempty"`
+	// Destinations chosen by the platform of the visitor, replaced as a whole list on update
+	Targets *RedirectOptions_Targets `protobuf:"bytes,7,opt,name=targets,proto3" json:"targets,omitempty"`
+	// Destinations chosen by the country of the visitor, checked before the targets, replaced as a whole list on update
+	GeoRules *RedirectOptions_GeoRules `protobuf:"bytes,8,opt,name=geo_rules,json=geoRules,proto3" json:"geo_rules,omitempty"`
+	// Destinations the traffic is split across by weight instead of the original URL, replaced as a whole list on update
+	Variants *RedirectOptions_Variants `protobuf:"bytes,9,opt,name=variants,proto3" json:"variants,omitempty"`
+	// RFC 3339 times the short URL is active from and until, unlimited if empty
+	ActiveFrom    *string `protobuf:"bytes,10,opt,name=active_from,json=activeFrom,proto3,oneof" json:"active_from,omitempty"`
+	ActiveUntil   *string `protobuf:"bytes,11,opt,name=active_until,json=activeUntil,proto3,oneof" json:"active_until,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ShortenRequest) Reset() {
-	*x = ShortenRequest{}
+func (x *RedirectOptions) Reset() {
+	*x = RedirectOptions{}
 	mi := &file_proto_shortener_proto_msgTypes[0]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ShortenRequest) String() string {
+func (x *RedirectOptions) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ShortenRequest) ProtoMessage() {}
+func (*RedirectOptions) ProtoMessage() {}
 
-func (x *ShortenRequest) ProtoReflect() protoreflect.Message {
+func (x *RedirectOptions) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_shortener_proto_msgTypes[0]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -57,46 +76,113 @@ func (x *ShortenRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ShortenRequest.ProtoReflect.Descriptor instead.
-func (*ShortenRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use RedirectOptions.ProtoReflect.Descriptor instead.
+func (*RedirectOptions) Descriptor() ([]byte, []int) {
 	return file_proto_shortener_proto_rawDescGZIP(), []int{0}
 }
 
-func (x *ShortenRequest) GetUrl() string {
-	if x != nil {
-		return x.Url
+func (x *RedirectOptions) GetInterstitial() bool {
+	if x != nil && x.Interstitial != nil {
+		return *x.Interstitial
+	}
+	return false
+}
+
+func (x *RedirectOptions) GetRedirectStatus() uint32 {
+	if x != nil && x.RedirectStatus != nil {
+		return *x.RedirectStatus
+	}
+	return 0
+}
+
+func (x *RedirectOptions) GetCacheControl() string {
+	if x != nil && x.CacheControl != nil {
+		return *x.CacheControl
 	}
 	return ""
 }
 
-func (x *ShortenRequest) GetUserId() string {
+func (x *RedirectOptions) GetReferrerPolicy() string {
+	if x != nil && x.ReferrerPolicy != nil {
+		return *x.ReferrerPolicy
+	}
+	return ""
+}
+
+func (x *RedirectOptions) GetRobotsTag() string {
+	if x != nil && x.RobotsTag != nil {
+		return *x.RobotsTag
+	}
+	return ""
+}
+
+func (x *RedirectOptions) GetPassthrough() string {
+	if x != nil && x.Passthrough != nil {
+		return *x.Passthrough
+	}
+	return ""
+}
+
+func (x *RedirectOptions) GetTargets() *RedirectOptions_Targets {
 	if x != nil {
-		return x.UserId
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *RedirectOptions) GetGeoRules() *RedirectOptions_GeoRules {
+	if x != nil {
+		return x.GeoRules
+	}
+	return nil
+}
+
+func (x *RedirectOptions) GetVariants() *RedirectOptions_Variants {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *RedirectOptions) GetActiveFrom() string {
+	if x != nil && x.ActiveFrom != nil {
+		return *x.ActiveFrom
 	}
 	return ""
 }
 
-type ShortenResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+func (x *RedirectOptions) GetActiveUntil() string {
+	if x != nil && x.ActiveUntil != nil {
+		return *x.ActiveUntil
+	}
+	return ""
+}
+
+// Destination for the visitors of the platform: "ios", "android", "windows", "macos", "linux", "mobile" or "desktop".
+// The rules are checked in order, the first matching one wins, the original URL is the fallback
+type TargetingRule struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Platform string                 `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	// Web page or deep link of the app
+	Url           string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ShortenResponse) Reset() {
-	*x = ShortenResponse{}
+func (x *TargetingRule) Reset() {
+	*x = TargetingRule{}
 	mi := &file_proto_shortener_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ShortenResponse) String() string {
+func (x *TargetingRule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ShortenResponse) ProtoMessage() {}
+func (*TargetingRule) ProtoMessage() {}
 
-func (x *ShortenResponse) ProtoReflect() protoreflect.Message {
+func (x *TargetingRule) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_shortener_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -108,41 +194,49 @@ func (x *ShortenResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ShortenResponse.ProtoReflect.Descriptor instead.
-func (*ShortenResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use TargetingRule.ProtoReflect.Descriptor instead.
+func (*TargetingRule) Descriptor() ([]byte, []int) {
 	return file_proto_shortener_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *ShortenResponse) GetResult() string {
+func (x *TargetingRule) GetPlatform() string {
 	if x != nil {
-		return x.Result
+		return x.Platform
 	}
 	return ""
 }
 
-// Message for batch URL creation
-type BatchShortenRequest struct {
-	state         protoimpl.MessageState      `protogen:"open.v1"`
-	Items         []*BatchShortenRequest_Item `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
-	UserId        string                      `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+func (x *TargetingRule) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+// Destination for the visitors from the country, resolved from the address by the GeoIP database of the server
+type GeoRule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ISO 3166-1 alpha-2 code
+	Country       string `protobuf:"bytes,1,opt,name=country,proto3" json:"country,omitempty"`
+	Url           string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BatchShortenRequest) Reset() {
-	*x = BatchShortenRequest{}
+func (x *GeoRule) Reset() {
+	*x = GeoRule{}
 	mi := &file_proto_shortener_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BatchShortenRequest) String() string {
+func (x *GeoRule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchShortenRequest) ProtoMessage() {}
+func (*GeoRule) ProtoMessage() {}
 
-func (x *BatchShortenRequest) ProtoReflect() protoreflect.Message {
+func (x *GeoRule) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_shortener_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -154,46 +248,49 @@ func (x *BatchShortenRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchShortenRequest.ProtoReflect.Descriptor instead.
-func (*BatchShortenRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GeoRule.ProtoReflect.Descriptor instead.
+func (*GeoRule) Descriptor() ([]byte, []int) {
 	return file_proto_shortener_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *BatchShortenRequest) GetItems() []*BatchShortenRequest_Item {
+func (x *GeoRule) GetCountry() string {
 	if x != nil {
-		return x.Items
+		return x.Country
 	}
-	return nil
+	return ""
 }
 
-func (x *BatchShortenRequest) GetUserId() string {
+func (x *GeoRule) GetUrl() string {
 	if x != nil {
-		return x.UserId
+		return x.Url
 	}
 	return ""
 }
 
-type BatchShortenResponse struct {
-	state         protoimpl.MessageState       `protogen:"open.v1"`
-	Items         []*BatchShortenResponse_Item `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+// Destination the visitors are assigned to at random by weight and kept on by the cookie
+type SplitVariant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	Weight        uint32 `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BatchShortenResponse) Reset() {
-	*x = BatchShortenResponse{}
+func (x *SplitVariant) Reset() {
+	*x = SplitVariant{}
 	mi := &file_proto_shortener_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BatchShortenResponse) String() string {
+func (x *SplitVariant) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchShortenResponse) ProtoMessage() {}
+func (*SplitVariant) ProtoMessage() {}
 
-func (x *BatchShortenResponse) ProtoReflect() protoreflect.Message {
+func (x *SplitVariant) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_shortener_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -205,40 +302,65 @@ func (x *BatchShortenResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchShortenResponse.ProtoReflect.Descriptor instead.
-func (*BatchShortenResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use SplitVariant.ProtoReflect.Descriptor instead.
+func (*SplitVariant) Descriptor() ([]byte, []int) {
 	return file_proto_shortener_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *BatchShortenResponse) GetItems() []*BatchShortenResponse_Item {
+func (x *SplitVariant) GetName() string {
 	if x != nil {
-		return x.Items
+		return x.Name
 	}
-	return nil
+	return ""
 }
 
-// Message for retrieving all user URLs
-type GetUserURLsRequest struct {
+func (x *SplitVariant) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *SplitVariant) GetWeight() uint32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// Message for creating a short URL
+type ShortenRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Redirect      *RedirectOptions       `protobuf:"bytes,6,opt,name=redirect,proto3" json:"redirect,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Notes         string                 `protobuf:"bytes,4,opt,name=notes,proto3" json:"notes,omitempty"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Password      string                 `protobuf:"bytes,7,opt,name=password,proto3" json:"password,omitempty"`
+	UtmTemplateId string                 `protobuf:"bytes,8,opt,name=utm_template_id,json=utmTemplateId,proto3" json:"utm_template_id,omitempty"`
+	Domain        string                 `protobuf:"bytes,10,opt,name=domain,proto3" json:"domain,omitempty"`
+	CampaignId    string                 `protobuf:"bytes,11,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,12,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	MaxClicks     uint64 `protobuf:"varint,9,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetUserURLsRequest) Reset() {
-	*x = GetUserURLsRequest{}
+func (x *ShortenRequest) Reset() {
+	*x = ShortenRequest{}
 	mi := &file_proto_shortener_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetUserURLsRequest) String() string {
+func (x *ShortenRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetUserURLsRequest) ProtoMessage() {}
+func (*ShortenRequest) ProtoMessage() {}
 
-func (x *GetUserURLsRequest) ProtoReflect() protoreflect.Message {
+func (x *ShortenRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_shortener_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -250,39 +372,116 @@ func (x *GetUserURLsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetUserURLsRequest.ProtoReflect.Descriptor instead.
-func (*GetUserURLsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ShortenRequest.ProtoReflect.Descriptor instead.
+func (*ShortenRequest) Descriptor() ([]byte, []int) {
 	return file_proto_shortener_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *GetUserURLsRequest) GetUserId() string {
+func (x *ShortenRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetUserId() string {
 	if x != nil {
 		return x.UserId
 	}
 	return ""
 }
 
-type GetUserURLsResponse struct {
-	state         protoimpl.MessageState     `protogen:"open.v1"`
-	Urls          []*GetUserURLsResponse_URL `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+func (x *ShortenRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ShortenRequest) GetRedirect() *RedirectOptions {
+	if x != nil {
+		return x.Redirect
+	}
+	return nil
+}
+
+func (x *ShortenRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetUtmTemplateId() string {
+	if x != nil {
+		return x.UtmTemplateId
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetMaxClicks() uint64 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *ShortenRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+type ShortenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetUserURLsResponse) Reset() {
-	*x = GetUserURLsResponse{}
+func (x *ShortenResponse) Reset() {
+	*x = ShortenResponse{}
 	mi := &file_proto_shortener_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetUserURLsResponse) String() string {
+func (x *ShortenResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetUserURLsResponse) ProtoMessage() {}
+func (*ShortenResponse) ProtoMessage() {}
 
-func (x *GetUserURLsResponse) ProtoReflect() protoreflect.Message {
+func (x *ShortenResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_shortener_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -294,42 +493,2571 @@ func (x *GetUserURLsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetUserURLsResponse.ProtoReflect.Descriptor instead.
-func (*GetUserURLsResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ShortenResponse.ProtoReflect.Descriptor instead.
+func (*ShortenResponse) Descriptor() ([]byte, []int) {
 	return file_proto_shortener_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *GetUserURLsResponse) GetUrls() []*GetUserURLsResponse_URL {
+func (x *ShortenResponse) GetResult() string {
 	if x != nil {
-		return x.Urls
+		return x.Result
 	}
-	return nil
+	return ""
 }
 
-// Message for deleting URLs
-type DeleteBatchRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ShortUrls     []string               `protobuf:"bytes,1,rep,name=short_urls,json=shortUrls,proto3" json:"short_urls,omitempty"`
-	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+// Message for batch URL creation
+type BatchShortenRequest struct {
+	state         protoimpl.MessageState      `protogen:"open.v1"`
+	Items         []*BatchShortenRequest_Item `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	UserId        string                      `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteBatchRequest) Reset() {
-	*x = DeleteBatchRequest{}
+func (x *BatchShortenRequest) Reset() {
+	*x = BatchShortenRequest{}
 	mi := &file_proto_shortener_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteBatchRequest) String() string {
+func (x *BatchShortenRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteBatchRequest) ProtoMessage() {}
+func (*BatchShortenRequest) ProtoMessage() {}
+
+func (x *BatchShortenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchShortenRequest.ProtoReflect.Descriptor instead.
+func (*BatchShortenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchShortenRequest) GetItems() []*BatchShortenRequest_Item {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *BatchShortenRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type BatchShortenResponse struct {
+	state         protoimpl.MessageState       `protogen:"open.v1"`
+	Items         []*BatchShortenResponse_Item `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchShortenResponse) Reset() {
+	*x = BatchShortenResponse{}
+	mi := &file_proto_shortener_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchShortenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchShortenResponse) ProtoMessage() {}
+
+func (x *BatchShortenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchShortenResponse.ProtoReflect.Descriptor instead.
+func (*BatchShortenResponse) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchShortenResponse) GetItems() []*BatchShortenResponse_Item {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// Message for retrieving all user URLs
+type GetUserURLsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Return only the URLs marked with this tag if not empty
+	Tag string `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`
+	// Return only the URLs of this campaign if not empty
+	CampaignId    string `protobuf:"bytes,3,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserURLsRequest) Reset() {
+	*x = GetUserURLsRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserURLsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserURLsRequest) ProtoMessage() {}
+
+func (x *GetUserURLsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserURLsRequest.ProtoReflect.Descriptor instead.
+func (*GetUserURLsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetUserURLsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetUserURLsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *GetUserURLsRequest) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+// Message for retrieving all the URLs of the organization the user is a member of
+type GetOrgURLsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	OrgId  string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	UserId string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Return only the URLs marked with this tag if not empty
+	Tag           string `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrgURLsRequest) Reset() {
+	*x = GetOrgURLsRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrgURLsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrgURLsRequest) ProtoMessage() {}
+
+func (x *GetOrgURLsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrgURLsRequest.ProtoReflect.Descriptor instead.
+func (*GetOrgURLsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetOrgURLsRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *GetOrgURLsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetOrgURLsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+// Metadata of the destination page fetched after the short URL is created
+type PageMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	FaviconUrl    string                 `protobuf:"bytes,2,opt,name=favicon_url,json=faviconUrl,proto3" json:"favicon_url,omitempty"`
+	OpenGraph     map[string]string      `protobuf:"bytes,3,rep,name=open_graph,json=openGraph,proto3" json:"open_graph,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PageMetadata) Reset() {
+	*x = PageMetadata{}
+	mi := &file_proto_shortener_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PageMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PageMetadata) ProtoMessage() {}
+
+func (x *PageMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PageMetadata.ProtoReflect.Descriptor instead.
+func (*PageMetadata) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PageMetadata) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *PageMetadata) GetFaviconUrl() string {
+	if x != nil {
+		return x.FaviconUrl
+	}
+	return ""
+}
+
+func (x *PageMetadata) GetOpenGraph() map[string]string {
+	if x != nil {
+		return x.OpenGraph
+	}
+	return nil
+}
+
+type GetUserURLsResponse struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Urls          []*GetUserURLsResponse_URL `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserURLsResponse) Reset() {
+	*x = GetUserURLsResponse{}
+	mi := &file_proto_shortener_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserURLsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserURLsResponse) ProtoMessage() {}
+
+func (x *GetUserURLsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserURLsResponse.ProtoReflect.Descriptor instead.
+func (*GetUserURLsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetUserURLsResponse) GetUrls() []*GetUserURLsResponse_URL {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+// Message for updating the attributes of a short URL, omitted fields are left unchanged
+type UpdateShortURLRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the short URL, prefixed with the host and the slash on a branded domain
+	ShortUrl string                      `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	UserId   string                      `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Title    *string                     `protobuf:"bytes,3,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	Notes    *string                     `protobuf:"bytes,4,opt,name=notes,proto3,oneof" json:"notes,omitempty"`
+	Tags     *UpdateShortURLRequest_Tags `protobuf:"bytes,5,opt,name=tags,proto3" json:"tags,omitempty"`
+	Redirect *RedirectOptions            `protobuf:"bytes,6,opt,name=redirect,proto3" json:"redirect,omitempty"`
+	// The empty one detaches the UTM template
+	UtmTemplateId *string `protobuf:"bytes,7,opt,name=utm_template_id,json=utmTemplateId,proto3,oneof" json:"utm_template_id,omitempty"`
+	// The empty one removes the short URL from the campaign
+	CampaignId    *string `protobuf:"bytes,8,opt,name=campaign_id,json=campaignId,proto3,oneof" json:"campaign_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateShortURLRequest) Reset() {
+	*x = UpdateShortURLRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateShortURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateShortURLRequest) ProtoMessage() {}
+
+func (x *UpdateShortURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateShortURLRequest.ProtoReflect.Descriptor instead.
+func (*UpdateShortURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateShortURLRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *UpdateShortURLRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateShortURLRequest) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *UpdateShortURLRequest) GetNotes() string {
+	if x != nil && x.Notes != nil {
+		return *x.Notes
+	}
+	return ""
+}
+
+func (x *UpdateShortURLRequest) GetTags() *UpdateShortURLRequest_Tags {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *UpdateShortURLRequest) GetRedirect() *RedirectOptions {
+	if x != nil {
+		return x.Redirect
+	}
+	return nil
+}
+
+func (x *UpdateShortURLRequest) GetUtmTemplateId() string {
+	if x != nil && x.UtmTemplateId != nil {
+		return *x.UtmTemplateId
+	}
+	return ""
+}
+
+func (x *UpdateShortURLRequest) GetCampaignId() string {
+	if x != nil && x.CampaignId != nil {
+		return *x.CampaignId
+	}
+	return ""
+}
+
+type UpdateShortURLResponse struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Url           *GetUserURLsResponse_URL `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateShortURLResponse) Reset() {
+	*x = UpdateShortURLResponse{}
+	mi := &file_proto_shortener_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateShortURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateShortURLResponse) ProtoMessage() {}
+
+func (x *UpdateShortURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateShortURLResponse.ProtoReflect.Descriptor instead.
+func (*UpdateShortURLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UpdateShortURLResponse) GetUrl() *GetUserURLsResponse_URL {
+	if x != nil {
+		return x.Url
+	}
+	return nil
+}
+
+// Reusable set of UTM parameters owned by the user
+type UTMTemplate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	UtmSource     string                 `protobuf:"bytes,3,opt,name=utm_source,json=utmSource,proto3" json:"utm_source,omitempty"`
+	UtmMedium     string                 `protobuf:"bytes,4,opt,name=utm_medium,json=utmMedium,proto3" json:"utm_medium,omitempty"`
+	UtmCampaign   string                 `protobuf:"bytes,5,opt,name=utm_campaign,json=utmCampaign,proto3" json:"utm_campaign,omitempty"`
+	UtmTerm       string                 `protobuf:"bytes,6,opt,name=utm_term,json=utmTerm,proto3" json:"utm_term,omitempty"`
+	UtmContent    string                 `protobuf:"bytes,7,opt,name=utm_content,json=utmContent,proto3" json:"utm_content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UTMTemplate) Reset() {
+	*x = UTMTemplate{}
+	mi := &file_proto_shortener_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UTMTemplate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UTMTemplate) ProtoMessage() {}
+
+func (x *UTMTemplate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UTMTemplate.ProtoReflect.Descriptor instead.
+func (*UTMTemplate) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UTMTemplate) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UTMTemplate) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UTMTemplate) GetUtmSource() string {
+	if x != nil {
+		return x.UtmSource
+	}
+	return ""
+}
+
+func (x *UTMTemplate) GetUtmMedium() string {
+	if x != nil {
+		return x.UtmMedium
+	}
+	return ""
+}
+
+func (x *UTMTemplate) GetUtmCampaign() string {
+	if x != nil {
+		return x.UtmCampaign
+	}
+	return ""
+}
+
+func (x *UTMTemplate) GetUtmTerm() string {
+	if x != nil {
+		return x.UtmTerm
+	}
+	return ""
+}
+
+func (x *UTMTemplate) GetUtmContent() string {
+	if x != nil {
+		return x.UtmContent
+	}
+	return ""
+}
+
+// Message for creating a UTM template, the ID is generated
+type CreateUTMTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Template      *UTMTemplate           `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUTMTemplateRequest) Reset() {
+	*x = CreateUTMTemplateRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUTMTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUTMTemplateRequest) ProtoMessage() {}
+
+func (x *CreateUTMTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUTMTemplateRequest.ProtoReflect.Descriptor instead.
+func (*CreateUTMTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CreateUTMTemplateRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateUTMTemplateRequest) GetTemplate() *UTMTemplate {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+// Message for retrieving all user UTM templates
+type GetUTMTemplatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUTMTemplatesRequest) Reset() {
+	*x = GetUTMTemplatesRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUTMTemplatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUTMTemplatesRequest) ProtoMessage() {}
+
+func (x *GetUTMTemplatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUTMTemplatesRequest.ProtoReflect.Descriptor instead.
+func (*GetUTMTemplatesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetUTMTemplatesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetUTMTemplatesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Templates     []*UTMTemplate         `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUTMTemplatesResponse) Reset() {
+	*x = GetUTMTemplatesResponse{}
+	mi := &file_proto_shortener_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUTMTemplatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUTMTemplatesResponse) ProtoMessage() {}
+
+func (x *GetUTMTemplatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUTMTemplatesResponse.ProtoReflect.Descriptor instead.
+func (*GetUTMTemplatesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetUTMTemplatesResponse) GetTemplates() []*UTMTemplate {
+	if x != nil {
+		return x.Templates
+	}
+	return nil
+}
+
+// Message for replacing the name and the parameters of a UTM template found by its ID
+type UpdateUTMTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Template      *UTMTemplate           `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUTMTemplateRequest) Reset() {
+	*x = UpdateUTMTemplateRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUTMTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUTMTemplateRequest) ProtoMessage() {}
+
+func (x *UpdateUTMTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUTMTemplateRequest.ProtoReflect.Descriptor instead.
+func (*UpdateUTMTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UpdateUTMTemplateRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateUTMTemplateRequest) GetTemplate() *UTMTemplate {
+	if x != nil {
+		return x.Template
+	}
+	return nil
+}
+
+// Message for deleting a UTM template
+type DeleteUTMTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUTMTemplateRequest) Reset() {
+	*x = DeleteUTMTemplateRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUTMTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUTMTemplateRequest) ProtoMessage() {}
+
+func (x *DeleteUTMTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUTMTemplateRequest.ProtoReflect.Descriptor instead.
+func (*DeleteUTMTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeleteUTMTemplateRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DeleteUTMTemplateRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// User-owned group of short URLs
+type Campaign struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Campaign) Reset() {
+	*x = Campaign{}
+	mi := &file_proto_shortener_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Campaign) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Campaign) ProtoMessage() {}
+
+func (x *Campaign) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Campaign.ProtoReflect.Descriptor instead.
+func (*Campaign) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Campaign) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Campaign) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Message for creating a campaign, the ID is generated
+type CreateCampaignRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Campaign      *Campaign              `protobuf:"bytes,2,opt,name=campaign,proto3" json:"campaign,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCampaignRequest) Reset() {
+	*x = CreateCampaignRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCampaignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCampaignRequest) ProtoMessage() {}
+
+func (x *CreateCampaignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCampaignRequest.ProtoReflect.Descriptor instead.
+func (*CreateCampaignRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CreateCampaignRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetCampaign() *Campaign {
+	if x != nil {
+		return x.Campaign
+	}
+	return nil
+}
+
+// Message for retrieving all user campaigns
+type GetCampaignsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCampaignsRequest) Reset() {
+	*x = GetCampaignsRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCampaignsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCampaignsRequest) ProtoMessage() {}
+
+func (x *GetCampaignsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCampaignsRequest.ProtoReflect.Descriptor instead.
+func (*GetCampaignsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetCampaignsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetCampaignsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Campaigns     []*Campaign            `protobuf:"bytes,1,rep,name=campaigns,proto3" json:"campaigns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCampaignsResponse) Reset() {
+	*x = GetCampaignsResponse{}
+	mi := &file_proto_shortener_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCampaignsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCampaignsResponse) ProtoMessage() {}
+
+func (x *GetCampaignsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCampaignsResponse.ProtoReflect.Descriptor instead.
+func (*GetCampaignsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetCampaignsResponse) GetCampaigns() []*Campaign {
+	if x != nil {
+		return x.Campaigns
+	}
+	return nil
+}
+
+// Message for renaming a campaign found by its ID
+type UpdateCampaignRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Campaign      *Campaign              `protobuf:"bytes,2,opt,name=campaign,proto3" json:"campaign,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCampaignRequest) Reset() {
+	*x = UpdateCampaignRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCampaignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCampaignRequest) ProtoMessage() {}
+
+func (x *UpdateCampaignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCampaignRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCampaignRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UpdateCampaignRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateCampaignRequest) GetCampaign() *Campaign {
+	if x != nil {
+		return x.Campaign
+	}
+	return nil
+}
+
+// Message for deleting a campaign together with its short URLs
+type DeleteCampaignRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCampaignRequest) Reset() {
+	*x = DeleteCampaignRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCampaignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCampaignRequest) ProtoMessage() {}
+
+func (x *DeleteCampaignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCampaignRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCampaignRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DeleteCampaignRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DeleteCampaignRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Message for retrieving the totals of a campaign
+type GetCampaignStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCampaignStatsRequest) Reset() {
+	*x = GetCampaignStatsRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCampaignStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCampaignStatsRequest) ProtoMessage() {}
+
+func (x *GetCampaignStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCampaignStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetCampaignStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetCampaignStatsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetCampaignStatsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetCampaignStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Campaign      *Campaign              `protobuf:"bytes,1,opt,name=campaign,proto3" json:"campaign,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	Clicks        uint64 `protobuf:"varint,3,opt,name=clicks,proto3" json:"clicks,omitempty"`
+	Urls          uint32 `protobuf:"varint,2,opt,name=urls,proto3" json:"urls,omitempty"`
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCampaignStatsResponse) Reset() {
+	*x = GetCampaignStatsResponse{}
+	mi := &file_proto_shortener_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCampaignStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCampaignStatsResponse) ProtoMessage() {}
+
+func (x *GetCampaignStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCampaignStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetCampaignStatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetCampaignStatsResponse) GetCampaign() *Campaign {
+	if x != nil {
+		return x.Campaign
+	}
+	return nil
+}
+
+func (x *GetCampaignStatsResponse) GetUrls() uint32 {
+	if x != nil {
+		return x.Urls
+	}
+	return 0
+}
+
+func (x *GetCampaignStatsResponse) GetClicks() uint64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+// Personal API key, the key itself is returned only on creation
+type APIKey struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// The first characters of the key to tell the keys apart
+	Prefix     string                 `protobuf:"bytes,3,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	CreatedAt  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastUsedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	// The key is rejected starting from this moment, never expires if not set
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *APIKey) Reset() {
+	*x = APIKey{}
+	mi := &file_proto_shortener_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *APIKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIKey) ProtoMessage() {}
+
+func (x *APIKey) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIKey.ProtoReflect.Descriptor instead.
+func (*APIKey) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *APIKey) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *APIKey) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *APIKey) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *APIKey) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *APIKey) GetLastUsedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return nil
+}
+
+func (x *APIKey) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+// Message for creating an API key, the key and the ID are generated
+type CreateAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyRequest) Reset() {
+	*x = CreateAPIKeyRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyRequest) ProtoMessage() {}
+
+func (x *CreateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CreateAPIKeyRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type CreateAPIKeyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The key to pass as the bearer token, shown only once
+	Key           string  `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	ApiKey        *APIKey `protobuf:"bytes,2,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAPIKeyResponse) Reset() {
+	*x = CreateAPIKeyResponse{}
+	mi := &file_proto_shortener_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAPIKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAPIKeyResponse) ProtoMessage() {}
+
+func (x *CreateAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CreateAPIKeyResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *CreateAPIKeyResponse) GetApiKey() *APIKey {
+	if x != nil {
+		return x.ApiKey
+	}
+	return nil
+}
+
+// Message for retrieving all user API keys
+type GetAPIKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAPIKeysRequest) Reset() {
+	*x = GetAPIKeysRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAPIKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAPIKeysRequest) ProtoMessage() {}
+
+func (x *GetAPIKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAPIKeysRequest.ProtoReflect.Descriptor instead.
+func (*GetAPIKeysRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetAPIKeysRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetAPIKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApiKeys       []*APIKey              `protobuf:"bytes,1,rep,name=api_keys,json=apiKeys,proto3" json:"api_keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAPIKeysResponse) Reset() {
+	*x = GetAPIKeysResponse{}
+	mi := &file_proto_shortener_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAPIKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAPIKeysResponse) ProtoMessage() {}
+
+func (x *GetAPIKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAPIKeysResponse.ProtoReflect.Descriptor instead.
+func (*GetAPIKeysResponse) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetAPIKeysResponse) GetApiKeys() []*APIKey {
+	if x != nil {
+		return x.ApiKeys
+	}
+	return nil
+}
+
+// Message for revoking an API key
+type DeleteAPIKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAPIKeyRequest) Reset() {
+	*x = DeleteAPIKeyRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAPIKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAPIKeyRequest) ProtoMessage() {}
+
+func (x *DeleteAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*DeleteAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *DeleteAPIKeyRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *DeleteAPIKeyRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Message for retrieving the clicks on the split variants of a short URL
+type GetShortURLStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the short URL, prefixed with the host and the slash on a branded domain
+	ShortUrl      string `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	UserId        string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetShortURLStatsRequest) Reset() {
+	*x = GetShortURLStatsRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetShortURLStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetShortURLStatsRequest) ProtoMessage() {}
+
+func (x *GetShortURLStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetShortURLStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetShortURLStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *GetShortURLStatsRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *GetShortURLStatsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetShortURLStatsResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	// The current variants first, then the removed ones without the URL and the weight
+	Variants      []*GetShortURLStatsResponse_Variant `protobuf:"bytes,2,rep,name=variants,proto3" json:"variants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetShortURLStatsResponse) Reset() {
+	*x = GetShortURLStatsResponse{}
+	mi := &file_proto_shortener_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetShortURLStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetShortURLStatsResponse) ProtoMessage() {}
+
+func (x *GetShortURLStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetShortURLStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetShortURLStatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetShortURLStatsResponse) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *GetShortURLStatsResponse) GetVariants() []*GetShortURLStatsResponse_Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+// Message for rendering the QR code image of a short URL
+type GetQRCodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	Level         string                 `protobuf:"bytes,4,opt,name=level,proto3" json:"level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	Size          uint32 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQRCodeRequest) Reset() {
+	*x = GetQRCodeRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQRCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQRCodeRequest) ProtoMessage() {}
+
+func (x *GetQRCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQRCodeRequest.ProtoReflect.Descriptor instead.
+func (*GetQRCodeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetQRCodeRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *GetQRCodeRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *GetQRCodeRequest) GetSize() uint32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *GetQRCodeRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+type GetQRCodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Image         []byte                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Etag          string                 `protobuf:"bytes,3,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQRCodeResponse) Reset() {
+	*x = GetQRCodeResponse{}
+	mi := &file_proto_shortener_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQRCodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQRCodeResponse) ProtoMessage() {}
+
+func (x *GetQRCodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQRCodeResponse.ProtoReflect.Descriptor instead.
+func (*GetQRCodeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetQRCodeResponse) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *GetQRCodeResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *GetQRCodeResponse) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+// Message for deleting URLs
+type DeleteBatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// IDs of the short URLs, prefixed with the host and the slash on a branded domain
+	ShortUrls     []string `protobuf:"bytes,1,rep,name=short_urls,json=shortUrls,proto3" json:"short_urls,omitempty"`
+	UserId        string   `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBatchRequest) Reset() {
+	*x = DeleteBatchRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBatchRequest) ProtoMessage() {}
+
+func (x *DeleteBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBatchRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *DeleteBatchRequest) GetShortUrls() []string {
+	if x != nil {
+		return x.ShortUrls
+	}
+	return nil
+}
+
+func (x *DeleteBatchRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// Message for retrieving service statistics
+type ServiceStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceStatsRequest) Reset() {
+	*x = ServiceStatsRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceStatsRequest) ProtoMessage() {}
+
+func (x *ServiceStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceStatsRequest.ProtoReflect.Descriptor instead.
+func (*ServiceStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{39}
+}
+
+type ServiceStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	Users         uint32 `protobuf:"varint,1,opt,name=users,proto3" json:"users,omitempty"`
+	Urls          uint32 `protobuf:"varint,2,opt,name=urls,proto3" json:"urls,omitempty"`
+	Campaigns     uint32 `protobuf:"varint,3,opt,name=campaigns,proto3" json:"campaigns,omitempty"`
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceStatsResponse) Reset() {
+	*x = ServiceStatsResponse{}
+	mi := &file_proto_shortener_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceStatsResponse) ProtoMessage() {}
+
+func (x *ServiceStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceStatsResponse.ProtoReflect.Descriptor instead.
+func (*ServiceStatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ServiceStatsResponse) GetUsers() uint32 {
+	if x != nil {
+		return x.Users
+	}
+	return 0
+}
+
+func (x *ServiceStatsResponse) GetUrls() uint32 {
+	if x != nil {
+		return x.Urls
+	}
+	return 0
+}
+
+func (x *ServiceStatsResponse) GetCampaigns() uint32 {
+	if x != nil {
+		return x.Campaigns
+	}
+	return 0
+}
+
+// Moderation of the short URL disabled by the admin
+type Moderation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DisabledAt    *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=disabled_at,json=disabledAt,proto3" json:"disabled_at,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Moderation) Reset() {
+	*x = Moderation{}
+	mi := &file_proto_shortener_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Moderation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Moderation) ProtoMessage() {}
+
+func (x *Moderation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Moderation.ProtoReflect.Descriptor instead.
+func (*Moderation) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *Moderation) GetDisabledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DisabledAt
+	}
+	return nil
+}
+
+func (x *Moderation) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Short URL of any user as seen by the admin
+type AdminShortURL struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Moderation    *Moderation            `protobuf:"bytes,7,opt,name=moderation,proto3" json:"moderation,omitempty"`
+	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	OriginalUrl   string                 `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,4,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Title         string                 `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+	Deleted       bool `protobuf:"varint,6,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	OwnerBanned   bool `protobuf:"varint,8,opt,name=owner_banned,json=ownerBanned,proto3" json:"owner_banned,omitempty"`
+}
+
+func (x *AdminShortURL) Reset() {
+	*x = AdminShortURL{}
+	mi := &file_proto_shortener_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminShortURL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminShortURL) ProtoMessage() {}
+
+func (x *AdminShortURL) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminShortURL.ProtoReflect.Descriptor instead.
+func (*AdminShortURL) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *AdminShortURL) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *AdminShortURL) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *AdminShortURL) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AdminShortURL) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *AdminShortURL) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *AdminShortURL) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *AdminShortURL) GetModeration() *Moderation {
+	if x != nil {
+		return x.Moderation
+	}
+	return nil
+}
+
+func (x *AdminShortURL) GetOwnerBanned() bool {
+	if x != nil {
+		return x.OwnerBanned
+	}
+	return false
+}
+
+// Message for retrieving the short URL of any user
+type AdminGetShortURLRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the short URL, prefixed with the host and the slash on a branded domain
+	ShortUrl      string `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminGetShortURLRequest) Reset() {
+	*x = AdminGetShortURLRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminGetShortURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminGetShortURLRequest) ProtoMessage() {}
+
+func (x *AdminGetShortURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminGetShortURLRequest.ProtoReflect.Descriptor instead.
+func (*AdminGetShortURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *AdminGetShortURLRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+// Message for searching the short URLs of all the users, at least one of the filters is required
+type SearchShortURLsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OriginalUrl   string                 `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	Limit         uint32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchShortURLsRequest) Reset() {
+	*x = SearchShortURLsRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchShortURLsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchShortURLsRequest) ProtoMessage() {}
+
+func (x *SearchShortURLsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchShortURLsRequest.ProtoReflect.Descriptor instead.
+func (*SearchShortURLsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *SearchShortURLsRequest) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *SearchShortURLsRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *SearchShortURLsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SearchShortURLsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchShortURLsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Urls          []*AdminShortURL       `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchShortURLsResponse) Reset() {
+	*x = SearchShortURLsResponse{}
+	mi := &file_proto_shortener_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchShortURLsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchShortURLsResponse) ProtoMessage() {}
+
+func (x *SearchShortURLsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchShortURLsResponse.ProtoReflect.Descriptor instead.
+func (*SearchShortURLsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *SearchShortURLsResponse) GetUrls() []*AdminShortURL {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+// Message for disabling the short URL of any user
+type DisableShortURLRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the short URL, prefixed with the host and the slash on a branded domain
+	ShortUrl      string `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	Reason        string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisableShortURLRequest) Reset() {
+	*x = DisableShortURLRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisableShortURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableShortURLRequest) ProtoMessage() {}
+
+func (x *DisableShortURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisableShortURLRequest.ProtoReflect.Descriptor instead.
+func (*DisableShortURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *DisableShortURLRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *DisableShortURLRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Message for enabling the short URL disabled by the admin
+type EnableShortURLRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the short URL, prefixed with the host and the slash on a branded domain
+	ShortUrl      string `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableShortURLRequest) Reset() {
+	*x = EnableShortURLRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableShortURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableShortURLRequest) ProtoMessage() {}
+
+func (x *EnableShortURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableShortURLRequest.ProtoReflect.Descriptor instead.
+func (*EnableShortURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *EnableShortURLRequest) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+// Message for banning the user
+type BanUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BanUserRequest) Reset() {
+	*x = BanUserRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BanUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BanUserRequest) ProtoMessage() {}
+
+func (x *BanUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BanUserRequest.ProtoReflect.Descriptor instead.
+func (*BanUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *BanUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *BanUserRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type UserBan struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	BannedAt      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=banned_at,json=bannedAt,proto3" json:"banned_at,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserBan) Reset() {
+	*x = UserBan{}
+	mi := &file_proto_shortener_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserBan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserBan) ProtoMessage() {}
+
+func (x *UserBan) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserBan.ProtoReflect.Descriptor instead.
+func (*UserBan) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *UserBan) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UserBan) GetBannedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.BannedAt
+	}
+	return nil
+}
+
+func (x *UserBan) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Message for lifting the ban of the user
+type UnbanUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnbanUserRequest) Reset() {
+	*x = UnbanUserRequest{}
+	mi := &file_proto_shortener_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnbanUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnbanUserRequest) ProtoMessage() {}
+
+func (x *UnbanUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnbanUserRequest.ProtoReflect.Descriptor instead.
+func (*UnbanUserRequest) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *UnbanUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type RedirectOptions_Targets struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*TargetingRule       `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedirectOptions_Targets) Reset() {
+	*x = RedirectOptions_Targets{}
+	mi := &file_proto_shortener_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedirectOptions_Targets) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedirectOptions_Targets) ProtoMessage() {}
 
-func (x *DeleteBatchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_shortener_proto_msgTypes[6]
+func (x *RedirectOptions_Targets) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -340,47 +3068,40 @@ func (x *DeleteBatchRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteBatchRequest.ProtoReflect.Descriptor instead.
-func (*DeleteBatchRequest) Descriptor() ([]byte, []int) {
-	return file_proto_shortener_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use RedirectOptions_Targets.ProtoReflect.Descriptor instead.
+func (*RedirectOptions_Targets) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{0, 0}
 }
 
-func (x *DeleteBatchRequest) GetShortUrls() []string {
+func (x *RedirectOptions_Targets) GetRules() []*TargetingRule {
 	if x != nil {
-		return x.ShortUrls
+		return x.Rules
 	}
 	return nil
 }
 
-func (x *DeleteBatchRequest) GetUserId() string {
-	if x != nil {
-		return x.UserId
-	}
-	return ""
-}
-
-// Message for retrieving service statistics
-type ServiceStatsRequest struct {
+type RedirectOptions_GeoRules struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*GeoRule             `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ServiceStatsRequest) Reset() {
-	*x = ServiceStatsRequest{}
-	mi := &file_proto_shortener_proto_msgTypes[7]
+func (x *RedirectOptions_GeoRules) Reset() {
+	*x = RedirectOptions_GeoRules{}
+	mi := &file_proto_shortener_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ServiceStatsRequest) String() string {
+func (x *RedirectOptions_GeoRules) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServiceStatsRequest) ProtoMessage() {}
+func (*RedirectOptions_GeoRules) ProtoMessage() {}
 
-func (x *ServiceStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_shortener_proto_msgTypes[7]
+func (x *RedirectOptions_GeoRules) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -391,34 +3112,40 @@ func (x *ServiceStatsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ServiceStatsRequest.ProtoReflect.Descriptor instead.
-func (*ServiceStatsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_shortener_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use RedirectOptions_GeoRules.ProtoReflect.Descriptor instead.
+func (*RedirectOptions_GeoRules) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{0, 1}
 }
 
-type ServiceStatsResponse struct {
+func (x *RedirectOptions_GeoRules) GetRules() []*GeoRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type RedirectOptions_Variants struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Variants      []*SplitVariant        `protobuf:"bytes,1,rep,name=variants,proto3" json:"variants,omitempty"`
 	unknownFields protoimpl.UnknownFields
-	Users         uint32 `protobuf:"varint,1,opt,name=users,proto3" json:"users,omitempty"`
-	Urls          uint32 `protobuf:"varint,2,opt,name=urls,proto3" json:"urls,omitempty"`
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ServiceStatsResponse) Reset() {
-	*x = ServiceStatsResponse{}
-	mi := &file_proto_shortener_proto_msgTypes[8]
+func (x *RedirectOptions_Variants) Reset() {
+	*x = RedirectOptions_Variants{}
+	mi := &file_proto_shortener_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ServiceStatsResponse) String() string {
+func (x *RedirectOptions_Variants) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServiceStatsResponse) ProtoMessage() {}
+func (*RedirectOptions_Variants) ProtoMessage() {}
 
-func (x *ServiceStatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_shortener_proto_msgTypes[8]
+func (x *RedirectOptions_Variants) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -429,36 +3156,39 @@ func (x *ServiceStatsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ServiceStatsResponse.ProtoReflect.Descriptor instead.
-func (*ServiceStatsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_shortener_proto_rawDescGZIP(), []int{8}
-}
-
-func (x *ServiceStatsResponse) GetUsers() uint32 {
-	if x != nil {
-		return x.Users
-	}
-	return 0
+// Deprecated: Use RedirectOptions_Variants.ProtoReflect.Descriptor instead.
+func (*RedirectOptions_Variants) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{0, 2}
 }
 
-func (x *ServiceStatsResponse) GetUrls() uint32 {
+func (x *RedirectOptions_Variants) GetVariants() []*SplitVariant {
 	if x != nil {
-		return x.Urls
+		return x.Variants
 	}
-	return 0
+	return nil
 }
 
 type BatchShortenRequest_Item struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	Redirect      *RedirectOptions       `protobuf:"bytes,6,opt,name=redirect,proto3" json:"redirect,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
 	OriginalUrl   string                 `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	Notes         string                 `protobuf:"bytes,4,opt,name=notes,proto3" json:"notes,omitempty"`
+	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	Password      string                 `protobuf:"bytes,7,opt,name=password,proto3" json:"password,omitempty"`
+	UtmTemplateId string                 `protobuf:"bytes,8,opt,name=utm_template_id,json=utmTemplateId,proto3" json:"utm_template_id,omitempty"`
+	Domain        string                 `protobuf:"bytes,10,opt,name=domain,proto3" json:"domain,omitempty"`
+	CampaignId    string                 `protobuf:"bytes,11,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,12,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	MaxClicks     uint64 `protobuf:"varint,9,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BatchShortenRequest_Item) Reset() {
 	*x = BatchShortenRequest_Item{}
-	mi := &file_proto_shortener_proto_msgTypes[9]
+	mi := &file_proto_shortener_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -470,7 +3200,7 @@ func (x *BatchShortenRequest_Item) String() string {
 func (*BatchShortenRequest_Item) ProtoMessage() {}
 
 func (x *BatchShortenRequest_Item) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_shortener_proto_msgTypes[9]
+	mi := &file_proto_shortener_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -483,7 +3213,7 @@ func (x *BatchShortenRequest_Item) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchShortenRequest_Item.ProtoReflect.Descriptor instead.
 func (*BatchShortenRequest_Item) Descriptor() ([]byte, []int) {
-	return file_proto_shortener_proto_rawDescGZIP(), []int{2, 0}
+	return file_proto_shortener_proto_rawDescGZIP(), []int{6, 0}
 }
 
 func (x *BatchShortenRequest_Item) GetCorrelationId() string {
@@ -500,6 +3230,76 @@ func (x *BatchShortenRequest_Item) GetOriginalUrl() string {
 	return ""
 }
 
+func (x *BatchShortenRequest_Item) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *BatchShortenRequest_Item) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *BatchShortenRequest_Item) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *BatchShortenRequest_Item) GetRedirect() *RedirectOptions {
+	if x != nil {
+		return x.Redirect
+	}
+	return nil
+}
+
+func (x *BatchShortenRequest_Item) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *BatchShortenRequest_Item) GetUtmTemplateId() string {
+	if x != nil {
+		return x.UtmTemplateId
+	}
+	return ""
+}
+
+func (x *BatchShortenRequest_Item) GetMaxClicks() uint64 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *BatchShortenRequest_Item) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *BatchShortenRequest_Item) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *BatchShortenRequest_Item) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
 type BatchShortenResponse_Item struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
@@ -510,7 +3310,7 @@ type BatchShortenResponse_Item struct {
 
 func (x *BatchShortenResponse_Item) Reset() {
 	*x = BatchShortenResponse_Item{}
-	mi := &file_proto_shortener_proto_msgTypes[10]
+	mi := &file_proto_shortener_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -522,7 +3322,7 @@ func (x *BatchShortenResponse_Item) String() string {
 func (*BatchShortenResponse_Item) ProtoMessage() {}
 
 func (x *BatchShortenResponse_Item) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_shortener_proto_msgTypes[10]
+	mi := &file_proto_shortener_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -535,7 +3335,7 @@ func (x *BatchShortenResponse_Item) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchShortenResponse_Item.ProtoReflect.Descriptor instead.
 func (*BatchShortenResponse_Item) Descriptor() ([]byte, []int) {
-	return file_proto_shortener_proto_rawDescGZIP(), []int{3, 0}
+	return file_proto_shortener_proto_rawDescGZIP(), []int{7, 0}
 }
 
 func (x *BatchShortenResponse_Item) GetCorrelationId() string {
@@ -553,16 +3353,28 @@ func (x *BatchShortenResponse_Item) GetShortUrl() string {
 }
 
 type GetUserURLsResponse_URL struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ShortUrl      string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
-	OriginalUrl   string                 `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Metadata          *PageMetadata          `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ClicksLeft        *uint64                `protobuf:"varint,11,opt,name=clicks_left,json=clicksLeft,proto3,oneof" json:"clicks_left,omitempty"`
+	Redirect          *RedirectOptions       `protobuf:"bytes,7,opt,name=redirect,proto3" json:"redirect,omitempty"`
+	Domain            string                 `protobuf:"bytes,12,opt,name=domain,proto3" json:"domain,omitempty"`
+	Notes             string                 `protobuf:"bytes,4,opt,name=notes,proto3" json:"notes,omitempty"`
+	Title             string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	UtmTemplateId     string                 `protobuf:"bytes,9,opt,name=utm_template_id,json=utmTemplateId,proto3" json:"utm_template_id,omitempty"`
+	OriginalUrl       string                 `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	ShortUrl          string                 `protobuf:"bytes,1,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	CampaignId        string                 `protobuf:"bytes,13,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	OrgId             string                 `protobuf:"bytes,14,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Tags              []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	MaxClicks         uint64 `protobuf:"varint,10,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	sizeCache         protoimpl.SizeCache
+	PasswordProtected bool `protobuf:"varint,8,opt,name=password_protected,json=passwordProtected,proto3" json:"password_protected,omitempty"`
 }
 
 func (x *GetUserURLsResponse_URL) Reset() {
 	*x = GetUserURLsResponse_URL{}
-	mi := &file_proto_shortener_proto_msgTypes[11]
+	mi := &file_proto_shortener_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -574,7 +3386,7 @@ func (x *GetUserURLsResponse_URL) String() string {
 func (*GetUserURLsResponse_URL) ProtoMessage() {}
 
 func (x *GetUserURLsResponse_URL) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_shortener_proto_msgTypes[11]
+	mi := &file_proto_shortener_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -587,7 +3399,7 @@ func (x *GetUserURLsResponse_URL) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUserURLsResponse_URL.ProtoReflect.Descriptor instead.
 func (*GetUserURLsResponse_URL) Descriptor() ([]byte, []int) {
-	return file_proto_shortener_proto_rawDescGZIP(), []int{5, 0}
+	return file_proto_shortener_proto_rawDescGZIP(), []int{11, 0}
 }
 
 func (x *GetUserURLsResponse_URL) GetShortUrl() string {
@@ -604,49 +3416,500 @@ func (x *GetUserURLsResponse_URL) GetOriginalUrl() string {
 	return ""
 }
 
+func (x *GetUserURLsResponse_URL) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *GetUserURLsResponse_URL) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *GetUserURLsResponse_URL) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *GetUserURLsResponse_URL) GetMetadata() *PageMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *GetUserURLsResponse_URL) GetRedirect() *RedirectOptions {
+	if x != nil {
+		return x.Redirect
+	}
+	return nil
+}
+
+func (x *GetUserURLsResponse_URL) GetPasswordProtected() bool {
+	if x != nil {
+		return x.PasswordProtected
+	}
+	return false
+}
+
+func (x *GetUserURLsResponse_URL) GetUtmTemplateId() string {
+	if x != nil {
+		return x.UtmTemplateId
+	}
+	return ""
+}
+
+func (x *GetUserURLsResponse_URL) GetMaxClicks() uint64 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *GetUserURLsResponse_URL) GetClicksLeft() uint64 {
+	if x != nil && x.ClicksLeft != nil {
+		return *x.ClicksLeft
+	}
+	return 0
+}
+
+func (x *GetUserURLsResponse_URL) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *GetUserURLsResponse_URL) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *GetUserURLsResponse_URL) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+type UpdateShortURLRequest_Tags struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateShortURLRequest_Tags) Reset() {
+	*x = UpdateShortURLRequest_Tags{}
+	mi := &file_proto_shortener_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateShortURLRequest_Tags) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateShortURLRequest_Tags) ProtoMessage() {}
+
+func (x *UpdateShortURLRequest_Tags) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateShortURLRequest_Tags.ProtoReflect.Descriptor instead.
+func (*UpdateShortURLRequest_Tags) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{12, 0}
+}
+
+func (x *UpdateShortURLRequest_Tags) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type GetShortURLStatsResponse_Variant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Variant       *SplitVariant          `protobuf:"bytes,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	Clicks        uint64 `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"`
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetShortURLStatsResponse_Variant) Reset() {
+	*x = GetShortURLStatsResponse_Variant{}
+	mi := &file_proto_shortener_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetShortURLStatsResponse_Variant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetShortURLStatsResponse_Variant) ProtoMessage() {}
+
+func (x *GetShortURLStatsResponse_Variant) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_shortener_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetShortURLStatsResponse_Variant.ProtoReflect.Descriptor instead.
+func (*GetShortURLStatsResponse_Variant) Descriptor() ([]byte, []int) {
+	return file_proto_shortener_proto_rawDescGZIP(), []int{35, 0}
+}
+
+func (x *GetShortURLStatsResponse_Variant) GetVariant() *SplitVariant {
+	if x != nil {
+		return x.Variant
+	}
+	return nil
+}
+
+func (x *GetShortURLStatsResponse_Variant) GetClicks() uint64 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
 var File_proto_shortener_proto protoreflect.FileDescriptor
 
 const file_proto_shortener_proto_rawDesc = "" +
 	"\n" +
-	"\x15proto/shortener.proto\x12\x06server\x1a\x1bgoogle/protobuf/empty.proto\";\n" +
+	"\x15proto/shortener.proto\x12\x06server\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc5\x06\n" +
+	"\x0fRedirectOptions\x12'\n" +
+	"\finterstitial\x18\x01 \x01(\bH\x00R\finterstitial\x88\x01\x01\x12,\n" +
+	"\x0fredirect_status\x18\x02 \x01(\rH\x01R\x0eredirectStatus\x88\x01\x01\x12(\n" +
+	"\rcache_control\x18\x03 \x01(\tH\x02R\fcacheControl\x88\x01\x01\x12,\n" +
+	"\x0freferrer_policy\x18\x04 \x01(\tH\x03R\x0ereferrerPolicy\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"robots_tag\x18\x05 \x01(\tH\x04R\trobotsTag\x88\x01\x01\x12%\n" +
+	"\vpassthrough\x18\x06 \x01(\tH\x05R\vpassthrough\x88\x01\x01\x129\n" +
+	"\atargets\x18\a \x01(\v2\x1f.server.RedirectOptions.TargetsR\atargets\x12=\n" +
+	"\tgeo_rules\x18\b \x01(\v2 .server.RedirectOptions.GeoRulesR\bgeoRules\x12<\n" +
+	"\bvariants\x18\t \x01(\v2 .server.RedirectOptions.VariantsR\bvariants\x12$\n" +
+	"\vactive_from\x18\n" +
+	" \x01(\tH\x06R\n" +
+	"activeFrom\x88\x01\x01\x12&\n" +
+	"\factive_until\x18\v \x01(\tH\aR\vactiveUntil\x88\x01\x01\x1a6\n" +
+	"\aTargets\x12+\n" +
+	"\x05rules\x18\x01 \x03(\v2\x15.server.TargetingRuleR\x05rules\x1a1\n" +
+	"\bGeoRules\x12%\n" +
+	"\x05rules\x18\x01 \x03(\v2\x0f.server.GeoRuleR\x05rules\x1a<\n" +
+	"\bVariants\x120\n" +
+	"\bvariants\x18\x01 \x03(\v2\x14.server.SplitVariantR\bvariantsB\x0f\n" +
+	"\r_interstitialB\x12\n" +
+	"\x10_redirect_statusB\x10\n" +
+	"\x0e_cache_controlB\x12\n" +
+	"\x10_referrer_policyB\r\n" +
+	"\v_robots_tagB\x0e\n" +
+	"\f_passthroughB\x0e\n" +
+	"\f_active_fromB\x0f\n" +
+	"\r_active_until\"=\n" +
+	"\rTargetingRule\x12\x1a\n" +
+	"\bplatform\x18\x01 \x01(\tR\bplatform\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\"5\n" +
+	"\aGeoRule\x12\x18\n" +
+	"\acountry\x18\x01 \x01(\tR\acountry\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\"L\n" +
+	"\fSplitVariant\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\rR\x06weight\"\xe3\x02\n" +
 	"\x0eShortenRequest\x12\x10\n" +
 	"\x03url\x18\x01 \x01(\tR\x03url\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\")\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x14\n" +
+	"\x05notes\x18\x04 \x01(\tR\x05notes\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x123\n" +
+	"\bredirect\x18\x06 \x01(\v2\x17.server.RedirectOptionsR\bredirect\x12\x1a\n" +
+	"\bpassword\x18\a \x01(\tR\bpassword\x12&\n" +
+	"\x0futm_template_id\x18\b \x01(\tR\rutmTemplateId\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\t \x01(\x04R\tmaxClicks\x12\x16\n" +
+	"\x06domain\x18\n" +
+	" \x01(\tR\x06domain\x12\x1f\n" +
+	"\vcampaign_id\x18\v \x01(\tR\n" +
+	"campaignId\x12\x15\n" +
+	"\x06org_id\x18\f \x01(\tR\x05orgId\")\n" +
 	"\x0fShortenResponse\x12\x16\n" +
-	"\x06result\x18\x01 \x01(\tR\x06result\"\xb8\x01\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result\"\xe1\x03\n" +
 	"\x13BatchShortenRequest\x126\n" +
 	"\x05items\x18\x01 \x03(\v2 .server.BatchShortenRequest.ItemR\x05items\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\x1aP\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x1a\xf8\x02\n" +
 	"\x04Item\x12%\n" +
 	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x12!\n" +
-	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\"\x9b\x01\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x14\n" +
+	"\x05notes\x18\x04 \x01(\tR\x05notes\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x123\n" +
+	"\bredirect\x18\x06 \x01(\v2\x17.server.RedirectOptionsR\bredirect\x12\x1a\n" +
+	"\bpassword\x18\a \x01(\tR\bpassword\x12&\n" +
+	"\x0futm_template_id\x18\b \x01(\tR\rutmTemplateId\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\t \x01(\x04R\tmaxClicks\x12\x16\n" +
+	"\x06domain\x18\n" +
+	" \x01(\tR\x06domain\x12\x1f\n" +
+	"\vcampaign_id\x18\v \x01(\tR\n" +
+	"campaignId\x12\x15\n" +
+	"\x06org_id\x18\f \x01(\tR\x05orgId\"\x9b\x01\n" +
 	"\x14BatchShortenResponse\x127\n" +
 	"\x05items\x18\x01 \x03(\v2!.server.BatchShortenResponse.ItemR\x05items\x1aJ\n" +
 	"\x04Item\x12%\n" +
 	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x12\x1b\n" +
-	"\tshort_url\x18\x02 \x01(\tR\bshortUrl\"-\n" +
+	"\tshort_url\x18\x02 \x01(\tR\bshortUrl\"`\n" +
 	"\x12GetUserURLsRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x91\x01\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\x12\x1f\n" +
+	"\vcampaign_id\x18\x03 \x01(\tR\n" +
+	"campaignId\"U\n" +
+	"\x11GetOrgURLsRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\"\xc7\x01\n" +
+	"\fPageMetadata\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x1f\n" +
+	"\vfavicon_url\x18\x02 \x01(\tR\n" +
+	"faviconUrl\x12B\n" +
+	"\n" +
+	"open_graph\x18\x03 \x03(\v2#.server.PageMetadata.OpenGraphEntryR\topenGraph\x1a<\n" +
+	"\x0eOpenGraphEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb5\x04\n" +
 	"\x13GetUserURLsResponse\x123\n" +
-	"\x04urls\x18\x01 \x03(\v2\x1f.server.GetUserURLsResponse.URLR\x04urls\x1aE\n" +
+	"\x04urls\x18\x01 \x03(\v2\x1f.server.GetUserURLsResponse.URLR\x04urls\x1a\xe8\x03\n" +
 	"\x03URL\x12\x1b\n" +
 	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12!\n" +
-	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\"L\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x14\n" +
+	"\x05notes\x18\x04 \x01(\tR\x05notes\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x120\n" +
+	"\bmetadata\x18\x06 \x01(\v2\x14.server.PageMetadataR\bmetadata\x123\n" +
+	"\bredirect\x18\a \x01(\v2\x17.server.RedirectOptionsR\bredirect\x12-\n" +
+	"\x12password_protected\x18\b \x01(\bR\x11passwordProtected\x12&\n" +
+	"\x0futm_template_id\x18\t \x01(\tR\rutmTemplateId\x12\x1d\n" +
+	"\n" +
+	"max_clicks\x18\n" +
+	" \x01(\x04R\tmaxClicks\x12$\n" +
+	"\vclicks_left\x18\v \x01(\x04H\x00R\n" +
+	"clicksLeft\x88\x01\x01\x12\x16\n" +
+	"\x06domain\x18\f \x01(\tR\x06domain\x12\x1f\n" +
+	"\vcampaign_id\x18\r \x01(\tR\n" +
+	"campaignId\x12\x15\n" +
+	"\x06org_id\x18\x0e \x01(\tR\x05orgIdB\x0e\n" +
+	"\f_clicks_left\"\x9b\x03\n" +
+	"\x15UpdateShortURLRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x19\n" +
+	"\x05title\x18\x03 \x01(\tH\x00R\x05title\x88\x01\x01\x12\x19\n" +
+	"\x05notes\x18\x04 \x01(\tH\x01R\x05notes\x88\x01\x01\x126\n" +
+	"\x04tags\x18\x05 \x01(\v2\".server.UpdateShortURLRequest.TagsR\x04tags\x123\n" +
+	"\bredirect\x18\x06 \x01(\v2\x17.server.RedirectOptionsR\bredirect\x12+\n" +
+	"\x0futm_template_id\x18\a \x01(\tH\x02R\rutmTemplateId\x88\x01\x01\x12$\n" +
+	"\vcampaign_id\x18\b \x01(\tH\x03R\n" +
+	"campaignId\x88\x01\x01\x1a\x1e\n" +
+	"\x04Tags\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06valuesB\b\n" +
+	"\x06_titleB\b\n" +
+	"\x06_notesB\x12\n" +
+	"\x10_utm_template_idB\x0e\n" +
+	"\f_campaign_id\"K\n" +
+	"\x16UpdateShortURLResponse\x121\n" +
+	"\x03url\x18\x01 \x01(\v2\x1f.server.GetUserURLsResponse.URLR\x03url\"\xce\x01\n" +
+	"\vUTMTemplate\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"utm_source\x18\x03 \x01(\tR\tutmSource\x12\x1d\n" +
+	"\n" +
+	"utm_medium\x18\x04 \x01(\tR\tutmMedium\x12!\n" +
+	"\futm_campaign\x18\x05 \x01(\tR\vutmCampaign\x12\x19\n" +
+	"\butm_term\x18\x06 \x01(\tR\autmTerm\x12\x1f\n" +
+	"\vutm_content\x18\a \x01(\tR\n" +
+	"utmContent\"d\n" +
+	"\x18CreateUTMTemplateRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12/\n" +
+	"\btemplate\x18\x02 \x01(\v2\x13.server.UTMTemplateR\btemplate\"1\n" +
+	"\x16GetUTMTemplatesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"L\n" +
+	"\x17GetUTMTemplatesResponse\x121\n" +
+	"\ttemplates\x18\x01 \x03(\v2\x13.server.UTMTemplateR\ttemplates\"d\n" +
+	"\x18UpdateUTMTemplateRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12/\n" +
+	"\btemplate\x18\x02 \x01(\v2\x13.server.UTMTemplateR\btemplate\"C\n" +
+	"\x18DeleteUTMTemplateRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\".\n" +
+	"\bCampaign\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"^\n" +
+	"\x15CreateCampaignRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12,\n" +
+	"\bcampaign\x18\x02 \x01(\v2\x10.server.CampaignR\bcampaign\".\n" +
+	"\x13GetCampaignsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"F\n" +
+	"\x14GetCampaignsResponse\x12.\n" +
+	"\tcampaigns\x18\x01 \x03(\v2\x10.server.CampaignR\tcampaigns\"^\n" +
+	"\x15UpdateCampaignRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12,\n" +
+	"\bcampaign\x18\x02 \x01(\v2\x10.server.CampaignR\bcampaign\"@\n" +
+	"\x15DeleteCampaignRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"B\n" +
+	"\x17GetCampaignStatsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"t\n" +
+	"\x18GetCampaignStatsResponse\x12,\n" +
+	"\bcampaign\x18\x01 \x01(\v2\x10.server.CampaignR\bcampaign\x12\x12\n" +
+	"\x04urls\x18\x02 \x01(\rR\x04urls\x12\x16\n" +
+	"\x06clicks\x18\x03 \x01(\x04R\x06clicks\"\xf8\x01\n" +
+	"\x06APIKey\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06prefix\x18\x03 \x01(\tR\x06prefix\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12<\n" +
+	"\flast_used_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastUsedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"}\n" +
+	"\x13CreateAPIKeyRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"Q\n" +
+	"\x14CreateAPIKeyResponse\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12'\n" +
+	"\aapi_key\x18\x02 \x01(\v2\x0e.server.APIKeyR\x06apiKey\",\n" +
+	"\x11GetAPIKeysRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"?\n" +
+	"\x12GetAPIKeysResponse\x12)\n" +
+	"\bapi_keys\x18\x01 \x03(\v2\x0e.server.APIKeyR\aapiKeys\">\n" +
+	"\x13DeleteAPIKeyRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"O\n" +
+	"\x17GetShortURLStatsRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\xd0\x01\n" +
+	"\x18GetShortURLStatsResponse\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12D\n" +
+	"\bvariants\x18\x02 \x03(\v2(.server.GetShortURLStatsResponse.VariantR\bvariants\x1aQ\n" +
+	"\aVariant\x12.\n" +
+	"\avariant\x18\x01 \x01(\v2\x14.server.SplitVariantR\avariant\x12\x16\n" +
+	"\x06clicks\x18\x02 \x01(\x04R\x06clicks\"q\n" +
+	"\x10GetQRCodeRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\rR\x04size\x12\x14\n" +
+	"\x05level\x18\x04 \x01(\tR\x05level\"`\n" +
+	"\x11GetQRCodeResponse\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\fR\x05image\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04etag\x18\x03 \x01(\tR\x04etag\"L\n" +
 	"\x12DeleteBatchRequest\x12\x1d\n" +
 	"\n" +
 	"short_urls\x18\x01 \x03(\tR\tshortUrls\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\tR\x06userId\"\x15\n" +
-	"\x13ServiceStatsRequest\"@\n" +
+	"\x13ServiceStatsRequest\"^\n" +
 	"\x14ServiceStatsResponse\x12\x14\n" +
 	"\x05users\x18\x01 \x01(\rR\x05users\x12\x12\n" +
-	"\x04urls\x18\x02 \x01(\rR\x04urls2\xbf\x03\n" +
+	"\x04urls\x18\x02 \x01(\rR\x04urls\x12\x1c\n" +
+	"\tcampaigns\x18\x03 \x01(\rR\tcampaigns\"a\n" +
+	"\n" +
+	"Moderation\x12;\n" +
+	"\vdisabled_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"disabledAt\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x86\x02\n" +
+	"\rAdminShortURL\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12!\n" +
+	"\foriginal_url\x18\x02 \x01(\tR\voriginalUrl\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x15\n" +
+	"\x06org_id\x18\x04 \x01(\tR\x05orgId\x12\x14\n" +
+	"\x05title\x18\x05 \x01(\tR\x05title\x12\x18\n" +
+	"\adeleted\x18\x06 \x01(\bR\adeleted\x122\n" +
+	"\n" +
+	"moderation\x18\a \x01(\v2\x12.server.ModerationR\n" +
+	"moderation\x12!\n" +
+	"\fowner_banned\x18\b \x01(\bR\vownerBanned\"6\n" +
+	"\x17AdminGetShortURLRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\"\x82\x01\n" +
+	"\x16SearchShortURLsRequest\x12!\n" +
+	"\foriginal_url\x18\x01 \x01(\tR\voriginalUrl\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\rR\x05limit\"D\n" +
+	"\x17SearchShortURLsResponse\x12)\n" +
+	"\x04urls\x18\x01 \x03(\v2\x15.server.AdminShortURLR\x04urls\"M\n" +
+	"\x16DisableShortURLRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"4\n" +
+	"\x15EnableShortURLRequest\x12\x1b\n" +
+	"\tshort_url\x18\x01 \x01(\tR\bshortUrl\"A\n" +
+	"\x0eBanUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"s\n" +
+	"\aUserBan\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x127\n" +
+	"\tbanned_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\bbannedAt\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"+\n" +
+	"\x10UnbanUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId2\xf0\f\n" +
 	"\x13URLShortenerService\x12A\n" +
 	"\x0eCreateShortURL\x12\x16.server.ShortenRequest\x1a\x17.server.ShortenResponse\x12P\n" +
 	"\x13BatchCreateShortURL\x12\x1b.server.BatchShortenRequest\x1a\x1c.server.BatchShortenResponse\x12F\n" +
-	"\vGetUserURLs\x12\x1a.server.GetUserURLsRequest\x1a\x1b.server.GetUserURLsResponse\x12E\n" +
+	"\vGetUserURLs\x12\x1a.server.GetUserURLsRequest\x1a\x1b.server.GetUserURLsResponse\x12D\n" +
+	"\n" +
+	"GetOrgURLs\x12\x19.server.GetOrgURLsRequest\x1a\x1b.server.GetUserURLsResponse\x12O\n" +
+	"\x0eUpdateShortURL\x12\x1d.server.UpdateShortURLRequest\x1a\x1e.server.UpdateShortURLResponse\x12J\n" +
+	"\x11CreateUTMTemplate\x12 .server.CreateUTMTemplateRequest\x1a\x13.server.UTMTemplate\x12R\n" +
+	"\x0fGetUTMTemplates\x12\x1e.server.GetUTMTemplatesRequest\x1a\x1f.server.GetUTMTemplatesResponse\x12J\n" +
+	"\x11UpdateUTMTemplate\x12 .server.UpdateUTMTemplateRequest\x1a\x13.server.UTMTemplate\x12M\n" +
+	"\x11DeleteUTMTemplate\x12 .server.DeleteUTMTemplateRequest\x1a\x16.google.protobuf.Empty\x12A\n" +
+	"\x0eCreateCampaign\x12\x1d.server.CreateCampaignRequest\x1a\x10.server.Campaign\x12I\n" +
+	"\fGetCampaigns\x12\x1b.server.GetCampaignsRequest\x1a\x1c.server.GetCampaignsResponse\x12A\n" +
+	"\x0eUpdateCampaign\x12\x1d.server.UpdateCampaignRequest\x1a\x10.server.Campaign\x12G\n" +
+	"\x0eDeleteCampaign\x12\x1d.server.DeleteCampaignRequest\x1a\x16.google.protobuf.Empty\x12U\n" +
+	"\x10GetCampaignStats\x12\x1f.server.GetCampaignStatsRequest\x1a .server.GetCampaignStatsResponse\x12I\n" +
+	"\fCreateAPIKey\x12\x1b.server.CreateAPIKeyRequest\x1a\x1c.server.CreateAPIKeyResponse\x12C\n" +
+	"\n" +
+	"GetAPIKeys\x12\x19.server.GetAPIKeysRequest\x1a\x1a.server.GetAPIKeysResponse\x12C\n" +
+	"\fDeleteAPIKey\x12\x1b.server.DeleteAPIKeyRequest\x1a\x16.google.protobuf.Empty\x12U\n" +
+	"\x10GetShortURLStats\x12\x1f.server.GetShortURLStatsRequest\x1a .server.GetShortURLStatsResponse\x12@\n" +
+	"\tGetQRCode\x12\x18.server.GetQRCodeRequest\x1a\x19.server.GetQRCodeResponse\x12E\n" +
 	"\x0fDeleteBatchURLs\x12\x1a.server.DeleteBatchRequest\x1a\x16.google.protobuf.Empty\x12L\n" +
 	"\x0fGetServiceStats\x12\x1b.server.ServiceStatsRequest\x1a\x1c.server.ServiceStatsResponse\x126\n" +
-	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.EmptyB\x17Z\x15internal/server/protob\x06proto3"
+	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty2\xba\x03\n" +
+	"\x18URLShortenerAdminService\x12E\n" +
+	"\vGetShortURL\x12\x1f.server.AdminGetShortURLRequest\x1a\x15.server.AdminShortURL\x12R\n" +
+	"\x0fSearchShortURLs\x12\x1e.server.SearchShortURLsRequest\x1a\x1f.server.SearchShortURLsResponse\x12H\n" +
+	"\x0fDisableShortURL\x12\x1e.server.DisableShortURLRequest\x1a\x15.server.AdminShortURL\x12F\n" +
+	"\x0eEnableShortURL\x12\x1d.server.EnableShortURLRequest\x1a\x15.server.AdminShortURL\x122\n" +
+	"\aBanUser\x12\x16.server.BanUserRequest\x1a\x0f.server.UserBan\x12=\n" +
+	"\tUnbanUser\x12\x18.server.UnbanUserRequest\x1a\x16.google.protobuf.EmptyB\x17Z\x15internal/server/protob\x06proto3"
 
 var (
 	file_proto_shortener_proto_rawDescOnce sync.Once
@@ -660,43 +3923,169 @@ func file_proto_shortener_proto_rawDescGZIP() []byte {
 	return file_proto_shortener_proto_rawDescData
 }
 
-var file_proto_shortener_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_proto_shortener_proto_msgTypes = make([]protoimpl.MessageInfo, 60)
 var file_proto_shortener_proto_goTypes = []any{
-	(*ShortenRequest)(nil),            // 0: server.ShortenRequest
-	(*ShortenResponse)(nil),           // 1: server.ShortenResponse
-	(*BatchShortenRequest)(nil),       // 2: server.BatchShortenRequest
-	(*BatchShortenResponse)(nil),      // 3: server.BatchShortenResponse
-	(*GetUserURLsRequest)(nil),        // 4: server.GetUserURLsRequest
-	(*GetUserURLsResponse)(nil),       // 5: server.GetUserURLsResponse
-	(*DeleteBatchRequest)(nil),        // 6: server.DeleteBatchRequest
-	(*ServiceStatsRequest)(nil),       // 7: server.ServiceStatsRequest
-	(*ServiceStatsResponse)(nil),      // 8: server.ServiceStatsResponse
-	(*BatchShortenRequest_Item)(nil),  // 9: server.BatchShortenRequest.Item
-	(*BatchShortenResponse_Item)(nil), // 10: server.BatchShortenResponse.Item
-	(*GetUserURLsResponse_URL)(nil),   // 11: server.GetUserURLsResponse.URL
-	(*emptypb.Empty)(nil),             // 12: google.protobuf.Empty
+	(*RedirectOptions)(nil),                  // 0: server.RedirectOptions
+	(*TargetingRule)(nil),                    // 1: server.TargetingRule
+	(*GeoRule)(nil),                          // 2: server.GeoRule
+	(*SplitVariant)(nil),                     // 3: server.SplitVariant
+	(*ShortenRequest)(nil),                   // 4: server.ShortenRequest
+	(*ShortenResponse)(nil),                  // 5: server.ShortenResponse
+	(*BatchShortenRequest)(nil),              // 6: server.BatchShortenRequest
+	(*BatchShortenResponse)(nil),             // 7: server.BatchShortenResponse
+	(*GetUserURLsRequest)(nil),               // 8: server.GetUserURLsRequest
+	(*GetOrgURLsRequest)(nil),                // 9: server.GetOrgURLsRequest
+	(*PageMetadata)(nil),                     // 10: server.PageMetadata
+	(*GetUserURLsResponse)(nil),              // 11: server.GetUserURLsResponse
+	(*UpdateShortURLRequest)(nil),            // 12: server.UpdateShortURLRequest
+	(*UpdateShortURLResponse)(nil),           // 13: server.UpdateShortURLResponse
+	(*UTMTemplate)(nil),                      // 14: server.UTMTemplate
+	(*CreateUTMTemplateRequest)(nil),         // 15: server.CreateUTMTemplateRequest
+	(*GetUTMTemplatesRequest)(nil),           // 16: server.GetUTMTemplatesRequest
+	(*GetUTMTemplatesResponse)(nil),          // 17: server.GetUTMTemplatesResponse
+	(*UpdateUTMTemplateRequest)(nil),         // 18: server.UpdateUTMTemplateRequest
+	(*DeleteUTMTemplateRequest)(nil),         // 19: server.DeleteUTMTemplateRequest
+	(*Campaign)(nil),                         // 20: server.Campaign
+	(*CreateCampaignRequest)(nil),            // 21: server.CreateCampaignRequest
+	(*GetCampaignsRequest)(nil),              // 22: server.GetCampaignsRequest
+	(*GetCampaignsResponse)(nil),             // 23: server.GetCampaignsResponse
+	(*UpdateCampaignRequest)(nil),            // 24: server.UpdateCampaignRequest
+	(*DeleteCampaignRequest)(nil),            // 25: server.DeleteCampaignRequest
+	(*GetCampaignStatsRequest)(nil),          // 26: server.GetCampaignStatsRequest
+	(*GetCampaignStatsResponse)(nil),         // 27: server.GetCampaignStatsResponse
+	(*APIKey)(nil),                           // 28: server.APIKey
+	(*CreateAPIKeyRequest)(nil),              // 29: server.CreateAPIKeyRequest
+	(*CreateAPIKeyResponse)(nil),             // 30: server.CreateAPIKeyResponse
+	(*GetAPIKeysRequest)(nil),                // 31: server.GetAPIKeysRequest
+	(*GetAPIKeysResponse)(nil),               // 32: server.GetAPIKeysResponse
+	(*DeleteAPIKeyRequest)(nil),              // 33: server.DeleteAPIKeyRequest
+	(*GetShortURLStatsRequest)(nil),          // 34: server.GetShortURLStatsRequest
+	(*GetShortURLStatsResponse)(nil),         // 35: server.GetShortURLStatsResponse
+	(*GetQRCodeRequest)(nil),                 // 36: server.GetQRCodeRequest
+	(*GetQRCodeResponse)(nil),                // 37: server.GetQRCodeResponse
+	(*DeleteBatchRequest)(nil),               // 38: server.DeleteBatchRequest
+	(*ServiceStatsRequest)(nil),              // 39: server.ServiceStatsRequest
+	(*ServiceStatsResponse)(nil),             // 40: server.ServiceStatsResponse
+	(*Moderation)(nil),                       // 41: server.Moderation
+	(*AdminShortURL)(nil),                    // 42: server.AdminShortURL
+	(*AdminGetShortURLRequest)(nil),          // 43: server.AdminGetShortURLRequest
+	(*SearchShortURLsRequest)(nil),           // 44: server.SearchShortURLsRequest
+	(*SearchShortURLsResponse)(nil),          // 45: server.SearchShortURLsResponse
+	(*DisableShortURLRequest)(nil),           // 46: server.DisableShortURLRequest
+	(*EnableShortURLRequest)(nil),            // 47: server.EnableShortURLRequest
+	(*BanUserRequest)(nil),                   // 48: server.BanUserRequest
+	(*UserBan)(nil),                          // 49: server.UserBan
+	(*UnbanUserRequest)(nil),                 // 50: server.UnbanUserRequest
+	(*RedirectOptions_Targets)(nil),          // 51: server.RedirectOptions.Targets
+	(*RedirectOptions_GeoRules)(nil),         // 52: server.RedirectOptions.GeoRules
+	(*RedirectOptions_Variants)(nil),         // 53: server.RedirectOptions.Variants
+	(*BatchShortenRequest_Item)(nil),         // 54: server.BatchShortenRequest.Item
+	(*BatchShortenResponse_Item)(nil),        // 55: server.BatchShortenResponse.Item
+	nil,                                      // 56: server.PageMetadata.OpenGraphEntry
+	(*GetUserURLsResponse_URL)(nil),          // 57: server.GetUserURLsResponse.URL
+	(*UpdateShortURLRequest_Tags)(nil),       // 58: server.UpdateShortURLRequest.Tags
+	(*GetShortURLStatsResponse_Variant)(nil), // 59: server.GetShortURLStatsResponse.Variant
+	(*timestamppb.Timestamp)(nil),            // 60: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),                    // 61: google.protobuf.Empty
 }
 var file_proto_shortener_proto_depIdxs = []int32{
-	9,  // 0: server.BatchShortenRequest.items:type_name -> server.BatchShortenRequest.Item
-	10, // 1: server.BatchShortenResponse.items:type_name -> server.BatchShortenResponse.Item
-	11, // 2: server.GetUserURLsResponse.urls:type_name -> server.GetUserURLsResponse.URL
-	0,  // 3: server.URLShortenerService.CreateShortURL:input_type -> server.ShortenRequest
-	2,  // 4: server.URLShortenerService.BatchCreateShortURL:input_type -> server.BatchShortenRequest
-	4,  // 5: server.URLShortenerService.GetUserURLs:input_type -> server.GetUserURLsRequest
-	6,  // 6: server.URLShortenerService.DeleteBatchURLs:input_type -> server.DeleteBatchRequest
-	7,  // 7: server.URLShortenerService.GetServiceStats:input_type -> server.ServiceStatsRequest
-	12, // 8: server.URLShortenerService.Ping:input_type -> google.protobuf.Empty
-	1,  // 9: server.URLShortenerService.CreateShortURL:output_type -> server.ShortenResponse
-	3,  // 10: server.URLShortenerService.BatchCreateShortURL:output_type -> server.BatchShortenResponse
-	5,  // 11: server.URLShortenerService.GetUserURLs:output_type -> server.GetUserURLsResponse
-	12, // 12: server.URLShortenerService.DeleteBatchURLs:output_type -> google.protobuf.Empty
-	8,  // 13: server.URLShortenerService.GetServiceStats:output_type -> server.ServiceStatsResponse
-	12, // 14: server.URLShortenerService.Ping:output_type -> google.protobuf.Empty
-	9,  // [9:15] is the sub-list for method output_type
-	3,  // [3:9] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	51, // 0: server.RedirectOptions.targets:type_name -> server.RedirectOptions.Targets
+	52, // 1: server.RedirectOptions.geo_rules:type_name -> server.RedirectOptions.GeoRules
+	53, // 2: server.RedirectOptions.variants:type_name -> server.RedirectOptions.Variants
+	0,  // 3: server.ShortenRequest.redirect:type_name -> server.RedirectOptions
+	54, // 4: server.BatchShortenRequest.items:type_name -> server.BatchShortenRequest.Item
+	55, // 5: server.BatchShortenResponse.items:type_name -> server.BatchShortenResponse.Item
+	56, // 6: server.PageMetadata.open_graph:type_name -> server.PageMetadata.OpenGraphEntry
+	57, // 7: server.GetUserURLsResponse.urls:type_name -> server.GetUserURLsResponse.URL
+	58, // 8: server.UpdateShortURLRequest.tags:type_name -> server.UpdateShortURLRequest.Tags
+	0,  // 9: server.UpdateShortURLRequest.redirect:type_name -> server.RedirectOptions
+	57, // 10: server.UpdateShortURLResponse.url:type_name -> server.GetUserURLsResponse.URL
+	14, // 11: server.CreateUTMTemplateRequest.template:type_name -> server.UTMTemplate
+	14, // 12: server.GetUTMTemplatesResponse.templates:type_name -> server.UTMTemplate
+	14, // 13: server.UpdateUTMTemplateRequest.template:type_name -> server.UTMTemplate
+	20, // 14: server.CreateCampaignRequest.campaign:type_name -> server.Campaign
+	20, // 15: server.GetCampaignsResponse.campaigns:type_name -> server.Campaign
+	20, // 16: server.UpdateCampaignRequest.campaign:type_name -> server.Campaign
+	20, // 17: server.GetCampaignStatsResponse.campaign:type_name -> server.Campaign
+	60, // 18: server.APIKey.created_at:type_name -> google.protobuf.Timestamp
+	60, // 19: server.APIKey.last_used_at:type_name -> google.protobuf.Timestamp
+	60, // 20: server.APIKey.expires_at:type_name -> google.protobuf.Timestamp
+	60, // 21: server.CreateAPIKeyRequest.expires_at:type_name -> google.protobuf.Timestamp
+	28, // 22: server.CreateAPIKeyResponse.api_key:type_name -> server.APIKey
+	28, // 23: server.GetAPIKeysResponse.api_keys:type_name -> server.APIKey
+	59, // 24: server.GetShortURLStatsResponse.variants:type_name -> server.GetShortURLStatsResponse.Variant
+	60, // 25: server.Moderation.disabled_at:type_name -> google.protobuf.Timestamp
+	41, // 26: server.AdminShortURL.moderation:type_name -> server.Moderation
+	42, // 27: server.SearchShortURLsResponse.urls:type_name -> server.AdminShortURL
+	60, // 28: server.UserBan.banned_at:type_name -> google.protobuf.Timestamp
+	1,  // 29: server.RedirectOptions.Targets.rules:type_name -> server.TargetingRule
+	2,  // 30: server.RedirectOptions.GeoRules.rules:type_name -> server.GeoRule
+	3,  // 31: server.RedirectOptions.Variants.variants:type_name -> server.SplitVariant
+	0,  // 32: server.BatchShortenRequest.Item.redirect:type_name -> server.RedirectOptions
+	10, // 33: server.GetUserURLsResponse.URL.metadata:type_name -> server.PageMetadata
+	0,  // 34: server.GetUserURLsResponse.URL.redirect:type_name -> server.RedirectOptions
+	3,  // 35: server.GetShortURLStatsResponse.Variant.variant:type_name -> server.SplitVariant
+	4,  // 36: server.URLShortenerService.CreateShortURL:input_type -> server.ShortenRequest
+	6,  // 37: server.URLShortenerService.BatchCreateShortURL:input_type -> server.BatchShortenRequest
+	8,  // 38: server.URLShortenerService.GetUserURLs:input_type -> server.GetUserURLsRequest
+	9,  // 39: server.URLShortenerService.GetOrgURLs:input_type -> server.GetOrgURLsRequest
+	12, // 40: server.URLShortenerService.UpdateShortURL:input_type -> server.UpdateShortURLRequest
+	15, // 41: server.URLShortenerService.CreateUTMTemplate:input_type -> server.CreateUTMTemplateRequest
+	16, // 42: server.URLShortenerService.GetUTMTemplates:input_type -> server.GetUTMTemplatesRequest
+	18, // 43: server.URLShortenerService.UpdateUTMTemplate:input_type -> server.UpdateUTMTemplateRequest
+	19, // 44: server.URLShortenerService.DeleteUTMTemplate:input_type -> server.DeleteUTMTemplateRequest
+	21, // 45: server.URLShortenerService.CreateCampaign:input_type -> server.CreateCampaignRequest
+	22, // 46: server.URLShortenerService.GetCampaigns:input_type -> server.GetCampaignsRequest
+	24, // 47: server.URLShortenerService.UpdateCampaign:input_type -> server.UpdateCampaignRequest
+	25, // 48: server.URLShortenerService.DeleteCampaign:input_type -> server.DeleteCampaignRequest
+	26, // 49: server.URLShortenerService.GetCampaignStats:input_type -> server.GetCampaignStatsRequest
+	29, // 50: server.URLShortenerService.CreateAPIKey:input_type -> server.CreateAPIKeyRequest
+	31, // 51: server.URLShortenerService.GetAPIKeys:input_type -> server.GetAPIKeysRequest
+	33, // 52: server.URLShortenerService.DeleteAPIKey:input_type -> server.DeleteAPIKeyRequest
+	34, // 53: server.URLShortenerService.GetShortURLStats:input_type -> server.GetShortURLStatsRequest
+	36, // 54: server.URLShortenerService.GetQRCode:input_type -> server.GetQRCodeRequest
+	38, // 55: server.URLShortenerService.DeleteBatchURLs:input_type -> server.DeleteBatchRequest
+	39, // 56: server.URLShortenerService.GetServiceStats:input_type -> server.ServiceStatsRequest
+	61, // 57: server.URLShortenerService.Ping:input_type -> google.protobuf.Empty
+	43, // 58: server.URLShortenerAdminService.GetShortURL:input_type -> server.AdminGetShortURLRequest
+	44, // 59: server.URLShortenerAdminService.SearchShortURLs:input_type -> server.SearchShortURLsRequest
+	46, // 60: server.URLShortenerAdminService.DisableShortURL:input_type -> server.DisableShortURLRequest
+	47, // 61: server.URLShortenerAdminService.EnableShortURL:input_type -> server.EnableShortURLRequest
+	48, // 62: server.URLShortenerAdminService.BanUser:input_type -> server.BanUserRequest
+	50, // 63: server.URLShortenerAdminService.UnbanUser:input_type -> server.UnbanUserRequest
+	5,  // 64: server.URLShortenerService.CreateShortURL:output_type -> server.ShortenResponse
+	7,  // 65: server.URLShortenerService.BatchCreateShortURL:output_type -> server.BatchShortenResponse
+	11, // 66: server.URLShortenerService.GetUserURLs:output_type -> server.GetUserURLsResponse
+	11, // 67: server.URLShortenerService.GetOrgURLs:output_type -> server.GetUserURLsResponse
+	13, // 68: server.URLShortenerService.UpdateShortURL:output_type -> server.UpdateShortURLResponse
+	14, // 69: server.URLShortenerService.CreateUTMTemplate:output_type -> server.UTMTemplate
+	17, // 70: server.URLShortenerService.GetUTMTemplates:output_type -> server.GetUTMTemplatesResponse
+	14, // 71: server.URLShortenerService.UpdateUTMTemplate:output_type -> server.UTMTemplate
+	61, // 72: server.URLShortenerService.DeleteUTMTemplate:output_type -> google.protobuf.Empty
+	20, // 73: server.URLShortenerService.CreateCampaign:output_type -> server.Campaign
+	23, // 74: server.URLShortenerService.GetCampaigns:output_type -> server.GetCampaignsResponse
+	20, // 75: server.URLShortenerService.UpdateCampaign:output_type -> server.Campaign
+	61, // 76: server.URLShortenerService.DeleteCampaign:output_type -> google.protobuf.Empty
+	27, // 77: server.URLShortenerService.GetCampaignStats:output_type -> server.GetCampaignStatsResponse
+	30, // 78: server.URLShortenerService.CreateAPIKey:output_type -> server.CreateAPIKeyResponse
+	32, // 79: server.URLShortenerService.GetAPIKeys:output_type -> server.GetAPIKeysResponse
+	61, // 80: server.URLShortenerService.DeleteAPIKey:output_type -> google.protobuf.Empty
+	35, // 81: server.URLShortenerService.GetShortURLStats:output_type -> server.GetShortURLStatsResponse
+	37, // 82: server.URLShortenerService.GetQRCode:output_type -> server.GetQRCodeResponse
+	61, // 83: server.URLShortenerService.DeleteBatchURLs:output_type -> google.protobuf.Empty
+	40, // 84: server.URLShortenerService.GetServiceStats:output_type -> server.ServiceStatsResponse
+	61, // 85: server.URLShortenerService.Ping:output_type -> google.protobuf.Empty
+	42, // 86: server.URLShortenerAdminService.GetShortURL:output_type -> server.AdminShortURL
+	45, // 87: server.URLShortenerAdminService.SearchShortURLs:output_type -> server.SearchShortURLsResponse
+	42, // 88: server.URLShortenerAdminService.DisableShortURL:output_type -> server.AdminShortURL
+	42, // 89: server.URLShortenerAdminService.EnableShortURL:output_type -> server.AdminShortURL
+	49, // 90: server.URLShortenerAdminService.BanUser:output_type -> server.UserBan
+	61, // 91: server.URLShortenerAdminService.UnbanUser:output_type -> google.protobuf.Empty
+	64, // [64:92] is the sub-list for method output_type
+	36, // [36:64] is the sub-list for method input_type
+	36, // [36:36] is the sub-list for extension type_name
+	36, // [36:36] is the sub-list for extension extendee
+	0,  // [0:36] is the sub-list for field type_name
 }
 
 func init() { file_proto_shortener_proto_init() }
@@ -704,15 +4093,18 @@ func file_proto_shortener_proto_init() {
 	if File_proto_shortener_proto != nil {
 		return
 	}
+	file_proto_shortener_proto_msgTypes[0].OneofWrappers = []any{}
+	file_proto_shortener_proto_msgTypes[12].OneofWrappers = []any{}
+	file_proto_shortener_proto_msgTypes[57].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_shortener_proto_rawDesc), len(file_proto_shortener_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   60,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_proto_shortener_proto_goTypes,
 		DependencyIndexes: file_proto_shortener_proto_depIdxs,
@@ -24,6 +24,22 @@ const (
 	URLShortenerService_CreateShortURL_FullMethodName      = "/server.URLShortenerService/CreateShortURL"
 	URLShortenerService_BatchCreateShortURL_FullMethodName = "/server.URLShortenerService/BatchCreateShortURL"
 	URLShortenerService_GetUserURLs_FullMethodName         = "/server.URLShortenerService/GetUserURLs"
+	URLShortenerService_GetOrgURLs_FullMethodName          = "/server.URLShortenerService/GetOrgURLs"
+	URLShortenerService_UpdateShortURL_FullMethodName      = "/server.URLShortenerService/UpdateShortURL"
+	URLShortenerService_CreateUTMTemplate_FullMethodName   = "/server.URLShortenerService/CreateUTMTemplate"
+	URLShortenerService_GetUTMTemplates_FullMethodName     = "/server.URLShortenerService/GetUTMTemplates"
+	URLShortenerService_UpdateUTMTemplate_FullMethodName   = "/server.URLShortenerService/UpdateUTMTemplate"
+	URLShortenerService_DeleteUTMTemplate_FullMethodName   = "/server.URLShortenerService/DeleteUTMTemplate"
+	URLShortenerService_CreateCampaign_FullMethodName      = "/server.URLShortenerService/CreateCampaign"
+	URLShortenerService_GetCampaigns_FullMethodName        = "/server.URLShortenerService/GetCampaigns"
+	URLShortenerService_UpdateCampaign_FullMethodName      = "/server.URLShortenerService/UpdateCampaign"
+	URLShortenerService_DeleteCampaign_FullMethodName      = "/server.URLShortenerService/DeleteCampaign"
+	URLShortenerService_GetCampaignStats_FullMethodName    = "/server.URLShortenerService/GetCampaignStats"
+	URLShortenerService_CreateAPIKey_FullMethodName        = "/server.URLShortenerService/CreateAPIKey"
+	URLShortenerService_GetAPIKeys_FullMethodName          = "/server.URLShortenerService/GetAPIKeys"
+	URLShortenerService_DeleteAPIKey_FullMethodName        = "/server.URLShortenerService/DeleteAPIKey"
+	URLShortenerService_GetShortURLStats_FullMethodName    = "/server.URLShortenerService/GetShortURLStats"
+	URLShortenerService_GetQRCode_FullMethodName           = "/server.URLShortenerService/GetQRCode"
 	URLShortenerService_DeleteBatchURLs_FullMethodName     = "/server.URLShortenerService/DeleteBatchURLs"
 	URLShortenerService_GetServiceStats_FullMethodName     = "/server.URLShortenerService/GetServiceStats"
 	URLShortenerService_Ping_FullMethodName                = "/server.URLShortenerService/Ping"
@@ -33,7 +49,10 @@ const (
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 //
-// Service for working with short URLs
+// Service for working with short URLs.
+// The calls are authorized with the bearer token in the metadata: the JWT from the auth cookie or the personal
+// API key act on behalf of their owner, the user_id fields may be left empty. The user_id fields are honoured
+// only for the shared admin token.
 type URLShortenerServiceClient interface {
 	// Create a short URL
 	CreateShortURL(ctx context.Context, in *ShortenRequest, opts ...grpc.CallOption) (*ShortenResponse, error)
@@ -41,6 +60,38 @@ type URLShortenerServiceClient interface {
 	BatchCreateShortURL(ctx context.Context, in *BatchShortenRequest, opts ...grpc.CallOption) (*BatchShortenResponse, error)
 	// Retrieve all user URLs
 	GetUserURLs(ctx context.Context, in *GetUserURLsRequest, opts ...grpc.CallOption) (*GetUserURLsResponse, error)
+	// Retrieve all the URLs of an organization
+	GetOrgURLs(ctx context.Context, in *GetOrgURLsRequest, opts ...grpc.CallOption) (*GetUserURLsResponse, error)
+	// Update the title, notes, tags and redirect attributes of a short URL
+	UpdateShortURL(ctx context.Context, in *UpdateShortURLRequest, opts ...grpc.CallOption) (*UpdateShortURLResponse, error)
+	// Create a UTM template
+	CreateUTMTemplate(ctx context.Context, in *CreateUTMTemplateRequest, opts ...grpc.CallOption) (*UTMTemplate, error)
+	// Retrieve all user UTM templates
+	GetUTMTemplates(ctx context.Context, in *GetUTMTemplatesRequest, opts ...grpc.CallOption) (*GetUTMTemplatesResponse, error)
+	// Replace the name and the parameters of a UTM template
+	UpdateUTMTemplate(ctx context.Context, in *UpdateUTMTemplateRequest, opts ...grpc.CallOption) (*UTMTemplate, error)
+	// Delete a UTM template, the short URLs using it are detached
+	DeleteUTMTemplate(ctx context.Context, in *DeleteUTMTemplateRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Create a campaign
+	CreateCampaign(ctx context.Context, in *CreateCampaignRequest, opts ...grpc.CallOption) (*Campaign, error)
+	// Retrieve all user campaigns
+	GetCampaigns(ctx context.Context, in *GetCampaignsRequest, opts ...grpc.CallOption) (*GetCampaignsResponse, error)
+	// Rename a campaign
+	UpdateCampaign(ctx context.Context, in *UpdateCampaignRequest, opts ...grpc.CallOption) (*Campaign, error)
+	// Delete a campaign, its short URLs are scheduled for the deletion
+	DeleteCampaign(ctx context.Context, in *DeleteCampaignRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Retrieve the totals of a campaign
+	GetCampaignStats(ctx context.Context, in *GetCampaignStatsRequest, opts ...grpc.CallOption) (*GetCampaignStatsResponse, error)
+	// Create a personal API key
+	CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*CreateAPIKeyResponse, error)
+	// Retrieve all user API keys
+	GetAPIKeys(ctx context.Context, in *GetAPIKeysRequest, opts ...grpc.CallOption) (*GetAPIKeysResponse, error)
+	// Revoke an API key
+	DeleteAPIKey(ctx context.Context, in *DeleteAPIKeyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Retrieve the clicks on the split variants of a short URL
+	GetShortURLStats(ctx context.Context, in *GetShortURLStatsRequest, opts ...grpc.CallOption) (*GetShortURLStatsResponse, error)
+	// Render the QR code image of a short URL
+	GetQRCode(ctx context.Context, in *GetQRCodeRequest, opts ...grpc.CallOption) (*GetQRCodeResponse, error)
 	// Delete multiple URLs in a batch
 	DeleteBatchURLs(ctx context.Context, in *DeleteBatchRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// Retrieve service statistics
@@ -87,6 +138,166 @@ func (c *uRLShortenerServiceClient) GetUserURLs(ctx context.Context, in *GetUser
 	return out, nil
 }
 
+func (c *uRLShortenerServiceClient) GetOrgURLs(ctx context.Context, in *GetOrgURLsRequest, opts ...grpc.CallOption) (*GetUserURLsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserURLsResponse)
+	err := c.cc.Invoke(ctx, URLShortenerService_GetOrgURLs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) UpdateShortURL(ctx context.Context, in *UpdateShortURLRequest, opts ...grpc.CallOption) (*UpdateShortURLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateShortURLResponse)
+	err := c.cc.Invoke(ctx, URLShortenerService_UpdateShortURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) CreateUTMTemplate(ctx context.Context, in *CreateUTMTemplateRequest, opts ...grpc.CallOption) (*UTMTemplate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UTMTemplate)
+	err := c.cc.Invoke(ctx, URLShortenerService_CreateUTMTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) GetUTMTemplates(ctx context.Context, in *GetUTMTemplatesRequest, opts ...grpc.CallOption) (*GetUTMTemplatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUTMTemplatesResponse)
+	err := c.cc.Invoke(ctx, URLShortenerService_GetUTMTemplates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) UpdateUTMTemplate(ctx context.Context, in *UpdateUTMTemplateRequest, opts ...grpc.CallOption) (*UTMTemplate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UTMTemplate)
+	err := c.cc.Invoke(ctx, URLShortenerService_UpdateUTMTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) DeleteUTMTemplate(ctx context.Context, in *DeleteUTMTemplateRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, URLShortenerService_DeleteUTMTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) CreateCampaign(ctx context.Context, in *CreateCampaignRequest, opts ...grpc.CallOption) (*Campaign, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Campaign)
+	err := c.cc.Invoke(ctx, URLShortenerService_CreateCampaign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) GetCampaigns(ctx context.Context, in *GetCampaignsRequest, opts ...grpc.CallOption) (*GetCampaignsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCampaignsResponse)
+	err := c.cc.Invoke(ctx, URLShortenerService_GetCampaigns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) UpdateCampaign(ctx context.Context, in *UpdateCampaignRequest, opts ...grpc.CallOption) (*Campaign, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Campaign)
+	err := c.cc.Invoke(ctx, URLShortenerService_UpdateCampaign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) DeleteCampaign(ctx context.Context, in *DeleteCampaignRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, URLShortenerService_DeleteCampaign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) GetCampaignStats(ctx context.Context, in *GetCampaignStatsRequest, opts ...grpc.CallOption) (*GetCampaignStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCampaignStatsResponse)
+	err := c.cc.Invoke(ctx, URLShortenerService_GetCampaignStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*CreateAPIKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateAPIKeyResponse)
+	err := c.cc.Invoke(ctx, URLShortenerService_CreateAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) GetAPIKeys(ctx context.Context, in *GetAPIKeysRequest, opts ...grpc.CallOption) (*GetAPIKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAPIKeysResponse)
+	err := c.cc.Invoke(ctx, URLShortenerService_GetAPIKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) DeleteAPIKey(ctx context.Context, in *DeleteAPIKeyRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, URLShortenerService_DeleteAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) GetShortURLStats(ctx context.Context, in *GetShortURLStatsRequest, opts ...grpc.CallOption) (*GetShortURLStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetShortURLStatsResponse)
+	err := c.cc.Invoke(ctx, URLShortenerService_GetShortURLStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerServiceClient) GetQRCode(ctx context.Context, in *GetQRCodeRequest, opts ...grpc.CallOption) (*GetQRCodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetQRCodeResponse)
+	err := c.cc.Invoke(ctx, URLShortenerService_GetQRCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *uRLShortenerServiceClient) DeleteBatchURLs(ctx context.Context, in *DeleteBatchRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -121,7 +332,10 @@ func (c *uRLShortenerServiceClient) Ping(ctx context.Context, in *emptypb.Empty,
 // All implementations must embed UnimplementedURLShortenerServiceServer
 // for forward compatibility.
 //
-// Service for working with short URLs
+// Service for working with short URLs.
+// The calls are authorized with the bearer token in the metadata: the JWT from the auth cookie or the personal
+// API key act on behalf of their owner, the user_id fields may be left empty. The user_id fields are honoured
+// only for the shared admin token.
 type URLShortenerServiceServer interface {
 	// Create a short URL
 	CreateShortURL(context.Context, *ShortenRequest) (*ShortenResponse, error)
@@ -129,6 +343,38 @@ type URLShortenerServiceServer interface {
 	BatchCreateShortURL(context.Context, *BatchShortenRequest) (*BatchShortenResponse, error)
 	// Retrieve all user URLs
 	GetUserURLs(context.Context, *GetUserURLsRequest) (*GetUserURLsResponse, error)
+	// Retrieve all the URLs of an organization
+	GetOrgURLs(context.Context, *GetOrgURLsRequest) (*GetUserURLsResponse, error)
+	// Update the title, notes, tags and redirect attributes of a short URL
+	UpdateShortURL(context.Context, *UpdateShortURLRequest) (*UpdateShortURLResponse, error)
+	// Create a UTM template
+	CreateUTMTemplate(context.Context, *CreateUTMTemplateRequest) (*UTMTemplate, error)
+	// Retrieve all user UTM templates
+	GetUTMTemplates(context.Context, *GetUTMTemplatesRequest) (*GetUTMTemplatesResponse, error)
+	// Replace the name and the parameters of a UTM template
+	UpdateUTMTemplate(context.Context, *UpdateUTMTemplateRequest) (*UTMTemplate, error)
+	// Delete a UTM template, the short URLs using it are detached
+	DeleteUTMTemplate(context.Context, *DeleteUTMTemplateRequest) (*emptypb.Empty, error)
+	// Create a campaign
+	CreateCampaign(context.Context, *CreateCampaignRequest) (*Campaign, error)
+	// Retrieve all user campaigns
+	GetCampaigns(context.Context, *GetCampaignsRequest) (*GetCampaignsResponse, error)
+	// Rename a campaign
+	UpdateCampaign(context.Context, *UpdateCampaignRequest) (*Campaign, error)
+	// Delete a campaign, its short URLs are scheduled for the deletion
+	DeleteCampaign(context.Context, *DeleteCampaignRequest) (*emptypb.Empty, error)
+	// Retrieve the totals of a campaign
+	GetCampaignStats(context.Context, *GetCampaignStatsRequest) (*GetCampaignStatsResponse, error)
+	// Create a personal API key
+	CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error)
+	// Retrieve all user API keys
+	GetAPIKeys(context.Context, *GetAPIKeysRequest) (*GetAPIKeysResponse, error)
+	// Revoke an API key
+	DeleteAPIKey(context.Context, *DeleteAPIKeyRequest) (*emptypb.Empty, error)
+	// Retrieve the clicks on the split variants of a short URL
+	GetShortURLStats(context.Context, *GetShortURLStatsRequest) (*GetShortURLStatsResponse, error)
+	// Render the QR code image of a short URL
+	GetQRCode(context.Context, *GetQRCodeRequest) (*GetQRCodeResponse, error)
 	// Delete multiple URLs in a batch
 	DeleteBatchURLs(context.Context, *DeleteBatchRequest) (*emptypb.Empty, error)
 	// Retrieve service statistics
@@ -154,6 +400,54 @@ func (UnimplementedURLShortenerServiceServer) BatchCreateShortURL(context.Contex
 func (UnimplementedURLShortenerServiceServer) GetUserURLs(context.Context, *GetUserURLsRequest) (*GetUserURLsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserURLs not implemented")
 }
+func (UnimplementedURLShortenerServiceServer) GetOrgURLs(context.Context, *GetOrgURLsRequest) (*GetUserURLsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrgURLs not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) UpdateShortURL(context.Context, *UpdateShortURLRequest) (*UpdateShortURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateShortURL not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) CreateUTMTemplate(context.Context, *CreateUTMTemplateRequest) (*UTMTemplate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUTMTemplate not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) GetUTMTemplates(context.Context, *GetUTMTemplatesRequest) (*GetUTMTemplatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUTMTemplates not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) UpdateUTMTemplate(context.Context, *UpdateUTMTemplateRequest) (*UTMTemplate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUTMTemplate not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) DeleteUTMTemplate(context.Context, *DeleteUTMTemplateRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteUTMTemplate not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) CreateCampaign(context.Context, *CreateCampaignRequest) (*Campaign, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCampaign not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) GetCampaigns(context.Context, *GetCampaignsRequest) (*GetCampaignsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCampaigns not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) UpdateCampaign(context.Context, *UpdateCampaignRequest) (*Campaign, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCampaign not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) DeleteCampaign(context.Context, *DeleteCampaignRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCampaign not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) GetCampaignStats(context.Context, *GetCampaignStatsRequest) (*GetCampaignStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCampaignStats not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAPIKey not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) GetAPIKeys(context.Context, *GetAPIKeysRequest) (*GetAPIKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAPIKeys not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) DeleteAPIKey(context.Context, *DeleteAPIKeyRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAPIKey not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) GetShortURLStats(context.Context, *GetShortURLStatsRequest) (*GetShortURLStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShortURLStats not implemented")
+}
+func (UnimplementedURLShortenerServiceServer) GetQRCode(context.Context, *GetQRCodeRequest) (*GetQRCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQRCode not implemented")
+}
 func (UnimplementedURLShortenerServiceServer) DeleteBatchURLs(context.Context, *DeleteBatchRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteBatchURLs not implemented")
 }
@@ -238,90 +532,751 @@ func _URLShortenerService_GetUserURLs_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
-func _URLShortenerService_DeleteBatchURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteBatchRequest)
+func _URLShortenerService_GetOrgURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrgURLsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(URLShortenerServiceServer).DeleteBatchURLs(ctx, in)
+		return srv.(URLShortenerServiceServer).GetOrgURLs(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: URLShortenerService_DeleteBatchURLs_FullMethodName,
+		FullMethod: URLShortenerService_GetOrgURLs_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(URLShortenerServiceServer).DeleteBatchURLs(ctx, req.(*DeleteBatchRequest))
+		return srv.(URLShortenerServiceServer).GetOrgURLs(ctx, req.(*GetOrgURLsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _URLShortenerService_GetServiceStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ServiceStatsRequest)
+func _URLShortenerService_UpdateShortURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateShortURLRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(URLShortenerServiceServer).GetServiceStats(ctx, in)
+		return srv.(URLShortenerServiceServer).UpdateShortURL(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: URLShortenerService_GetServiceStats_FullMethodName,
+		FullMethod: URLShortenerService_UpdateShortURL_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(URLShortenerServiceServer).GetServiceStats(ctx, req.(*ServiceStatsRequest))
+		return srv.(URLShortenerServiceServer).UpdateShortURL(ctx, req.(*UpdateShortURLRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _URLShortenerService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+func _URLShortenerService_CreateUTMTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUTMTemplateRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(URLShortenerServiceServer).Ping(ctx, in)
+		return srv.(URLShortenerServiceServer).CreateUTMTemplate(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: URLShortenerService_Ping_FullMethodName,
+		FullMethod: URLShortenerService_CreateUTMTemplate_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(URLShortenerServiceServer).Ping(ctx, req.(*emptypb.Empty))
+		return srv.(URLShortenerServiceServer).CreateUTMTemplate(ctx, req.(*CreateUTMTemplateRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// URLShortenerService_ServiceDesc is the grpc.ServiceDesc for URLShortenerService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var URLShortenerService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "server.URLShortenerService",
-	HandlerType: (*URLShortenerServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "CreateShortURL",
-			Handler:    _URLShortenerService_CreateShortURL_Handler,
-		},
-		{
-			MethodName: "BatchCreateShortURL",
-			Handler:    _URLShortenerService_BatchCreateShortURL_Handler,
-		},
-		{
-			MethodName: "GetUserURLs",
-			Handler:    _URLShortenerService_GetUserURLs_Handler,
-		},
-		{
-			MethodName: "DeleteBatchURLs",
-			Handler:    _URLShortenerService_DeleteBatchURLs_Handler,
-		},
-		{
-			MethodName: "GetServiceStats",
-			Handler:    _URLShortenerService_GetServiceStats_Handler,
-		},
-		{
-			MethodName: "Ping",
-			Handler:    _URLShortenerService_Ping_Handler,
+func _URLShortenerService_GetUTMTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUTMTemplatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).GetUTMTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_GetUTMTemplates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).GetUTMTemplates(ctx, req.(*GetUTMTemplatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_UpdateUTMTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUTMTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).UpdateUTMTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_UpdateUTMTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).UpdateUTMTemplate(ctx, req.(*UpdateUTMTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_DeleteUTMTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteUTMTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).DeleteUTMTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_DeleteUTMTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).DeleteUTMTemplate(ctx, req.(*DeleteUTMTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_CreateCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).CreateCampaign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_CreateCampaign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).CreateCampaign(ctx, req.(*CreateCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_GetCampaigns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCampaignsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).GetCampaigns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_GetCampaigns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).GetCampaigns(ctx, req.(*GetCampaignsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_UpdateCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).UpdateCampaign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_UpdateCampaign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).UpdateCampaign(ctx, req.(*UpdateCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_DeleteCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).DeleteCampaign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_DeleteCampaign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).DeleteCampaign(ctx, req.(*DeleteCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_GetCampaignStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCampaignStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).GetCampaignStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_GetCampaignStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).GetCampaignStats(ctx, req.(*GetCampaignStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_CreateAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).CreateAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_CreateAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).CreateAPIKey(ctx, req.(*CreateAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_GetAPIKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAPIKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).GetAPIKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_GetAPIKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).GetAPIKeys(ctx, req.(*GetAPIKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_DeleteAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).DeleteAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_DeleteAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).DeleteAPIKey(ctx, req.(*DeleteAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_GetShortURLStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetShortURLStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).GetShortURLStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_GetShortURLStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).GetShortURLStats(ctx, req.(*GetShortURLStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_GetQRCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQRCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).GetQRCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_GetQRCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).GetQRCode(ctx, req.(*GetQRCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_DeleteBatchURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).DeleteBatchURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_DeleteBatchURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).DeleteBatchURLs(ctx, req.(*DeleteBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_GetServiceStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServiceStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).GetServiceStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_GetServiceStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).GetServiceStats(ctx, req.(*ServiceStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServiceServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerService_Ping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServiceServer).Ping(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// URLShortenerService_ServiceDesc is the grpc.ServiceDesc for URLShortenerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var URLShortenerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "server.URLShortenerService",
+	HandlerType: (*URLShortenerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateShortURL",
+			Handler:    _URLShortenerService_CreateShortURL_Handler,
+		},
+		{
+			MethodName: "BatchCreateShortURL",
+			Handler:    _URLShortenerService_BatchCreateShortURL_Handler,
+		},
+		{
+			MethodName: "GetUserURLs",
+			Handler:    _URLShortenerService_GetUserURLs_Handler,
+		},
+		{
+			MethodName: "GetOrgURLs",
+			Handler:    _URLShortenerService_GetOrgURLs_Handler,
+		},
+		{
+			MethodName: "UpdateShortURL",
+			Handler:    _URLShortenerService_UpdateShortURL_Handler,
+		},
+		{
+			MethodName: "CreateUTMTemplate",
+			Handler:    _URLShortenerService_CreateUTMTemplate_Handler,
+		},
+		{
+			MethodName: "GetUTMTemplates",
+			Handler:    _URLShortenerService_GetUTMTemplates_Handler,
+		},
+		{
+			MethodName: "UpdateUTMTemplate",
+			Handler:    _URLShortenerService_UpdateUTMTemplate_Handler,
+		},
+		{
+			MethodName: "DeleteUTMTemplate",
+			Handler:    _URLShortenerService_DeleteUTMTemplate_Handler,
+		},
+		{
+			MethodName: "CreateCampaign",
+			Handler:    _URLShortenerService_CreateCampaign_Handler,
+		},
+		{
+			MethodName: "GetCampaigns",
+			Handler:    _URLShortenerService_GetCampaigns_Handler,
+		},
+		{
+			MethodName: "UpdateCampaign",
+			Handler:    _URLShortenerService_UpdateCampaign_Handler,
+		},
+		{
+			MethodName: "DeleteCampaign",
+			Handler:    _URLShortenerService_DeleteCampaign_Handler,
+		},
+		{
+			MethodName: "GetCampaignStats",
+			Handler:    _URLShortenerService_GetCampaignStats_Handler,
+		},
+		{
+			MethodName: "CreateAPIKey",
+			Handler:    _URLShortenerService_CreateAPIKey_Handler,
+		},
+		{
+			MethodName: "GetAPIKeys",
+			Handler:    _URLShortenerService_GetAPIKeys_Handler,
+		},
+		{
+			MethodName: "DeleteAPIKey",
+			Handler:    _URLShortenerService_DeleteAPIKey_Handler,
+		},
+		{
+			MethodName: "GetShortURLStats",
+			Handler:    _URLShortenerService_GetShortURLStats_Handler,
+		},
+		{
+			MethodName: "GetQRCode",
+			Handler:    _URLShortenerService_GetQRCode_Handler,
+		},
+		{
+			MethodName: "DeleteBatchURLs",
+			Handler:    _URLShortenerService_DeleteBatchURLs_Handler,
+		},
+		{
+			MethodName: "GetServiceStats",
+			Handler:    _URLShortenerService_GetServiceStats_Handler,
+		},
+		{
+			MethodName: "Ping",
+			Handler:    _URLShortenerService_Ping_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/shortener.proto",
+}
+
+const (
+	URLShortenerAdminService_GetShortURL_FullMethodName     = "/server.URLShortenerAdminService/GetShortURL"
+	URLShortenerAdminService_SearchShortURLs_FullMethodName = "/server.URLShortenerAdminService/SearchShortURLs"
+	URLShortenerAdminService_DisableShortURL_FullMethodName = "/server.URLShortenerAdminService/DisableShortURL"
+	URLShortenerAdminService_EnableShortURL_FullMethodName  = "/server.URLShortenerAdminService/EnableShortURL"
+	URLShortenerAdminService_BanUser_FullMethodName         = "/server.URLShortenerAdminService/BanUser"
+	URLShortenerAdminService_UnbanUser_FullMethodName       = "/server.URLShortenerAdminService/UnbanUser"
+)
+
+// URLShortenerAdminServiceClient is the client API for URLShortenerAdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Service for moderating the short URLs and the users, available to the admin holding the shared token only.
+type URLShortenerAdminServiceClient interface {
+	// Retrieve the short URL of any user along with its moderation state
+	GetShortURL(ctx context.Context, in *AdminGetShortURLRequest, opts ...grpc.CallOption) (*AdminShortURL, error)
+	// Search the short URLs of all the users by the destination, its domain or the author
+	SearchShortURLs(ctx context.Context, in *SearchShortURLsRequest, opts ...grpc.CallOption) (*SearchShortURLsResponse, error)
+	// Block the redirects of a short URL
+	DisableShortURL(ctx context.Context, in *DisableShortURLRequest, opts ...grpc.CallOption) (*AdminShortURL, error)
+	// Let the disabled short URL redirect again
+	EnableShortURL(ctx context.Context, in *EnableShortURLRequest, opts ...grpc.CallOption) (*AdminShortURL, error)
+	// Ban a user: the user can't create the short URLs and the user's short URLs don't redirect
+	BanUser(ctx context.Context, in *BanUserRequest, opts ...grpc.CallOption) (*UserBan, error)
+	// Lift the ban of a user
+	UnbanUser(ctx context.Context, in *UnbanUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type uRLShortenerAdminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewURLShortenerAdminServiceClient(cc grpc.ClientConnInterface) URLShortenerAdminServiceClient {
+	return &uRLShortenerAdminServiceClient{cc}
+}
+
+func (c *uRLShortenerAdminServiceClient) GetShortURL(ctx context.Context, in *AdminGetShortURLRequest, opts ...grpc.CallOption) (*AdminShortURL, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminShortURL)
+	err := c.cc.Invoke(ctx, URLShortenerAdminService_GetShortURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerAdminServiceClient) SearchShortURLs(ctx context.Context, in *SearchShortURLsRequest, opts ...grpc.CallOption) (*SearchShortURLsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchShortURLsResponse)
+	err := c.cc.Invoke(ctx, URLShortenerAdminService_SearchShortURLs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerAdminServiceClient) DisableShortURL(ctx context.Context, in *DisableShortURLRequest, opts ...grpc.CallOption) (*AdminShortURL, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminShortURL)
+	err := c.cc.Invoke(ctx, URLShortenerAdminService_DisableShortURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerAdminServiceClient) EnableShortURL(ctx context.Context, in *EnableShortURLRequest, opts ...grpc.CallOption) (*AdminShortURL, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminShortURL)
+	err := c.cc.Invoke(ctx, URLShortenerAdminService_EnableShortURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerAdminServiceClient) BanUser(ctx context.Context, in *BanUserRequest, opts ...grpc.CallOption) (*UserBan, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserBan)
+	err := c.cc.Invoke(ctx, URLShortenerAdminService_BanUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerAdminServiceClient) UnbanUser(ctx context.Context, in *UnbanUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, URLShortenerAdminService_UnbanUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// URLShortenerAdminServiceServer is the server API for URLShortenerAdminService service.
+// All implementations must embed UnimplementedURLShortenerAdminServiceServer
+// for forward compatibility.
+//
+// Service for moderating the short URLs and the users, available to the admin holding the shared token only.
+type URLShortenerAdminServiceServer interface {
+	// Retrieve the short URL of any user along with its moderation state
+	GetShortURL(context.Context, *AdminGetShortURLRequest) (*AdminShortURL, error)
+	// Search the short URLs of all the users by the destination, its domain or the author
+	SearchShortURLs(context.Context, *SearchShortURLsRequest) (*SearchShortURLsResponse, error)
+	// Block the redirects of a short URL
+	DisableShortURL(context.Context, *DisableShortURLRequest) (*AdminShortURL, error)
+	// Let the disabled short URL redirect again
+	EnableShortURL(context.Context, *EnableShortURLRequest) (*AdminShortURL, error)
+	// Ban a user: the user can't create the short URLs and the user's short URLs don't redirect
+	BanUser(context.Context, *BanUserRequest) (*UserBan, error)
+	// Lift the ban of a user
+	UnbanUser(context.Context, *UnbanUserRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedURLShortenerAdminServiceServer()
+}
+
+// UnimplementedURLShortenerAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedURLShortenerAdminServiceServer struct{}
+
+func (UnimplementedURLShortenerAdminServiceServer) GetShortURL(context.Context, *AdminGetShortURLRequest) (*AdminShortURL, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShortURL not implemented")
+}
+func (UnimplementedURLShortenerAdminServiceServer) SearchShortURLs(context.Context, *SearchShortURLsRequest) (*SearchShortURLsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchShortURLs not implemented")
+}
+func (UnimplementedURLShortenerAdminServiceServer) DisableShortURL(context.Context, *DisableShortURLRequest) (*AdminShortURL, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableShortURL not implemented")
+}
+func (UnimplementedURLShortenerAdminServiceServer) EnableShortURL(context.Context, *EnableShortURLRequest) (*AdminShortURL, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnableShortURL not implemented")
+}
+func (UnimplementedURLShortenerAdminServiceServer) BanUser(context.Context, *BanUserRequest) (*UserBan, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BanUser not implemented")
+}
+func (UnimplementedURLShortenerAdminServiceServer) UnbanUser(context.Context, *UnbanUserRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnbanUser not implemented")
+}
+func (UnimplementedURLShortenerAdminServiceServer) mustEmbedUnimplementedURLShortenerAdminServiceServer() {
+}
+func (UnimplementedURLShortenerAdminServiceServer) testEmbeddedByValue() {}
+
+// UnsafeURLShortenerAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to URLShortenerAdminServiceServer will
+// result in compilation errors.
+type UnsafeURLShortenerAdminServiceServer interface {
+	mustEmbedUnimplementedURLShortenerAdminServiceServer()
+}
+
+func RegisterURLShortenerAdminServiceServer(s grpc.ServiceRegistrar, srv URLShortenerAdminServiceServer) {
+	// If the following call pancis, it indicates UnimplementedURLShortenerAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&URLShortenerAdminService_ServiceDesc, srv)
+}
+
+func _URLShortenerAdminService_GetShortURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminGetShortURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerAdminServiceServer).GetShortURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerAdminService_GetShortURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerAdminServiceServer).GetShortURL(ctx, req.(*AdminGetShortURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerAdminService_SearchShortURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchShortURLsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerAdminServiceServer).SearchShortURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerAdminService_SearchShortURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerAdminServiceServer).SearchShortURLs(ctx, req.(*SearchShortURLsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerAdminService_DisableShortURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableShortURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerAdminServiceServer).DisableShortURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerAdminService_DisableShortURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerAdminServiceServer).DisableShortURL(ctx, req.(*DisableShortURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerAdminService_EnableShortURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnableShortURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerAdminServiceServer).EnableShortURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerAdminService_EnableShortURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerAdminServiceServer).EnableShortURL(ctx, req.(*EnableShortURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerAdminService_BanUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerAdminServiceServer).BanUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerAdminService_BanUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerAdminServiceServer).BanUser(ctx, req.(*BanUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortenerAdminService_UnbanUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnbanUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerAdminServiceServer).UnbanUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortenerAdminService_UnbanUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerAdminServiceServer).UnbanUser(ctx, req.(*UnbanUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// URLShortenerAdminService_ServiceDesc is the grpc.ServiceDesc for URLShortenerAdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var URLShortenerAdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "server.URLShortenerAdminService",
+	HandlerType: (*URLShortenerAdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetShortURL",
+			Handler:    _URLShortenerAdminService_GetShortURL_Handler,
+		},
+		{
+			MethodName: "SearchShortURLs",
+			Handler:    _URLShortenerAdminService_SearchShortURLs_Handler,
+		},
+		{
+			MethodName: "DisableShortURL",
+			Handler:    _URLShortenerAdminService_DisableShortURL_Handler,
+		},
+		{
+			MethodName: "EnableShortURL",
+			Handler:    _URLShortenerAdminService_EnableShortURL_Handler,
+		},
+		{
+			MethodName: "BanUser",
+			Handler:    _URLShortenerAdminService_BanUser_Handler,
+		},
+		{
+			MethodName: "UnbanUser",
+			Handler:    _URLShortenerAdminService_UnbanUser_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
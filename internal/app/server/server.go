@@ -22,9 +22,11 @@ import (
 	"github.com/pressly/goose"
 
 	"github.com/clearthree/url-shortener/internal/app/config"
+	"github.com/clearthree/url-shortener/internal/app/geoip"
 	"github.com/clearthree/url-shortener/internal/app/handlers"
 	"github.com/clearthree/url-shortener/internal/app/logger"
 	"github.com/clearthree/url-shortener/internal/app/middlewares"
+	"github.com/clearthree/url-shortener/internal/app/oidc"
 	"github.com/clearthree/url-shortener/internal/app/server/proto"
 	"github.com/clearthree/url-shortener/internal/app/service"
 	"github.com/clearthree/url-shortener/internal/app/storage"
@@ -40,29 +42,116 @@ func ShortenURLRouter(shortURLService service.ShortURLServiceInterface) chi.Rout
 	var createHandler = handlers.NewCreateShortURLHandler(shortURLService)
 	var createJSONShortURLHandler = handlers.NewCreateJSONShortURLHandler(shortURLService)
 	var redirectHandler = handlers.NewRedirectToOriginalURLHandler(shortURLService)
+	var previewHandler = handlers.NewPreviewShortURLHandler(shortURLService)
+	var unlockHandler = handlers.NewUnlockShortURLHandler(shortURLService)
 	var pingHandler = handlers.NewPingHandler(shortURLService)
 	var batchCreateHandler = handlers.NewBatchCreateShortURLHandler(shortURLService)
 	var getAllUrlsByUserHandler = handlers.NewGetAllURLsForUserHandler(shortURLService)
+	var updateShortURLHandler = handlers.NewUpdateShortURLHandler(shortURLService)
 	var deleteBatchOfURLsHandler = handlers.NewDeleteBatchOfURLsHandler(shortURLService)
 	var getStatsHandler = handlers.NewGetStatsHandler(shortURLService)
+	var adminGetShortURLHandler = handlers.NewAdminGetShortURLHandler(shortURLService)
+	var searchShortURLsHandler = handlers.NewSearchShortURLsHandler(shortURLService)
+	var adminGetUserURLsHandler = handlers.NewAdminGetUserURLsHandler(shortURLService)
+	var disableShortURLHandler = handlers.NewDisableShortURLHandler(shortURLService)
+	var enableShortURLHandler = handlers.NewEnableShortURLHandler(shortURLService)
+	var banUserHandler = handlers.NewBanUserHandler(shortURLService)
+	var unbanUserHandler = handlers.NewUnbanUserHandler(shortURLService)
+	var getAuditLogHandler = handlers.NewGetAuditLogHandler(shortURLService)
+	var verifyAuditLogHandler = handlers.NewVerifyAuditLogHandler(shortURLService)
+	var createUTMTemplateHandler = handlers.NewCreateUTMTemplateHandler(shortURLService)
+	var getUTMTemplatesHandler = handlers.NewGetUTMTemplatesHandler(shortURLService)
+	var updateUTMTemplateHandler = handlers.NewUpdateUTMTemplateHandler(shortURLService)
+	var deleteUTMTemplateHandler = handlers.NewDeleteUTMTemplateHandler(shortURLService)
+	var createCampaignHandler = handlers.NewCreateCampaignHandler(shortURLService)
+	var getCampaignsHandler = handlers.NewGetCampaignsHandler(shortURLService)
+	var updateCampaignHandler = handlers.NewUpdateCampaignHandler(shortURLService)
+	var deleteCampaignHandler = handlers.NewDeleteCampaignHandler(shortURLService)
+	var getCampaignStatsHandler = handlers.NewGetCampaignStatsHandler(shortURLService)
+	var createOrganizationHandler = handlers.NewCreateOrganizationHandler(shortURLService)
+	var getOrganizationsHandler = handlers.NewGetOrganizationsHandler(shortURLService)
+	var getOrgMembersHandler = handlers.NewGetOrgMembersHandler(shortURLService)
+	var addOrgMemberHandler = handlers.NewAddOrgMemberHandler(shortURLService)
+	var updateOrgMemberHandler = handlers.NewUpdateOrgMemberHandler(shortURLService)
+	var removeOrgMemberHandler = handlers.NewRemoveOrgMemberHandler(shortURLService)
+	var getOrgURLsHandler = handlers.NewGetOrgURLsHandler(shortURLService)
+	var registerHandler = handlers.NewRegisterHandler(shortURLService)
+	var loginHandler = handlers.NewLoginHandler(shortURLService)
+	var logoutHandler = handlers.NewLogoutHandler(shortURLService)
+	var logoutEverywhereHandler = handlers.NewLogoutEverywhereHandler(shortURLService)
+	var oidcLoginHandler = handlers.NewOIDCLoginHandler()
+	var oidcCallbackHandler = handlers.NewOIDCCallbackHandler(shortURLService)
+	var jwksHandler = handlers.NewJWKSHandler()
+	var createAPIKeyHandler = handlers.NewCreateAPIKeyHandler(shortURLService)
+	var getAPIKeysHandler = handlers.NewGetAPIKeysHandler(shortURLService)
+	var deleteAPIKeyHandler = handlers.NewDeleteAPIKeyHandler(shortURLService)
+	var getShortURLStatsHandler = handlers.NewGetShortURLStatsHandler(shortURLService)
+	var qrCodeHandler = handlers.NewQRCodeHandler(shortURLService)
 
 	router := chi.NewRouter()
 	router.Use(middlewares.RequestLogger)
-	router.Use(middlewares.AuthMiddleware)
+	router.Use(middlewares.AuthMiddleware(shortURLService))
+	router.Use(middlewares.ActorMiddleware)
 	router.Use(middlewares.GzipMiddleware)
 	router.Use(middleware.Recoverer)
 	router.Post("/", createHandler.ServeHTTP)
 	router.Post("/api/shorten", createJSONShortURLHandler.ServeHTTP)
 	router.Post("/api/shorten/batch", batchCreateHandler.ServeHTTP)
+	router.Post("/api/user/register", registerHandler.ServeHTTP)
+	router.Post("/api/user/login", loginHandler.ServeHTTP)
+	router.Post("/api/user/logout", logoutHandler.ServeHTTP)
+	router.Post("/api/user/logout-all", logoutEverywhereHandler.ServeHTTP)
+	router.Get("/api/user/oidc/login", oidcLoginHandler.ServeHTTP)
+	router.Get("/"+config.OIDCCallbackPath, oidcCallbackHandler.ServeHTTP)
+	router.Post("/api/user/api-keys", createAPIKeyHandler.ServeHTTP)
+	router.Get("/api/user/api-keys", getAPIKeysHandler.ServeHTTP)
+	router.Delete("/api/user/api-keys/{id}", deleteAPIKeyHandler.ServeHTTP)
 	router.Get("/api/user/urls", getAllUrlsByUserHandler.ServeHTTP)
 	router.Delete("/api/user/urls", deleteBatchOfURLsHandler.ServeHTTP)
+	router.Patch("/api/user/urls/{id}", updateShortURLHandler.ServeHTTP)
+	router.Get("/api/user/urls/{id}/stats", getShortURLStatsHandler.ServeHTTP)
+	router.Post("/api/user/utm-templates", createUTMTemplateHandler.ServeHTTP)
+	router.Get("/api/user/utm-templates", getUTMTemplatesHandler.ServeHTTP)
+	router.Put("/api/user/utm-templates/{id}", updateUTMTemplateHandler.ServeHTTP)
+	router.Delete("/api/user/utm-templates/{id}", deleteUTMTemplateHandler.ServeHTTP)
+	router.Post("/api/user/campaigns", createCampaignHandler.ServeHTTP)
+	router.Get("/api/user/campaigns", getCampaignsHandler.ServeHTTP)
+	router.Put("/api/user/campaigns/{id}", updateCampaignHandler.ServeHTTP)
+	router.Delete("/api/user/campaigns/{id}", deleteCampaignHandler.ServeHTTP)
+	router.Get("/api/user/campaigns/{id}/stats", getCampaignStatsHandler.ServeHTTP)
+	router.Post("/api/orgs", createOrganizationHandler.ServeHTTP)
+	router.Get("/api/orgs", getOrganizationsHandler.ServeHTTP)
+	router.Get("/api/orgs/{id}/members", getOrgMembersHandler.ServeHTTP)
+	router.Post("/api/orgs/{id}/members", addOrgMemberHandler.ServeHTTP)
+	router.Put("/api/orgs/{id}/members/{userID}", updateOrgMemberHandler.ServeHTTP)
+	router.Delete("/api/orgs/{id}/members/{userID}", removeOrgMemberHandler.ServeHTTP)
+	router.Get("/api/orgs/{id}/urls", getOrgURLsHandler.ServeHTTP)
 	router.Get("/{id}", redirectHandler.ServeHTTP)
+	router.Get("/{id}/*", redirectHandler.ServeHTTP)
+	router.Post("/{id}", unlockHandler.ServeHTTP)
+	router.Post("/{id}/*", unlockHandler.ServeHTTP)
+	router.Get("/{id}+", previewHandler.ServeHTTP)
+	// The static route takes precedence over the wildcard one, so qr is the reserved suffix of the path passthrough.
+	router.Get("/{id}/qr", qrCodeHandler.ServeHTTP)
 	router.Get("/ping", pingHandler.ServeHTTP)
+	router.Get("/.well-known/jwks.json", jwksHandler.ServeHTTP)
 
 	router.Route("/api/internal", func(r chi.Router) {
 		internalRoutesGroup := r.Group(nil)
 		internalRoutesGroup.Use(middlewares.CheckSubnet)
 		internalRoutesGroup.Get("/stats", getStatsHandler.ServeHTTP)
+
+		adminRoutesGroup := internalRoutesGroup.Group(nil)
+		adminRoutesGroup.Use(middlewares.RequireAdminToken)
+		adminRoutesGroup.Get("/urls", searchShortURLsHandler.ServeHTTP)
+		adminRoutesGroup.Get("/urls/{id}", adminGetShortURLHandler.ServeHTTP)
+		adminRoutesGroup.Post("/urls/{id}/disable", disableShortURLHandler.ServeHTTP)
+		adminRoutesGroup.Post("/urls/{id}/enable", enableShortURLHandler.ServeHTTP)
+		adminRoutesGroup.Get("/users/{id}/urls", adminGetUserURLsHandler.ServeHTTP)
+		adminRoutesGroup.Post("/users/{id}/ban", banUserHandler.ServeHTTP)
+		adminRoutesGroup.Post("/users/{id}/unban", unbanUserHandler.ServeHTTP)
+		adminRoutesGroup.Get("/audit", getAuditLogHandler.ServeHTTP)
+		adminRoutesGroup.Get("/audit/verify", verifyAuditLogHandler.ServeHTTP)
 	})
 
 	router.Mount("/debug", middleware.Profiler())
@@ -72,6 +161,9 @@ func ShortenURLRouter(shortURLService service.ShortURLServiceInterface) chi.Rout
 // Run is a function that prepares all the infrastructure dependencies and settings and runs the web server.
 func Run(addr string) error {
 	logger.Log.Infof("starting server at %s", addr)
+	if err := middlewares.LoadKeyring(); err != nil {
+		return err
+	}
 	doneChan := make(chan struct{})
 	sigint := make(chan os.Signal, 1)
 	signal.Notify(sigint, syscall.SIGINT|syscall.SIGTERM|syscall.SIGQUIT)
@@ -114,14 +206,45 @@ func Run(addr string) error {
 			}
 		}(storage.FSWrapper)
 	}
+	if config.Settings.GeoIPDatabasePath != "" {
+		var err error
+		geoip.DB, err = geoip.Open(config.Settings.GeoIPDatabasePath)
+		if err != nil {
+			return err
+		}
+		defer func(DB *geoip.Reader) {
+			closeErr := DB.Close()
+			if closeErr != nil {
+				panic(closeErr)
+			}
+		}(geoip.DB)
+		logger.Log.Info("GeoIP database opened")
+	}
+	if config.Settings.OIDCIssuer != "" {
+		var err error
+		oidc.SSO, err = oidc.NewProvider(topCtx, oidc.Config{
+			Issuer:       config.Settings.OIDCIssuer,
+			ClientID:     config.Settings.OIDCClientID,
+			ClientSecret: config.Settings.OIDCClientSecret,
+			RedirectURL:  config.Settings.OIDCRedirectURL,
+			Scopes:       config.Settings.OIDCScopes,
+		}, nil)
+		if err != nil {
+			return err
+		}
+		logger.Log.Infof("Single sign-on with %s enabled", oidc.SSO.Issuer())
+	}
 	if Pool == nil {
 		shortURLService = service.NewService(storage.MemoryRepo{}, doneChan)
 	} else {
 		shortURLService = service.NewService(storage.NewDBRepo(Pool), doneChan)
 	}
+	middlewares.SetRevocationChecker(&shortURLService)
 	server := &http.Server{Addr: addr, Handler: ShortenURLRouter(&shortURLService)}
-	gRPCServer := grpc.NewServer(grpc.UnaryInterceptor(auth.UnaryServerInterceptor(proto.AuthFn)))
+	gRPCServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		auth.UnaryServerInterceptor(proto.NewAuthFn(&shortURLService)), proto.ActorInterceptor))
 	gRPCServerListener := proto.NewShortenerGRPCServer(&shortURLService)
+	gRPCAdminServerListener := proto.NewShortenerAdminGRPCServer(&shortURLService)
 	go func() {
 		<-sigint
 		logger.Log.Info("shutting down HTTP and GRPC servers")
@@ -137,6 +260,7 @@ func Run(addr string) error {
 			logger.Log.Fatal(err)
 		}
 		proto.RegisterURLShortenerServiceServer(gRPCServer, gRPCServerListener)
+		proto.RegisterURLShortenerAdminServiceServer(gRPCServer, gRPCAdminServerListener)
 		logger.Log.Info("gRPC server listening on", listen.Addr())
 		if err = gRPCServer.Serve(listen); err != nil {
 			log.Fatal(err)
@@ -165,6 +289,7 @@ func Run(addr string) error {
 }
 
 func prefillMemory() error {
+	shortURLService = service.NewService(storage.MemoryRepo{}, make(chan struct{}))
 	for {
 		row, err := storage.FSWrapper.ReadNextLine()
 		if err != nil {
@@ -174,8 +299,56 @@ func prefillMemory() error {
 				return err
 			}
 		}
-		shortURLService = service.NewService(storage.MemoryRepo{}, make(chan struct{}))
-		fillingError := shortURLService.FillRow(topCtx, row.OriginalURL, row.ShortURL, row.UserID)
+		var fillingError error
+		switch {
+		case row.UTMTemplate != nil:
+			template := *row.UTMTemplate
+			template.UserID = row.UserID
+			fillingError = shortURLService.FillUTMTemplate(topCtx, template, row.Deleted)
+		case row.Campaign != nil:
+			campaign := *row.Campaign
+			campaign.UserID = row.UserID
+			fillingError = shortURLService.FillCampaign(topCtx, campaign, row.Deleted)
+		case row.Account != nil:
+			account := *row.Account
+			account.ID = row.UserID
+			account.PasswordHash = row.PasswordHash
+			fillingError = shortURLService.FillAccount(topCtx, account, row.Deleted)
+		case row.APIKey != nil:
+			key := *row.APIKey
+			key.UserID = row.UserID
+			key.KeyHash = row.PasswordHash
+			fillingError = shortURLService.FillAPIKey(topCtx, key, row.Deleted)
+		case row.Revocation != nil:
+			revocation := *row.Revocation
+			revocation.UserID = row.UserID
+			fillingError = shortURLService.FillTokenRevocation(topCtx, revocation)
+		case row.OIDCIdentity != nil:
+			identity := *row.OIDCIdentity
+			identity.UserID = row.UserID
+			fillingError = shortURLService.FillOIDCIdentity(topCtx, identity)
+		case row.Organization != nil:
+			fillingError = shortURLService.FillOrganization(topCtx, *row.Organization, row.UserID)
+		case row.OrgMember != nil:
+			member := *row.OrgMember
+			member.OrgID = row.OrgID
+			member.UserID = row.UserID
+			fillingError = shortURLService.FillOrgMember(topCtx, member, row.Deleted)
+		case row.Moderation != nil:
+			fillingError = shortURLService.FillModeration(topCtx, row.ShortURL, *row.Moderation, row.Deleted)
+		case row.Ban != nil:
+			ban := *row.Ban
+			ban.UserID = row.UserID
+			fillingError = shortURLService.FillUserBan(topCtx, ban, row.Deleted)
+		case row.Audit != nil:
+			fillingError = shortURLService.FillAuditEvent(topCtx, *row.Audit)
+		case row.Variant != "":
+			fillingError = shortURLService.FillVariantClicks(topCtx, row.ShortURL, row.Variant, row.Clicks)
+		case row.UsedClicks > 0:
+			fillingError = shortURLService.FillUsedClicks(topCtx, row.ShortURL, row.UsedClicks)
+		default:
+			fillingError = shortURLService.FillRow(topCtx, row.OriginalURL, row.ShortURL, row.UserID, row.Options(), row.Metadata)
+		}
 		if fillingError != nil {
 			return fillingError
 		}
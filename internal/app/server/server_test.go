@@ -3,20 +3,42 @@ package server
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/clearthree/url-shortener/internal/app/config"
 	"github.com/clearthree/url-shortener/internal/app/middlewares"
+	"github.com/clearthree/url-shortener/internal/app/models"
+	"github.com/clearthree/url-shortener/internal/app/oidc"
+	"github.com/clearthree/url-shortener/internal/app/oidc/oidctest"
 	"github.com/clearthree/url-shortener/internal/app/service"
 	"github.com/clearthree/url-shortener/internal/app/storage"
 )
 
+// TestMain keeps the file storage written by the tests in the temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "url-shortener")
+	if err != nil {
+		panic(err)
+	}
+	config.Settings.FileStoragePath = filepath.Join(dir, "storage.json")
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func testRequest(t *testing.T, testServer *httptest.Server, method string, path string, contentType string, payload string) (*http.Response, string) {
 	var body io.Reader
 	if payload != "" {
@@ -70,6 +92,15 @@ func TestRouter(t *testing.T) {
 			status:      http.StatusNotFound,
 			preCreate:   false,
 		},
+		{
+			URL:         "/lele/qr?size=large",
+			method:      http.MethodGet,
+			payload:     "",
+			contentType: "text/plain",
+			want:        "Invalid size",
+			status:      http.StatusBadRequest,
+			preCreate:   false,
+		},
 		{
 			URL:         "/",
 			method:      http.MethodGet,
@@ -225,7 +256,7 @@ func TestAuth(t *testing.T) {
 
 		resp, err := testServer.Client().Do(request)
 		require.NoError(t, err)
-		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode, "the token is expired beyond the grace period")
 
 		defer resp.Body.Close()
 
@@ -286,12 +317,14 @@ func TestAuth(t *testing.T) {
 
 		request, err = http.NewRequest(http.MethodGet, testServer.URL+"/api/user/urls", nil)
 		require.NoError(t, err)
-		token := strings.TrimPrefix(resp.Header.Get("Set-Cookie"), middlewares.AuthCookieName+"=")
-		token = strings.TrimSuffix(token, "; Path=/")
-		require.NotEmpty(t, token)
+		require.Len(t, resp.Cookies(), 1)
+		token := resp.Cookies()[0]
+		require.NotEmpty(t, token.Value)
+		assert.True(t, token.HttpOnly, "the token isn't readable by the scripts")
+		assert.Equal(t, http.SameSiteLaxMode, token.SameSite)
 		request.AddCookie(&http.Cookie{
 			Name:  middlewares.AuthCookieName,
-			Value: token,
+			Value: token.Value,
 		})
 		resp, err = testServer.Client().Do(request)
 		require.NoError(t, err)
@@ -300,4 +333,274 @@ func TestAuth(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, http.StatusOK, resp.StatusCode)
 	})
+
+	t.Run("with_api_key_we_act_as_its_owner", func(t *testing.T) {
+		key, err := serviceForTest.CreateAPIKey(context.Background(), "APIKeyOwner", models.CreateAPIKeyRequest{Name: "CI"})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, testServer.URL+"/", strings.NewReader(requestBody))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "text/plain")
+		request.Header.Set("Authorization", "Bearer "+key.Key)
+		resp, err := testServer.Client().Do(request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Set-Cookie"), "no cookie is issued for the api key")
+
+		urls, err := serviceForTest.ReadByUserID(context.Background(), "APIKeyOwner", models.ShortURLFilter{})
+		require.NoError(t, err)
+		assert.Len(t, urls, 1)
+
+		require.NoError(t, serviceForTest.DeleteAPIKey(context.Background(), key.ID, "APIKeyOwner"))
+		request, err = http.NewRequest(http.MethodGet, testServer.URL+"/api/user/urls", nil)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", "Bearer "+key.Key)
+		resp, err = testServer.Client().Do(request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "the revoked key is rejected")
+	})
+
+	middlewares.SetRevocationChecker(&serviceForTest)
+	defer middlewares.SetRevocationChecker(nil)
+	oldExpire := config.Settings.JWTExpireHours
+	defer func() { config.Settings.JWTExpireHours = oldExpire }()
+	config.Settings.JWTExpireHours = 1
+
+	t.Run("logged_out_token_is_rejected", func(t *testing.T) {
+		token, _, err := middlewares.GenerateJWTString("LoggingOut")
+		require.NoError(t, err)
+		cookie := &http.Cookie{Name: middlewares.AuthCookieName, Value: token}
+
+		request, err := http.NewRequest(http.MethodPost, testServer.URL+"/api/user/logout", nil)
+		require.NoError(t, err)
+		request.AddCookie(cookie)
+		resp, err := testServer.Client().Do(request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		request, err = http.NewRequest(http.MethodGet, testServer.URL+"/api/user/urls", nil)
+		require.NoError(t, err)
+		request.AddCookie(cookie)
+		resp, err = testServer.Client().Do(request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "the copy of the token is rejected as well")
+	})
+
+	t.Run("logout_everywhere_rejects_all_the_tokens", func(t *testing.T) {
+		firstToken, _, err := middlewares.GenerateJWTString("LoggingOutEverywhere")
+		require.NoError(t, err)
+		secondToken, _, err := middlewares.GenerateJWTString("LoggingOutEverywhere")
+		require.NoError(t, err)
+		time.Sleep(time.Second)
+
+		request, err := http.NewRequest(http.MethodPost, testServer.URL+"/api/user/logout-all", nil)
+		require.NoError(t, err)
+		request.AddCookie(&http.Cookie{Name: middlewares.AuthCookieName, Value: firstToken})
+		resp, err := testServer.Client().Do(request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		request, err = http.NewRequest(http.MethodGet, testServer.URL+"/api/user/urls", nil)
+		require.NoError(t, err)
+		request.AddCookie(&http.Cookie{Name: middlewares.AuthCookieName, Value: secondToken})
+		resp, err = testServer.Client().Do(request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "the token of the other device is rejected")
+	})
+
+	t.Run("user_id_header_is_ignored", func(t *testing.T) {
+		_, err := serviceForTest.Create(context.Background(), "https://ya.ru/victim", "Victim", models.ShortURLOptions{})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodGet, testServer.URL+"/api/user/urls", nil)
+		require.NoError(t, err)
+		request.Header.Set(middlewares.UserIDHeaderName, "Victim")
+		resp, err := testServer.Client().Do(request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode, "the new anonymous user has no links")
+		assert.NotEmpty(t, resp.Header.Get("Set-Cookie"))
+	})
+}
+
+func TestOIDCLogin(t *testing.T) {
+	idp := oidctest.NewServer("shortener", "secret")
+	defer idp.Close()
+	testServer := httptest.NewServer(ShortenURLRouter(&serviceForTest))
+	defer testServer.Close()
+	provider, err := oidc.NewProvider(context.Background(),
+		idp.Config(testServer.URL+"/"+config.OIDCCallbackPath), idp.Client())
+	require.NoError(t, err)
+	oidc.SSO = provider
+	defer func() { oidc.SSO = nil }()
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	browser := &http.Client{Jar: jar}
+
+	resp, err := browser.Post(testServer.URL+"/", "text/plain", strings.NewReader("https://ya.ru/before-sso"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	idp.User = oidctest.User{Subject: "employee-42", Email: "employee42@corp.example", EmailVerified: true}
+	resp, err = browser.Get(testServer.URL + "/api/user/oidc/login?claim_links=true")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "the user is signed in after the redirects")
+	var account models.Account
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&account))
+	assert.Equal(t, "employee42@corp.example", account.Email)
+
+	resp, err = browser.Get(testServer.URL + "/api/user/urls")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "before-sso", "the links created before the sign-in are kept")
+
+	otherJar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	fresh := &http.Client{Jar: otherJar}
+	resp, err = fresh.Get(testServer.URL + "/api/user/oidc/login")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var again models.Account
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&again))
+	assert.Equal(t, account.ID, again.ID, "the subject signs in to the same account on the other device")
+
+	anotherJar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	anonymous := &http.Client{Jar: anotherJar}
+	resp, err = anonymous.Post(testServer.URL+"/", "text/plain", strings.NewReader("https://ya.ru/not-claimed"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	idp.User = oidctest.User{Subject: "employee-43", Email: "employee43@corp.example", EmailVerified: true}
+	resp, err = anonymous.Get(testServer.URL + "/api/user/oidc/login")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, err = anonymous.Get(testServer.URL + "/api/user/urls")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "not-claimed", "the links are claimed on request only")
+
+	resp, err = browser.Get(testServer.URL + "/" + config.OIDCCallbackPath + "?code=stolen&state=forged")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "the callback without the login is rejected")
+}
+
+func TestPassthroughRoutes(t *testing.T) {
+	testServer := httptest.NewServer(ShortenURLRouter(&serviceForTest))
+	defer testServer.Close()
+	client := testServer.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	shorten := func(payload string) string {
+		resp, body := testRequest(t, testServer, http.MethodPost, "/api/shorten", "application/json", payload)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		var result models.ShortenResponse
+		require.NoError(t, json.Unmarshal([]byte(body), &result))
+		return strings.TrimPrefix(result.Result, config.Settings.HostedOn)
+	}
+	pathID := shorten(`{"url": "https://ya.ru/docs?v=2", "passthrough": "path"}`)
+	queryID := shorten(`{"url": "https://ya.ru/search?v=2", "passthrough": "query"}`)
+	plainID := shorten(`{"url": "https://ya.ru/plain"}`)
+
+	tests := []struct {
+		name         string
+		path         string
+		wantLocation string
+		wantStatus   int
+	}{
+		{name: "Path suffix is appended", path: "/" + pathID + "/sub/page?x=1",
+			wantLocation: "https://ya.ru/docs/sub/page?v=2", wantStatus: http.StatusTemporaryRedirect},
+		{name: "Dot segments are rejected", path: "/" + pathID + "/a/%2e%2e/admin", wantStatus: http.StatusBadRequest},
+		{name: "Query is merged", path: "/" + queryID + "?ref=mail&v=3",
+			wantLocation: "https://ya.ru/search?v=2&ref=mail", wantStatus: http.StatusTemporaryRedirect},
+		{name: "Path suffix is not found for the query mode", path: "/" + queryID + "/sub", wantStatus: http.StatusNotFound},
+		{name: "Query is ignored by default", path: "/" + plainID + "?ref=mail",
+			wantLocation: "https://ya.ru/plain", wantStatus: http.StatusTemporaryRedirect},
+		{name: "Path suffix is not found by default", path: "/" + plainID + "/sub", wantStatus: http.StatusNotFound},
+		{name: "Path suffix under the reserved qr one is appended", path: "/" + pathID + "/qr/code",
+			wantLocation: "https://ya.ru/docs/qr/code?v=2", wantStatus: http.StatusTemporaryRedirect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Get(testServer.URL + tt.path)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, tt.wantLocation, resp.Header.Get("Location"))
+		})
+	}
+
+	resp, err := client.Get(testServer.URL + "/" + pathID + "/qr")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "qr is the reserved suffix of the QR code")
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+}
+
+func TestAdminRoutes(t *testing.T) {
+	oldSettings := config.Settings
+	defer func() {
+		config.Settings = oldSettings
+		middlewares.IPNet = nil
+	}()
+	config.Settings.TrustedSubnet = "127.0.0.0/8"
+	config.Settings.GRPCToken = "admin-secret"
+	middlewares.IPNet = nil
+	testServer := httptest.NewServer(ShortenURLRouter(&serviceForTest))
+	defer testServer.Close()
+	tests := []struct {
+		name       string
+		path       string
+		adminToken string
+		wantStatus int
+	}{
+		{name: "Stats need the trusted subnet only", path: "/api/internal/stats", wantStatus: http.StatusOK},
+		{name: "Audit needs the admin token", path: "/api/internal/audit/verify", wantStatus: http.StatusUnauthorized},
+		{name: "Wrong admin token", path: "/api/internal/audit/verify", adminToken: "guess",
+			wantStatus: http.StatusUnauthorized},
+		{name: "Admin token", path: "/api/internal/audit/verify", adminToken: "admin-secret", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodGet, testServer.URL+tt.path, nil)
+			require.NoError(t, err)
+			if tt.adminToken != "" {
+				request.Header.Set(middlewares.AdminTokenHeaderName, tt.adminToken)
+			}
+			resp, err := testServer.Client().Do(request)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+
+	config.Settings.TrustedSubnet = "10.0.0.0/8"
+	config.Settings.UseHeaderForSourceAddress = true
+	config.Settings.TrustedProxies = []string{"192.0.2.0/24"}
+	middlewares.IPNet = nil
+	request, err := http.NewRequest(http.MethodGet, testServer.URL+"/api/internal/audit/verify", nil)
+	require.NoError(t, err)
+	request.Header.Set("X-Real-IP", "10.0.0.1")
+	request.Header.Set(middlewares.AdminTokenHeaderName, "admin-secret")
+	resp, err := testServer.Client().Do(request)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode,
+		"the header forged by the client other than the trusted proxies doesn't put it in the trusted subnet")
 }
@@ -3,25 +3,255 @@ package service
 
 import (
 	"context"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/rand"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/net/http/httpguts"
 
 	"github.com/clearthree/url-shortener/internal/app/config"
+	"github.com/clearthree/url-shortener/internal/app/domains"
 	"github.com/clearthree/url-shortener/internal/app/logger"
+	"github.com/clearthree/url-shortener/internal/app/metadata"
 	"github.com/clearthree/url-shortener/internal/app/models"
+	"github.com/clearthree/url-shortener/internal/app/qr"
 	"github.com/clearthree/url-shortener/internal/app/storage"
+	"github.com/clearthree/url-shortener/internal/app/utils"
 )
 
 const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 const shortURLIdLength = 8
 
+// Limits for the optional attributes of the short URL.
+const (
+	maxTitleLength = 256
+	maxNotesLength = 4096
+	maxTagLength   = 64
+	maxTagsCount   = 32
+	// bcrypt ignores the bytes beyond this limit, so the longer passwords are rejected.
+	maxPasswordLength        = 72
+	maxHeaderValueLength     = 256
+	maxUTMValueLength        = 256
+	maxCampaignNameLength    = 256
+	maxOrgNameLength         = 256
+	maxEmailLength           = 254
+	minAccountPasswordLength = 8
+	maxAPIKeyNameLength      = 256
+	maxAPIKeysCount          = 20
+	maxTargetsCount          = 16
+	maxGeoRulesCount         = 64
+	maxVariantsCount         = 16
+	maxVariantWeight         = 1000
+	maxVariantNameLength     = 32
+	minQRSize                = 64
+	maxQRSize                = 2048
+	defaultQRSize            = 256
+	maxModerationReason      = 1024
+	defaultSearchLimit       = 100
+	maxSearchLimit           = 1000
+)
+
+// Actions of the mutations recorded in the audit log, the target of the event is the ID of the object mutated.
+const (
+	AuditURLCreate          = "url.create"
+	AuditURLUpdate          = "url.update"
+	AuditURLDelete          = "url.delete"
+	AuditURLDisable         = "url.disable"
+	AuditURLEnable          = "url.enable"
+	AuditUserBan            = "user.ban"
+	AuditUserUnban          = "user.unban"
+	AuditAccountRegister    = "account.register"
+	AuditAccountClaimLinks  = "account.claim_links"
+	AuditAccountUnregister  = "account.unregister"
+	AuditOIDCIdentityLink   = "oidc_identity.link"
+	AuditTokenRevoke        = "token.revoke"
+	AuditTokenRevokeAll     = "token.revoke_all"
+	AuditURLClicksExhausted = "url.clicks_exhausted"
+	AuditAPIKeyCreate       = "api_key.create"
+	AuditAPIKeyDelete       = "api_key.delete"
+	AuditUTMTemplateCreate  = "utm_template.create"
+	AuditUTMTemplateUpdate  = "utm_template.update"
+	AuditUTMTemplateDelete  = "utm_template.delete"
+	AuditCampaignCreate     = "campaign.create"
+	AuditCampaignUpdate     = "campaign.update"
+	AuditCampaignDelete     = "campaign.delete"
+	AuditOrganizationCreate = "organization.create"
+	AuditOrgMemberAdd       = "org_member.add"
+	AuditOrgMemberUpdate    = "org_member.update"
+	AuditOrgMemberRemove    = "org_member.remove"
+)
+
+// roleRanks orders the roles of the organization members, each one allows everything the lower ones do.
+var roleRanks = map[string]int{models.RoleViewer: 1, models.RoleEditor: 2, models.RoleAdmin: 3, models.RoleOwner: 4}
+
+// redirectStatuses are the HTTP statuses allowed for the redirect of the short URL, zero stands for the server default.
+var redirectStatuses = []int{0, http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect,
+	http.StatusPermanentRedirect}
+
+// referrerPolicies are the values of Referrer-Policy header defined by the standard.
+var referrerPolicies = []string{"", "no-referrer", "no-referrer-when-downgrade", "origin", "origin-when-cross-origin",
+	"same-origin", "strict-origin", "strict-origin-when-cross-origin", "unsafe-url"}
+
 // ErrShortURLNotFound is an error that will be returned in case the non-existing short URL is being requested
 // by the user.
 var ErrShortURLNotFound = errors.New("no urls found by the given id")
 
+// ErrForbidden is an error that will be returned in case the user tries to change the short URL of another user
+// or the short URL of the organization the role of the user doesn't allow to change.
+var ErrForbidden = errors.New("the short url belongs to another user")
+
+// ErrInvalidOptions is an error that will be returned in case the optional attributes of the short URL are invalid.
+var ErrInvalidOptions = errors.New("invalid short url attributes")
+
+// ErrUTMTemplateNotFound is an error that will be returned in case the non-existing UTM template or the template
+// of another user is being requested.
+var ErrUTMTemplateNotFound = errors.New("no utm templates found by the given id")
+
+// ErrInvalidUTMTemplate is an error that will be returned in case the name or the parameters of the UTM template are invalid.
+var ErrInvalidUTMTemplate = errors.New("invalid utm template")
+
+// ErrCampaignNotFound is an error that will be returned in case the non-existing campaign or the campaign
+// of another user is requested.
+var ErrCampaignNotFound = errors.New("no campaigns found by the given id")
+
+// ErrInvalidCampaign is an error that will be returned in case the name of the campaign is invalid.
+var ErrInvalidCampaign = errors.New("invalid campaign")
+
+// ErrOrganizationNotFound is an error that will be returned in case the non-existing organization or the organization
+// the user isn't a member of is requested.
+var ErrOrganizationNotFound = errors.New("no organizations found by the given id")
+
+// ErrInvalidOrganization is an error that will be returned in case the name of the organization or the role
+// of the member are invalid, or the change would leave the organization without an owner.
+var ErrInvalidOrganization = errors.New("invalid organization")
+
+// ErrOrgForbidden is an error that will be returned in case the role of the user in the organization doesn't allow
+// the action.
+var ErrOrgForbidden = errors.New("the role in the organization doesn't allow the action")
+
+// ErrOrgMemberNotFound is an error that will be returned in case the user to change isn't a member of the organization.
+var ErrOrgMemberNotFound = errors.New("no organization members found by the given id")
+
+// ErrAccountExists is an error that will be returned in case the email is registered already.
+var ErrAccountExists = errors.New("the account with the given email exists already")
+
+// ErrInvalidAccount is an error that will be returned in case the email or the password of the new account are invalid.
+var ErrInvalidAccount = errors.New("invalid account")
+
+// ErrInvalidCredentials is an error that will be returned in case the email or the password don't match any account.
+// The same error is returned for both, so the registered emails can't be probed.
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// ErrAPIKeyNotFound is an error that will be returned in case the non-existing API key or the key of another user
+// is requested.
+var ErrAPIKeyNotFound = errors.New("no api keys found by the given id")
+
+// ErrInvalidAPIKey is an error that will be returned in case the name or the expiry of the new API key are invalid.
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// ErrAPIKeyRejected is an error that will be returned in case the unknown, revoked or expired API key is presented.
+var ErrAPIKeyRejected = errors.New("the api key is unknown, revoked or expired")
+
+// ErrWrongPassword is an error that will be returned in case the visitor enters the wrong password
+// of the protected short URL.
+var ErrWrongPassword = errors.New("wrong password")
+
+// ErrTooManyAttempts is an error that will be returned in case the visitor made too many wrong password attempts.
+var ErrTooManyAttempts = errors.New("too many password attempts")
+
+// ErrNotActiveYet is an error that will be returned in case the short URL is followed before its activation window.
+var ErrNotActiveYet = errors.New("the short url is not active yet")
+
+// ErrNoLongerActive is an error that will be returned in case the short URL is followed after its activation window.
+var ErrNoLongerActive = errors.New("the short url is no longer active")
+
+// ErrInvalidQROptions is an error that will be returned in case the format, the size or the error correction level
+// of the QR code image are invalid.
+var ErrInvalidQROptions = errors.New("invalid qr code options")
+
+// ErrDomainNotAllowed is an error that will be returned in case the user tries to create the short URL
+// on the branded domain the user isn't allowed to use.
+var ErrDomainNotAllowed = errors.New("the short domain is not allowed for the user")
+
+// ErrClicksExhausted is an error that will be returned in case the click-limited short URL is followed
+// after it has been followed as many times as allowed.
+var ErrClicksExhausted = errors.New("the short url has no clicks left")
+
+// ErrShortURLDisabled is an error that will be returned in case the short URL disabled by the admin
+// or created by the banned user is followed.
+var ErrShortURLDisabled = errors.New("the short url is disabled")
+
+// ErrUserBanned is an error that will be returned in case the banned user tries to create the short URL.
+var ErrUserBanned = errors.New("the user is banned")
+
+// ErrUserNotBanned is an error that will be returned in case the admin lifts the ban of the user who isn't banned.
+var ErrUserNotBanned = errors.New("the user is not banned")
+
+// ErrInvalidModeration is an error that will be returned in case the reason of the moderation or the search
+// of the short URLs are invalid.
+var ErrInvalidModeration = errors.New("invalid moderation request")
+
+// ErrInvalidAuditFilter is an error that will be returned in case the filters of the audit log are invalid.
+var ErrInvalidAuditFilter = errors.New("invalid audit log filter")
+
+// ErrNotActiveYetExtended is a wrapper for ErrNotActiveYet to pass the time the short URL becomes active to the caller.
+type ErrNotActiveYetExtended struct {
+	ActiveFrom time.Time
+	Err        error
+}
+
+// NewErrNotActiveYet is the constructor that returns the new ErrNotActiveYetExtended structure.
+func NewErrNotActiveYet(err error, activeFrom time.Time) *ErrNotActiveYetExtended {
+	return &ErrNotActiveYetExtended{ActiveFrom: activeFrom, Err: err}
+}
+
+// Unwrap unwraps the error - returns the original error itself.
+func (e ErrNotActiveYetExtended) Unwrap() error {
+	return e.Err
+}
+
+// Error returns the string representation of an error message.
+func (e ErrNotActiveYetExtended) Error() string {
+	return fmt.Sprintf("%s, active from %s", e.Err.Error(), e.ActiveFrom.Format(time.RFC3339))
+}
+
+// ErrTooManyAttemptsExtended is a wrapper for ErrTooManyAttempts to pass the time left until the next attempt
+// is allowed to the caller.
+type ErrTooManyAttemptsExtended struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+// NewErrTooManyAttempts is the constructor that returns the new ErrTooManyAttemptsExtended structure.
+func NewErrTooManyAttempts(err error, retryAfter time.Duration) *ErrTooManyAttemptsExtended {
+	return &ErrTooManyAttemptsExtended{err, retryAfter}
+}
+
+// Unwrap unwraps the error - returns the original error itself.
+func (e ErrTooManyAttemptsExtended) Unwrap() error {
+	return e.Err
+}
+
+// Error returns the string representation of an error message.
+func (e ErrTooManyAttemptsExtended) Error() string {
+	return fmt.Sprintf("%s, retry in %s", e.Err.Error(), e.RetryAfter.Round(time.Second))
+}
+
 func generateID() string {
 	bytesSlice := make([]byte, shortURLIdLength)
 	for i := range bytesSlice {
@@ -34,11 +264,24 @@ func generateID() string {
 type ShortURLServiceInterface interface {
 
 	// Create creates the short URL by passed original URL and connects it with the user.
-	Create(ctx context.Context, originalURL string, userID string) (string, error)
+	Create(ctx context.Context, originalURL string, userID string, options models.ShortURLOptions) (string, error)
 
 	// Read reads the original URL from the storage by passed ID, which is the ID of short URL.
 	Read(ctx context.Context, id string) (string, bool, error)
 
+	// Resolve reads the whole short URL record to decide how the visitor should be redirected.
+	// Returns ErrNotActiveYet or ErrNoLongerActive if the short URL is followed outside its activation window,
+	// ErrClicksExhausted if the click-limited short URL has no clicks left, ErrShortURLDisabled if the short URL
+	// is disabled by the admin or its author is banned.
+	Resolve(ctx context.Context, id string) (*models.ShortURL, error)
+
+	// UseClick takes one of the clicks left for the click-limited short URL the visitor is redirected by.
+	// Returns ErrClicksExhausted if there are none.
+	UseClick(ctx context.Context, shortURL *models.ShortURL) error
+
+	// CheckPassword checks the password entered by the visitor of the protected short URL.
+	CheckPassword(shortURL *models.ShortURL, password string, clientIP string) error
+
 	// Ping pings the required dependencies.
 	Ping(ctx context.Context) error
 
@@ -46,8 +289,11 @@ type ShortURLServiceInterface interface {
 	// short URLs with this user.
 	BatchCreate(ctx context.Context, requestData []models.ShortenBatchItemRequest, userID string) ([]models.ShortenBatchItemResponse, error)
 
-	// ReadByUserID Reads all the URLs created by the current user.
-	ReadByUserID(ctx context.Context, userID string) ([]models.ShortURLsByUserResponse, error)
+	// ReadByUserID Reads all the URLs created by the current user and matching the filter.
+	ReadByUserID(ctx context.Context, userID string, filter models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error)
+
+	// Update changes the optional attributes of the short URL the current user manages.
+	Update(ctx context.Context, id string, userID string, update models.UpdateShortURLRequest) (*models.ShortURLsByUserResponse, error)
 
 	// ScheduleDeletionOfBatch Schedules the batch of short URLs for the deletion.
 	ScheduleDeletionOfBatch(shortURLs []models.ShortURLChannelMessage)
@@ -55,46 +301,212 @@ type ShortURLServiceInterface interface {
 	// FlushDeletions marks some scheduled deletions as deleted in the storage.
 	FlushDeletions()
 
+	// FetchMetadata fetches the metadata of the destination pages for the newly created short URLs.
+	FetchMetadata()
+
 	// GetStats returns the total number of users and shortened URLs stored in the service
 	GetStats(ctx context.Context) (*models.ServiceStats, error)
+
+	// CreateUTMTemplate creates the UTM template owned by the current user.
+	CreateUTMTemplate(ctx context.Context, userID string, template models.UTMTemplate) (*models.UTMTemplate, error)
+
+	// ReadUTMTemplatesByUserID reads all the UTM templates created by the current user.
+	ReadUTMTemplatesByUserID(ctx context.Context, userID string) ([]models.UTMTemplate, error)
+
+	// UpdateUTMTemplate replaces the name and the parameters of the UTM template owned by the current user.
+	UpdateUTMTemplate(ctx context.Context, id string, userID string, template models.UTMTemplate) (*models.UTMTemplate, error)
+
+	// DeleteUTMTemplate deletes the UTM template owned by the current user, detaching it from the short URLs.
+	DeleteUTMTemplate(ctx context.Context, id string, userID string) error
+
+	// CreateCampaign creates the campaign owned by the current user.
+	CreateCampaign(ctx context.Context, userID string, campaign models.Campaign) (*models.Campaign, error)
+
+	// ReadCampaignsByUserID reads all the campaigns created by the current user.
+	ReadCampaignsByUserID(ctx context.Context, userID string) ([]models.Campaign, error)
+
+	// UpdateCampaign renames the campaign owned by the current user.
+	UpdateCampaign(ctx context.Context, id string, userID string, campaign models.Campaign) (*models.Campaign, error)
+
+	// DeleteCampaign deletes the campaign owned by the current user and schedules its short URLs for the deletion.
+	DeleteCampaign(ctx context.Context, id string, userID string) error
+
+	// GetCampaignStats returns the totals of the campaign owned by the current user.
+	GetCampaignStats(ctx context.Context, id string, userID string) (*models.CampaignStats, error)
+
+	// CreateOrganization creates the organization with the current user as its owner.
+	CreateOrganization(ctx context.Context, userID string, org models.Organization) (*models.Organization, error)
+
+	// ReadOrganizationsByUserID reads all the organizations the current user is a member of.
+	ReadOrganizationsByUserID(ctx context.Context, userID string) ([]models.Organization, error)
+
+	// ReadOrgMembers reads all the members of the organization the current user is a member of.
+	ReadOrgMembers(ctx context.Context, orgID string, userID string) ([]models.OrgMember, error)
+
+	// AddOrgMember adds the account found by the email to the organization managed by the current user.
+	AddOrgMember(ctx context.Context, orgID string, userID string, request models.AddOrgMemberRequest) (*models.OrgMember, error)
+
+	// UpdateOrgMember changes the role of the member of the organization managed by the current user.
+	UpdateOrgMember(ctx context.Context, orgID string, memberID string, userID string, role string) (*models.OrgMember, error)
+
+	// RemoveOrgMember removes the member from the organization managed by the current user, or the current user
+	// leaves the organization.
+	RemoveOrgMember(ctx context.Context, orgID string, memberID string, userID string) error
+
+	// ReadByOrgID reads all the URLs of the organization the current user is a member of, matching the filter.
+	ReadByOrgID(ctx context.Context, orgID string, userID string, filter models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error)
+
+	// Register creates the account with the credentials, taking over the short URLs of the current user if asked.
+	Register(ctx context.Context, credentials models.Credentials, userID string, clientIP string) (*models.Account, error)
+
+	// Login checks the credentials and returns the account the user logs in to.
+	Login(ctx context.Context, credentials models.Credentials, clientIP string) (*models.Account, error)
+
+	// LoginOIDC returns the account linked to the user of the identity provider, creating it on the first login.
+	LoginOIDC(ctx context.Context, login models.OIDCLogin, userID string) (*models.Account, error)
+
+	// CreateAPIKey creates the API key owned by the current user, the plain key is returned once.
+	CreateAPIKey(ctx context.Context, userID string, request models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error)
+
+	// ReadAPIKeysByUserID reads all the API keys created by the current user.
+	ReadAPIKeysByUserID(ctx context.Context, userID string) ([]models.APIKey, error)
+
+	// DeleteAPIKey revokes the API key owned by the current user.
+	DeleteAPIKey(ctx context.Context, id string, userID string) error
+
+	// ResolveAPIKey returns the ID of the user the API key belongs to.
+	ResolveAPIKey(ctx context.Context, key string) (string, error)
+
+	// RevokeToken logs the single token of the user out.
+	RevokeToken(ctx context.Context, tokenID string, userID string) error
+
+	// RevokeAllTokens logs the user out everywhere, revoking all the tokens issued so far.
+	RevokeAllTokens(ctx context.Context, userID string) error
+
+	// IsTokenRevoked reports whether the token is logged out.
+	IsTokenRevoked(ctx context.Context, tokenID string, userID string, issuedAt time.Time) (bool, error)
+
+	// RecordClick schedules counting the click on the split variant of the short URL.
+	RecordClick(shortURL string, variant string)
+
+	// FlushClicks adds the scheduled clicks to the counters in the storage.
+	FlushClicks()
+
+	// GetShortURLStats returns the clicks on the split variants of the short URL the current user has access to.
+	GetShortURLStats(ctx context.Context, id string, userID string) (*models.ShortURLStats, error)
+
+	// GetQRCode renders the QR code image of the short URL.
+	GetQRCode(ctx context.Context, id string, options models.QROptions) (*models.QRCode, error)
+
+	// AdminReadShortURL reads the short URL of any user along with its moderation state, available to the admin only.
+	AdminReadShortURL(ctx context.Context, id string) (*models.AdminShortURL, error)
+
+	// SearchShortURLs reads the short URLs of all the users matching the search, available to the admin only.
+	SearchShortURLs(ctx context.Context, search models.ShortURLSearch) ([]models.AdminShortURL, error)
+
+	// DisableShortURL blocks the redirects of the short URL for the reason, available to the admin only.
+	DisableShortURL(ctx context.Context, id string, reason string) (*models.AdminShortURL, error)
+
+	// EnableShortURL lets the short URL disabled by the admin redirect again, available to the admin only.
+	EnableShortURL(ctx context.Context, id string) (*models.AdminShortURL, error)
+
+	// BanUser bans the user for the reason, available to the admin only.
+	BanUser(ctx context.Context, userID string, reason string) (*models.UserBan, error)
+
+	// UnbanUser lifts the ban of the user, available to the admin only.
+	UnbanUser(ctx context.Context, userID string) error
+
+	// ReadAuditEvents reads the events of the audit log matching the filter, available to the admin only.
+	ReadAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error)
+
+	// VerifyAuditLog checks the hash chain of the whole audit log, available to the admin only.
+	VerifyAuditLog(ctx context.Context) (*models.AuditVerification, error)
 }
 
 // ShortURLService is the structure that implements the ShortURLServiceInterface interface and performs as the main
 // business-logic generalization for the short-url functionality.
 type ShortURLService struct {
 	repo             storage.Repository
+	fetcher          metadata.Fetcher
 	doneChan         chan struct{}
 	deleteMsgChanIn  chan models.ShortURLChannelMessage
-	deleteMsgChanOut chan string
+	deleteMsgChanOut chan models.ShortURLChannelMessage
+	metadataJobs     chan models.MetadataJob
+	clicks           chan models.VariantClick
+	passwordAttempts *utils.AttemptLimiter
+	linkAttempts     *utils.AttemptLimiter
+	accountAttempts  *utils.AttemptLimiter
+	registerAttempts *utils.AttemptLimiter
+	metadataTimeout  time.Duration
 }
 
 // NewService initializes the new ShortURLService structure, using its dependencies as an input.
+// Starts the bounded pool of metadata fetching workers unless it is disabled in the settings.
 func NewService(repo storage.Repository, doneChan chan struct{}) ShortURLService {
 	deleteMsgChanIn := make(chan models.ShortURLChannelMessage, config.Settings.DefaultChannelsBufferSize)
-	deleteMsgChanOut := make(chan string, config.Settings.DefaultChannelsBufferSize)
+	deleteMsgChanOut := make(chan models.ShortURLChannelMessage, config.Settings.DefaultChannelsBufferSize)
 	service := ShortURLService{repo: repo, deleteMsgChanIn: deleteMsgChanIn, deleteMsgChanOut: deleteMsgChanOut, doneChan: doneChan}
+	attemptsWindow := time.Duration(config.Settings.PasswordAttemptsWindowSeconds) * time.Second
+	service.passwordAttempts = utils.NewAttemptLimiter(config.Settings.PasswordMaxAttempts, attemptsWindow)
+	service.linkAttempts = utils.NewAttemptLimiter(config.Settings.PasswordLinkMaxAttempts, attemptsWindow)
+	service.accountAttempts = utils.NewAttemptLimiter(config.Settings.LoginAccountMaxAttempts, attemptsWindow)
+	service.registerAttempts = utils.NewAttemptLimiter(config.Settings.RegisterMaxAttempts, attemptsWindow)
 	go service.FlushDeletions()
+	service.clicks = make(chan models.VariantClick, config.Settings.DefaultChannelsBufferSize)
+	go service.FlushClicks()
+	if config.Settings.MetadataWorkers > 0 {
+		service.metadataJobs = make(chan models.MetadataJob, config.Settings.DefaultChannelsBufferSize)
+		service.metadataTimeout = time.Duration(config.Settings.MetadataFetchTimeoutSeconds) * time.Second
+		service.fetcher = metadata.NewHTTPFetcher(
+			utils.URLPolicy{AllowPrivateNetworks: config.Settings.AllowPrivateNetworks}, service.metadataTimeout)
+		for i := 0; i < config.Settings.MetadataWorkers; i++ {
+			go service.FetchMetadata()
+		}
+	}
 	return service
 }
 
 // Create creates the short URL by passed original URL and connects it with the user. Generates the ID before saving to the storage.
-func (s *ShortURLService) Create(ctx context.Context, originalURL string, userID string) (string, error) {
+// The ID is unique within the domain of the short URL only, so the short URL is stored by the key of the domain and the ID.
+func (s *ShortURLService) Create(ctx context.Context, originalURL string, userID string, options models.ShortURLOptions) (string, error) {
+	if err := s.checkBanned(ctx, userID); err != nil {
+		return "", err
+	}
+	options, err := normalizeOptions(options)
+	if err != nil {
+		return "", err
+	}
+	if options.Domain, err = checkDomain(options.Domain, userID); err != nil {
+		return "", err
+	}
+	if err = s.checkUTMTemplate(ctx, options.UTMTemplateID, userID); err != nil {
+		return "", err
+	}
+	if err = s.checkCampaign(ctx, options.CampaignID, userID); err != nil {
+		return "", err
+	}
+	if err = s.checkOrganization(ctx, options.OrgID, userID); err != nil {
+		return "", err
+	}
 	var id string
 	for {
-		id = generateID()
+		id = domains.Key(options.Domain, generateID())
 		existingURLByID, _ := s.repo.Read(ctx, id)
 		if existingURLByID == "" {
 			break
 		}
 	}
-	shortURL, err := s.repo.Create(ctx, id, originalURL, userID)
+	shortURL, err := s.repo.Create(ctx, id, originalURL, userID, options)
 	if err != nil {
 		if !errors.Is(err, storage.ErrAlreadyExists) {
 			return "", err
 		}
+	} else {
+		s.scheduleMetadataFetch(shortURL, originalURL)
+		s.audit(ctx, AuditURLCreate, id, userID)
 	}
-	result := config.Settings.HostedOn + shortURL
-	_, fsWrapperErr := storage.FSWrapper.Create(id, originalURL, userID)
+	result := domains.ShortURL(shortURL)
+	_, fsWrapperErr := storage.FSWrapper.Create(id, originalURL, userID, options)
 	if fsWrapperErr != nil {
 		return "", fsWrapperErr
 	}
@@ -110,10 +522,125 @@ func (s *ShortURLService) Read(ctx context.Context, id string) (string, bool, er
 	return originalURL, deleted, nil
 }
 
+// Resolve reads the whole short URL record to decide how the visitor should be redirected.
+// Deleted URLs are returned as well, marked as deleted. The URLs followed outside their activation window
+// are not returned, the error tells whether the window is ahead or behind. Neither are the click-limited URLs
+// with no clicks left, the URLs disabled by the admin and the URLs created by the banned users.
+func (s *ShortURLService) Resolve(ctx context.Context, id string) (*models.ShortURL, error) {
+	shortURL, err := s.repo.ReadShortURL(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrShortURLNotFound
+		}
+		return nil, err
+	}
+	if shortURL.Moderation != nil {
+		return nil, ErrShortURLDisabled
+	}
+	if err = s.checkBanned(ctx, shortURL.UserID); err != nil {
+		if errors.Is(err, ErrUserBanned) {
+			return nil, ErrShortURLDisabled
+		}
+		return nil, err
+	}
+	if !shortURL.Deleted {
+		if err = checkActive(shortURL.RedirectOptions, time.Now()); err != nil {
+			return nil, err
+		}
+		if shortURL.ClickLimited() && shortURL.ClicksLeft <= 0 {
+			return nil, ErrClicksExhausted
+		}
+	}
+	return shortURL, nil
+}
+
+// UseClick takes one of the clicks left for the click-limited short URL the visitor is redirected by,
+// nothing is taken from the short URL without the limit. The storage takes the click atomically, so the clicks
+// left read by Resolve are only a hint: when the last click is raced for, all the visitors but one get ErrClicksExhausted.
+// Writes the taken click to the file (cold-storage) afterward. Taking the last click is recorded in the audit log
+// as made by the visitor, since the short URL is gone after it.
+func (s *ShortURLService) UseClick(ctx context.Context, shortURL *models.ShortURL) error {
+	if !shortURL.ClickLimited() {
+		return nil
+	}
+	clicksLeft, err := s.repo.UseClick(ctx, shortURL.ShortURL)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoClicksLeft) || errors.Is(err, storage.ErrNotFound) {
+			return ErrClicksExhausted
+		}
+		return err
+	}
+	if _, err = storage.FSWrapper.WriteUsedClick(shortURL.ShortURL); err != nil {
+		logger.Log.Warnf("Couldn't write the click on %s to file: %s", shortURL.ShortURL, err)
+	}
+	if clicksLeft == 0 {
+		s.audit(ctx, AuditURLClicksExhausted, shortURL.ShortURL, "")
+	}
+	return nil
+}
+
+// FillUsedClicks takes the clicks used according to the single row of file (cold-storage) from the storage (warm-storage).
+func (s *ShortURLService) FillUsedClicks(ctx context.Context, shortURL string, clicks int64) error {
+	for range clicks {
+		_, err := s.repo.UseClick(ctx, shortURL)
+		if errors.Is(err, storage.ErrNoClicksLeft) || errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkActive checks that the moment is within the activation window of the short URL.
+// The window is open from ActiveFrom inclusive until ActiveUntil exclusive, either side may be unlimited.
+func checkActive(options models.RedirectOptions, now time.Time) error {
+	if activeFrom, err := time.Parse(time.RFC3339, options.ActiveFrom); err == nil && now.Before(activeFrom) {
+		return NewErrNotActiveYet(ErrNotActiveYet, activeFrom)
+	}
+	if activeUntil, err := time.Parse(time.RFC3339, options.ActiveUntil); err == nil && !now.Before(activeUntil) {
+		return ErrNoLongerActive
+	}
+	return nil
+}
+
+// CheckPassword checks the password entered by the visitor of the protected short URL.
+// The attempts are limited per short URL and IP address of the visitor, and per short URL whatever the address is,
+// so changing the address doesn't give the unlimited guesses. The successful attempt isn't counted.
+func (s *ShortURLService) CheckPassword(shortURL *models.ShortURL, password string, clientIP string) error {
+	if !shortURL.Protected() {
+		return nil
+	}
+	keys := []utils.LimitedKey{
+		{Limiter: s.linkAttempts, Key: shortURL.ShortURL},
+		{Limiter: s.passwordAttempts, Key: shortURL.ShortURL + "|" + clientIP},
+	}
+	if allowed, retryAfter := utils.AllowAll(keys...); !allowed {
+		return NewErrTooManyAttempts(ErrTooManyAttempts, retryAfter)
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(shortURL.PasswordHash), []byte(password))
+	if err != nil {
+		if !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			utils.RefundAll(keys...)
+			return err
+		}
+		return ErrWrongPassword
+	}
+	utils.RefundAll(keys...)
+	return nil
+}
+
 // FillRow saves the single row of file (cold-storage) to the storage (warm-storage).
-func (s *ShortURLService) FillRow(ctx context.Context, originalURL string, shortURL string, userID string) error {
-	_, err := s.repo.Create(ctx, shortURL, originalURL, userID)
-	return err
+func (s *ShortURLService) FillRow(
+	ctx context.Context, originalURL string, shortURL string, userID string, options models.ShortURLOptions,
+	pageMetadata *models.PageMetadata) error {
+	options.Domain, _ = domains.Split(shortURL)
+	_, err := s.repo.Create(ctx, shortURL, originalURL, userID, options)
+	if err != nil || pageMetadata == nil {
+		return err
+	}
+	return s.repo.SetMetadata(ctx, shortURL, *pageMetadata)
 }
 
 // Ping pings the required dependencies.
@@ -125,9 +652,41 @@ func (s *ShortURLService) Ping(ctx context.Context) error {
 // short URLs with this user.
 func (s *ShortURLService) BatchCreate(
 	ctx context.Context, requestData []models.ShortenBatchItemRequest, userID string) ([]models.ShortenBatchItemResponse, error) {
+	if err := s.checkBanned(ctx, userID); err != nil {
+		return nil, err
+	}
 	URLs := make(map[string]models.ShortenBatchItemRequest)
+	checkedTemplates := make(map[string]bool)
+	checkedCampaigns := make(map[string]bool)
+	checkedOrganizations := make(map[string]bool)
 	for _, item := range requestData {
-		shortURL := generateID()
+		options, err := normalizeOptions(item.ShortURLOptions)
+		if err != nil {
+			return nil, err
+		}
+		if options.Domain, err = checkDomain(options.Domain, userID); err != nil {
+			return nil, err
+		}
+		if !checkedTemplates[options.UTMTemplateID] {
+			if err = s.checkUTMTemplate(ctx, options.UTMTemplateID, userID); err != nil {
+				return nil, err
+			}
+			checkedTemplates[options.UTMTemplateID] = true
+		}
+		if !checkedCampaigns[options.CampaignID] {
+			if err = s.checkCampaign(ctx, options.CampaignID, userID); err != nil {
+				return nil, err
+			}
+			checkedCampaigns[options.CampaignID] = true
+		}
+		if !checkedOrganizations[options.OrgID] {
+			if err = s.checkOrganization(ctx, options.OrgID, userID); err != nil {
+				return nil, err
+			}
+			checkedOrganizations[options.OrgID] = true
+		}
+		item.ShortURLOptions = options
+		shortURL := domains.Key(options.Domain, generateID())
 		URLs[shortURL] = item
 	}
 	result, err := s.repo.BatchCreate(ctx, URLs, userID)
@@ -136,129 +695,2284 @@ func (s *ShortURLService) BatchCreate(
 	}
 	for i := 0; i < len(result); i++ {
 		data := &result[i]
-		data.ShortURL = config.Settings.HostedOn + data.ShortURL
+		data.ShortURL = domains.ShortURL(data.ShortURL)
 	}
 	_, err = storage.FSWrapper.BatchCreate(URLs, userID)
 	if err != nil {
 		return nil, err
 	}
+	created := make([]string, 0, len(URLs))
+	for shortURL, item := range URLs {
+		s.scheduleMetadataFetch(shortURL, item.OriginalURL)
+		created = append(created, shortURL)
+	}
+	s.auditAll(ctx, AuditURLCreate, created, userID)
 	return result, nil
 }
 
-// ReadByUserID Reads all the URLs created by the current user.
-func (s *ShortURLService) ReadByUserID(ctx context.Context, userID string) ([]models.ShortURLsByUserResponse, error) {
-	result, err := s.repo.ReadByUserID(ctx, userID)
+// ReadByUserID Reads all the URLs created by the current user and matching the filter.
+// Returns ErrCampaignNotFound if the URLs are filtered by the campaign the user doesn't own.
+func (s *ShortURLService) ReadByUserID(ctx context.Context, userID string, filter models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
+	filter.Tag = strings.ToLower(strings.TrimSpace(filter.Tag))
+	filter.CampaignID = strings.TrimSpace(filter.CampaignID)
+	if filter.CampaignID != "" {
+		if _, err := s.readCampaign(ctx, filter.CampaignID, userID); err != nil {
+			return nil, err
+		}
+	}
+	result, err := s.repo.ReadByUserID(ctx, userID, filter)
 	if err != nil {
 		return nil, err
 	}
+	presentShortURLs(result)
+	return result, err
+}
+
+// presentShortURLs turns the keys of the listed short URLs into the full short URLs on their domains.
+func presentShortURLs(result []models.ShortURLsByUserResponse) {
 	for i := 0; i < len(result); i++ {
 		data := &result[i]
-		data.ShortURL = config.Settings.HostedOn + data.ShortURL
+		data.Domain, _ = domains.Split(data.ShortURL)
+		data.ShortURL = domains.ShortURL(data.ShortURL)
+		data.PasswordProtected = data.Protected()
 	}
-	return result, err
 }
 
-// FlushDeletions marks some scheduled deletions as deleted in the storage.
-func (s *ShortURLService) FlushDeletions() {
-	ticker := time.NewTicker(time.Duration(config.Settings.DeletionBufferFlushIntervalSeconds) * time.Second)
-
-	var shortURLsToDelete []string
-
-	for {
-		select {
-		case msg := <-s.deleteMsgChanOut:
-			shortURLsToDelete = append(shortURLsToDelete, msg)
-		case <-ticker.C:
-			if len(shortURLsToDelete) == 0 {
-				continue
-			}
-			err := s.repo.SetURLsInactive(context.TODO(), shortURLsToDelete)
-			if err != nil {
-				logger.Log.Warn("cannot delete URLs", zap.Error(err))
-				continue
-			}
-			shortURLsToDelete = nil
+// Update changes the optional attributes of the short URL the current user manages: the personal short URL
+// of the user or the short URL of the organization the user is at least an editor of. The banned user can't
+// change anything. Writes the actual state of the short URL to the file (cold-storage) afterward.
+func (s *ShortURLService) Update(
+	ctx context.Context, id string, userID string, update models.UpdateShortURLRequest) (*models.ShortURLsByUserResponse, error) {
+	if err := s.checkBanned(ctx, userID); err != nil {
+		return nil, err
+	}
+	shortURL, err := s.repo.ReadShortURL(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrShortURLNotFound
 		}
+		return nil, err
 	}
-}
-
-// ScheduleDeletionOfBatch Schedules the batch of short URLs for the deletion. Uses FanOut + FanIn.
-func (s *ShortURLService) ScheduleDeletionOfBatch(shortURLs []models.ShortURLChannelMessage) {
-	s.deletionGenerator(shortURLs)
-	channels := s.deletionFanOut()
-	s.deletionFanIn(channels...)
-}
-
-func (s *ShortURLService) deletionGenerator(input []models.ShortURLChannelMessage) {
-	go func() {
-		for _, item := range input {
-			select {
-			case <-s.doneChan:
-				return
-			case s.deleteMsgChanIn <- item:
-			}
+	if shortURL.Deleted {
+		return nil, ErrShortURLNotFound
+	}
+	if err = s.authorizeShortURL(ctx, shortURL, userID, models.RoleEditor); err != nil {
+		return nil, err
+	}
+	if update, err = normalizeUpdate(update); err != nil {
+		return nil, err
+	}
+	if update.ActiveFrom != nil || update.ActiveUntil != nil {
+		window := shortURL.RedirectOptions
+		update.ApplyRedirect(&window)
+		if err = checkActiveWindow(window); err != nil {
+			return nil, err
 		}
-	}()
+	}
+	if update.UTMTemplateID != nil {
+		if err = s.checkUTMTemplate(ctx, *update.UTMTemplateID, userID); err != nil {
+			return nil, err
+		}
+	}
+	if update.CampaignID != nil {
+		if err = s.checkCampaign(ctx, *update.CampaignID, userID); err != nil {
+			return nil, err
+		}
+	}
+	if err = s.repo.Update(ctx, id, update); err != nil {
+		return nil, err
+	}
+	if shortURL, err = s.repo.ReadShortURL(ctx, id); err != nil {
+		return nil, err
+	}
+	if _, err = storage.FSWrapper.Update(*shortURL); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditURLUpdate, id, userID)
+	shortURL.Domain, _ = domains.Split(shortURL.ShortURL)
+	return &models.ShortURLsByUserResponse{
+		ShortURL:          domains.ShortURL(shortURL.ShortURL),
+		OriginalURL:       shortURL.OriginalURL,
+		ShortURLOptions:   shortURL.ShortURLOptions,
+		Metadata:          shortURL.Metadata,
+		ClicksLeft:        clicksLeft(shortURL),
+		PasswordProtected: shortURL.Protected(),
+	}, nil
 }
 
-func (s *ShortURLService) deletionFanOut() []chan string {
-	numWorkers := 10
-	channels := make([]chan string, numWorkers)
-	for i := 0; i < numWorkers; i++ {
-		channels[i] = s.validateUser()
+// checkDomain returns the host of the branded domain the user creates the short URL on, or the empty string
+// for the default domain, which is open to everyone.
+func checkDomain(domain string, userID string) (string, error) {
+	domain = strings.TrimSpace(domain)
+	if domains.IsDefault(domain) {
+		return "", nil
 	}
-	return channels
+	configured, ok := domains.Lookup(domain)
+	if !ok {
+		return "", fmt.Errorf("%w: unknown domain %s", ErrInvalidOptions, domain)
+	}
+	if !configured.Allows(userID) {
+		return "", fmt.Errorf("%w: %s", ErrDomainNotAllowed, configured.Host)
+	}
+	return configured.Host, nil
 }
 
-func (s *ShortURLService) validateUser() chan string {
-	validateRes := make(chan string)
-	go func() {
-		defer close(validateRes)
-		for data := range s.deleteMsgChanIn {
-			currentUserID, err := s.repo.GetUserIDByShortURL(context.TODO(), data.ShortURL)
-			if err != nil {
-				logger.Log.Error("cannot get user ID", zap.String("shortURL", data.ShortURL), zap.Error(err))
-			}
-			if currentUserID == "" {
-				logger.Log.Infof("Skipping URL %s - not found in storage", data.ShortURL)
-				return
-			}
-			if currentUserID == data.UserID {
-				select {
-				case <-s.doneChan:
-					return
-				case validateRes <- data.ShortURL:
-				}
-			} else {
-				logger.Log.Infof("Skipping URL %s - user is not the owner", data.ShortURL)
-			}
-		}
-	}()
-	return validateRes
+// clicksLeft returns the clicks left for the click-limited short URL or nil if there is no limit.
+func clicksLeft(shortURL *models.ShortURL) *int64 {
+	if !shortURL.ClickLimited() {
+		return nil
+	}
+	left := shortURL.ClicksLeft
+	return &left
 }
 
-func (s *ShortURLService) deletionFanIn(channels ...chan string) {
-	for _, ch := range channels {
-		chClosure := ch
-
-		go func() {
-			for data := range chClosure {
-				select {
-				case <-s.doneChan:
-					return
-				case s.deleteMsgChanOut <- data:
-				}
-			}
-		}()
-
+// normalizeOptions trims the optional attributes of the short URL and checks their limits.
+// Replaces the plain password with its hash.
+func normalizeOptions(options models.ShortURLOptions) (models.ShortURLOptions, error) {
+	var err error
+	if options.Title, err = normalizeText(options.Title, maxTitleLength, "title"); err != nil {
+		return options, err
+	}
+	if options.Notes, err = normalizeText(options.Notes, maxNotesLength, "notes"); err != nil {
+		return options, err
+	}
+	if options.Tags, err = normalizeTags(options.Tags); err != nil {
+		return options, err
+	}
+	if options.RedirectOptions, err = normalizeRedirect(options.RedirectOptions); err != nil {
+		return options, err
 	}
+	options.UTMTemplateID = strings.TrimSpace(options.UTMTemplateID)
+	options.CampaignID = strings.TrimSpace(options.CampaignID)
+	options.OrgID = strings.TrimSpace(options.OrgID)
+	if options.MaxClicks < 0 {
+		return options, fmt.Errorf("%w: max clicks must not be negative", ErrInvalidOptions)
+	}
+	options.PasswordHash, err = hashPassword(options.Password)
+	options.Password = ""
+	return options, err
 }
 
-// GetStats Returns the number of users and URLs registered in the service.
-func (s *ShortURLService) GetStats(ctx context.Context) (*models.ServiceStats, error) {
-	stats, err := s.repo.GetStats(ctx)
+// hashPassword returns the salted hash of the password or the empty string if there is no password.
+func hashPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	if len(password) > maxPasswordLength {
+		return "", fmt.Errorf("%w: password is longer than %d bytes", ErrInvalidOptions, maxPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// normalizeUpdate applies the same rules as normalizeOptions to the fields that are going to be updated.
+func normalizeUpdate(update models.UpdateShortURLRequest) (models.UpdateShortURLRequest, error) {
+	if update.Title != nil {
+		title, err := normalizeText(*update.Title, maxTitleLength, "title")
+		if err != nil {
+			return update, err
+		}
+		update.Title = &title
+	}
+	if update.Notes != nil {
+		notes, err := normalizeText(*update.Notes, maxNotesLength, "notes")
+		if err != nil {
+			return update, err
+		}
+		update.Notes = &notes
+	}
+	if update.Tags != nil {
+		tags, err := normalizeTags(*update.Tags)
+		if err != nil {
+			return update, err
+		}
+		if tags == nil {
+			tags = []string{}
+		}
+		update.Tags = &tags
+	}
+	if update.RedirectStatus != nil {
+		if err := checkRedirectStatus(*update.RedirectStatus); err != nil {
+			return update, err
+		}
+	}
+	var err error
+	if update.CacheControl, err = normalizeHeaderUpdate(update.CacheControl, "cache_control"); err != nil {
+		return update, err
+	}
+	if update.RobotsTag, err = normalizeHeaderUpdate(update.RobotsTag, "robots_tag"); err != nil {
+		return update, err
+	}
+	if update.ReferrerPolicy, err = normalizeHeaderUpdate(update.ReferrerPolicy, "referrer_policy"); err != nil {
+		return update, err
+	}
+	if update.Passthrough != nil {
+		passthrough, passthroughErr := normalizePassthrough(*update.Passthrough)
+		if passthroughErr != nil {
+			return update, passthroughErr
+		}
+		update.Passthrough = &passthrough
+	}
+	if update.UTMTemplateID != nil {
+		utmTemplateID := strings.TrimSpace(*update.UTMTemplateID)
+		update.UTMTemplateID = &utmTemplateID
+	}
+	if update.CampaignID != nil {
+		campaignID := strings.TrimSpace(*update.CampaignID)
+		update.CampaignID = &campaignID
+	}
+	if update.Targets != nil {
+		targets, targetsErr := normalizeTargets(*update.Targets)
+		if targetsErr != nil {
+			return update, targetsErr
+		}
+		if targets == nil {
+			targets = []models.TargetingRule{}
+		}
+		update.Targets = &targets
+	}
+	if update.GeoRules != nil {
+		geoRules, geoRulesErr := normalizeGeoRules(*update.GeoRules)
+		if geoRulesErr != nil {
+			return update, geoRulesErr
+		}
+		if geoRules == nil {
+			geoRules = []models.GeoRule{}
+		}
+		update.GeoRules = &geoRules
+	}
+	if update.Variants != nil {
+		variants, variantsErr := normalizeVariants(*update.Variants)
+		if variantsErr != nil {
+			return update, variantsErr
+		}
+		if variants == nil {
+			variants = []models.SplitVariant{}
+		}
+		update.Variants = &variants
+	}
+	if update.ActiveFrom, err = normalizeTimeUpdate(update.ActiveFrom, "active_from"); err != nil {
+		return update, err
+	}
+	if update.ActiveUntil, err = normalizeTimeUpdate(update.ActiveUntil, "active_until"); err != nil {
+		return update, err
+	}
+	if update.ReferrerPolicy != nil {
+		err = checkReferrerPolicy(*update.ReferrerPolicy)
+	}
+	return update, err
+}
+
+func normalizeHeaderUpdate(value *string, field string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	normalized, err := normalizeHeaderValue(*value, field)
+	if err != nil {
+		return nil, err
+	}
+	return &normalized, nil
+}
+
+// normalizeRedirect trims the redirect attributes of the short URL and checks that they are allowed.
+func normalizeRedirect(options models.RedirectOptions) (models.RedirectOptions, error) {
+	var err error
+	if err = checkRedirectStatus(options.RedirectStatus); err != nil {
+		return options, err
+	}
+	if options.CacheControl, err = normalizeHeaderValue(options.CacheControl, "cache_control"); err != nil {
+		return options, err
+	}
+	if options.RobotsTag, err = normalizeHeaderValue(options.RobotsTag, "robots_tag"); err != nil {
+		return options, err
+	}
+	if options.ReferrerPolicy, err = normalizeHeaderValue(options.ReferrerPolicy, "referrer_policy"); err != nil {
+		return options, err
+	}
+	if options.Passthrough, err = normalizePassthrough(options.Passthrough); err != nil {
+		return options, err
+	}
+	if options.Targets, err = normalizeTargets(options.Targets); err != nil {
+		return options, err
+	}
+	if options.GeoRules, err = normalizeGeoRules(options.GeoRules); err != nil {
+		return options, err
+	}
+	if options.Variants, err = normalizeVariants(options.Variants); err != nil {
+		return options, err
+	}
+	if options.ActiveFrom, err = normalizeTime(options.ActiveFrom, "active_from"); err != nil {
+		return options, err
+	}
+	if options.ActiveUntil, err = normalizeTime(options.ActiveUntil, "active_until"); err != nil {
+		return options, err
+	}
+	if err = checkActiveWindow(options); err != nil {
+		return options, err
+	}
+	return options, checkReferrerPolicy(options.ReferrerPolicy)
+}
+
+// normalizeTargets lowercases the platforms of the targeting rules and checks that they are known
+// and their destinations are safe to redirect to. The order of the rules is kept.
+func normalizeTargets(rules []models.TargetingRule) ([]models.TargetingRule, error) {
+	if len(rules) > maxTargetsCount {
+		return nil, fmt.Errorf("%w: more than %d targets", ErrInvalidOptions, maxTargetsCount)
+	}
+	var result []models.TargetingRule
+	for _, rule := range rules {
+		rule.Platform = strings.ToLower(strings.TrimSpace(rule.Platform))
+		rule.URL = strings.TrimSpace(rule.URL)
+		if !slices.Contains(utils.TargetingPlatforms, rule.Platform) {
+			return nil, fmt.Errorf("%w: unknown target platform %q", ErrInvalidOptions, rule.Platform)
+		}
+		if !utils.IsDeepLink(rule.URL) {
+			return nil, fmt.Errorf("%w: target url %q is invalid", ErrInvalidOptions, rule.URL)
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+// normalizeGeoRules uppercases the country codes of the geo rules and checks that they are well-formed, unique
+// and their destinations are safe to redirect to. The order of the rules is kept.
+func normalizeGeoRules(rules []models.GeoRule) ([]models.GeoRule, error) {
+	if len(rules) > maxGeoRulesCount {
+		return nil, fmt.Errorf("%w: more than %d geo rules", ErrInvalidOptions, maxGeoRulesCount)
+	}
+	var result []models.GeoRule
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		rule.Country = strings.ToUpper(strings.TrimSpace(rule.Country))
+		rule.URL = strings.TrimSpace(rule.URL)
+		if !isCountryCode(rule.Country) {
+			return nil, fmt.Errorf("%w: invalid country code %q", ErrInvalidOptions, rule.Country)
+		}
+		if seen[rule.Country] {
+			return nil, fmt.Errorf("%w: duplicate geo rule for %q", ErrInvalidOptions, rule.Country)
+		}
+		if !utils.IsDeepLink(rule.URL) {
+			return nil, fmt.Errorf("%w: geo rule url %q is invalid", ErrInvalidOptions, rule.URL)
+		}
+		seen[rule.Country] = true
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+// isCountryCode reports whether the code looks like the ISO 3166-1 alpha-2 one.
+func isCountryCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+	for _, char := range code {
+		if char < 'A' || char > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeVariants trims the split variants of the short URL and checks that their names are unique and fit the cookie,
+// and their destinations are safe to redirect to. The variants without the name are named by their position,
+// the ones without the weight get the weight of 1. The order of the variants is kept.
+func normalizeVariants(variants []models.SplitVariant) ([]models.SplitVariant, error) {
+	if len(variants) > maxVariantsCount {
+		return nil, fmt.Errorf("%w: more than %d variants", ErrInvalidOptions, maxVariantsCount)
+	}
+	var result []models.SplitVariant
+	seen := make(map[string]bool, len(variants))
+	for i, variant := range variants {
+		variant.Name = strings.TrimSpace(variant.Name)
+		variant.URL = strings.TrimSpace(variant.URL)
+		if variant.Name == "" {
+			variant.Name = strconv.Itoa(i + 1)
+		}
+		if variant.Weight == 0 {
+			variant.Weight = 1
+		}
+		if !isVariantName(variant.Name) {
+			return nil, fmt.Errorf("%w: invalid variant name %q", ErrInvalidOptions, variant.Name)
+		}
+		if seen[variant.Name] {
+			return nil, fmt.Errorf("%w: duplicate variant %q", ErrInvalidOptions, variant.Name)
+		}
+		if variant.Weight < 0 || variant.Weight > maxVariantWeight {
+			return nil, fmt.Errorf("%w: variant weight must be between 1 and %d", ErrInvalidOptions, maxVariantWeight)
+		}
+		if !utils.IsDeepLink(variant.URL) {
+			return nil, fmt.Errorf("%w: variant url %q is invalid", ErrInvalidOptions, variant.URL)
+		}
+		seen[variant.Name] = true
+		result = append(result, variant)
+	}
+	return result, nil
+}
+
+// isVariantName reports whether the name consists of the letters, digits, dashes, underscores and dots only,
+// so it can be kept in the cookie as is.
+func isVariantName(name string) bool {
+	if len(name) > maxVariantNameLength {
+		return false
+	}
+	for _, char := range name {
+		switch {
+		case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z', char >= '0' && char <= '9':
+		case char == '-' || char == '_' || char == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeTime trims the RFC 3339 time and converts it to UTC, the empty one is kept as no limit.
+func normalizeTime(value string, field string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s must be the RFC 3339 time", ErrInvalidOptions, field)
+	}
+	return parsed.UTC().Format(time.RFC3339), nil
+}
+
+func normalizeTimeUpdate(value *string, field string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	normalized, err := normalizeTime(*value, field)
+	if err != nil {
+		return nil, err
+	}
+	return &normalized, nil
+}
+
+// checkActiveWindow checks that the activation window of the short URL isn't empty.
+func checkActiveWindow(options models.RedirectOptions) error {
+	if options.ActiveFrom == "" || options.ActiveUntil == "" {
+		return nil
+	}
+	activeFrom, _ := time.Parse(time.RFC3339, options.ActiveFrom)
+	activeUntil, _ := time.Parse(time.RFC3339, options.ActiveUntil)
+	if !activeUntil.After(activeFrom) {
+		return fmt.Errorf("%w: active_until must be after active_from", ErrInvalidOptions)
+	}
+	return nil
+}
+
+// normalizePassthrough lowercases the passthrough mode and checks that it is known, "none" is the same as empty.
+func normalizePassthrough(mode string) (string, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "none":
+		return models.PassthroughNone, nil
+	case models.PassthroughNone, models.PassthroughQuery, models.PassthroughPath:
+		return mode, nil
+	}
+	return "", fmt.Errorf("%w: unknown passthrough mode %q", ErrInvalidOptions, mode)
+}
+
+func checkRedirectStatus(status int) error {
+	if !slices.Contains(redirectStatuses, status) {
+		return fmt.Errorf("%w: redirect status %d is not one of 301, 302, 307, 308", ErrInvalidOptions, status)
+	}
+	return nil
+}
+
+func checkReferrerPolicy(policy string) error {
+	if !slices.Contains(referrerPolicies, policy) {
+		return fmt.Errorf("%w: unknown referrer policy %q", ErrInvalidOptions, policy)
+	}
+	return nil
+}
+
+// normalizeHeaderValue trims the value that is sent to the visitors as the header and checks that it is safe to send.
+func normalizeHeaderValue(value string, field string) (string, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > maxHeaderValueLength {
+		return "", fmt.Errorf("%w: %s is longer than %d bytes", ErrInvalidOptions, field, maxHeaderValueLength)
+	}
+	if !httpguts.ValidHeaderFieldValue(value) {
+		return "", fmt.Errorf("%w: %s contains invalid characters", ErrInvalidOptions, field)
+	}
+	return value, nil
+}
+
+func normalizeText(text string, maxLength int, field string) (string, error) {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > maxLength {
+		return "", fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidOptions, field, maxLength)
+	}
+	return text, nil
+}
+
+// normalizeTags lowercases and trims the tags, removes empty ones and duplicates and sorts the rest.
+func normalizeTags(tags []string) ([]string, error) {
+	var result []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(result, tag) {
+			continue
+		}
+		if strings.Contains(tag, ",") {
+			return nil, fmt.Errorf("%w: tag %q must not contain commas", ErrInvalidOptions, tag)
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidOptions, tag, maxTagLength)
+		}
+		result = append(result, tag)
+	}
+	if len(result) > maxTagsCount {
+		return nil, fmt.Errorf("%w: more than %d tags", ErrInvalidOptions, maxTagsCount)
+	}
+	slices.Sort(result)
+	return result, nil
+}
+
+// checkUTMTemplate checks that the UTM template attached to the short URL exists and belongs to the user.
+// The empty ID means no template.
+func (s *ShortURLService) checkUTMTemplate(ctx context.Context, id string, userID string) error {
+	if id == "" {
+		return nil
+	}
+	if _, err := s.readUTMTemplate(ctx, id, userID); err != nil {
+		if errors.Is(err, ErrUTMTemplateNotFound) {
+			return fmt.Errorf("%w: unknown utm template %q", ErrInvalidOptions, id)
+		}
+		return err
+	}
+	return nil
+}
+
+// CreateUTMTemplate creates the UTM template owned by the current user. Generates the ID before saving to the storage.
+// Writes the template to the file (cold-storage) afterward.
+func (s *ShortURLService) CreateUTMTemplate(
+	ctx context.Context, userID string, template models.UTMTemplate) (*models.UTMTemplate, error) {
+	template, err := normalizeUTMTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	template.ID = uuid.New().String()
+	template.UserID = userID
+	if err = s.repo.CreateUTMTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+	if _, err = storage.FSWrapper.WriteUTMTemplate(template); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditUTMTemplateCreate, template.ID, userID)
+	return &template, nil
+}
+
+// ReadUTMTemplatesByUserID reads all the UTM templates created by the current user.
+func (s *ShortURLService) ReadUTMTemplatesByUserID(ctx context.Context, userID string) ([]models.UTMTemplate, error) {
+	return s.repo.ReadUTMTemplatesByUserID(ctx, userID)
+}
+
+// UpdateUTMTemplate replaces the name and the parameters of the UTM template owned by the current user.
+// The short URLs using the template get the new parameters on the next redirect.
+// Writes the actual state of the template to the file (cold-storage) afterward.
+func (s *ShortURLService) UpdateUTMTemplate(
+	ctx context.Context, id string, userID string, template models.UTMTemplate) (*models.UTMTemplate, error) {
+	existing, err := s.readUTMTemplate(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if template, err = normalizeUTMTemplate(template); err != nil {
+		return nil, err
+	}
+	template.ID = existing.ID
+	template.UserID = existing.UserID
+	if err = s.repo.UpdateUTMTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+	if _, err = storage.FSWrapper.WriteUTMTemplate(template); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditUTMTemplateUpdate, template.ID, userID)
+	return &template, nil
+}
+
+// DeleteUTMTemplate deletes the UTM template owned by the current user, the short URLs using it are redirected
+// without the UTM parameters afterward. Writes the deletion to the file (cold-storage).
+func (s *ShortURLService) DeleteUTMTemplate(ctx context.Context, id string, userID string) error {
+	template, err := s.readUTMTemplate(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if err = s.repo.DeleteUTMTemplate(ctx, template.ID); err != nil {
+		return err
+	}
+	if _, err = storage.FSWrapper.DeleteUTMTemplate(*template); err != nil {
+		return err
+	}
+	s.audit(ctx, AuditUTMTemplateDelete, template.ID, userID)
+	return nil
+}
+
+// FillUTMTemplate saves the UTM template from the single row of file (cold-storage) to the storage (warm-storage).
+func (s *ShortURLService) FillUTMTemplate(ctx context.Context, template models.UTMTemplate, deleted bool) error {
+	if !deleted {
+		return s.repo.CreateUTMTemplate(ctx, template)
+	}
+	err := s.repo.DeleteUTMTemplate(ctx, template.ID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// readUTMTemplate reads the UTM template owned by the user. The templates of other users are not found,
+// so their IDs can't be probed.
+func (s *ShortURLService) readUTMTemplate(ctx context.Context, id string, userID string) (*models.UTMTemplate, error) {
+	template, err := s.repo.ReadUTMTemplate(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrUTMTemplateNotFound
+		}
+		return nil, err
+	}
+	if template.UserID != userID {
+		return nil, ErrUTMTemplateNotFound
+	}
+	return template, nil
+}
+
+// normalizeUTMTemplate trims the name and the parameters of the UTM template and checks their limits.
+// The template must have the name and at least one parameter.
+func normalizeUTMTemplate(template models.UTMTemplate) (models.UTMTemplate, error) {
+	var err error
+	if template.Name, err = normalizeUTMValue(template.Name, "name"); err != nil {
+		return template, err
+	}
+	if template.Name == "" {
+		return template, fmt.Errorf("%w: name is required", ErrInvalidUTMTemplate)
+	}
+	parameters := &template.UTMParameters
+	fields := []struct {
+		value *string
+		name  string
+	}{
+		{&parameters.Source, "utm_source"},
+		{&parameters.Medium, "utm_medium"},
+		{&parameters.Campaign, "utm_campaign"},
+		{&parameters.Term, "utm_term"},
+		{&parameters.Content, "utm_content"},
+	}
+	for _, field := range fields {
+		if *field.value, err = normalizeUTMValue(*field.value, field.name); err != nil {
+			return template, err
+		}
+	}
+	if parameters.RawQuery() == "" {
+		return template, fmt.Errorf("%w: at least one utm parameter is required", ErrInvalidUTMTemplate)
+	}
+	return template, nil
+}
+
+func normalizeUTMValue(value string, field string) (string, error) {
+	value = strings.TrimSpace(value)
+	if utf8.RuneCountInString(value) > maxUTMValueLength {
+		return "", fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidUTMTemplate, field, maxUTMValueLength)
+	}
+	return value, nil
+}
+
+// checkCampaign checks that the campaign the short URL is grouped into exists and belongs to the user.
+// The empty ID means no campaign.
+func (s *ShortURLService) checkCampaign(ctx context.Context, id string, userID string) error {
+	if id == "" {
+		return nil
+	}
+	if _, err := s.readCampaign(ctx, id, userID); err != nil {
+		if errors.Is(err, ErrCampaignNotFound) {
+			return fmt.Errorf("%w: unknown campaign %q", ErrInvalidOptions, id)
+		}
+		return err
+	}
+	return nil
+}
+
+// CreateCampaign creates the campaign owned by the current user. Generates the ID before saving to the storage.
+// Writes the campaign to the file (cold-storage) afterward.
+func (s *ShortURLService) CreateCampaign(
+	ctx context.Context, userID string, campaign models.Campaign) (*models.Campaign, error) {
+	campaign, err := normalizeCampaign(campaign)
+	if err != nil {
+		return nil, err
+	}
+	campaign.ID = uuid.New().String()
+	campaign.UserID = userID
+	if err = s.repo.CreateCampaign(ctx, campaign); err != nil {
+		return nil, err
+	}
+	if _, err = storage.FSWrapper.WriteCampaign(campaign); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditCampaignCreate, campaign.ID, userID)
+	return &campaign, nil
+}
+
+// ReadCampaignsByUserID reads all the campaigns created by the current user.
+func (s *ShortURLService) ReadCampaignsByUserID(ctx context.Context, userID string) ([]models.Campaign, error) {
+	return s.repo.ReadCampaignsByUserID(ctx, userID)
+}
+
+// UpdateCampaign renames the campaign owned by the current user.
+// Writes the actual state of the campaign to the file (cold-storage) afterward.
+func (s *ShortURLService) UpdateCampaign(
+	ctx context.Context, id string, userID string, campaign models.Campaign) (*models.Campaign, error) {
+	existing, err := s.readCampaign(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign, err = normalizeCampaign(campaign); err != nil {
+		return nil, err
+	}
+	campaign.ID = existing.ID
+	campaign.UserID = existing.UserID
+	if err = s.repo.UpdateCampaign(ctx, campaign); err != nil {
+		return nil, err
+	}
+	if _, err = storage.FSWrapper.WriteCampaign(campaign); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditCampaignUpdate, campaign.ID, userID)
+	return &campaign, nil
+}
+
+// DeleteCampaign deletes the campaign owned by the current user and writes the deletion to the file (cold-storage).
+// The short URLs of the campaign are scheduled for the deletion the same way as the batch deleted by the user.
+func (s *ShortURLService) DeleteCampaign(ctx context.Context, id string, userID string) error {
+	campaign, err := s.readCampaign(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	shortURLs, err := s.repo.ReadByUserID(ctx, userID, models.ShortURLFilter{CampaignID: campaign.ID})
+	if err != nil {
+		return err
+	}
+	if err = s.repo.DeleteCampaign(ctx, campaign.ID); err != nil {
+		return err
+	}
+	if _, err = storage.FSWrapper.DeleteCampaign(*campaign); err != nil {
+		return err
+	}
+	s.audit(ctx, AuditCampaignDelete, campaign.ID, userID)
+	messages := make([]models.ShortURLChannelMessage, len(shortURLs))
+	for i, shortURL := range shortURLs {
+		messages[i] = models.ShortURLChannelMessage{Actor: ActorFromContext(ctx), ShortURL: shortURL.ShortURL, UserID: userID}
+	}
+	s.ScheduleDeletionOfBatch(messages)
+	return nil
+}
+
+// GetCampaignStats returns the amount of the active short URLs in the campaign owned by the current user
+// and the clicks counted on their split variants.
+func (s *ShortURLService) GetCampaignStats(ctx context.Context, id string, userID string) (*models.CampaignStats, error) {
+	if _, err := s.readCampaign(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.GetCampaignStats(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrCampaignNotFound
+		}
+		return nil, err
+	}
+	return stats, nil
+}
+
+// FillCampaign saves the campaign from the single row of file (cold-storage) to the storage (warm-storage).
+func (s *ShortURLService) FillCampaign(ctx context.Context, campaign models.Campaign, deleted bool) error {
+	if !deleted {
+		return s.repo.CreateCampaign(ctx, campaign)
+	}
+	err := s.repo.DeleteCampaign(ctx, campaign.ID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// readCampaign reads the campaign owned by the user. The campaigns of other users are not found,
+// so their IDs can't be probed.
+func (s *ShortURLService) readCampaign(ctx context.Context, id string, userID string) (*models.Campaign, error) {
+	campaign, err := s.repo.ReadCampaign(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrCampaignNotFound
+		}
+		return nil, err
+	}
+	if campaign.UserID != userID {
+		return nil, ErrCampaignNotFound
+	}
+	return campaign, nil
+}
+
+// normalizeCampaign trims the name of the campaign and checks its limit, the name is required.
+func normalizeCampaign(campaign models.Campaign) (models.Campaign, error) {
+	campaign.Name = strings.TrimSpace(campaign.Name)
+	if campaign.Name == "" {
+		return campaign, fmt.Errorf("%w: name is required", ErrInvalidCampaign)
+	}
+	if utf8.RuneCountInString(campaign.Name) > maxCampaignNameLength {
+		return campaign, fmt.Errorf("%w: name is longer than %d characters", ErrInvalidCampaign, maxCampaignNameLength)
+	}
+	return campaign, nil
+}
+
+// authorizeShortURL checks that the user may act on the short URL with the role at least as high as minRole.
+// The personal short URL is managed by its author only, whatever the role is. The short URL of the organization
+// is managed by its members according to their roles, the author included. Returns ErrForbidden otherwise.
+func (s *ShortURLService) authorizeShortURL(
+	ctx context.Context, shortURL *models.ShortURL, userID string, minRole string) error {
+	if shortURL.OrgID == "" {
+		if shortURL.UserID != userID {
+			return ErrForbidden
+		}
+		return nil
+	}
+	_, err := s.authorizeOrg(ctx, shortURL.OrgID, userID, minRole)
+	if errors.Is(err, ErrOrganizationNotFound) || errors.Is(err, ErrOrgForbidden) {
+		return ErrForbidden
+	}
+	return err
+}
+
+// authorizeOrg returns the membership of the user in the organization with the role at least as high as minRole.
+// The organizations the user isn't a member of are not found, so their IDs can't be probed; the members
+// with the lower role get ErrOrgForbidden.
+func (s *ShortURLService) authorizeOrg(
+	ctx context.Context, orgID string, userID string, minRole string) (*models.OrgMember, error) {
+	member, err := s.repo.ReadOrgMember(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	if roleRanks[member.Role] < roleRanks[minRole] {
+		return nil, fmt.Errorf("%w: %s is required", ErrOrgForbidden, minRole)
+	}
+	return member, nil
+}
+
+// checkOrganization checks that the user may create the short URL owned by the organization, which requires
+// at least the editor role. The empty ID means the personal short URL.
+func (s *ShortURLService) checkOrganization(ctx context.Context, id string, userID string) error {
+	if id == "" {
+		return nil
+	}
+	_, err := s.authorizeOrg(ctx, id, userID, models.RoleEditor)
+	if errors.Is(err, ErrOrganizationNotFound) {
+		return fmt.Errorf("%w: unknown organization %q", ErrInvalidOptions, id)
+	}
+	return err
+}
+
+// CreateOrganization creates the organization with the current user as its owner. Generates the ID before saving
+// to the storage. Writes the organization to the file (cold-storage) afterward.
+func (s *ShortURLService) CreateOrganization(
+	ctx context.Context, userID string, org models.Organization) (*models.Organization, error) {
+	name, err := normalizeOrgName(org.Name)
+	if err != nil {
+		return nil, err
+	}
+	org = models.Organization{ID: uuid.New().String(), Name: name, CreatedAt: time.Now().UTC()}
+	owner := models.OrgMember{OrgID: org.ID, UserID: userID, Role: models.RoleOwner, CreatedAt: org.CreatedAt}
+	if err = s.repo.CreateOrganization(ctx, org, owner); err != nil {
+		return nil, err
+	}
+	if _, err = storage.FSWrapper.WriteOrganization(org, userID); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditOrganizationCreate, org.ID, userID)
+	org.Role = owner.Role
+	return &org, nil
+}
+
+// ReadOrganizationsByUserID reads all the organizations the current user is a member of, with the role of the user.
+func (s *ShortURLService) ReadOrganizationsByUserID(ctx context.Context, userID string) ([]models.Organization, error) {
+	return s.repo.ReadOrganizationsByUserID(ctx, userID)
+}
+
+// ReadOrgMembers reads all the members of the organization the current user is a member of, with their emails.
+func (s *ShortURLService) ReadOrgMembers(ctx context.Context, orgID string, userID string) ([]models.OrgMember, error) {
+	if _, err := s.authorizeOrg(ctx, orgID, userID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ReadOrgMembers(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range members {
+		account, err := s.repo.ReadAccount(ctx, members[i].UserID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		members[i].Email = account.Email
+	}
+	return members, nil
+}
+
+// AddOrgMember adds the account found by the email to the organization with the role. Requires at least the admin
+// role, only the owners can add the owners. Writes the member to the file (cold-storage) afterward.
+func (s *ShortURLService) AddOrgMember(
+	ctx context.Context, orgID string, userID string, request models.AddOrgMemberRequest) (*models.OrgMember, error) {
+	current, err := s.authorizeOrg(ctx, orgID, userID, models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	role, err := normalizeRole(request.Role)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkGrant(current, role); err != nil {
+		return nil, err
+	}
+	account, err := s.repo.ReadAccountByEmail(ctx, strings.ToLower(strings.TrimSpace(request.Email)))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("%w: no account with the email %q", ErrInvalidOrganization, request.Email)
+		}
+		return nil, err
+	}
+	_, err = s.repo.ReadOrgMember(ctx, orgID, account.ID)
+	switch {
+	case err == nil:
+		return nil, fmt.Errorf("%w: %s is a member already", ErrInvalidOrganization, account.Email)
+	case !errors.Is(err, storage.ErrNotFound):
+		return nil, err
+	}
+	member := models.OrgMember{OrgID: orgID, UserID: account.ID, Role: role, CreatedAt: time.Now().UTC()}
+	if err = s.saveOrgMember(ctx, member); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditOrgMemberAdd, orgMemberTarget(member), userID)
+	member.Email = account.Email
+	return &member, nil
+}
+
+// UpdateOrgMember changes the role of the member of the organization. Requires at least the admin role, only
+// the owners can change the role of the owners or grant it, the last owner can't be demoted.
+// Writes the member to the file (cold-storage) afterward.
+func (s *ShortURLService) UpdateOrgMember(
+	ctx context.Context, orgID string, memberID string, userID string, role string) (*models.OrgMember, error) {
+	current, err := s.authorizeOrg(ctx, orgID, userID, models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if role, err = normalizeRole(role); err != nil {
+		return nil, err
+	}
+	member, err := s.readOrgMember(ctx, orgID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkGrant(current, member.Role); err != nil {
+		return nil, err
+	}
+	if err = checkGrant(current, role); err != nil {
+		return nil, err
+	}
+	if member.Role == models.RoleOwner && role != models.RoleOwner {
+		if err = s.checkOtherOwners(ctx, orgID, memberID); err != nil {
+			return nil, err
+		}
+	}
+	member.Role = role
+	if err = s.saveOrgMember(ctx, *member); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditOrgMemberUpdate, orgMemberTarget(*member), userID)
+	return member, nil
+}
+
+// RemoveOrgMember removes the member from the organization. Requires at least the admin role, unless the member
+// leaves the organization; only the owners can remove the owners, the last owner can't leave.
+// Writes the removal to the file (cold-storage) afterward.
+func (s *ShortURLService) RemoveOrgMember(ctx context.Context, orgID string, memberID string, userID string) error {
+	minRole := models.RoleAdmin
+	if memberID == userID {
+		minRole = models.RoleViewer
+	}
+	current, err := s.authorizeOrg(ctx, orgID, userID, minRole)
+	if err != nil {
+		return err
+	}
+	member, err := s.readOrgMember(ctx, orgID, memberID)
+	if err != nil {
+		return err
+	}
+	if err = checkGrant(current, member.Role); err != nil {
+		return err
+	}
+	if member.Role == models.RoleOwner {
+		if err = s.checkOtherOwners(ctx, orgID, memberID); err != nil {
+			return err
+		}
+	}
+	if err = s.repo.DeleteOrgMember(ctx, orgID, memberID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrOrgMemberNotFound
+		}
+		return err
+	}
+	if _, err = storage.FSWrapper.DeleteOrgMember(*member); err != nil {
+		return err
+	}
+	s.audit(ctx, AuditOrgMemberRemove, orgMemberTarget(*member), userID)
+	return nil
+}
+
+// ReadByOrgID reads all the URLs of the organization the current user is a member of, matching the filter.
+// The URLs are filtered by the tag only, the campaigns are personal.
+func (s *ShortURLService) ReadByOrgID(
+	ctx context.Context, orgID string, userID string, filter models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
+	if _, err := s.authorizeOrg(ctx, orgID, userID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	filter = models.ShortURLFilter{Tag: strings.ToLower(strings.TrimSpace(filter.Tag))}
+	result, err := s.repo.ReadByOrgID(ctx, orgID, filter)
+	if err != nil {
+		return nil, err
+	}
+	presentShortURLs(result)
+	return result, nil
+}
+
+// FillOrganization saves the organization from the single row of file (cold-storage) to the storage (warm-storage)
+// together with its first owner.
+func (s *ShortURLService) FillOrganization(ctx context.Context, org models.Organization, ownerID string) error {
+	owner := models.OrgMember{OrgID: org.ID, UserID: ownerID, Role: models.RoleOwner, CreatedAt: org.CreatedAt}
+	return s.repo.CreateOrganization(ctx, org, owner)
+}
+
+// FillOrgMember saves the member from the single row of file (cold-storage) to the storage (warm-storage).
+func (s *ShortURLService) FillOrgMember(ctx context.Context, member models.OrgMember, deleted bool) error {
+	var err error
+	if deleted {
+		err = s.repo.DeleteOrgMember(ctx, member.OrgID, member.UserID)
+	} else {
+		err = s.repo.SaveOrgMember(ctx, member)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// readOrgMember reads the member of the organization to change.
+func (s *ShortURLService) readOrgMember(ctx context.Context, orgID string, memberID string) (*models.OrgMember, error) {
+	member, err := s.repo.ReadOrgMember(ctx, orgID, memberID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrOrgMemberNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// saveOrgMember saves the member to the storage and writes it to the file (cold-storage).
+func (s *ShortURLService) saveOrgMember(ctx context.Context, member models.OrgMember) error {
+	if err := s.repo.SaveOrgMember(ctx, member); err != nil {
+		return err
+	}
+	_, err := storage.FSWrapper.WriteOrgMember(member)
+	return err
+}
+
+// checkOtherOwners checks that the organization keeps an owner besides the member who stops being one.
+func (s *ShortURLService) checkOtherOwners(ctx context.Context, orgID string, memberID string) error {
+	members, err := s.repo.ReadOrgMembers(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if member.Role == models.RoleOwner && member.UserID != memberID {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: the organization must keep an owner", ErrInvalidOrganization)
+}
+
+// checkGrant checks that the current member may manage the members with the role: the admins manage everyone
+// but the owners, the owners manage everyone.
+func checkGrant(current *models.OrgMember, role string) error {
+	if role == models.RoleOwner && current.Role != models.RoleOwner {
+		return fmt.Errorf("%w: only the owners manage the owners", ErrOrgForbidden)
+	}
+	return nil
+}
+
+// normalizeRole lowercases the role of the member and checks it is one of the known roles.
+func normalizeRole(role string) (string, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("%w: unknown role %q", ErrInvalidOrganization, role)
+	}
+	return role, nil
+}
+
+// normalizeOrgName trims the name of the organization and checks its limit, the name is required.
+func normalizeOrgName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidOrganization)
+	}
+	if utf8.RuneCountInString(name) > maxOrgNameLength {
+		return "", fmt.Errorf("%w: name is longer than %d characters", ErrInvalidOrganization, maxOrgNameLength)
+	}
+	return name, nil
+}
+
+// Register creates the account with the email and the password hashed with the salted bcrypt.
+// The account claiming the links takes over the ID of the current anonymous user, so its short URLs, templates
+// and campaigns stay owned by the account; otherwise the account gets the new ID.
+// The registrations are limited per IP address of the client. Writes the account to the file (cold-storage) afterward.
+func (s *ShortURLService) Register(
+	ctx context.Context, credentials models.Credentials, userID string, clientIP string) (*models.Account, error) {
+	if allowed, retryAfter := s.registerAttempts.Allow("register|" + clientIP); !allowed {
+		return nil, NewErrTooManyAttempts(ErrTooManyAttempts, retryAfter)
+	}
+	email, err := normalizeEmail(credentials.Email)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkAccountPassword(credentials.Password); err != nil {
+		return nil, err
+	}
+	account := models.Account{ID: uuid.New().String(), Email: email, RegisteredAt: time.Now().UTC()}
+	if credentials.ClaimLinks && userID != "" {
+		_, err = s.repo.ReadAccount(ctx, userID)
+		switch {
+		case err == nil:
+			return nil, fmt.Errorf("%w: the current user is registered already", ErrInvalidAccount)
+		case !errors.Is(err, storage.ErrNotFound):
+			return nil, err
+		}
+		account.ID = userID
+	}
+	if account.PasswordHash, err = hashPassword(credentials.Password); err != nil {
+		return nil, err
+	}
+	if err = s.repo.CreateAccount(ctx, account); err != nil {
+		if errors.Is(err, storage.ErrAlreadyExists) {
+			return nil, ErrAccountExists
+		}
+		return nil, err
+	}
+	if _, err = storage.FSWrapper.WriteAccount(account); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditAccountRegister, account.ID, account.ID)
+	if account.ID == userID {
+		s.audit(ctx, AuditAccountClaimLinks, account.ID, account.ID)
+	}
+	return &account, nil
+}
+
+// Login checks the credentials and returns the account the user logs in to.
+// The attempts are limited per email and IP address of the client, and per email whatever the address is,
+// the successful attempt isn't counted. The password is compared with the dummy hash if there is no account
+// with its own password, so the response time doesn't tell the registered emails.
+func (s *ShortURLService) Login(ctx context.Context, credentials models.Credentials, clientIP string) (*models.Account, error) {
+	email := strings.ToLower(strings.TrimSpace(credentials.Email))
+	keys := []utils.LimitedKey{
+		{Limiter: s.accountAttempts, Key: "login|" + email},
+		{Limiter: s.passwordAttempts, Key: "login|" + email + "|" + clientIP},
+	}
+	if allowed, retryAfter := utils.AllowAll(keys...); !allowed {
+		return nil, NewErrTooManyAttempts(ErrTooManyAttempts, retryAfter)
+	}
+	account, err := s.repo.ReadAccountByEmail(ctx, email)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		utils.RefundAll(keys...)
+		return nil, err
+	}
+	passwordHash := dummyPasswordHash()
+	if err == nil && account.PasswordHash != "" {
+		// Otherwise, the account signs in with the identity provider only.
+		passwordHash = []byte(account.PasswordHash)
+	}
+	err = bcrypt.CompareHashAndPassword(passwordHash, []byte(credentials.Password))
+	if err != nil {
+		if !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			utils.RefundAll(keys...)
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+	if account == nil || account.PasswordHash == "" {
+		return nil, ErrInvalidCredentials
+	}
+	utils.RefundAll(keys...)
+	return account, nil
+}
+
+// dummyPasswordHash returns the hash compared with the password when there is no real one, it takes as long
+// to compare as the real hashes. Matching it never logs in.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// LoginOIDC returns the account linked to the user of the identity provider. On the first login the identity is
+// linked to the account with the same email if both the provider and the account have it verified, otherwise
+// the new account without the password is created. The account with the same email nobody confirmed doesn't block
+// the email verified by the provider, see deleteUnverifiedAccount. The new account claiming the links takes over
+// the current anonymous user, so the short URLs created before the login are kept, like Register does.
+// Writes the new account and identity to the file (cold-storage) afterward.
+func (s *ShortURLService) LoginOIDC(ctx context.Context, login models.OIDCLogin, userID string) (*models.Account, error) {
+	identity, err := s.repo.ReadOIDCIdentity(ctx, login.Issuer, login.Subject)
+	if err == nil {
+		return s.repo.ReadAccount(ctx, identity.UserID)
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	email, err := normalizeEmail(login.Email)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the identity provider shares no valid email", ErrInvalidAccount)
+	}
+	account, err := s.repo.ReadAccountByEmail(ctx, email)
+	if err == nil && login.EmailVerified && account.VerifiedAt == nil {
+		if err = s.deleteUnverifiedAccount(ctx, *account); err != nil {
+			return nil, err
+		}
+		err = storage.ErrNotFound
+	}
+	switch {
+	case err == nil:
+		if !login.EmailVerified {
+			return nil, ErrAccountExists
+		}
+	case errors.Is(err, storage.ErrNotFound):
+		if account, err = s.createOIDCAccount(ctx, email, login, userID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+	identity = &models.OIDCIdentity{Issuer: login.Issuer, Subject: login.Subject, UserID: account.ID,
+		CreatedAt: time.Now().UTC()}
+	if err = s.repo.CreateOIDCIdentity(ctx, *identity); err != nil {
+		return nil, err
+	}
+	if _, err = storage.FSWrapper.WriteOIDCIdentity(*identity); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditOIDCIdentityLink, oidcIdentityTarget(*identity), account.ID)
+	return account, nil
+}
+
+// deleteUnverifiedAccount frees the email of the account nobody confirmed for the owner verified by the identity
+// provider: anyone can register the email of someone else first, which must not block the owner.
+// The account is turned back into the anonymous user keeping its short URLs, none of them is given to the owner.
+// Writes the deletion to the file (cold-storage) afterward.
+func (s *ShortURLService) deleteUnverifiedAccount(ctx context.Context, account models.Account) error {
+	err := s.repo.DeleteUnverifiedAccount(ctx, account.ID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return ErrAccountExists
+	}
+	if err != nil {
+		return err
+	}
+	if _, err = storage.FSWrapper.DeleteAccount(account); err != nil {
+		return err
+	}
+	s.audit(ctx, AuditAccountUnregister, account.ID, account.ID)
+	return nil
+}
+
+// createOIDCAccount creates the account of the user signing in with the identity provider for the first time.
+func (s *ShortURLService) createOIDCAccount(
+	ctx context.Context, email string, login models.OIDCLogin, userID string) (*models.Account, error) {
+	now := time.Now().UTC()
+	account := models.Account{ID: uuid.New().String(), Email: email, RegisteredAt: now}
+	if login.EmailVerified {
+		account.VerifiedAt = &now
+	}
+	if login.ClaimLinks && userID != "" {
+		_, err := s.repo.ReadAccount(ctx, userID)
+		switch {
+		case err == nil:
+			return nil, fmt.Errorf("%w: the current user is registered already", ErrInvalidAccount)
+		case !errors.Is(err, storage.ErrNotFound):
+			return nil, err
+		}
+		account.ID = userID
+	}
+	if err := s.repo.CreateAccount(ctx, account); err != nil {
+		if errors.Is(err, storage.ErrAlreadyExists) {
+			return nil, ErrAccountExists
+		}
+		return nil, err
+	}
+	if _, err := storage.FSWrapper.WriteAccount(account); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditAccountRegister, account.ID, account.ID)
+	if account.ID == userID {
+		s.audit(ctx, AuditAccountClaimLinks, account.ID, account.ID)
+	}
+	return &account, nil
+}
+
+// FillOIDCIdentity saves the identity from the single row of file (cold-storage) to the storage (warm-storage).
+func (s *ShortURLService) FillOIDCIdentity(ctx context.Context, identity models.OIDCIdentity) error {
+	err := s.repo.CreateOIDCIdentity(ctx, identity)
+	if errors.Is(err, storage.ErrAlreadyExists) {
+		return nil
+	}
+	return err
+}
+
+// FillAccount saves the account from the single row of file (cold-storage) to the storage (warm-storage).
+func (s *ShortURLService) FillAccount(ctx context.Context, account models.Account, deleted bool) error {
+	if deleted {
+		err := s.repo.DeleteUnverifiedAccount(ctx, account.ID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	err := s.repo.CreateAccount(ctx, account)
+	if errors.Is(err, storage.ErrAlreadyExists) {
+		return nil
+	}
+	return err
+}
+
+// normalizeEmail trims and lowercases the email, which must be the bare address without the display name.
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return "", fmt.Errorf("%w: email is required", ErrInvalidAccount)
+	}
+	if len(email) > maxEmailLength {
+		return "", fmt.Errorf("%w: email is longer than %d characters", ErrInvalidAccount, maxEmailLength)
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", fmt.Errorf("%w: invalid email %q", ErrInvalidAccount, email)
+	}
+	return email, nil
+}
+
+// checkAccountPassword checks the length of the account password, bcrypt ignores the bytes beyond maxPasswordLength.
+func checkAccountPassword(password string) error {
+	if utf8.RuneCountInString(password) < minAccountPasswordLength {
+		return fmt.Errorf("%w: password is shorter than %d characters", ErrInvalidAccount, minAccountPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("%w: password is longer than %d bytes", ErrInvalidAccount, maxPasswordLength)
+	}
+	return nil
+}
+
+// APIKeyPrefix starts every personal API key, so the keys are told apart from the other bearer tokens.
+const APIKeyPrefix = "usk_"
+
+// API keys are the prefix followed by the random bytes encoded as base64url, the visible prefix
+// includes a few random characters to tell the keys apart.
+const (
+	apiKeyBytes         = 24
+	apiKeyVisibleLength = len(APIKeyPrefix) + 8
+	// The last-used time is saved once in a while, not on every request.
+	apiKeyTouchInterval = time.Minute
+)
+
+// CreateAPIKey creates the API key owned by the current user. Only the SHA-256 hash of the key is stored:
+// the key is random enough to make the salted slow hashing pointless, and the hash is looked up on every request.
+// Writes the key to the file (cold-storage) afterward.
+func (s *ShortURLService) CreateAPIKey(
+	ctx context.Context, userID string, request models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAPIKey)
+	}
+	if utf8.RuneCountInString(name) > maxAPIKeyNameLength {
+		return nil, fmt.Errorf("%w: name is longer than %d characters", ErrInvalidAPIKey, maxAPIKeyNameLength)
+	}
+	now := time.Now().UTC()
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKey)
+	}
+	existing, err := s.repo.ReadAPIKeysByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxAPIKeysCount {
+		return nil, fmt.Errorf("%w: no more than %d api keys are allowed", ErrInvalidAPIKey, maxAPIKeysCount)
+	}
+	randomBytes := make([]byte, apiKeyBytes)
+	if _, err = cryptorand.Read(randomBytes); err != nil {
+		return nil, err
+	}
+	plainKey := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(randomBytes)
+	key := models.APIKey{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plainKey[:apiKeyVisibleLength],
+		KeyHash:   hashAPIKey(plainKey),
+		CreatedAt: now,
+	}
+	if request.ExpiresAt != nil {
+		expiresAt := request.ExpiresAt.UTC()
+		key.ExpiresAt = &expiresAt
+	}
+	if err = s.repo.CreateAPIKey(ctx, key); err != nil {
+		return nil, err
+	}
+	if _, err = storage.FSWrapper.WriteAPIKey(key); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditAPIKeyCreate, key.ID, userID)
+	return &models.CreatedAPIKey{Key: plainKey, APIKey: key}, nil
+}
+
+// ReadAPIKeysByUserID reads all the API keys created by the current user.
+func (s *ShortURLService) ReadAPIKeysByUserID(ctx context.Context, userID string) ([]models.APIKey, error) {
+	return s.repo.ReadAPIKeysByUserID(ctx, userID)
+}
+
+// DeleteAPIKey revokes the API key owned by the current user, the key is rejected right away.
+// Writes the revocation to the file (cold-storage) afterward.
+func (s *ShortURLService) DeleteAPIKey(ctx context.Context, id string, userID string) error {
+	key, err := s.repo.ReadAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+	if key.UserID != userID {
+		return ErrAPIKeyNotFound
+	}
+	if err = s.repo.DeleteAPIKey(ctx, key.ID); err != nil {
+		return err
+	}
+	if _, err = storage.FSWrapper.DeleteAPIKey(*key); err != nil {
+		return err
+	}
+	s.audit(ctx, AuditAPIKeyDelete, key.ID, userID)
+	return nil
+}
+
+// ResolveAPIKey returns the ID of the user the API key belongs to, saving the moment the key is used at.
+// Returns ErrAPIKeyRejected if the key is unknown, revoked or expired.
+func (s *ShortURLService) ResolveAPIKey(ctx context.Context, plainKey string) (string, error) {
+	if !strings.HasPrefix(plainKey, APIKeyPrefix) {
+		return "", ErrAPIKeyRejected
+	}
+	key, err := s.repo.ReadAPIKeyByHash(ctx, hashAPIKey(plainKey))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", ErrAPIKeyRejected
+		}
+		return "", err
+	}
+	now := time.Now().UTC()
+	if key.ExpiresAt != nil && !now.Before(*key.ExpiresAt) {
+		return "", ErrAPIKeyRejected
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err = s.repo.TouchAPIKey(ctx, key.ID, now); err != nil {
+			logger.Log.Warnf("Couldn't save the last use of api key %s: %s", key.ID, err)
+		}
+	}
+	return key.UserID, nil
+}
+
+// FillAPIKey saves the API key from the single row of file (cold-storage) to the storage (warm-storage).
+func (s *ShortURLService) FillAPIKey(ctx context.Context, key models.APIKey, deleted bool) error {
+	if !deleted {
+		return s.repo.CreateAPIKey(ctx, key)
+	}
+	err := s.repo.DeleteAPIKey(ctx, key.ID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// RevokeToken adds the token to the denylist, so it's rejected even before it expires. The token is kept
+// in the denylist until it can't be used nor reissued anyway, see revokedTokenExpiry.
+// Writes the revocation to the file (cold-storage) afterward.
+func (s *ShortURLService) RevokeToken(ctx context.Context, tokenID string, userID string) error {
+	expiresAt := revokedTokenExpiry(time.Now().UTC())
+	if err := s.repo.RevokeToken(ctx, tokenID, userID, expiresAt); err != nil {
+		return err
+	}
+	s.audit(ctx, AuditTokenRevoke, tokenID, userID)
+	_, err := storage.FSWrapper.WriteTokenRevocation(
+		models.TokenRevocation{ExpiresAt: expiresAt, TokenID: tokenID, UserID: userID})
+	return err
+}
+
+// revokedTokenExpiry returns the moment the token revoked at the moment is useless: the token valid at the moment
+// expires within the lifetime of the tokens, and is reissued within the grace period after it only.
+func revokedTokenExpiry(revokedAt time.Time) time.Time {
+	return revokedAt.Add(time.Hour * time.Duration(config.Settings.JWTExpireHours+config.Settings.JWTReissueGraceHours))
+}
+
+// RevokeAllTokens revokes all the tokens of the user issued before the current second. The issue time of the token
+// is kept in seconds, so the token issued later in the same second, e.g. on the next login, stays valid.
+// Writes the revocation to the file (cold-storage) afterward.
+func (s *ShortURLService) RevokeAllTokens(ctx context.Context, userID string) error {
+	before := time.Now().UTC().Truncate(time.Second)
+	if err := s.repo.RevokeUserTokens(ctx, userID, before); err != nil {
+		return err
+	}
+	s.audit(ctx, AuditTokenRevokeAll, userID, userID)
+	_, err := storage.FSWrapper.WriteTokenRevocation(models.TokenRevocation{RevokedBefore: before, UserID: userID})
+	return err
+}
+
+// IsTokenRevoked reports whether the token is in the denylist or is issued before the tokens of the user
+// are revoked.
+func (s *ShortURLService) IsTokenRevoked(
+	ctx context.Context, tokenID string, userID string, issuedAt time.Time) (bool, error) {
+	return s.repo.IsTokenRevoked(ctx, tokenID, userID, issuedAt)
+}
+
+// FillTokenRevocation saves the revocation from the single row of file (cold-storage) to the storage (warm-storage).
+// The tokens expired already are skipped, the rows written before the expiry was kept are given the longest one.
+func (s *ShortURLService) FillTokenRevocation(ctx context.Context, revocation models.TokenRevocation) error {
+	if revocation.TokenID != "" {
+		if revocation.ExpiresAt.IsZero() {
+			revocation.ExpiresAt = revokedTokenExpiry(time.Now().UTC())
+		}
+		if revocation.ExpiresAt.Before(time.Now()) {
+			return nil
+		}
+		return s.repo.RevokeToken(ctx, revocation.TokenID, revocation.UserID, revocation.ExpiresAt)
+	}
+	return s.repo.RevokeUserTokens(ctx, revocation.UserID, revocation.RevokedBefore)
+}
+
+func hashAPIKey(plainKey string) string {
+	hash := sha256.Sum256([]byte(plainKey))
+	return hex.EncodeToString(hash[:])
+}
+
+// scheduleMetadataFetch passes the short URL to the metadata fetching workers. The job is dropped if the queue is full
+// or the workers are disabled: the metadata is optional and must never slow down the creation of the short URL.
+func (s *ShortURLService) scheduleMetadataFetch(shortURL string, originalURL string) {
+	if s.metadataJobs == nil {
+		return
+	}
+	select {
+	case s.metadataJobs <- models.MetadataJob{ShortURL: shortURL, OriginalURL: originalURL}:
+	default:
+		logger.Log.Warnf("Metadata queue is full, skipping %s", shortURL)
+	}
+}
+
+// FetchMetadata fetches the metadata of the destination pages for the newly created short URLs.
+// Several instances are run as the pool of workers.
+func (s *ShortURLService) FetchMetadata() {
+	for {
+		select {
+		case job := <-s.metadataJobs:
+			s.fetchMetadata(job)
+		case <-s.doneChan:
+			return
+		}
+	}
+}
+
+// fetchMetadata fetches and stores the metadata of a single destination page.
+// Writes the actual state of the short URL to the file (cold-storage) afterward.
+func (s *ShortURLService) fetchMetadata(job models.MetadataJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.metadataTimeout)
+	defer cancel()
+	pageMetadata, err := s.fetcher.Fetch(ctx, job.OriginalURL)
+	if err != nil {
+		logger.Log.Infof("Couldn't fetch page metadata for %s: %s", job.ShortURL, err)
+		return
+	}
+	if err = s.repo.SetMetadata(ctx, job.ShortURL, *pageMetadata); err != nil {
+		logger.Log.Warnf("Couldn't store page metadata for %s: %s", job.ShortURL, err)
+		return
+	}
+	shortURL, err := s.repo.ReadShortURL(ctx, job.ShortURL)
+	if err != nil {
+		logger.Log.Warnf("Couldn't read short url %s: %s", job.ShortURL, err)
+		return
+	}
+	if _, err = storage.FSWrapper.Update(*shortURL); err != nil {
+		logger.Log.Warnf("Couldn't write short url %s to file: %s", job.ShortURL, err)
+	}
+}
+
+// FlushDeletions marks some scheduled deletions as deleted in the storage.
+func (s *ShortURLService) FlushDeletions() {
+	ticker := time.NewTicker(time.Duration(config.Settings.DeletionBufferFlushIntervalSeconds) * time.Second)
+
+	var messages []models.ShortURLChannelMessage
+
+	for {
+		select {
+		case msg := <-s.deleteMsgChanOut:
+			messages = append(messages, msg)
+		case <-ticker.C:
+			if len(messages) == 0 {
+				continue
+			}
+			err := s.flushDeletions(messages)
+			if err != nil {
+				logger.Log.Warn("cannot delete URLs", zap.Error(err))
+				continue
+			}
+			messages = nil
+		}
+	}
+}
+
+// flushDeletions marks the scheduled short URLs as deleted in the storage and records the deletions
+// in the audit log as made by the actors who scheduled them. Nothing is recorded if the storage fails.
+func (s *ShortURLService) flushDeletions(messages []models.ShortURLChannelMessage) error {
+	shortURLsToDelete := make([]string, len(messages))
+	for i, msg := range messages {
+		shortURLsToDelete[i] = msg.ShortURL
+	}
+	if err := s.repo.SetURLsInactive(context.TODO(), shortURLsToDelete); err != nil {
+		return err
+	}
+	events := make([]models.AuditEvent, len(messages))
+	for i, msg := range messages {
+		events[i] = newAuditEvent(WithActor(context.Background(), msg.Actor), AuditURLDelete, msg.ShortURL, msg.UserID)
+	}
+	if err := s.appendAuditEvents(context.Background(), events); err != nil {
+		logger.Log.Error("cannot write audit events", zap.String("action", AuditURLDelete), zap.Error(err))
+	}
+	return nil
+}
+
+// ScheduleDeletionOfBatch Schedules the batch of short URLs for the deletion. Uses FanOut + FanIn.
+func (s *ShortURLService) ScheduleDeletionOfBatch(shortURLs []models.ShortURLChannelMessage) {
+	s.deletionGenerator(shortURLs)
+	channels := s.deletionFanOut()
+	s.deletionFanIn(channels...)
+}
+
+func (s *ShortURLService) deletionGenerator(input []models.ShortURLChannelMessage) {
+	go func() {
+		for _, item := range input {
+			select {
+			case <-s.doneChan:
+				return
+			case s.deleteMsgChanIn <- item:
+			}
+		}
+	}()
+}
+
+func (s *ShortURLService) deletionFanOut() []chan models.ShortURLChannelMessage {
+	numWorkers := 10
+	channels := make([]chan models.ShortURLChannelMessage, numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		channels[i] = s.validateUser()
+	}
+	return channels
+}
+
+// validateUser passes on the scheduled short URLs the user is allowed to delete: the personal short URLs of the user
+// and the short URLs of the organizations the user is at least an editor of.
+func (s *ShortURLService) validateUser() chan models.ShortURLChannelMessage {
+	validateRes := make(chan models.ShortURLChannelMessage)
+	go func() {
+		defer close(validateRes)
+		for data := range s.deleteMsgChanIn {
+			shortURL, err := s.repo.ReadShortURL(context.TODO(), data.ShortURL)
+			if err != nil && !errors.Is(err, storage.ErrNotFound) {
+				logger.Log.Error("cannot read short URL", zap.String("shortURL", data.ShortURL), zap.Error(err))
+			}
+			if err != nil || shortURL.Deleted {
+				logger.Log.Infof("Skipping URL %s - not found in storage", data.ShortURL)
+				continue
+			}
+			if err = s.authorizeShortURL(context.TODO(), shortURL, data.UserID, models.RoleEditor); err != nil {
+				logger.Log.Infof("Skipping URL %s - user is not allowed to delete it: %s", data.ShortURL, err)
+				continue
+			}
+			select {
+			case <-s.doneChan:
+				return
+			case validateRes <- data:
+			}
+		}
+	}()
+	return validateRes
+}
+
+func (s *ShortURLService) deletionFanIn(channels ...chan models.ShortURLChannelMessage) {
+	for _, ch := range channels {
+		chClosure := ch
+
+		go func() {
+			for data := range chClosure {
+				select {
+				case <-s.doneChan:
+					return
+				case s.deleteMsgChanOut <- data:
+				}
+			}
+		}()
+
+	}
+}
+
+// RecordClick passes the click on the split variant of the short URL to the click counting worker. The click is dropped
+// if the queue is full: counting must never slow down the redirect.
+func (s *ShortURLService) RecordClick(shortURL string, variant string) {
+	select {
+	case s.clicks <- models.VariantClick{ShortURL: shortURL, Variant: variant}:
+	default:
+		logger.Log.Warnf("Clicks queue is full, skipping the click on %s", shortURL)
+	}
+}
+
+// FlushClicks sums up the clicks on the split variants and adds them to the counters in the storage periodically,
+// so the storage is written once per variant and not per click. The rest of the clicks is flushed on shutdown.
+func (s *ShortURLService) FlushClicks() {
+	ticker := time.NewTicker(time.Duration(config.Settings.ClicksFlushIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	pending := make(map[models.VariantClick]int64)
+	for {
+		select {
+		case click := <-s.clicks:
+			pending[click]++
+		case <-ticker.C:
+			s.flushClicks(pending)
+			clear(pending)
+		case <-s.doneChan:
+			s.flushClicks(pending)
+			return
+		}
+	}
+}
+
+// flushClicks adds the summed up clicks to the counters in the storage and writes them to the file (cold-storage).
+// The clicks on the short URLs deleted from the storage meanwhile are dropped.
+func (s *ShortURLService) flushClicks(pending map[models.VariantClick]int64) {
+	for click, clicks := range pending {
+		if err := s.repo.AddVariantClicks(context.TODO(), click.ShortURL, click.Variant, clicks); err != nil {
+			logger.Log.Warnf("Couldn't count clicks on %s: %s", click.ShortURL, err)
+			continue
+		}
+		if _, err := storage.FSWrapper.WriteVariantClicks(click.ShortURL, click.Variant, clicks); err != nil {
+			logger.Log.Warnf("Couldn't write clicks on %s to file: %s", click.ShortURL, err)
+		}
+	}
+}
+
+// FillVariantClicks saves the clicks on the split variant from the single row of file (cold-storage)
+// to the storage (warm-storage).
+func (s *ShortURLService) FillVariantClicks(ctx context.Context, shortURL string, variant string, clicks int64) error {
+	err := s.repo.AddVariantClicks(ctx, shortURL, variant, clicks)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// GetShortURLStats returns the clicks on the split variants of the personal short URL of the current user
+// or the short URL of the organization the user is a member of. The current variants go first in their order, the removed ones that got the clicks follow sorted by name.
+func (s *ShortURLService) GetShortURLStats(ctx context.Context, id string, userID string) (*models.ShortURLStats, error) {
+	shortURL, err := s.repo.ReadShortURL(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrShortURLNotFound
+		}
+		return nil, err
+	}
+	if shortURL.Deleted {
+		return nil, ErrShortURLNotFound
+	}
+	if err = s.authorizeShortURL(ctx, shortURL, userID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	clicks, err := s.repo.ReadVariantClicks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	stats := &models.ShortURLStats{ShortURL: domains.ShortURL(shortURL.ShortURL), Variants: []models.VariantStats{}}
+	for _, variant := range shortURL.Variants {
+		stats.Variants = append(stats.Variants, models.VariantStats{SplitVariant: variant, Clicks: clicks[variant.Name]})
+		delete(clicks, variant.Name)
+	}
+	removed := make([]string, 0, len(clicks))
+	for name := range clicks {
+		removed = append(removed, name)
+	}
+	slices.Sort(removed)
+	for _, name := range removed {
+		stats.Variants = append(stats.Variants,
+			models.VariantStats{SplitVariant: models.SplitVariant{Name: name}, Clicks: clicks[name]})
+	}
+	return stats, nil
+}
+
+// GetQRCode renders the QR code image of the full short URL, as it is hosted on the server.
+// The QR codes of the short URLs outside their activation window are rendered as well, so they can be printed in advance,
+// but not the ones of the short URLs disabled by the admin and created by the banned users, as Resolve does.
+func (s *ShortURLService) GetQRCode(ctx context.Context, id string, options models.QROptions) (*models.QRCode, error) {
+	options, err := normalizeQROptions(options)
+	if err != nil {
+		return nil, err
+	}
+	shortURL, err := s.repo.ReadShortURL(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrShortURLNotFound
+		}
+		return nil, err
+	}
+	if shortURL.Deleted {
+		return nil, ErrShortURLNotFound
+	}
+	if shortURL.Moderation != nil {
+		return nil, ErrShortURLDisabled
+	}
+	if err = s.checkBanned(ctx, shortURL.UserID); err != nil {
+		if errors.Is(err, ErrUserBanned) {
+			return nil, ErrShortURLDisabled
+		}
+		return nil, err
+	}
+	image, err := qr.Render(domains.ShortURL(shortURL.ShortURL), options.Format, options.Level, options.Size)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(image)
+	return &models.QRCode{
+		ContentType: qr.ContentType(options.Format),
+		ETag:        `"` + hex.EncodeToString(hash[:16]) + `"`,
+		Image:       image,
+	}, nil
+}
+
+// normalizeQROptions checks the options of the QR code image, the omitted ones get the default values.
+// The format is case-insensitive, so is the error correction level.
+func normalizeQROptions(options models.QROptions) (models.QROptions, error) {
+	options.Format = strings.ToLower(strings.TrimSpace(options.Format))
+	if options.Format == "" {
+		options.Format = qr.FormatPNG
+	}
+	if !qr.IsFormat(options.Format) {
+		return options, fmt.Errorf("%w: unsupported format %q", ErrInvalidQROptions, options.Format)
+	}
+	options.Level = strings.ToUpper(strings.TrimSpace(options.Level))
+	if options.Level == "" {
+		options.Level = qr.LevelMedium
+	}
+	if !qr.IsLevel(options.Level) {
+		return options, fmt.Errorf("%w: unsupported error correction level %q", ErrInvalidQROptions, options.Level)
+	}
+	if options.Size == 0 {
+		options.Size = defaultQRSize
+	}
+	if options.Size < minQRSize || options.Size > maxQRSize {
+		return options, fmt.Errorf("%w: size must be between %d and %d", ErrInvalidQROptions, minQRSize, maxQRSize)
+	}
+	return options, nil
+}
+
+// GetStats Returns the number of users and URLs registered in the service.
+func (s *ShortURLService) GetStats(ctx context.Context) (*models.ServiceStats, error) {
+	stats, err := s.repo.GetStats(ctx)
 	if err != nil {
 		return &models.ServiceStats{}, err
 	}
 	return stats, nil
 }
+
+// AdminReadShortURL reads the short URL of any user by its key along with its moderation state and the ban
+// of its author.
+func (s *ShortURLService) AdminReadShortURL(ctx context.Context, id string) (*models.AdminShortURL, error) {
+	shortURL, err := s.repo.ReadShortURL(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrShortURLNotFound
+		}
+		return nil, err
+	}
+	banned, err := s.isBanned(ctx, shortURL.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AdminShortURL{
+		Moderation:  shortURL.Moderation,
+		ShortURL:    domains.ShortURL(shortURL.ShortURL),
+		OriginalURL: shortURL.OriginalURL,
+		UserID:      shortURL.UserID,
+		OrgID:       shortURL.OrgID,
+		Title:       shortURL.Title,
+		Deleted:     shortURL.Deleted,
+		OwnerBanned: banned,
+	}, nil
+}
+
+// SearchShortURLs reads the short URLs of all the users matching the search: the destination, the host
+// of the destination along with its subdomains and the author. At least one of them is required,
+// the amount of the short URLs returned is limited.
+func (s *ShortURLService) SearchShortURLs(ctx context.Context, search models.ShortURLSearch) ([]models.AdminShortURL, error) {
+	search, err := normalizeSearch(search)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.repo.SearchShortURLs(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].ShortURL = domains.ShortURL(result[i].ShortURL)
+	}
+	return result, nil
+}
+
+// DisableShortURL blocks the redirects of the short URL for the reason, which is required. Disabling the short URL
+// once again replaces the reason. Writes the moderation to the file (cold-storage) afterward.
+func (s *ShortURLService) DisableShortURL(ctx context.Context, id string, reason string) (*models.AdminShortURL, error) {
+	reason, err := normalizeReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	moderation := models.Moderation{DisabledAt: time.Now().UTC(), Reason: reason}
+	if err = s.repo.SetModeration(ctx, id, &moderation); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrShortURLNotFound
+		}
+		return nil, err
+	}
+	if _, err = storage.FSWrapper.WriteModeration(id, moderation); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditURLDisable, id, "")
+	return s.AdminReadShortURL(ctx, id)
+}
+
+// EnableShortURL lets the short URL disabled by the admin redirect again, enabling the short URL that isn't disabled
+// changes nothing. Writes the moderation to the file (cold-storage) afterward.
+func (s *ShortURLService) EnableShortURL(ctx context.Context, id string) (*models.AdminShortURL, error) {
+	if err := s.repo.SetModeration(ctx, id, nil); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrShortURLNotFound
+		}
+		return nil, err
+	}
+	if _, err := storage.FSWrapper.DeleteModeration(id); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditURLEnable, id, "")
+	return s.AdminReadShortURL(ctx, id)
+}
+
+// BanUser bans the user for the reason, which is required: the user can't create the short URLs and the redirects
+// of the user's short URLs are blocked. Banning the user once again replaces the ban.
+// Writes the ban to the file (cold-storage) afterward.
+func (s *ShortURLService) BanUser(ctx context.Context, userID string, reason string) (*models.UserBan, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user ID is required", ErrInvalidModeration)
+	}
+	reason, err := normalizeReason(reason)
+	if err != nil {
+		return nil, err
+	}
+	ban := models.UserBan{BannedAt: time.Now().UTC(), UserID: userID, Reason: reason}
+	if err = s.repo.BanUser(ctx, ban); err != nil {
+		return nil, err
+	}
+	if _, err = storage.FSWrapper.WriteUserBan(ban); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, AuditUserBan, userID, "")
+	return &ban, nil
+}
+
+// UnbanUser lifts the ban of the user, the short URLs of the user redirect again.
+// Writes the lifted ban to the file (cold-storage) afterward.
+func (s *ShortURLService) UnbanUser(ctx context.Context, userID string) error {
+	if err := s.repo.UnbanUser(ctx, userID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrUserNotBanned
+		}
+		return err
+	}
+	if _, err := storage.FSWrapper.DeleteUserBan(userID); err != nil {
+		return err
+	}
+	s.audit(ctx, AuditUserUnban, userID, "")
+	return nil
+}
+
+// FillModeration saves the moderation of the short URL from the single row of file (cold-storage)
+// to the storage (warm-storage).
+func (s *ShortURLService) FillModeration(ctx context.Context, id string, moderation models.Moderation, deleted bool) error {
+	var err error
+	if deleted {
+		err = s.repo.SetModeration(ctx, id, nil)
+	} else {
+		err = s.repo.SetModeration(ctx, id, &moderation)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// FillUserBan saves the ban of the user from the single row of file (cold-storage) to the storage (warm-storage).
+func (s *ShortURLService) FillUserBan(ctx context.Context, ban models.UserBan, deleted bool) error {
+	if !deleted {
+		return s.repo.BanUser(ctx, ban)
+	}
+	err := s.repo.UnbanUser(ctx, ban.UserID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// checkBanned returns ErrUserBanned if the user is banned.
+func (s *ShortURLService) checkBanned(ctx context.Context, userID string) error {
+	banned, err := s.isBanned(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return ErrUserBanned
+	}
+	return nil
+}
+
+// isBanned reports whether the user is banned, the anonymous author of the short URL never is.
+func (s *ShortURLService) isBanned(ctx context.Context, userID string) (bool, error) {
+	if userID == "" {
+		return false, nil
+	}
+	_, err := s.repo.ReadUserBan(ctx, userID)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, storage.ErrNotFound):
+		return false, nil
+	}
+	return false, err
+}
+
+// normalizeReason trims the reason of the moderation and checks its limit, the reason is required.
+func normalizeReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", fmt.Errorf("%w: reason is required", ErrInvalidModeration)
+	}
+	if utf8.RuneCountInString(reason) > maxModerationReason {
+		return "", fmt.Errorf("%w: reason is longer than %d characters", ErrInvalidModeration, maxModerationReason)
+	}
+	return reason, nil
+}
+
+// normalizeSearch trims the filters of the search and lowercases the domain, at least one filter is required.
+// The limit defaults to defaultSearchLimit and can't exceed maxSearchLimit.
+func normalizeSearch(search models.ShortURLSearch) (models.ShortURLSearch, error) {
+	search.OriginalURL = strings.TrimSpace(search.OriginalURL)
+	search.UserID = strings.TrimSpace(search.UserID)
+	search.Domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(search.Domain)), ".")
+	if search.OriginalURL == "" && search.Domain == "" && search.UserID == "" {
+		return search, fmt.Errorf("%w: original URL, domain or user ID is required", ErrInvalidModeration)
+	}
+	if search.Domain != "" && !isDomainName(search.Domain) {
+		return search, fmt.Errorf("%w: invalid domain %q", ErrInvalidModeration, search.Domain)
+	}
+	switch {
+	case search.Limit < 0:
+		return search, fmt.Errorf("%w: limit can't be negative", ErrInvalidModeration)
+	case search.Limit == 0:
+		search.Limit = defaultSearchLimit
+	case search.Limit > maxSearchLimit:
+		search.Limit = maxSearchLimit
+	}
+	return search, nil
+}
+
+// isDomainName reports whether the lowercased name consists of the non-empty labels of letters, digits and hyphens.
+func isDomainName(name string) bool {
+	for _, label := range strings.Split(name, ".") {
+		if label == "" {
+			return false
+		}
+		for _, char := range label {
+			if (char < 'a' || char > 'z') && (char < '0' || char > '9') && char != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type actorKey struct{}
+
+// orgMemberTarget returns the target of the audit event of the organization member: the organization and the user.
+func orgMemberTarget(member models.OrgMember) string {
+	return member.OrgID + "/" + member.UserID
+}
+
+// oidcIdentityTarget returns the target of the audit event of the identity: the issuer and the subject,
+// separated by # since the issuer is the URL.
+func oidcIdentityTarget(identity models.OIDCIdentity) string {
+	return identity.Issuer + "#" + identity.Subject
+}
+
+// WithActor returns the copy of the context carrying the caller recorded in the audit log as the actor
+// of the mutations made within the context.
+func WithActor(ctx context.Context, actor models.Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the caller saved to the context by WithActor, the empty actor if there is none.
+func ActorFromContext(ctx context.Context) models.Actor {
+	actor, _ := ctx.Value(actorKey{}).(models.Actor)
+	return actor
+}
+
+// audit appends the event of the mutation of the target to the audit log. The event is recorded as made by the user
+// or by the actor from the context if the user is empty, along with the address and the transport of the actor.
+// The mutation is done already, so the failure to record it is logged only.
+func (s *ShortURLService) audit(ctx context.Context, action string, target string, userID string) {
+	s.auditAll(ctx, action, []string{target}, userID)
+}
+
+// auditAll appends the events of the same mutation of each of the targets to the audit log at once,
+// see audit.
+func (s *ShortURLService) auditAll(ctx context.Context, action string, targets []string, userID string) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	events := make([]models.AuditEvent, len(targets))
+	for i, target := range targets {
+		events[i] = newAuditEvent(ctx, action, target, userID)
+	}
+	if err := s.appendAuditEvents(context.WithoutCancel(ctx), events); err != nil {
+		logger.Log.Error("cannot write audit events", zap.String("action", action), zap.Strings("targets", targets),
+			zap.Error(err))
+	}
+}
+
+// newAuditEvent returns the event of the mutation of the target made by the user or by the actor from the context.
+func newAuditEvent(ctx context.Context, action string, target string, userID string) models.AuditEvent {
+	actor := ActorFromContext(ctx)
+	if userID == "" {
+		userID = actor.UserID
+	}
+	return models.AuditEvent{
+		CreatedAt:    time.Now().UTC().Truncate(time.Microsecond),
+		Action:       action,
+		Target:       target,
+		UserID:       userID,
+		IP:           actor.IP,
+		ForwardedFor: actor.ForwardedFor,
+		Transport:    actor.Transport,
+	}
+}
+
+// appendAuditEvents appends the events to the audit log, the storage chains them to the last one atomically,
+// and writes them to the file (cold-storage) afterward.
+func (s *ShortURLService) appendAuditEvents(ctx context.Context, events []models.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	appended, err := s.repo.AppendAuditEvents(ctx, events, auditHash)
+	if err != nil {
+		return err
+	}
+	for _, event := range appended {
+		if _, err = storage.FSWrapper.WriteAuditEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditHash returns the SHA-256 hash of the event covering its position, its attributes and the hash
+// of the previous event. The time is taken in microseconds, which the database keeps.
+func auditHash(event models.AuditEvent) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d\n%d\n%q\n%q\n%q\n%q\n%q\n%q\n%s", event.Seq, event.CreatedAt.UnixMicro(), event.Action,
+		event.Target, event.UserID, event.IP, event.ForwardedFor, event.Transport, event.PrevHash)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ReadAuditEvents reads the events of the audit log matching the filter ordered by their position.
+// The amount of the events returned is limited the same way as the search of the short URLs.
+func (s *ShortURLService) ReadAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	switch {
+	case filter.Limit < 0:
+		return nil, fmt.Errorf("%w: limit can't be negative", ErrInvalidAuditFilter)
+	case filter.Limit == 0:
+		filter.Limit = defaultSearchLimit
+	case filter.Limit > maxSearchLimit:
+		filter.Limit = maxSearchLimit
+	}
+	if filter.AfterSeq < 0 {
+		return nil, fmt.Errorf("%w: position can't be negative", ErrInvalidAuditFilter)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%w: the end of the period should follow its start", ErrInvalidAuditFilter)
+	}
+	return s.repo.ReadAuditEvents(ctx, filter)
+}
+
+// VerifyAuditLog reads the whole audit log page by page and checks that each event follows the previous one
+// and its hash matches its attributes. Reports the position of the first event breaking the chain.
+func (s *ShortURLService) VerifyAuditLog(ctx context.Context) (*models.AuditVerification, error) {
+	verification := &models.AuditVerification{Valid: true}
+	var last models.AuditEvent
+	for {
+		events, err := s.repo.ReadAuditEvents(ctx, models.AuditFilter{AfterSeq: last.Seq, Limit: maxSearchLimit})
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			verification.Events++
+			if event.Seq != last.Seq+1 || event.PrevHash != last.Hash || event.Hash != auditHash(event) {
+				verification.Valid = false
+				verification.BrokenAt = last.Seq + 1
+				return verification, nil
+			}
+			last = event
+		}
+		if len(events) < maxSearchLimit {
+			return verification, nil
+		}
+	}
+}
+
+// FillAuditEvent saves the event of the audit log from the single row of file (cold-storage)
+// to the storage (warm-storage).
+func (s *ShortURLService) FillAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	err := s.repo.CreateAuditEvent(ctx, event)
+	if errors.Is(err, storage.ErrAlreadyExists) {
+		return nil
+	}
+	return err
+}
@@ -4,20 +4,42 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 
 	"github.com/clearthree/url-shortener/internal/app/config"
+	"github.com/clearthree/url-shortener/internal/app/metadata"
 	"github.com/clearthree/url-shortener/internal/app/mocks"
 	"github.com/clearthree/url-shortener/internal/app/models"
+	"github.com/clearthree/url-shortener/internal/app/qr"
 	"github.com/clearthree/url-shortener/internal/app/storage"
+	"github.com/clearthree/url-shortener/internal/app/utils"
 
 	"testing"
 )
 
+// TestMain keeps the file storage written by the tests in the temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "url-shortener")
+	if err != nil {
+		panic(err)
+	}
+	config.Settings.FileStoragePath = filepath.Join(dir, "storage.json")
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 type RepoMock struct {
 	localStorage                map[string]string
 	localIDsStorage             map[string][]string
@@ -25,7 +47,7 @@ type RepoMock struct {
 	localStorageDeactivatedURLs map[string]bool
 }
 
-func (rm RepoMock) Create(_ context.Context, id string, originalURL string, userID string) (string, error) {
+func (rm RepoMock) Create(_ context.Context, id string, originalURL string, userID string, _ models.ShortURLOptions) (string, error) {
 	if rm.localStorage == nil {
 		rm.localStorage = make(map[string]string)
 		rm.localIDsStorage = make(map[string][]string)
@@ -59,7 +81,7 @@ func (rm RepoMock) Ping(_ context.Context) error {
 func (rm RepoMock) BatchCreate(ctx context.Context, URLs map[string]models.ShortenBatchItemRequest, userID string) ([]models.ShortenBatchItemResponse, error) {
 	results := make([]models.ShortenBatchItemResponse, 0, len(URLs))
 	for shortURL, data := range URLs {
-		result, err := rm.Create(ctx, shortURL, data.OriginalURL, userID)
+		result, err := rm.Create(ctx, shortURL, data.OriginalURL, userID, data.ShortURLOptions)
 		if err != nil {
 			return nil, err
 		}
@@ -68,7 +90,7 @@ func (rm RepoMock) BatchCreate(ctx context.Context, URLs map[string]models.Short
 	return results, nil
 }
 
-func (rm RepoMock) ReadByUserID(_ context.Context, userID string) ([]models.ShortURLsByUserResponse, error) {
+func (rm RepoMock) ReadByUserID(_ context.Context, userID string, _ models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
 	currentShortURLs := rm.localIDsStorage[userID]
 	if len(currentShortURLs) == 0 {
 		return nil, nil
@@ -87,12 +109,32 @@ func (rm RepoMock) ReadByUserID(_ context.Context, userID string) ([]models.Shor
 	return result, nil
 }
 
-func (rm RepoMock) GetUserIDByShortURL(_ context.Context, shortURL string) (string, error) {
-	_, ok := rm.localStorageDeactivatedURLs[shortURL]
-	if ok {
-		return "", nil
+func (rm RepoMock) ReadShortURL(_ context.Context, id string) (*models.ShortURL, error) {
+	originalURL, ok := rm.localStorage[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	_, deleted := rm.localStorageDeactivatedURLs[id]
+	return &models.ShortURL{
+		ShortURL:    id,
+		OriginalURL: originalURL,
+		UserID:      rm.localStorageUsersByURLs[id],
+		Deleted:     deleted,
+	}, nil
+}
+
+func (rm RepoMock) Update(_ context.Context, id string, _ models.UpdateShortURLRequest) error {
+	if _, ok := rm.localStorage[id]; !ok {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (rm RepoMock) SetMetadata(_ context.Context, id string, _ models.PageMetadata) error {
+	if _, ok := rm.localStorage[id]; !ok {
+		return storage.ErrNotFound
 	}
-	return rm.localStorageUsersByURLs[shortURL], nil
+	return nil
 }
 
 func (rm RepoMock) SetURLsInactive(_ context.Context, shortURLs []string) error {
@@ -110,6 +152,195 @@ func (rm RepoMock) GetStats(_ context.Context) (*models.ServiceStats, error) {
 	return response, nil
 }
 
+func (rm RepoMock) CreateUTMTemplate(_ context.Context, _ models.UTMTemplate) error {
+	return nil
+}
+
+func (rm RepoMock) ReadUTMTemplate(_ context.Context, _ string) (*models.UTMTemplate, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (rm RepoMock) ReadUTMTemplatesByUserID(_ context.Context, _ string) ([]models.UTMTemplate, error) {
+	return nil, nil
+}
+
+func (rm RepoMock) UpdateUTMTemplate(_ context.Context, _ models.UTMTemplate) error {
+	return storage.ErrNotFound
+}
+
+func (rm RepoMock) DeleteUTMTemplate(_ context.Context, _ string) error {
+	return storage.ErrNotFound
+}
+
+func (rm RepoMock) CreateCampaign(_ context.Context, _ models.Campaign) error {
+	return nil
+}
+
+func (rm RepoMock) ReadCampaign(_ context.Context, _ string) (*models.Campaign, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (rm RepoMock) ReadCampaignsByUserID(_ context.Context, _ string) ([]models.Campaign, error) {
+	return nil, nil
+}
+
+func (rm RepoMock) UpdateCampaign(_ context.Context, _ models.Campaign) error {
+	return storage.ErrNotFound
+}
+
+func (rm RepoMock) DeleteCampaign(_ context.Context, _ string) error {
+	return storage.ErrNotFound
+}
+
+func (rm RepoMock) GetCampaignStats(_ context.Context, _ string) (*models.CampaignStats, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (rm RepoMock) CreateAccount(_ context.Context, _ models.Account) error {
+	return nil
+}
+
+func (rm RepoMock) ReadAccount(_ context.Context, _ string) (*models.Account, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (rm RepoMock) ReadAccountByEmail(_ context.Context, _ string) (*models.Account, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (rm RepoMock) DeleteUnverifiedAccount(_ context.Context, _ string) error {
+	return nil
+}
+
+func (rm RepoMock) CreateAPIKey(_ context.Context, _ models.APIKey) error {
+	return nil
+}
+
+func (rm RepoMock) ReadAPIKey(_ context.Context, _ string) (*models.APIKey, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (rm RepoMock) ReadAPIKeyByHash(_ context.Context, _ string) (*models.APIKey, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (rm RepoMock) ReadAPIKeysByUserID(_ context.Context, _ string) ([]models.APIKey, error) {
+	return nil, nil
+}
+
+func (rm RepoMock) TouchAPIKey(_ context.Context, _ string, _ time.Time) error {
+	return nil
+}
+
+func (rm RepoMock) DeleteAPIKey(_ context.Context, _ string) error {
+	return nil
+}
+
+func (rm RepoMock) RevokeToken(_ context.Context, _ string, _ string, _ time.Time) error {
+	return nil
+}
+
+func (rm RepoMock) RevokeUserTokens(_ context.Context, _ string, _ time.Time) error {
+	return nil
+}
+
+func (rm RepoMock) IsTokenRevoked(_ context.Context, _ string, _ string, _ time.Time) (bool, error) {
+	return false, nil
+}
+
+func (rm RepoMock) CreateOIDCIdentity(_ context.Context, _ models.OIDCIdentity) error {
+	return nil
+}
+
+func (rm RepoMock) ReadOIDCIdentity(_ context.Context, _ string, _ string) (*models.OIDCIdentity, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (rm RepoMock) ReadByOrgID(_ context.Context, _ string, _ models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
+	return nil, nil
+}
+
+func (rm RepoMock) CreateOrganization(_ context.Context, _ models.Organization, _ models.OrgMember) error {
+	return nil
+}
+
+func (rm RepoMock) ReadOrganization(_ context.Context, _ string) (*models.Organization, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (rm RepoMock) ReadOrganizationsByUserID(_ context.Context, _ string) ([]models.Organization, error) {
+	return nil, nil
+}
+
+func (rm RepoMock) ReadOrgMember(_ context.Context, _ string, _ string) (*models.OrgMember, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (rm RepoMock) ReadOrgMembers(_ context.Context, _ string) ([]models.OrgMember, error) {
+	return nil, nil
+}
+
+func (rm RepoMock) SaveOrgMember(_ context.Context, _ models.OrgMember) error {
+	return nil
+}
+
+func (rm RepoMock) DeleteOrgMember(_ context.Context, _ string, _ string) error {
+	return storage.ErrNotFound
+}
+
+func (rm RepoMock) SearchShortURLs(_ context.Context, _ models.ShortURLSearch) ([]models.AdminShortURL, error) {
+	return nil, nil
+}
+
+func (rm RepoMock) SetModeration(_ context.Context, _ string, _ *models.Moderation) error {
+	return storage.ErrNotFound
+}
+
+func (rm RepoMock) BanUser(_ context.Context, _ models.UserBan) error {
+	return nil
+}
+
+func (rm RepoMock) UnbanUser(_ context.Context, _ string) error {
+	return storage.ErrNotFound
+}
+
+func (rm RepoMock) ReadUserBan(_ context.Context, _ string) (*models.UserBan, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (rm RepoMock) CreateAuditEvent(_ context.Context, _ models.AuditEvent) error {
+	return nil
+}
+
+func (rm RepoMock) AppendAuditEvents(
+	_ context.Context, events []models.AuditEvent, _ func(models.AuditEvent) string) ([]models.AuditEvent, error) {
+	return events, nil
+}
+
+func (rm RepoMock) ReadAuditEvents(_ context.Context, _ models.AuditFilter) ([]models.AuditEvent, error) {
+	return nil, nil
+}
+
+// allowAudit lets the service append any events to the audit log kept by the repository mock.
+func allowAudit(repoMock *mocks.MockRepository) {
+	repoMock.EXPECT().AppendAuditEvents(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, events []models.AuditEvent, _ func(models.AuditEvent) string) ([]models.AuditEvent, error) {
+			return events, nil
+		}).AnyTimes()
+}
+
+func (rm RepoMock) AddVariantClicks(_ context.Context, _ string, _ string, _ int64) error {
+	return nil
+}
+
+func (rm RepoMock) ReadVariantClicks(_ context.Context, _ string) (map[string]int64, error) {
+	return nil, nil
+}
+
+func (rm RepoMock) UseClick(_ context.Context, _ string) (int64, error) {
+	return 0, nil
+}
+
 func TestNewService(t *testing.T) {
 	type args struct {
 		repo     storage.Repository
@@ -193,7 +424,7 @@ func TestShortURLService_Create(t *testing.T) {
 			s := &ShortURLService{
 				repo: tt.fields.repo,
 			}
-			got, err := s.Create(tt.args.ctx, tt.args.originalURL, tt.args.userID)
+			got, err := s.Create(tt.args.ctx, tt.args.originalURL, tt.args.userID, models.ShortURLOptions{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -242,6 +473,7 @@ func TestShortURLService_CreateWithError(t *testing.T) {
 			defer ctrl.Finish()
 
 			repoMock := mocks.NewMockRepository(ctrl)
+			repoMock.EXPECT().ReadUserBan(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound).AnyTimes()
 			s := &ShortURLService{
 				repo: repoMock,
 			}
@@ -249,9 +481,9 @@ func TestShortURLService_CreateWithError(t *testing.T) {
 				Read(tt.args.ctx, gomock.Any()).
 				Return("", false)
 			repoMock.EXPECT().
-				Create(tt.args.ctx, gomock.Any(), tt.args.originalURL, tt.args.userID).
+				Create(tt.args.ctx, gomock.Any(), tt.args.originalURL, tt.args.userID, models.ShortURLOptions{}).
 				Return(tt.mockReturns, tt.mockReturnsErr)
-			got, err := s.Create(tt.args.ctx, tt.args.originalURL, tt.args.userID)
+			got, err := s.Create(tt.args.ctx, tt.args.originalURL, tt.args.userID, models.ShortURLOptions{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -366,7 +598,7 @@ func TestShortURLService_FillRow(t *testing.T) {
 			s := &ShortURLService{
 				repo: tt.fields.repo,
 			}
-			err := s.FillRow(tt.args.ctx, tt.args.originalURL, tt.args.shortURL, tt.args.userID)
+			err := s.FillRow(tt.args.ctx, tt.args.originalURL, tt.args.shortURL, tt.args.userID, models.ShortURLOptions{}, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -446,6 +678,14 @@ func TestShortURLService_BatchCreate(t *testing.T) {
 			defer ctrl.Finish()
 
 			repoMock := mocks.NewMockRepository(ctrl)
+			var audited []models.AuditEvent
+			repoMock.EXPECT().AppendAuditEvents(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, events []models.AuditEvent, _ func(models.AuditEvent) string) (
+					[]models.AuditEvent, error) {
+					audited = events
+					return events, nil
+				})
+			repoMock.EXPECT().ReadUserBan(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound).AnyTimes()
 			s := &ShortURLService{
 				repo: repoMock,
 			}
@@ -464,6 +704,11 @@ func TestShortURLService_BatchCreate(t *testing.T) {
 				return
 			}
 			assert.Equalf(t, tt.want, got, "BatchCreate(%v, %v, %v)", tt.args.ctx, tt.args.requestData, tt.args.userID)
+			assert.Len(t, audited, len(tt.args.requestData), "the batch is recorded in the audit log at once")
+			for _, event := range audited {
+				assert.Equal(t, AuditURLCreate, event.Action)
+				assert.Equal(t, tt.args.userID, event.UserID)
+			}
 		})
 	}
 }
@@ -529,9 +774,9 @@ func TestShortURLService_ReadByUserID(t *testing.T) {
 				repo: repoMock,
 			}
 			repoMock.EXPECT().
-				ReadByUserID(tt.args.ctx, tt.args.userID).
+				ReadByUserID(tt.args.ctx, tt.args.userID, models.ShortURLFilter{}).
 				Return(tt.mockReturns, nil)
-			got, err := s.ReadByUserID(tt.args.ctx, tt.args.userID)
+			got, err := s.ReadByUserID(tt.args.ctx, tt.args.userID, models.ShortURLFilter{})
 			if !tt.wantErr(t, err, fmt.Sprintf("ReadByUserID(%v, %v)", tt.args.ctx, tt.args.userID)) {
 				return
 			}
@@ -555,7 +800,7 @@ func BenchmarkShortURLService(b *testing.B) {
 	for i := 0; i < testCaseLength; i++ {
 		URLs[i] = "http://yandex" + strconv.Itoa(i) + ".ru"
 	}
-	shortURL, err := service.Create(ctx, URLs[0], testUserID)
+	shortURL, err := service.Create(ctx, URLs[0], testUserID, models.ShortURLOptions{})
 	if err != nil {
 		panic(err)
 	}
@@ -564,7 +809,7 @@ func BenchmarkShortURLService(b *testing.B) {
 	b.ResetTimer()
 	b.Run("ReadByUserID", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			_, innerErr := service.ReadByUserID(ctx, testUserID)
+			_, innerErr := service.ReadByUserID(ctx, testUserID, models.ShortURLFilter{})
 			if innerErr != nil {
 				panic(innerErr)
 			}
@@ -572,7 +817,7 @@ func BenchmarkShortURLService(b *testing.B) {
 	})
 	b.Run("Create", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			_, err = service.Create(ctx, "http://ya.ru", testUserID)
+			_, err = service.Create(ctx, "http://ya.ru", testUserID, models.ShortURLOptions{})
 			if err != nil {
 				panic(err)
 			}
@@ -647,3 +892,1601 @@ func TestShortURLService_GetStats(t *testing.T) {
 		})
 	}
 }
+
+func TestShortURLService_Update(t *testing.T) {
+	title := "  Yandex  "
+	tooLongTitle := strings.Repeat("a", maxTitleLength+1)
+	stored := &models.ShortURL{ShortURL: "lelele", OriginalURL: "https://ya.ru", UserID: "SomeUserID"}
+	updated := &models.ShortURL{
+		ShortURL:        "lelele",
+		OriginalURL:     "https://ya.ru",
+		UserID:          "SomeUserID",
+		ShortURLOptions: models.ShortURLOptions{Title: "Yandex"},
+	}
+	tests := []struct {
+		wantErr   error
+		stored    *models.ShortURL
+		readErr   error
+		want      *models.ShortURLsByUserResponse
+		update    models.UpdateShortURLRequest
+		name      string
+		userID    string
+		wantWrite bool
+		banned    bool
+	}{
+		{
+			name:      "Successful update",
+			stored:    stored,
+			userID:    "SomeUserID",
+			update:    models.UpdateShortURLRequest{Title: &title},
+			wantWrite: true,
+			want: &models.ShortURLsByUserResponse{
+				ShortURL:        config.Settings.HostedOn + "lelele",
+				OriginalURL:     "https://ya.ru",
+				ShortURLOptions: models.ShortURLOptions{Title: "Yandex"},
+			},
+		},
+		{
+			name:    "Not found",
+			readErr: storage.ErrNotFound,
+			userID:  "SomeUserID",
+			update:  models.UpdateShortURLRequest{Title: &title},
+			wantErr: ErrShortURLNotFound,
+		},
+		{
+			name:    "Deleted",
+			stored:  &models.ShortURL{ShortURL: "lelele", UserID: "SomeUserID", Deleted: true},
+			userID:  "SomeUserID",
+			update:  models.UpdateShortURLRequest{Title: &title},
+			wantErr: ErrShortURLNotFound,
+		},
+		{
+			name:    "Belongs to another user",
+			stored:  stored,
+			userID:  "AnotherUserID",
+			update:  models.UpdateShortURLRequest{Title: &title},
+			wantErr: ErrForbidden,
+		},
+		{
+			name:    "Invalid title",
+			stored:  stored,
+			userID:  "SomeUserID",
+			update:  models.UpdateShortURLRequest{Title: &tooLongTitle},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Banned user",
+			userID:  "SomeUserID",
+			update:  models.UpdateShortURLRequest{Title: &title},
+			banned:  true,
+			wantErr: ErrUserBanned,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repoMock := mocks.NewMockRepository(ctrl)
+			allowAudit(repoMock)
+			s := ShortURLService{
+				repo: repoMock,
+			}
+			ctx := context.Background()
+			if tt.banned {
+				repoMock.EXPECT().ReadUserBan(ctx, tt.userID).Return(&models.UserBan{UserID: tt.userID}, nil)
+				_, err := s.Update(ctx, "lelele", tt.userID, tt.update)
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			repoMock.EXPECT().ReadUserBan(ctx, tt.userID).Return(nil, storage.ErrNotFound)
+			first := repoMock.EXPECT().ReadShortURL(ctx, "lelele").Return(tt.stored, tt.readErr)
+			if tt.wantWrite {
+				trimmed := "Yandex"
+				update := repoMock.EXPECT().
+					Update(ctx, "lelele", models.UpdateShortURLRequest{Title: &trimmed}).
+					Return(nil).After(first)
+				repoMock.EXPECT().ReadShortURL(ctx, "lelele").Return(updated, nil).After(update)
+			}
+			got, err := s.Update(ctx, "lelele", tt.userID, tt.update)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestShortURLService_Resolve(t *testing.T) {
+	stored := &models.ShortURL{ShortURL: "lelele", OriginalURL: "https://ya.ru", UserID: "SomeUserID"}
+	someErr := errors.New("connection lost")
+	tests := []struct {
+		readErr error
+		wantErr error
+		stored  *models.ShortURL
+		want    *models.ShortURL
+		name    string
+	}{
+		{
+			name:   "Successful resolve",
+			stored: stored,
+			want:   stored,
+		},
+		{
+			name:    "Not found",
+			readErr: storage.ErrNotFound,
+			wantErr: ErrShortURLNotFound,
+		},
+		{
+			name:    "Storage error",
+			readErr: someErr,
+			wantErr: someErr,
+		},
+		{
+			name: "Before the activation window",
+			stored: &models.ShortURL{ShortURL: "lelele", OriginalURL: "https://ya.ru", ShortURLOptions: models.ShortURLOptions{
+				RedirectOptions: models.RedirectOptions{ActiveFrom: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)}}},
+			wantErr: ErrNotActiveYet,
+		},
+		{
+			name: "No clicks left",
+			stored: &models.ShortURL{ShortURL: "lelele", OriginalURL: "https://ya.ru",
+				ShortURLOptions: models.ShortURLOptions{MaxClicks: 1}},
+			wantErr: ErrClicksExhausted,
+		},
+		{
+			name: "After the activation window",
+			stored: &models.ShortURL{ShortURL: "lelele", OriginalURL: "https://ya.ru", ShortURLOptions: models.ShortURLOptions{
+				RedirectOptions: models.RedirectOptions{ActiveUntil: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)}}},
+			wantErr: ErrNoLongerActive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repoMock := mocks.NewMockRepository(ctrl)
+			repoMock.EXPECT().ReadUserBan(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound).AnyTimes()
+			s := ShortURLService{
+				repo: repoMock,
+			}
+			ctx := context.Background()
+			repoMock.EXPECT().ReadShortURL(ctx, "lelele").Return(tt.stored, tt.readErr)
+			got, err := s.Resolve(ctx, "lelele")
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_checkActive(t *testing.T) {
+	launch := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	window := models.RedirectOptions{ActiveFrom: "2026-11-01T09:00:00Z", ActiveUntil: "2026-11-08T09:00:00Z"}
+	tests := []struct {
+		wantErr error
+		now     time.Time
+		options models.RedirectOptions
+		name    string
+	}{
+		{name: "No window", now: launch},
+		{name: "Before the window", options: window, now: launch.Add(-time.Second), wantErr: ErrNotActiveYet},
+		{name: "At the start of the window", options: window, now: launch},
+		{name: "Just before the end of the window", options: window, now: launch.Add(7*24*time.Hour - time.Second)},
+		{name: "At the end of the window", options: window, now: launch.Add(7 * 24 * time.Hour), wantErr: ErrNoLongerActive},
+		{name: "Open end", options: models.RedirectOptions{ActiveFrom: window.ActiveFrom}, now: launch.AddDate(10, 0, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkActive(tt.options, tt.now)
+			assert.ErrorIs(t, err, tt.wantErr)
+			var notActiveYetErr *ErrNotActiveYetExtended
+			if errors.As(err, &notActiveYetErr) {
+				assert.Equal(t, launch, notActiveYetErr.ActiveFrom)
+			}
+		})
+	}
+}
+
+func TestShortURLService_CheckPassword(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	protected := &models.ShortURL{ShortURL: "lelele", ShortURLOptions: models.ShortURLOptions{PasswordHash: string(hash)}}
+	s := ShortURLService{passwordAttempts: utils.NewAttemptLimiter(2, time.Minute),
+		linkAttempts: utils.NewAttemptLimiter(3, time.Minute)}
+
+	assert.NoError(t, s.CheckPassword(&models.ShortURL{ShortURL: "public"}, "", "127.0.0.1"),
+		"the short URL without password is always allowed")
+	assert.NoError(t, s.CheckPassword(protected, "secret", "127.0.0.1"))
+
+	assert.ErrorIs(t, s.CheckPassword(protected, "wrong", "127.0.0.1"), ErrWrongPassword)
+	assert.ErrorIs(t, s.CheckPassword(protected, "wrong", "127.0.0.1"), ErrWrongPassword)
+	err = s.CheckPassword(protected, "secret", "127.0.0.1")
+	assert.ErrorIs(t, err, ErrTooManyAttempts, "the right password is rejected too after too many attempts")
+	var tooManyAttemptsErr *ErrTooManyAttemptsExtended
+	require.ErrorAs(t, err, &tooManyAttemptsErr)
+	assert.Positive(t, tooManyAttemptsErr.RetryAfter)
+
+	assert.NoError(t, s.CheckPassword(protected, "secret", "127.0.0.2"), "other IP addresses are not affected")
+
+	assert.ErrorIs(t, s.CheckPassword(protected, "wrong", "127.0.0.3"), ErrWrongPassword)
+	assert.ErrorIs(t, s.CheckPassword(protected, "secret", "127.0.0.4"), ErrTooManyAttempts,
+		"the short URL is limited whatever the IP address is")
+}
+
+func Test_normalizeOptionsHashesPassword(t *testing.T) {
+	options, err := normalizeOptions(models.ShortURLOptions{Password: "secret"})
+	require.NoError(t, err)
+	assert.Empty(t, options.Password, "the plain password must not be stored")
+	assert.True(t, options.Protected())
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(options.PasswordHash), []byte("secret")))
+
+	options, err = normalizeOptions(models.ShortURLOptions{})
+	require.NoError(t, err)
+	assert.False(t, options.Protected())
+
+	_, err = normalizeOptions(models.ShortURLOptions{Password: strings.Repeat("a", maxPasswordLength+1)})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func Test_normalizeOptionsMaxClicks(t *testing.T) {
+	options, err := normalizeOptions(models.ShortURLOptions{MaxClicks: 1})
+	require.NoError(t, err)
+	assert.True(t, options.ClickLimited())
+
+	_, err = normalizeOptions(models.ShortURLOptions{MaxClicks: -1})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func Test_normalizeRedirect(t *testing.T) {
+	tests := []struct {
+		wantErr error
+		name    string
+		options models.RedirectOptions
+		want    models.RedirectOptions
+	}{
+		{
+			name: "Defaults",
+		},
+		{
+			name: "Valid attributes are trimmed",
+			options: models.RedirectOptions{RedirectStatus: http.StatusMovedPermanently, CacheControl: " public, max-age=60 ",
+				ReferrerPolicy: "no-referrer", RobotsTag: "noindex"},
+			want: models.RedirectOptions{RedirectStatus: http.StatusMovedPermanently, CacheControl: "public, max-age=60",
+				ReferrerPolicy: "no-referrer", RobotsTag: "noindex"},
+		},
+		{
+			name:    "Passthrough mode is lowercased",
+			options: models.RedirectOptions{Passthrough: " Query "},
+			want:    models.RedirectOptions{Passthrough: models.PassthroughQuery},
+		},
+		{
+			name:    "Explicit none passthrough mode",
+			options: models.RedirectOptions{Passthrough: "none"},
+			want:    models.RedirectOptions{Passthrough: models.PassthroughNone},
+		},
+		{
+			name:    "Unknown passthrough mode",
+			options: models.RedirectOptions{Passthrough: "everything"},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "Targets are normalized in order",
+			options: models.RedirectOptions{Targets: []models.TargetingRule{
+				{Platform: " iOS ", URL: "https://apps.apple.com/app/id1"},
+				{Platform: "android", URL: " market://details?id=com.example "},
+			}},
+			want: models.RedirectOptions{Targets: []models.TargetingRule{
+				{Platform: "ios", URL: "https://apps.apple.com/app/id1"},
+				{Platform: "android", URL: "market://details?id=com.example"},
+			}},
+		},
+		{
+			name:    "Unknown target platform",
+			options: models.RedirectOptions{Targets: []models.TargetingRule{{Platform: "symbian", URL: "https://ya.ru"}}},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Unsafe target URL",
+			options: models.RedirectOptions{Targets: []models.TargetingRule{{Platform: "ios", URL: "javascript:alert(1)"}}},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Too many targets",
+			options: models.RedirectOptions{Targets: make([]models.TargetingRule, maxTargetsCount+1)},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "Geo rules are normalized in order",
+			options: models.RedirectOptions{GeoRules: []models.GeoRule{
+				{Country: " gb ", URL: "https://ya.ru/uk "},
+				{Country: "SE", URL: "https://ya.ru/se"},
+			}},
+			want: models.RedirectOptions{GeoRules: []models.GeoRule{
+				{Country: "GB", URL: "https://ya.ru/uk"},
+				{Country: "SE", URL: "https://ya.ru/se"},
+			}},
+		},
+		{
+			name:    "Invalid country code",
+			options: models.RedirectOptions{GeoRules: []models.GeoRule{{Country: "GBR", URL: "https://ya.ru"}}},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "Duplicate country",
+			options: models.RedirectOptions{GeoRules: []models.GeoRule{
+				{Country: "gb", URL: "https://ya.ru/1"}, {Country: "GB", URL: "https://ya.ru/2"}}},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Unsafe geo rule URL",
+			options: models.RedirectOptions{GeoRules: []models.GeoRule{{Country: "GB", URL: "data:text/html,hi"}}},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "Variants are named and weighted by default",
+			options: models.RedirectOptions{Variants: []models.SplitVariant{
+				{Name: " control ", URL: "https://ya.ru/a", Weight: 3},
+				{URL: " https://ya.ru/b "},
+			}},
+			want: models.RedirectOptions{Variants: []models.SplitVariant{
+				{Name: "control", URL: "https://ya.ru/a", Weight: 3},
+				{Name: "2", URL: "https://ya.ru/b", Weight: 1},
+			}},
+		},
+		{
+			name:    "Variant name unfit for the cookie",
+			options: models.RedirectOptions{Variants: []models.SplitVariant{{Name: "a;b", URL: "https://ya.ru"}}},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "Duplicate variant",
+			options: models.RedirectOptions{Variants: []models.SplitVariant{
+				{Name: "2", URL: "https://ya.ru/a"}, {URL: "https://ya.ru/b"}}},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Negative variant weight",
+			options: models.RedirectOptions{Variants: []models.SplitVariant{{URL: "https://ya.ru", Weight: -1}}},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Too heavy variant",
+			options: models.RedirectOptions{Variants: []models.SplitVariant{{URL: "https://ya.ru", Weight: maxVariantWeight + 1}}},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Unsafe variant URL",
+			options: models.RedirectOptions{Variants: []models.SplitVariant{{URL: "javascript:alert(1)"}}},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Activation window is converted to UTC",
+			options: models.RedirectOptions{ActiveFrom: " 2026-11-01T12:00:00+03:00 ", ActiveUntil: "2026-11-08T09:00:00Z"},
+			want:    models.RedirectOptions{ActiveFrom: "2026-11-01T09:00:00Z", ActiveUntil: "2026-11-08T09:00:00Z"},
+		},
+		{
+			name:    "Activation time in another format",
+			options: models.RedirectOptions{ActiveFrom: "2026-11-01 09:00"},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Empty activation window",
+			options: models.RedirectOptions{ActiveFrom: "2026-11-01T09:00:00Z", ActiveUntil: "2026-11-01T12:00:00+03:00"},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Unsupported status",
+			options: models.RedirectOptions{RedirectStatus: http.StatusOK},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Unknown referrer policy",
+			options: models.RedirectOptions{ReferrerPolicy: "everyone"},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Header injection",
+			options: models.RedirectOptions{RobotsTag: "noindex\r\nSet-Cookie: a=b"},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Too long header",
+			options: models.RedirectOptions{CacheControl: strings.Repeat("a", maxHeaderValueLength+1)},
+			wantErr: ErrInvalidOptions,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeRedirect(tt.options)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_normalizeUpdateRedirect(t *testing.T) {
+	permanent := http.StatusPermanentRedirect
+	cacheControl := " no-cache "
+	update, err := normalizeUpdate(models.UpdateShortURLRequest{RedirectStatus: &permanent, CacheControl: &cacheControl})
+	require.NoError(t, err)
+	assert.Equal(t, "no-cache", *update.CacheControl)
+	assert.Nil(t, update.RobotsTag)
+
+	invalidStatus := http.StatusNotFound
+	_, err = normalizeUpdate(models.UpdateShortURLRequest{RedirectStatus: &invalidStatus})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+
+	invalidPolicy := "everyone"
+	_, err = normalizeUpdate(models.UpdateShortURLRequest{ReferrerPolicy: &invalidPolicy})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+
+	passthrough := "PATH"
+	update, err = normalizeUpdate(models.UpdateShortURLRequest{Passthrough: &passthrough})
+	require.NoError(t, err)
+	assert.Equal(t, models.PassthroughPath, *update.Passthrough)
+}
+
+func TestShortURLService_CreateWithUTMTemplate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repoMock := mocks.NewMockRepository(ctrl)
+	repoMock.EXPECT().ReadUserBan(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound).AnyTimes()
+	s := ShortURLService{repo: repoMock}
+	ctx := context.Background()
+	template := &models.UTMTemplate{ID: "template", UserID: "AnotherUserID"}
+	repoMock.EXPECT().ReadUTMTemplate(ctx, "template").Return(template, nil)
+	_, err := s.Create(ctx, "https://ya.ru", "SomeUserID", models.ShortURLOptions{UTMTemplateID: " template "})
+	assert.ErrorIs(t, err, ErrInvalidOptions, "the template of another user must not be attached")
+
+	repoMock.EXPECT().ReadUTMTemplate(ctx, "missing").Return(nil, storage.ErrNotFound)
+	_, err = s.BatchCreate(ctx, []models.ShortenBatchItemRequest{
+		{OriginalURL: "https://ya.ru", ShortURLOptions: models.ShortURLOptions{UTMTemplateID: "missing"}},
+		{OriginalURL: "https://ya.ru/2", ShortURLOptions: models.ShortURLOptions{UTMTemplateID: "missing"}},
+	}, "SomeUserID")
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func TestShortURLService_UTMTemplates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repoMock := mocks.NewMockRepository(ctrl)
+	allowAudit(repoMock)
+	s := ShortURLService{repo: repoMock}
+	ctx := context.Background()
+
+	var created models.UTMTemplate
+	repoMock.EXPECT().CreateUTMTemplate(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, template models.UTMTemplate) error {
+			created = template
+			return nil
+		})
+	got, err := s.CreateUTMTemplate(ctx, "SomeUserID", models.UTMTemplate{
+		ID:            "ignored",
+		Name:          " Newsletter ",
+		UTMParameters: models.UTMParameters{Source: " newsletter ", Medium: "email"},
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, "ignored", got.ID, "the ID is generated")
+	assert.Equal(t, "SomeUserID", got.UserID)
+	assert.Equal(t, "Newsletter", got.Name)
+	assert.Equal(t, models.UTMParameters{Source: "newsletter", Medium: "email"}, got.UTMParameters)
+	assert.Equal(t, created, *got)
+
+	repoMock.EXPECT().ReadUTMTemplate(ctx, created.ID).Return(&created, nil).Times(2)
+	_, err = s.UpdateUTMTemplate(ctx, created.ID, "AnotherUserID", created)
+	assert.ErrorIs(t, err, ErrUTMTemplateNotFound, "the template of another user is not found")
+
+	changed := models.UTMTemplate{Name: "Newsletter", UTMParameters: models.UTMParameters{Campaign: "autumn"}}
+	want := models.UTMTemplate{ID: created.ID, UserID: "SomeUserID", Name: "Newsletter",
+		UTMParameters: models.UTMParameters{Campaign: "autumn"}}
+	repoMock.EXPECT().UpdateUTMTemplate(ctx, want).Return(nil)
+	got, err = s.UpdateUTMTemplate(ctx, created.ID, "SomeUserID", changed)
+	require.NoError(t, err)
+	assert.Equal(t, want, *got)
+
+	repoMock.EXPECT().ReadUTMTemplate(ctx, "missing").Return(nil, storage.ErrNotFound)
+	assert.ErrorIs(t, s.DeleteUTMTemplate(ctx, "missing", "SomeUserID"), ErrUTMTemplateNotFound)
+}
+
+func TestShortURLService_CreateWithCampaign(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repoMock := mocks.NewMockRepository(ctrl)
+	repoMock.EXPECT().ReadUserBan(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound).AnyTimes()
+	s := ShortURLService{repo: repoMock}
+	ctx := context.Background()
+	campaign := &models.Campaign{ID: "campaign", UserID: "AnotherUserID"}
+	repoMock.EXPECT().ReadCampaign(ctx, "campaign").Return(campaign, nil).Times(2)
+	_, err := s.Create(ctx, "https://ya.ru", "SomeUserID", models.ShortURLOptions{CampaignID: " campaign "})
+	assert.ErrorIs(t, err, ErrInvalidOptions, "the short URL must not be added to the campaign of another user")
+
+	_, err = s.ReadByUserID(ctx, "SomeUserID", models.ShortURLFilter{CampaignID: "campaign"})
+	assert.ErrorIs(t, err, ErrCampaignNotFound, "the campaign of another user is not listed")
+
+	repoMock.EXPECT().ReadCampaign(ctx, "missing").Return(nil, storage.ErrNotFound)
+	_, err = s.BatchCreate(ctx, []models.ShortenBatchItemRequest{
+		{OriginalURL: "https://ya.ru", ShortURLOptions: models.ShortURLOptions{CampaignID: "missing"}},
+		{OriginalURL: "https://ya.ru/2", ShortURLOptions: models.ShortURLOptions{CampaignID: "missing"}},
+	}, "SomeUserID")
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
+
+func TestShortURLService_Campaigns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repoMock := mocks.NewMockRepository(ctrl)
+	allowAudit(repoMock)
+	s := ShortURLService{
+		repo:             repoMock,
+		doneChan:         make(chan struct{}),
+		deleteMsgChanIn:  make(chan models.ShortURLChannelMessage, 2),
+		deleteMsgChanOut: make(chan models.ShortURLChannelMessage, 2),
+	}
+	defer close(s.doneChan)
+	ctx := context.Background()
+
+	_, err := s.CreateCampaign(ctx, "SomeUserID", models.Campaign{Name: "  "})
+	assert.ErrorIs(t, err, ErrInvalidCampaign)
+
+	var created models.Campaign
+	repoMock.EXPECT().CreateCampaign(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, campaign models.Campaign) error {
+			created = campaign
+			return nil
+		})
+	got, err := s.CreateCampaign(ctx, "SomeUserID", models.Campaign{ID: "ignored", Name: " Black Friday "})
+	require.NoError(t, err)
+	assert.NotEqual(t, "ignored", got.ID, "the ID is generated")
+	assert.Equal(t, models.Campaign{ID: got.ID, UserID: "SomeUserID", Name: "Black Friday"}, *got)
+	assert.Equal(t, created, *got)
+
+	repoMock.EXPECT().ReadCampaign(ctx, created.ID).Return(&created, nil).AnyTimes()
+	_, err = s.UpdateCampaign(ctx, created.ID, "AnotherUserID", models.Campaign{Name: "Cyber Monday"})
+	assert.ErrorIs(t, err, ErrCampaignNotFound, "the campaign of another user is not found")
+	_, err = s.GetCampaignStats(ctx, created.ID, "AnotherUserID")
+	assert.ErrorIs(t, err, ErrCampaignNotFound)
+
+	renamed := models.Campaign{ID: created.ID, UserID: "SomeUserID", Name: "Cyber Monday"}
+	repoMock.EXPECT().UpdateCampaign(ctx, renamed).Return(nil)
+	got, err = s.UpdateCampaign(ctx, created.ID, "SomeUserID", models.Campaign{Name: "Cyber Monday"})
+	require.NoError(t, err)
+	assert.Equal(t, renamed, *got)
+
+	stats := &models.CampaignStats{Campaign: created, URLs: 2, Clicks: 7}
+	repoMock.EXPECT().GetCampaignStats(ctx, created.ID).Return(stats, nil)
+	gotStats, err := s.GetCampaignStats(ctx, created.ID, "SomeUserID")
+	require.NoError(t, err)
+	assert.Equal(t, stats, gotStats)
+
+	repoMock.EXPECT().ReadByUserID(ctx, "SomeUserID", models.ShortURLFilter{CampaignID: created.ID}).
+		Return([]models.ShortURLsByUserResponse{{ShortURL: "lelelele"}, {ShortURL: "go.example/lalalala"}}, nil)
+	repoMock.EXPECT().DeleteCampaign(ctx, created.ID).Return(nil)
+	repoMock.EXPECT().ReadShortURL(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, id string) (*models.ShortURL, error) {
+			return &models.ShortURL{ShortURL: id, UserID: "SomeUserID"}, nil
+		}).Times(2)
+	require.NoError(t, s.DeleteCampaign(ctx, created.ID, "SomeUserID"))
+	var scheduled []string
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-s.deleteMsgChanOut:
+			scheduled = append(scheduled, msg.ShortURL)
+		case <-time.After(time.Second):
+			t.Fatal("the short URLs of the campaign are not scheduled for the deletion")
+		}
+	}
+	assert.ElementsMatch(t, []string{"lelelele", "go.example/lalalala"}, scheduled)
+}
+
+func TestShortURLService_Organizations(t *testing.T) {
+	repo := storage.MemoryRepo{}
+	s := ShortURLService{repo: repo}
+	ctx := context.Background()
+	for _, account := range []models.Account{
+		{ID: "OrgsOwner", Email: "orgs-owner@example.com"},
+		{ID: "OrgsAdmin", Email: "orgs-admin@example.com"},
+		{ID: "OrgsEditor", Email: "orgs-editor@example.com"},
+		{ID: "OrgsViewer", Email: "orgs-viewer@example.com"},
+	} {
+		require.NoError(t, repo.CreateAccount(ctx, account))
+	}
+
+	_, err := s.CreateOrganization(ctx, "OrgsOwner", models.Organization{Name: "  "})
+	assert.ErrorIs(t, err, ErrInvalidOrganization, "the name is required")
+	org, err := s.CreateOrganization(ctx, "OrgsOwner", models.Organization{ID: "ignored", Name: " Acme "})
+	require.NoError(t, err)
+	assert.NotEqual(t, "ignored", org.ID, "the ID is generated")
+	assert.Equal(t, "Acme", org.Name)
+	assert.Equal(t, models.RoleOwner, org.Role)
+
+	admin, err := s.AddOrgMember(ctx, org.ID, "OrgsOwner",
+		models.AddOrgMemberRequest{Email: " Orgs-Admin@Example.com ", Role: "Admin"})
+	require.NoError(t, err)
+	assert.Equal(t, "OrgsAdmin", admin.UserID)
+	assert.Equal(t, models.RoleAdmin, admin.Role)
+	_, err = s.AddOrgMember(ctx, org.ID, "OrgsAdmin",
+		models.AddOrgMemberRequest{Email: "orgs-editor@example.com", Role: models.RoleOwner})
+	assert.ErrorIs(t, err, ErrOrgForbidden, "only the owners grant the owner role")
+	_, err = s.AddOrgMember(ctx, org.ID, "OrgsAdmin",
+		models.AddOrgMemberRequest{Email: "orgs-editor@example.com", Role: models.RoleEditor})
+	require.NoError(t, err)
+	_, err = s.AddOrgMember(ctx, org.ID, "OrgsAdmin",
+		models.AddOrgMemberRequest{Email: "orgs-viewer@example.com", Role: models.RoleViewer})
+	require.NoError(t, err)
+	_, err = s.AddOrgMember(ctx, org.ID, "OrgsAdmin",
+		models.AddOrgMemberRequest{Email: "orgs-viewer@example.com", Role: models.RoleViewer})
+	assert.ErrorIs(t, err, ErrInvalidOrganization, "the member is added once")
+	_, err = s.AddOrgMember(ctx, org.ID, "OrgsEditor",
+		models.AddOrgMemberRequest{Email: "nobody@example.com", Role: models.RoleViewer})
+	assert.ErrorIs(t, err, ErrOrgForbidden, "the editors don't manage the members")
+	_, err = s.ReadOrgMembers(ctx, org.ID, "OrgsStranger")
+	assert.ErrorIs(t, err, ErrOrganizationNotFound, "the organization is hidden from the non-members")
+
+	members, err := s.ReadOrgMembers(ctx, org.ID, "OrgsViewer")
+	require.NoError(t, err)
+	require.Len(t, members, 4)
+	assert.Equal(t, "orgs-owner@example.com", members[0].Email)
+
+	_, err = s.Create(ctx, "https://ya.ru/orgs", "OrgsViewer", models.ShortURLOptions{OrgID: org.ID})
+	assert.ErrorIs(t, err, ErrOrgForbidden, "the viewers don't create the links")
+	_, err = s.Create(ctx, "https://ya.ru/orgs", "OrgsStranger", models.ShortURLOptions{OrgID: org.ID})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+	created, err := s.Create(ctx, "https://ya.ru/orgs", "OrgsEditor", models.ShortURLOptions{OrgID: " " + org.ID + " "})
+	require.NoError(t, err)
+	shortURL := created[strings.LastIndex(created, "/")+1:]
+
+	title := "Shared"
+	updated, err := s.Update(ctx, shortURL, "OrgsAdmin", models.UpdateShortURLRequest{Title: &title})
+	require.NoError(t, err, "the link of the organization is editable by its editors")
+	assert.Equal(t, "Shared", updated.Title)
+	_, err = s.Update(ctx, shortURL, "OrgsViewer", models.UpdateShortURLRequest{Title: &title})
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = s.Update(ctx, shortURL, "OrgsStranger", models.UpdateShortURLRequest{Title: &title})
+	assert.ErrorIs(t, err, ErrForbidden)
+	urls, err := s.ReadByOrgID(ctx, org.ID, "OrgsViewer", models.ShortURLFilter{})
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	assert.Equal(t, org.ID, urls[0].OrgID)
+
+	_, err = s.UpdateOrgMember(ctx, org.ID, "OrgsOwner", "OrgsOwner", models.RoleAdmin)
+	assert.ErrorIs(t, err, ErrInvalidOrganization, "the last owner can't be demoted")
+	assert.ErrorIs(t, s.RemoveOrgMember(ctx, org.ID, "OrgsOwner", "OrgsOwner"), ErrInvalidOrganization,
+		"the last owner can't leave")
+	assert.ErrorIs(t, s.RemoveOrgMember(ctx, org.ID, "OrgsOwner", "OrgsAdmin"), ErrOrgForbidden)
+	_, err = s.UpdateOrgMember(ctx, org.ID, "OrgsAdmin", "OrgsOwner", models.RoleOwner)
+	require.NoError(t, err)
+	_, err = s.UpdateOrgMember(ctx, org.ID, "OrgsOwner", "OrgsAdmin", models.RoleViewer)
+	require.NoError(t, err, "the organization keeps another owner")
+	require.NoError(t, s.RemoveOrgMember(ctx, org.ID, "OrgsViewer", "OrgsViewer"), "the member leaves")
+	assert.ErrorIs(t, s.RemoveOrgMember(ctx, org.ID, "OrgsViewer", "OrgsAdmin"), ErrOrgMemberNotFound)
+	_, err = s.ReadByOrgID(ctx, org.ID, "OrgsViewer", models.ShortURLFilter{})
+	assert.ErrorIs(t, err, ErrOrganizationNotFound)
+
+	orgs, err := s.ReadOrganizationsByUserID(ctx, "OrgsOwner")
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	assert.Equal(t, models.RoleViewer, orgs[0].Role)
+}
+
+func TestShortURLService_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repoMock := mocks.NewMockRepository(ctrl)
+	allowAudit(repoMock)
+	s := ShortURLService{repo: repoMock}
+	ctx := context.Background()
+
+	_, err := s.Register(ctx, models.Credentials{Email: "Jane <jane@example.com>", Password: "correct horse"}, "", "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidAccount, "the display name is not a part of the email")
+	_, err = s.Register(ctx, models.Credentials{Email: "jane@example.com", Password: "short"}, "", "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidAccount)
+
+	var created models.Account
+	repoMock.EXPECT().ReadAccount(ctx, "AnonymousUserID").Return(nil, storage.ErrNotFound)
+	repoMock.EXPECT().CreateAccount(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, account models.Account) error {
+			created = account
+			return nil
+		})
+	got, err := s.Register(ctx, models.Credentials{Email: " Jane@Example.com ", Password: "correct horse",
+		ClaimLinks: true}, "AnonymousUserID", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "AnonymousUserID", got.ID, "the account takes over the links of the anonymous user")
+	assert.Equal(t, "jane@example.com", got.Email)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(got.PasswordHash), []byte("correct horse")))
+	assert.Equal(t, created, *got)
+
+	repoMock.EXPECT().CreateAccount(ctx, gomock.Any()).Return(storage.ErrAlreadyExists)
+	got, err = s.Register(ctx, models.Credentials{Email: "jane@example.com", Password: "correct horse"},
+		"AnotherUserID", "127.0.0.1")
+	assert.ErrorIs(t, err, ErrAccountExists)
+	assert.Nil(t, got)
+
+	repoMock.EXPECT().ReadAccount(ctx, "AnonymousUserID").Return(&created, nil)
+	_, err = s.Register(ctx, models.Credentials{Email: "john@example.com", Password: "correct horse",
+		ClaimLinks: true}, "AnonymousUserID", "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidAccount, "the registered user can't be claimed")
+}
+
+func TestShortURLService_RegisterLimited(t *testing.T) {
+	s := ShortURLService{registerAttempts: utils.NewAttemptLimiter(2, time.Minute)}
+	ctx := context.Background()
+	for range 2 {
+		_, err := s.Register(ctx, models.Credentials{Email: "jane@example.com", Password: "short"}, "", "127.0.0.1")
+		assert.ErrorIs(t, err, ErrInvalidAccount)
+	}
+	_, err := s.Register(ctx, models.Credentials{Email: "jane@example.com", Password: "correct horse"}, "", "127.0.0.1")
+	assert.ErrorIs(t, err, ErrTooManyAttempts)
+	_, err = s.Register(ctx, models.Credentials{Email: "jane@example.com", Password: "short"}, "", "127.0.0.2")
+	assert.ErrorIs(t, err, ErrInvalidAccount, "other IP addresses are not affected")
+}
+
+func TestShortURLService_Login(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repoMock := mocks.NewMockRepository(ctrl)
+	s := ShortURLService{repo: repoMock, passwordAttempts: utils.NewAttemptLimiter(2, time.Minute),
+		accountAttempts: utils.NewAttemptLimiter(3, time.Minute)}
+	ctx := context.Background()
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	require.NoError(t, err)
+	account := &models.Account{ID: "AccountID", Email: "jane@example.com", PasswordHash: string(hash)}
+
+	repoMock.EXPECT().ReadAccountByEmail(ctx, "jane@example.com").Return(account, nil).Times(3)
+	got, err := s.Login(ctx, models.Credentials{Email: "Jane@Example.com", Password: "correct horse"}, "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, account, got)
+
+	_, err = s.Login(ctx, models.Credentials{Email: "jane@example.com", Password: "wrong"}, "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	repoMock.EXPECT().ReadAccountByEmail(ctx, "john@example.com").Return(nil, storage.ErrNotFound)
+	_, err = s.Login(ctx, models.Credentials{Email: "john@example.com", Password: "correct horse"}, "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials, "the unknown email looks the same as the wrong password")
+
+	_, err = s.Login(ctx, models.Credentials{Email: "jane@example.com", Password: "wrong"}, "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = s.Login(ctx, models.Credentials{Email: "jane@example.com", Password: "correct horse"}, "127.0.0.1")
+	assert.ErrorIs(t, err, ErrTooManyAttempts)
+
+	repoMock.EXPECT().ReadAccountByEmail(ctx, "jane@example.com").Return(account, nil)
+	_, err = s.Login(ctx, models.Credentials{Email: "jane@example.com", Password: "wrong"}, "127.0.0.2")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = s.Login(ctx, models.Credentials{Email: "jane@example.com", Password: "correct horse"}, "127.0.0.3")
+	assert.ErrorIs(t, err, ErrTooManyAttempts, "the email is limited whatever the IP address is")
+}
+
+func Test_normalizeUTMTemplate(t *testing.T) {
+	tests := []struct {
+		wantErr  error
+		template models.UTMTemplate
+		name     string
+	}{
+		{
+			name:     "Valid",
+			template: models.UTMTemplate{Name: "Ads", UTMParameters: models.UTMParameters{Source: "google"}},
+		},
+		{
+			name:     "No name",
+			template: models.UTMTemplate{Name: "  ", UTMParameters: models.UTMParameters{Source: "google"}},
+			wantErr:  ErrInvalidUTMTemplate,
+		},
+		{
+			name:     "No parameters",
+			template: models.UTMTemplate{Name: "Ads", UTMParameters: models.UTMParameters{Term: "  "}},
+			wantErr:  ErrInvalidUTMTemplate,
+		},
+		{
+			name: "Too long parameter",
+			template: models.UTMTemplate{Name: "Ads",
+				UTMParameters: models.UTMParameters{Content: strings.Repeat("a", maxUTMValueLength+1)}},
+			wantErr: ErrInvalidUTMTemplate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := normalizeUTMTemplate(tt.template)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func Test_normalizeTags(t *testing.T) {
+	tests := []struct {
+		wantErr error
+		name    string
+		tags    []string
+		want    []string
+	}{
+		{
+			name: "Lowercased, trimmed, deduplicated and sorted",
+			tags: []string{" Search ", "news", "", "search"},
+			want: []string{"news", "search"},
+		},
+		{
+			name: "Empty list",
+			tags: nil,
+			want: nil,
+		},
+		{
+			name:    "Comma in tag",
+			tags:    []string{"news,search"},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name:    "Too long tag",
+			tags:    []string{strings.Repeat("a", maxTagLength+1)},
+			wantErr: ErrInvalidOptions,
+		},
+		{
+			name: "Too many tags",
+			tags: func() []string {
+				tags := make([]string, 0, maxTagsCount+1)
+				for i := 0; i <= maxTagsCount; i++ {
+					tags = append(tags, strconv.Itoa(i))
+				}
+				return tags
+			}(),
+			wantErr: ErrInvalidOptions,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTags(tt.tags)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type FetcherMock struct {
+	err      error
+	metadata *models.PageMetadata
+}
+
+func (fm FetcherMock) Fetch(_ context.Context, _ string) (*models.PageMetadata, error) {
+	return fm.metadata, fm.err
+}
+
+func TestShortURLService_fetchMetadata(t *testing.T) {
+	pageMetadata := &models.PageMetadata{Title: "Yandex", FaviconURL: "https://ya.ru/favicon.ico"}
+	tests := []struct {
+		fetcher   FetcherMock
+		name      string
+		wantStore bool
+	}{
+		{
+			name:      "Fetched metadata is stored",
+			fetcher:   FetcherMock{metadata: pageMetadata},
+			wantStore: true,
+		},
+		{
+			name:      "Fetching error is skipped",
+			fetcher:   FetcherMock{err: errors.New("timeout")},
+			wantStore: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repoMock := mocks.NewMockRepository(ctrl)
+			s := ShortURLService{
+				repo:            repoMock,
+				fetcher:         tt.fetcher,
+				metadataTimeout: time.Second,
+			}
+			if tt.wantStore {
+				repoMock.EXPECT().SetMetadata(gomock.Any(), "lelele", *pageMetadata).Return(nil)
+				repoMock.EXPECT().ReadShortURL(gomock.Any(), "lelele").Return(&models.ShortURL{
+					ShortURL:    "lelele",
+					OriginalURL: "https://ya.ru",
+					Metadata:    pageMetadata,
+				}, nil)
+			}
+			s.fetchMetadata(models.MetadataJob{ShortURL: "lelele", OriginalURL: "https://ya.ru"})
+		})
+	}
+}
+
+func TestShortURLService_scheduleMetadataFetch(t *testing.T) {
+	s := ShortURLService{metadataJobs: make(chan models.MetadataJob, 1)}
+	s.scheduleMetadataFetch("lelele", "https://ya.ru")
+	s.scheduleMetadataFetch("lololo", "https://yandex.ru")
+	assert.Len(t, s.metadataJobs, 1)
+	assert.Equal(t, models.MetadataJob{ShortURL: "lelele", OriginalURL: "https://ya.ru"}, <-s.metadataJobs)
+
+	disabled := ShortURLService{}
+	disabled.scheduleMetadataFetch("lelele", "https://ya.ru")
+}
+
+func TestShortURLService_RecordClick(t *testing.T) {
+	s := ShortURLService{clicks: make(chan models.VariantClick, 1)}
+	s.RecordClick("lelele", "a")
+	s.RecordClick("lelele", "b")
+	assert.Len(t, s.clicks, 1, "the click is dropped when the queue is full")
+	assert.Equal(t, models.VariantClick{ShortURL: "lelele", Variant: "a"}, <-s.clicks)
+}
+
+func TestShortURLService_UseClick(t *testing.T) {
+	ctx := context.Background()
+	limited := &models.ShortURL{ShortURL: "lelele", ShortURLOptions: models.ShortURLOptions{MaxClicks: 1}, ClicksLeft: 1}
+	someErr := errors.New("connection lost")
+	tests := []struct {
+		useErr     error
+		wantErr    error
+		shortURL   *models.ShortURL
+		name       string
+		clicksLeft int64
+		wantUse    bool
+		wantAudit  bool
+	}{
+		{name: "Unlimited short URL", shortURL: &models.ShortURL{ShortURL: "lelele"}},
+		{name: "Click is taken", shortURL: limited, wantUse: true, clicksLeft: 2},
+		{name: "Last click is taken", shortURL: limited, wantUse: true, wantAudit: true},
+		{name: "No clicks left", shortURL: limited, wantUse: true, useErr: storage.ErrNoClicksLeft,
+			wantErr: ErrClicksExhausted},
+		{name: "Deleted meanwhile", shortURL: limited, wantUse: true, useErr: storage.ErrNotFound,
+			wantErr: ErrClicksExhausted},
+		{name: "Storage error", shortURL: limited, wantUse: true, useErr: someErr, wantErr: someErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repoMock := mocks.NewMockRepository(ctrl)
+			s := ShortURLService{repo: repoMock}
+			if tt.wantUse {
+				repoMock.EXPECT().UseClick(ctx, "lelele").Return(tt.clicksLeft, tt.useErr)
+			}
+			var audited []models.AuditEvent
+			if tt.wantAudit {
+				repoMock.EXPECT().AppendAuditEvents(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, events []models.AuditEvent, _ func(models.AuditEvent) string) (
+						[]models.AuditEvent, error) {
+						audited = append(audited, events...)
+						return events, nil
+					})
+			}
+			assert.ErrorIs(t, s.UseClick(ctx, tt.shortURL), tt.wantErr)
+			if tt.wantAudit {
+				require.Len(t, audited, 1)
+				assert.Equal(t, AuditURLClicksExhausted, audited[0].Action)
+				assert.Equal(t, "lelele", audited[0].Target)
+			}
+		})
+	}
+}
+
+func TestShortURLService_FillUsedClicks(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repoMock := mocks.NewMockRepository(ctrl)
+	s := ShortURLService{repo: repoMock}
+	repoMock.EXPECT().UseClick(ctx, "lelele").Return(int64(0), nil)
+	repoMock.EXPECT().UseClick(ctx, "lelele").Return(int64(0), storage.ErrNoClicksLeft)
+	assert.NoError(t, s.FillUsedClicks(ctx, "lelele", 3), "the clicks over the limit are dropped")
+}
+
+func TestShortURLService_flushDeletions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repoMock := mocks.NewMockRepository(ctrl)
+	s := ShortURLService{repo: repoMock}
+	actor := models.Actor{UserID: "SomeUserID", IP: "192.0.2.10", Transport: models.TransportHTTP}
+	messages := []models.ShortURLChannelMessage{
+		{Actor: actor, ShortURL: "lelele", UserID: "SomeUserID"},
+		{Actor: actor, ShortURL: "lololo", UserID: "SomeUserID"},
+	}
+
+	repoMock.EXPECT().SetURLsInactive(gomock.Any(), []string{"lelele", "lololo"}).Return(errors.New("connection lost"))
+	assert.Error(t, s.flushDeletions(messages), "nothing is recorded in the audit log if the storage fails")
+
+	var audited []models.AuditEvent
+	repoMock.EXPECT().SetURLsInactive(gomock.Any(), []string{"lelele", "lololo"}).Return(nil)
+	repoMock.EXPECT().AppendAuditEvents(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, events []models.AuditEvent, _ func(models.AuditEvent) string) ([]models.AuditEvent, error) {
+			audited = events
+			return events, nil
+		}).Times(1)
+	require.NoError(t, s.flushDeletions(messages))
+	require.Len(t, audited, 2)
+	for i, event := range audited {
+		assert.Equal(t, AuditURLDelete, event.Action)
+		assert.Equal(t, messages[i].ShortURL, event.Target)
+		assert.Equal(t, "SomeUserID", event.UserID)
+		assert.Equal(t, "192.0.2.10", event.IP)
+		assert.Equal(t, models.TransportHTTP, event.Transport)
+	}
+}
+
+func TestShortURLService_flushClicks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repoMock := mocks.NewMockRepository(ctrl)
+	s := ShortURLService{repo: repoMock}
+	repoMock.EXPECT().AddVariantClicks(gomock.Any(), "lelele", "a", int64(3)).Return(nil)
+	repoMock.EXPECT().AddVariantClicks(gomock.Any(), "lololo", "b", int64(1)).Return(storage.ErrNotFound)
+	s.flushClicks(map[models.VariantClick]int64{
+		{ShortURL: "lelele", Variant: "a"}: 3,
+		{ShortURL: "lololo", Variant: "b"}: 1,
+	})
+}
+
+func TestShortURLService_GetShortURLStats(t *testing.T) {
+	ctx := context.Background()
+	split := &models.ShortURL{ShortURL: "lelele", OriginalURL: "https://ya.ru", UserID: "SomeUserID",
+		ShortURLOptions: models.ShortURLOptions{RedirectOptions: models.RedirectOptions{Variants: []models.SplitVariant{
+			{Name: "a", URL: "https://ya.ru/a", Weight: 1},
+			{Name: "b", URL: "https://ya.ru/b", Weight: 2},
+		}}}}
+	tests := []struct {
+		shortURL *models.ShortURL
+		readErr  error
+		want     *models.ShortURLStats
+		wantErr  error
+		name     string
+		userID   string
+	}{
+		{
+			name:     "Current variants first, then the removed ones",
+			shortURL: split,
+			userID:   "SomeUserID",
+			want: &models.ShortURLStats{ShortURL: config.Settings.HostedOn + "lelele", Variants: []models.VariantStats{
+				{SplitVariant: models.SplitVariant{Name: "a", URL: "https://ya.ru/a", Weight: 1}, Clicks: 10},
+				{SplitVariant: models.SplitVariant{Name: "b", URL: "https://ya.ru/b", Weight: 2}},
+				{SplitVariant: models.SplitVariant{Name: "old"}, Clicks: 4},
+			}},
+		},
+		{
+			name:     "Short URL of another user",
+			shortURL: split,
+			userID:   "AnotherUserID",
+			wantErr:  ErrForbidden,
+		},
+		{
+			name:    "Missing short URL",
+			readErr: storage.ErrNotFound,
+			userID:  "SomeUserID",
+			wantErr: ErrShortURLNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repoMock := mocks.NewMockRepository(ctrl)
+			s := ShortURLService{repo: repoMock}
+			repoMock.EXPECT().ReadShortURL(ctx, "lelele").Return(tt.shortURL, tt.readErr)
+			if tt.want != nil {
+				repoMock.EXPECT().ReadVariantClicks(ctx, "lelele").Return(map[string]int64{"a": 10, "old": 4}, nil)
+			}
+			got, err := s.GetShortURLStats(ctx, "lelele", tt.userID)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestShortURLService_CreateFetchesMetadata(t *testing.T) {
+	destination := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+		_, _ = writer.Write([]byte(`<html><head><title>Destination</title></head></html>`))
+	}))
+	defer destination.Close()
+
+	doneChan := make(chan struct{})
+	defer close(doneChan)
+	s := ShortURLService{
+		repo:            storage.MemoryRepo{},
+		fetcher:         metadata.NewHTTPFetcher(utils.URLPolicy{AllowPrivateNetworks: true}, time.Second),
+		doneChan:        doneChan,
+		metadataJobs:    make(chan models.MetadataJob, 1),
+		metadataTimeout: time.Second,
+	}
+	go s.FetchMetadata()
+
+	shortURL, err := s.Create(context.Background(), destination.URL, "MetadataUserID", models.ShortURLOptions{})
+	require.NoError(t, err)
+	id := strings.TrimPrefix(shortURL, config.Settings.HostedOn)
+	assert.Eventually(t, func() bool {
+		record, readErr := s.repo.ReadShortURL(context.Background(), id)
+		return readErr == nil && record.Metadata != nil && record.Metadata.Title == "Destination"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_normalizeQROptions(t *testing.T) {
+	tests := []struct {
+		wantErr error
+		name    string
+		options models.QROptions
+		want    models.QROptions
+	}{
+		{
+			name: "Defaults",
+			want: models.QROptions{Format: qr.FormatPNG, Level: qr.LevelMedium, Size: defaultQRSize},
+		},
+		{
+			name:    "Format and level are case-insensitive",
+			options: models.QROptions{Format: " SVG ", Level: "h", Size: 512},
+			want:    models.QROptions{Format: qr.FormatSVG, Level: qr.LevelHigh, Size: 512},
+		},
+		{
+			name:    "Unsupported format",
+			options: models.QROptions{Format: "gif"},
+			wantErr: ErrInvalidQROptions,
+		},
+		{
+			name:    "Unsupported level",
+			options: models.QROptions{Level: "X"},
+			wantErr: ErrInvalidQROptions,
+		},
+		{
+			name:    "Too small",
+			options: models.QROptions{Size: minQRSize - 1},
+			wantErr: ErrInvalidQROptions,
+		},
+		{
+			name:    "Too large",
+			options: models.QROptions{Size: maxQRSize + 1},
+			wantErr: ErrInvalidQROptions,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeQROptions(tt.options)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestShortURLService_GetQRCode(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repoMock := mocks.NewMockRepository(ctrl)
+	s := ShortURLService{repo: repoMock}
+	repoMock.EXPECT().ReadShortURL(ctx, "lelele").Return(&models.ShortURL{ShortURL: "lelele"}, nil).Times(2)
+	repoMock.EXPECT().ReadShortURL(ctx, "deleted").Return(&models.ShortURL{ShortURL: "deleted", Deleted: true}, nil)
+	repoMock.EXPECT().ReadShortURL(ctx, "missing").Return(nil, storage.ErrNotFound)
+	repoMock.EXPECT().ReadShortURL(ctx, "disabled").Return(&models.ShortURL{
+		ShortURL: "disabled", Moderation: &models.Moderation{Reason: "Phishing"},
+	}, nil)
+	repoMock.EXPECT().ReadShortURL(ctx, "banned").Return(&models.ShortURL{ShortURL: "banned", UserID: "BannedUserID"}, nil)
+	repoMock.EXPECT().ReadUserBan(ctx, "BannedUserID").Return(&models.UserBan{UserID: "BannedUserID"}, nil)
+
+	code, err := s.GetQRCode(ctx, "lelele", models.QROptions{Format: qr.FormatSVG})
+	require.NoError(t, err)
+	assert.Equal(t, "image/svg+xml", code.ContentType)
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, code.ETag)
+	want, err := qr.Render(config.Settings.HostedOn+"lelele", qr.FormatSVG, qr.LevelMedium, defaultQRSize)
+	require.NoError(t, err)
+	assert.Equal(t, want, code.Image, "the code contains the full short URL")
+
+	again, err := s.GetQRCode(ctx, "lelele", models.QROptions{Format: qr.FormatSVG})
+	require.NoError(t, err)
+	assert.Equal(t, code.ETag, again.ETag, "the same image gets the same ETag")
+
+	_, err = s.GetQRCode(ctx, "deleted", models.QROptions{})
+	assert.ErrorIs(t, err, ErrShortURLNotFound)
+	_, err = s.GetQRCode(ctx, "missing", models.QROptions{})
+	assert.ErrorIs(t, err, ErrShortURLNotFound)
+	_, err = s.GetQRCode(ctx, "disabled", models.QROptions{})
+	assert.ErrorIs(t, err, ErrShortURLDisabled, "the disabled short URL gets no code")
+	_, err = s.GetQRCode(ctx, "banned", models.QROptions{})
+	assert.ErrorIs(t, err, ErrShortURLDisabled, "the short URL of the banned user gets no code")
+	_, err = s.GetQRCode(ctx, "lelele", models.QROptions{Format: "gif"})
+	assert.ErrorIs(t, err, ErrInvalidQROptions)
+}
+
+func withDomains(t *testing.T, domains ...config.Domain) {
+	previous := config.Settings.Domains
+	config.Settings.Domains = domains
+	t.Cleanup(func() { config.Settings.Domains = previous })
+}
+
+func Test_checkDomain(t *testing.T) {
+	withDomains(t, config.Domain{Host: "go.example.com"}, config.Domain{Host: "brand.example.com", Users: []string{"alice"}})
+	tests := []struct {
+		wantErr error
+		name    string
+		domain  string
+		userID  string
+		want    string
+	}{
+		{name: "default domain", domain: "", userID: "bob", want: ""},
+		{name: "default domain by host", domain: "localhost:8080", userID: "bob", want: ""},
+		{name: "open domain", domain: " Go.Example.com ", userID: "bob", want: "go.example.com"},
+		{name: "allowed domain", domain: "brand.example.com", userID: "alice", want: "brand.example.com"},
+		{name: "not allowed domain", domain: "brand.example.com", userID: "bob", wantErr: ErrDomainNotAllowed},
+		{name: "unknown domain", domain: "evil.example.com", userID: "alice", wantErr: ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkDomain(tt.domain, tt.userID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestShortURLService_CreateOnDomain(t *testing.T) {
+	withDomains(t, config.Domain{Host: "go.example.com"}, config.Domain{Host: "brand.example.com", Users: []string{"alice"}})
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repoMock := mocks.NewMockRepository(ctrl)
+	allowAudit(repoMock)
+	repoMock.EXPECT().ReadUserBan(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound).AnyTimes()
+	s := ShortURLService{repo: repoMock}
+
+	repoMock.EXPECT().Read(ctx, gomock.Any()).Return("", false)
+	repoMock.EXPECT().Create(ctx, gomock.Any(), "https://ya.ru", "bob", gomock.Any()).DoAndReturn(
+		func(_ context.Context, id string, _ string, _ string, options models.ShortURLOptions) (string, error) {
+			assert.True(t, strings.HasPrefix(id, "go.example.com/"), "the ID is namespaced by the domain")
+			assert.Equal(t, "go.example.com", options.Domain)
+			return id, nil
+		})
+	got, err := s.Create(ctx, "https://ya.ru", "bob", models.ShortURLOptions{Domain: "go.example.com"})
+	require.NoError(t, err)
+	assert.Regexp(t, `^http://go\.example\.com/[a-zA-Z]{8}$`, got)
+
+	_, err = s.Create(ctx, "https://ya.ru", "bob", models.ShortURLOptions{Domain: "brand.example.com"})
+	assert.ErrorIs(t, err, ErrDomainNotAllowed)
+	_, err = s.BatchCreate(ctx, []models.ShortenBatchItemRequest{
+		{OriginalURL: "https://ya.ru", ShortURLOptions: models.ShortURLOptions{Domain: "evil.example.com"}},
+	}, "alice")
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+
+	repoMock.EXPECT().ReadByUserID(ctx, "bob", models.ShortURLFilter{}).Return([]models.ShortURLsByUserResponse{
+		{ShortURL: "lelelele", OriginalURL: "https://ya.ru"},
+		{ShortURL: "go.example.com/lelelele", OriginalURL: "https://ya.ru"},
+	}, nil)
+	urls, err := s.ReadByUserID(ctx, "bob", models.ShortURLFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/lelelele", urls[0].ShortURL)
+	assert.Equal(t, "", urls[0].Domain)
+	assert.Equal(t, "http://go.example.com/lelelele", urls[1].ShortURL)
+	assert.Equal(t, "go.example.com", urls[1].Domain)
+}
+
+func TestShortURLService_APIKeys(t *testing.T) {
+	s := ShortURLService{repo: storage.MemoryRepo{}}
+	ctx := context.Background()
+
+	_, err := s.CreateAPIKey(ctx, "APIKeysOwner", models.CreateAPIKeyRequest{Name: "  "})
+	assert.ErrorIs(t, err, ErrInvalidAPIKey, "the name is required")
+	past := time.Now().Add(-time.Minute)
+	_, err = s.CreateAPIKey(ctx, "APIKeysOwner", models.CreateAPIKeyRequest{Name: "CI", ExpiresAt: &past})
+	assert.ErrorIs(t, err, ErrInvalidAPIKey, "the key can't be expired already")
+
+	created, err := s.CreateAPIKey(ctx, "APIKeysOwner", models.CreateAPIKeyRequest{Name: " CI "})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+	assert.Equal(t, "CI", created.Name)
+	assert.NotContains(t, created.KeyHash, created.Key, "the key is stored hashed")
+
+	userID, err := s.ResolveAPIKey(ctx, created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, "APIKeysOwner", userID)
+	keys, err := s.ReadAPIKeysByUserID(ctx, "APIKeysOwner")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.NotNil(t, keys[0].LastUsedAt, "the use of the key is saved")
+
+	_, err = s.ResolveAPIKey(ctx, created.Key+"x")
+	assert.ErrorIs(t, err, ErrAPIKeyRejected)
+	_, err = s.ResolveAPIKey(ctx, "not-an-api-key")
+	assert.ErrorIs(t, err, ErrAPIKeyRejected)
+
+	assert.ErrorIs(t, s.DeleteAPIKey(ctx, created.ID, "AnotherUserID"), ErrAPIKeyNotFound)
+	require.NoError(t, s.DeleteAPIKey(ctx, created.ID, "APIKeysOwner"))
+	_, err = s.ResolveAPIKey(ctx, created.Key)
+	assert.ErrorIs(t, err, ErrAPIKeyRejected, "the revoked key is rejected")
+}
+
+func TestShortURLService_ResolveAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repoMock := mocks.NewMockRepository(ctrl)
+	s := ShortURLService{repo: repoMock}
+	ctx := context.Background()
+	plainKey := APIKeyPrefix + "secret"
+
+	expired := time.Now().Add(-time.Second)
+	repoMock.EXPECT().ReadAPIKeyByHash(ctx, hashAPIKey(plainKey)).
+		Return(&models.APIKey{ID: "SomeKeyID", UserID: "SomeUserID", ExpiresAt: &expired}, nil)
+	_, err := s.ResolveAPIKey(ctx, plainKey)
+	assert.ErrorIs(t, err, ErrAPIKeyRejected, "the expired key is rejected")
+
+	usedRecently := time.Now().Add(-time.Second)
+	repoMock.EXPECT().ReadAPIKeyByHash(ctx, hashAPIKey(plainKey)).
+		Return(&models.APIKey{ID: "SomeKeyID", UserID: "SomeUserID", LastUsedAt: &usedRecently}, nil)
+	userID, err := s.ResolveAPIKey(ctx, plainKey)
+	require.NoError(t, err)
+	assert.Equal(t, "SomeUserID", userID, "the recent use isn't saved again")
+
+	repoMock.EXPECT().ReadAPIKeyByHash(ctx, hashAPIKey(plainKey)).
+		Return(&models.APIKey{ID: "SomeKeyID", UserID: "SomeUserID"}, nil)
+	repoMock.EXPECT().TouchAPIKey(ctx, "SomeKeyID", gomock.Any()).Return(errors.New("database is down"))
+	userID, err = s.ResolveAPIKey(ctx, plainKey)
+	require.NoError(t, err, "the failure to save the use doesn't reject the key")
+	assert.Equal(t, "SomeUserID", userID)
+}
+
+func TestShortURLService_RevokeTokens(t *testing.T) {
+	s := ShortURLService{repo: storage.MemoryRepo{}}
+	ctx := context.Background()
+	issuedAt := time.Now().UTC().Add(-time.Hour)
+
+	require.NoError(t, s.RevokeToken(ctx, "LoggedOutToken", "RevokingUser"))
+	revoked, err := s.IsTokenRevoked(ctx, "LoggedOutToken", "RevokingUser", issuedAt)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = s.IsTokenRevoked(ctx, "OtherToken", "RevokingUser", issuedAt)
+	require.NoError(t, err)
+	assert.False(t, revoked, "the other sessions are kept")
+
+	require.NoError(t, s.RevokeAllTokens(ctx, "RevokingUser"))
+	revoked, err = s.IsTokenRevoked(ctx, "OtherToken", "RevokingUser", issuedAt)
+	require.NoError(t, err)
+	assert.True(t, revoked, "all the sessions are logged out")
+	revoked, err = s.IsTokenRevoked(ctx, "NextToken", "RevokingUser", time.Now().UTC().Add(time.Second))
+	require.NoError(t, err)
+	assert.False(t, revoked, "the user can log in again")
+
+	require.NoError(t, s.FillTokenRevocation(ctx, models.TokenRevocation{TokenID: "FilledToken", UserID: "Other"}))
+	revoked, err = s.IsTokenRevoked(ctx, "FilledToken", "Other", time.Now())
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	require.NoError(t, s.FillTokenRevocation(ctx, models.TokenRevocation{TokenID: "ExpiredToken", UserID: "Other",
+		ExpiresAt: time.Now().Add(-time.Minute)}))
+	revoked, err = s.IsTokenRevoked(ctx, "ExpiredToken", "Other", time.Now())
+	require.NoError(t, err)
+	assert.False(t, revoked, "the token expired already isn't kept in the denylist")
+}
+
+func TestShortURLService_LoginOIDC(t *testing.T) {
+	s := ShortURLService{repo: storage.MemoryRepo{}}
+	ctx := context.Background()
+	login := models.OIDCLogin{Issuer: "https://idp.example", Subject: "employee-1", Email: "Employee@Corp.Example",
+		EmailVerified: true, ClaimLinks: true}
+
+	_, err := s.Create(ctx, "https://ya.ru/before-sso", "AnonymousBeforeSSO", models.ShortURLOptions{})
+	require.NoError(t, err)
+	account, err := s.LoginOIDC(ctx, login, "AnonymousBeforeSSO")
+	require.NoError(t, err)
+	assert.Equal(t, "AnonymousBeforeSSO", account.ID, "the links of the anonymous user are kept")
+	assert.Equal(t, "employee@corp.example", account.Email)
+	assert.NotNil(t, account.VerifiedAt)
+
+	again, err := s.LoginOIDC(ctx, login, "OtherAnonymous")
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, again.ID, "the subject is mapped to the same user")
+
+	_, err = s.Login(ctx, models.Credentials{Email: "employee@corp.example", Password: ""}, "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials, "the account has no password")
+
+	migrated := login
+	migrated.Issuer = "https://new-idp.example"
+	linked, err := s.LoginOIDC(ctx, migrated, "")
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, linked.ID, "the verified email links the identity of the other provider")
+
+	squatter, err := s.Register(ctx, models.Credentials{Email: "jane@corp.example", Password: "long-enough-password"},
+		"SquatterUserID", "127.0.0.1")
+	require.NoError(t, err)
+	_, err = s.Create(ctx, "https://ya.ru/squatted", squatter.ID, models.ShortURLOptions{})
+	require.NoError(t, err)
+	_, err = s.LoginOIDC(ctx, models.OIDCLogin{Issuer: "https://idp.example", Subject: "employee-2",
+		Email: "jane@corp.example"}, "")
+	assert.ErrorIs(t, err, ErrAccountExists, "the email the provider hasn't verified doesn't free the account")
+	jane, err := s.LoginOIDC(ctx, models.OIDCLogin{Issuer: "https://idp.example", Subject: "employee-2",
+		Email: "jane@corp.example", EmailVerified: true}, "")
+	require.NoError(t, err, "the unverified account doesn't block the owner of the email")
+	assert.NotEqual(t, squatter.ID, jane.ID, "the unverified account isn't taken over")
+	assert.Equal(t, "jane@corp.example", jane.Email)
+	_, err = s.Login(ctx, models.Credentials{Email: "jane@corp.example", Password: "long-enough-password"}, "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials, "the password of the unverified account is gone")
+	_, err = s.repo.ReadAccount(ctx, squatter.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound, "the unverified account is the anonymous user again")
+	urls, err := s.repo.ReadByUserID(ctx, jane.ID, models.ShortURLFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, urls, "the short URLs of the unverified account aren't given to the owner of the email")
+
+	_, err = s.LoginOIDC(ctx, models.OIDCLogin{Issuer: "https://idp.example", Subject: "employee-3"}, "")
+	assert.ErrorIs(t, err, ErrInvalidAccount, "the email is required")
+
+	unclaimed, err := s.LoginOIDC(ctx, models.OIDCLogin{Issuer: "https://idp.example", Subject: "employee-4",
+		Email: "john@corp.example", EmailVerified: true}, "AnonymousKeepingLinks")
+	require.NoError(t, err)
+	assert.NotEqual(t, "AnonymousKeepingLinks", unclaimed.ID, "the links are claimed on request only")
+
+	_, err = s.LoginOIDC(ctx, models.OIDCLogin{Issuer: "https://idp.example", Subject: "employee-5",
+		Email: "bob@corp.example", EmailVerified: true, ClaimLinks: true}, account.ID)
+	assert.ErrorIs(t, err, ErrInvalidAccount, "the registered user can't be claimed")
+
+	events, err := s.ReadAuditEvents(ctx, models.AuditFilter{Action: AuditAccountClaimLinks, Target: account.ID})
+	require.NoError(t, err)
+	assert.Len(t, events, 1, "the claim is audited")
+	events, err = s.ReadAuditEvents(ctx, models.AuditFilter{Action: AuditAccountRegister, Target: unclaimed.ID})
+	require.NoError(t, err)
+	assert.Len(t, events, 1, "the account created by the identity provider is audited")
+	events, err = s.ReadAuditEvents(ctx, models.AuditFilter{Action: AuditOIDCIdentityLink, UserID: account.ID})
+	require.NoError(t, err)
+	require.Len(t, events, 2, "both identities linked to the account are audited")
+	assert.Equal(t, "https://idp.example#employee-1", events[0].Target)
+	assert.Equal(t, "https://new-idp.example#employee-1", events[1].Target)
+}
+
+func TestShortURLService_Moderation(t *testing.T) {
+	s := ShortURLService{repo: storage.MemoryRepo{}}
+	ctx := context.Background()
+	created, err := s.Create(ctx, "https://moderation.example/login", "ModerationAuthor", models.ShortURLOptions{})
+	require.NoError(t, err)
+	id := created[strings.LastIndex(created, "/")+1:]
+
+	_, err = s.DisableShortURL(ctx, id, "  ")
+	assert.ErrorIs(t, err, ErrInvalidModeration, "the reason is required")
+	_, err = s.DisableShortURL(ctx, "nonExistent", "Phishing")
+	assert.ErrorIs(t, err, ErrShortURLNotFound)
+	disabled, err := s.DisableShortURL(ctx, id, " Phishing ")
+	require.NoError(t, err)
+	require.NotNil(t, disabled.Moderation)
+	assert.Equal(t, "Phishing", disabled.Moderation.Reason)
+	assert.Equal(t, created, disabled.ShortURL)
+	_, err = s.Resolve(ctx, id)
+	assert.ErrorIs(t, err, ErrShortURLDisabled)
+
+	enabled, err := s.EnableShortURL(ctx, id)
+	require.NoError(t, err)
+	assert.Nil(t, enabled.Moderation)
+	_, err = s.Resolve(ctx, id)
+	require.NoError(t, err)
+
+	_, err = s.BanUser(ctx, "ModerationAuthor", "")
+	assert.ErrorIs(t, err, ErrInvalidModeration, "the reason is required")
+	ban, err := s.BanUser(ctx, "ModerationAuthor", "Spam")
+	require.NoError(t, err)
+	assert.Equal(t, "Spam", ban.Reason)
+	_, err = s.Resolve(ctx, id)
+	assert.ErrorIs(t, err, ErrShortURLDisabled, "the short URLs of the banned user don't redirect")
+	_, err = s.Create(ctx, "https://moderation.example/other", "ModerationAuthor", models.ShortURLOptions{})
+	assert.ErrorIs(t, err, ErrUserBanned)
+	found, err := s.SearchShortURLs(ctx, models.ShortURLSearch{Domain: "Moderation.Example."})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.True(t, found[0].OwnerBanned)
+
+	require.NoError(t, s.UnbanUser(ctx, "ModerationAuthor"))
+	assert.ErrorIs(t, s.UnbanUser(ctx, "ModerationAuthor"), ErrUserNotBanned)
+	_, err = s.Resolve(ctx, id)
+	require.NoError(t, err)
+}
+
+func Test_normalizeSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		search  models.ShortURLSearch
+		want    models.ShortURLSearch
+		wantErr bool
+	}{
+		{name: "no filters", search: models.ShortURLSearch{OriginalURL: " "}, wantErr: true},
+		{name: "invalid domain", search: models.ShortURLSearch{Domain: "example..com"}, wantErr: true},
+		{name: "negative limit", search: models.ShortURLSearch{UserID: "SomeUserID", Limit: -1}, wantErr: true},
+		{
+			name:   "default limit",
+			search: models.ShortURLSearch{Domain: " Example.COM. "},
+			want:   models.ShortURLSearch{Domain: "example.com", Limit: defaultSearchLimit},
+		},
+		{
+			name:   "capped limit",
+			search: models.ShortURLSearch{UserID: "SomeUserID", Limit: maxSearchLimit + 1},
+			want:   models.ShortURLSearch{UserID: "SomeUserID", Limit: maxSearchLimit},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeSearch(tt.search)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidModeration)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestShortURLService_AuditLog(t *testing.T) {
+	s := ShortURLService{repo: storage.MemoryRepo{}}
+	actor := models.Actor{UserID: "AuditActor", IP: "192.0.2.10", ForwardedFor: "203.0.113.7",
+		Transport: models.TransportGRPC}
+	ctx := WithActor(context.Background(), actor)
+	created, err := s.Create(ctx, "https://audit.example", "AuditActor", models.ShortURLOptions{})
+	require.NoError(t, err)
+	id := created[strings.LastIndex(created, "/")+1:]
+	title := "Audited"
+	_, err = s.Update(ctx, id, "AuditActor", models.UpdateShortURLRequest{Title: &title})
+	require.NoError(t, err)
+	_, err = s.DisableShortURL(WithActor(context.Background(), models.Actor{IP: "192.0.2.20"}), id, "Phishing")
+	require.NoError(t, err)
+
+	events, err := s.ReadAuditEvents(ctx, models.AuditFilter{Target: id})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, AuditURLCreate, events[0].Action)
+	assert.Equal(t, "AuditActor", events[0].UserID)
+	assert.Equal(t, "192.0.2.10", events[0].IP)
+	assert.Equal(t, "203.0.113.7", events[0].ForwardedFor)
+	assert.Equal(t, models.TransportGRPC, events[0].Transport)
+	assert.Equal(t, AuditURLUpdate, events[1].Action)
+	assert.Equal(t, AuditURLDisable, events[2].Action)
+	assert.Empty(t, events[2].UserID, "the admin is recorded without the user")
+	assert.Equal(t, "192.0.2.20", events[2].IP)
+	assert.Equal(t, events[1].Hash, events[2].PrevHash, "the events written one after another are chained")
+
+	require.NoError(t, s.RevokeToken(ctx, "AuditTokenID", "AuditActor"))
+	require.NoError(t, s.RevokeAllTokens(ctx, "AuditActor"))
+	events, err = s.ReadAuditEvents(ctx, models.AuditFilter{UserID: "AuditActor", Action: AuditTokenRevoke})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "AuditTokenID", events[0].Target)
+	events, err = s.ReadAuditEvents(ctx, models.AuditFilter{UserID: "AuditActor", Action: AuditTokenRevokeAll})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "AuditActor", events[0].Target)
+
+	verification, err := s.VerifyAuditLog(ctx)
+	require.NoError(t, err)
+	assert.True(t, verification.Valid)
+	assert.GreaterOrEqual(t, verification.Events, int64(3))
+
+	_, err = s.ReadAuditEvents(ctx, models.AuditFilter{Limit: -1})
+	assert.ErrorIs(t, err, ErrInvalidAuditFilter)
+	now := time.Now()
+	_, err = s.ReadAuditEvents(ctx, models.AuditFilter{From: now, To: now.Add(-time.Hour)})
+	assert.ErrorIs(t, err, ErrInvalidAuditFilter)
+}
+
+func TestShortURLService_VerifyAuditLog(t *testing.T) {
+	createdAt := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	first := models.AuditEvent{Seq: 1, CreatedAt: createdAt, Action: AuditURLCreate, Target: "lelelele"}
+	first.Hash = auditHash(first)
+	second := models.AuditEvent{Seq: 2, CreatedAt: createdAt, Action: AuditURLUpdate, Target: "lelelele",
+		PrevHash: first.Hash}
+	second.Hash = auditHash(second)
+	third := models.AuditEvent{Seq: 3, CreatedAt: createdAt, Action: AuditURLDelete, Target: "lelelele",
+		PrevHash: second.Hash}
+	third.Hash = auditHash(third)
+	tampered := second
+	tampered.UserID = "AnotherUserID"
+	tests := []struct {
+		name   string
+		events []models.AuditEvent
+		want   models.AuditVerification
+	}{
+		{name: "intact", events: []models.AuditEvent{first, second, third}, want: models.AuditVerification{Events: 3, Valid: true}},
+		{name: "changed", events: []models.AuditEvent{first, tampered, third}, want: models.AuditVerification{Events: 2, BrokenAt: 2}},
+		{name: "removed", events: []models.AuditEvent{first, third}, want: models.AuditVerification{Events: 2, BrokenAt: 2}},
+		{name: "empty", events: []models.AuditEvent{}, want: models.AuditVerification{Valid: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repoMock := mocks.NewMockRepository(ctrl)
+			repoMock.EXPECT().ReadAuditEvents(gomock.Any(), models.AuditFilter{Limit: maxSearchLimit}).Return(tt.events, nil)
+			s := ShortURLService{repo: repoMock}
+			got, err := s.VerifyAuditLog(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, &tt.want, got)
+		})
+	}
+}
@@ -3,9 +3,11 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -24,8 +26,8 @@ func NewDBRepo(pool *sql.DB) *DBRepo {
 	return &DBRepo{pool}
 }
 
-// Create stores the single URL in the database.
-func (D DBRepo) Create(ctx context.Context, id string, originalURL string, userID string) (string, error) {
+// Create stores the single URL in the database along with its optional attributes.
+func (D DBRepo) Create(ctx context.Context, id string, originalURL string, userID string, options models.ShortURLOptions) (string, error) {
 	transaction, err := D.pool.Begin()
 	if err != nil {
 		return "", err
@@ -43,17 +45,26 @@ func (D DBRepo) Create(ctx context.Context, id string, originalURL string, userI
 		}
 	}
 
-	createShortURLPreparedStmt, err := transaction.PrepareContext(
-		ctx, "INSERT INTO short_url (short_url, original_url, user_id) VALUES ($1, $2, $3)")
+	redirectOptions, err := json.Marshal(options.RedirectOptions)
 	if err != nil {
 		return "", err
 	}
-	_, createErr := createShortURLPreparedStmt.ExecContext(ctx, id, originalURL, userID)
+	createShortURLPreparedStmt, err := transaction.PrepareContext(ctx, `
+		INSERT INTO short_url (short_url, original_url, user_id, title, notes, redirect_options, password_hash,
+		                       utm_template_id, max_clicks, clicks_left, domain, campaign_id, org_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, '')::uuid, $9, $9, $10, NULLIF($11, '')::uuid,
+		        NULLIF($12, '')::uuid)`)
+	if err != nil {
+		return "", err
+	}
+	_, createErr := createShortURLPreparedStmt.ExecContext(
+		ctx, id, originalURL, userID, options.Title, options.Notes, redirectOptions, options.PasswordHash,
+		options.UTMTemplateID, options.MaxClicks, options.Domain, options.CampaignID, options.OrgID)
 	if createErr != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(createErr, &pgErr) && pgerrcode.IsIntegrityConstraintViolation(pgErr.Code) {
 			logger.Log.Infof("OriginalURL %s already exists", originalURL)
-			existingID, innerErr := D.GetShortURLByOriginalURL(ctx, originalURL)
+			existingID, innerErr := D.GetShortURLByOriginalURL(ctx, originalURL, options.Domain)
 			if innerErr != nil {
 				txErr := transaction.Rollback()
 				if txErr != nil {
@@ -74,6 +85,13 @@ func (D DBRepo) Create(ctx context.Context, id string, originalURL string, userI
 		}
 		return "", err
 	}
+	if tagsErr := D.linkTags(ctx, transaction, id, options.Tags); tagsErr != nil {
+		txErr := transaction.Rollback()
+		if txErr != nil {
+			return "", txErr
+		}
+		return "", tagsErr
+	}
 	txErr := transaction.Commit()
 	if txErr != nil {
 		return "", txErr
@@ -81,6 +99,42 @@ func (D DBRepo) Create(ctx context.Context, id string, originalURL string, userI
 	return id, nil
 }
 
+// linkTags creates the missing tags and connects them with the short URL within the transaction.
+func (D DBRepo) linkTags(ctx context.Context, transaction *sql.Tx, id string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	createTagPreparedStmt, err := transaction.PrepareContext(
+		ctx, "INSERT INTO tags (name) VALUES ($1) ON CONFLICT (name) DO NOTHING")
+	if err != nil {
+		return err
+	}
+	linkTagPreparedStmt, err := transaction.PrepareContext(ctx, `
+		INSERT INTO short_url_tags (short_url_id, tag_id)
+		SELECT s.id, t.id FROM short_url s, tags t WHERE s.short_url = $1 AND t.name = $2
+		ON CONFLICT DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err = createTagPreparedStmt.ExecContext(ctx, tag); err != nil {
+			return err
+		}
+		if _, err = linkTagPreparedStmt.ExecContext(ctx, id, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitTags converts the aggregated comma-separated list of tags from the database to the slice.
+func splitTags(aggregated string) []string {
+	if aggregated == "" {
+		return nil
+	}
+	return strings.Split(aggregated, ",")
+}
+
 // Read reads the single original URL from the database by its short ID.
 func (D DBRepo) Read(ctx context.Context, id string) (string, bool) {
 	readOriginalURLPreparedStmt, err := D.pool.PrepareContext(ctx, "SELECT original_url, active FROM short_url WHERE short_url = $1")
@@ -98,13 +152,14 @@ func (D DBRepo) Read(ctx context.Context, id string) (string, bool) {
 
 }
 
-// GetShortURLByOriginalURL takes the short URL from the database by the provided original URL.
-func (D DBRepo) GetShortURLByOriginalURL(ctx context.Context, originalURL string) (string, error) {
-	readOriginalURLPreparedStmt, err := D.pool.PrepareContext(ctx, "SELECT short_url FROM short_url WHERE original_url = $1")
+// GetShortURLByOriginalURL takes the short URL on the domain from the database by the provided original URL.
+func (D DBRepo) GetShortURLByOriginalURL(ctx context.Context, originalURL string, domain string) (string, error) {
+	readOriginalURLPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "SELECT short_url FROM short_url WHERE original_url = $1 AND domain = $2")
 	if err != nil {
 		return "", err
 	}
-	result := readOriginalURLPreparedStmt.QueryRowContext(ctx, originalURL)
+	result := readOriginalURLPreparedStmt.QueryRowContext(ctx, originalURL, domain)
 	var shortURL string
 	err = result.Scan(&shortURL)
 	if err != nil {
@@ -152,15 +207,27 @@ func (D DBRepo) BatchCreate(ctx context.Context, URLs map[string]models.ShortenB
 		}
 	}
 
-	createShortURLPreparedStmt, err := transaction.PrepareContext(
-		ctx, "INSERT INTO short_url (short_url, original_url, correlation_id, user_id) VALUES ($1, $2, $3, $4)")
+	createShortURLPreparedStmt, err := transaction.PrepareContext(ctx, `
+		INSERT INTO short_url (short_url, original_url, correlation_id, user_id, title, notes, redirect_options,
+		                       password_hash, utm_template_id, max_clicks, clicks_left, domain, campaign_id, org_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, '')::uuid, $10, $10, $11, NULLIF($12, '')::uuid,
+		        NULLIF($13, '')::uuid)`)
 	if err != nil {
 		return nil, err
 	}
 	results := make([]models.ShortenBatchItemResponse, len(URLs))
 	cnt := 0
 	for shortURL, data := range URLs {
-		_, err = createShortURLPreparedStmt.ExecContext(ctx, shortURL, data.OriginalURL, data.CorrelationID, userID)
+		var redirectOptions []byte
+		redirectOptions, err = json.Marshal(data.RedirectOptions)
+		if err == nil {
+			_, err = createShortURLPreparedStmt.ExecContext(
+				ctx, shortURL, data.OriginalURL, data.CorrelationID, userID, data.Title, data.Notes, redirectOptions,
+				data.PasswordHash, data.UTMTemplateID, data.MaxClicks, data.Domain, data.CampaignID, data.OrgID)
+		}
+		if err == nil {
+			err = D.linkTags(ctx, transaction, shortURL, data.Tags)
+		}
 		if err != nil {
 			txErr := transaction.Rollback()
 			if txErr != nil {
@@ -178,14 +245,34 @@ func (D DBRepo) BatchCreate(ctx context.Context, URLs map[string]models.ShortenB
 	return results, nil
 }
 
-// ReadByUserID reads all the user-owned URLs from the database.
-func (D DBRepo) ReadByUserID(ctx context.Context, userID string) ([]models.ShortURLsByUserResponse, error) {
-	readURLsByUserIDPreparedStmt, err := D.pool.PrepareContext(
-		ctx, "SELECT short_url, original_url FROM short_url WHERE user_id = $1")
+// ReadByUserID reads all the user-owned URLs matching the filter from the database.
+func (D DBRepo) ReadByUserID(ctx context.Context, userID string, filter models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
+	return D.readShortURLs(ctx, "s.user_id = $1", userID, filter)
+}
+
+// ReadByOrgID reads all the URLs of the organization matching the filter from the database.
+func (D DBRepo) ReadByOrgID(ctx context.Context, orgID string, filter models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
+	return D.readShortURLs(ctx, "s.org_id::text = $1", orgID, filter)
+}
+
+func (D DBRepo) readShortURLs(
+	ctx context.Context, condition string, value string, filter models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
+	readURLsPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT s.short_url, s.original_url, s.title, s.notes, COALESCE(string_agg(t.name, ',' ORDER BY t.name), ''),
+		       s.page_metadata, s.redirect_options, s.password_hash, COALESCE(s.utm_template_id::text, ''),
+		       s.max_clicks, s.clicks_left, COALESCE(s.campaign_id::text, ''), COALESCE(s.org_id::text, '')
+		FROM short_url s
+		LEFT JOIN short_url_tags st ON st.short_url_id = s.id
+		LEFT JOIN tags t ON t.id = st.tag_id
+		WHERE `+condition+` AND ($2::text = '' OR EXISTS (
+			SELECT 1 FROM short_url_tags ft JOIN tags ftn ON ftn.id = ft.tag_id
+			WHERE ft.short_url_id = s.id AND ftn.name = $2::text))
+		  AND ($3::text = '' OR s.campaign_id::text = $3::text)
+		GROUP BY s.id`)
 	if err != nil {
 		return nil, err
 	}
-	rows, err := readURLsByUserIDPreparedStmt.QueryContext(ctx, userID)
+	rows, err := readURLsPreparedStmt.QueryContext(ctx, value, filter.Tag, filter.CampaignID)
 	if err != nil {
 		return nil, err
 	}
@@ -195,34 +282,205 @@ func (D DBRepo) ReadByUserID(ctx context.Context, userID string) ([]models.Short
 	results := make([]models.ShortURLsByUserResponse, 0)
 	for rows.Next() {
 		URL := models.ShortURLsByUserResponse{}
-		scanErr := rows.Scan(&URL.ShortURL, &URL.OriginalURL)
+		var tags string
+		var metadata, redirectOptions []byte
+		var clicksLeft int64
+		scanErr := rows.Scan(
+			&URL.ShortURL, &URL.OriginalURL, &URL.Title, &URL.Notes, &tags, &metadata, &redirectOptions, &URL.PasswordHash,
+			&URL.UTMTemplateID, &URL.MaxClicks, &clicksLeft, &URL.CampaignID, &URL.OrgID)
 		if scanErr != nil {
 			logger.Log.Error(scanErr.Error())
 			return nil, scanErr
 		}
+		URL.Tags = splitTags(tags)
+		if URL.ClickLimited() {
+			URL.ClicksLeft = &clicksLeft
+		}
+		if URL.Metadata, scanErr = unmarshalMetadata(metadata); scanErr != nil {
+			return nil, scanErr
+		}
+		if scanErr = json.Unmarshal(redirectOptions, &URL.RedirectOptions); scanErr != nil {
+			return nil, scanErr
+		}
 		results = append(results, URL)
 	}
 	return results, nil
 }
 
-// GetUserIDByShortURL Reads the user ID of the short URL author from the database.
-func (D DBRepo) GetUserIDByShortURL(ctx context.Context, shortURL string) (string, error) {
-	getUserIDByShortURLPreparedStmt, err := D.pool.PrepareContext(
-		ctx, "SELECT user_id FROM short_url WHERE short_url = $1")
+// ReadShortURL reads the whole short URL record from the database. Returns ErrNotFound if there is no such URL.
+func (D DBRepo) ReadShortURL(ctx context.Context, id string) (*models.ShortURL, error) {
+	readShortURLPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT s.short_url, s.original_url, COALESCE(s.user_id::text, ''), s.title, s.notes, s.active,
+		       COALESCE(string_agg(t.name, ',' ORDER BY t.name), ''), s.page_metadata, s.redirect_options, s.password_hash,
+		       COALESCE(u.id::text, ''), COALESCE(u.utm_source, ''), COALESCE(u.utm_medium, ''),
+		       COALESCE(u.utm_campaign, ''), COALESCE(u.utm_term, ''), COALESCE(u.utm_content, ''),
+		       s.max_clicks, s.clicks_left, COALESCE(s.campaign_id::text, ''), COALESCE(s.org_id::text, ''),
+		       s.disabled_at, COALESCE(s.disabled_reason, '')
+		FROM short_url s
+		LEFT JOIN short_url_tags st ON st.short_url_id = s.id
+		LEFT JOIN tags t ON t.id = st.tag_id
+		LEFT JOIN utm_templates u ON u.id = s.utm_template_id
+		WHERE s.short_url = $1
+		GROUP BY s.id, u.id`)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	result := getUserIDByShortURLPreparedStmt.QueryRowContext(ctx, shortURL)
-	var userID string
-	err = result.Scan(&userID)
+	result := readShortURLPreparedStmt.QueryRowContext(ctx, id)
+	shortURL := models.ShortURL{}
+	var active bool
+	var tags string
+	var metadata, redirectOptions []byte
+	var utm models.UTMParameters
+	var disabledAt *time.Time
+	var disabledReason string
+	err = result.Scan(
+		&shortURL.ShortURL, &shortURL.OriginalURL, &shortURL.UserID, &shortURL.Title, &shortURL.Notes, &active, &tags,
+		&metadata, &redirectOptions, &shortURL.PasswordHash, &shortURL.UTMTemplateID, &utm.Source, &utm.Medium,
+		&utm.Campaign, &utm.Term, &utm.Content, &shortURL.MaxClicks, &shortURL.ClicksLeft, &shortURL.CampaignID,
+		&shortURL.OrgID, &disabledAt, &disabledReason)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", nil
+			return nil, ErrNotFound
 		}
-		return "", err
+		return nil, err
+	}
+	shortURL.Tags = splitTags(tags)
+	shortURL.Deleted = !active
+	shortURL.Moderation = moderation(disabledAt, disabledReason)
+	if shortURL.UTMTemplateID != "" {
+		shortURL.UTM = &utm
+	}
+	if shortURL.Metadata, err = unmarshalMetadata(metadata); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(redirectOptions, &shortURL.RedirectOptions); err != nil {
+		return nil, err
+	}
+	return &shortURL, nil
+}
+
+// unmarshalMetadata converts the page metadata stored as JSON in the database. Returns nil if it is not fetched yet.
+func unmarshalMetadata(data []byte) (*models.PageMetadata, error) {
+	if data == nil {
+		return nil, nil
+	}
+	metadata := &models.PageMetadata{}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// SetMetadata stores the fetched metadata of the destination page in the database.
+func (D DBRepo) SetMetadata(ctx context.Context, id string, metadata models.PageMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	setMetadataPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "UPDATE short_url SET page_metadata = $2, modified_at = NOW() WHERE short_url = $1")
+	if err != nil {
+		return err
+	}
+	result, err := setMetadataPreparedStmt.ExecContext(ctx, id, data)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
 	}
-	return userID, nil
+	return nil
+}
+
+// Update changes the optional attributes of the short URL in the database.
+func (D DBRepo) Update(ctx context.Context, id string, update models.UpdateShortURLRequest) error {
+	transaction, err := D.pool.Begin()
+	if err != nil {
+		return err
+	}
+	updateErr := D.update(ctx, transaction, id, update)
+	if updateErr != nil {
+		txErr := transaction.Rollback()
+		if txErr != nil {
+			return txErr
+		}
+		return updateErr
+	}
+	return transaction.Commit()
+}
+
+func (D DBRepo) update(ctx context.Context, transaction *sql.Tx, id string, update models.UpdateShortURLRequest) error {
+	updateShortURLPreparedStmt, err := transaction.PrepareContext(ctx, `
+		UPDATE short_url SET title = COALESCE($2::text, title), notes = COALESCE($3::text, notes),
+		       utm_template_id = CASE WHEN $4::text IS NULL THEN utm_template_id ELSE NULLIF($4::text, '')::uuid END,
+		       campaign_id = CASE WHEN $5::text IS NULL THEN campaign_id ELSE NULLIF($5::text, '')::uuid END,
+		       modified_at = NOW()
+		WHERE short_url = $1`)
+	if err != nil {
+		return err
+	}
+	result, err := updateShortURLPreparedStmt.ExecContext(
+		ctx, id, update.Title, update.Notes, update.UTMTemplateID, update.CampaignID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	if update.ChangesRedirect() {
+		if err = D.updateRedirectOptions(ctx, transaction, id, update); err != nil {
+			return err
+		}
+	}
+	if update.Tags == nil {
+		return nil
+	}
+	unlinkTagsPreparedStmt, err := transaction.PrepareContext(ctx, `
+		DELETE FROM short_url_tags WHERE short_url_id IN (SELECT id FROM short_url WHERE short_url = $1)`)
+	if err != nil {
+		return err
+	}
+	if _, err = unlinkTagsPreparedStmt.ExecContext(ctx, id); err != nil {
+		return err
+	}
+	return D.linkTags(ctx, transaction, id, *update.Tags)
+}
 
+// updateRedirectOptions applies the update to the redirect attributes of the short URL, locking the row in the transaction.
+func (D DBRepo) updateRedirectOptions(
+	ctx context.Context, transaction *sql.Tx, id string, update models.UpdateShortURLRequest) error {
+	readRedirectOptionsPreparedStmt, err := transaction.PrepareContext(
+		ctx, "SELECT redirect_options FROM short_url WHERE short_url = $1 FOR UPDATE")
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if err = readRedirectOptionsPreparedStmt.QueryRowContext(ctx, id).Scan(&data); err != nil {
+		return err
+	}
+	var redirectOptions models.RedirectOptions
+	if err = json.Unmarshal(data, &redirectOptions); err != nil {
+		return err
+	}
+	update.ApplyRedirect(&redirectOptions)
+	if data, err = json.Marshal(redirectOptions); err != nil {
+		return err
+	}
+	updateRedirectOptionsPreparedStmt, err := transaction.PrepareContext(
+		ctx, "UPDATE short_url SET redirect_options = $2 WHERE short_url = $1")
+	if err != nil {
+		return err
+	}
+	_, err = updateRedirectOptionsPreparedStmt.ExecContext(ctx, id, data)
+	return err
 }
 
 // SetURLsInactive marks the URL as inactive in the database.
@@ -278,9 +536,1040 @@ func (D DBRepo) GetStats(ctx context.Context) (*models.ServiceStats, error) {
 		return &models.ServiceStats{}, err
 	}
 
+	campaignsCountPreparedStatement, err := D.pool.PrepareContext(
+		ctx, "SELECT count(*) FROM campaigns")
+	if err != nil {
+		return &models.ServiceStats{}, err
+	}
+	result = campaignsCountPreparedStatement.QueryRowContext(ctx)
+	var campaignsCount int
+	err = result.Scan(&campaignsCount)
+	if err != nil {
+		return &models.ServiceStats{}, err
+	}
+
 	response := &models.ServiceStats{
-		Users: usersCount,
-		URLs:  URLsCount,
+		Users:     usersCount,
+		URLs:      URLsCount,
+		Campaigns: campaignsCount,
 	}
 	return response, nil
 }
+
+// CreateUTMTemplate stores the UTM template in the database, creating the owner if it doesn't exist yet.
+func (D DBRepo) CreateUTMTemplate(ctx context.Context, template models.UTMTemplate) error {
+	transaction, err := D.pool.Begin()
+	if err != nil {
+		return err
+	}
+	createErr := D.createUTMTemplate(ctx, transaction, template)
+	if createErr != nil {
+		txErr := transaction.Rollback()
+		if txErr != nil {
+			return txErr
+		}
+		return createErr
+	}
+	return transaction.Commit()
+}
+
+func (D DBRepo) createUTMTemplate(ctx context.Context, transaction *sql.Tx, template models.UTMTemplate) error {
+	createUserPreparedStmt, err := transaction.PrepareContext(
+		ctx, "INSERT INTO users (id) VALUES ($1) ON CONFLICT DO NOTHING")
+	if err != nil {
+		return err
+	}
+	if _, err = createUserPreparedStmt.ExecContext(ctx, template.UserID); err != nil {
+		return err
+	}
+	createTemplatePreparedStmt, err := transaction.PrepareContext(ctx, `
+		INSERT INTO utm_templates (id, user_id, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`)
+	if err != nil {
+		return err
+	}
+	_, err = createTemplatePreparedStmt.ExecContext(ctx, template.ID, template.UserID, template.Name, template.Source,
+		template.Medium, template.Campaign, template.Term, template.Content)
+	return err
+}
+
+// ReadUTMTemplate reads the UTM template from the database by its ID. Returns ErrNotFound if there is no such template.
+func (D DBRepo) ReadUTMTemplate(ctx context.Context, id string) (*models.UTMTemplate, error) {
+	readTemplatePreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT id, user_id, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content
+		FROM utm_templates WHERE id::text = $1`)
+	if err != nil {
+		return nil, err
+	}
+	template := models.UTMTemplate{}
+	err = readTemplatePreparedStmt.QueryRowContext(ctx, id).Scan(&template.ID, &template.UserID, &template.Name,
+		&template.Source, &template.Medium, &template.Campaign, &template.Term, &template.Content)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+// ReadUTMTemplatesByUserID reads all the user-owned UTM templates from the database, sorted by name.
+func (D DBRepo) ReadUTMTemplatesByUserID(ctx context.Context, userID string) ([]models.UTMTemplate, error) {
+	readTemplatesPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT id, user_id, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content
+		FROM utm_templates WHERE user_id = $1 ORDER BY name, id`)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readTemplatesPreparedStmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	var results []models.UTMTemplate
+	for rows.Next() {
+		template := models.UTMTemplate{}
+		scanErr := rows.Scan(&template.ID, &template.UserID, &template.Name, &template.Source, &template.Medium,
+			&template.Campaign, &template.Term, &template.Content)
+		if scanErr != nil {
+			logger.Log.Error(scanErr.Error())
+			return nil, scanErr
+		}
+		results = append(results, template)
+	}
+	return results, nil
+}
+
+// UpdateUTMTemplate replaces the name and the parameters of the UTM template in the database.
+func (D DBRepo) UpdateUTMTemplate(ctx context.Context, template models.UTMTemplate) error {
+	updateTemplatePreparedStmt, err := D.pool.PrepareContext(ctx, `
+		UPDATE utm_templates SET name = $2, utm_source = $3, utm_medium = $4, utm_campaign = $5, utm_term = $6,
+		                         utm_content = $7, modified_at = NOW()
+		WHERE id::text = $1`)
+	if err != nil {
+		return err
+	}
+	result, err := updateTemplatePreparedStmt.ExecContext(ctx, template.ID, template.Name, template.Source,
+		template.Medium, template.Campaign, template.Term, template.Content)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+// DeleteUTMTemplate removes the UTM template from the database, the short URLs are detached by the foreign key.
+func (D DBRepo) DeleteUTMTemplate(ctx context.Context, id string) error {
+	deleteTemplatePreparedStmt, err := D.pool.PrepareContext(ctx, "DELETE FROM utm_templates WHERE id::text = $1")
+	if err != nil {
+		return err
+	}
+	result, err := deleteTemplatePreparedStmt.ExecContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+// AddVariantClicks adds the clicks to the counter of the split variant of the short URL in the database.
+func (D DBRepo) AddVariantClicks(ctx context.Context, id string, variant string, clicks int64) error {
+	addClicksPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		INSERT INTO variant_clicks (short_url_id, variant, clicks)
+		SELECT id, $2, $3 FROM short_url WHERE short_url = $1
+		ON CONFLICT (short_url_id, variant) DO UPDATE SET clicks = variant_clicks.clicks + EXCLUDED.clicks`)
+	if err != nil {
+		return err
+	}
+	result, err := addClicksPreparedStmt.ExecContext(ctx, id, variant, clicks)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+// ReadVariantClicks reads the click counters of all the split variants of the short URL from the database.
+func (D DBRepo) ReadVariantClicks(ctx context.Context, id string) (map[string]int64, error) {
+	readClicksPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT c.variant, c.clicks FROM variant_clicks c JOIN short_url s ON s.id = c.short_url_id
+		WHERE s.short_url = $1`)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readClicksPreparedStmt.QueryContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := make(map[string]int64)
+	for rows.Next() {
+		var variant string
+		var clicks int64
+		if scanErr := rows.Scan(&variant, &clicks); scanErr != nil {
+			return nil, scanErr
+		}
+		results[variant] = clicks
+	}
+	return results, rows.Err()
+}
+
+// UseClick takes one of the clicks left for the click-limited short URL in the database.
+// The single conditional update is atomic, so the concurrent visitors never get more clicks than the limit.
+func (D DBRepo) UseClick(ctx context.Context, id string) (int64, error) {
+	useClickPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		UPDATE short_url SET clicks_left = clicks_left - 1 WHERE short_url = $1 AND clicks_left > 0
+		RETURNING clicks_left`)
+	if err != nil {
+		return 0, err
+	}
+	var clicksLeft int64
+	err = useClickPreparedStmt.QueryRowContext(ctx, id).Scan(&clicksLeft)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNoClicksLeft
+	}
+	return clicksLeft, err
+}
+
+// CreateCampaign stores the campaign in the database, creating the owner if it doesn't exist yet.
+func (D DBRepo) CreateCampaign(ctx context.Context, campaign models.Campaign) error {
+	transaction, err := D.pool.Begin()
+	if err != nil {
+		return err
+	}
+	createErr := D.createCampaign(ctx, transaction, campaign)
+	if createErr != nil {
+		txErr := transaction.Rollback()
+		if txErr != nil {
+			return txErr
+		}
+		return createErr
+	}
+	return transaction.Commit()
+}
+
+func (D DBRepo) createCampaign(ctx context.Context, transaction *sql.Tx, campaign models.Campaign) error {
+	createUserPreparedStmt, err := transaction.PrepareContext(
+		ctx, "INSERT INTO users (id) VALUES ($1) ON CONFLICT DO NOTHING")
+	if err != nil {
+		return err
+	}
+	if _, err = createUserPreparedStmt.ExecContext(ctx, campaign.UserID); err != nil {
+		return err
+	}
+	createCampaignPreparedStmt, err := transaction.PrepareContext(
+		ctx, "INSERT INTO campaigns (id, user_id, name) VALUES ($1, $2, $3)")
+	if err != nil {
+		return err
+	}
+	_, err = createCampaignPreparedStmt.ExecContext(ctx, campaign.ID, campaign.UserID, campaign.Name)
+	return err
+}
+
+// ReadCampaign reads the campaign from the database by its ID. Returns ErrNotFound if there is no such campaign.
+func (D DBRepo) ReadCampaign(ctx context.Context, id string) (*models.Campaign, error) {
+	readCampaignPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "SELECT id, user_id, name FROM campaigns WHERE id::text = $1")
+	if err != nil {
+		return nil, err
+	}
+	campaign := models.Campaign{}
+	err = readCampaignPreparedStmt.QueryRowContext(ctx, id).Scan(&campaign.ID, &campaign.UserID, &campaign.Name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// ReadCampaignsByUserID reads all the user-owned campaigns from the database, sorted by name.
+func (D DBRepo) ReadCampaignsByUserID(ctx context.Context, userID string) ([]models.Campaign, error) {
+	readCampaignsPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "SELECT id, user_id, name FROM campaigns WHERE user_id = $1 ORDER BY name, id")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readCampaignsPreparedStmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	var results []models.Campaign
+	for rows.Next() {
+		campaign := models.Campaign{}
+		if scanErr := rows.Scan(&campaign.ID, &campaign.UserID, &campaign.Name); scanErr != nil {
+			logger.Log.Error(scanErr.Error())
+			return nil, scanErr
+		}
+		results = append(results, campaign)
+	}
+	return results, nil
+}
+
+// UpdateCampaign renames the campaign in the database.
+func (D DBRepo) UpdateCampaign(ctx context.Context, campaign models.Campaign) error {
+	updateCampaignPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "UPDATE campaigns SET name = $2, modified_at = NOW() WHERE id::text = $1")
+	if err != nil {
+		return err
+	}
+	result, err := updateCampaignPreparedStmt.ExecContext(ctx, campaign.ID, campaign.Name)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+// DeleteCampaign removes the campaign from the database, the short URLs are detached by the foreign key.
+func (D DBRepo) DeleteCampaign(ctx context.Context, id string) error {
+	deleteCampaignPreparedStmt, err := D.pool.PrepareContext(ctx, "DELETE FROM campaigns WHERE id::text = $1")
+	if err != nil {
+		return err
+	}
+	result, err := deleteCampaignPreparedStmt.ExecContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+// GetCampaignStats returns the amount of the active short URLs in the campaign and the clicks on their split variants
+// counted in the database.
+func (D DBRepo) GetCampaignStats(ctx context.Context, id string) (*models.CampaignStats, error) {
+	campaign, err := D.ReadCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	readStatsPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT count(*), COALESCE(sum((SELECT COALESCE(sum(c.clicks), 0) FROM variant_clicks c
+		                               WHERE c.short_url_id = s.id)), 0)
+		FROM short_url s WHERE s.campaign_id::text = $1 AND s.active`)
+	if err != nil {
+		return nil, err
+	}
+	stats := &models.CampaignStats{Campaign: *campaign}
+	if err = readStatsPreparedStmt.QueryRowContext(ctx, id).Scan(&stats.URLs, &stats.Clicks); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// CreateAccount registers the user as the account in the database. The row of the anonymous user is upgraded
+// if it exists, the registered users are never overwritten.
+func (D DBRepo) CreateAccount(ctx context.Context, account models.Account) error {
+	createAccountPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		INSERT INTO users (id, email, password_hash, registered_at, verified_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE
+		SET email = EXCLUDED.email, password_hash = EXCLUDED.password_hash, registered_at = EXCLUDED.registered_at,
+		verified_at = EXCLUDED.verified_at
+		WHERE users.email IS NULL`)
+	if err != nil {
+		return err
+	}
+	result, err := createAccountPreparedStmt.ExecContext(
+		ctx, account.ID, account.Email, account.PasswordHash, account.RegisteredAt, account.VerifiedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+	if err = checkAffected(result); errors.Is(err, ErrNotFound) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// ReadAccount reads the account of the user from the database by its ID. Returns ErrNotFound if the user
+// isn't registered.
+func (D DBRepo) ReadAccount(ctx context.Context, id string) (*models.Account, error) {
+	return D.readAccount(ctx, "id::text = $1", id)
+}
+
+// ReadAccountByEmail reads the account from the database by its email. Returns ErrNotFound if there is no such account.
+func (D DBRepo) ReadAccountByEmail(ctx context.Context, email string) (*models.Account, error) {
+	return D.readAccount(ctx, "email = $1", email)
+}
+
+func (D DBRepo) readAccount(ctx context.Context, condition string, value string) (*models.Account, error) {
+	readAccountPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT id, email, password_hash, registered_at, verified_at FROM users
+		WHERE email IS NOT NULL AND `+condition)
+	if err != nil {
+		return nil, err
+	}
+	account := models.Account{}
+	var verifiedAt sql.NullTime
+	err = readAccountPreparedStmt.QueryRowContext(ctx, value).Scan(
+		&account.ID, &account.Email, &account.PasswordHash, &account.RegisteredAt, &verifiedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if verifiedAt.Valid {
+		account.VerifiedAt = &verifiedAt.Time
+	}
+	return &account, nil
+}
+
+// DeleteUnverifiedAccount turns the account with the unconfirmed email back into the anonymous user in the database.
+func (D DBRepo) DeleteUnverifiedAccount(ctx context.Context, id string) error {
+	deleteUnverifiedAccountPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		UPDATE users SET email = NULL, password_hash = NULL, registered_at = NULL
+		WHERE id::text = $1 AND email IS NOT NULL AND verified_at IS NULL`)
+	if err != nil {
+		return err
+	}
+	result, err := deleteUnverifiedAccountPreparedStmt.ExecContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+// CreateAPIKey stores the API key in the database, creating the owner if it doesn't exist yet.
+func (D DBRepo) CreateAPIKey(ctx context.Context, key models.APIKey) error {
+	transaction, err := D.pool.Begin()
+	if err != nil {
+		return err
+	}
+	createErr := D.createAPIKey(ctx, transaction, key)
+	if createErr != nil {
+		txErr := transaction.Rollback()
+		if txErr != nil {
+			return txErr
+		}
+		return createErr
+	}
+	return transaction.Commit()
+}
+
+func (D DBRepo) createAPIKey(ctx context.Context, transaction *sql.Tx, key models.APIKey) error {
+	createUserPreparedStmt, err := transaction.PrepareContext(
+		ctx, "INSERT INTO users (id) VALUES ($1) ON CONFLICT DO NOTHING")
+	if err != nil {
+		return err
+	}
+	if _, err = createUserPreparedStmt.ExecContext(ctx, key.UserID); err != nil {
+		return err
+	}
+	createAPIKeyPreparedStmt, err := transaction.PrepareContext(ctx, `
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`)
+	if err != nil {
+		return err
+	}
+	_, err = createAPIKeyPreparedStmt.ExecContext(
+		ctx, key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, key.CreatedAt, key.ExpiresAt)
+	return err
+}
+
+// auditLogLockKey is the key of the advisory lock taken to append to the audit log.
+const auditLogLockKey int64 = 0x61756469746c6f67
+
+const apiKeyColumns = "id, user_id, name, prefix, key_hash, created_at, last_used_at, expires_at"
+
+// ReadAPIKey reads the API key from the database by its ID. Returns ErrNotFound if there is no such key.
+func (D DBRepo) ReadAPIKey(ctx context.Context, id string) (*models.APIKey, error) {
+	return D.readAPIKey(ctx, "id::text = $1", id)
+}
+
+// ReadAPIKeyByHash reads the API key from the database by the hash of the key. Returns ErrNotFound if there is
+// no such key.
+func (D DBRepo) ReadAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	return D.readAPIKey(ctx, "key_hash = $1", keyHash)
+}
+
+func (D DBRepo) readAPIKey(ctx context.Context, condition string, value string) (*models.APIKey, error) {
+	readAPIKeyPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE "+condition)
+	if err != nil {
+		return nil, err
+	}
+	key, err := scanAPIKey(readAPIKeyPreparedStmt.QueryRowContext(ctx, value))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// ReadAPIKeysByUserID reads all the user-owned API keys from the database, the newest first.
+func (D DBRepo) ReadAPIKeysByUserID(ctx context.Context, userID string) ([]models.APIKey, error) {
+	readAPIKeysPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC, id")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readAPIKeysPreparedStmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	var results []models.APIKey
+	for rows.Next() {
+		key, scanErr := scanAPIKey(rows)
+		if scanErr != nil {
+			logger.Log.Error(scanErr.Error())
+			return nil, scanErr
+		}
+		results = append(results, *key)
+	}
+	return results, nil
+}
+
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (*models.APIKey, error) {
+	key := models.APIKey{}
+	var lastUsedAt, expiresAt sql.NullTime
+	err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &key.CreatedAt, &lastUsedAt, &expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	return &key, nil
+}
+
+// TouchAPIKey saves the moment the API key was used at in the database.
+func (D DBRepo) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	touchAPIKeyPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "UPDATE api_keys SET last_used_at = $2 WHERE id::text = $1")
+	if err != nil {
+		return err
+	}
+	result, err := touchAPIKeyPreparedStmt.ExecContext(ctx, id, usedAt)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+// DeleteAPIKey removes the API key from the database.
+func (D DBRepo) DeleteAPIKey(ctx context.Context, id string) error {
+	deleteAPIKeyPreparedStmt, err := D.pool.PrepareContext(ctx, "DELETE FROM api_keys WHERE id::text = $1")
+	if err != nil {
+		return err
+	}
+	result, err := deleteAPIKeyPreparedStmt.ExecContext(ctx, id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+// RevokeToken adds the token to the denylist in the database, removing the expired tokens in the same statement.
+func (D DBRepo) RevokeToken(ctx context.Context, tokenID string, userID string, expiresAt time.Time) error {
+	revokeTokenPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		WITH expired AS (DELETE FROM revoked_tokens WHERE expires_at < NOW())
+		INSERT INTO revoked_tokens (id, user_id, expires_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	_, err = revokeTokenPreparedStmt.ExecContext(ctx, tokenID, userID, expiresAt)
+	return err
+}
+
+// RevokeUserTokens saves the moment the tokens of the user issued before are revoked in the database,
+// creating the user if it doesn't exist yet. The latest moment wins.
+func (D DBRepo) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	revokeUserTokensPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		INSERT INTO users (id, tokens_revoked_before) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE
+		SET tokens_revoked_before = GREATEST(users.tokens_revoked_before, EXCLUDED.tokens_revoked_before)`)
+	if err != nil {
+		return err
+	}
+	_, err = revokeUserTokensPreparedStmt.ExecContext(ctx, userID, before)
+	return err
+}
+
+// IsTokenRevoked reports whether the token is revoked according to the database.
+func (D DBRepo) IsTokenRevoked(ctx context.Context, tokenID string, userID string, issuedAt time.Time) (bool, error) {
+	isTokenRevokedPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE id = $1)
+		OR EXISTS (SELECT 1 FROM users WHERE id::text = $2 AND tokens_revoked_before > $3)`)
+	if err != nil {
+		return false, err
+	}
+	var revoked bool
+	if err = isTokenRevokedPreparedStmt.QueryRowContext(ctx, tokenID, userID, issuedAt).Scan(&revoked); err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
+
+// CreateOIDCIdentity links the user of the identity provider to the local user in the database.
+func (D DBRepo) CreateOIDCIdentity(ctx context.Context, identity models.OIDCIdentity) error {
+	createOIDCIdentityPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		INSERT INTO oidc_identities (issuer, subject, user_id, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	result, err := createOIDCIdentityPreparedStmt.ExecContext(
+		ctx, identity.Issuer, identity.Subject, identity.UserID, identity.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err = checkAffected(result); errors.Is(err, ErrNotFound) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// ReadOIDCIdentity reads the identity from the database by the issuer and the subject.
+func (D DBRepo) ReadOIDCIdentity(ctx context.Context, issuer string, subject string) (*models.OIDCIdentity, error) {
+	readOIDCIdentityPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT issuer, subject, user_id, created_at FROM oidc_identities WHERE issuer = $1 AND subject = $2`)
+	if err != nil {
+		return nil, err
+	}
+	var identity models.OIDCIdentity
+	err = readOIDCIdentityPreparedStmt.QueryRowContext(ctx, issuer, subject).
+		Scan(&identity.Issuer, &identity.Subject, &identity.UserID, &identity.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateOrganization stores the organization in the database together with its first owner, creating the owner
+// if it doesn't exist yet.
+func (D DBRepo) CreateOrganization(ctx context.Context, org models.Organization, owner models.OrgMember) error {
+	transaction, err := D.pool.Begin()
+	if err != nil {
+		return err
+	}
+	createErr := D.createOrganization(ctx, transaction, org, owner)
+	if createErr != nil {
+		txErr := transaction.Rollback()
+		if txErr != nil {
+			return txErr
+		}
+		return createErr
+	}
+	return transaction.Commit()
+}
+
+func (D DBRepo) createOrganization(
+	ctx context.Context, transaction *sql.Tx, org models.Organization, owner models.OrgMember) error {
+	createUserPreparedStmt, err := transaction.PrepareContext(
+		ctx, "INSERT INTO users (id) VALUES ($1) ON CONFLICT DO NOTHING")
+	if err != nil {
+		return err
+	}
+	if _, err = createUserPreparedStmt.ExecContext(ctx, owner.UserID); err != nil {
+		return err
+	}
+	createOrganizationPreparedStmt, err := transaction.PrepareContext(
+		ctx, "INSERT INTO organizations (id, name, created_at) VALUES ($1, $2, $3)")
+	if err != nil {
+		return err
+	}
+	if _, err = createOrganizationPreparedStmt.ExecContext(ctx, org.ID, org.Name, org.CreatedAt); err != nil {
+		return err
+	}
+	createMemberPreparedStmt, err := transaction.PrepareContext(
+		ctx, "INSERT INTO org_members (org_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)")
+	if err != nil {
+		return err
+	}
+	_, err = createMemberPreparedStmt.ExecContext(ctx, owner.OrgID, owner.UserID, owner.Role, owner.CreatedAt)
+	return err
+}
+
+// ReadOrganization reads the organization from the database by its ID. Returns ErrNotFound if there is
+// no such organization.
+func (D DBRepo) ReadOrganization(ctx context.Context, id string) (*models.Organization, error) {
+	readOrganizationPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "SELECT id, name, created_at FROM organizations WHERE id::text = $1")
+	if err != nil {
+		return nil, err
+	}
+	org := models.Organization{}
+	err = readOrganizationPreparedStmt.QueryRowContext(ctx, id).Scan(&org.ID, &org.Name, &org.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+// ReadOrganizationsByUserID reads all the organizations the user is a member of from the database, sorted by name.
+// The role of the user is set in each of them.
+func (D DBRepo) ReadOrganizationsByUserID(ctx context.Context, userID string) ([]models.Organization, error) {
+	readOrganizationsPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT o.id, o.name, o.created_at, m.role FROM organizations o JOIN org_members m ON m.org_id = o.id
+		WHERE m.user_id::text = $1 ORDER BY o.name, o.id`)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readOrganizationsPreparedStmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	var results []models.Organization
+	for rows.Next() {
+		org := models.Organization{}
+		if scanErr := rows.Scan(&org.ID, &org.Name, &org.CreatedAt, &org.Role); scanErr != nil {
+			logger.Log.Error(scanErr.Error())
+			return nil, scanErr
+		}
+		results = append(results, org)
+	}
+	return results, nil
+}
+
+const orgMemberColumns = "org_id, user_id, role, created_at"
+
+// ReadOrgMember reads the membership of the user in the organization from the database. Returns ErrNotFound
+// if the user isn't a member.
+func (D DBRepo) ReadOrgMember(ctx context.Context, orgID string, userID string) (*models.OrgMember, error) {
+	readOrgMemberPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "SELECT "+orgMemberColumns+" FROM org_members WHERE org_id::text = $1 AND user_id::text = $2")
+	if err != nil {
+		return nil, err
+	}
+	member := models.OrgMember{}
+	err = readOrgMemberPreparedStmt.QueryRowContext(ctx, orgID, userID).
+		Scan(&member.OrgID, &member.UserID, &member.Role, &member.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ReadOrgMembers reads all the members of the organization from the database, in the order they joined.
+func (D DBRepo) ReadOrgMembers(ctx context.Context, orgID string) ([]models.OrgMember, error) {
+	readOrgMembersPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "SELECT "+orgMemberColumns+" FROM org_members WHERE org_id::text = $1 ORDER BY created_at, user_id")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readOrgMembersPreparedStmt.QueryContext(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	var results []models.OrgMember
+	for rows.Next() {
+		member := models.OrgMember{}
+		if scanErr := rows.Scan(&member.OrgID, &member.UserID, &member.Role, &member.CreatedAt); scanErr != nil {
+			logger.Log.Error(scanErr.Error())
+			return nil, scanErr
+		}
+		results = append(results, member)
+	}
+	return results, nil
+}
+
+// SaveOrgMember adds the member to the organization in the database or changes the role of the existing one.
+func (D DBRepo) SaveOrgMember(ctx context.Context, member models.OrgMember) error {
+	saveOrgMemberPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		INSERT INTO org_members (org_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (org_id, user_id) DO UPDATE SET role = EXCLUDED.role`)
+	if err != nil {
+		return err
+	}
+	_, err = saveOrgMemberPreparedStmt.ExecContext(ctx, member.OrgID, member.UserID, member.Role, member.CreatedAt)
+	return err
+}
+
+// DeleteOrgMember removes the member from the organization in the database.
+func (D DBRepo) DeleteOrgMember(ctx context.Context, orgID string, userID string) error {
+	deleteOrgMemberPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "DELETE FROM org_members WHERE org_id::text = $1 AND user_id::text = $2")
+	if err != nil {
+		return err
+	}
+	result, err := deleteOrgMemberPreparedStmt.ExecContext(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+// SearchShortURLs reads the short URLs of all the users matching the search from the database, ordered by
+// their creation. The domain is matched against the host of the destination parsed in the query.
+func (D DBRepo) SearchShortURLs(ctx context.Context, search models.ShortURLSearch) ([]models.AdminShortURL, error) {
+	searchPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT s.short_url, s.original_url, COALESCE(s.user_id::text, ''), COALESCE(s.org_id::text, ''), s.title,
+		       s.active, s.disabled_at, COALESCE(s.disabled_reason, ''), u.banned_at IS NOT NULL
+		FROM short_url s
+		LEFT JOIN users u ON u.id = s.user_id
+		CROSS JOIN LATERAL (
+			SELECT lower(substring(s.original_url FROM '^[^:]+://(?:[^/?#@]*@)?([^/?#:]+)')) AS host) d
+		WHERE ($1::text = '' OR s.original_url = $1::text)
+		  AND ($2::text = '' OR d.host = $2::text OR d.host LIKE '%.' || $2::text)
+		  AND ($3::text = '' OR s.user_id::text = $3::text)
+		ORDER BY s.id
+		LIMIT $4`)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := searchPreparedStmt.QueryContext(ctx, search.OriginalURL, search.Domain, search.UserID, search.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	results := make([]models.AdminShortURL, 0)
+	for rows.Next() {
+		shortURL := models.AdminShortURL{}
+		var active bool
+		var disabledAt *time.Time
+		var disabledReason string
+		scanErr := rows.Scan(&shortURL.ShortURL, &shortURL.OriginalURL, &shortURL.UserID, &shortURL.OrgID,
+			&shortURL.Title, &active, &disabledAt, &disabledReason, &shortURL.OwnerBanned)
+		if scanErr != nil {
+			logger.Log.Error(scanErr.Error())
+			return nil, scanErr
+		}
+		shortURL.Deleted = !active
+		shortURL.Moderation = moderation(disabledAt, disabledReason)
+		results = append(results, shortURL)
+	}
+	return results, nil
+}
+
+// moderation returns the state of the short URL disabled at the moment, nil if it isn't disabled.
+func moderation(disabledAt *time.Time, reason string) *models.Moderation {
+	if disabledAt == nil {
+		return nil
+	}
+	return &models.Moderation{DisabledAt: *disabledAt, Reason: reason}
+}
+
+// SetModeration disables the short URL in the database or enables it back if the moderation is nil.
+func (D DBRepo) SetModeration(ctx context.Context, id string, moderation *models.Moderation) error {
+	setModerationPreparedStmt, err := D.pool.PrepareContext(
+		ctx, "UPDATE short_url SET disabled_at = $2, disabled_reason = $3 WHERE short_url = $1")
+	if err != nil {
+		return err
+	}
+	var disabledAt *time.Time
+	var reason *string
+	if moderation != nil {
+		disabledAt, reason = &moderation.DisabledAt, &moderation.Reason
+	}
+	result, err := setModerationPreparedStmt.ExecContext(ctx, id, disabledAt, reason)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+// BanUser bans the user in the database or replaces the existing ban.
+func (D DBRepo) BanUser(ctx context.Context, ban models.UserBan) error {
+	banUserPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		INSERT INTO users (id, banned_at, ban_reason) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET banned_at = EXCLUDED.banned_at, ban_reason = EXCLUDED.ban_reason`)
+	if err != nil {
+		return err
+	}
+	_, err = banUserPreparedStmt.ExecContext(ctx, ban.UserID, ban.BannedAt, ban.Reason)
+	return err
+}
+
+// UnbanUser lifts the ban of the user in the database.
+func (D DBRepo) UnbanUser(ctx context.Context, userID string) error {
+	unbanUserPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		UPDATE users SET banned_at = NULL, ban_reason = NULL WHERE id::text = $1 AND banned_at IS NOT NULL`)
+	if err != nil {
+		return err
+	}
+	result, err := unbanUserPreparedStmt.ExecContext(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+// ReadUserBan reads the ban of the user from the database.
+func (D DBRepo) ReadUserBan(ctx context.Context, userID string) (*models.UserBan, error) {
+	readUserBanPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT id, banned_at, COALESCE(ban_reason, '') FROM users WHERE id::text = $1 AND banned_at IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	ban := models.UserBan{}
+	err = readUserBanPreparedStmt.QueryRowContext(ctx, userID).Scan(&ban.UserID, &ban.BannedAt, &ban.Reason)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &ban, nil
+}
+
+// CreateAuditEvent appends the event to the audit log in the database.
+func (D DBRepo) CreateAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	createAuditEventPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		INSERT INTO audit_log (seq, created_at, action, target, user_id, ip, forwarded_for, transport, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (seq) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	result, err := createAuditEventPreparedStmt.ExecContext(ctx, event.Seq, event.CreatedAt, event.Action, event.Target,
+		event.UserID, event.IP, event.ForwardedFor, event.Transport, event.PrevHash, event.Hash)
+	if err != nil {
+		return err
+	}
+	if err = checkAffected(result); errors.Is(err, ErrNotFound) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// AppendAuditEvents chains the events to the last one of the audit log in the database and appends them
+// in a single transaction. The transaction holds the advisory lock until it ends, so the instances sharing
+// the database append to the log one by one.
+func (D DBRepo) AppendAuditEvents(
+	ctx context.Context, events []models.AuditEvent, hash func(models.AuditEvent) string) ([]models.AuditEvent, error) {
+	transaction, err := D.pool.Begin()
+	if err != nil {
+		return nil, err
+	}
+	chained, appendErr := D.appendAuditEvents(ctx, transaction, events, hash)
+	if appendErr != nil {
+		txErr := transaction.Rollback()
+		if txErr != nil {
+			return nil, txErr
+		}
+		return nil, appendErr
+	}
+	return chained, transaction.Commit()
+}
+
+func (D DBRepo) appendAuditEvents(ctx context.Context, transaction *sql.Tx, events []models.AuditEvent,
+	hash func(models.AuditEvent) string) ([]models.AuditEvent, error) {
+	if _, err := transaction.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", auditLogLockKey); err != nil {
+		return nil, err
+	}
+	readLastAuditEventPreparedStmt, err := transaction.PrepareContext(ctx, `
+		SELECT seq, created_at, action, target, user_id, ip, forwarded_for, transport, prev_hash, hash
+		FROM audit_log ORDER BY seq DESC LIMIT 1`)
+	if err != nil {
+		return nil, err
+	}
+	var last models.AuditEvent
+	lastEvent, err := scanAuditEvent(readLastAuditEventPreparedStmt.QueryRowContext(ctx))
+	switch {
+	case err == nil:
+		last = *lastEvent
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+	createAuditEventPreparedStmt, err := transaction.PrepareContext(ctx, `
+		INSERT INTO audit_log (seq, created_at, action, target, user_id, ip, forwarded_for, transport, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`)
+	if err != nil {
+		return nil, err
+	}
+	chained := chainAuditEvents(last, events, hash)
+	for _, event := range chained {
+		_, err = createAuditEventPreparedStmt.ExecContext(ctx, event.Seq, event.CreatedAt, event.Action, event.Target,
+			event.UserID, event.IP, event.ForwardedFor, event.Transport, event.PrevHash, event.Hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return chained, nil
+}
+
+// ReadAuditEvents reads the events of the audit log matching the filter from the database, ordered by their position.
+func (D DBRepo) ReadAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	readAuditEventsPreparedStmt, err := D.pool.PrepareContext(ctx, `
+		SELECT seq, created_at, action, target, user_id, ip, forwarded_for, transport, prev_hash, hash
+		FROM audit_log
+		WHERE seq > $1
+		  AND ($2::timestamp IS NULL OR created_at >= $2::timestamp)
+		  AND ($3::timestamp IS NULL OR created_at < $3::timestamp)
+		  AND ($4::text = '' OR action = $4::text)
+		  AND ($5::text = '' OR target = $5::text)
+		  AND ($6::text = '' OR user_id = $6::text)
+		  AND ($7::text = '' OR transport = $7::text)
+		ORDER BY seq
+		LIMIT $8`)
+	if err != nil {
+		return nil, err
+	}
+	var from, to *time.Time
+	if !filter.From.IsZero() {
+		from = &filter.From
+	}
+	if !filter.To.IsZero() {
+		to = &filter.To
+	}
+	rows, err := readAuditEventsPreparedStmt.QueryContext(ctx, filter.AfterSeq, from, to, filter.Action, filter.Target,
+		filter.UserID, filter.Transport, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	results := make([]models.AuditEvent, 0)
+	for rows.Next() {
+		event, scanErr := scanAuditEvent(rows)
+		if scanErr != nil {
+			logger.Log.Error(scanErr.Error())
+			return nil, scanErr
+		}
+		results = append(results, *event)
+	}
+	return results, nil
+}
+
+func scanAuditEvent(row interface{ Scan(dest ...any) error }) (*models.AuditEvent, error) {
+	event := models.AuditEvent{}
+	err := row.Scan(&event.Seq, &event.CreatedAt, &event.Action, &event.Target, &event.UserID, &event.IP,
+		&event.ForwardedFor, &event.Transport, &event.PrevHash, &event.Hash)
+	if err != nil {
+		return nil, err
+	}
+	event.CreatedAt = event.CreatedAt.UTC()
+	return &event, nil
+}
+
+// checkAffected returns ErrNotFound if the statement hasn't changed any row.
+func checkAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
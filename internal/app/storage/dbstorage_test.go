@@ -3,9 +3,12 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jackc/pgerrcode"
@@ -22,6 +25,7 @@ func TestDBRepo_Create(t *testing.T) {
 		id          string
 		originalURL string
 		userID      string
+		options     models.ShortURLOptions
 	}
 	tests := []struct {
 		wantErr assert.ErrorAssertionFunc
@@ -40,6 +44,54 @@ func TestDBRepo_Create(t *testing.T) {
 			want:    "lelelele",
 			wantErr: assert.NoError,
 		},
+		{
+			name: "success with title, notes and tags",
+			args: args{
+				ctx:         context.Background(),
+				id:          "lelelele",
+				originalURL: "http://ya.ru",
+				userID:      "SomeUserID",
+				options:     models.ShortURLOptions{Title: "Yandex", Notes: "Search", Tags: []string{"news", "search"}},
+			},
+			want:    "lelelele",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "success with redirect attributes",
+			args: args{
+				ctx:         context.Background(),
+				id:          "lelelele",
+				originalURL: "http://ya.ru",
+				userID:      "SomeUserID",
+				options:     models.ShortURLOptions{RedirectOptions: models.RedirectOptions{Interstitial: true}},
+			},
+			want:    "lelelele",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "success on branded domain",
+			args: args{
+				ctx:         context.Background(),
+				id:          "go.example.com/lelelele",
+				originalURL: "http://ya.ru",
+				userID:      "SomeUserID",
+				options:     models.ShortURLOptions{Domain: "go.example.com"},
+			},
+			want:    "go.example.com/lelelele",
+			wantErr: assert.NoError,
+		},
+		{
+			name: "success with password",
+			args: args{
+				ctx:         context.Background(),
+				id:          "lelelele",
+				originalURL: "http://ya.ru",
+				userID:      "SomeUserID",
+				options:     models.ShortURLOptions{PasswordHash: "$2a$10$hash"},
+			},
+			want:    "lelelele",
+			wantErr: assert.NoError,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -54,14 +106,26 @@ func TestDBRepo_Create(t *testing.T) {
 				WillReturnResult(sqlmock.NewResult(1, 1))
 
 			mock.ExpectPrepare("INSERT INTO short_url").ExpectExec().
-				WithArgs(tt.args.id, tt.args.originalURL, tt.args.userID).
+				WithArgs(tt.args.id, tt.args.originalURL, tt.args.userID, tt.args.options.Title, tt.args.options.Notes,
+					redirectOptionsJSON(t, tt.args.options.RedirectOptions), tt.args.options.PasswordHash,
+					tt.args.options.UTMTemplateID, tt.args.options.MaxClicks, tt.args.options.Domain,
+					tt.args.options.CampaignID, tt.args.options.OrgID).
 				WillReturnResult(sqlmock.NewResult(1, 1))
+			if len(tt.args.options.Tags) > 0 {
+				createTagStatement := mock.ExpectPrepare("INSERT INTO tags")
+				linkTagStatement := mock.ExpectPrepare("INSERT INTO short_url_tags")
+				for _, tag := range tt.args.options.Tags {
+					createTagStatement.ExpectExec().WithArgs(tag).WillReturnResult(sqlmock.NewResult(1, 1))
+					linkTagStatement.ExpectExec().WithArgs(tt.args.id, tag).WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+			}
 			mock.ExpectCommit()
-			got, err := D.Create(tt.args.ctx, tt.args.id, tt.args.originalURL, tt.args.userID)
+			got, err := D.Create(tt.args.ctx, tt.args.id, tt.args.originalURL, tt.args.userID, tt.args.options)
 			if !tt.wantErr(t, err, fmt.Sprintf("Create(%v, %v, %v, %v)", tt.args.ctx, tt.args.id, tt.args.originalURL, tt.args.userID)) {
 				return
 			}
 			assert.Equalf(t, tt.want, got, "Create(%v, %v, %v, %v)", tt.args.ctx, tt.args.id, tt.args.originalURL, tt.args.userID)
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
@@ -121,13 +185,13 @@ func TestDBRepo_CreateAlreadyExists(t *testing.T) {
 				WithArgs(tt.args.userID).
 				WillReturnResult(sqlmock.NewResult(1, 1))
 			mock.ExpectPrepare("INSERT INTO short_url").ExpectExec().
-				WithArgs(tt.args.id, tt.args.originalURL, tt.args.userID).
+				WithArgs(tt.args.id, tt.args.originalURL, tt.args.userID, "", "", []byte("{}"), "", "", int64(0), "", "", "").
 				WillReturnError(&pgconn.PgError{Code: tt.args.errorCode})
 			mock.ExpectPrepare("SELECT short_url FROM short_url").ExpectQuery().
-				WithArgs(tt.args.originalURL).
+				WithArgs(tt.args.originalURL, "").
 				WillReturnRows(mock.NewRows([]string{"short_url"}).AddRow(tt.want))
 			mock.ExpectRollback()
-			got, err := D.Create(tt.args.ctx, tt.args.id, tt.args.originalURL, tt.args.userID)
+			got, err := D.Create(tt.args.ctx, tt.args.id, tt.args.originalURL, tt.args.userID, models.ShortURLOptions{})
 			if !tt.wantErr(t, err, fmt.Sprintf("Create(%v, %v, %v, %v)", tt.args.ctx, tt.args.id, tt.args.originalURL, tt.args.userID)) {
 				return
 			}
@@ -337,6 +401,7 @@ func TestDBRepo_GetShortURLByOriginalURL(t *testing.T) {
 	type args struct {
 		ctx         context.Context
 		originalURL string
+		domain      string
 	}
 	tests := []struct {
 		name string
@@ -359,6 +424,15 @@ func TestDBRepo_GetShortURLByOriginalURL(t *testing.T) {
 			},
 			want: "",
 		},
+		{
+			name: "success on branded domain",
+			args: args{
+				ctx:         context.Background(),
+				originalURL: "https://ya.ru",
+				domain:      "go.example.com",
+			},
+			want: "go.example.com/lelelele",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -367,10 +441,10 @@ func TestDBRepo_GetShortURLByOriginalURL(t *testing.T) {
 			D := DBRepo{
 				pool: db,
 			}
-			mock.ExpectPrepare("SELECT short_url FROM short_url").ExpectQuery().
-				WithArgs(tt.args.originalURL).
+			mock.ExpectPrepare("SELECT short_url FROM short_url WHERE original_url = \\$1 AND domain = \\$2").ExpectQuery().
+				WithArgs(tt.args.originalURL, tt.args.domain).
 				WillReturnRows(mock.NewRows([]string{"short_url"}).AddRow(tt.want))
-			res, err := D.GetShortURLByOriginalURL(tt.args.ctx, tt.args.originalURL)
+			res, err := D.GetShortURLByOriginalURL(tt.args.ctx, tt.args.originalURL, tt.args.domain)
 			assert.Equalf(t, tt.want, res, "GetShortURLByOriginalURL(%v, %v)", tt.args.ctx, tt.args.originalURL)
 			require.NoError(t, err)
 		})
@@ -380,8 +454,10 @@ func TestDBRepo_GetShortURLByOriginalURL(t *testing.T) {
 func TestDBRepo_ReadByUserID(t *testing.T) {
 	type args struct {
 		ctx    context.Context
+		filter models.ShortURLFilter
 		userID string
 	}
+	clicksLeft := int64(2)
 	tests := []struct {
 		wantErr assert.ErrorAssertionFunc
 		args    args
@@ -400,8 +476,35 @@ func TestDBRepo_ReadByUserID(t *testing.T) {
 					OriginalURL: "http://ya.ru",
 				},
 				{
-					ShortURL:    "lololo",
-					OriginalURL: "http://yandex.ru",
+					ShortURL:        "lololo",
+					OriginalURL:     "http://yandex.ru",
+					ShortURLOptions: models.ShortURLOptions{MaxClicks: 5},
+					ClicksLeft:      &clicksLeft,
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "Successful batch read filtered by tag",
+			args: args{
+				ctx:    context.Background(),
+				userID: "SomeUserID",
+				filter: models.ShortURLFilter{Tag: "search"},
+			},
+			want: []models.ShortURLsByUserResponse{
+				{
+					ShortURL:    "lelele",
+					OriginalURL: "http://ya.ru",
+					ShortURLOptions: models.ShortURLOptions{
+						Title: "Yandex",
+						Notes: "Search engine",
+						Tags:  []string{"news", "search"},
+					},
+					Metadata: &models.PageMetadata{
+						Title:      "Yandex",
+						FaviconURL: "http://ya.ru/favicon.ico",
+						OpenGraph:  map[string]string{"title": "Yandex"},
+					},
 				},
 			},
 			wantErr: assert.NoError,
@@ -423,19 +526,28 @@ func TestDBRepo_ReadByUserID(t *testing.T) {
 			D := DBRepo{
 				pool: db,
 			}
-			var rs *sqlmock.Rows
-			if len(tt.want) > 0 {
-				rs = mock.NewRows([]string{"short_url", "original_url"}).
-					AddRow(tt.want[0].ShortURL, tt.want[0].OriginalURL).
-					AddRow(tt.want[1].ShortURL, tt.want[1].OriginalURL)
-			} else {
-				rs = mock.NewRows([]string{"short_url", "original_url"})
+			rs := mock.NewRows([]string{
+				"short_url", "original_url", "title", "notes", "tags", "page_metadata", "redirect_options", "password_hash",
+				"utm_template_id", "max_clicks", "clicks_left", "campaign_id", "org_id"})
+			for _, item := range tt.want {
+				var metadata []byte
+				if item.Metadata != nil {
+					metadata, err = json.Marshal(item.Metadata)
+					require.NoError(t, err)
+				}
+				var left int64
+				if item.ClicksLeft != nil {
+					left = *item.ClicksLeft
+				}
+				rs.AddRow(item.ShortURL, item.OriginalURL, item.Title, item.Notes, strings.Join(item.Tags, ","), metadata,
+					redirectOptionsJSON(t, item.RedirectOptions), item.PasswordHash, item.UTMTemplateID, item.MaxClicks, left,
+					item.CampaignID, item.OrgID)
 			}
 
-			mock.ExpectPrepare("SELECT short_url, original_url FROM short_url").ExpectQuery().
-				WithArgs(tt.args.userID).
+			mock.ExpectPrepare("SELECT s.short_url, s.original_url, s.title, s.notes").ExpectQuery().
+				WithArgs(tt.args.userID, tt.args.filter.Tag, tt.args.filter.CampaignID).
 				WillReturnRows(rs)
-			res, err := D.ReadByUserID(tt.args.ctx, tt.args.userID)
+			res, err := D.ReadByUserID(tt.args.ctx, tt.args.userID, tt.args.filter)
 			assert.Equalf(t, tt.want, res, "ReadByUserID(%v, %v)", tt.args.ctx, tt.args.userID)
 			require.NoError(t, err)
 		})
@@ -450,15 +562,17 @@ func TestDBRepo_GetStats(t *testing.T) {
 		{
 			name: "success",
 			want: &models.ServiceStats{
-				Users: 1337,
-				URLs:  1338,
+				Users:     1337,
+				URLs:      1338,
+				Campaigns: 12,
 			},
 		},
 		{
 			name: "failure",
 			want: &models.ServiceStats{
-				Users: 1337,
-				URLs:  1338,
+				Users:     1337,
+				URLs:      1338,
+				Campaigns: 12,
 			},
 		},
 	}
@@ -469,12 +583,14 @@ func TestDBRepo_GetStats(t *testing.T) {
 			D := DBRepo{
 				pool: db,
 			}
-			var rsUsers, rsUrls *sqlmock.Rows
+			var rsUsers, rsUrls, rsCampaigns *sqlmock.Rows
 			rsUsers = mock.NewRows([]string{"count"}).AddRow(tt.want.Users)
 			rsUrls = mock.NewRows([]string{"count"}).AddRow(tt.want.URLs)
+			rsCampaigns = mock.NewRows([]string{"count"}).AddRow(tt.want.Campaigns)
 
 			mock.ExpectPrepare("SELECT count").ExpectQuery().WillReturnRows(rsUsers)
 			mock.ExpectPrepare("SELECT count").ExpectQuery().WillReturnRows(rsUrls)
+			mock.ExpectPrepare("SELECT count").ExpectQuery().WillReturnRows(rsCampaigns)
 			res, err := D.GetStats(context.Background())
 
 			assert.Equal(t, tt.want, res, "GetStats")
@@ -482,3 +598,769 @@ func TestDBRepo_GetStats(t *testing.T) {
 		})
 	}
 }
+
+func TestDBRepo_ReadShortURL(t *testing.T) {
+	tests := []struct {
+		wantErr error
+		want    *models.ShortURL
+		name    string
+		id      string
+	}{
+		{
+			name: "Successful read",
+			id:   "lelelele",
+			want: &models.ShortURL{
+				ShortURL:    "lelelele",
+				OriginalURL: "https://ya.ru",
+				UserID:      "SomeUserID",
+				ShortURLOptions: models.ShortURLOptions{
+					Title:           "Yandex",
+					PasswordHash:    "$2a$10$hash",
+					Tags:            []string{"news", "search"},
+					UTMTemplateID:   "8c5b1e52-7f3a-4c1e-9d1a-2f6b0c3e4a5d",
+					RedirectOptions: models.RedirectOptions{Interstitial: true},
+					MaxClicks:       10,
+				},
+				Metadata:   &models.PageMetadata{Title: "Yandex", FaviconURL: "https://ya.ru/favicon.ico"},
+				UTM:        &models.UTMParameters{Source: "newsletter", Campaign: "autumn"},
+				Moderation: &models.Moderation{DisabledAt: time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC), Reason: "Phishing"},
+				ClicksLeft: 3,
+			},
+		},
+		{
+			name:    "Not found",
+			id:      "lololo",
+			wantErr: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			D := NewDBRepo(db)
+			rows := mock.NewRows([]string{
+				"short_url", "original_url", "user_id", "title", "notes", "active", "tags", "page_metadata", "redirect_options",
+				"password_hash", "utm_template_id", "utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content",
+				"max_clicks", "clicks_left", "campaign_id", "org_id", "disabled_at", "disabled_reason"})
+			if tt.want != nil {
+				var metadata []byte
+				if tt.want.Metadata != nil {
+					metadata, err = json.Marshal(tt.want.Metadata)
+					require.NoError(t, err)
+				}
+				rows.AddRow(tt.want.ShortURL, tt.want.OriginalURL, tt.want.UserID, tt.want.Title, tt.want.Notes,
+					!tt.want.Deleted, strings.Join(tt.want.Tags, ","), metadata, redirectOptionsJSON(t, tt.want.RedirectOptions),
+					tt.want.PasswordHash, tt.want.UTMTemplateID, tt.want.UTM.Source, tt.want.UTM.Medium, tt.want.UTM.Campaign,
+					tt.want.UTM.Term, tt.want.UTM.Content, tt.want.MaxClicks, tt.want.ClicksLeft, tt.want.CampaignID,
+					tt.want.OrgID, tt.want.Moderation.DisabledAt, tt.want.Moderation.Reason)
+			}
+			mock.ExpectPrepare("SELECT s.short_url, s.original_url").ExpectQuery().
+				WithArgs(tt.id).
+				WillReturnRows(rows)
+			got, err := D.ReadShortURL(context.Background(), tt.id)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDBRepo_Update(t *testing.T) {
+	title := "Yandex"
+	tags := []string{"search"}
+	interstitial := true
+	tests := []struct {
+		wantErr  error
+		name     string
+		update   models.UpdateShortURLRequest
+		affected int64
+	}{
+		{
+			name:     "Successful update of title",
+			update:   models.UpdateShortURLRequest{Title: &title},
+			affected: 1,
+		},
+		{
+			name:     "Successful update of tags",
+			update:   models.UpdateShortURLRequest{Tags: &tags},
+			affected: 1,
+		},
+		{
+			name:     "Successful update of redirect attributes",
+			update:   models.UpdateShortURLRequest{Interstitial: &interstitial},
+			affected: 1,
+		},
+		{
+			name:     "Not found",
+			update:   models.UpdateShortURLRequest{Title: &title},
+			affected: 0,
+			wantErr:  ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			D := NewDBRepo(db)
+			mock.ExpectBegin()
+			mock.ExpectPrepare("UPDATE short_url SET title").ExpectExec().
+				WithArgs("lelelele", tt.update.Title, tt.update.Notes, tt.update.UTMTemplateID, tt.update.CampaignID).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			if tt.update.ChangesRedirect() {
+				mock.ExpectPrepare("SELECT redirect_options FROM short_url").ExpectQuery().
+					WithArgs("lelelele").
+					WillReturnRows(mock.NewRows([]string{"redirect_options"}).AddRow([]byte("{}")))
+				mock.ExpectPrepare("UPDATE short_url SET redirect_options").ExpectExec().
+					WithArgs("lelelele", []byte(`{"interstitial":true}`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			if tt.update.Tags != nil {
+				mock.ExpectPrepare("DELETE FROM short_url_tags").ExpectExec().
+					WithArgs("lelelele").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				createTagStatement := mock.ExpectPrepare("INSERT INTO tags")
+				linkTagStatement := mock.ExpectPrepare("INSERT INTO short_url_tags")
+				createTagStatement.ExpectExec().WithArgs("search").WillReturnResult(sqlmock.NewResult(1, 1))
+				linkTagStatement.ExpectExec().WithArgs("lelelele", "search").WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+			if tt.wantErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectCommit()
+			}
+			err = D.Update(context.Background(), "lelelele", tt.update)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDBRepo_SetMetadata(t *testing.T) {
+	tests := []struct {
+		wantErr  error
+		name     string
+		affected int64
+	}{
+		{
+			name:     "Successful set",
+			affected: 1,
+		},
+		{
+			name:     "Not found",
+			affected: 0,
+			wantErr:  ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			D := NewDBRepo(db)
+			metadata := models.PageMetadata{Title: "Yandex", OpenGraph: map[string]string{"site_name": "Yandex"}}
+			data, err := json.Marshal(metadata)
+			require.NoError(t, err)
+			mock.ExpectPrepare("UPDATE short_url SET page_metadata").ExpectExec().
+				WithArgs("lelelele", data).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			err = D.SetMetadata(context.Background(), "lelelele", metadata)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDBRepo_CreateUTMTemplate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	template := models.UTMTemplate{ID: "8c5b1e52-7f3a-4c1e-9d1a-2f6b0c3e4a5d", UserID: "SomeUserID", Name: "Newsletter",
+		UTMParameters: models.UTMParameters{Source: "newsletter", Medium: "email"}}
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO users").ExpectExec().
+		WithArgs("SomeUserID").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectPrepare("INSERT INTO utm_templates").ExpectExec().
+		WithArgs(template.ID, "SomeUserID", "Newsletter", "newsletter", "email", "", "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	require.NoError(t, D.CreateUTMTemplate(context.Background(), template))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_ReadUTMTemplate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	columns := []string{"id", "user_id", "name", "utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content"}
+	mock.ExpectPrepare("SELECT id, user_id, name").ExpectQuery().
+		WithArgs("template").
+		WillReturnRows(mock.NewRows(columns).AddRow("template", "SomeUserID", "Ads", "google", "cpc", "", "", ""))
+	got, err := D.ReadUTMTemplate(context.Background(), "template")
+	require.NoError(t, err)
+	assert.Equal(t, &models.UTMTemplate{ID: "template", UserID: "SomeUserID", Name: "Ads",
+		UTMParameters: models.UTMParameters{Source: "google", Medium: "cpc"}}, got)
+
+	mock.ExpectPrepare("SELECT id, user_id, name").ExpectQuery().
+		WithArgs("missing").
+		WillReturnRows(mock.NewRows(columns))
+	_, err = D.ReadUTMTemplate(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_DeleteUTMTemplate(t *testing.T) {
+	tests := []struct {
+		wantErr  error
+		name     string
+		affected int64
+	}{
+		{
+			name:     "Successful delete",
+			affected: 1,
+		},
+		{
+			name:     "Not found",
+			affected: 0,
+			wantErr:  ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			D := NewDBRepo(db)
+			mock.ExpectPrepare("DELETE FROM utm_templates").ExpectExec().
+				WithArgs("template").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			err = D.DeleteUTMTemplate(context.Background(), "template")
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func redirectOptionsJSON(t *testing.T, options models.RedirectOptions) []byte {
+	data, err := json.Marshal(options)
+	require.NoError(t, err)
+	return data
+}
+
+func TestDBRepo_AddVariantClicks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	mock.ExpectPrepare("INSERT INTO variant_clicks").ExpectExec().
+		WithArgs("lelele", "a", int64(3)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	require.NoError(t, D.AddVariantClicks(context.Background(), "lelele", "a", 3))
+
+	mock.ExpectPrepare("INSERT INTO variant_clicks").ExpectExec().
+		WithArgs("missing", "a", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, D.AddVariantClicks(context.Background(), "missing", "a", 1), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_ReadVariantClicks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	mock.ExpectPrepare("SELECT c.variant, c.clicks FROM variant_clicks").ExpectQuery().
+		WithArgs("lelele").
+		WillReturnRows(mock.NewRows([]string{"variant", "clicks"}).AddRow("a", 10).AddRow("b", 4))
+	got, err := D.ReadVariantClicks(context.Background(), "lelele")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a": 10, "b": 4}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_UseClick(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	mock.ExpectPrepare("UPDATE short_url SET clicks_left = clicks_left - 1").ExpectQuery().
+		WithArgs("lelele").
+		WillReturnRows(sqlmock.NewRows([]string{"clicks_left"}).AddRow(4))
+	left, err := D.UseClick(context.Background(), "lelele")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), left)
+
+	mock.ExpectPrepare("UPDATE short_url SET clicks_left = clicks_left - 1").ExpectQuery().
+		WithArgs("lelele").
+		WillReturnRows(sqlmock.NewRows([]string{"clicks_left"}))
+	_, err = D.UseClick(context.Background(), "lelele")
+	assert.ErrorIs(t, err, ErrNoClicksLeft)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_CreateCampaign(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	campaign := models.Campaign{ID: "5f0c2d9e-3b1a-4e7c-8a6d-1c2b3a4d5e6f", UserID: "SomeUserID", Name: "Black Friday"}
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO users").ExpectExec().
+		WithArgs("SomeUserID").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectPrepare("INSERT INTO campaigns").ExpectExec().
+		WithArgs(campaign.ID, "SomeUserID", "Black Friday").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	require.NoError(t, D.CreateCampaign(context.Background(), campaign))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_DeleteCampaign(t *testing.T) {
+	tests := []struct {
+		wantErr  error
+		name     string
+		affected int64
+	}{
+		{
+			name:     "Successful delete",
+			affected: 1,
+		},
+		{
+			name:     "Not found",
+			affected: 0,
+			wantErr:  ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			D := NewDBRepo(db)
+			mock.ExpectPrepare("DELETE FROM campaigns").ExpectExec().
+				WithArgs("campaign").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			err = D.DeleteCampaign(context.Background(), "campaign")
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDBRepo_GetCampaignStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	mock.ExpectPrepare("SELECT id, user_id, name FROM campaigns").ExpectQuery().
+		WithArgs("campaign").
+		WillReturnRows(mock.NewRows([]string{"id", "user_id", "name"}).AddRow("campaign", "SomeUserID", "Sale"))
+	mock.ExpectPrepare("SELECT count").ExpectQuery().
+		WithArgs("campaign").
+		WillReturnRows(mock.NewRows([]string{"count", "sum"}).AddRow(3, 42))
+	got, err := D.GetCampaignStats(context.Background(), "campaign")
+	require.NoError(t, err)
+	assert.Equal(t, &models.CampaignStats{
+		Campaign: models.Campaign{ID: "campaign", UserID: "SomeUserID", Name: "Sale"},
+		URLs:     3,
+		Clicks:   42,
+	}, got)
+
+	mock.ExpectPrepare("SELECT id, user_id, name FROM campaigns").ExpectQuery().
+		WithArgs("missing").
+		WillReturnRows(mock.NewRows([]string{"id", "user_id", "name"}))
+	_, err = D.GetCampaignStats(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_CreateAccount(t *testing.T) {
+	registeredAt := time.Date(2026, 10, 18, 19, 0, 0, 0, time.UTC)
+	account := models.Account{ID: "SomeUserID", Email: "jane@example.com", PasswordHash: "hash",
+		RegisteredAt: registeredAt}
+	tests := []struct {
+		execErr  error
+		wantErr  error
+		name     string
+		affected int64
+	}{
+		{
+			name:     "Successful registration",
+			affected: 1,
+		},
+		{
+			name:     "Registered already",
+			affected: 0,
+			wantErr:  ErrAlreadyExists,
+		},
+		{
+			name:    "Email taken",
+			execErr: &pgconn.PgError{Code: pgerrcode.UniqueViolation},
+			wantErr: ErrAlreadyExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			D := NewDBRepo(db)
+			exec := mock.ExpectPrepare("INSERT INTO users").ExpectExec().
+				WithArgs("SomeUserID", "jane@example.com", "hash", registeredAt, nil)
+			if tt.execErr != nil {
+				exec.WillReturnError(tt.execErr)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			}
+			assert.ErrorIs(t, D.CreateAccount(context.Background(), account), tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDBRepo_ReadAccountByEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	registeredAt := time.Date(2026, 10, 18, 19, 0, 0, 0, time.UTC)
+	columns := []string{"id", "email", "password_hash", "registered_at", "verified_at"}
+	mock.ExpectPrepare("SELECT id, email, password_hash, registered_at, verified_at FROM users").ExpectQuery().
+		WithArgs("jane@example.com").
+		WillReturnRows(mock.NewRows(columns).AddRow("SomeUserID", "jane@example.com", "hash", registeredAt, nil))
+	got, err := D.ReadAccountByEmail(context.Background(), "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, &models.Account{ID: "SomeUserID", Email: "jane@example.com", PasswordHash: "hash",
+		RegisteredAt: registeredAt}, got)
+
+	mock.ExpectPrepare("SELECT id, email, password_hash, registered_at, verified_at FROM users").ExpectQuery().
+		WithArgs("SomeUserID").
+		WillReturnRows(mock.NewRows(columns))
+	_, err = D.ReadAccount(context.Background(), "SomeUserID")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_DeleteUnverifiedAccount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	mock.ExpectPrepare("UPDATE users SET email = NULL").ExpectExec().
+		WithArgs("SomeUserID").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, D.DeleteUnverifiedAccount(context.Background(), "SomeUserID"))
+	mock.ExpectPrepare("UPDATE users SET email = NULL").ExpectExec().
+		WithArgs("VerifiedUserID").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, D.DeleteUnverifiedAccount(context.Background(), "VerifiedUserID"), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_CreateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	createdAt := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	key := models.APIKey{ID: "SomeKeyID", UserID: "SomeUserID", Name: "CI", Prefix: "usk_abcdefgh",
+		KeyHash: "hash", CreatedAt: createdAt}
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO users").ExpectExec().WithArgs("SomeUserID").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("INSERT INTO api_keys").ExpectExec().
+		WithArgs("SomeKeyID", "SomeUserID", "CI", "usk_abcdefgh", "hash", createdAt, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	require.NoError(t, D.CreateAPIKey(context.Background(), key))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_ReadAPIKeyByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	createdAt := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(24 * time.Hour)
+	columns := []string{"id", "user_id", "name", "prefix", "key_hash", "created_at", "last_used_at", "expires_at"}
+	mock.ExpectPrepare("SELECT id, user_id, name, prefix, key_hash, created_at, last_used_at, expires_at FROM api_keys").
+		ExpectQuery().WithArgs("hash").
+		WillReturnRows(mock.NewRows(columns).
+			AddRow("SomeKeyID", "SomeUserID", "CI", "usk_abcdefgh", "hash", createdAt, nil, expiresAt))
+	got, err := D.ReadAPIKeyByHash(context.Background(), "hash")
+	require.NoError(t, err)
+	assert.Equal(t, &models.APIKey{ID: "SomeKeyID", UserID: "SomeUserID", Name: "CI", Prefix: "usk_abcdefgh",
+		KeyHash: "hash", CreatedAt: createdAt, ExpiresAt: &expiresAt}, got)
+
+	mock.ExpectPrepare("SELECT (.+) FROM api_keys").ExpectQuery().WithArgs("SomeKeyID").
+		WillReturnRows(mock.NewRows(columns))
+	_, err = D.ReadAPIKey(context.Background(), "SomeKeyID")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_TouchAndDeleteAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	usedAt := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	mock.ExpectPrepare("UPDATE api_keys SET last_used_at").ExpectExec().WithArgs("SomeKeyID", usedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, D.TouchAPIKey(context.Background(), "SomeKeyID", usedAt))
+
+	mock.ExpectPrepare("DELETE FROM api_keys").ExpectExec().WithArgs("SomeKeyID").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, D.DeleteAPIKey(context.Background(), "SomeKeyID"), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_TokenRevocations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	issuedAt := time.Date(2026, 10, 18, 21, 0, 0, 0, time.UTC)
+	expiresAt := issuedAt.Add(120 * time.Hour)
+	mock.ExpectPrepare("DELETE FROM revoked_tokens WHERE expires_at < NOW\\(\\)(.+)INSERT INTO revoked_tokens").
+		ExpectExec().WithArgs("SomeToken", "SomeUser", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.NoError(t, D.RevokeToken(context.Background(), "SomeToken", "SomeUser", expiresAt))
+
+	mock.ExpectPrepare("INSERT INTO users").ExpectExec().WithArgs("SomeUser", issuedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, D.RevokeUserTokens(context.Background(), "SomeUser", issuedAt))
+
+	mock.ExpectPrepare("SELECT EXISTS").ExpectQuery().WithArgs("OtherToken", "SomeUser", issuedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"revoked"}).AddRow(true))
+	revoked, err := D.IsTokenRevoked(context.Background(), "OtherToken", "SomeUser", issuedAt)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_OIDCIdentities(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	identity := models.OIDCIdentity{Issuer: "https://idp.example", Subject: "employee", UserID: "SomeUser",
+		CreatedAt: time.Date(2026, 10, 18, 22, 0, 0, 0, time.UTC)}
+	mock.ExpectPrepare("INSERT INTO oidc_identities").ExpectExec().
+		WithArgs(identity.Issuer, identity.Subject, identity.UserID, identity.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, D.CreateOIDCIdentity(context.Background(), identity), ErrAlreadyExists)
+
+	mock.ExpectPrepare("SELECT issuer, subject, user_id, created_at FROM oidc_identities").ExpectQuery().
+		WithArgs(identity.Issuer, identity.Subject).
+		WillReturnRows(sqlmock.NewRows([]string{"issuer", "subject", "user_id", "created_at"}).
+			AddRow(identity.Issuer, identity.Subject, identity.UserID, identity.CreatedAt))
+	got, err := D.ReadOIDCIdentity(context.Background(), identity.Issuer, identity.Subject)
+	require.NoError(t, err)
+	assert.Equal(t, &identity, got)
+
+	mock.ExpectPrepare("SELECT issuer").ExpectQuery().WillReturnError(sql.ErrNoRows)
+	_, err = D.ReadOIDCIdentity(context.Background(), identity.Issuer, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_CreateOrganization(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	createdAt := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	org := models.Organization{ID: "5f0c2d9e-3b1a-4e7c-8a6d-1c2b3a4d5e6f", Name: "Acme", CreatedAt: createdAt}
+	owner := models.OrgMember{OrgID: org.ID, UserID: "SomeUserID", Role: models.RoleOwner, CreatedAt: createdAt}
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO users").ExpectExec().
+		WithArgs("SomeUserID").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectPrepare("INSERT INTO organizations").ExpectExec().
+		WithArgs(org.ID, "Acme", createdAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectPrepare("INSERT INTO org_members").ExpectExec().
+		WithArgs(org.ID, "SomeUserID", models.RoleOwner, createdAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	require.NoError(t, D.CreateOrganization(context.Background(), org, owner))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_ReadOrganizationsByUserID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	createdAt := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	mock.ExpectPrepare("SELECT o.id, o.name, o.created_at, m.role FROM organizations").ExpectQuery().
+		WithArgs("SomeUserID").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at", "role"}).
+			AddRow("org", "Acme", createdAt, models.RoleAdmin))
+	got, err := D.ReadOrganizationsByUserID(context.Background(), "SomeUserID")
+	require.NoError(t, err)
+	assert.Equal(t, []models.Organization{{ID: "org", Name: "Acme", CreatedAt: createdAt, Role: models.RoleAdmin}}, got)
+
+	mock.ExpectPrepare("SELECT id, name, created_at FROM organizations").ExpectQuery().
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at"}))
+	_, err = D.ReadOrganization(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_OrgMembers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	createdAt := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	member := models.OrgMember{OrgID: "org", UserID: "SomeUserID", Role: models.RoleEditor, CreatedAt: createdAt}
+	columns := []string{"org_id", "user_id", "role", "created_at"}
+	mock.ExpectPrepare("INSERT INTO org_members").ExpectExec().
+		WithArgs("org", "SomeUserID", models.RoleEditor, createdAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	require.NoError(t, D.SaveOrgMember(context.Background(), member))
+
+	mock.ExpectPrepare("SELECT (.+) FROM org_members").ExpectQuery().
+		WithArgs("org", "SomeUserID").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("org", "SomeUserID", models.RoleEditor, createdAt))
+	got, err := D.ReadOrgMember(context.Background(), "org", "SomeUserID")
+	require.NoError(t, err)
+	assert.Equal(t, &member, got)
+
+	mock.ExpectPrepare("SELECT (.+) FROM org_members").ExpectQuery().
+		WithArgs("org").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("org", "SomeUserID", models.RoleEditor, createdAt))
+	members, err := D.ReadOrgMembers(context.Background(), "org")
+	require.NoError(t, err)
+	assert.Equal(t, []models.OrgMember{member}, members)
+
+	mock.ExpectPrepare("DELETE FROM org_members").ExpectExec().
+		WithArgs("org", "SomeUserID").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, D.DeleteOrgMember(context.Background(), "org", "SomeUserID"), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_SearchShortURLs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	disabledAt := time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC)
+	search := models.ShortURLSearch{Domain: "example.com", Limit: 100}
+	mock.ExpectPrepare("SELECT (.+) FROM short_url s").ExpectQuery().
+		WithArgs("", "example.com", "", 100).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"short_url", "original_url", "user_id", "org_id", "title", "active", "disabled_at", "disabled_reason", "banned"}).
+			AddRow("lelelele", "https://login.example.com", "SomeUserID", "", "", true, disabledAt, "Phishing", true).
+			AddRow("lalalala", "https://example.com/page", "OtherUserID", "org", "Page", false, nil, "", false))
+	got, err := D.SearchShortURLs(context.Background(), search)
+	require.NoError(t, err)
+	assert.Equal(t, []models.AdminShortURL{
+		{
+			ShortURL:    "lelelele",
+			OriginalURL: "https://login.example.com",
+			UserID:      "SomeUserID",
+			Moderation:  &models.Moderation{DisabledAt: disabledAt, Reason: "Phishing"},
+			OwnerBanned: true,
+		},
+		{ShortURL: "lalalala", OriginalURL: "https://example.com/page", UserID: "OtherUserID", OrgID: "org", Title: "Page",
+			Deleted: true},
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_SetModeration(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	moderation := &models.Moderation{DisabledAt: time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC), Reason: "Phishing"}
+	mock.ExpectPrepare("UPDATE short_url SET disabled_at").ExpectExec().
+		WithArgs("lelelele", &moderation.DisabledAt, &moderation.Reason).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, D.SetModeration(context.Background(), "lelelele", moderation))
+
+	mock.ExpectPrepare("UPDATE short_url SET disabled_at").ExpectExec().
+		WithArgs("missing", nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, D.SetModeration(context.Background(), "missing", nil), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_UserBans(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	ban := models.UserBan{BannedAt: time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC), UserID: "SomeUserID", Reason: "Spam"}
+	mock.ExpectPrepare("INSERT INTO users").ExpectExec().
+		WithArgs("SomeUserID", ban.BannedAt, "Spam").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, D.BanUser(context.Background(), ban))
+
+	mock.ExpectPrepare("SELECT id, banned_at").ExpectQuery().
+		WithArgs("SomeUserID").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "banned_at", "ban_reason"}).AddRow("SomeUserID", ban.BannedAt, "Spam"))
+	got, err := D.ReadUserBan(context.Background(), "SomeUserID")
+	require.NoError(t, err)
+	assert.Equal(t, &ban, got)
+
+	mock.ExpectPrepare("UPDATE users SET banned_at = NULL").ExpectExec().
+		WithArgs("SomeUserID").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, D.UnbanUser(context.Background(), "SomeUserID"), ErrNotFound)
+
+	mock.ExpectPrepare("SELECT id, banned_at").ExpectQuery().
+		WithArgs("OtherUserID").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "banned_at", "ban_reason"}))
+	_, err = D.ReadUserBan(context.Background(), "OtherUserID")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBRepo_AuditLog(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	D := NewDBRepo(db)
+	event := models.AuditEvent{Seq: 2, CreatedAt: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), Action: "url.create",
+		Target: "lelelele", UserID: "SomeUserID", IP: "192.0.2.10", ForwardedFor: "203.0.113.7",
+		Transport: models.TransportHTTP, PrevHash: "first", Hash: "second"}
+	columns := []string{"seq", "created_at", "action", "target", "user_id", "ip", "forwarded_for", "transport",
+		"prev_hash", "hash"}
+
+	mock.ExpectPrepare("INSERT INTO audit_log").ExpectExec().
+		WithArgs(event.Seq, event.CreatedAt, event.Action, event.Target, event.UserID, event.IP, event.ForwardedFor,
+			event.Transport, event.PrevHash, event.Hash).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, D.CreateAuditEvent(context.Background(), event))
+	mock.ExpectPrepare("INSERT INTO audit_log").ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, D.CreateAuditEvent(context.Background(), event), ErrAlreadyExists)
+
+	hash := func(event models.AuditEvent) string { return fmt.Sprintf("hash%d", event.Seq) }
+	batch := []models.AuditEvent{
+		{CreatedAt: event.CreatedAt, Action: "url.create", Target: "lololo", UserID: "SomeUserID"},
+		{CreatedAt: event.CreatedAt, Action: "url.create", Target: "lululu", UserID: "SomeUserID"},
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs(auditLogLockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("SELECT (.+) FROM audit_log ORDER BY seq DESC").ExpectQuery().
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(event.Seq, event.CreatedAt, event.Action, event.Target,
+			event.UserID, event.IP, event.ForwardedFor, event.Transport, event.PrevHash, event.Hash))
+	insert := mock.ExpectPrepare("INSERT INTO audit_log")
+	insert.ExpectExec().WithArgs(int64(3), event.CreatedAt, "url.create", "lololo", "SomeUserID", "", "", "",
+		"second", "hash3").WillReturnResult(sqlmock.NewResult(0, 1))
+	insert.ExpectExec().WithArgs(int64(4), event.CreatedAt, "url.create", "lululu", "SomeUserID", "", "", "",
+		"hash3", "hash4").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	appended, err := D.AppendAuditEvents(context.Background(), batch, hash)
+	require.NoError(t, err)
+	require.Len(t, appended, 2)
+	assert.Equal(t, int64(3), appended[0].Seq)
+	assert.Equal(t, "second", appended[0].PrevHash, "the batch follows the last event")
+	assert.Equal(t, appended[0].Hash, appended[1].PrevHash, "the events of the batch are chained")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WithArgs(auditLogLockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare("SELECT (.+) FROM audit_log ORDER BY seq DESC").ExpectQuery().
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectPrepare("INSERT INTO audit_log").ExpectExec().
+		WithArgs(int64(1), event.CreatedAt, "url.create", "lololo", "SomeUserID", "", "", "", "", "hash1").
+		WillReturnError(errors.New("connection lost"))
+	mock.ExpectRollback()
+	_, err = D.AppendAuditEvents(context.Background(), batch[:1], hash)
+	assert.Error(t, err, "nothing is appended if the storage fails")
+
+	mock.ExpectPrepare("SELECT (.+) FROM audit_log WHERE").ExpectQuery().
+		WithArgs(int64(1), nil, nil, "", "lelelele", "", "", 100).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(event.Seq, event.CreatedAt, event.Action, event.Target,
+			event.UserID, event.IP, event.ForwardedFor, event.Transport, event.PrevHash, event.Hash))
+	events, err := D.ReadAuditEvents(context.Background(), models.AuditFilter{AfterSeq: 1, Target: "lelelele", Limit: 100})
+	require.NoError(t, err)
+	assert.Equal(t, []models.AuditEvent{event}, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"sync"
 
 	"github.com/clearthree/url-shortener/internal/app/config"
 	"github.com/clearthree/url-shortener/internal/app/logger"
@@ -16,18 +17,73 @@ import (
 var ErrorFileReadCompletely = errors.New("file has been read completely")
 
 // FileRow is a structure that represents the columns of a single object in the file.
+// The same short URL might be written several times: the later row contains the updated state of the URL.
+// The row with UTMTemplate contains the actual state of the UTM template owned by UserID instead of the short URL.
+// The row with Campaign contains the actual state of the campaign owned by UserID instead of the short URL.
+// The row with Account contains the account registered by UserID, its password hash is written as PasswordHash.
+// The row with APIKey contains the API key owned by UserID, the hash of the key is written as PasswordHash.
+// The row with Organization contains the organization created by UserID, who is its first owner.
+// The row with OrgMember contains the actual role of UserID in the organization OrgID, or the removal if Deleted.
+// The row with Moderation contains the short URL disabled by the admin, or enabled back if Deleted.
+// The row with Ban contains the ban of UserID, or the lifted ban if Deleted.
+// The row with Audit contains the event of the audit log, the file keeps all the events in their order.
+// The row with Revocation contains the logout of UserID: the revoked token or all the tokens issued before.
+// The row with Variant contains the clicks on the split variant of the short URL since the previous such row.
+// The row with UsedClicks contains the clicks taken from the click-limited short URL since the previous such row.
 type FileRow struct {
-	ShortURL    string `json:"short_url"`
-	OriginalURL string `json:"original_url"`
-	UserID      string `json:"user_id"`
-	UUID        int32  `json:"uuid"`
+	Metadata      *models.PageMetadata    `json:"metadata,omitempty"`
+	UTMTemplate   *models.UTMTemplate     `json:"utm_template,omitempty"`
+	Campaign      *models.Campaign        `json:"campaign,omitempty"`
+	Account       *models.Account         `json:"account,omitempty"`
+	APIKey        *models.APIKey          `json:"api_key,omitempty"`
+	Revocation    *models.TokenRevocation `json:"revocation,omitempty"`
+	OIDCIdentity  *models.OIDCIdentity    `json:"oidc_identity,omitempty"`
+	Organization  *models.Organization    `json:"organization,omitempty"`
+	OrgMember     *models.OrgMember       `json:"org_member,omitempty"`
+	Moderation    *models.Moderation      `json:"moderation,omitempty"`
+	Ban           *models.UserBan         `json:"ban,omitempty"`
+	Audit         *models.AuditEvent      `json:"audit,omitempty"`
+	ShortURL      string                  `json:"short_url"`
+	OriginalURL   string                  `json:"original_url"`
+	UserID        string                  `json:"user_id"`
+	Title         string                  `json:"title,omitempty"`
+	Notes         string                  `json:"notes,omitempty"`
+	PasswordHash  string                  `json:"password_hash,omitempty"` // the plain password is never written
+	UTMTemplateID string                  `json:"utm_template_id,omitempty"`
+	CampaignID    string                  `json:"campaign_id,omitempty"`
+	OrgID         string                  `json:"org_id,omitempty"`
+	Variant       string                  `json:"variant,omitempty"`
+	Tags          []string                `json:"tags,omitempty"`
+	models.RedirectOptions
+	Clicks     int64 `json:"clicks,omitempty"`
+	MaxClicks  int64 `json:"max_clicks,omitempty"`
+	UsedClicks int64 `json:"used_clicks,omitempty"`
+	UUID       int32 `json:"uuid"`
+	Deleted    bool  `json:"deleted,omitempty"` // the template, campaign, API key, member, moderation or ban of the row is deleted
+}
+
+// Options returns the optional attributes of the short URL stored in the row.
+func (r *FileRow) Options() models.ShortURLOptions {
+	return models.ShortURLOptions{
+		Title:           r.Title,
+		Notes:           r.Notes,
+		PasswordHash:    r.PasswordHash,
+		Tags:            r.Tags,
+		UTMTemplateID:   r.UTMTemplateID,
+		CampaignID:      r.CampaignID,
+		OrgID:           r.OrgID,
+		RedirectOptions: r.RedirectOptions,
+		MaxClicks:       r.MaxClicks,
+	}
 }
 
 // FileWrapper is a structure that wraps all objects required for the file reading and writing.
+// Writing is guarded by the mutex, since the rows are written by the background workers too.
 type FileWrapper struct {
 	file     *os.File
 	reader   *bufio.Reader
 	writer   *bufio.Writer
+	mu       sync.Mutex
 	lastUUID int32
 }
 
@@ -65,51 +121,176 @@ func (f *FileWrapper) Close() error {
 }
 
 // Create writes the single row to the file.
-func (f *FileWrapper) Create(id string, originalURL string, userID string) (int32, error) {
-	if f.file == nil {
-		err := f.Open()
-		if err != nil {
-			return 0, err
-		}
-	}
-	row := FileRow{
-		UUID:        f.lastUUID + 1,
-		ShortURL:    id,
-		OriginalURL: originalURL,
-		UserID:      userID,
-	}
-	data, err := json.Marshal(&row)
-	if err != nil {
-		return 0, err
-	}
-	data = append(data, '\n')
-	_, err = f.writer.Write(data)
-	if err != nil {
-		return 0, err
-	}
-	f.lastUUID++
-	err = f.writer.Flush()
-	if err != nil {
-		return 0, err
-	}
-	return f.lastUUID, nil
+func (f *FileWrapper) Create(id string, originalURL string, userID string, options models.ShortURLOptions) (int32, error) {
+	return f.write(FileRow{
+		ShortURL:        id,
+		OriginalURL:     originalURL,
+		UserID:          userID,
+		Title:           options.Title,
+		Notes:           options.Notes,
+		PasswordHash:    options.PasswordHash,
+		Tags:            options.Tags,
+		UTMTemplateID:   options.UTMTemplateID,
+		CampaignID:      options.CampaignID,
+		OrgID:           options.OrgID,
+		RedirectOptions: options.RedirectOptions,
+		MaxClicks:       options.MaxClicks,
+	})
 }
 
 // BatchCreate writes multiple rows to the file.
 func (f *FileWrapper) BatchCreate(URLs map[string]models.ShortenBatchItemRequest, userID string) (int32, error) {
+	rows := make([]FileRow, 0, len(URLs))
+	for id, item := range URLs {
+		rows = append(rows, FileRow{
+			ShortURL:        id,
+			OriginalURL:     item.OriginalURL,
+			UserID:          userID,
+			Title:           item.Title,
+			Notes:           item.Notes,
+			PasswordHash:    item.PasswordHash,
+			Tags:            item.Tags,
+			UTMTemplateID:   item.UTMTemplateID,
+			CampaignID:      item.CampaignID,
+			OrgID:           item.OrgID,
+			RedirectOptions: item.RedirectOptions,
+			MaxClicks:       item.MaxClicks,
+		})
+	}
+	return f.write(rows...)
+}
+
+// Update writes the row with the actual state of the already existing short URL to the file.
+func (f *FileWrapper) Update(shortURL models.ShortURL) (int32, error) {
+	return f.write(FileRow{
+		ShortURL:        shortURL.ShortURL,
+		OriginalURL:     shortURL.OriginalURL,
+		UserID:          shortURL.UserID,
+		Title:           shortURL.Title,
+		Notes:           shortURL.Notes,
+		PasswordHash:    shortURL.PasswordHash,
+		Tags:            shortURL.Tags,
+		UTMTemplateID:   shortURL.UTMTemplateID,
+		CampaignID:      shortURL.CampaignID,
+		OrgID:           shortURL.OrgID,
+		RedirectOptions: shortURL.RedirectOptions,
+		MaxClicks:       shortURL.MaxClicks,
+		Metadata:        shortURL.Metadata,
+	})
+}
+
+// WriteUTMTemplate writes the row with the actual state of the UTM template to the file.
+func (f *FileWrapper) WriteUTMTemplate(template models.UTMTemplate) (int32, error) {
+	return f.write(FileRow{UTMTemplate: &template, UserID: template.UserID})
+}
+
+// DeleteUTMTemplate writes the row marking the UTM template as deleted to the file.
+func (f *FileWrapper) DeleteUTMTemplate(template models.UTMTemplate) (int32, error) {
+	return f.write(FileRow{UTMTemplate: &template, UserID: template.UserID, Deleted: true})
+}
+
+// WriteCampaign writes the row with the actual state of the campaign to the file.
+func (f *FileWrapper) WriteCampaign(campaign models.Campaign) (int32, error) {
+	return f.write(FileRow{Campaign: &campaign, UserID: campaign.UserID})
+}
+
+// DeleteCampaign writes the row marking the campaign as deleted to the file.
+func (f *FileWrapper) DeleteCampaign(campaign models.Campaign) (int32, error) {
+	return f.write(FileRow{Campaign: &campaign, UserID: campaign.UserID, Deleted: true})
+}
+
+// WriteAccount writes the row with the registered account to the file.
+func (f *FileWrapper) WriteAccount(account models.Account) (int32, error) {
+	return f.write(FileRow{Account: &account, UserID: account.ID, PasswordHash: account.PasswordHash})
+}
+
+// DeleteAccount writes the row marking the account as turned back into the anonymous user to the file.
+func (f *FileWrapper) DeleteAccount(account models.Account) (int32, error) {
+	return f.write(FileRow{Account: &account, UserID: account.ID, Deleted: true})
+}
+
+// WriteAPIKey writes the row with the created API key to the file.
+func (f *FileWrapper) WriteAPIKey(key models.APIKey) (int32, error) {
+	return f.write(FileRow{APIKey: &key, UserID: key.UserID, PasswordHash: key.KeyHash})
+}
+
+// WriteTokenRevocation writes the row with the logout of the user to the file.
+func (f *FileWrapper) WriteTokenRevocation(revocation models.TokenRevocation) (int32, error) {
+	return f.write(FileRow{Revocation: &revocation, UserID: revocation.UserID})
+}
+
+// WriteOIDCIdentity writes the row with the identity linked to the user to the file.
+func (f *FileWrapper) WriteOIDCIdentity(identity models.OIDCIdentity) (int32, error) {
+	return f.write(FileRow{OIDCIdentity: &identity, UserID: identity.UserID})
+}
+
+// WriteOrganization writes the row with the organization created by the owner to the file.
+func (f *FileWrapper) WriteOrganization(org models.Organization, ownerID string) (int32, error) {
+	return f.write(FileRow{Organization: &org, UserID: ownerID})
+}
+
+// WriteOrgMember writes the row with the actual role of the member of the organization to the file.
+func (f *FileWrapper) WriteOrgMember(member models.OrgMember) (int32, error) {
+	return f.write(FileRow{OrgMember: &member, UserID: member.UserID, OrgID: member.OrgID})
+}
+
+// DeleteOrgMember writes the row marking the member as removed from the organization to the file.
+func (f *FileWrapper) DeleteOrgMember(member models.OrgMember) (int32, error) {
+	return f.write(FileRow{OrgMember: &member, UserID: member.UserID, OrgID: member.OrgID, Deleted: true})
+}
+
+// WriteModeration writes the row with the short URL disabled by the admin to the file.
+func (f *FileWrapper) WriteModeration(id string, moderation models.Moderation) (int32, error) {
+	return f.write(FileRow{Moderation: &moderation, ShortURL: id})
+}
+
+// DeleteModeration writes the row marking the short URL as enabled back by the admin to the file.
+func (f *FileWrapper) DeleteModeration(id string) (int32, error) {
+	return f.write(FileRow{Moderation: &models.Moderation{}, ShortURL: id, Deleted: true})
+}
+
+// WriteUserBan writes the row with the ban of the user to the file.
+func (f *FileWrapper) WriteUserBan(ban models.UserBan) (int32, error) {
+	return f.write(FileRow{Ban: &ban, UserID: ban.UserID})
+}
+
+// DeleteUserBan writes the row marking the ban of the user as lifted to the file.
+func (f *FileWrapper) DeleteUserBan(userID string) (int32, error) {
+	return f.write(FileRow{Ban: &models.UserBan{UserID: userID}, UserID: userID, Deleted: true})
+}
+
+// WriteAuditEvent writes the row with the event of the audit log to the file.
+func (f *FileWrapper) WriteAuditEvent(event models.AuditEvent) (int32, error) {
+	return f.write(FileRow{Audit: &event})
+}
+
+// DeleteAPIKey writes the row marking the API key as revoked to the file.
+func (f *FileWrapper) DeleteAPIKey(key models.APIKey) (int32, error) {
+	return f.write(FileRow{APIKey: &key, UserID: key.UserID, Deleted: true})
+}
+
+// WriteVariantClicks writes the row with the new clicks on the split variant of the short URL to the file.
+func (f *FileWrapper) WriteVariantClicks(id string, variant string, clicks int64) (int32, error) {
+	return f.write(FileRow{ShortURL: id, Variant: variant, Clicks: clicks})
+}
+
+// WriteUsedClick writes the row with the click taken from the click-limited short URL to the file.
+func (f *FileWrapper) WriteUsedClick(id string) (int32, error) {
+	return f.write(FileRow{ShortURL: id, UsedClicks: 1})
+}
+
+// write appends the rows to the file assigning the UUIDs to them. Returns the UUID of the last written row.
+func (f *FileWrapper) write(rows ...FileRow) (int32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	if f.file == nil {
 		err := f.Open()
 		if err != nil {
 			return 0, err
 		}
 	}
-	for id, item := range URLs {
-		row := FileRow{
-			UUID:        f.lastUUID + 1,
-			ShortURL:    id,
-			OriginalURL: item.OriginalURL,
-			UserID:      userID,
-		}
+	for _, row := range rows {
+		row.UUID = f.lastUUID + 1
 		data, err := json.Marshal(&row)
 		if err != nil {
 			return 0, err
@@ -108,7 +108,7 @@ func TestFileWrapper_Create(t *testing.T) {
 				writer:   tt.fields.writer,
 				lastUUID: tt.fields.lastUUID,
 			}
-			got, err := f.Create(tt.args.id, tt.args.originalURL, tt.args.userID)
+			got, err := f.Create(tt.args.id, tt.args.originalURL, tt.args.userID, models.ShortURLOptions{})
 			require.NoError(t, err)
 			assert.Equalf(t, tt.want, got, "Create(%v, %v)", tt.args.id, tt.args.originalURL)
 		})
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS title text NOT NULL DEFAULT '';
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS notes text NOT NULL DEFAULT '';
+CREATE TABLE IF NOT EXISTS tags(
+    id bigserial PRIMARY KEY,
+    name text NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS tags_name_udx ON tags(name);
+CREATE TABLE IF NOT EXISTS short_url_tags(
+    short_url_id bigint NOT NULL REFERENCES short_url(id) ON DELETE CASCADE,
+    tag_id bigint NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (short_url_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS short_url_tags_tag_id_idx ON short_url_tags(tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS short_url_tags;
+DROP TABLE IF EXISTS tags;
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS notes;
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS title;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS page_metadata jsonb;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS page_metadata;
+-- +goose StatementEnd
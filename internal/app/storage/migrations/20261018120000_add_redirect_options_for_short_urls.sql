@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS redirect_options jsonb NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS redirect_options;
+-- +goose StatementEnd
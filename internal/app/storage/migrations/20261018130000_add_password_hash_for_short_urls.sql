@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS password_hash text NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS password_hash;
+-- +goose StatementEnd
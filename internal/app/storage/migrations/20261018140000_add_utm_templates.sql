@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS utm_templates(
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id),
+    name text NOT NULL,
+    utm_source text NOT NULL DEFAULT '',
+    utm_medium text NOT NULL DEFAULT '',
+    utm_campaign text NOT NULL DEFAULT '',
+    utm_term text NOT NULL DEFAULT '',
+    utm_content text NOT NULL DEFAULT '',
+    created_at timestamp default NOW(),
+    modified_at timestamp default NOW()
+);
+CREATE INDEX IF NOT EXISTS utm_templates_user_id_idx ON utm_templates USING HASH (user_id);
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS utm_template_id uuid REFERENCES utm_templates(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS utm_template_id;
+DROP TABLE IF EXISTS utm_templates;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS variant_clicks(
+    short_url_id bigint NOT NULL REFERENCES short_url(id) ON DELETE CASCADE,
+    variant text NOT NULL,
+    clicks bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (short_url_id, variant)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS variant_clicks;
+-- +goose StatementEnd
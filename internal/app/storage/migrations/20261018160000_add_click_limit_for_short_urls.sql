@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS max_clicks bigint NOT NULL DEFAULT 0;
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS clicks_left bigint NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS clicks_left;
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS max_clicks;
+-- +goose StatementEnd
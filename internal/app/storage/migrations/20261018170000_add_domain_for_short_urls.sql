@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS domain text NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS short_urls_original_url_udx;
+CREATE UNIQUE INDEX IF NOT EXISTS short_urls_domain_original_url_udx ON short_url(domain, original_url);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM short_url WHERE domain != '') THEN
+        RAISE EXCEPTION 'short_url has the links on the branded domains, move or delete them before the rollback';
+    END IF;
+END;
+$$;
+DROP INDEX IF EXISTS short_urls_domain_original_url_udx;
+CREATE UNIQUE INDEX IF NOT EXISTS short_urls_original_url_udx ON short_url(original_url);
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS domain;
+-- +goose StatementEnd
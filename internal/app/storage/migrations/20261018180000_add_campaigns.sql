@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS campaigns(
+    id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users(id),
+    name text NOT NULL,
+    created_at timestamp default NOW(),
+    modified_at timestamp default NOW()
+);
+CREATE INDEX IF NOT EXISTS campaigns_user_id_idx ON campaigns USING HASH (user_id);
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS campaign_id uuid REFERENCES campaigns(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS short_url_campaign_id_idx ON short_url USING HASH (campaign_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS short_url_campaign_id_idx;
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS campaign_id;
+DROP TABLE IF EXISTS campaigns;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS email text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS password_hash text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS registered_at timestamp;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS verified_at timestamp;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_idx ON users (email);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_email_idx;
+ALTER TABLE "users" DROP COLUMN IF EXISTS verified_at;
+ALTER TABLE "users" DROP COLUMN IF EXISTS registered_at;
+ALTER TABLE "users" DROP COLUMN IF EXISTS password_hash;
+ALTER TABLE "users" DROP COLUMN IF EXISTS email;
+-- +goose StatementEnd
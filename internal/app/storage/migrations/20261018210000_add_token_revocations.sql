@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS revoked_tokens(
+    id text PRIMARY KEY,
+    user_id uuid NOT NULL,
+    revoked_at timestamp NOT NULL default NOW(),
+    expires_at timestamp NOT NULL
+);
+CREATE INDEX IF NOT EXISTS revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tokens_revoked_before timestamp;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS tokens_revoked_before;
+DROP TABLE IF EXISTS revoked_tokens;
+-- +goose StatementEnd
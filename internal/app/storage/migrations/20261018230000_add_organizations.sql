@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS organizations(
+    id uuid PRIMARY KEY,
+    name text NOT NULL,
+    created_at timestamp NOT NULL default NOW()
+);
+CREATE TABLE IF NOT EXISTS org_members(
+    org_id uuid NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users(id),
+    role text NOT NULL,
+    created_at timestamp NOT NULL default NOW(),
+    PRIMARY KEY (org_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS org_members_user_id_idx ON org_members (user_id);
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS org_id uuid REFERENCES organizations(id);
+CREATE INDEX IF NOT EXISTS short_url_org_id_idx ON short_url USING HASH (org_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS short_url_org_id_idx;
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS org_id;
+DROP TABLE IF EXISTS org_members;
+DROP TABLE IF EXISTS organizations;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS disabled_at timestamp;
+ALTER TABLE "short_url" ADD COLUMN IF NOT EXISTS disabled_reason text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS banned_at timestamp;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS ban_reason text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "users" DROP COLUMN IF EXISTS ban_reason;
+ALTER TABLE "users" DROP COLUMN IF EXISTS banned_at;
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS disabled_reason;
+ALTER TABLE "short_url" DROP COLUMN IF EXISTS disabled_at;
+-- +goose StatementEnd
@@ -2,9 +2,16 @@
 package storage
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/clearthree/url-shortener/internal/app/models"
 )
@@ -12,6 +19,12 @@ import (
 // ErrAlreadyExists is an error that returned  when one tries to shorten the URL that exists already in the storage.
 var ErrAlreadyExists = errors.New("URL already exists")
 
+// ErrNotFound is an error that returned when the requested short URL doesn't exist in the storage.
+var ErrNotFound = errors.New("short URL not found")
+
+// ErrNoClicksLeft is an error that returned when the click-limited short URL has been followed as many times as allowed.
+var ErrNoClicksLeft = errors.New("no clicks left")
+
 // ErrAlreadyExistsExtended is a wrapper for ErrAlreadyExists to pass the existing short URL to the caller
 // when the error happens. Implements
 type ErrAlreadyExistsExtended struct {
@@ -37,8 +50,8 @@ func (e ErrAlreadyExistsExtended) Error() string {
 // Repository is the interface that all the storages must implement.
 type Repository interface {
 
-	// Create stores the single URL in the storage.
-	Create(ctx context.Context, id string, originalURL string, userID string) (string, error)
+	// Create stores the single URL in the storage along with its optional attributes.
+	Create(ctx context.Context, id string, originalURL string, userID string, options models.ShortURLOptions) (string, error)
 
 	// Read reads the single original URL from the storage by its short ID.
 	Read(ctx context.Context, id string) (string, bool)
@@ -49,40 +62,238 @@ type Repository interface {
 	// BatchCreate stores the batch of URLs in the storage.
 	BatchCreate(ctx context.Context, URLs map[string]models.ShortenBatchItemRequest, userID string) ([]models.ShortenBatchItemResponse, error)
 
-	// ReadByUserID reads all the user-owned URLs from the storage.
-	ReadByUserID(ctx context.Context, userID string) ([]models.ShortURLsByUserResponse, error)
+	// ReadByUserID reads all the user-owned URLs matching the filter from the storage.
+	ReadByUserID(ctx context.Context, userID string, filter models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error)
+
+	// ReadByOrgID reads all the URLs owned by the organization and matching the filter from the storage.
+	ReadByOrgID(ctx context.Context, orgID string, filter models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error)
+
+	// ReadShortURL reads the whole short URL record from the storage. Returns ErrNotFound if there is no such URL.
+	ReadShortURL(ctx context.Context, id string) (*models.ShortURL, error)
+
+	// Update changes the optional attributes of the short URL in the storage.
+	Update(ctx context.Context, id string, update models.UpdateShortURLRequest) error
 
-	// GetUserIDByShortURL Reads the user ID of the short URL author from the storage.
-	GetUserIDByShortURL(ctx context.Context, shortURL string) (string, error)
+	// SetMetadata stores the fetched metadata of the destination page along with the short URL.
+	SetMetadata(ctx context.Context, id string, metadata models.PageMetadata) error
 
 	// SetURLsInactive marks the URL as inactive in the storage.
 	SetURLsInactive(ctx context.Context, shortURLs []string) error
 
 	// GetStats returns the total number of users and shortened URLs stored in the storage
 	GetStats(ctx context.Context) (*models.ServiceStats, error)
+
+	// CreateUTMTemplate stores the UTM template in the storage.
+	CreateUTMTemplate(ctx context.Context, template models.UTMTemplate) error
+
+	// ReadUTMTemplate reads the UTM template from the storage by its ID. Returns ErrNotFound if there is no such template.
+	ReadUTMTemplate(ctx context.Context, id string) (*models.UTMTemplate, error)
+
+	// ReadUTMTemplatesByUserID reads all the user-owned UTM templates from the storage.
+	ReadUTMTemplatesByUserID(ctx context.Context, userID string) ([]models.UTMTemplate, error)
+
+	// UpdateUTMTemplate replaces the name and the parameters of the UTM template in the storage.
+	UpdateUTMTemplate(ctx context.Context, template models.UTMTemplate) error
+
+	// DeleteUTMTemplate removes the UTM template from the storage, detaching it from the short URLs.
+	DeleteUTMTemplate(ctx context.Context, id string) error
+
+	// AddVariantClicks adds the clicks to the counter of the split variant of the short URL.
+	AddVariantClicks(ctx context.Context, id string, variant string, clicks int64) error
+
+	// ReadVariantClicks reads the click counters of all the split variants of the short URL by their names.
+	ReadVariantClicks(ctx context.Context, id string) (map[string]int64, error)
+
+	// UseClick atomically takes one of the clicks left for the click-limited short URL and returns the clicks left
+	// after it. Returns ErrNoClicksLeft if there are none, which is always the case for the short URL without the limit.
+	UseClick(ctx context.Context, id string) (int64, error)
+
+	// CreateCampaign stores the campaign in the storage.
+	CreateCampaign(ctx context.Context, campaign models.Campaign) error
+
+	// ReadCampaign reads the campaign from the storage by its ID. Returns ErrNotFound if there is no such campaign.
+	ReadCampaign(ctx context.Context, id string) (*models.Campaign, error)
+
+	// ReadCampaignsByUserID reads all the user-owned campaigns from the storage.
+	ReadCampaignsByUserID(ctx context.Context, userID string) ([]models.Campaign, error)
+
+	// UpdateCampaign renames the campaign in the storage.
+	UpdateCampaign(ctx context.Context, campaign models.Campaign) error
+
+	// DeleteCampaign removes the campaign from the storage, detaching the short URLs from it.
+	DeleteCampaign(ctx context.Context, id string) error
+
+	// GetCampaignStats returns the amount of the active short URLs in the campaign and the clicks on them.
+	GetCampaignStats(ctx context.Context, id string) (*models.CampaignStats, error)
+
+	// CreateAccount registers the user as the account. Returns ErrAlreadyExists if the email is taken
+	// or the user is registered already.
+	CreateAccount(ctx context.Context, account models.Account) error
+
+	// ReadAccount reads the account of the user by its ID. Returns ErrNotFound if the user isn't registered.
+	ReadAccount(ctx context.Context, id string) (*models.Account, error)
+
+	// ReadAccountByEmail reads the account by its email. Returns ErrNotFound if there is no such account.
+	ReadAccountByEmail(ctx context.Context, email string) (*models.Account, error)
+
+	// DeleteUnverifiedAccount turns the account with the unconfirmed email back into the anonymous user, keeping
+	// its short URLs, so the email can be registered again. Returns ErrNotFound if the user has no such account.
+	DeleteUnverifiedAccount(ctx context.Context, id string) error
+
+	// CreateAPIKey stores the API key in the storage.
+	CreateAPIKey(ctx context.Context, key models.APIKey) error
+
+	// ReadAPIKey reads the API key from the storage by its ID. Returns ErrNotFound if there is no such key.
+	ReadAPIKey(ctx context.Context, id string) (*models.APIKey, error)
+
+	// ReadAPIKeyByHash reads the API key from the storage by the hash of the key. Returns ErrNotFound if there is
+	// no such key.
+	ReadAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+
+	// ReadAPIKeysByUserID reads all the user-owned API keys from the storage.
+	ReadAPIKeysByUserID(ctx context.Context, userID string) ([]models.APIKey, error)
+
+	// TouchAPIKey saves the moment the API key was used at.
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+
+	// DeleteAPIKey removes the API key from the storage, the revoked key is rejected afterward.
+	DeleteAPIKey(ctx context.Context, id string) error
+
+	// RevokeToken adds the token to the denylist of the storage until it expires. The tokens expired already
+	// are removed from the denylist meanwhile.
+	RevokeToken(ctx context.Context, tokenID string, userID string, expiresAt time.Time) error
+
+	// RevokeUserTokens saves the moment the tokens of the user issued before are revoked.
+	RevokeUserTokens(ctx context.Context, userID string, before time.Time) error
+
+	// IsTokenRevoked reports whether the token is in the denylist or is issued before the tokens of the user
+	// are revoked.
+	IsTokenRevoked(ctx context.Context, tokenID string, userID string, issuedAt time.Time) (bool, error)
+
+	// CreateOIDCIdentity links the user of the identity provider to the local user. Returns ErrAlreadyExists
+	// if the identity is linked already.
+	CreateOIDCIdentity(ctx context.Context, identity models.OIDCIdentity) error
+
+	// ReadOIDCIdentity reads the identity by the issuer and the subject. Returns ErrNotFound if it isn't linked.
+	ReadOIDCIdentity(ctx context.Context, issuer string, subject string) (*models.OIDCIdentity, error)
+
+	// CreateOrganization stores the organization in the storage together with its first owner.
+	CreateOrganization(ctx context.Context, org models.Organization, owner models.OrgMember) error
+
+	// ReadOrganization reads the organization from the storage by its ID. Returns ErrNotFound if there is
+	// no such organization.
+	ReadOrganization(ctx context.Context, id string) (*models.Organization, error)
+
+	// ReadOrganizationsByUserID reads all the organizations the user is a member of, with the role of the user.
+	ReadOrganizationsByUserID(ctx context.Context, userID string) ([]models.Organization, error)
+
+	// ReadOrgMember reads the membership of the user in the organization. Returns ErrNotFound if the user
+	// isn't a member.
+	ReadOrgMember(ctx context.Context, orgID string, userID string) (*models.OrgMember, error)
+
+	// ReadOrgMembers reads all the members of the organization.
+	ReadOrgMembers(ctx context.Context, orgID string) ([]models.OrgMember, error)
+
+	// SaveOrgMember adds the member to the organization or changes the role of the existing one.
+	SaveOrgMember(ctx context.Context, member models.OrgMember) error
+
+	// DeleteOrgMember removes the member from the organization. Returns ErrNotFound if the user isn't a member.
+	DeleteOrgMember(ctx context.Context, orgID string, userID string) error
+
+	// SearchShortURLs reads the short URLs of all the users matching the search, ordered by their creation.
+	SearchShortURLs(ctx context.Context, search models.ShortURLSearch) ([]models.AdminShortURL, error)
+
+	// SetModeration disables the short URL or enables it back if the moderation is nil.
+	// Returns ErrNotFound if there is no such URL.
+	SetModeration(ctx context.Context, id string, moderation *models.Moderation) error
+
+	// BanUser bans the user or replaces the existing ban.
+	BanUser(ctx context.Context, ban models.UserBan) error
+
+	// UnbanUser lifts the ban of the user. Returns ErrNotFound if the user isn't banned.
+	UnbanUser(ctx context.Context, userID string) error
+
+	// ReadUserBan reads the ban of the user. Returns ErrNotFound if the user isn't banned.
+	ReadUserBan(ctx context.Context, userID string) (*models.UserBan, error)
+
+	// CreateAuditEvent appends the event chained already, e.g. restored from the file. The event must follow
+	// the last one: returns ErrAlreadyExists if its position is taken already.
+	CreateAuditEvent(ctx context.Context, event models.AuditEvent) error
+
+	// AppendAuditEvents chains the events to the last one of the audit log one after another and appends them
+	// atomically: each event gets the position and the hash of the previous one, then its own hash computed
+	// by the function. Returns the events appended. The concurrent appends are serialized.
+	AppendAuditEvents(
+		ctx context.Context, events []models.AuditEvent, hash func(models.AuditEvent) string) ([]models.AuditEvent, error)
+
+	// ReadAuditEvents reads the events of the audit log matching the filter, ordered by their position.
+	ReadAuditEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error)
 }
 
 var memoryStorage map[string]string
 var memoryIDsStorage map[string][]string
 var memoryStorageUsersByURLs map[string]string
 var memoryStorageDeactivatedURLs map[string]bool
+var memoryStorageOptions map[string]models.ShortURLOptions
+var memoryStorageMetadata map[string]models.PageMetadata
+var memoryUTMTemplates map[string]models.UTMTemplate
+var memoryVariantClicks map[string]map[string]int64
+var memoryClicksLeft map[string]int64
+var memoryCampaigns map[string]models.Campaign
+var memoryAccounts map[string]models.Account
+var memoryAPIKeys map[string]models.APIKey
+var memoryRevokedTokens map[string]time.Time
+var memoryTokensRevokedBefore map[string]time.Time
+var memoryOIDCIdentities map[oidcIdentityKey]models.OIDCIdentity
+var memoryOrgURLs map[string][]string
+var memoryOrganizations map[string]models.Organization
+var memoryOrgMembers map[string]map[string]models.OrgMember
+var memoryModerations map[string]models.Moderation
+var memoryUserBans map[string]models.UserBan
+var memoryCreationOrder []string
+var memoryAuditLog []models.AuditEvent
+
+type oidcIdentityKey struct {
+	issuer  string
+	subject string
+}
+
+// memoryLock guards all the in-memory maps, since they are written by the background workers too.
+var memoryLock sync.RWMutex
 
 // MemoryRepo struct implements the Repository interface as an in-memory storage. In-memory storage is a set of maps to
 // store and obtain any needed data by O(1) complexity.
 type MemoryRepo struct{}
 
-// Create stores the single URL in the storage.
-func (m MemoryRepo) Create(_ context.Context, id string, originalURL string, userID string) (string, error) {
+// Create stores the single URL in the storage along with its optional attributes.
+// Storing the same ID once again overwrites the record, which is used when the storage is refilled from the file.
+// The clicks left are kept then, they are counted down from the limit the URL is created with.
+func (m MemoryRepo) Create(_ context.Context, id string, originalURL string, userID string, options models.ShortURLOptions) (string, error) {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	_, exists := memoryStorage[id]
 	memoryStorage[id] = originalURL
 	memoryStorageUsersByURLs[id] = userID
-	currentShortURLs := memoryIDsStorage[userID]
-	currentShortURLs = append(currentShortURLs, id)
-	memoryIDsStorage[userID] = currentShortURLs
+	memoryStorageOptions[id] = options
+	if !exists {
+		if options.ClickLimited() {
+			memoryClicksLeft[id] = options.MaxClicks
+		}
+		currentShortURLs := memoryIDsStorage[userID]
+		currentShortURLs = append(currentShortURLs, id)
+		memoryIDsStorage[userID] = currentShortURLs
+		if options.OrgID != "" {
+			memoryOrgURLs[options.OrgID] = append(memoryOrgURLs[options.OrgID], id)
+		}
+		memoryCreationOrder = append(memoryCreationOrder, id)
+	}
 	return id, nil
 }
 
 // Read reads the single original URL from the storage by its short ID.
 func (m MemoryRepo) Read(_ context.Context, id string) (string, bool) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
 	originalURL, ok := memoryStorage[id]
 	if !ok {
 		return "", false
@@ -100,7 +311,7 @@ func (m MemoryRepo) Ping(_ context.Context) error {
 func (m MemoryRepo) BatchCreate(ctx context.Context, URLs map[string]models.ShortenBatchItemRequest, userID string) ([]models.ShortenBatchItemResponse, error) {
 	results := make([]models.ShortenBatchItemResponse, 0, len(URLs))
 	for shortURL, data := range URLs {
-		result, err := m.Create(ctx, shortURL, data.OriginalURL, userID)
+		result, err := m.Create(ctx, shortURL, data.OriginalURL, userID, data.ShortURLOptions)
 		if err != nil {
 			return nil, err
 		}
@@ -109,11 +320,24 @@ func (m MemoryRepo) BatchCreate(ctx context.Context, URLs map[string]models.Shor
 	return results, nil
 }
 
-// ReadByUserID reads all the user-owned URLs from the storage.
-func (m MemoryRepo) ReadByUserID(_ context.Context, userID string) ([]models.ShortURLsByUserResponse, error) {
-	currentShortURLs := memoryIDsStorage[userID]
+// ReadByUserID reads all the user-owned URLs matching the filter from the storage.
+func (m MemoryRepo) ReadByUserID(_ context.Context, userID string, filter models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	return memoryShortURLs(memoryIDsStorage[userID], filter), nil
+}
+
+// ReadByOrgID reads all the URLs of the organization matching the filter from the storage.
+func (m MemoryRepo) ReadByOrgID(_ context.Context, orgID string, filter models.ShortURLFilter) ([]models.ShortURLsByUserResponse, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	return memoryShortURLs(memoryOrgURLs[orgID], filter), nil
+}
+
+// memoryShortURLs returns the active short URLs out of the listed ones matching the filter.
+func memoryShortURLs(currentShortURLs []string, filter models.ShortURLFilter) []models.ShortURLsByUserResponse {
 	if len(currentShortURLs) == 0 {
-		return nil, nil
+		return nil
 	}
 	result := make([]models.ShortURLsByUserResponse, 0)
 	for _, shortURL := range currentShortURLs {
@@ -121,25 +345,121 @@ func (m MemoryRepo) ReadByUserID(_ context.Context, userID string) ([]models.Sho
 		if deleted {
 			continue
 		}
+		options := memoryOptions(shortURL)
+		if filter.Tag != "" && !slices.Contains(options.Tags, filter.Tag) {
+			continue
+		}
+		if filter.CampaignID != "" && options.CampaignID != filter.CampaignID {
+			continue
+		}
+		var clicksLeft *int64
+		if options.ClickLimited() {
+			left := memoryClicksLeft[shortURL]
+			clicksLeft = &left
+		}
 		result = append(result, models.ShortURLsByUserResponse{
-			ShortURL:    shortURL,
-			OriginalURL: memoryStorage[shortURL],
+			ShortURL:        shortURL,
+			OriginalURL:     memoryStorage[shortURL],
+			ShortURLOptions: options,
+			Metadata:        memoryMetadata(shortURL),
+			ClicksLeft:      clicksLeft,
 		})
 	}
-	return result, nil
+	return result
 }
 
-// GetUserIDByShortURL Reads the user ID of the short URL author from the storage.
-func (m MemoryRepo) GetUserIDByShortURL(_ context.Context, shortURL string) (string, error) {
-	_, ok := memoryStorageDeactivatedURLs[shortURL]
-	if ok {
-		return "", nil
+// ReadShortURL reads the whole short URL record from the storage. Returns ErrNotFound if there is no such URL.
+func (m MemoryRepo) ReadShortURL(_ context.Context, id string) (*models.ShortURL, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	originalURL, ok := memoryStorage[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	_, deleted := memoryStorageDeactivatedURLs[id]
+	shortURL := &models.ShortURL{
+		ShortURL:        id,
+		OriginalURL:     originalURL,
+		UserID:          memoryStorageUsersByURLs[id],
+		ShortURLOptions: memoryOptions(id),
+		Metadata:        memoryMetadata(id),
+		ClicksLeft:      memoryClicksLeft[id],
+		Deleted:         deleted,
 	}
-	return memoryStorageUsersByURLs[shortURL], nil
+	if moderation, ok := memoryModerations[id]; ok {
+		shortURL.Moderation = &moderation
+	}
+	if template, ok := memoryUTMTemplates[shortURL.UTMTemplateID]; ok {
+		shortURL.UTM = &template.UTMParameters
+	}
+	return shortURL, nil
+}
+
+// memoryOptions returns the optional attributes of the short URL. The deleted UTM template and campaign are detached,
+// as the database does it with the foreign keys.
+func memoryOptions(id string) models.ShortURLOptions {
+	options := memoryStorageOptions[id]
+	if _, ok := memoryUTMTemplates[options.UTMTemplateID]; !ok {
+		options.UTMTemplateID = ""
+	}
+	if _, ok := memoryCampaigns[options.CampaignID]; !ok {
+		options.CampaignID = ""
+	}
+	return options
+}
+
+// memoryMetadata returns the copy of the page metadata stored for the short URL or nil if it is not fetched yet.
+func memoryMetadata(id string) *models.PageMetadata {
+	metadata, ok := memoryStorageMetadata[id]
+	if !ok {
+		return nil
+	}
+	return &metadata
+}
+
+// Update changes the optional attributes of the short URL in the storage.
+func (m MemoryRepo) Update(_ context.Context, id string, update models.UpdateShortURLRequest) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if _, ok := memoryStorage[id]; !ok {
+		return ErrNotFound
+	}
+	options := memoryStorageOptions[id]
+	if update.Title != nil {
+		options.Title = *update.Title
+	}
+	if update.Notes != nil {
+		options.Notes = *update.Notes
+	}
+	if update.Tags != nil {
+		options.Tags = *update.Tags
+	}
+	if update.UTMTemplateID != nil {
+		options.UTMTemplateID = *update.UTMTemplateID
+	}
+	if update.CampaignID != nil {
+		options.CampaignID = *update.CampaignID
+	}
+	update.ApplyRedirect(&options.RedirectOptions)
+	memoryStorageOptions[id] = options
+	return nil
+}
+
+// SetMetadata stores the fetched metadata of the destination page along with the short URL.
+func (m MemoryRepo) SetMetadata(_ context.Context, id string, metadata models.PageMetadata) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if _, ok := memoryStorage[id]; !ok {
+		return ErrNotFound
+	}
+	memoryStorageMetadata[id] = metadata
+	return nil
 }
 
 // SetURLsInactive marks the URL as inactive in the storage.
 func (m MemoryRepo) SetURLsInactive(_ context.Context, shortURLs []string) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
 	for _, shortURL := range shortURLs {
 		memoryStorageDeactivatedURLs[shortURL] = true
 	}
@@ -148,16 +468,637 @@ func (m MemoryRepo) SetURLsInactive(_ context.Context, shortURLs []string) error
 
 // GetStats returns the total number of users and shortened URLs stored in the memory
 func (m MemoryRepo) GetStats(_ context.Context) (*models.ServiceStats, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
 	response := &models.ServiceStats{
-		Users: len(memoryIDsStorage),
-		URLs:  len(memoryStorage),
+		Users:     len(memoryIDsStorage),
+		URLs:      len(memoryStorage),
+		Campaigns: len(memoryCampaigns),
 	}
 	return response, nil
 }
 
+// CreateUTMTemplate stores the UTM template in the memory.
+// Storing the same ID once again overwrites the template, which is used when the storage is refilled from the file.
+func (m MemoryRepo) CreateUTMTemplate(_ context.Context, template models.UTMTemplate) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	memoryUTMTemplates[template.ID] = template
+	return nil
+}
+
+// ReadUTMTemplate reads the UTM template from the memory by its ID. Returns ErrNotFound if there is no such template.
+func (m MemoryRepo) ReadUTMTemplate(_ context.Context, id string) (*models.UTMTemplate, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	template, ok := memoryUTMTemplates[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &template, nil
+}
+
+// ReadUTMTemplatesByUserID reads all the user-owned UTM templates from the memory, sorted by name.
+func (m MemoryRepo) ReadUTMTemplatesByUserID(_ context.Context, userID string) ([]models.UTMTemplate, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	var result []models.UTMTemplate
+	for _, template := range memoryUTMTemplates {
+		if template.UserID == userID {
+			result = append(result, template)
+		}
+	}
+	slices.SortFunc(result, func(a, b models.UTMTemplate) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return result, nil
+}
+
+// UpdateUTMTemplate replaces the name and the parameters of the UTM template in the memory.
+func (m MemoryRepo) UpdateUTMTemplate(_ context.Context, template models.UTMTemplate) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	existing, ok := memoryUTMTemplates[template.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	existing.Name = template.Name
+	existing.UTMParameters = template.UTMParameters
+	memoryUTMTemplates[template.ID] = existing
+	return nil
+}
+
+// DeleteUTMTemplate removes the UTM template from the memory. The short URLs stop using it on the next read.
+func (m MemoryRepo) DeleteUTMTemplate(_ context.Context, id string) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if _, ok := memoryUTMTemplates[id]; !ok {
+		return ErrNotFound
+	}
+	delete(memoryUTMTemplates, id)
+	return nil
+}
+
+// AddVariantClicks adds the clicks to the counter of the split variant of the short URL in the memory.
+func (m MemoryRepo) AddVariantClicks(_ context.Context, id string, variant string, clicks int64) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if _, ok := memoryStorage[id]; !ok {
+		return ErrNotFound
+	}
+	if memoryVariantClicks[id] == nil {
+		memoryVariantClicks[id] = make(map[string]int64)
+	}
+	memoryVariantClicks[id][variant] += clicks
+	return nil
+}
+
+// ReadVariantClicks reads the click counters of all the split variants of the short URL from the memory.
+func (m MemoryRepo) ReadVariantClicks(_ context.Context, id string) (map[string]int64, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	return maps.Clone(memoryVariantClicks[id]), nil
+}
+
+// UseClick takes one of the clicks left for the click-limited short URL in the memory.
+// The lock makes it atomic, so the concurrent visitors never get more clicks than the limit.
+func (m MemoryRepo) UseClick(_ context.Context, id string) (int64, error) {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if _, ok := memoryStorage[id]; !ok {
+		return 0, ErrNotFound
+	}
+	if memoryClicksLeft[id] <= 0 {
+		return 0, ErrNoClicksLeft
+	}
+	memoryClicksLeft[id]--
+	return memoryClicksLeft[id], nil
+}
+
+// CreateCampaign stores the campaign in the memory.
+// Storing the same ID once again overwrites the campaign, which is used when the storage is refilled from the file.
+func (m MemoryRepo) CreateCampaign(_ context.Context, campaign models.Campaign) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	memoryCampaigns[campaign.ID] = campaign
+	return nil
+}
+
+// ReadCampaign reads the campaign from the memory by its ID. Returns ErrNotFound if there is no such campaign.
+func (m MemoryRepo) ReadCampaign(_ context.Context, id string) (*models.Campaign, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	campaign, ok := memoryCampaigns[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &campaign, nil
+}
+
+// ReadCampaignsByUserID reads all the user-owned campaigns from the memory, sorted by name.
+func (m MemoryRepo) ReadCampaignsByUserID(_ context.Context, userID string) ([]models.Campaign, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	var result []models.Campaign
+	for _, campaign := range memoryCampaigns {
+		if campaign.UserID == userID {
+			result = append(result, campaign)
+		}
+	}
+	slices.SortFunc(result, func(a, b models.Campaign) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return result, nil
+}
+
+// UpdateCampaign renames the campaign in the memory.
+func (m MemoryRepo) UpdateCampaign(_ context.Context, campaign models.Campaign) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	existing, ok := memoryCampaigns[campaign.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	existing.Name = campaign.Name
+	memoryCampaigns[campaign.ID] = existing
+	return nil
+}
+
+// DeleteCampaign removes the campaign from the memory. The short URLs leave it on the next read.
+func (m MemoryRepo) DeleteCampaign(_ context.Context, id string) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if _, ok := memoryCampaigns[id]; !ok {
+		return ErrNotFound
+	}
+	delete(memoryCampaigns, id)
+	return nil
+}
+
+// GetCampaignStats returns the amount of the active short URLs in the campaign and the clicks on their split variants
+// counted in the memory.
+func (m MemoryRepo) GetCampaignStats(_ context.Context, id string) (*models.CampaignStats, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	campaign, ok := memoryCampaigns[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	stats := &models.CampaignStats{Campaign: campaign}
+	for shortURL, options := range memoryStorageOptions {
+		if options.CampaignID != id || memoryStorageDeactivatedURLs[shortURL] {
+			continue
+		}
+		stats.URLs++
+		for _, clicks := range memoryVariantClicks[shortURL] {
+			stats.Clicks += clicks
+		}
+	}
+	return stats, nil
+}
+
+// CreateAccount registers the user as the account in the memory.
+func (m MemoryRepo) CreateAccount(_ context.Context, account models.Account) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if _, ok := memoryAccounts[account.ID]; ok {
+		return ErrAlreadyExists
+	}
+	for _, existing := range memoryAccounts {
+		if existing.Email == account.Email {
+			return ErrAlreadyExists
+		}
+	}
+	memoryAccounts[account.ID] = account
+	return nil
+}
+
+// ReadAccount reads the account of the user from the memory by its ID.
+func (m MemoryRepo) ReadAccount(_ context.Context, id string) (*models.Account, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	account, ok := memoryAccounts[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &account, nil
+}
+
+// ReadAccountByEmail reads the account from the memory by its email.
+func (m MemoryRepo) ReadAccountByEmail(_ context.Context, email string) (*models.Account, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	for _, account := range memoryAccounts {
+		if account.Email == email {
+			return &account, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// DeleteUnverifiedAccount turns the account with the unconfirmed email back into the anonymous user in the memory.
+func (m MemoryRepo) DeleteUnverifiedAccount(_ context.Context, id string) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	account, ok := memoryAccounts[id]
+	if !ok || account.VerifiedAt != nil {
+		return ErrNotFound
+	}
+	delete(memoryAccounts, id)
+	return nil
+}
+
+// CreateAPIKey stores the API key in the memory.
+func (m MemoryRepo) CreateAPIKey(_ context.Context, key models.APIKey) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	memoryAPIKeys[key.ID] = key
+	return nil
+}
+
+// ReadAPIKey reads the API key from the memory by its ID. Returns ErrNotFound if there is no such key.
+func (m MemoryRepo) ReadAPIKey(_ context.Context, id string) (*models.APIKey, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	key, ok := memoryAPIKeys[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &key, nil
+}
+
+// ReadAPIKeyByHash reads the API key from the memory by the hash of the key.
+func (m MemoryRepo) ReadAPIKeyByHash(_ context.Context, keyHash string) (*models.APIKey, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	for _, key := range memoryAPIKeys {
+		if key.KeyHash == keyHash {
+			return &key, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ReadAPIKeysByUserID reads all the user-owned API keys from the memory, the newest first.
+func (m MemoryRepo) ReadAPIKeysByUserID(_ context.Context, userID string) ([]models.APIKey, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	var results []models.APIKey
+	for _, key := range memoryAPIKeys {
+		if key.UserID == userID {
+			results = append(results, key)
+		}
+	}
+	slices.SortFunc(results, func(a, b models.APIKey) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return results, nil
+}
+
+// TouchAPIKey saves the moment the API key was used at in the memory.
+func (m MemoryRepo) TouchAPIKey(_ context.Context, id string, usedAt time.Time) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	key, ok := memoryAPIKeys[id]
+	if !ok {
+		return ErrNotFound
+	}
+	key.LastUsedAt = &usedAt
+	memoryAPIKeys[id] = key
+	return nil
+}
+
+// DeleteAPIKey removes the API key from the memory.
+func (m MemoryRepo) DeleteAPIKey(_ context.Context, id string) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if _, ok := memoryAPIKeys[id]; !ok {
+		return ErrNotFound
+	}
+	delete(memoryAPIKeys, id)
+	return nil
+}
+
+// RevokeToken adds the token to the denylist in the memory.
+func (m MemoryRepo) RevokeToken(_ context.Context, tokenID string, _ string, expiresAt time.Time) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	now := time.Now()
+	maps.DeleteFunc(memoryRevokedTokens, func(_ string, tokenExpiresAt time.Time) bool {
+		return tokenExpiresAt.Before(now)
+	})
+	memoryRevokedTokens[tokenID] = expiresAt
+	return nil
+}
+
+// RevokeUserTokens saves the moment the tokens of the user issued before are revoked in the memory.
+// The latest moment wins.
+func (m MemoryRepo) RevokeUserTokens(_ context.Context, userID string, before time.Time) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if before.After(memoryTokensRevokedBefore[userID]) {
+		memoryTokensRevokedBefore[userID] = before
+	}
+	return nil
+}
+
+// IsTokenRevoked reports whether the token is revoked according to the memory.
+func (m MemoryRepo) IsTokenRevoked(_ context.Context, tokenID string, userID string, issuedAt time.Time) (bool, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	if _, ok := memoryRevokedTokens[tokenID]; tokenID != "" && ok {
+		return true, nil
+	}
+	return issuedAt.Before(memoryTokensRevokedBefore[userID]), nil
+}
+
+// CreateOIDCIdentity links the user of the identity provider to the local user in the memory.
+func (m MemoryRepo) CreateOIDCIdentity(_ context.Context, identity models.OIDCIdentity) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	key := oidcIdentityKey{issuer: identity.Issuer, subject: identity.Subject}
+	if _, ok := memoryOIDCIdentities[key]; ok {
+		return ErrAlreadyExists
+	}
+	memoryOIDCIdentities[key] = identity
+	return nil
+}
+
+// ReadOIDCIdentity reads the identity from the memory by the issuer and the subject.
+func (m MemoryRepo) ReadOIDCIdentity(_ context.Context, issuer string, subject string) (*models.OIDCIdentity, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	identity, ok := memoryOIDCIdentities[oidcIdentityKey{issuer: issuer, subject: subject}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &identity, nil
+}
+
+// CreateOrganization stores the organization in the memory together with its first owner.
+// Storing the same ID once again overwrites the organization, which is used when the storage is refilled from the file.
+func (m MemoryRepo) CreateOrganization(_ context.Context, org models.Organization, owner models.OrgMember) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	memoryOrganizations[org.ID] = org
+	if memoryOrgMembers[org.ID] == nil {
+		memoryOrgMembers[org.ID] = make(map[string]models.OrgMember)
+	}
+	memoryOrgMembers[org.ID][owner.UserID] = owner
+	return nil
+}
+
+// ReadOrganization reads the organization from the memory by its ID. Returns ErrNotFound if there is
+// no such organization.
+func (m MemoryRepo) ReadOrganization(_ context.Context, id string) (*models.Organization, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	org, ok := memoryOrganizations[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &org, nil
+}
+
+// ReadOrganizationsByUserID reads all the organizations the user is a member of from the memory, sorted by name.
+func (m MemoryRepo) ReadOrganizationsByUserID(_ context.Context, userID string) ([]models.Organization, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	var result []models.Organization
+	for id, members := range memoryOrgMembers {
+		member, ok := members[userID]
+		if !ok {
+			continue
+		}
+		org := memoryOrganizations[id]
+		org.Role = member.Role
+		result = append(result, org)
+	}
+	slices.SortFunc(result, func(a, b models.Organization) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return result, nil
+}
+
+// ReadOrgMember reads the membership of the user in the organization from the memory.
+func (m MemoryRepo) ReadOrgMember(_ context.Context, orgID string, userID string) (*models.OrgMember, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	member, ok := memoryOrgMembers[orgID][userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &member, nil
+}
+
+// ReadOrgMembers reads all the members of the organization from the memory, in the order they joined.
+func (m MemoryRepo) ReadOrgMembers(_ context.Context, orgID string) ([]models.OrgMember, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	result := make([]models.OrgMember, 0, len(memoryOrgMembers[orgID]))
+	for _, member := range memoryOrgMembers[orgID] {
+		result = append(result, member)
+	}
+	slices.SortFunc(result, func(a, b models.OrgMember) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.UserID, b.UserID))
+	})
+	return result, nil
+}
+
+// SaveOrgMember adds the member to the organization in the memory or changes the role of the existing one.
+func (m MemoryRepo) SaveOrgMember(_ context.Context, member models.OrgMember) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if _, ok := memoryOrganizations[member.OrgID]; !ok {
+		return ErrNotFound
+	}
+	if existing, ok := memoryOrgMembers[member.OrgID][member.UserID]; ok {
+		member.CreatedAt = existing.CreatedAt
+	}
+	memoryOrgMembers[member.OrgID][member.UserID] = member
+	return nil
+}
+
+// DeleteOrgMember removes the member from the organization in the memory.
+func (m MemoryRepo) DeleteOrgMember(_ context.Context, orgID string, userID string) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if _, ok := memoryOrgMembers[orgID][userID]; !ok {
+		return ErrNotFound
+	}
+	delete(memoryOrgMembers[orgID], userID)
+	return nil
+}
+
+// SearchShortURLs reads the short URLs of all the users matching the search from the memory, ordered by their creation.
+func (m MemoryRepo) SearchShortURLs(_ context.Context, search models.ShortURLSearch) ([]models.AdminShortURL, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	result := make([]models.AdminShortURL, 0)
+	for _, id := range memoryCreationOrder {
+		if len(result) >= search.Limit {
+			break
+		}
+		originalURL, userID := memoryStorage[id], memoryStorageUsersByURLs[id]
+		if search.OriginalURL != "" && originalURL != search.OriginalURL ||
+			search.UserID != "" && userID != search.UserID ||
+			search.Domain != "" && !matchesDomain(originalURL, search.Domain) {
+			continue
+		}
+		_, deleted := memoryStorageDeactivatedURLs[id]
+		_, banned := memoryUserBans[userID]
+		shortURL := models.AdminShortURL{
+			ShortURL:    id,
+			OriginalURL: originalURL,
+			UserID:      userID,
+			OrgID:       memoryStorageOptions[id].OrgID,
+			Title:       memoryStorageOptions[id].Title,
+			Deleted:     deleted,
+			OwnerBanned: banned,
+		}
+		if moderation, ok := memoryModerations[id]; ok {
+			shortURL.Moderation = &moderation
+		}
+		result = append(result, shortURL)
+	}
+	return result, nil
+}
+
+// matchesDomain reports whether the URL points to the host that is the domain or its subdomain.
+func matchesDomain(rawURL string, domain string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// SetModeration disables the short URL in the memory or enables it back if the moderation is nil.
+func (m MemoryRepo) SetModeration(_ context.Context, id string, moderation *models.Moderation) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if _, ok := memoryStorage[id]; !ok {
+		return ErrNotFound
+	}
+	if moderation == nil {
+		delete(memoryModerations, id)
+	} else {
+		memoryModerations[id] = *moderation
+	}
+	return nil
+}
+
+// BanUser bans the user in the memory or replaces the existing ban.
+func (m MemoryRepo) BanUser(_ context.Context, ban models.UserBan) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	memoryUserBans[ban.UserID] = ban
+	return nil
+}
+
+// UnbanUser lifts the ban of the user in the memory.
+func (m MemoryRepo) UnbanUser(_ context.Context, userID string) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if _, ok := memoryUserBans[userID]; !ok {
+		return ErrNotFound
+	}
+	delete(memoryUserBans, userID)
+	return nil
+}
+
+// ReadUserBan reads the ban of the user from the memory.
+func (m MemoryRepo) ReadUserBan(_ context.Context, userID string) (*models.UserBan, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	ban, ok := memoryUserBans[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &ban, nil
+}
+
+// CreateAuditEvent appends the event to the audit log in the memory.
+func (m MemoryRepo) CreateAuditEvent(_ context.Context, event models.AuditEvent) error {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	if event.Seq != int64(len(memoryAuditLog))+1 {
+		return ErrAlreadyExists
+	}
+	memoryAuditLog = append(memoryAuditLog, event)
+	return nil
+}
+
+// AppendAuditEvents chains the events to the last one of the audit log in the memory and appends them.
+func (m MemoryRepo) AppendAuditEvents(
+	_ context.Context, events []models.AuditEvent, hash func(models.AuditEvent) string) ([]models.AuditEvent, error) {
+	memoryLock.Lock()
+	defer memoryLock.Unlock()
+	var last models.AuditEvent
+	if len(memoryAuditLog) > 0 {
+		last = memoryAuditLog[len(memoryAuditLog)-1]
+	}
+	chained := chainAuditEvents(last, events, hash)
+	memoryAuditLog = append(memoryAuditLog, chained...)
+	return chained, nil
+}
+
+// chainAuditEvents returns the copies of the events chained one after another following the last event
+// of the audit log, the zero one if the log is empty.
+func chainAuditEvents(
+	last models.AuditEvent, events []models.AuditEvent, hash func(models.AuditEvent) string) []models.AuditEvent {
+	chained := make([]models.AuditEvent, len(events))
+	for i, event := range events {
+		event.Seq, event.PrevHash = last.Seq+1, last.Hash
+		event.Hash = hash(event)
+		chained[i], last = event, event
+	}
+	return chained
+}
+
+// ReadAuditEvents reads the events of the audit log matching the filter from the memory, ordered by their position.
+func (m MemoryRepo) ReadAuditEvents(_ context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	memoryLock.RLock()
+	defer memoryLock.RUnlock()
+	result := make([]models.AuditEvent, 0)
+	for _, event := range memoryAuditLog {
+		if len(result) >= filter.Limit {
+			break
+		}
+		if event.Seq <= filter.AfterSeq ||
+			!filter.From.IsZero() && event.CreatedAt.Before(filter.From) ||
+			!filter.To.IsZero() && !event.CreatedAt.Before(filter.To) ||
+			filter.Action != "" && event.Action != filter.Action ||
+			filter.Target != "" && event.Target != filter.Target ||
+			filter.UserID != "" && event.UserID != filter.UserID ||
+			filter.Transport != "" && event.Transport != filter.Transport {
+			continue
+		}
+		result = append(result, event)
+	}
+	return result, nil
+}
+
 func init() {
 	memoryStorage = make(map[string]string)
 	memoryIDsStorage = make(map[string][]string)
 	memoryStorageUsersByURLs = make(map[string]string)
 	memoryStorageDeactivatedURLs = make(map[string]bool)
+	memoryStorageOptions = make(map[string]models.ShortURLOptions)
+	memoryStorageMetadata = make(map[string]models.PageMetadata)
+	memoryUTMTemplates = make(map[string]models.UTMTemplate)
+	memoryVariantClicks = make(map[string]map[string]int64)
+	memoryClicksLeft = make(map[string]int64)
+	memoryCampaigns = make(map[string]models.Campaign)
+	memoryAccounts = make(map[string]models.Account)
+	memoryAPIKeys = make(map[string]models.APIKey)
+	memoryRevokedTokens = make(map[string]time.Time)
+	memoryTokensRevokedBefore = make(map[string]time.Time)
+	memoryOIDCIdentities = make(map[oidcIdentityKey]models.OIDCIdentity)
+	memoryOrgURLs = make(map[string][]string)
+	memoryOrganizations = make(map[string]models.Organization)
+	memoryOrgMembers = make(map[string]map[string]models.OrgMember)
+	memoryModerations = make(map[string]models.Moderation)
+	memoryUserBans = make(map[string]models.UserBan)
 }
@@ -3,14 +3,33 @@ package storage
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/clearthree/url-shortener/internal/app/config"
 	"github.com/clearthree/url-shortener/internal/app/models"
 )
 
+// TestMain keeps the file storage written by the tests in the temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "url-shortener")
+	if err != nil {
+		panic(err)
+	}
+	config.Settings.FileStoragePath = filepath.Join(dir, "storage.json")
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestMemoryRepo_Create(t *testing.T) {
 	type args struct {
 		ctx         context.Context
@@ -50,7 +69,7 @@ func TestMemoryRepo_Create(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := MemoryRepo{}
-			if got, err := m.Create(tt.args.ctx, tt.args.id, tt.args.originalURL, tt.args.userID); got != tt.want {
+			if got, err := m.Create(tt.args.ctx, tt.args.id, tt.args.originalURL, tt.args.userID, models.ShortURLOptions{}); got != tt.want {
 				require.NoError(t, err)
 				t.Errorf("Create() = %v, want %v", got, tt.want)
 			}
@@ -88,7 +107,7 @@ func TestMemoryRepo_Read(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			m := MemoryRepo{}
 			for k, v := range tt.preLoad {
-				_, err := m.Create(context.Background(), k, v, "SomeUserID")
+				_, err := m.Create(context.Background(), k, v, "SomeUserID", models.ShortURLOptions{})
 				require.NoError(t, err)
 			}
 			got, deleted := m.Read(tt.args.ctx, tt.args.id)
@@ -215,12 +234,12 @@ func TestMemoryRepo_ReadByUserID(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			m := MemoryRepo{}
 			for _, v := range tt.want {
-				_, err := m.Create(tt.args.ctx, v.ShortURL, v.OriginalURL, tt.args.userID)
+				_, err := m.Create(tt.args.ctx, v.ShortURL, v.OriginalURL, tt.args.userID, v.ShortURLOptions)
 				if err != nil {
 					require.NoError(t, err)
 				}
 			}
-			got, err := m.ReadByUserID(tt.args.ctx, tt.args.userID)
+			got, err := m.ReadByUserID(tt.args.ctx, tt.args.userID, models.ShortURLFilter{})
 			require.NoError(t, err)
 			assert.Equalf(t, tt.want, got, "ReadByUserID(%v, %v)", tt.args.ctx, tt.args.userID)
 		})
@@ -248,3 +267,454 @@ func TestMemoryRepo_GetStats(t *testing.T) {
 		})
 	}
 }
+
+func TestMemoryRepo_ReadByUserIDFilteredByTag(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	_, err := m.Create(ctx, "tagged", "http://ya.ru", "TaggedUserID", models.ShortURLOptions{Tags: []string{"news", "search"}})
+	require.NoError(t, err)
+	_, err = m.Create(ctx, "untagged", "http://yandex.ru", "TaggedUserID", models.ShortURLOptions{})
+	require.NoError(t, err)
+
+	got, err := m.ReadByUserID(ctx, "TaggedUserID", models.ShortURLFilter{Tag: "search"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.ShortURLsByUserResponse{
+		{
+			ShortURL:        "tagged",
+			OriginalURL:     "http://ya.ru",
+			ShortURLOptions: models.ShortURLOptions{Tags: []string{"news", "search"}},
+		},
+	}, got)
+}
+
+func TestMemoryRepo_ReadShortURL(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	options := models.ShortURLOptions{Title: "Yandex", Notes: "Search engine", Tags: []string{"search"}}
+	_, err := m.Create(ctx, "readable", "http://ya.ru", "SomeUserID", options)
+	require.NoError(t, err)
+
+	got, err := m.ReadShortURL(ctx, "readable")
+	require.NoError(t, err)
+	assert.Equal(t, &models.ShortURL{
+		ShortURL:        "readable",
+		OriginalURL:     "http://ya.ru",
+		UserID:          "SomeUserID",
+		ShortURLOptions: options,
+	}, got)
+
+	_, err = m.ReadShortURL(ctx, "nonExistent")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryRepo_Update(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	_, err := m.Create(ctx, "updatable", "http://ya.ru", "SomeUserID",
+		models.ShortURLOptions{Title: "Yandex", Notes: "Search engine"})
+	require.NoError(t, err)
+
+	notes := "Not only a search engine"
+	tags := []string{"news"}
+	err = m.Update(ctx, "updatable", models.UpdateShortURLRequest{Notes: &notes, Tags: &tags})
+	require.NoError(t, err)
+
+	got, err := m.ReadShortURL(ctx, "updatable")
+	require.NoError(t, err)
+	assert.Equal(t, models.ShortURLOptions{Title: "Yandex", Notes: notes, Tags: tags}, got.ShortURLOptions)
+
+	interstitial := true
+	redirectStatus := http.StatusMovedPermanently
+	err = m.Update(ctx, "updatable", models.UpdateShortURLRequest{Interstitial: &interstitial, RedirectStatus: &redirectStatus})
+	require.NoError(t, err)
+	got, err = m.ReadShortURL(ctx, "updatable")
+	require.NoError(t, err)
+	assert.Equal(t, models.RedirectOptions{Interstitial: true, RedirectStatus: http.StatusMovedPermanently}, got.RedirectOptions)
+	assert.Equal(t, "Yandex", got.Title)
+
+	err = m.Update(ctx, "nonExistent", models.UpdateShortURLRequest{Notes: &notes})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryRepo_UTMTemplates(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	template := models.UTMTemplate{ID: "template", UserID: "TemplateOwner", Name: "Newsletter",
+		UTMParameters: models.UTMParameters{Source: "newsletter"}}
+	require.NoError(t, m.CreateUTMTemplate(ctx, template))
+	_, err := m.Create(ctx, "tracked", "http://ya.ru", "TemplateOwner", models.ShortURLOptions{UTMTemplateID: "template"})
+	require.NoError(t, err)
+
+	got, err := m.ReadShortURL(ctx, "tracked")
+	require.NoError(t, err)
+	assert.Equal(t, &models.UTMParameters{Source: "newsletter"}, got.UTM)
+
+	template.UTMParameters = models.UTMParameters{Campaign: "autumn"}
+	require.NoError(t, m.UpdateUTMTemplate(ctx, template))
+	got, err = m.ReadShortURL(ctx, "tracked")
+	require.NoError(t, err)
+	assert.Equal(t, &models.UTMParameters{Campaign: "autumn"}, got.UTM, "the short URL uses the actual template")
+
+	templates, err := m.ReadUTMTemplatesByUserID(ctx, "TemplateOwner")
+	require.NoError(t, err)
+	assert.Equal(t, []models.UTMTemplate{template}, templates)
+
+	require.NoError(t, m.DeleteUTMTemplate(ctx, "template"))
+	got, err = m.ReadShortURL(ctx, "tracked")
+	require.NoError(t, err)
+	assert.Nil(t, got.UTM)
+	assert.Empty(t, got.UTMTemplateID, "the deleted template is detached")
+	assert.ErrorIs(t, m.DeleteUTMTemplate(ctx, "template"), ErrNotFound)
+	assert.ErrorIs(t, m.UpdateUTMTemplate(ctx, template), ErrNotFound)
+}
+
+func TestMemoryRepo_Campaigns(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	campaign := models.Campaign{ID: "campaign", UserID: "CampaignOwner", Name: "Black Friday"}
+	require.NoError(t, m.CreateCampaign(ctx, campaign))
+	_, err := m.Create(ctx, "grouped", "http://ya.ru/friday", "CampaignOwner", models.ShortURLOptions{CampaignID: "campaign"})
+	require.NoError(t, err)
+	_, err = m.Create(ctx, "ungrouped", "http://ya.ru/monday", "CampaignOwner", models.ShortURLOptions{})
+	require.NoError(t, err)
+	require.NoError(t, m.AddVariantClicks(ctx, "grouped", "a", 4))
+
+	urls, err := m.ReadByUserID(ctx, "CampaignOwner", models.ShortURLFilter{CampaignID: "campaign"})
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	assert.Equal(t, "grouped", urls[0].ShortURL)
+
+	stats, err := m.GetCampaignStats(ctx, "campaign")
+	require.NoError(t, err)
+	assert.Equal(t, &models.CampaignStats{Campaign: campaign, URLs: 1, Clicks: 4}, stats)
+
+	campaign.Name = "Cyber Monday"
+	require.NoError(t, m.UpdateCampaign(ctx, campaign))
+	campaigns, err := m.ReadCampaignsByUserID(ctx, "CampaignOwner")
+	require.NoError(t, err)
+	assert.Equal(t, []models.Campaign{campaign}, campaigns)
+
+	require.NoError(t, m.DeleteCampaign(ctx, "campaign"))
+	got, err := m.ReadShortURL(ctx, "grouped")
+	require.NoError(t, err)
+	assert.Empty(t, got.CampaignID, "the deleted campaign is detached")
+	_, err = m.GetCampaignStats(ctx, "campaign")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, m.DeleteCampaign(ctx, "campaign"), ErrNotFound)
+}
+
+func TestMemoryRepo_Accounts(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	account := models.Account{ID: "AccountOwner", Email: "owner@example.com", PasswordHash: "hash"}
+	require.NoError(t, m.CreateAccount(ctx, account))
+	assert.ErrorIs(t, m.CreateAccount(ctx, models.Account{ID: "AnotherOwner", Email: "owner@example.com"}),
+		ErrAlreadyExists, "the email is taken")
+	assert.ErrorIs(t, m.CreateAccount(ctx, models.Account{ID: "AccountOwner", Email: "another@example.com"}),
+		ErrAlreadyExists, "the user is registered already")
+
+	got, err := m.ReadAccount(ctx, "AccountOwner")
+	require.NoError(t, err)
+	assert.Equal(t, &account, got)
+	got, err = m.ReadAccountByEmail(ctx, "owner@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, &account, got)
+	_, err = m.ReadAccountByEmail(ctx, "another@example.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	verifiedAt := time.Date(2026, 10, 18, 19, 0, 0, 0, time.UTC)
+	require.NoError(t, m.CreateAccount(ctx, models.Account{ID: "VerifiedOwner", Email: "verified@example.com",
+		VerifiedAt: &verifiedAt}))
+	assert.ErrorIs(t, m.DeleteUnverifiedAccount(ctx, "VerifiedOwner"), ErrNotFound, "the verified account is kept")
+	require.NoError(t, m.DeleteUnverifiedAccount(ctx, "AccountOwner"))
+	_, err = m.ReadAccountByEmail(ctx, "owner@example.com")
+	assert.ErrorIs(t, err, ErrNotFound, "the email is freed")
+	require.NoError(t, m.CreateAccount(ctx, models.Account{ID: "AnotherOwner", Email: "owner@example.com"}))
+}
+
+func TestMemoryRepo_APIKeys(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	createdAt := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	older := models.APIKey{ID: "OlderKey", UserID: "APIKeysOwner", Name: "CI", KeyHash: "older-hash",
+		CreatedAt: createdAt}
+	newer := models.APIKey{ID: "NewerKey", UserID: "APIKeysOwner", Name: "Deploy", KeyHash: "newer-hash",
+		CreatedAt: createdAt.Add(time.Hour)}
+	require.NoError(t, m.CreateAPIKey(ctx, older))
+	require.NoError(t, m.CreateAPIKey(ctx, newer))
+
+	got, err := m.ReadAPIKeysByUserID(ctx, "APIKeysOwner")
+	require.NoError(t, err)
+	assert.Equal(t, []models.APIKey{newer, older}, got, "the newest key goes first")
+
+	usedAt := createdAt.Add(2 * time.Hour)
+	require.NoError(t, m.TouchAPIKey(ctx, "OlderKey", usedAt))
+	key, err := m.ReadAPIKeyByHash(ctx, "older-hash")
+	require.NoError(t, err)
+	assert.Equal(t, &usedAt, key.LastUsedAt)
+
+	require.NoError(t, m.DeleteAPIKey(ctx, "OlderKey"))
+	_, err = m.ReadAPIKey(ctx, "OlderKey")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, m.TouchAPIKey(ctx, "OlderKey", usedAt), ErrNotFound)
+	assert.ErrorIs(t, m.DeleteAPIKey(ctx, "OlderKey"), ErrNotFound)
+}
+
+func TestMemoryRepo_TokenRevocations(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	issuedAt := time.Date(2026, 10, 18, 21, 0, 0, 0, time.UTC)
+	revoked, err := m.IsTokenRevoked(ctx, "SomeToken", "RevokedOwner", issuedAt)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, m.RevokeToken(ctx, "ExpiredToken", "RevokedOwner", time.Now().Add(-time.Minute)))
+	require.NoError(t, m.RevokeToken(ctx, "SomeToken", "RevokedOwner", time.Now().Add(time.Hour)))
+	revoked, err = m.IsTokenRevoked(ctx, "SomeToken", "RevokedOwner", issuedAt)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	memoryLock.RLock()
+	_, kept := memoryRevokedTokens["ExpiredToken"]
+	memoryLock.RUnlock()
+	assert.False(t, kept, "the expired tokens are removed from the denylist")
+
+	require.NoError(t, m.RevokeUserTokens(ctx, "RevokedOwner", issuedAt.Add(time.Second)))
+	require.NoError(t, m.RevokeUserTokens(ctx, "RevokedOwner", issuedAt.Add(-time.Hour)))
+	revoked, err = m.IsTokenRevoked(ctx, "OtherToken", "RevokedOwner", issuedAt)
+	require.NoError(t, err)
+	assert.True(t, revoked, "the latest revocation wins")
+	revoked, err = m.IsTokenRevoked(ctx, "NewerToken", "RevokedOwner", issuedAt.Add(time.Second))
+	require.NoError(t, err)
+	assert.False(t, revoked, "the tokens issued afterward stay valid")
+}
+
+func TestMemoryRepo_OIDCIdentities(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	identity := models.OIDCIdentity{Issuer: "https://idp.example", Subject: "employee", UserID: "SSOUser",
+		CreatedAt: time.Date(2026, 10, 18, 22, 0, 0, 0, time.UTC)}
+	_, err := m.ReadOIDCIdentity(ctx, identity.Issuer, identity.Subject)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, m.CreateOIDCIdentity(ctx, identity))
+	got, err := m.ReadOIDCIdentity(ctx, identity.Issuer, identity.Subject)
+	require.NoError(t, err)
+	assert.Equal(t, &identity, got)
+	_, err = m.ReadOIDCIdentity(ctx, "https://other-idp.example", identity.Subject)
+	assert.ErrorIs(t, err, ErrNotFound, "the subject is unique within the issuer only")
+	assert.ErrorIs(t, m.CreateOIDCIdentity(ctx, identity), ErrAlreadyExists)
+}
+
+func TestMemoryRepo_Organizations(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	createdAt := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	org := models.Organization{ID: "org", Name: "Acme", CreatedAt: createdAt}
+	owner := models.OrgMember{OrgID: "org", UserID: "OrgOwner", Role: models.RoleOwner, CreatedAt: createdAt}
+	require.NoError(t, m.CreateOrganization(ctx, org, owner))
+	editor := models.OrgMember{OrgID: "org", UserID: "OrgEditor", Role: models.RoleViewer,
+		CreatedAt: createdAt.Add(time.Hour)}
+	require.NoError(t, m.SaveOrgMember(ctx, editor))
+	editor.Role = models.RoleEditor
+	require.NoError(t, m.SaveOrgMember(ctx, editor))
+
+	got, err := m.ReadOrganization(ctx, "org")
+	require.NoError(t, err)
+	assert.Equal(t, &org, got)
+	orgs, err := m.ReadOrganizationsByUserID(ctx, "OrgEditor")
+	require.NoError(t, err)
+	assert.Equal(t, []models.Organization{{ID: "org", Name: "Acme", CreatedAt: createdAt, Role: models.RoleEditor}}, orgs)
+	member, err := m.ReadOrgMember(ctx, "org", "OrgEditor")
+	require.NoError(t, err)
+	assert.Equal(t, &editor, member)
+	members, err := m.ReadOrgMembers(ctx, "org")
+	require.NoError(t, err)
+	assert.Equal(t, []models.OrgMember{owner, editor}, members, "the members are listed in the order they joined")
+
+	_, err = m.Create(ctx, "shared", "http://ya.ru/shared", "OrgEditor", models.ShortURLOptions{OrgID: "org"})
+	require.NoError(t, err)
+	_, err = m.Create(ctx, "personal", "http://ya.ru/personal", "OrgEditor", models.ShortURLOptions{})
+	require.NoError(t, err)
+	urls, err := m.ReadByOrgID(ctx, "org", models.ShortURLFilter{})
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	assert.Equal(t, "shared", urls[0].ShortURL)
+	assert.Equal(t, "org", urls[0].OrgID)
+
+	require.NoError(t, m.DeleteOrgMember(ctx, "org", "OrgEditor"))
+	_, err = m.ReadOrgMember(ctx, "org", "OrgEditor")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, m.DeleteOrgMember(ctx, "org", "OrgEditor"), ErrNotFound)
+	_, err = m.ReadOrganization(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryRepo_VariantClicks(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	_, err := m.Create(ctx, "split", "http://ya.ru", "SplitOwner", models.ShortURLOptions{})
+	require.NoError(t, err)
+	require.NoError(t, m.AddVariantClicks(ctx, "split", "a", 2))
+	require.NoError(t, m.AddVariantClicks(ctx, "split", "a", 3))
+	require.NoError(t, m.AddVariantClicks(ctx, "split", "b", 1))
+	assert.ErrorIs(t, m.AddVariantClicks(ctx, "missing", "a", 1), ErrNotFound)
+
+	clicks, err := m.ReadVariantClicks(ctx, "split")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a": 5, "b": 1}, clicks)
+	clicks["a"] = 0
+	clicks, err = m.ReadVariantClicks(ctx, "split")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), clicks["a"], "the counters are copied")
+}
+
+func TestMemoryRepo_UseClick(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	_, err := m.Create(ctx, "limited", "http://ya.ru/reset", "LimitOwner", models.ShortURLOptions{MaxClicks: 10})
+	require.NoError(t, err)
+	_, err = m.Create(ctx, "unlimited", "http://ya.ru/forever", "LimitOwner", models.ShortURLOptions{})
+	require.NoError(t, err)
+
+	var used, exhausted atomic.Int64
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if left, err := m.UseClick(ctx, "limited"); err == nil {
+				used.Add(1)
+				if left == 0 {
+					exhausted.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(10), used.Load(), "the concurrent visitors never get more clicks than the limit")
+	assert.Equal(t, int64(1), exhausted.Load(), "only one of the visitors takes the last click")
+	_, err = m.UseClick(ctx, "limited")
+	assert.ErrorIs(t, err, ErrNoClicksLeft)
+	_, err = m.UseClick(ctx, "unlimited")
+	assert.ErrorIs(t, err, ErrNoClicksLeft)
+	_, err = m.UseClick(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = m.Create(ctx, "limited", "http://ya.ru/reset", "LimitOwner", models.ShortURLOptions{MaxClicks: 10})
+	require.NoError(t, err)
+	shortURL, err := m.ReadShortURL(ctx, "limited")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), shortURL.ClicksLeft, "the clicks left are kept when the record is overwritten")
+	list, err := m.ReadByUserID(ctx, "LimitOwner", models.ShortURLFilter{})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.NotNil(t, list[0].ClicksLeft)
+	assert.Equal(t, int64(0), *list[0].ClicksLeft)
+	assert.Nil(t, list[1].ClicksLeft)
+}
+
+func TestMemoryRepo_SetMetadata(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	_, err := m.Create(ctx, "withMetadata", "http://ya.ru", "MetadataUserID", models.ShortURLOptions{})
+	require.NoError(t, err)
+
+	pageMetadata := models.PageMetadata{Title: "Yandex", FaviconURL: "http://ya.ru/favicon.ico"}
+	require.NoError(t, m.SetMetadata(ctx, "withMetadata", pageMetadata))
+
+	got, err := m.ReadShortURL(ctx, "withMetadata")
+	require.NoError(t, err)
+	assert.Equal(t, &pageMetadata, got.Metadata)
+	listed, err := m.ReadByUserID(ctx, "MetadataUserID", models.ShortURLFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, &pageMetadata, listed[0].Metadata)
+
+	assert.ErrorIs(t, m.SetMetadata(ctx, "nonExistent", pageMetadata), ErrNotFound)
+}
+
+func TestMemoryRepo_Moderation(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	_, err := m.Create(ctx, "moderatedPhishing", "https://Login.Moderated.example/verify", "ModeratedUserID", models.ShortURLOptions{})
+	require.NoError(t, err)
+	_, err = m.Create(ctx, "moderatedClean", "https://moderated.example/page", "ModeratedCleanUserID", models.ShortURLOptions{})
+	require.NoError(t, err)
+
+	moderation := models.Moderation{DisabledAt: time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC), Reason: "Phishing"}
+	require.NoError(t, m.SetModeration(ctx, "moderatedPhishing", &moderation))
+	assert.ErrorIs(t, m.SetModeration(ctx, "nonExistent", &moderation), ErrNotFound)
+	got, err := m.ReadShortURL(ctx, "moderatedPhishing")
+	require.NoError(t, err)
+	assert.Equal(t, &moderation, got.Moderation)
+
+	ban := models.UserBan{BannedAt: moderation.DisabledAt, UserID: "ModeratedUserID", Reason: "Spam"}
+	require.NoError(t, m.BanUser(ctx, ban))
+	readBan, err := m.ReadUserBan(ctx, "ModeratedUserID")
+	require.NoError(t, err)
+	assert.Equal(t, &ban, readBan)
+
+	found, err := m.SearchShortURLs(ctx, models.ShortURLSearch{Domain: "moderated.example", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []models.AdminShortURL{
+		{
+			Moderation:  &moderation,
+			ShortURL:    "moderatedPhishing",
+			OriginalURL: "https://Login.Moderated.example/verify",
+			UserID:      "ModeratedUserID",
+			OwnerBanned: true,
+		},
+		{ShortURL: "moderatedClean", OriginalURL: "https://moderated.example/page", UserID: "ModeratedCleanUserID"},
+	}, found)
+	found, err = m.SearchShortURLs(ctx, models.ShortURLSearch{Domain: "moderated.example", Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+	found, err = m.SearchShortURLs(ctx, models.ShortURLSearch{UserID: "ModeratedCleanUserID", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+
+	require.NoError(t, m.SetModeration(ctx, "moderatedPhishing", nil))
+	got, err = m.ReadShortURL(ctx, "moderatedPhishing")
+	require.NoError(t, err)
+	assert.Nil(t, got.Moderation)
+
+	require.NoError(t, m.UnbanUser(ctx, "ModeratedUserID"))
+	assert.ErrorIs(t, m.UnbanUser(ctx, "ModeratedUserID"), ErrNotFound)
+	_, err = m.ReadUserBan(ctx, "ModeratedUserID")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryRepo_AuditLog(t *testing.T) {
+	m := MemoryRepo{}
+	ctx := context.Background()
+	createdAt := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	hash := func(event models.AuditEvent) string { return fmt.Sprintf("hash%d", event.Seq) }
+	appended, err := m.AppendAuditEvents(ctx, []models.AuditEvent{
+		{CreatedAt: createdAt, Action: "url.create", Target: "auditedURL", UserID: "AuditUserID",
+			Transport: models.TransportHTTP},
+		{CreatedAt: createdAt.Add(time.Hour), Action: "url.update", Target: "auditedURL", UserID: "AuditUserID",
+			Transport: models.TransportGRPC},
+	}, hash)
+	require.NoError(t, err)
+	require.Len(t, appended, 2)
+	first, second := appended[0], appended[1]
+	assert.Equal(t, hash(first), first.Hash)
+	assert.Equal(t, first.Seq+1, second.Seq)
+	assert.Equal(t, first.Hash, second.PrevHash, "the events are chained")
+	assert.ErrorIs(t, m.CreateAuditEvent(ctx, first), ErrAlreadyExists, "the position is taken")
+	third := models.AuditEvent{Seq: second.Seq + 1, CreatedAt: createdAt.Add(2 * time.Hour), Action: "url.delete",
+		Target: "anotherURL", PrevHash: second.Hash, Hash: "restored"}
+	require.NoError(t, m.CreateAuditEvent(ctx, third), "the event restored from the file follows the last one")
+	events, err := m.ReadAuditEvents(ctx, models.AuditFilter{UserID: "AuditUserID", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []models.AuditEvent{first, second}, events)
+	events, err = m.ReadAuditEvents(ctx, models.AuditFilter{Transport: models.TransportGRPC, Target: "auditedURL", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []models.AuditEvent{second}, events)
+	events, err = m.ReadAuditEvents(ctx, models.AuditFilter{
+		UserID: "AuditUserID", From: createdAt, To: createdAt.Add(time.Hour), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []models.AuditEvent{first}, events, "the end of the period is excluded")
+	events, err = m.ReadAuditEvents(ctx, models.AuditFilter{AfterSeq: first.Seq, Action: "url.update", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []models.AuditEvent{second}, events)
+}
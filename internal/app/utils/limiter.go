@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// maxTrackedKeys is the amount of tracked keys the limiter is bounded by.
+const maxTrackedKeys = 10000
+
+type attemptWindow struct {
+	start time.Time
+	count int
+}
+
+// AttemptLimiter limits the amount of attempts per key within the fixed time window,
+// e.g. the password guesses for the short URL from the same IP address. Safe for the concurrent use.
+// The nil limiter allows everything.
+type AttemptLimiter struct {
+	attempts map[string]attemptWindow
+	now      func() time.Time
+	window   time.Duration
+	mu       sync.Mutex
+	max      int
+}
+
+// NewAttemptLimiter is the constructor that returns the new AttemptLimiter allowing up to max attempts
+// per key within the window.
+func NewAttemptLimiter(max int, window time.Duration) *AttemptLimiter {
+	return &AttemptLimiter{
+		attempts: make(map[string]attemptWindow),
+		now:      time.Now,
+		window:   window,
+		max:      max,
+	}
+}
+
+// Allow reserves one more attempt for the key if it is allowed, so the concurrent attempts can't all pass
+// the check before the failed ones are counted. Otherwise, returns the time left until the window is over.
+// The reserved attempt counts as failed unless it is refunded.
+func (l *AttemptLimiter) Allow(key string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	attempt, ok := l.attempts[key]
+	if !ok || now.Sub(attempt.start) >= l.window {
+		if !ok && len(l.attempts) >= maxTrackedKeys {
+			l.evict(now)
+		}
+		attempt = attemptWindow{start: now}
+	}
+	if attempt.count >= l.max {
+		return false, l.window - now.Sub(attempt.start)
+	}
+	attempt.count++
+	l.attempts[key] = attempt
+	return true, 0
+}
+
+// Refund returns the attempt reserved for the key, e.g. the successful one.
+func (l *AttemptLimiter) Refund(key string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	attempt, ok := l.attempts[key]
+	if !ok {
+		return
+	}
+	attempt.count--
+	if attempt.count <= 0 {
+		delete(l.attempts, key)
+		return
+	}
+	l.attempts[key] = attempt
+}
+
+// Reset forgets the attempts for the key.
+func (l *AttemptLimiter) Reset(key string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.attempts, key)
+}
+
+// evict removes the keys which windows are over and, if there are still too many of them, the oldest one,
+// so the flood of distinct keys can't grow the limiter infinitely.
+func (l *AttemptLimiter) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, attempt := range l.attempts {
+		if now.Sub(attempt.start) >= l.window {
+			delete(l.attempts, key)
+			continue
+		}
+		if oldestKey == "" || attempt.start.Before(oldest) {
+			oldestKey, oldest = key, attempt.start
+		}
+	}
+	if len(l.attempts) >= maxTrackedKeys {
+		delete(l.attempts, oldestKey)
+	}
+}
+
+// LimitedKey is the key of the attempt along with the limiter the attempt is counted in.
+type LimitedKey struct {
+	Limiter *AttemptLimiter
+	Key     string
+}
+
+// AllowAll reserves the attempt for every key in its limiter, the attempt is allowed only if all of them allow it.
+// Otherwise, the reserved attempts are refunded and the time left until the window of the exhausted key is over
+// is returned.
+func AllowAll(keys ...LimitedKey) (bool, time.Duration) {
+	for i, key := range keys {
+		if allowed, retryAfter := key.Limiter.Allow(key.Key); !allowed {
+			RefundAll(keys[:i]...)
+			return false, retryAfter
+		}
+	}
+	return true, 0
+}
+
+// RefundAll returns the attempt reserved for every key by AllowAll.
+func RefundAll(keys ...LimitedKey) {
+	for _, key := range keys {
+		key.Limiter.Refund(key.Key)
+	}
+}
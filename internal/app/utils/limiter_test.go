@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttemptLimiter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	limiter := NewAttemptLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	allowed, _ := limiter.Allow("lelele|127.0.0.1")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("lelele|127.0.0.1")
+	assert.True(t, allowed)
+
+	allowed, retryAfter := limiter.Allow("lelele|127.0.0.1")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, retryAfter)
+
+	allowed, _ = limiter.Allow("lelele|127.0.0.2")
+	assert.True(t, allowed, "other IP addresses must not be affected")
+	allowed, _ = limiter.Allow("another|127.0.0.1")
+	assert.True(t, allowed, "other links must not be affected")
+
+	now = now.Add(30 * time.Second)
+	allowed, retryAfter = limiter.Allow("lelele|127.0.0.1")
+	assert.False(t, allowed)
+	assert.Equal(t, 30*time.Second, retryAfter)
+
+	now = now.Add(30 * time.Second)
+	allowed, _ = limiter.Allow("lelele|127.0.0.1")
+	assert.True(t, allowed, "the window is over")
+
+	limiter.Refund("lelele|127.0.0.1")
+	allowed, _ = limiter.Allow("lelele|127.0.0.1")
+	assert.True(t, allowed, "the refunded attempt isn't counted")
+	allowed, _ = limiter.Allow("lelele|127.0.0.1")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("lelele|127.0.0.1")
+	assert.False(t, allowed)
+
+	limiter.Reset("lelele|127.0.0.1")
+	allowed, _ = limiter.Allow("lelele|127.0.0.1")
+	assert.True(t, allowed, "the attempts are forgotten after the reset")
+}
+
+func TestAttemptLimiter_concurrent(t *testing.T) {
+	limiter := NewAttemptLimiter(5, time.Minute)
+	var allowedCount atomic.Int32
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if allowed, _ := limiter.Allow("lelele"); allowed {
+				allowedCount.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(5), allowedCount.Load(), "the attempts checked at once must not exceed the limit")
+}
+
+func TestAttemptLimiter_evict(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	limiter := NewAttemptLimiter(1, time.Minute)
+	limiter.now = func() time.Time { return now }
+	limiter.Allow("expired")
+	now = now.Add(time.Minute)
+	limiter.Allow("active")
+	limiter.evict(now)
+	assert.NotContains(t, limiter.attempts, "expired")
+	assert.Contains(t, limiter.attempts, "active")
+
+	for i := range maxTrackedKeys + 10 {
+		now = now.Add(time.Millisecond)
+		limiter.Allow(strconv.Itoa(i))
+	}
+	assert.Len(t, limiter.attempts, maxTrackedKeys, "the limiter must be bounded")
+	assert.NotContains(t, limiter.attempts, "active", "the oldest key is evicted first")
+	assert.Contains(t, limiter.attempts, strconv.Itoa(maxTrackedKeys+9))
+}
+
+func TestAllowAll(t *testing.T) {
+	perIP := NewAttemptLimiter(1, time.Minute)
+	perLink := NewAttemptLimiter(2, time.Minute)
+	keys := func(ip string) []LimitedKey {
+		return []LimitedKey{{Limiter: perLink, Key: "lelele"}, {Limiter: perIP, Key: "lelele|" + ip}}
+	}
+
+	allowed, _ := AllowAll(keys("127.0.0.1")...)
+	assert.True(t, allowed)
+	allowed, _ = AllowAll(keys("127.0.0.1")...)
+	assert.False(t, allowed, "the IP address is exhausted")
+	allowed, _ = AllowAll(keys("127.0.0.2")...)
+	assert.True(t, allowed, "the refused attempt isn't counted for the link")
+	allowed, _ = AllowAll(keys("127.0.0.3")...)
+	assert.False(t, allowed, "the link is exhausted whatever the IP address is")
+
+	RefundAll(keys("127.0.0.2")...)
+	allowed, _ = AllowAll(keys("127.0.0.4")...)
+	assert.True(t, allowed)
+}
+
+func TestAttemptLimiter_nil(t *testing.T) {
+	var limiter *AttemptLimiter
+	limiter.Refund("lelele")
+	limiter.Reset("lelele")
+	allowed, _ := limiter.Allow("lelele")
+	assert.True(t, allowed)
+}
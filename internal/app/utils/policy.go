@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+// ErrURLNotAllowed is an error that is returned when the URL is rejected by the URLPolicy.
+var ErrURLNotAllowed = errors.New("url is not allowed by the safety policy")
+
+// URLPolicy is the safety policy for the URLs the service requests on its own, e.g. to fetch the page metadata.
+// Only http(s) URLs are allowed, private, loopback and link-local networks are rejected unless explicitly allowed.
+type URLPolicy struct {
+	AllowPrivateNetworks bool
+}
+
+// CheckURL checks the scheme and the host of the URL. Hosts given as IP addresses are checked with CheckIP,
+// the domain names are checked after the resolution, see Control.
+func (p URLPolicy) CheckURL(rawURL string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrURLNotAllowed, err)
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrURLNotAllowed, parsedURL.Scheme)
+	}
+	if parsedURL.Hostname() == "" {
+		return fmt.Errorf("%w: empty host", ErrURLNotAllowed)
+	}
+	if ip := net.ParseIP(parsedURL.Hostname()); ip != nil {
+		return p.CheckIP(ip)
+	}
+	return nil
+}
+
+// CheckIP checks that the IP address belongs to the public network.
+func (p URLPolicy) CheckIP(ip net.IP) error {
+	if p.AllowPrivateNetworks {
+		return nil
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%w: address %s", ErrURLNotAllowed, ip)
+	}
+	return nil
+}
+
+// Control is the net.Dialer control function that checks the already resolved address before connecting to it,
+// so neither redirects nor DNS records can lead the request to the private network.
+func (p URLPolicy) Control(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: address %s", ErrURLNotAllowed, host)
+	}
+	return p.CheckIP(ip)
+}
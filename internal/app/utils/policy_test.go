@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLPolicy_CheckURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		policy  URLPolicy
+		allowed bool
+	}{
+		{name: "Public domain", url: "https://ya.ru/page", allowed: true},
+		{name: "Public IP", url: "http://77.88.55.242/", allowed: true},
+		{name: "Unsupported scheme", url: "file:///etc/passwd", allowed: false},
+		{name: "Empty host", url: "http:///page", allowed: false},
+		{name: "Loopback", url: "http://127.0.0.1:8080/", allowed: false},
+		{name: "Private network", url: "http://10.0.0.1/", allowed: false},
+		{name: "IPv6 loopback", url: "http://[::1]/", allowed: false},
+		{name: "Link-local metadata endpoint", url: "http://169.254.169.254/latest", allowed: false},
+		{name: "Loopback allowed", url: "http://127.0.0.1:8080/", policy: URLPolicy{AllowPrivateNetworks: true}, allowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.CheckURL(tt.url)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrURLNotAllowed)
+			}
+		})
+	}
+}
+
+func TestURLPolicy_Control(t *testing.T) {
+	policy := URLPolicy{}
+	assert.NoError(t, policy.Control("tcp", "77.88.55.242:443", nil))
+	assert.ErrorIs(t, policy.Control("tcp", "192.168.1.1:80", nil), ErrURLNotAllowed)
+	assert.ErrorIs(t, policy.Control("tcp6", "[fe80::1]:80", nil), ErrURLNotAllowed)
+	assert.NoError(t, policy.CheckIP(net.ParseIP("2a02:6b8::2:242")))
+}
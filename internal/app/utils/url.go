@@ -1,7 +1,15 @@
 // Package utils contains useful functions
 package utils
 
-import "net/url"
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ErrUnsafePath is an error that is returned when the path suffix tries to leave the path of the destination.
+var ErrUnsafePath = errors.New("path suffix must not contain dot segments")
 
 // IsURL Is a helper function that checks if the string is a valid URL
 func IsURL(payload string) bool {
@@ -11,3 +19,71 @@ func IsURL(payload string) bool {
 	}
 	return parsedURL.Scheme == "https" || parsedURL.Scheme == "http"
 }
+
+// unsafeSchemes are the schemes that must never be redirected to, since they run the code or read the local files.
+var unsafeSchemes = []string{"javascript", "vbscript", "data", "file", "blob"}
+
+// IsDeepLink Is a helper function that checks if the string is a valid absolute URL with any safe scheme,
+// e.g. the web page or the link opening the mobile app.
+func IsDeepLink(payload string) bool {
+	parsedURL, err := url.Parse(payload)
+	if err != nil || parsedURL.Scheme == "" || slices.Contains(unsafeSchemes, parsedURL.Scheme) {
+		return false
+	}
+	return parsedURL.Host != "" || parsedURL.Opaque != "" || parsedURL.Path != ""
+}
+
+// MergeQuery adds the parameters of the raw query to the query of the URL. The parameters already present in the URL
+// win, so they can't be overridden; the new ones are appended in the original order and encoding.
+func MergeQuery(rawURL string, rawQuery string) (string, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if rawQuery == "" {
+		return rawURL, nil
+	}
+	existing := parsedURL.Query()
+	merged := []string{}
+	if parsedURL.RawQuery != "" {
+		merged = append(merged, parsedURL.RawQuery)
+	}
+	for _, pair := range strings.Split(rawQuery, "&") {
+		rawKey, _, _ := strings.Cut(pair, "=")
+		key, unescapeErr := url.QueryUnescape(rawKey)
+		if unescapeErr != nil || key == "" || existing.Has(key) {
+			continue
+		}
+		merged = append(merged, pair)
+	}
+	parsedURL.RawQuery = strings.Join(merged, "&")
+	return parsedURL.String(), nil
+}
+
+// AppendPath appends the escaped path suffix to the path of the URL, keeping its query and fragment.
+// The suffixes with dot segments are rejected, so the result never leaves the path of the URL.
+func AppendPath(rawURL string, escapedSuffix string) (string, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	escapedSuffix = strings.TrimPrefix(escapedSuffix, "/")
+	if escapedSuffix == "" {
+		return rawURL, nil
+	}
+	suffix, err := url.PathUnescape(escapedSuffix)
+	if err != nil {
+		return "", err
+	}
+	for _, segment := range strings.Split(suffix, "/") {
+		if segment == "." || segment == ".." {
+			return "", ErrUnsafePath
+		}
+	}
+	rawPath := strings.TrimSuffix(parsedURL.EscapedPath(), "/") + "/" + escapedSuffix
+	if parsedURL.Path, err = url.PathUnescape(rawPath); err != nil {
+		return "", err
+	}
+	parsedURL.RawPath = rawPath
+	return parsedURL.String(), nil
+}
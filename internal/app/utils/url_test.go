@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		rawQuery string
+		want     string
+	}{
+		{name: "No query", url: "https://ya.ru/page", want: "https://ya.ru/page"},
+		{name: "New parameters", url: "https://ya.ru/page", rawQuery: "ref=mail&b=2", want: "https://ya.ru/page?ref=mail&b=2"},
+		{name: "Existing parameters win", url: "https://ya.ru/page?ref=site", rawQuery: "ref=mail&utm=x",
+			want: "https://ya.ru/page?ref=site&utm=x"},
+		{name: "Repeated parameters are kept", url: "https://ya.ru/", rawQuery: "tag=a&tag=b", want: "https://ya.ru/?tag=a&tag=b"},
+		{name: "Encoding is kept", url: "https://ya.ru/", rawQuery: "q=a%20b%26c", want: "https://ya.ru/?q=a%20b%26c"},
+		{name: "Fragment is kept", url: "https://ya.ru/page#top", rawQuery: "ref=mail", want: "https://ya.ru/page?ref=mail#top"},
+		{name: "Empty keys are skipped", url: "https://ya.ru/", rawQuery: "=x&&ref=mail", want: "https://ya.ru/?ref=mail"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeQuery(tt.url, tt.rawQuery)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAppendPath(t *testing.T) {
+	tests := []struct {
+		wantErr error
+		name    string
+		url     string
+		suffix  string
+		want    string
+	}{
+		{name: "No suffix", url: "https://ya.ru/docs", want: "https://ya.ru/docs"},
+		{name: "Suffix", url: "https://ya.ru/docs", suffix: "sub/page", want: "https://ya.ru/docs/sub/page"},
+		{name: "Trailing slash of the destination", url: "https://ya.ru/docs/", suffix: "/sub/", want: "https://ya.ru/docs/sub/"},
+		{name: "Destination without path", url: "https://ya.ru", suffix: "page", want: "https://ya.ru/page"},
+		{name: "Query and fragment are kept", url: "https://ya.ru/docs?v=2#top", suffix: "page",
+			want: "https://ya.ru/docs/page?v=2#top"},
+		{name: "Encoding is kept", url: "https://ya.ru/docs", suffix: "a%2Fb%20c", want: "https://ya.ru/docs/a%2Fb%20c"},
+		{name: "Dot segments", url: "https://ya.ru/docs", suffix: "../admin", wantErr: ErrUnsafePath},
+		{name: "Encoded dot segments", url: "https://ya.ru/docs", suffix: "a/%2e%2e/admin", wantErr: ErrUnsafePath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AppendPath(tt.url, tt.suffix)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIsDeepLink(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    bool
+	}{
+		{payload: "https://apps.apple.com/app/id1", want: true},
+		{payload: "market://details?id=com.example", want: true},
+		{payload: "myapp://open/item/1", want: true},
+		{payload: "mailto:info@example.com", want: true},
+		{payload: "JavaScript:alert(1)", want: false},
+		{payload: "data:text/html,hello", want: false},
+		{payload: "file:///etc/passwd", want: false},
+		{payload: "/relative/path", want: false},
+		{payload: "myapp://", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.payload, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsDeepLink(tt.payload))
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import "strings"
+
+// Platforms the short URL can be targeted at. The operating systems are more specific than the device types.
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWindows = "windows"
+	PlatformMacOS   = "macos"
+	PlatformLinux   = "linux"
+	PlatformMobile  = "mobile"
+	PlatformDesktop = "desktop"
+)
+
+// TargetingPlatforms are all the platforms the short URL can be targeted at.
+var TargetingPlatforms = []string{PlatformIOS, PlatformAndroid, PlatformWindows, PlatformMacOS, PlatformLinux,
+	PlatformMobile, PlatformDesktop}
+
+// DetectPlatforms returns the platforms of the visitor detected from the User-Agent header: the operating system
+// if it is known and the device type. Nothing is detected from the empty header.
+// iPads requesting the desktop sites pretend to be Macs, so they are detected as macOS desktops.
+func DetectPlatforms(userAgent string) []string {
+	userAgent = strings.ToLower(userAgent)
+	if userAgent == "" {
+		return nil
+	}
+	var platforms []string
+	switch {
+	case strings.Contains(userAgent, "iphone") || strings.Contains(userAgent, "ipad") || strings.Contains(userAgent, "ipod"):
+		platforms = append(platforms, PlatformIOS)
+	case strings.Contains(userAgent, "android"):
+		platforms = append(platforms, PlatformAndroid)
+	case strings.Contains(userAgent, "windows"):
+		platforms = append(platforms, PlatformWindows)
+	case strings.Contains(userAgent, "macintosh") || strings.Contains(userAgent, "mac os x"):
+		platforms = append(platforms, PlatformMacOS)
+	case strings.Contains(userAgent, "linux") || strings.Contains(userAgent, "cros "):
+		platforms = append(platforms, PlatformLinux)
+	}
+	mobile := len(platforms) > 0 && (platforms[0] == PlatformIOS || platforms[0] == PlatformAndroid) ||
+		strings.Contains(userAgent, "mobile")
+	if mobile {
+		return append(platforms, PlatformMobile)
+	}
+	return append(platforms, PlatformDesktop)
+}
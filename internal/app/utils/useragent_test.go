@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectPlatforms(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      []string
+	}{
+		{
+			name:      "iPhone",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1",
+			want:      []string{PlatformIOS, PlatformMobile},
+		},
+		{
+			name:      "Android",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36",
+			want:      []string{PlatformAndroid, PlatformMobile},
+		},
+		{
+			name:      "Windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36",
+			want:      []string{PlatformWindows, PlatformDesktop},
+		},
+		{
+			name:      "Mac",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15",
+			want:      []string{PlatformMacOS, PlatformDesktop},
+		},
+		{
+			name:      "Linux",
+			userAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0",
+			want:      []string{PlatformLinux, PlatformDesktop},
+		},
+		{
+			name:      "Unknown client",
+			userAgent: "curl/8.7.1",
+			want:      []string{PlatformDesktop},
+		},
+		{
+			name: "No header",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectPlatforms(tt.userAgent))
+		})
+	}
+}